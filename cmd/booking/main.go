@@ -0,0 +1,738 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dmehra2102/booking-system/docs"
+	apikeyrepository "github.com/dmehra2102/booking-system/internal/apikey/repository"
+	apikeyservice "github.com/dmehra2102/booking-system/internal/apikey/service"
+	"github.com/dmehra2102/booking-system/internal/booking/clients"
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/booking/handler"
+	"github.com/dmehra2102/booking-system/internal/booking/repository"
+	"github.com/dmehra2102/booking-system/internal/booking/service"
+	calendarhandler "github.com/dmehra2102/booking-system/internal/calendar/handler"
+	calendarprovider "github.com/dmehra2102/booking-system/internal/calendar/provider"
+	calendarrepository "github.com/dmehra2102/booking-system/internal/calendar/repository"
+	calendarservice "github.com/dmehra2102/booking-system/internal/calendar/service"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/featureflags"
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	exportdomain "github.com/dmehra2102/booking-system/internal/export/domain"
+	exporthandler "github.com/dmehra2102/booking-system/internal/export/handler"
+	exportrepository "github.com/dmehra2102/booking-system/internal/export/repository"
+	exportservice "github.com/dmehra2102/booking-system/internal/export/service"
+	pricinghandler "github.com/dmehra2102/booking-system/internal/pricing/handler"
+	pricingrepository "github.com/dmehra2102/booking-system/internal/pricing/repository"
+	pricingservice "github.com/dmehra2102/booking-system/internal/pricing/service"
+	reviewhandler "github.com/dmehra2102/booking-system/internal/review/handler"
+	reviewrepository "github.com/dmehra2102/booking-system/internal/review/repository"
+	reviewservice "github.com/dmehra2102/booking-system/internal/review/service"
+	statshandler "github.com/dmehra2102/booking-system/internal/stats/handler"
+	statsrepository "github.com/dmehra2102/booking-system/internal/stats/repository"
+	statsservice "github.com/dmehra2102/booking-system/internal/stats/service"
+	tenantrepository "github.com/dmehra2102/booking-system/internal/tenant/repository"
+	webhookdomain "github.com/dmehra2102/booking-system/internal/webhook/domain"
+	webhookhandler "github.com/dmehra2102/booking-system/internal/webhook/handler"
+	webhookrepository "github.com/dmehra2102/booking-system/internal/webhook/repository"
+	webhookservice "github.com/dmehra2102/booking-system/internal/webhook/service"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/grpc/bookingpb"
+	"github.com/dmehra2102/booking-system/pkg/grpc/resourcepb"
+	"github.com/dmehra2102/booking-system/pkg/grpc/userpb"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Failed to load config: %v", err))
+	}
+
+	// Initialize logger
+	log := logger.New(cfg.ServiceName, cfg.LogLevel)
+	buildinfo.LogStartup(log, cfg.Environment)
+	log.WithFields(cfg.Redacted()).Info("effective configuration")
+
+	// Components register their stop hooks as they start; they are stopped
+	// in reverse order on shutdown
+	lc := lifecycle.New(log, cfg.ShutdownTimeout, cfg.ShutdownDrainDelay)
+
+	// Apply configuration changes on SIGHUP or when the config file changes
+	watcher := bootstrap.WatchConfig(cfg, log, lc)
+
+	// Initialize tracing
+	tracerShutdown := initTracing(cfg, log)
+	lc.OnStop("tracer", func(context.Context) error {
+		tracerShutdown()
+		return nil
+	})
+
+	tracer := tracing.GetTracer(cfg.ServiceName)
+
+	// Keep values read from the secret store current
+	if secrets := cfg.Secrets(); secrets != nil {
+		stopSecrets := secrets.Watch(cfg.SecretsRefreshInterval, log)
+		lc.OnStop("secrets refresh", func(context.Context) error {
+			stopSecrets()
+			return nil
+		})
+	}
+
+	// Initialize metrics
+	metricsCollector := metrics.New(cfg.ServiceName)
+
+	// Initialize dependencies
+	db := initDatabase(cfg, log, metricsCollector, tracer)
+	lc.OnStop("postgres", lifecycle.Close(db.Close))
+
+	migrateOnly, err := bootstrap.RunMigrations(cfg, log, db)
+	if err != nil {
+		log.Error(fmt.Sprintf("Migration failed: %v", err))
+		os.Exit(1)
+	}
+	if migrateOnly {
+		lc.Shutdown()
+		return
+	}
+
+	metricsServer := bootstrap.StartMetricsServer(cfg, log, metricsCollector)
+	lc.OnStop("metrics server", metricsServer.Shutdown)
+
+	redisClient := initRedis(cfg, log, metricsCollector, tracer)
+	lc.OnStop("redis", lifecycle.Close(redisClient.Close))
+
+	revocations := auth.NewRedisRevocationStore(redisClient)
+
+	bus, err := bootstrap.MessageBus(cfg, log, metricsCollector, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to set up the message bus: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("message bus", lifecycle.Close(bus.Close))
+
+	// Health checks
+	checks := health.New(cfg.ServiceName)
+	checks.Register("shutdown", lc.Check)
+	checks.Register("postgres", db.Health)
+	checks.RegisterOptional("postgres_replicas", db.ReplicaHealth)
+	checks.Register("redis", redisClient.Health)
+	checks.RegisterOptional(cfg.MessageBus, bus.Ping)
+
+	auditRecorder := audit.NewRecorder(db, log, cfg.ServiceName)
+	lc.OnStop("audit recorder", func(context.Context) error {
+		auditRecorder.Close()
+		return nil
+	})
+
+	apiKeys := apikeyservice.NewAPIKeyService(apikeyrepository.NewPostgresAPIKeyRepository(db, tracer), log, tracer)
+
+	references, closeReferences := initReferenceValidator(cfg, log, checks)
+	lc.OnStop("grpc clients", func(context.Context) error {
+		closeReferences()
+		return nil
+	})
+
+	flags := initFeatureFlags(cfg, watcher, log, redisClient, metricsCollector)
+	stopFlags := flags.Watch(cfg.FeatureFlagsRefreshInterval, log)
+	lc.OnStop("feature flags", func(context.Context) error {
+		stopFlags()
+		return nil
+	})
+
+	// Initialize application components
+	pricingService := pricingservice.NewPricingService(pricingrepository.NewPostgresPricingRepository(db, tracer), cfg.ExchangeRates(), log, tracer)
+	pricingHandler := pricinghandler.NewPricingHandler(pricingService, log)
+
+	bookingRepo := repository.NewPostgresBookingRepository(db, tracer)
+	waitlistRepo := repository.NewPostgresWaitlistRepository(db, tracer)
+	bookingService := service.NewBookingService(
+		bookingRepo,
+		references,
+		redisClient,
+		pricingService,
+		waitlistRepo,
+		initCancellationPolicy(cfg, log),
+		domain.CheckInPolicy{Early: cfg.CheckInEarly, Grace: cfg.NoShowGrace},
+		bus,
+		log,
+		metricsCollector,
+		tracer,
+	)
+	if users, ok := references.(service.UserDirectory); ok && cfg.UserServiceGRPCAddr != "" {
+		bookingService.SetUserDirectory(users)
+	}
+	bookingHandler := handler.NewBookingHandler(bookingService, log, tracer)
+	waitlistService := service.NewWaitlistService(bookingService, waitlistRepo, cfg.WaitlistOfferTTL, bus, log, tracer)
+	waitlistHandler := handler.NewWaitlistHandler(waitlistService, log)
+	statusService := service.NewStatusService(bookingRepo, repository.NewRedisStatusStream(redisClient, log), log, tracer)
+	streamHandler := handler.NewStreamHandler(statusService, log)
+
+	var reviewRepo reviewrepository.ReviewRepository = reviewrepository.NewPostgresReviewRepository(db, tracer)
+	if cfg.CacheTTL > 0 {
+		reviewRepo = reviewrepository.NewCachedReviewRepository(reviewRepo, redisClient, cfg.CacheTTL, log, metricsCollector)
+	}
+	reviewHandler := reviewhandler.NewReviewHandler(reviewservice.NewReviewService(reviewRepo, bookingService, bus, log, tracer), log)
+
+	statsHandler := statshandler.NewStatsHandler(statsservice.NewStatsService(statsrepository.NewPostgresStatsRepository(db, tracer), log, tracer), log)
+
+	exportService := initExports(cfg, log, db, bus, tracer)
+	lc.OnStop("exports", exportService.Shutdown)
+	exportHandler := exporthandler.NewExportHandler(exportService, exportdomain.DatasetBookings, log)
+
+	webhookClient := cfg.HTTPClientConfig()
+	webhookClient.Timeout = cfg.WebhookTimeout
+	webhookClient.DisableRedirects = true
+	webhookService := webhookservice.NewWebhookService(
+		webhookrepository.NewPostgresWebhookRepository(db, tracer),
+		httpclient.New("webhooks", webhookClient, log, metricsCollector),
+		cfg.WebhookTimeout,
+		cfg.WebhookRetryPolicy(),
+		log,
+		tracer,
+	)
+	webhookHandler := webhookhandler.NewWebhookHandler(webhookService, log)
+
+	// Calendar sync is served only when an OAuth client is configured
+	var calendarService *calendarservice.CalendarService
+	var calendarHandler *calendarhandler.CalendarHandler
+	if cfg.GoogleCalendarClientID != "" {
+		calendarService = calendarservice.NewCalendarService(
+			calendarrepository.NewPostgresCalendarRepository(db, tracer),
+			calendarprovider.NewGoogleCalendar(cfg.GoogleCalendarClientID, cfg.GoogleCalendarClientSecret, httpclient.New("google-calendar", cfg.HTTPClientConfig(), log, metricsCollector)),
+			log,
+			tracer,
+		)
+		calendarHandler = calendarhandler.NewCalendarHandler(calendarService, log)
+	}
+
+	// Background jobs run on the elected leader among the replicas, once
+	// for every active tenant
+	tenants := tenantrepository.NewPostgresTenantRepository(db, tracer)
+	jobs := scheduler.New(cfg.ServiceName, redisClient, log, metricsCollector, tracer)
+	if cfg.BookingRetention > 0 {
+		jobs.Add(scheduler.PerTenant(service.RetentionJob(bookingService, log, cfg.BookingRetention, cfg.BookingRetentionInterval), tenants))
+	}
+	jobs.Add(scheduler.PerTenant(service.OfferExpiryJob(waitlistService, log, cfg.WaitlistExpiryInterval), tenants))
+	jobs.Add(scheduler.PerTenant(service.NoShowJob(bookingService, log, cfg.NoShowInterval), tenants))
+	if cfg.BookingReminderLead > 0 {
+		jobs.Add(scheduler.PerTenant(service.ReminderJob(bookingService, log, cfg.BookingReminderLead, cfg.BookingReminderInterval), tenants))
+	}
+	jobs.Add(scheduler.PerTenant(webhookservice.DeliveryJob(webhookService, log, cfg.WebhookDeliveryInterval), tenants))
+	processed := bootstrap.ProcessedStore(cfg, log, db, redisClient, jobs)
+	jobs.Start()
+	lc.OnStop("scheduler", jobs.Shutdown)
+
+	// Start gRPC server
+	grpcServer := grpcserver.New(log, cfg.ServiceTokenSecret)
+	bookingpb.RegisterBookingServiceServer(grpcServer, handler.NewBookingGRPCServer(bookingService))
+	if err := grpcserver.Serve(grpcServer, cfg.GRPCPort, log); err != nil {
+		log.Error(fmt.Sprintf("Failed to start gRPC server: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("grpc server", func(ctx context.Context) error {
+		return grpcserver.Stop(ctx, grpcServer)
+	})
+
+	// Start consumer
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := startConsumer(ctx, cfg, log, metricsCollector, tracer, bus, processed, handler.NewEventHandler(bookingService, waitlistService, service.NewPrivacyService(repository.NewPostgresPrivacyRepository(db, tracer), bus, log, tracer), log))
+	lc.OnStopWithTimeout("event consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		// Abort handlers that are still running when the drain times out
+		defer cancel()
+		return consumer.Shutdown(stopCtx)
+	})
+
+	// Webhook deliveries are queued by a consumer group of their own, so
+	// they follow every booking and payment event independently
+	webhookCtx, cancelWebhooks := context.WithCancel(context.Background())
+	webhookConsumer := startWebhookConsumer(webhookCtx, cfg, log, metricsCollector, tracer, bus, processed, webhookhandler.NewEventHandler(webhookService, log))
+	lc.OnStopWithTimeout("webhook consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		defer cancelWebhooks()
+		return webhookConsumer.Shutdown(stopCtx)
+	})
+
+	// Status updates are published by a consumer group of their own and
+	// reach the replicas streaming them over Redis
+	statusCtx, cancelStatus := context.WithCancel(context.Background())
+	statusConsumer := startStatusConsumer(statusCtx, cfg, log, metricsCollector, tracer, bus, processed, handler.NewStatusEventHandler(statusService))
+	lc.OnStopWithTimeout("status consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		defer cancelStatus()
+		return statusConsumer.Shutdown(stopCtx)
+	})
+
+	// Calendar events follow the bookings in a consumer group of their own,
+	// so a slow calendar API does not hold up the saga
+	if calendarService != nil {
+		calendarCtx, cancelCalendar := context.WithCancel(context.Background())
+		calendarConsumer := startCalendarConsumer(calendarCtx, cfg, log, metricsCollector, tracer, bus, processed, calendarhandler.NewEventHandler(calendarService))
+		lc.OnStopWithTimeout("calendar consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+			defer cancelCalendar()
+			return calendarConsumer.Shutdown(stopCtx)
+		})
+	}
+
+	// Availability is cached per resource until one of its bookings or the
+	// resource itself changes
+	availability := middleware.NewResponseCache(redisClient, metricsCollector, log, middleware.ResponseCacheRule{
+		Name:       "availability",
+		TTL:        cfg.ResponseCacheTTL,
+		ScopeParam: "id",
+	})
+	cacheCtx, cancelCache := context.WithCancel(context.Background())
+	cacheConsumer := startResponseCacheConsumer(cacheCtx, cfg, log, metricsCollector, tracer, bus, processed, handler.NewResponseCacheEventHandler(availability))
+	lc.OnStopWithTimeout("response cache consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		defer cancelCache()
+		return cacheConsumer.Shutdown(stopCtx)
+	})
+
+	// Setup router
+	router := setupRouter(cfg, watcher, log, checks, redisClient, metricsCollector, revocations, apiKeys, flags, auditRecorder, availability, bookingHandler, waitlistHandler, streamHandler, pricingHandler, reviewHandler, statsHandler, exportHandler, webhookHandler, calendarHandler)
+
+	// Start server
+	server := startServer(cfg, log, router)
+	lc.OnStopWithTimeout("http server", cfg.ShutdownHTTPTimeout, server.Shutdown)
+
+	lc.Wait()
+}
+
+// ------------------- Initialization Helpers -------------------
+
+func initTracing(cfg *config.Config, log *logger.Logger) func() {
+	tracerShutdown, err := tracing.InitTracer(cfg.ServiceName, cfg.JaegerEndpoint)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize tracer: %v", err))
+		return func() {}
+	}
+	return tracerShutdown
+}
+
+func initDatabase(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.PostgresDB {
+	db, err := database.NewPostgresDB(cfg.PostgresURL, cfg.RetryPolicy(), cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database: %v", err))
+		os.Exit(1)
+	}
+	db.SetSlowQueryThreshold(cfg.PostgresSlowQueryThreshold)
+	if err := db.ConnectReplicas(cfg.PostgresReplicaURLs, cfg.PostgresReplicaCheckInterval); err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database replicas: %v", err))
+		os.Exit(1)
+	}
+	return db
+}
+
+func initRedis(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.RedisClient {
+	redisClient, err := database.NewRedisClient(cfg.RedisURL, cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to redis: %v", err))
+		os.Exit(1)
+	}
+	return redisClient
+}
+
+// initReferenceValidator connects to the user and resource services over
+// gRPC when their addresses are configured. Without either address booking
+// references are only checked against the local database. Each connected
+// service is registered as an optional health check.
+func initReferenceValidator(cfg *config.Config, log *logger.Logger, checks *health.Registry) (service.ReferenceValidator, func()) {
+	if cfg.UserServiceGRPCAddr == "" && cfg.ResourceServiceGRPCAddr == "" {
+		return nil, func() {}
+	}
+
+	var conns []*grpc.ClientConn
+	dial := func(name, addr string) *grpc.ClientConn {
+		if addr == "" {
+			return nil
+		}
+		conn, err := grpcserver.Dial(addr, cfg.ServiceName, cfg.ServiceTokenSecret)
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to dial %s: %v", name, err))
+			os.Exit(1)
+		}
+		conns = append(conns, conn)
+		checks.RegisterOptional(name, grpcserver.HealthCheck(conn))
+		return conn
+	}
+
+	var users userpb.UserServiceClient
+	if conn := dial("user-service", cfg.UserServiceGRPCAddr); conn != nil {
+		users = userpb.NewUserServiceClient(conn)
+	}
+
+	var resources resourcepb.ResourceServiceClient
+	if conn := dial("resource-service", cfg.ResourceServiceGRPCAddr); conn != nil {
+		resources = resourcepb.NewResourceServiceClient(conn)
+	}
+
+	return clients.NewGRPCReferenceValidator(users, resources, cfg.RetryPolicy()), func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+}
+
+func initCancellationPolicy(cfg *config.Config, log *logger.Logger) domain.CancellationPolicy {
+	tiers, err := domain.ParseFeeTiers(cfg.CancellationFeeTiers)
+	if err != nil {
+		log.Error(fmt.Sprintf("Invalid CANCELLATION_FEE_TIERS: %v", err))
+		os.Exit(1)
+	}
+
+	return domain.CancellationPolicy{
+		FreeWindow: cfg.CancellationFreeWindow,
+		Tiers:      tiers,
+	}
+}
+
+// initExports returns the service writing background exports to
+// cfg.ExportDir.
+func initExports(cfg *config.Config, log *logger.Logger, db *database.PostgresDB, publisher messagebus.Publisher, tracer trace.Tracer) *exportservice.ExportService {
+	exports, err := exportservice.NewExportService(
+		exportrepository.NewPostgresExportRepository(db, tracer),
+		cfg.ExportDir,
+		cfg.ExportDownloadURL,
+		cfg.ServiceName,
+		publisher,
+		log,
+		tracer,
+	)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to create export directory: %v", err))
+		os.Exit(1)
+	}
+	return exports
+}
+
+func startConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.EventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.InventoryReleased, h.HandleInventoryReleased)
+	events.On(dispatcher, events.BookingCancelled, h.HandleBookingCancelled)
+	events.On(dispatcher, events.UserDataExportRequested, h.HandleUserDataExportRequested)
+	events.On(dispatcher, events.UserDeleted, h.HandleUserDeleted)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName,
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("event consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// startWebhookConsumer queues webhook deliveries of the events endpoints
+// can subscribe to.
+func startWebhookConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *webhookhandler.EventHandler) messagebus.Subscription {
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName + "-webhooks",
+		EventTypes:   webhookdomain.EventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, h.HandleEvent)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("webhook consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// startStatusConsumer publishes the status updates of bookings going
+// through the booking saga.
+func startStatusConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.StatusEventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.BookingRequested, h.HandleBookingRequested)
+	events.On(dispatcher, events.InventoryReserved, h.HandleInventoryReserved)
+	events.On(dispatcher, events.InventoryReservationFailed, h.HandleInventoryReservationFailed)
+	events.On(dispatcher, events.InventoryReleased, h.HandleInventoryReleased)
+	events.On(dispatcher, events.PaymentProcessed, h.HandlePaymentProcessed)
+	events.On(dispatcher, events.PaymentFailed, h.HandlePaymentFailed)
+	events.On(dispatcher, events.BookingConfirmed, h.HandleBookingConfirmed)
+	events.On(dispatcher, events.BookingUpdated, h.HandleBookingUpdated)
+	events.On(dispatcher, events.BookingCancelled, h.HandleBookingCancelled)
+	events.On(dispatcher, events.BookingCheckedIn, h.HandleBookingCheckedIn)
+	events.On(dispatcher, events.BookingCheckedOut, h.HandleBookingCheckedOut)
+	events.On(dispatcher, events.BookingNoShow, h.HandleBookingNoShow)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName + "-status",
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("status consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// startResponseCacheConsumer invalidates the cached availability of
+// resources on booking and resource events, in a consumer group of its own.
+func startResponseCacheConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.ResponseCacheEventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.BookingRequested, h.HandleBookingRequested)
+	events.On(dispatcher, events.BookingConfirmed, h.HandleBookingConfirmed)
+	events.On(dispatcher, events.BookingUpdated, h.HandleBookingUpdated)
+	events.On(dispatcher, events.BookingCancelled, h.HandleBookingCancelled)
+	events.On(dispatcher, events.BookingCheckedOut, h.HandleBookingCheckedOut)
+	events.On(dispatcher, events.BookingNoShow, h.HandleBookingNoShow)
+	events.On(dispatcher, events.ResourceUpdated, h.HandleResourceUpdated)
+	events.On(dispatcher, events.ResourceDeleted, h.HandleResourceDeleted)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName + "-response-cache",
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("response cache consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// startCalendarConsumer pushes confirmed bookings into the calendars of
+// their users and keeps the events up to date.
+func startCalendarConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *calendarhandler.EventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.BookingConfirmed, h.HandleBookingConfirmed)
+	events.On(dispatcher, events.BookingUpdated, h.HandleBookingUpdated)
+	events.On(dispatcher, events.BookingCancelled, h.HandleBookingCancelled)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName + "-calendar",
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("calendar consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// initFeatureFlags reads the feature flag rules from the provider selected
+// by FEATURE_FLAGS_PROVIDER. The service starts on the defaults when they
+// cannot be read, and picks the rules up on a later refresh.
+func initFeatureFlags(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, redisClient *database.RedisClient, m *metrics.Metrics) *featureflags.Flags {
+	var provider featureflags.Provider
+	switch cfg.FeatureFlagsProvider {
+	case "redis":
+		provider = featureflags.NewRedisProvider(redisClient, cfg.FeatureFlagsRedisKey)
+	case "unleash":
+		unleash, err := featureflags.NewUnleashProvider(cfg.UnleashURL, cfg.UnleashAPIToken, cfg.ServiceName, httpclient.New("unleash", cfg.HTTPClientConfig(), log, m))
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to create feature flag provider: %v", err))
+			os.Exit(1)
+		}
+		provider = unleash
+	default:
+		provider = featureflags.NewEnvProvider(func() string { return watcher.Current().FeatureFlags })
+	}
+
+	flags := featureflags.New(provider)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := flags.Refresh(ctx); err != nil {
+		log.WithError(err).Warn("failed to read feature flags, starting on the defaults")
+	}
+	return flags
+}
+
+// ------------------- Router Setup -------------------
+
+func setupRouter(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, checks *health.Registry, redisClient *database.RedisClient, m *metrics.Metrics, revocations auth.RevocationStore, apiKeys middleware.APIKeyAuthenticator, flags *featureflags.Flags, auditRecorder *audit.Recorder, availability *middleware.ResponseCache, bookingHandler *handler.BookingHandler, waitlistHandler *handler.WaitlistHandler, streamHandler *handler.StreamHandler, pricingHandler *pricinghandler.PricingHandler, reviewHandler *reviewhandler.ReviewHandler, statsHandler *statshandler.StatsHandler, exportHandler *exporthandler.ExportHandler, webhookHandler *webhookhandler.WebhookHandler, calendarHandler *calendarhandler.CalendarHandler) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+
+	// Bind request bodies with the same rules the OpenAPI document describes
+	binding.Validator = validation.GinValidator{}
+
+	// Global middlewares
+	router.Use(
+		middleware.RequestID(),
+		middleware.Locale(),
+		middleware.Compress(cfg.Compression, cfg.CompressionMinSize, cfg.CompressionTypes),
+		middleware.MaxBodySize(int64(cfg.MaxRequestBodySize)),
+		middleware.BodyLog(log, func() bool { return watcher.Current().DebugBodyLog }, cfg.DebugBodyLogLimit, cfg.DebugBodyLogRedact),
+		middleware.Errors(log, cfg.IsProduction()),
+		middleware.Tenant(),
+		middleware.ReadYourWrites(),
+		middleware.CORS(),
+		middleware.Recovery(log),
+		middleware.Timing(log, func() bool { return watcher.Current().DebugTimingHeader }),
+		middleware.Audit(auditRecorder),
+		middleware.Timeout(30*time.Second),
+		m.GinMiddleware(),
+		otelgin.Middleware(cfg.ServiceName),
+	)
+
+	// Health checks
+	router.GET("/health", checks.Health)
+	router.GET("/ready", checks.Ready)
+
+	// Metrics are served on METRICS_PORT; this keeps old scrape configs working
+	if cfg.MetricsOnAPIPort {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+
+	if cfg.DocsEnabled {
+		openapi.Register(router, docs.BookingSpec)
+	}
+
+	// API routes
+	api := router.Group("/api/v1")
+	apiLimit := middleware.NewRateLimiter(redisClient, m, log, middleware.RateLimitRule{
+		Name:   "api",
+		Limit:  cfg.RateLimitRequests,
+		Window: cfg.RateLimitWindow,
+	})
+	watcher.OnChange(func(cfg *config.Config) {
+		apiLimit.SetLimit(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	})
+	api.Use(apiLimit.Handler())
+	{
+		api.GET("/resources/:id/availability", middleware.UUIDParams("id"), availability.Handler(), bookingHandler.GetAvailability)
+		api.GET("/resources/:id/reviews", middleware.UUIDParams("id"), reviewHandler.ListReviews)
+		api.GET("/resources/:id/rating", middleware.UUIDParams("id"), reviewHandler.GetRating)
+
+		protected := api.Group("")
+		protected.Use(middleware.APIKeyAuth(apiKeys, middleware.AuthMiddleware(cfg.JWTSecret, revocations)), middleware.FeatureFlags(flags), middleware.UUIDParams("id"))
+		{
+			protected.POST("/bookings", bookingHandler.CreateBooking)
+			protected.POST("/bookings/quote", middleware.RequireFeature(featureflags.PricingEngine), pricingHandler.Quote)
+			protected.POST("/bookings/series", bookingHandler.CreateSeries)
+			protected.GET("/bookings/series/:id", bookingHandler.GetSeries)
+			protected.POST("/bookings/series/:id/cancel", bookingHandler.CancelSeries)
+			protected.GET("/bookings", bookingHandler.ListBookings)
+			protected.GET("/bookings/:id", bookingHandler.GetBooking)
+			protected.GET("/bookings/:id/confirmation.pdf", bookingHandler.GetConfirmationPDF)
+			protected.GET("/bookings/:id/confirmation.ics", bookingHandler.GetConfirmationICS)
+			protected.GET("/bookings/:id/stream", streamHandler.StreamStatus)
+			protected.PUT("/bookings/:id", bookingHandler.UpdateBooking)
+			protected.POST("/bookings/:id/cancel", bookingHandler.CancelBooking)
+			protected.POST("/bookings/:id/check-in", bookingHandler.CheckIn)
+			protected.POST("/bookings/:id/check-out", bookingHandler.CheckOut)
+			protected.POST("/bookings/:id/comments", bookingHandler.AddComment)
+			protected.GET("/bookings/:id/comments", bookingHandler.ListComments)
+			protected.GET("/users/:id/bookings", bookingHandler.ListUserBookings)
+			protected.GET("/resources/:id/rate-card", pricingHandler.GetRateCard)
+			protected.POST("/reviews", reviewHandler.CreateReview)
+			protected.POST("/waitlist", waitlistHandler.JoinWaitlist)
+			protected.GET("/waitlist", waitlistHandler.ListWaitlist)
+			protected.DELETE("/waitlist/:id", waitlistHandler.LeaveWaitlist)
+		}
+
+		if calendarHandler != nil {
+			protected.PUT("/calendar", calendarHandler.Connect)
+			protected.GET("/calendar", calendarHandler.GetConnection)
+			protected.PATCH("/calendar", calendarHandler.UpdateConnection)
+			protected.DELETE("/calendar", calendarHandler.Disconnect)
+		}
+
+		admin := protected.Group("")
+		admin.Use(middleware.RequireRole(auth.RoleAdmin))
+		{
+			admin.DELETE("/bookings/:id", bookingHandler.DeleteBooking)
+			admin.POST("/bookings/:id/restore", bookingHandler.RestoreBooking)
+			admin.PUT("/resources/:id/rate-card", pricingHandler.UpdateRateCard)
+			admin.POST("/promo-codes", pricingHandler.CreatePromoCode)
+			admin.GET("/admin/stats/bookings", statsHandler.Bookings)
+			admin.GET("/admin/stats/revenue", statsHandler.Revenue)
+			admin.GET("/admin/stats/cancellations", statsHandler.Cancellations)
+			admin.GET("/admin/stats/users", statsHandler.Users)
+			admin.GET("/admin/stats/occupancy", statsHandler.Occupancy)
+			admin.GET("/admin/bookings/export", exportHandler.Export(bookingHandler.ExportSource))
+			admin.GET("/admin/bookings/exports/:id", exportHandler.GetExport)
+			admin.GET("/admin/bookings/exports/:id/download", exportHandler.DownloadExport)
+			admin.POST("/admin/webhooks", webhookHandler.CreateEndpoint)
+			admin.GET("/admin/webhooks", webhookHandler.ListEndpoints)
+			admin.GET("/admin/webhooks/:id", webhookHandler.GetEndpoint)
+			admin.PUT("/admin/webhooks/:id", webhookHandler.UpdateEndpoint)
+			admin.DELETE("/admin/webhooks/:id", webhookHandler.DeleteEndpoint)
+			admin.GET("/admin/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+			admin.GET("/admin/webhook-deliveries/:id", webhookHandler.GetDelivery)
+			admin.POST("/admin/webhook-deliveries/:id/redeliver", webhookHandler.Redeliver)
+		}
+	}
+
+	return router
+}
+
+// startServer serves router in the background. Stopping the returned
+// server is left to the lifecycle manager.
+func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) *http.Server {
+	server := &http.Server{
+		Addr:    ":" + cfg.ServicePort,
+		Handler: router,
+	}
+
+	go func() {
+		log.Info(fmt.Sprintf("🚀 Starting %s on port %s", cfg.ServiceName, cfg.ServicePort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(fmt.Sprintf("Failed to start server: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/gateway"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Failed to load config: %v", err))
+	}
+
+	// Initialize logger
+	log := logger.New(cfg.ServiceName, cfg.LogLevel)
+	buildinfo.LogStartup(log, cfg.Environment)
+	log.WithFields(cfg.Redacted()).Info("effective configuration")
+
+	// Components register their stop hooks as they start; they are stopped
+	// in reverse order on shutdown
+	lc := lifecycle.New(log, cfg.ShutdownTimeout, cfg.ShutdownDrainDelay)
+
+	// Apply configuration changes on SIGHUP or when the config file changes
+	watcher := bootstrap.WatchConfig(cfg, log, lc)
+
+	// Initialize tracing
+	tracerShutdown := initTracing(cfg, log)
+	lc.OnStop("tracer", func(context.Context) error {
+		tracerShutdown()
+		return nil
+	})
+
+	tracer := tracing.GetTracer(cfg.ServiceName)
+
+	// Keep values read from the secret store current
+	if secrets := cfg.Secrets(); secrets != nil {
+		stopSecrets := secrets.Watch(cfg.SecretsRefreshInterval, log)
+		lc.OnStop("secrets refresh", func(context.Context) error {
+			stopSecrets()
+			return nil
+		})
+	}
+
+	// Initialize metrics
+	metricsCollector := metrics.New(cfg.ServiceName)
+
+	// Initialize dependencies
+	metricsServer := bootstrap.StartMetricsServer(cfg, log, metricsCollector)
+	lc.OnStop("metrics server", metricsServer.Shutdown)
+
+	redisClient := initRedis(cfg, log, metricsCollector, tracer)
+	lc.OnStop("redis", lifecycle.Close(redisClient.Close))
+
+	revocations := auth.NewRedisRevocationStore(redisClient)
+
+	// Health checks
+	checks := health.New(cfg.ServiceName)
+	checks.Register("shutdown", lc.Check)
+	checks.Register("redis", redisClient.Health)
+
+	// Proxied requests are sent once: their bodies cannot be replayed and
+	// clients retry through the gateway themselves. Responses are relayed
+	// as they arrive, so event streams and downloads are not cut off.
+	proxyConfig := cfg.HTTPClientConfig()
+	proxyConfig.Retry.MaxAttempts = 1
+	proxyConfig.DisableRedirects = true
+	proxyConfig.Streaming = true
+	proxyClient := httpclient.New("gateway", proxyConfig, log, metricsCollector)
+	servicesClient := httpclient.New("services", cfg.HTTPClientConfig(), log, metricsCollector)
+
+	upstreams := map[string]string{
+		gateway.UserService:      cfg.UserServiceURL,
+		gateway.ResourceService:  cfg.ResourceServiceURL,
+		gateway.BookingService:   cfg.BookingServiceURL,
+		gateway.InventoryService: cfg.InventoryServiceURL,
+		gateway.PaymentService:   cfg.PaymentServiceURL,
+	}
+	for service, baseURL := range upstreams {
+		if baseURL != "" {
+			checks.RegisterOptional(service+"-service", gateway.HealthCheck(servicesClient, baseURL))
+		}
+	}
+
+	// Initialize application components
+	proxy, err := gateway.NewProxy(gateway.Routes, upstreams, proxyClient, log)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to configure proxy: %v", err))
+		os.Exit(1)
+	}
+	dashboardHandler := gateway.NewDashboardHandler(servicesClient, cfg.UserServiceURL, cfg.BookingServiceURL, log)
+
+	// Setup router
+	router := setupRouter(cfg, watcher, log, checks, redisClient, metricsCollector, revocations, proxy, dashboardHandler)
+
+	// Start server
+	server := startServer(cfg, log, router)
+	lc.OnStopWithTimeout("http server", cfg.ShutdownHTTPTimeout, server.Shutdown)
+
+	lc.Wait()
+}
+
+// ------------------- Initialization Helpers -------------------
+
+func initTracing(cfg *config.Config, log *logger.Logger) func() {
+	tracerShutdown, err := tracing.InitTracer(cfg.ServiceName, cfg.JaegerEndpoint)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize tracer: %v", err))
+		return func() {}
+	}
+	return tracerShutdown
+}
+
+func initRedis(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.RedisClient {
+	redisClient, err := database.NewRedisClient(cfg.RedisURL, cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to redis: %v", err))
+		os.Exit(1)
+	}
+	return redisClient
+}
+
+// ------------------- Router Setup -------------------
+
+func setupRouter(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, checks *health.Registry, redisClient *database.RedisClient, m *metrics.Metrics, revocations auth.RevocationStore, proxy *gateway.Proxy, dashboardHandler *gateway.DashboardHandler) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+
+	// Global middlewares
+	router.Use(
+		middleware.RequestID(),
+		middleware.Locale(),
+		middleware.MaxBodySize(int64(cfg.MaxRequestBodySize)),
+		middleware.Errors(log, cfg.IsProduction()),
+		middleware.Tenant(),
+		middleware.AccessLog(log),
+		middleware.CORS(),
+		middleware.Recovery(log),
+		middleware.Timeout(30*time.Second),
+		m.GinMiddleware(),
+		otelgin.Middleware(cfg.ServiceName),
+	)
+
+	// Health checks
+	router.GET("/health", checks.Health)
+	router.GET("/ready", checks.Ready)
+
+	// Metrics are served on METRICS_PORT; this keeps old scrape configs working
+	if cfg.MetricsOnAPIPort {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+
+	// Tokens are verified here once for all services, which check them
+	// again; requests are limited per user, or per client IP without a
+	// token
+	apiLimit := middleware.NewRateLimiter(redisClient, m, log, middleware.RateLimitRule{
+		Name:   "gateway",
+		Limit:  cfg.RateLimitRequests,
+		Window: cfg.RateLimitWindow,
+	})
+	watcher.OnChange(func(cfg *config.Config) {
+		apiLimit.SetLimit(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	})
+
+	router.GET("/api/v1/me/dashboard",
+		middleware.AuthMiddleware(cfg.JWTSecret, revocations),
+		apiLimit.Handler(),
+		dashboardHandler.GetDashboard,
+	)
+
+	// Everything else is proxied to the service owning the path
+	router.NoRoute(
+		middleware.VerifyTokenMiddleware(cfg.JWTSecret, revocations),
+		apiLimit.Handler(),
+		proxy.Handle,
+	)
+
+	return router
+}
+
+// startServer serves router in the background. Stopping the returned
+// server is left to the lifecycle manager.
+func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) *http.Server {
+	server := &http.Server{
+		Addr:    ":" + cfg.ServicePort,
+		Handler: router,
+	}
+
+	go func() {
+		log.Info(fmt.Sprintf("🚀 Starting %s on port %s", cfg.ServiceName, cfg.ServicePort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(fmt.Sprintf("Failed to start server: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
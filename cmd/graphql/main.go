@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/graphql/clients"
+	"github.com/dmehra2102/booking-system/internal/graphql/handler"
+	"github.com/dmehra2102/booking-system/internal/graphql/resolver"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/grpc/bookingpb"
+	"github.com/dmehra2102/booking-system/pkg/grpc/resourcepb"
+	"github.com/dmehra2102/booking-system/pkg/grpc/userpb"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Failed to load config: %v", err))
+	}
+
+	// Initialize logger
+	log := logger.New(cfg.ServiceName, cfg.LogLevel)
+	buildinfo.LogStartup(log, cfg.Environment)
+	log.WithFields(cfg.Redacted()).Info("effective configuration")
+
+	// Components register their stop hooks as they start; they are stopped
+	// in reverse order on shutdown
+	lc := lifecycle.New(log, cfg.ShutdownTimeout, cfg.ShutdownDrainDelay)
+
+	// Apply configuration changes on SIGHUP or when the config file changes
+	watcher := bootstrap.WatchConfig(cfg, log, lc)
+
+	// Initialize tracing
+	tracerShutdown := initTracing(cfg, log)
+	lc.OnStop("tracer", func(context.Context) error {
+		tracerShutdown()
+		return nil
+	})
+
+	tracer := tracing.GetTracer(cfg.ServiceName)
+
+	// Keep values read from the secret store current
+	if secrets := cfg.Secrets(); secrets != nil {
+		stopSecrets := secrets.Watch(cfg.SecretsRefreshInterval, log)
+		lc.OnStop("secrets refresh", func(context.Context) error {
+			stopSecrets()
+			return nil
+		})
+	}
+
+	// Initialize metrics
+	metricsCollector := metrics.New(cfg.ServiceName)
+
+	// Initialize dependencies
+	metricsServer := bootstrap.StartMetricsServer(cfg, log, metricsCollector)
+	lc.OnStop("metrics server", metricsServer.Shutdown)
+
+	redisClient := initRedis(cfg, log, metricsCollector, tracer)
+	lc.OnStop("redis", lifecycle.Close(redisClient.Close))
+
+	revocations := auth.NewRedisRevocationStore(redisClient)
+
+	// Health checks
+	checks := health.New(cfg.ServiceName)
+	checks.Register("shutdown", lc.Check)
+	checks.Register("redis", redisClient.Health)
+
+	services, closeServices := initServices(cfg, log, metricsCollector, checks)
+	lc.OnStop("grpc clients", func(context.Context) error {
+		closeServices()
+		return nil
+	})
+
+	// Initialize application components
+	root := resolver.New(services)
+	schema, err := resolver.Schema(root, cfg.GraphQLMaxDepth)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to parse GraphQL schema: %v", err))
+		os.Exit(1)
+	}
+	graphqlHandler := handler.NewGraphQLHandler(schema, root, redisClient, cfg.GraphQLPersistedQueryTTL, log)
+
+	// Setup router
+	router := setupRouter(cfg, watcher, log, checks, redisClient, metricsCollector, revocations, graphqlHandler)
+
+	// Start server
+	server := startServer(cfg, log, router)
+	lc.OnStopWithTimeout("http server", cfg.ShutdownHTTPTimeout, server.Shutdown)
+
+	lc.Wait()
+}
+
+// ------------------- Initialization Helpers -------------------
+
+func initTracing(cfg *config.Config, log *logger.Logger) func() {
+	tracerShutdown, err := tracing.InitTracer(cfg.ServiceName, cfg.JaegerEndpoint)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize tracer: %v", err))
+		return func() {}
+	}
+	return tracerShutdown
+}
+
+func initRedis(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.RedisClient {
+	redisClient, err := database.NewRedisClient(cfg.RedisURL, cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to redis: %v", err))
+		os.Exit(1)
+	}
+	return redisClient
+}
+
+// initServices connects to the user, resource and booking services over
+// gRPC, each registered as an optional health check, and reaches their
+// HTTP APIs for lists.
+func initServices(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, checks *health.Registry) (*clients.Services, func()) {
+	var conns []*grpc.ClientConn
+	dial := func(name, addr string) *grpc.ClientConn {
+		if addr == "" {
+			log.Error(fmt.Sprintf("The gRPC address of %s is not configured", name))
+			os.Exit(1)
+		}
+		conn, err := grpcserver.Dial(addr, cfg.ServiceName, cfg.ServiceTokenSecret)
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to dial %s: %v", name, err))
+			os.Exit(1)
+		}
+		conns = append(conns, conn)
+		checks.RegisterOptional(name, grpcserver.HealthCheck(conn))
+		return conn
+	}
+
+	users := userpb.NewUserServiceClient(dial("user-service", cfg.UserServiceGRPCAddr))
+	resources := resourcepb.NewResourceServiceClient(dial("resource-service", cfg.ResourceServiceGRPCAddr))
+	bookings := bookingpb.NewBookingServiceClient(dial("booking-service", cfg.BookingServiceGRPCAddr))
+
+	services := clients.NewServices(users, resources, bookings, httpclient.New("services", cfg.HTTPClientConfig(), log, m), clients.Config{
+		BookingServiceURL:  cfg.BookingServiceURL,
+		ResourceServiceURL: cfg.ResourceServiceURL,
+	}, cfg.RetryPolicy())
+
+	return services, func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+}
+
+// ------------------- Router Setup -------------------
+
+func setupRouter(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, checks *health.Registry, redisClient *database.RedisClient, m *metrics.Metrics, revocations auth.RevocationStore, graphqlHandler *handler.GraphQLHandler) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+
+	// Global middlewares
+	router.Use(
+		middleware.RequestID(),
+		middleware.Locale(),
+		middleware.Compress(cfg.Compression, cfg.CompressionMinSize, cfg.CompressionTypes),
+		middleware.MaxBodySize(int64(cfg.MaxRequestBodySize)),
+		middleware.BodyLog(log, func() bool { return watcher.Current().DebugBodyLog }, cfg.DebugBodyLogLimit, cfg.DebugBodyLogRedact),
+		middleware.Errors(log, cfg.IsProduction()),
+		middleware.Tenant(),
+		middleware.CORS(),
+		middleware.Recovery(log),
+		middleware.Timeout(30*time.Second),
+		m.GinMiddleware(),
+		otelgin.Middleware(cfg.ServiceName),
+	)
+
+	// Health checks
+	router.GET("/health", checks.Health)
+	router.GET("/ready", checks.Ready)
+
+	// Metrics are served on METRICS_PORT; this keeps old scrape configs working
+	if cfg.MetricsOnAPIPort {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+
+	// Queries run anonymously without a token; fields of users and
+	// bookings require one
+	apiLimit := middleware.NewRateLimiter(redisClient, m, log, middleware.RateLimitRule{
+		Name:   "graphql",
+		Limit:  cfg.RateLimitRequests,
+		Window: cfg.RateLimitWindow,
+	})
+	watcher.OnChange(func(cfg *config.Config) {
+		apiLimit.SetLimit(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	})
+	graphql := router.Group("/graphql")
+	graphql.Use(apiLimit.Handler(), middleware.OptionalAuthMiddleware(cfg.JWTSecret, revocations))
+	{
+		graphql.POST("", graphqlHandler.Query)
+		graphql.GET("", graphqlHandler.Query)
+	}
+
+	return router
+}
+
+// startServer serves router in the background. Stopping the returned
+// server is left to the lifecycle manager.
+func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) *http.Server {
+	server := &http.Server{
+		Addr:    ":" + cfg.ServicePort,
+		Handler: router,
+	}
+
+	go func() {
+		log.Info(fmt.Sprintf("🚀 Starting %s on port %s", cfg.ServiceName, cfg.ServicePort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(fmt.Sprintf("Failed to start server: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
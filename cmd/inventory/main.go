@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/inventory/handler"
+	"github.com/dmehra2102/booking-system/internal/inventory/repository"
+	"github.com/dmehra2102/booking-system/internal/inventory/service"
+	tenantrepository "github.com/dmehra2102/booking-system/internal/tenant/repository"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/grpc/inventorypb"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Failed to load config: %v", err))
+	}
+
+	// Initialize logger
+	log := logger.New(cfg.ServiceName, cfg.LogLevel)
+	buildinfo.LogStartup(log, cfg.Environment)
+	log.WithFields(cfg.Redacted()).Info("effective configuration")
+
+	// Components register their stop hooks as they start; they are stopped
+	// in reverse order on shutdown
+	lc := lifecycle.New(log, cfg.ShutdownTimeout, cfg.ShutdownDrainDelay)
+
+	// Apply configuration changes on SIGHUP or when the config file changes
+	watcher := bootstrap.WatchConfig(cfg, log, lc)
+
+	// Initialize tracing
+	tracerShutdown := initTracing(cfg, log)
+	lc.OnStop("tracer", func(context.Context) error {
+		tracerShutdown()
+		return nil
+	})
+
+	tracer := tracing.GetTracer(cfg.ServiceName)
+
+	// Keep values read from the secret store current
+	if secrets := cfg.Secrets(); secrets != nil {
+		stopSecrets := secrets.Watch(cfg.SecretsRefreshInterval, log)
+		lc.OnStop("secrets refresh", func(context.Context) error {
+			stopSecrets()
+			return nil
+		})
+	}
+
+	// Initialize metrics
+	metricsCollector := metrics.New(cfg.ServiceName)
+
+	// Initialize dependencies
+	db := initDatabase(cfg, log, metricsCollector, tracer)
+	lc.OnStop("postgres", lifecycle.Close(db.Close))
+
+	migrateOnly, err := bootstrap.RunMigrations(cfg, log, db)
+	if err != nil {
+		log.Error(fmt.Sprintf("Migration failed: %v", err))
+		os.Exit(1)
+	}
+	if migrateOnly {
+		lc.Shutdown()
+		return
+	}
+
+	metricsServer := bootstrap.StartMetricsServer(cfg, log, metricsCollector)
+	lc.OnStop("metrics server", metricsServer.Shutdown)
+
+	bus, err := bootstrap.MessageBus(cfg, log, metricsCollector, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to set up the message bus: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("message bus", lifecycle.Close(bus.Close))
+
+	// Health checks
+	checks := health.New(cfg.ServiceName)
+	checks.Register("shutdown", lc.Check)
+	checks.Register("postgres", db.Health)
+	checks.RegisterOptional("postgres_replicas", db.ReplicaHealth)
+	checks.Register(cfg.MessageBus, bus.Ping)
+
+	// Initialize application components
+	inventoryRepo := repository.NewPostgresInventoryRepository(db, tracer)
+	inventoryService := service.NewInventoryService(
+		inventoryRepo,
+		bus,
+		log,
+		metricsCollector,
+		tracer,
+		cfg.ReservationHoldTTL,
+	)
+	eventHandler := handler.NewEventHandler(inventoryService, log)
+
+	// Release holds that were not paid in time. Every replica runs the job;
+	// a hold is only released, and its release published, once.
+	jobs := scheduler.New(cfg.ServiceName, nil, log, metricsCollector, tracer)
+	if cfg.ReservationHoldTTL > 0 {
+		jobs.Add(scheduler.PerTenant(service.HoldExpiryJob(inventoryService, cfg.ReservationExpiryInterval), tenantrepository.NewPostgresTenantRepository(db, tracer)))
+	}
+	processed := bootstrap.ProcessedStore(cfg, log, db, nil, jobs)
+	jobs.Start()
+	lc.OnStop("scheduler", jobs.Shutdown)
+
+	// Start gRPC server
+	grpcServer := grpcserver.New(log, cfg.ServiceTokenSecret)
+	inventorypb.RegisterInventoryServiceServer(grpcServer, handler.NewInventoryGRPCServer(inventoryService))
+	if err := grpcserver.Serve(grpcServer, cfg.GRPCPort, log); err != nil {
+		log.Error(fmt.Sprintf("Failed to start gRPC server: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("grpc server", func(ctx context.Context) error {
+		return grpcserver.Stop(ctx, grpcServer)
+	})
+
+	// Start consumer
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := startConsumer(ctx, cfg, log, metricsCollector, tracer, bus, processed, eventHandler)
+	lc.OnStopWithTimeout("event consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		// Abort handlers that are still running when the drain times out
+		defer cancel()
+		return consumer.Shutdown(stopCtx)
+	})
+
+	// Setup router
+	router := setupRouter(cfg, watcher, log, checks, metricsCollector)
+
+	// Start server
+	server := startServer(cfg, log, router)
+	lc.OnStopWithTimeout("http server", cfg.ShutdownHTTPTimeout, server.Shutdown)
+
+	lc.Wait()
+}
+
+// ------------------- Initialization Helpers -------------------
+
+func initTracing(cfg *config.Config, log *logger.Logger) func() {
+	tracerShutdown, err := tracing.InitTracer(cfg.ServiceName, cfg.JaegerEndpoint)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize tracer: %v", err))
+		return func() {}
+	}
+	return tracerShutdown
+}
+
+func initDatabase(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.PostgresDB {
+	db, err := database.NewPostgresDB(cfg.PostgresURL, cfg.RetryPolicy(), cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database: %v", err))
+		os.Exit(1)
+	}
+	db.SetSlowQueryThreshold(cfg.PostgresSlowQueryThreshold)
+	if err := db.ConnectReplicas(cfg.PostgresReplicaURLs, cfg.PostgresReplicaCheckInterval); err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database replicas: %v", err))
+		os.Exit(1)
+	}
+	return db
+}
+
+func startConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.EventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.BookingRequested, h.HandleBookingRequested)
+	events.On(dispatcher, events.BookingCancelled, h.HandleBookingCancelled)
+	events.On(dispatcher, events.PaymentProcessed, h.HandlePaymentProcessed)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName,
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("event consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// ------------------- Router Setup -------------------
+
+func setupRouter(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, checks *health.Registry, m *metrics.Metrics) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+
+	router.Use(
+		middleware.RequestID(),
+		middleware.Locale(),
+		middleware.Compress(cfg.Compression, cfg.CompressionMinSize, cfg.CompressionTypes),
+		middleware.MaxBodySize(int64(cfg.MaxRequestBodySize)),
+		middleware.BodyLog(log, func() bool { return watcher.Current().DebugBodyLog }, cfg.DebugBodyLogLimit, cfg.DebugBodyLogRedact),
+		middleware.Errors(log, cfg.IsProduction()),
+		middleware.Tenant(),
+		middleware.ReadYourWrites(),
+		m.GinMiddleware(),
+	)
+
+	// Health checks
+	router.GET("/health", checks.Health)
+	router.GET("/ready", checks.Ready)
+
+	// Metrics are served on METRICS_PORT; this keeps old scrape configs working
+	if cfg.MetricsOnAPIPort {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+
+	return router
+}
+
+// startServer serves router in the background. Stopping the returned
+// server is left to the lifecycle manager.
+func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) *http.Server {
+	server := &http.Server{
+		Addr:    ":" + cfg.ServicePort,
+		Handler: router,
+	}
+
+	go func() {
+		log.Info(fmt.Sprintf("🚀 Starting %s on port %s", cfg.ServiceName, cfg.ServicePort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(fmt.Sprintf("Failed to start server: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+	"github.com/dmehra2102/booking-system/internal/notification/handler"
+	"github.com/dmehra2102/booking-system/internal/notification/repository"
+	"github.com/dmehra2102/booking-system/internal/notification/sender"
+	"github.com/dmehra2102/booking-system/internal/notification/service"
+	"github.com/dmehra2102/booking-system/internal/notification/templates"
+	"github.com/dmehra2102/booking-system/pkg/crypto"
+	"github.com/dmehra2102/booking-system/pkg/events"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Failed to load config: %v", err))
+	}
+
+	// Initialize logger
+	log := logger.New(cfg.ServiceName, cfg.LogLevel)
+	buildinfo.LogStartup(log, cfg.Environment)
+	log.WithFields(cfg.Redacted()).Info("effective configuration")
+
+	// Components register their stop hooks as they start; they are stopped
+	// in reverse order on shutdown
+	lc := lifecycle.New(log, cfg.ShutdownTimeout, cfg.ShutdownDrainDelay)
+
+	// Apply configuration changes on SIGHUP or when the config file changes
+	watcher := bootstrap.WatchConfig(cfg, log, lc)
+
+	// Initialize tracing
+	tracerShutdown := initTracing(cfg, log)
+	lc.OnStop("tracer", func(context.Context) error {
+		tracerShutdown()
+		return nil
+	})
+
+	tracer := tracing.GetTracer(cfg.ServiceName)
+
+	// Keep values read from the secret store current
+	if secrets := cfg.Secrets(); secrets != nil {
+		stopSecrets := secrets.Watch(cfg.SecretsRefreshInterval, log)
+		lc.OnStop("secrets refresh", func(context.Context) error {
+			stopSecrets()
+			return nil
+		})
+	}
+
+	// Initialize metrics
+	metricsCollector := metrics.New(cfg.ServiceName)
+
+	// Initialize dependencies
+	db := initDatabase(cfg, log, metricsCollector, tracer)
+	lc.OnStop("postgres", lifecycle.Close(db.Close))
+
+	migrateOnly, err := bootstrap.RunMigrations(cfg, log, db)
+	if err != nil {
+		log.Error(fmt.Sprintf("Migration failed: %v", err))
+		os.Exit(1)
+	}
+	if migrateOnly {
+		lc.Shutdown()
+		return
+	}
+
+	metricsServer := bootstrap.StartMetricsServer(cfg, log, metricsCollector)
+	lc.OnStop("metrics server", metricsServer.Shutdown)
+
+	bus, err := bootstrap.MessageBus(cfg, log, metricsCollector, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to set up the message bus: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("message bus", lifecycle.Close(bus.Close))
+
+	// Health checks
+	checks := health.New(cfg.ServiceName)
+	checks.Register("shutdown", lc.Check)
+	checks.Register("postgres", db.Health)
+	checks.RegisterOptional("postgres_replicas", db.ReplicaHealth)
+	checks.Register(cfg.MessageBus, bus.Ping)
+
+	// Initialize application components
+	renderer, err := templates.NewRenderer()
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to load notification templates: %v", err))
+		os.Exit(1)
+	}
+
+	kms, err := crypto.NewLocalKMS(cfg.FieldEncryptionKeys)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize field encryption: %v", err))
+		os.Exit(1)
+	}
+	notificationRepo := repository.NewPostgresNotificationRepository(db, crypto.NewEnvelope(kms), crypto.NewBlindIndex(cfg.FieldIndexKey), tracer)
+	notificationService := service.NewNotificationService(
+		notificationRepo,
+		renderer,
+		initChannels(cfg, log, metricsCollector, notificationRepo),
+		bus,
+		log,
+		metricsCollector,
+		tracer,
+	)
+	eventHandler := handler.NewEventHandler(notificationService, log)
+
+	var twilioHandler *handler.TwilioWebhookHandler
+	if cfg.TwilioAccountSID != "" && cfg.TwilioWebhookBaseURL != "" {
+		twilioHandler = handler.NewTwilioWebhookHandler(notificationService, cfg.TwilioAuthToken, cfg.TwilioWebhookBaseURL, log)
+	}
+
+	// Purge handled events that expired. Every replica runs the job
+	jobs := scheduler.New(cfg.ServiceName, nil, log, metricsCollector, tracer)
+	processed := bootstrap.ProcessedStore(cfg, log, db, nil, jobs)
+	jobs.Start()
+	lc.OnStop("scheduler", jobs.Shutdown)
+
+	// Start consumer
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := startConsumer(ctx, cfg, log, metricsCollector, tracer, bus, processed, eventHandler)
+	lc.OnStopWithTimeout("event consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		// Abort handlers that are still running when the drain times out
+		defer cancel()
+		return consumer.Shutdown(stopCtx)
+	})
+
+	// Setup router
+	router := setupRouter(cfg, watcher, log, checks, metricsCollector, twilioHandler)
+
+	// Start server
+	server := startServer(cfg, log, router)
+	lc.OnStopWithTimeout("http server", cfg.ShutdownHTTPTimeout, server.Shutdown)
+
+	lc.Wait()
+}
+
+// ------------------- Initialization Helpers -------------------
+
+func initTracing(cfg *config.Config, log *logger.Logger) func() {
+	tracerShutdown, err := tracing.InitTracer(cfg.ServiceName, cfg.JaegerEndpoint)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize tracer: %v", err))
+		return func() {}
+	}
+	return tracerShutdown
+}
+
+// initEmailSender returns the SMTP sender, switching to rotated
+// credentials when the secret store changes them.
+func initEmailSender(cfg *config.Config) *sender.SMTPSender {
+	smtpSender := sender.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+
+	if secrets := cfg.Secrets(); secrets != nil {
+		secrets.OnChange(func(changed map[string]string) {
+			username, usernameChanged := changed["SMTP_USERNAME"]
+			password, passwordChanged := changed["SMTP_PASSWORD"]
+			if !usernameChanged && !passwordChanged {
+				return
+			}
+
+			if !usernameChanged {
+				username, _ = secrets.Lookup("SMTP_USERNAME")
+			}
+			if !passwordChanged {
+				password, _ = secrets.Lookup("SMTP_PASSWORD")
+			}
+			smtpSender.SetCredentials(username, password)
+		})
+	}
+
+	return smtpSender
+}
+
+// initChannels returns the channels notifications are delivered over:
+// email, and text messages and push notifications when their providers
+// are configured.
+func initChannels(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, store *repository.PostgresNotificationRepository) []sender.Channel {
+	channels := []sender.Channel{sender.NewEmailChannel(initEmailSender(cfg))}
+
+	if cfg.TwilioAccountSID != "" {
+		statusCallback := ""
+		if cfg.TwilioWebhookBaseURL != "" {
+			statusCallback = strings.TrimSuffix(cfg.TwilioWebhookBaseURL, "/") + handler.TwilioStatusPath
+		}
+
+		client := httpclient.New("twilio", cfg.HTTPClientConfig(), log, m)
+		twilio := sender.NewTwilioSender(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFrom, statusCallback, client)
+		channels = append(channels, sender.NewSMSChannel(twilio, store))
+	}
+
+	pushSenders := make(map[domain.Platform]sender.PushSender)
+	if cfg.FCMCredentialsFile != "" {
+		credentials, err := os.ReadFile(cfg.FCMCredentialsFile)
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to read FCM credentials: %v", err))
+			os.Exit(1)
+		}
+		fcm, err := sender.NewFCMSender(credentials, httpclient.New("fcm", cfg.HTTPClientConfig(), log, m))
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to initialize FCM: %v", err))
+			os.Exit(1)
+		}
+		pushSenders[domain.PlatformAndroid] = fcm
+		pushSenders[domain.PlatformWeb] = fcm
+		pushSenders[domain.PlatformIOS] = fcm
+	}
+	if cfg.APNsKeyFile != "" {
+		key, err := os.ReadFile(cfg.APNsKeyFile)
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to read APNs key: %v", err))
+			os.Exit(1)
+		}
+		apns, err := sender.NewAPNsSender(sender.APNsConfig{
+			Key:        key,
+			KeyID:      cfg.APNsKeyID,
+			TeamID:     cfg.APNsTeamID,
+			Topic:      cfg.APNsTopic,
+			Production: cfg.APNsProduction,
+		}, httpclient.New("apns", cfg.HTTPClientConfig(), log, m))
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to initialize APNs: %v", err))
+			os.Exit(1)
+		}
+		pushSenders[domain.PlatformIOS] = apns
+	}
+	if len(pushSenders) > 0 {
+		channels = append(channels, sender.NewPushChannel(pushSenders, store, log))
+	}
+
+	return channels
+}
+
+func initDatabase(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.PostgresDB {
+	db, err := database.NewPostgresDB(cfg.PostgresURL, cfg.RetryPolicy(), cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database: %v", err))
+		os.Exit(1)
+	}
+	db.SetSlowQueryThreshold(cfg.PostgresSlowQueryThreshold)
+	if err := db.ConnectReplicas(cfg.PostgresReplicaURLs, cfg.PostgresReplicaCheckInterval); err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database replicas: %v", err))
+		os.Exit(1)
+	}
+	return db
+}
+
+func startConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.EventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.UserCreated, h.HandleUserCreated)
+	events.On(dispatcher, events.UserUpdated, h.HandleUserUpdated)
+	events.On(dispatcher, events.UserDeleted, h.HandleUserDeleted)
+	events.On(dispatcher, events.UserVerificationRequested, h.HandleVerificationRequested)
+	events.On(dispatcher, events.UserPasswordResetRequested, h.HandlePasswordResetRequested)
+	events.On(dispatcher, events.UserNewDeviceLogin, h.HandleNewDeviceLogin)
+	events.On(dispatcher, events.UserNotificationPreferencesUpdated, h.HandleNotificationPreferencesUpdated)
+	events.On(dispatcher, events.UserNotificationDefaultsUpdated, h.HandleNotificationDefaultsUpdated)
+	events.On(dispatcher, events.UserDeviceRegistered, h.HandleDeviceRegistered)
+	events.On(dispatcher, events.UserDeviceRemoved, h.HandleDeviceRemoved)
+	events.On(dispatcher, events.UserDataExportRequested, h.HandleDataExportRequested)
+	events.On(dispatcher, events.UserDataExportReady, h.HandleDataExportReady)
+	events.On(dispatcher, events.BookingConfirmed, h.HandleBookingConfirmed)
+	events.On(dispatcher, events.BookingReminder, h.HandleBookingReminder)
+	events.On(dispatcher, events.BookingCancelled, h.HandleBookingCancelled)
+	events.On(dispatcher, events.WaitlistOffered, h.HandleWaitlistOffered)
+	events.On(dispatcher, events.PaymentProcessed, h.HandlePaymentProcessed)
+	events.On(dispatcher, events.PaymentFailed, h.HandlePaymentFailed)
+	events.On(dispatcher, events.ExportCompleted, h.HandleExportCompleted)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName,
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("event consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// ------------------- Router Setup -------------------
+
+// setupRouter serves the Twilio webhooks when twilio is not nil.
+func setupRouter(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, checks *health.Registry, m *metrics.Metrics, twilio *handler.TwilioWebhookHandler) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+
+	router.Use(
+		middleware.RequestID(),
+		middleware.Locale(),
+		middleware.Compress(cfg.Compression, cfg.CompressionMinSize, cfg.CompressionTypes),
+		middleware.MaxBodySize(int64(cfg.MaxRequestBodySize)),
+		middleware.BodyLog(log, func() bool { return watcher.Current().DebugBodyLog }, cfg.DebugBodyLogLimit, cfg.DebugBodyLogRedact),
+		middleware.Errors(log, cfg.IsProduction()),
+		middleware.Tenant(),
+		middleware.ReadYourWrites(),
+		m.GinMiddleware(),
+	)
+
+	// Health checks
+	router.GET("/health", checks.Health)
+	router.GET("/ready", checks.Ready)
+
+	// Metrics are served on METRICS_PORT; this keeps old scrape configs working
+	if cfg.MetricsOnAPIPort {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+
+	if twilio != nil {
+		router.POST(handler.TwilioStatusPath, twilio.HandleStatus)
+		router.POST(handler.TwilioInboundPath, twilio.HandleInbound)
+	}
+
+	return router
+}
+
+// startServer serves router in the background. Stopping the returned
+// server is left to the lifecycle manager.
+func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) *http.Server {
+	server := &http.Server{
+		Addr:    ":" + cfg.ServicePort,
+		Handler: router,
+	}
+
+	go func() {
+		log.Info(fmt.Sprintf("🚀 Starting %s on port %s", cfg.ServiceName, cfg.ServicePort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(fmt.Sprintf("Failed to start server: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
@@ -0,0 +1,74 @@
+// Command openapi writes the OpenAPI document of a service. It is run by
+// go generate in the docs package:
+//
+//	go run ./cmd/openapi -service user -out docs/user.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	bookinghandler "github.com/dmehra2102/booking-system/internal/booking/handler"
+	calendarhandler "github.com/dmehra2102/booking-system/internal/calendar/handler"
+	pricinghandler "github.com/dmehra2102/booking-system/internal/pricing/handler"
+	reviewhandler "github.com/dmehra2102/booking-system/internal/review/handler"
+	statshandler "github.com/dmehra2102/booking-system/internal/stats/handler"
+	tenanthandler "github.com/dmehra2102/booking-system/internal/tenant/handler"
+	userhandler "github.com/dmehra2102/booking-system/internal/user/handler"
+	webhookhandler "github.com/dmehra2102/booking-system/internal/webhook/handler"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+const apiVersion = "v1"
+
+var services = map[string]struct {
+	title  string
+	routes func() []openapi.Route
+}{
+	"user":    {title: "User Service API", routes: userRoutes},
+	"booking": {title: "Booking Service API", routes: bookingRoutes},
+}
+
+// userRoutes adds the tenant administration API, which the user service
+// serves.
+func userRoutes() []openapi.Route {
+	return slices.Concat(userhandler.Routes(), tenanthandler.Routes())
+}
+
+// bookingRoutes adds the pricing, review, admin statistics, webhook and
+// calendar APIs, which the booking service serves.
+func bookingRoutes() []openapi.Route {
+	return slices.Concat(bookinghandler.Routes(), pricinghandler.Routes(), reviewhandler.Routes(), statshandler.Routes(), webhookhandler.Routes(), calendarhandler.Routes())
+}
+
+func main() {
+	service := flag.String("service", "", "service to document (user or booking)")
+	out := flag.String("out", "", "output file, stdout when empty")
+	flag.Parse()
+
+	svc, ok := services[*service]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown service %q\n", *service)
+		os.Exit(2)
+	}
+
+	spec, err := json.MarshalIndent(openapi.Build(svc.title, apiVersion, svc.routes()), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode document: %v\n", err)
+		os.Exit(1)
+	}
+	spec = append(spec, '\n')
+
+	if *out == "" {
+		os.Stdout.Write(spec)
+		return
+	}
+
+	if err := os.WriteFile(*out, spec, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		os.Exit(1)
+	}
+}
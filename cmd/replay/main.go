@@ -0,0 +1,175 @@
+// Command replay rebuilds a projection by feeding the events of its Kafka
+// topics through its handlers again, outside of the consumer groups of the
+// services:
+//
+//	go run ./cmd/replay -projection search
+//	go run ./cmd/replay -projection ratings -from 2026-01-01T00:00:00Z -dry-run
+//
+// Handlers of a projection overwrite what they index, so replaying events
+// that were handled already is safe.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/kafka"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/opensearch"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	resourcehandler "github.com/dmehra2102/booking-system/internal/resource/handler"
+	resourcerepository "github.com/dmehra2102/booking-system/internal/resource/repository"
+	resourceservice "github.com/dmehra2102/booking-system/internal/resource/service"
+	searchdomain "github.com/dmehra2102/booking-system/internal/search/domain"
+	searchhandler "github.com/dmehra2102/booking-system/internal/search/handler"
+	searchrepository "github.com/dmehra2102/booking-system/internal/search/repository"
+	searchservice "github.com/dmehra2102/booking-system/internal/search/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "replay"
+
+// projection registers the handlers of a projection on a dispatcher and
+// returns a function releasing what they use.
+type projection func(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, d *events.Dispatcher) (func(), error)
+
+var projections = map[string]projection{
+	"search":  searchProjection,
+	"ratings": ratingsProjection,
+}
+
+func main() {
+	name := flag.String("projection", "", "projection to rebuild ("+strings.Join(slices.Sorted(maps.Keys(projections)), " or ")+")")
+	offset := flag.Int64("offset", kafka.FirstOffset, "offset to start every partition at, the first when negative")
+	from := flag.String("from", "", "RFC 3339 time to start at, overrides -offset")
+	dryRun := flag.Bool("dry-run", false, "only count the events that would be replayed")
+	progress := flag.Duration("progress", 10*time.Second, "interval of progress reports")
+	flag.Parse()
+
+	setup, ok := projections[*name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown projection %q\n", *name)
+		os.Exit(2)
+	}
+
+	replay := kafka.ReplayConfig{Offset: *offset, DryRun: *dryRun, ProgressInterval: *progress}
+	if replay.Offset < 0 {
+		replay.Offset = kafka.FirstOffset
+	}
+	if *from != "" {
+		t, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -from: %v\n", err)
+			os.Exit(2)
+		}
+		replay.From = t
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log := logger.New(serviceName, cfg.LogLevel).With("projection", *name)
+	os.Exit(run(cfg, log, setup, replay))
+}
+
+// run replays the events of the projection set up by setup and returns
+// the exit code.
+func run(cfg *config.Config, log *logger.Logger, setup projection, replay kafka.ReplayConfig) int {
+	m := metrics.New(serviceName)
+	tracer := tracing.GetTracer(serviceName)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dispatcher := events.NewDispatcher(m, tracer)
+	release, err := setup(ctx, cfg, log, m, tracer, dispatcher)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to set up projection: %v", err))
+		return 1
+	}
+	defer release()
+
+	eventTypes := dispatcher.EventTypes()
+	replay.Topics = cfg.KafkaTopics().Subscribe(eventTypes)
+
+	codec, err := bootstrap.Codec(cfg, log, m)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to configure schema registry: %v", err))
+		return 1
+	}
+
+	replayer := kafka.NewReplayer(cfg.KafkaBrokers, codec, log)
+	for _, eventType := range eventTypes {
+		replayer.RegisterHandler(string(eventType), dispatcher.Handle)
+	}
+
+	log.With("topics", strings.Join(replay.Topics, ",")).With("dry_run", fmt.Sprint(replay.DryRun)).Info("starting replay")
+
+	stats, err := replayer.Replay(ctx, replay)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			log.Warn("replay interrupted")
+		} else {
+			log.WithError(err).Error("replay failed")
+		}
+		return 1
+	}
+	if stats.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// searchProjection rebuilds the OpenSearch indexes of the search indexer,
+// creating the missing ones first.
+func searchProjection(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, d *events.Dispatcher) (func(), error) {
+	if cfg.SearchBackend != "opensearch" {
+		return nil, errors.New("SEARCH_BACKEND is not opensearch")
+	}
+
+	client, err := opensearch.New(cfg.OpenSearchURL, httpclient.New("opensearch", cfg.HTTPClientConfig(), log, m))
+	if err != nil {
+		return nil, err
+	}
+
+	indexer := searchservice.NewIndexerService(searchrepository.NewOpenSearchIndexRepository(client, searchdomain.NewIndexes(cfg.OpenSearchIndexPrefix), tracer), log, tracer)
+	if err := indexer.Setup(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create search indexes: %w", err)
+	}
+
+	searchhandler.NewEventHandler(indexer, log).Register(d)
+	return func() {}, nil
+}
+
+// ratingsProjection rebuilds the catalog's copies of the resource ratings.
+// Cached resources show the old rating until their cache entry expires.
+func ratingsProjection(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, d *events.Dispatcher) (func(), error) {
+	db, err := database.NewPostgresDB(cfg.PostgresURL, cfg.RetryPolicy(), cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	resources := resourceservice.NewResourceService(resourcerepository.NewPostgresResourceRepository(db, tracer), nil, nil, log, m, tracer)
+	h := resourcehandler.NewEventHandler(resources, log)
+	events.On(d, events.ReviewCreated, h.HandleReviewCreated)
+
+	return func() { db.Close() }, nil
+}
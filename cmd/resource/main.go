@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	apikeyrepository "github.com/dmehra2102/booking-system/internal/apikey/repository"
+	apikeyservice "github.com/dmehra2102/booking-system/internal/apikey/service"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/opensearch"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/resource/handler"
+	"github.com/dmehra2102/booking-system/internal/resource/repository"
+	"github.com/dmehra2102/booking-system/internal/resource/service"
+	searchdomain "github.com/dmehra2102/booking-system/internal/search/domain"
+	searchhandler "github.com/dmehra2102/booking-system/internal/search/handler"
+	searchrepository "github.com/dmehra2102/booking-system/internal/search/repository"
+	searchservice "github.com/dmehra2102/booking-system/internal/search/service"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/grpc/resourcepb"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func main() {
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		panic(fmt.Sprintf("❌ Failed to load config: %v", err))
+	}
+
+	// Initialize logger
+	log := logger.New(cfg.ServiceName, cfg.LogLevel)
+	buildinfo.LogStartup(log, cfg.Environment)
+	log.WithFields(cfg.Redacted()).Info("effective configuration")
+
+	// Components register their stop hooks as they start; they are stopped
+	// in reverse order on shutdown
+	lc := lifecycle.New(log, cfg.ShutdownTimeout, cfg.ShutdownDrainDelay)
+
+	// Apply configuration changes on SIGHUP or when the config file changes
+	watcher := bootstrap.WatchConfig(cfg, log, lc)
+
+	// Initialize tracing
+	tracerShutdown := initTracing(cfg, log)
+	lc.OnStop("tracer", func(context.Context) error {
+		tracerShutdown()
+		return nil
+	})
+
+	tracer := tracing.GetTracer(cfg.ServiceName)
+
+	// Keep values read from the secret store current
+	if secrets := cfg.Secrets(); secrets != nil {
+		stopSecrets := secrets.Watch(cfg.SecretsRefreshInterval, log)
+		lc.OnStop("secrets refresh", func(context.Context) error {
+			stopSecrets()
+			return nil
+		})
+	}
+
+	// Initialize metrics
+	metricsCollector := metrics.New(cfg.ServiceName)
+
+	// Initialize dependencies
+	db := initDatabase(cfg, log, metricsCollector, tracer)
+	lc.OnStop("postgres", lifecycle.Close(db.Close))
+
+	migrateOnly, err := bootstrap.RunMigrations(cfg, log, db)
+	if err != nil {
+		log.Error(fmt.Sprintf("Migration failed: %v", err))
+		os.Exit(1)
+	}
+	if migrateOnly {
+		lc.Shutdown()
+		return
+	}
+
+	metricsServer := bootstrap.StartMetricsServer(cfg, log, metricsCollector)
+	lc.OnStop("metrics server", metricsServer.Shutdown)
+
+	redisClient := initRedis(cfg, log, metricsCollector, tracer)
+	lc.OnStop("redis", lifecycle.Close(redisClient.Close))
+
+	revocations := auth.NewRedisRevocationStore(redisClient)
+
+	bus, err := bootstrap.MessageBus(cfg, log, metricsCollector, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to set up the message bus: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("message bus", lifecycle.Close(bus.Close))
+
+	// Health checks
+	checks := health.New(cfg.ServiceName)
+	checks.Register("shutdown", lc.Check)
+	checks.Register("postgres", db.Health)
+	checks.RegisterOptional("postgres_replicas", db.ReplicaHealth)
+	checks.Register("redis", redisClient.Health)
+	checks.RegisterOptional(cfg.MessageBus, bus.Ping)
+
+	auditRecorder := audit.NewRecorder(db, log, cfg.ServiceName)
+	lc.OnStop("audit recorder", func(context.Context) error {
+		auditRecorder.Close()
+		return nil
+	})
+
+	apiKeys := apikeyservice.NewAPIKeyService(apikeyrepository.NewPostgresAPIKeyRepository(db, tracer), log, tracer)
+
+	// Initialize application components
+	var resourceRepo repository.ResourceRepository = repository.NewPostgresResourceRepository(db, tracer)
+	if cfg.CacheTTL > 0 {
+		resourceRepo = repository.NewCachedResourceRepository(resourceRepo, redisClient, cfg.CacheTTL, log, metricsCollector)
+	}
+
+	// Search on OpenSearch reads the documents of the search indexer run
+	// below
+	var search service.SearchIndex = repository.NewPostgresResourceSearch(db, tracer)
+	var searchClient *opensearch.Client
+	searchIndexes := searchdomain.NewIndexes(cfg.OpenSearchIndexPrefix)
+	if cfg.SearchBackend == "opensearch" {
+		searchClient = initOpenSearch(cfg, log, metricsCollector)
+		checks.RegisterOptional("opensearch", searchClient.Health)
+		search = repository.NewOpenSearchResourceSearch(searchClient, searchIndexes.Resources, resourceRepo, tracer)
+	}
+
+	resourceService := service.NewResourceService(resourceRepo, search, bus, log, metricsCollector, tracer)
+	resourceHandler := handler.NewResourceHandler(resourceService, log, tracer)
+
+	// Start gRPC server
+	grpcServer := grpcserver.New(log, cfg.ServiceTokenSecret)
+	resourcepb.RegisterResourceServiceServer(grpcServer, handler.NewResourceGRPCServer(resourceService))
+	if err := grpcserver.Serve(grpcServer, cfg.GRPCPort, log); err != nil {
+		log.Error(fmt.Sprintf("Failed to start gRPC server: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("grpc server", func(ctx context.Context) error {
+		return grpcserver.Stop(ctx, grpcServer)
+	})
+
+	// Purge handled events that expired. Every replica runs the job
+	jobs := scheduler.New(cfg.ServiceName, redisClient, log, metricsCollector, tracer)
+	processed := bootstrap.ProcessedStore(cfg, log, db, redisClient, jobs)
+	jobs.Start()
+	lc.OnStop("scheduler", jobs.Shutdown)
+
+	// Start consumer
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := startConsumer(ctx, cfg, log, metricsCollector, tracer, bus, processed, handler.NewEventHandler(resourceService, log))
+	lc.OnStopWithTimeout("event consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		// Abort handlers that are still running when the drain times out
+		defer cancel()
+		return consumer.Shutdown(stopCtx)
+	})
+
+	// Resource listings are cached until a resource or rating changes
+	listings := middleware.NewResponseCache(redisClient, metricsCollector, log, middleware.ResponseCacheRule{
+		Name: "resource_listings",
+		TTL:  cfg.ResponseCacheTTL,
+	})
+	cacheCtx, cancelCache := context.WithCancel(context.Background())
+	cacheConsumer := startResponseCacheConsumer(cacheCtx, cfg, log, metricsCollector, tracer, bus, processed, handler.NewResponseCacheEventHandler(listings))
+	lc.OnStopWithTimeout("response cache consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		defer cancelCache()
+		return cacheConsumer.Shutdown(stopCtx)
+	})
+
+	// Start search indexer
+	if searchClient != nil {
+		indexer := searchservice.NewIndexerService(searchrepository.NewOpenSearchIndexRepository(searchClient, searchIndexes, tracer), log, tracer)
+		indexerCtx, cancelIndexer := context.WithCancel(context.Background())
+		indexerConsumer := startSearchIndexer(indexerCtx, cfg, log, metricsCollector, tracer, bus, processed, indexer)
+		lc.OnStopWithTimeout("search indexer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+			defer cancelIndexer()
+			return indexerConsumer.Shutdown(stopCtx)
+		})
+	}
+
+	// Setup router
+	router := setupRouter(cfg, watcher, log, checks, redisClient, metricsCollector, revocations, apiKeys, auditRecorder, listings, resourceHandler)
+
+	// Start server
+	server := startServer(cfg, log, router)
+	lc.OnStopWithTimeout("http server", cfg.ShutdownHTTPTimeout, server.Shutdown)
+
+	lc.Wait()
+}
+
+// ------------------- Initialization Helpers -------------------
+
+func initTracing(cfg *config.Config, log *logger.Logger) func() {
+	tracerShutdown, err := tracing.InitTracer(cfg.ServiceName, cfg.JaegerEndpoint)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize tracer: %v", err))
+		return func() {}
+	}
+	return tracerShutdown
+}
+
+func initDatabase(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.PostgresDB {
+	db, err := database.NewPostgresDB(cfg.PostgresURL, cfg.RetryPolicy(), cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database: %v", err))
+		os.Exit(1)
+	}
+	db.SetSlowQueryThreshold(cfg.PostgresSlowQueryThreshold)
+	if err := db.ConnectReplicas(cfg.PostgresReplicaURLs, cfg.PostgresReplicaCheckInterval); err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database replicas: %v", err))
+		os.Exit(1)
+	}
+	return db
+}
+
+func initRedis(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.RedisClient {
+	redisClient, err := database.NewRedisClient(cfg.RedisURL, cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to redis: %v", err))
+		os.Exit(1)
+	}
+	return redisClient
+}
+
+func startConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.EventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.ReviewCreated, h.HandleReviewCreated)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName,
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("event consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// startResponseCacheConsumer invalidates the cached resource listings on
+// resource and review events, in a consumer group of its own.
+func startResponseCacheConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.ResponseCacheEventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.ResourceCreated, h.HandleResourceCreated)
+	events.On(dispatcher, events.ResourceUpdated, h.HandleResourceUpdated)
+	events.On(dispatcher, events.ResourceDeleted, h.HandleResourceDeleted)
+	events.On(dispatcher, events.ReviewCreated, h.HandleReviewCreated)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName + "-response-cache",
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("response cache consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+func initOpenSearch(cfg *config.Config, log *logger.Logger, m *metrics.Metrics) *opensearch.Client {
+	client, err := opensearch.New(cfg.OpenSearchURL, httpclient.New("opensearch", cfg.HTTPClientConfig(), log, m))
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to configure opensearch: %v", err))
+		os.Exit(1)
+	}
+	return client
+}
+
+// startSearchIndexer creates the missing search indexes and keeps them
+// current with the resource, user and booking events, in a consumer group
+// of its own.
+func startSearchIndexer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, indexer *searchservice.IndexerService) messagebus.Subscription {
+	if err := indexer.Setup(ctx); err != nil {
+		log.Error(fmt.Sprintf("Failed to create search indexes: %v", err))
+		os.Exit(1)
+	}
+
+	dispatcher := events.NewDispatcher(m, tracer)
+	searchhandler.NewEventHandler(indexer, log).Register(dispatcher)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName + "-search-indexer",
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("search indexer stopped")
+		}
+	}()
+
+	return consumer
+}
+
+// ------------------- Router Setup -------------------
+
+func setupRouter(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, checks *health.Registry, redisClient *database.RedisClient, m *metrics.Metrics, revocations auth.RevocationStore, apiKeys middleware.APIKeyAuthenticator, auditRecorder *audit.Recorder, listings *middleware.ResponseCache, resourceHandler *handler.ResourceHandler) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+
+	// Global middlewares
+	router.Use(
+		middleware.RequestID(),
+		middleware.Locale(),
+		middleware.Compress(cfg.Compression, cfg.CompressionMinSize, cfg.CompressionTypes),
+		middleware.MaxBodySize(int64(cfg.MaxRequestBodySize)),
+		middleware.BodyLog(log, func() bool { return watcher.Current().DebugBodyLog }, cfg.DebugBodyLogLimit, cfg.DebugBodyLogRedact),
+		middleware.Errors(log, cfg.IsProduction()),
+		middleware.Tenant(),
+		middleware.ReadYourWrites(),
+		middleware.CORS(),
+		middleware.Recovery(log),
+		middleware.Timing(log, func() bool { return watcher.Current().DebugTimingHeader }),
+		middleware.Audit(auditRecorder),
+		middleware.Timeout(30*time.Second),
+		m.GinMiddleware(),
+		otelgin.Middleware(cfg.ServiceName),
+	)
+
+	// Health checks
+	router.GET("/health", checks.Health)
+	router.GET("/ready", checks.Ready)
+
+	// Metrics are served on METRICS_PORT; this keeps old scrape configs working
+	if cfg.MetricsOnAPIPort {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+
+	// API routes
+	api := router.Group("/api/v1")
+	apiLimit := middleware.NewRateLimiter(redisClient, m, log, middleware.RateLimitRule{
+		Name:   "api",
+		Limit:  cfg.RateLimitRequests,
+		Window: cfg.RateLimitWindow,
+	})
+	watcher.OnChange(func(cfg *config.Config) {
+		apiLimit.SetLimit(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	})
+	api.Use(apiLimit.Handler())
+	{
+		api.GET("/resources", listings.Handler(), resourceHandler.ListResources)
+		api.GET("/resources/search", listings.Handler(), resourceHandler.SearchResources)
+		api.GET("/resources/:id", middleware.UUIDParams("id"), resourceHandler.GetResource)
+
+		admin := api.Group("")
+		admin.Use(
+			middleware.APIKeyAuth(apiKeys, middleware.AuthMiddleware(cfg.JWTSecret, revocations)),
+			middleware.RequireRole(auth.RoleAdmin),
+			middleware.UUIDParams("id"),
+		)
+		{
+			admin.POST("/resources", resourceHandler.CreateResource)
+			admin.PUT("/resources/:id", resourceHandler.UpdateResource)
+			admin.DELETE("/resources/:id", resourceHandler.DeleteResource)
+		}
+	}
+
+	return router
+}
+
+// startServer serves router in the background. Stopping the returned
+// server is left to the lifecycle manager.
+func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) *http.Server {
+	server := &http.Server{
+		Addr:    ":" + cfg.ServicePort,
+		Handler: router,
+	}
+
+	go func() {
+		log.Info(fmt.Sprintf("🚀 Starting %s on port %s", cfg.ServiceName, cfg.ServicePort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(fmt.Sprintf("Failed to start server: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
@@ -5,25 +5,55 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"github.com/dmehra2102/booking-system/docs"
+	apikeyhandler "github.com/dmehra2102/booking-system/internal/apikey/handler"
+	apikeyrepository "github.com/dmehra2102/booking-system/internal/apikey/repository"
+	apikeyservice "github.com/dmehra2102/booking-system/internal/apikey/service"
+	audithandler "github.com/dmehra2102/booking-system/internal/audit/handler"
+	auditrepository "github.com/dmehra2102/booking-system/internal/audit/repository"
+	auditservice "github.com/dmehra2102/booking-system/internal/audit/service"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/bootstrap"
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
 	"github.com/dmehra2102/booking-system/internal/common/config"
 	"github.com/dmehra2102/booking-system/internal/common/database"
-	"github.com/dmehra2102/booking-system/internal/common/kafka"
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
 	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
 	"github.com/dmehra2102/booking-system/internal/common/metrics"
 	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
 	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	exportdomain "github.com/dmehra2102/booking-system/internal/export/domain"
+	exporthandler "github.com/dmehra2102/booking-system/internal/export/handler"
+	exportrepository "github.com/dmehra2102/booking-system/internal/export/repository"
+	exportservice "github.com/dmehra2102/booking-system/internal/export/service"
+	tenanthandler "github.com/dmehra2102/booking-system/internal/tenant/handler"
+	tenantrepository "github.com/dmehra2102/booking-system/internal/tenant/repository"
+	tenantservice "github.com/dmehra2102/booking-system/internal/tenant/service"
+	"github.com/dmehra2102/booking-system/internal/user/captcha"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
 	"github.com/dmehra2102/booking-system/internal/user/handler"
+	"github.com/dmehra2102/booking-system/internal/user/oauth"
 	"github.com/dmehra2102/booking-system/internal/user/repository"
 	"github.com/dmehra2102/booking-system/internal/user/service"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/crypto"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/grpc/userpb"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+	"github.com/dmehra2102/booking-system/pkg/validation"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.opentelemetry.io/otel/trace"
-	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func main() {
@@ -35,41 +65,230 @@ func main() {
 
 	// Initialize logger
 	log := logger.New(cfg.ServiceName, cfg.LogLevel)
+	buildinfo.LogStartup(log, cfg.Environment)
+	log.WithFields(cfg.Redacted()).Info("effective configuration")
+
+	// Components register their stop hooks as they start; they are stopped
+	// in reverse order on shutdown
+	lc := lifecycle.New(log, cfg.ShutdownTimeout, cfg.ShutdownDrainDelay)
+
+	// Apply configuration changes on SIGHUP or when the config file changes
+	watcher := bootstrap.WatchConfig(cfg, log, lc)
 
 	// Initialize tracing
 	tracerShutdown := initTracing(cfg, log)
-	defer tracerShutdown()
+	lc.OnStop("tracer", func(context.Context) error {
+		tracerShutdown()
+		return nil
+	})
 
-	tracer := noop.NewTracerProvider().Tracer(cfg.ServiceName)
+	tracer := tracing.GetTracer(cfg.ServiceName)
+
+	// Keep values read from the secret store current
+	if secrets := cfg.Secrets(); secrets != nil {
+		stopSecrets := secrets.Watch(cfg.SecretsRefreshInterval, log)
+		lc.OnStop("secrets refresh", func(context.Context) error {
+			stopSecrets()
+			return nil
+		})
+	}
 
 	// Initialize metrics
 	metricsCollector := metrics.New(cfg.ServiceName)
 
 	// Initialize dependencies
 	db := initDatabase(cfg, log, metricsCollector, tracer)
-	defer db.Close()
+	lc.OnStop("postgres", lifecycle.Close(db.Close))
+
+	migrateOnly, err := bootstrap.RunMigrations(cfg, log, db)
+	if err != nil {
+		log.Error(fmt.Sprintf("Migration failed: %v", err))
+		os.Exit(1)
+	}
+	if migrateOnly {
+		lc.Shutdown()
+		return
+	}
+
+	metricsServer := bootstrap.StartMetricsServer(cfg, log, metricsCollector)
+	lc.OnStop("metrics server", metricsServer.Shutdown)
+
+	redisClient := initRedis(cfg, log, metricsCollector, tracer)
+	lc.OnStop("redis", lifecycle.Close(redisClient.Close))
 
-	producer := kafka.NewProducer(cfg.KafkaBrokers, log, metricsCollector, tracer)
-	defer producer.Close()
+	revocations := auth.NewRedisRevocationStore(redisClient)
+
+	bus, err := bootstrap.MessageBus(cfg, log, metricsCollector, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to set up the message bus: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("message bus", lifecycle.Close(bus.Close))
+
+	// Health checks
+	checks := health.New(cfg.ServiceName)
+	checks.Register("shutdown", lc.Check)
+	checks.Register("postgres", db.Health)
+	checks.RegisterOptional("postgres_replicas", db.ReplicaHealth)
+	checks.Register("redis", redisClient.Health)
+	checks.RegisterOptional(cfg.MessageBus, bus.Ping)
+
+	auditRecorder := audit.NewRecorder(db, log, cfg.ServiceName)
+	lc.OnStop("audit recorder", func(context.Context) error {
+		auditRecorder.Close()
+		return nil
+	})
+
+	apiKeys := apikeyservice.NewAPIKeyService(apikeyrepository.NewPostgresAPIKeyRepository(db, tracer), log, tracer)
 
 	// Initialize application components
-	userRepo := repository.NewPostgresUserRepository(db, tracer)
+	var userRepo repository.UserRepository = repository.NewPostgresUserRepository(db, tracer)
+	if cfg.CacheTTL > 0 {
+		userRepo = repository.NewCachedUserRepository(userRepo, redisClient, cfg.CacheTTL, log, metricsCollector)
+	}
 	userService := service.NewUserService(
 		userRepo,
-		producer,
+		bus,
+		revocations,
+		auth.NewRedisPasswordResetStore(redisClient),
 		log,
 		metricsCollector,
 		tracer,
 		cfg.JWTSecret,
 		cfg.JWTExpiry,
+		cfg.JWTRefreshExpiry,
+		service.EmailVerification{
+			URL:      cfg.EmailVerificationURL,
+			Expiry:   cfg.EmailVerificationExpiry,
+			Required: cfg.EmailVerificationRequired,
+		},
+		service.PasswordReset{
+			URL:    cfg.PasswordResetURL,
+			Expiry: cfg.PasswordResetExpiry,
+		},
 	)
+	watcher.OnChange(func(cfg *config.Config) {
+		userService.SetEmailVerificationRequired(cfg.EmailVerificationRequired)
+	})
+	userService.SetLoginProtection(initLoginProtection(cfg, log, metricsCollector, redisClient))
 	userHandler := handler.NewUserHandler(userService, log, tracer)
+	sessionRepo := repository.NewPostgresSessionRepository(db, tracer)
+	sessionService := service.NewSessionService(userService, sessionRepo, log, tracer)
+	sessionHandler := handler.NewSessionHandler(sessionService, log)
+	kms, err := crypto.NewLocalKMS(cfg.FieldEncryptionKeys)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize field encryption: %v", err))
+		os.Exit(1)
+	}
+	deviceRepo := repository.NewPostgresDeviceRepository(db, crypto.NewEnvelope(kms), tracer)
+	deviceService := service.NewDeviceService(deviceRepo, bus, log, tracer)
+	deviceHandler := handler.NewDeviceHandler(deviceService, log)
+
+	// Social login offers the providers an OAuth client is configured for
+	providers := make(map[string]service.OAuthProvider)
+	oauthClient := httpclient.New("oauth", cfg.HTTPClientConfig(), log, metricsCollector)
+	if cfg.GoogleOAuthClientID != "" {
+		providers[domain.ProviderGoogle] = oauth.NewGoogle(cfg.GoogleOAuthClientID, cfg.GoogleOAuthClientSecret, oauthClient)
+	}
+	if cfg.GitHubOAuthClientID != "" {
+		providers[domain.ProviderGitHub] = oauth.NewGitHub(cfg.GitHubOAuthClientID, cfg.GitHubOAuthClientSecret, oauthClient)
+	}
+	identityRepo := repository.NewPostgresIdentityRepository(db, tracer)
+	oauthService := service.NewOAuthService(
+		userService,
+		identityRepo,
+		auth.NewRedisOAuthStateStore(redisClient),
+		providers,
+		cfg.OAuthCallbackURL,
+		cfg.OAuthStateExpiry,
+		log,
+		tracer,
+	)
+	oauthHandler := handler.NewOAuthHandler(oauthService, log)
+
+	secretBox, err := auth.NewSecretBox(cfg.TwoFactorEncryptionKey)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initialize two-factor encryption: %v", err))
+		os.Exit(1)
+	}
+	twoFactorRepo := repository.NewPostgresTwoFactorRepository(db, tracer)
+	twoFactorService := service.NewTwoFactorService(
+		userService,
+		twoFactorRepo,
+		secretBox,
+		service.TwoFactorPolicy{
+			Issuer:          cfg.TwoFactorIssuer,
+			RequiredRoles:   cfg.TwoFactorRequiredRoles,
+			ChallengeExpiry: cfg.TwoFactorChallengeExpiry,
+		},
+		log,
+		tracer,
+	)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, log)
+
+	privacyService := service.NewPrivacyService(
+		userService,
+		repository.NewPostgresPrivacyRepository(db, tracer),
+		service.PersonalData{
+			Devices:    deviceRepo,
+			Sessions:   sessionRepo,
+			Identities: identityRepo,
+			TwoFactor:  twoFactorRepo,
+		},
+		service.DataExportPolicy{
+			DownloadURL: cfg.DataExportDownloadURL,
+			Expiry:      cfg.DataExportExpiry,
+		},
+		log,
+		tracer,
+	)
+	privacyHandler := handler.NewPrivacyHandler(privacyService, log)
+
+	auditService := auditservice.NewAuditService(auditrepository.NewPostgresAuditRepository(db, tracer), tracer)
+	auditHandler := audithandler.NewAuditHandler(auditService)
+	apiKeyHandler := apikeyhandler.NewAPIKeyHandler(apiKeys)
+	tenantHandler := tenanthandler.NewTenantHandler(tenantservice.NewTenantService(tenantrepository.NewPostgresTenantRepository(db, tracer), userService, log, tracer))
+
+	exportService := initExports(cfg, log, db, bus, tracer)
+	lc.OnStop("exports", exportService.Shutdown)
+	exportHandler := exporthandler.NewExportHandler(exportService, exportdomain.DatasetUsers, log)
+
+	// Start gRPC server
+	grpcServer := grpcserver.New(log, cfg.ServiceTokenSecret)
+	userpb.RegisterUserServiceServer(grpcServer, handler.NewUserGRPCServer(userService))
+	if err := grpcserver.Serve(grpcServer, cfg.GRPCPort, log); err != nil {
+		log.Error(fmt.Sprintf("Failed to start gRPC server: %v", err))
+		os.Exit(1)
+	}
+	lc.OnStop("grpc server", func(ctx context.Context) error {
+		return grpcserver.Stop(ctx, grpcServer)
+	})
+
+	// Expired data exports are purged by the elected leader among the
+	// replicas
+	jobs := scheduler.New(cfg.ServiceName, redisClient, log, metricsCollector, tracer)
+	jobs.Add(service.DataExportPurgeJob(privacyService, log, cfg.DataExportPurgeInterval))
+	processed := bootstrap.ProcessedStore(cfg, log, db, redisClient, jobs)
+	jobs.Start()
+	lc.OnStop("scheduler", jobs.Shutdown)
+
+	// Start consumer
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer := startConsumer(ctx, cfg, log, metricsCollector, tracer, bus, processed, handler.NewEventHandler(privacyService, log))
+	lc.OnStopWithTimeout("event consumer", cfg.ShutdownConsumerTimeout, func(stopCtx context.Context) error {
+		// Abort handlers that are still running when the drain times out
+		defer cancel()
+		return consumer.Shutdown(stopCtx)
+	})
 
 	// Setup router
-	router := setupRouter(cfg, log, db, metricsCollector, userHandler)
+	router := setupRouter(cfg, watcher, log, checks, redisClient, metricsCollector, revocations, apiKeys, auditRecorder, userHandler, oauthHandler, twoFactorHandler, sessionHandler, deviceHandler, privacyHandler, auditHandler, apiKeyHandler, tenantHandler, exportHandler)
 
 	// Start server
-	startServer(cfg, log, router)
+	server := startServer(cfg, log, router)
+	lc.OnStopWithTimeout("http server", cfg.ShutdownHTTPTimeout, server.Shutdown)
+
+	lc.Wait()
 }
 
 // ------------------- Initialization Helpers -------------------
@@ -84,75 +303,224 @@ func initTracing(cfg *config.Config, log *logger.Logger) func() {
 }
 
 func initDatabase(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.PostgresDB {
-	db, err := database.NewPostgresDB(cfg.PostgresURL, log, m, tracer)
+	db, err := database.NewPostgresDB(cfg.PostgresURL, cfg.RetryPolicy(), cfg.BreakerConfig(), log, m, tracer)
 	if err != nil {
 		log.Error(fmt.Sprintf("Failed to connect to database: %v", err))
 		os.Exit(1)
 	}
+	db.SetSlowQueryThreshold(cfg.PostgresSlowQueryThreshold)
+	if err := db.ConnectReplicas(cfg.PostgresReplicaURLs, cfg.PostgresReplicaCheckInterval); err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to database replicas: %v", err))
+		os.Exit(1)
+	}
 	return db
 }
 
+func initRedis(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) *database.RedisClient {
+	redisClient, err := database.NewRedisClient(cfg.RedisURL, cfg.BreakerConfig(), log, m, tracer)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to connect to redis: %v", err))
+		os.Exit(1)
+	}
+	return redisClient
+}
+
+// initLoginProtection returns the lockout, CAPTCHA and new device
+// notification settings of logins.
+func initLoginProtection(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, redisClient *database.RedisClient) service.LoginProtection {
+	protection := service.LoginProtection{
+		Attempts:           auth.NewRedisLoginAttemptStore(redisClient),
+		MaxFailures:        cfg.LoginMaxFailures,
+		FailureWindow:      cfg.LoginFailureWindow,
+		LockoutDuration:    cfg.LoginLockoutDuration,
+		MaxLockoutDuration: cfg.LoginMaxLockoutDuration,
+		CaptchaThreshold:   cfg.LoginCaptchaThreshold,
+		KnownClientTTL:     cfg.LoginKnownClientTTL,
+	}
+	if cfg.CaptchaSecret != "" {
+		protection.Captcha = captcha.NewSiteVerify(cfg.CaptchaVerifyURL, cfg.CaptchaSecret, httpclient.New("captcha", cfg.HTTPClientConfig(), log, m))
+	}
+	if cfg.LoginKnownClientTTL > 0 {
+		protection.KnownClients = auth.NewRedisKnownClientStore(redisClient)
+	}
+	return protection
+}
+
+// initExports returns the service writing background exports to
+// cfg.ExportDir.
+func initExports(cfg *config.Config, log *logger.Logger, db *database.PostgresDB, publisher messagebus.Publisher, tracer trace.Tracer) *exportservice.ExportService {
+	exports, err := exportservice.NewExportService(
+		exportrepository.NewPostgresExportRepository(db, tracer),
+		cfg.ExportDir,
+		cfg.ExportDownloadURL,
+		cfg.ServiceName,
+		publisher,
+		log,
+		tracer,
+	)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to create export directory: %v", err))
+		os.Exit(1)
+	}
+	return exports
+}
+
+func startConsumer(ctx context.Context, cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer, bus messagebus.MessageBus, processed messagebus.ProcessedStore, h *handler.EventHandler) messagebus.Subscription {
+	dispatcher := events.NewDispatcher(m, tracer)
+	events.On(dispatcher, events.UserDataExportPart, h.HandleDataExportPart)
+	events.On(dispatcher, events.UserDataErased, h.HandleDataErased)
+
+	eventTypes := dispatcher.EventTypes()
+
+	consumer := bus.Subscribe(messagebus.SubscriptionConfig{
+		Group:        cfg.ServiceName,
+		EventTypes:   eventTypes,
+		Workers:      cfg.KafkaConsumerWorkers,
+		Processed:    processed,
+		ProcessedTTL: cfg.KafkaProcessedTTL,
+	}, dispatcher.Handle)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil && err != context.Canceled {
+			log.WithError(err).Error("event consumer stopped")
+		}
+	}()
+
+	return consumer
+}
+
 // ------------------- Router Setup -------------------
 
-func setupRouter(cfg *config.Config, log *logger.Logger, db *database.PostgresDB, m *metrics.Metrics, userHandler *handler.UserHandler) *gin.Engine {
+func setupRouter(cfg *config.Config, watcher *config.Watcher, log *logger.Logger, checks *health.Registry, redisClient *database.RedisClient, m *metrics.Metrics, revocations auth.RevocationStore, apiKeys middleware.APIKeyAuthenticator, auditRecorder *audit.Recorder, userHandler *handler.UserHandler, oauthHandler *handler.OAuthHandler, twoFactorHandler *handler.TwoFactorHandler, sessionHandler *handler.SessionHandler, deviceHandler *handler.DeviceHandler, privacyHandler *handler.PrivacyHandler, auditHandler *audithandler.AuditHandler, apiKeyHandler *apikeyhandler.APIKeyHandler, tenantHandler *tenanthandler.TenantHandler, exportHandler *exporthandler.ExportHandler) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 
+	// Bind request bodies with the same rules the OpenAPI document describes
+	binding.Validator = validation.GinValidator{}
+
 	// Global middlewares
 	router.Use(
 		middleware.RequestID(),
+		middleware.Locale(),
+		middleware.Compress(cfg.Compression, cfg.CompressionMinSize, cfg.CompressionTypes),
+		middleware.MaxBodySize(int64(cfg.MaxRequestBodySize)),
+		middleware.BodyLog(log, func() bool { return watcher.Current().DebugBodyLog }, cfg.DebugBodyLogLimit, cfg.DebugBodyLogRedact),
+		middleware.Errors(log, cfg.IsProduction()),
+		middleware.Tenant(),
+		middleware.ReadYourWrites(),
 		middleware.CORS(),
 		middleware.Recovery(log),
+		middleware.Timing(log, func() bool { return watcher.Current().DebugTimingHeader }),
+		middleware.Audit(auditRecorder),
 		middleware.Timeout(30*time.Second),
 		m.GinMiddleware(),
 		otelgin.Middleware(cfg.ServiceName),
 	)
 
-	// Health Check
-	router.GET("/health", func(ctx *gin.Context) {
-		status := "healthy"
-		dbStatus := "healthy"
-
-		if err := db.Health(); err != nil {
-			status = "unhealthy"
-			dbStatus = "unhealthy"
-		}
-
-		ctx.JSON(http.StatusOK, gin.H{
-			"status":   status,
-			"database": dbStatus,
-			"service":  cfg.ServiceName,
-			"version":  "1.0.0",
-		})
-	})
+	// Health checks
+	router.GET("/health", checks.Health)
+	router.GET("/ready", checks.Ready)
 
-	router.GET("/ready", func(ctx *gin.Context) {
-		ctx.JSON(http.StatusOK, gin.H{"status": "ready"})
-	})
+	// Metrics are served on METRICS_PORT; this keeps old scrape configs working
+	if cfg.MetricsOnAPIPort {
+		router.GET("/metrics", gin.WrapH(m.Handler()))
+	}
 
-	// Metrics Endpoint
-	router.GET("/metrics", gin.WrapH(m.Handler()))
+	if cfg.DocsEnabled {
+		openapi.Register(router, docs.UserSpec)
+	}
 
 	// API routes
 	api := router.Group("/api/v1")
+	apiLimit := middleware.NewRateLimiter(redisClient, m, log, middleware.RateLimitRule{
+		Name:   "api",
+		Limit:  cfg.RateLimitRequests,
+		Window: cfg.RateLimitWindow,
+	})
+	watcher.OnChange(func(cfg *config.Config) {
+		apiLimit.SetLimit(cfg.RateLimitRequests, cfg.RateLimitWindow)
+	})
+	api.Use(apiLimit.Handler())
 	{
 		api.POST("/users", userHandler.CreateUser)
-		api.POST("/auth/login", userHandler.Login)
+		api.POST("/users/verify-email", userHandler.VerifyEmail)
+
+		authLimiter := middleware.NewRateLimiter(redisClient, m, log, middleware.RateLimitRule{
+			Name:   "auth",
+			Limit:  cfg.RateLimitAuthRequests,
+			Window: cfg.RateLimitWindow,
+		})
+		watcher.OnChange(func(cfg *config.Config) {
+			authLimiter.SetLimit(cfg.RateLimitAuthRequests, cfg.RateLimitWindow)
+		})
+		authLimit := authLimiter.Handler()
+		api.POST("/auth/login", authLimit, userHandler.Login)
+		api.POST("/auth/refresh", authLimit, userHandler.RefreshToken)
+		api.POST("/auth/forgot-password", authLimit, userHandler.ForgotPassword)
+		api.POST("/auth/reset-password", authLimit, userHandler.ResetPassword)
+		api.GET("/auth/oauth/:provider", authLimit, oauthHandler.Authorize)
+		api.GET("/auth/oauth/:provider/callback", authLimit, oauthHandler.Callback)
+		api.POST("/auth/login/2fa", authLimit, twoFactorHandler.CompleteLogin)
+		api.POST("/auth/login/2fa/setup", authLimit, twoFactorHandler.SetupLogin)
 
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+		protected.Use(middleware.APIKeyAuth(apiKeys, middleware.AuthMiddleware(cfg.JWTSecret, revocations)), middleware.UUIDParams("id", "device_id"))
 		{
-			protected.GET("/users", userHandler.ListUsers)
+			protected.POST("/auth/logout", userHandler.Logout)
+			protected.POST("/auth/logout-all", sessionHandler.EndAllSessions)
+			protected.GET("/users/me/sessions", sessionHandler.ListSessions)
+			protected.DELETE("/users/me/sessions/:id", sessionHandler.EndSession)
+			protected.GET("/users/me/2fa", twoFactorHandler.Get)
+			protected.POST("/users/me/2fa/setup", twoFactorHandler.Setup)
+			protected.POST("/users/me/2fa/enable", twoFactorHandler.Enable)
+			protected.POST("/users/me/2fa/disable", twoFactorHandler.Disable)
+			protected.POST("/users/me/2fa/backup-codes", twoFactorHandler.RegenerateBackupCodes)
+			protected.POST("/users/me/data-export", privacyHandler.RequestDataExport)
+			protected.GET("/users/me/data-export", privacyHandler.GetDataExport)
+			protected.GET("/users/me/data-export/download", privacyHandler.DownloadDataExport)
 			protected.GET("/users/:id", userHandler.GetUser)
 			protected.PUT("/users/:id", userHandler.UpdateUser)
 			protected.DELETE("/users/:id", userHandler.DeleteUser)
+			protected.POST("/users/:id/devices", deviceHandler.RegisterDevice)
+			protected.GET("/users/:id/devices", deviceHandler.ListDevices)
+			protected.DELETE("/users/:id/devices/:device_id", deviceHandler.RemoveDevice)
+			protected.GET("/users/:id/notification-preferences", deviceHandler.GetPreferences)
+			protected.PUT("/users/:id/notification-preferences", deviceHandler.UpdatePreferences)
+		}
+
+		admin := protected.Group("")
+		admin.Use(middleware.RequireRole(auth.RoleAdmin))
+		{
+			admin.GET("/users", userHandler.ListUsers)
+			admin.GET("/admin/users/export", exportHandler.Export(userHandler.ExportSource))
+			admin.GET("/admin/users/exports/:id", exportHandler.GetExport)
+			admin.GET("/admin/users/exports/:id/download", exportHandler.DownloadExport)
+			admin.PUT("/users/:id/role", userHandler.UpdateRole)
+			admin.GET("/notification-defaults", deviceHandler.GetDefaults)
+			admin.PUT("/notification-defaults", deviceHandler.UpdateDefaults)
+			admin.GET("/audit-logs", auditHandler.ListEntries)
+			admin.POST("/api-keys", apiKeyHandler.CreateKey)
+			admin.GET("/api-keys", apiKeyHandler.ListKeys)
+			admin.DELETE("/api-keys/:id", apiKeyHandler.RevokeKey)
+		}
+
+		// Tenants are administered by the admins of the default tenant
+		tenants := admin.Group("/admin/tenants", middleware.RequireTenant(tenancy.DefaultID))
+		{
+			tenants.POST("", tenantHandler.CreateTenant)
+			tenants.GET("", tenantHandler.ListTenants)
+			tenants.GET("/:id", tenantHandler.GetTenant)
+			tenants.PUT("/:id", tenantHandler.UpdateTenant)
+			tenants.DELETE("/:id", tenantHandler.DeactivateTenant)
 		}
 	}
 
 	return router
 }
 
-func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) {
+// startServer serves router in the background. Stopping the returned
+// server is left to the lifecycle manager.
+func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) *http.Server {
 	server := &http.Server{
 		Addr:    ":" + cfg.ServicePort,
 		Handler: router,
@@ -166,23 +534,5 @@ func startServer(cfg *config.Config, log *logger.Logger, router *gin.Engine) {
 		}
 	}()
 
-	// Graceful shutdown
-	waitForShutdown(server, log)
-}
-
-func waitForShutdown(server *http.Server, log *logger.Logger) {
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Info("🛑 Shutting down server gracefully...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		log.Error(fmt.Sprintf("Server forced to shutdown: %v", err))
-	}
-
-	log.Info("✅ Server stopped cleanly")
+	return server
 }
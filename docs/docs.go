@@ -0,0 +1,15 @@
+// Package docs embeds the generated OpenAPI documents served at /docs.
+// Regenerate them with go generate ./docs after changing routes or
+// request types.
+package docs
+
+import _ "embed"
+
+//go:generate go run ../cmd/openapi -service user -out user.json
+//go:generate go run ../cmd/openapi -service booking -out booking.json
+
+//go:embed user.json
+var UserSpec []byte
+
+//go:embed booking.json
+var BookingSpec []byte
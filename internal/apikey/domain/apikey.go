@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/auth"
+)
+
+// APIKey authenticates a machine client as the user that owns it. Only a
+// hash of the key is stored; the key itself is shown once on creation.
+type APIKey struct {
+	ID         string     `json:"id" db:"id"`
+	TenantID   string     `json:"tenant_id" db:"tenant_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	UserID     string     `json:"user_id" db:"user_id"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy  string     `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Owner is the user an API key acts as.
+type Owner struct {
+	Email  string
+	Role   string
+	Active bool
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=100"`
+	UserID    string     `json:"user_id,omitempty" validate:"omitempty,uuid"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=read write"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedAPIKey is returned once, when the key is created.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+func (k *APIKey) IsUsable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyService is the application API of the API key module.
+type APIKeyService interface {
+	CreateKey(ctx context.Context, createdBy string, req *CreateAPIKeyRequest) (*CreatedAPIKey, error)
+	ListKeys(ctx context.Context, page, pageSize int) ([]*APIKey, int64, error)
+	RevokeKey(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, key string) (*auth.APIKeyPrincipal, error)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dmehra2102/booking-system/internal/apikey/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	service domain.APIKeyService
+}
+
+func NewAPIKeyHandler(service domain.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// CreateKey returns the new key in full. It cannot be retrieved again.
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req domain.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	key, err := h.service.CreateKey(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Keys are only listed; there is no URL of their own for a Location
+	// header
+	response.Created(c, key)
+}
+
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	keys, total, err := h.service.ListKeys(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := &response.Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}
+
+	response.Paginated(c, keys, pagination)
+}
+
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	if err := h.service.RevokeKey(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/apikey/domain"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// lastUsedResolution limits how often last_used_at is written for a busy
+// key.
+const lastUsedResolution = time.Minute
+
+type PostgresAPIKeyRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresAPIKeyRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresAPIKeyRepository {
+	return &PostgresAPIKeyRepository{db: db, tracer: tracer}
+}
+
+const selectAPIKeyQuery = `
+	SELECT k.id, k.tenant_id, k.name, k.prefix, k.key_hash, k.user_id, k.scopes, k.expires_at,
+		k.last_used_at, k.revoked_at, k.created_by, k.created_at
+	FROM api_keys k
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner, extra ...any) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	dest := []any{
+		&key.ID, &key.TenantID, &key.Name, &key.Prefix, &key.KeyHash, &key.UserID, database.Array(&key.Scopes),
+		&expiresAt, &lastUsedAt, &revokedAt, &key.CreatedBy, &key.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) (err error) {
+	ctx, span := r.tracer.Start(ctx, "apikey.repository.create")
+	defer tracing.End(span, &err)
+
+	key.ID = uuid.New().String()
+	key.TenantID = tenancy.ID(ctx)
+	key.CreatedAt = time.Now().UTC()
+
+	// Keys can only act as users of their own tenant
+	query := `
+		INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, user_id, scopes, expires_at, created_by, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $6 AND tenant_id = $2)
+	`
+
+	result, err := r.db.Exec(ctx, "apikey.create", query,
+		key.ID, key.TenantID, key.Name, key.Prefix, key.KeyHash, key.UserID,
+		key.Scopes, key.ExpiresAt, key.CreatedBy, key.CreatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create api key", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check create result", err)
+	}
+
+	// The owning user does not exist
+	if rowsAffected == 0 {
+		return errors.NewValidationError("user does not exist", nil)
+	}
+
+	return nil
+}
+
+// GetByHash returns the key with the given hash and the user it acts as.
+// It is not scoped to a tenant: the key identifies its tenant, which must
+// be active.
+func (r *PostgresAPIKeyRepository) GetByHash(ctx context.Context, hash string) (_ *domain.APIKey, _ *domain.Owner, err error) {
+	ctx, span := r.tracer.Start(ctx, "apikey.repository.get_by_hash")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT k.id, k.tenant_id, k.name, k.prefix, k.key_hash, k.user_id, k.scopes, k.expires_at,
+			k.last_used_at, k.revoked_at, k.created_by, k.created_at,
+			u.email, u.role, u.active
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id AND u.tenant_id = k.tenant_id
+		JOIN tenants t ON t.id = k.tenant_id AND t.active
+		WHERE k.key_hash = $1
+	`
+
+	owner := &domain.Owner{}
+	key, err := scanAPIKey(r.db.QueryRow(ctx, "apikey.get_by_hash", query, hash), &owner.Email, &owner.Role, &owner.Active)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, errors.NewNotFoundError("api key")
+		}
+		return nil, nil, errors.NewInternalError("failed to get api key", err)
+	}
+
+	return key, owner, nil
+}
+
+func (r *PostgresAPIKeyRepository) List(ctx context.Context, limit, offset int) (_ []*domain.APIKey, _ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "apikey.repository.list")
+	defer tracing.End(span, &err)
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "apikey.count", `SELECT COUNT(*) FROM api_keys WHERE tenant_id = $1`, tenancy.ID(ctx)).Scan(&total); err != nil {
+		return nil, 0, errors.NewInternalError("failed to count api keys", err)
+	}
+
+	query := selectAPIKeyQuery + `
+		WHERE k.tenant_id = $3
+		ORDER BY k.created_at DESC, k.id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(ctx, "apikey.list", query, limit, offset, tenancy.ID(ctx))
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to list api keys", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*domain.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, 0, errors.NewInternalError("failed to scan api key", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.NewInternalError("failed to iterate api keys", err)
+	}
+
+	return keys, total, nil
+}
+
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "apikey.repository.revoke")
+	defer tracing.End(span, &err)
+
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND tenant_id = $3 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(ctx, "apikey.revoke", query, time.Now().UTC(), id, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to revoke api key", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check revoke result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("api key")
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that the key was used, at most once per
+// lastUsedResolution.
+func (r *PostgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "apikey.repository.touch_last_used")
+	defer tracing.End(span, &err)
+
+	now := time.Now().UTC()
+	query := `
+		UPDATE api_keys SET last_used_at = $1
+		WHERE id = $2 AND tenant_id = $4 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+
+	if _, err := r.db.Exec(ctx, "apikey.touch_last_used", query, now, id, now.Add(-lastUsedResolution), tenancy.ID(ctx)); err != nil {
+		return errors.NewInternalError("failed to record api key use", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/apikey/domain"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	GetByHash(ctx context.Context, hash string) (*domain.APIKey, *domain.Owner, error)
+	List(ctx context.Context, limit, offset int) ([]*domain.APIKey, int64, error)
+	Revoke(ctx context.Context, id string) error
+	TouchLastUsed(ctx context.Context, id string) error
+}
+
+var _ domain.APIKeyService = (*APIKeyService)(nil)
+
+type APIKeyService struct {
+	repo   APIKeyRepository
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+func NewAPIKeyService(repo APIKeyRepository, logger *logger.Logger, tracer trace.Tracer) *APIKeyService {
+	return &APIKeyService{repo: repo, logger: logger, tracer: tracer}
+}
+
+// CreateKey issues a key acting as req.UserID, or as the creator when no
+// user is given.
+func (s *APIKeyService) CreateKey(ctx context.Context, createdBy string, req *domain.CreateAPIKeyRequest) (_ *domain.CreatedAPIKey, err error) {
+	ctx, span := s.tracer.Start(ctx, "apikey.service.create")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.NewValidationError("expires_at must be in the future", nil)
+	}
+
+	raw, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate api key", err)
+	}
+
+	key := &domain.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   auth.HashAPIKey(raw),
+		UserID:    req.UserID,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: createdBy,
+	}
+	if key.UserID == "" {
+		key.UserID = createdBy
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "api_key.create", "api_key", key.ID, nil, key)
+	s.logger.WithContext(ctx).With("api_key_id", key.ID).With("user_id", key.UserID).Info("api key created successfully")
+
+	return &domain.CreatedAPIKey{APIKey: key, Key: raw}, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, page, pageSize int) (_ []*domain.APIKey, _ int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "apikey.service.list")
+	defer tracing.End(span, &err)
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.List(ctx, pageSize, offset)
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "apikey.service.revoke")
+	defer tracing.End(span, &err)
+
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "api_key.revoke", "api_key", id, nil, nil)
+	s.logger.WithContext(ctx).With("api_key_id", id).Info("api key revoked successfully")
+
+	return nil
+}
+
+// Authenticate resolves a presented key to the user it acts as. Revoked
+// and expired keys, and keys of deactivated users, are rejected.
+func (s *APIKeyService) Authenticate(ctx context.Context, raw string) (_ *auth.APIKeyPrincipal, err error) {
+	ctx, span := s.tracer.Start(ctx, "apikey.service.authenticate")
+	defer tracing.End(span, &err)
+
+	key, owner, err := s.repo.GetByHash(ctx, auth.HashAPIKey(raw))
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			return nil, errors.NewUnauthorizedError("invalid api key")
+		}
+		return nil, err
+	}
+
+	if !key.IsUsable(time.Now()) || !owner.Active {
+		return nil, errors.NewUnauthorizedError("api key has expired or been revoked")
+	}
+	ctx = tenancy.WithID(ctx, key.TenantID)
+
+	if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+		s.logger.WithContext(ctx).WithError(err).With("api_key_id", key.ID).Warn("failed to record api key use")
+	}
+
+	return &auth.APIKeyPrincipal{
+		KeyID:     key.ID,
+		TenantID:  key.TenantID,
+		UserID:    key.UserID,
+		UserEmail: owner.Email,
+		UserRole:  owner.Role,
+		Scopes:    key.Scopes,
+	}, nil
+}
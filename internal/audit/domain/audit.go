@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+)
+
+// ListFilter narrows an audit log query. Empty fields match everything.
+type ListFilter struct {
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Service      string
+	From         *time.Time
+	To           *time.Time
+}
+
+// AuditService is the read API over the audit log. Entries are written by
+// audit.Recorder in each service.
+type AuditService interface {
+	ListEntries(ctx context.Context, filter ListFilter, page, pageSize int) ([]*audit.Entry, int64, error)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/audit/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service domain.AuditService
+}
+
+func NewAuditHandler(service domain.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// ListEntries returns audit entries, newest first. Supported filters are
+// actor_id, action, resource_type, resource_id, service and an RFC 3339
+// from/to range.
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	filter := domain.ListFilter{
+		ActorID:      c.Query("actor_id"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Service:      c.Query("service"),
+	}
+
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := c.Query(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				response.ValidationError(c, validation.Invalid(name, "rfc3339", ""))
+				return
+			}
+			*target = &parsed
+		}
+	}
+
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	entries, total, err := h.service.ListEntries(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := &response.Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}
+
+	response.Paginated(c, entries, pagination)
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/audit/domain"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresAuditRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresAuditRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db, tracer: tracer}
+}
+
+func (r *PostgresAuditRepository) List(ctx context.Context, filter domain.ListFilter, limit, offset int) (_ []*audit.Entry, _ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "audit.repository.list")
+	defer tracing.End(span, &err)
+
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	add("tenant_id = $%d", tenancy.ID(ctx))
+	if filter.ActorID != "" {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		add("resource_type = $%d", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		add("resource_id = $%d", filter.ResourceID)
+	}
+	if filter.Service != "" {
+		add("service = $%d", filter.Service)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "audit.count", "SELECT COUNT(*) FROM audit_log "+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.NewInternalError("failed to count audit entries", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, tenant_id, service, actor_id, actor_role, action, resource_type, resource_id,
+			changes, request_id, trace_id, ip_address, method, path, status_code, created_at
+		FROM audit_log
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, "audit.list", query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to list audit entries", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*audit.Entry, 0)
+	for rows.Next() {
+		entry := &audit.Entry{}
+		var changes []byte
+		err := rows.Scan(
+			&entry.ID, &entry.TenantID, &entry.Service, &entry.ActorID, &entry.ActorRole, &entry.Action,
+			&entry.ResourceType, &entry.ResourceID, &changes, &entry.RequestID, &entry.TraceID,
+			&entry.IPAddress, &entry.Method, &entry.Path, &entry.StatusCode, &entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, errors.NewInternalError("failed to scan audit entry", err)
+		}
+
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+				return nil, 0, errors.NewInternalError("failed to decode audit changes", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.NewInternalError("failed to iterate audit entries", err)
+	}
+
+	return entries, total, nil
+}
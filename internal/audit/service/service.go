@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/audit/domain"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuditRepository interface {
+	List(ctx context.Context, filter domain.ListFilter, limit, offset int) ([]*audit.Entry, int64, error)
+}
+
+var _ domain.AuditService = (*AuditService)(nil)
+
+type AuditService struct {
+	repo   AuditRepository
+	tracer trace.Tracer
+}
+
+func NewAuditService(repo AuditRepository, tracer trace.Tracer) *AuditService {
+	return &AuditService{repo: repo, tracer: tracer}
+}
+
+func (s *AuditService) ListEntries(ctx context.Context, filter domain.ListFilter, page, pageSize int) (_ []*audit.Entry, _ int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "audit.service.list")
+	defer tracing.End(span, &err)
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.List(ctx, filter, pageSize, offset)
+}
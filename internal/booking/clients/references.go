@@ -0,0 +1,83 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/pkg/grpc/resourcepb"
+	"github.com/dmehra2102/booking-system/pkg/grpc/userpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCReferenceValidator checks booking references against the user and
+// resource services. A nil client skips the corresponding check. Lookups
+// are retried while the remote service is unavailable.
+type GRPCReferenceValidator struct {
+	users     userpb.UserServiceClient
+	resources resourcepb.ResourceServiceClient
+	retry     retry.Policy
+}
+
+func NewGRPCReferenceValidator(users userpb.UserServiceClient, resources resourcepb.ResourceServiceClient, retryPolicy retry.Policy) *GRPCReferenceValidator {
+	retryPolicy.Retryable = func(err error) bool {
+		return status.Code(err) == codes.Unavailable
+	}
+	return &GRPCReferenceValidator{users: users, resources: resources, retry: retryPolicy}
+}
+
+func (v *GRPCReferenceValidator) ValidateUser(ctx context.Context, userID string) error {
+	if v.users == nil {
+		return nil
+	}
+
+	err := v.retry.Do(ctx, func(ctx context.Context) error {
+		_, err := v.users.GetUser(ctx, &userpb.GetUserRequest{Id: userID})
+		return err
+	})
+	return fromStatus(err, "user-service", "user", userID)
+}
+
+// LookupUser returns the name and email address of userID. It is not
+// retried: a list is served without the user rather than delayed.
+func (v *GRPCReferenceValidator) LookupUser(ctx context.Context, userID string) (name, email string, err error) {
+	if v.users == nil {
+		return "", "", errors.NewNotFoundError("user")
+	}
+
+	resp, err := v.users.GetUser(ctx, &userpb.GetUserRequest{Id: userID})
+	if err != nil {
+		return "", "", fromStatus(err, "user-service", "user", userID)
+	}
+	return resp.GetUser().GetName(), resp.GetUser().GetEmail(), nil
+}
+
+func (v *GRPCReferenceValidator) ValidateResource(ctx context.Context, resourceID string) error {
+	if v.resources == nil {
+		return nil
+	}
+
+	err := v.retry.Do(ctx, func(ctx context.Context) error {
+		_, err := v.resources.GetResource(ctx, &resourcepb.GetResourceRequest{Id: resourceID})
+		return err
+	})
+	return fromStatus(err, "resource-service", "resource", resourceID)
+}
+
+// fromStatus maps a lookup failure to an application error. Missing or
+// malformed references are the caller's fault; anything else means the
+// remote service could not answer.
+func fromStatus(err error, service, kind, id string) error {
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.NotFound, codes.InvalidArgument:
+		return errors.NewValidationError(fmt.Sprintf("%s %s does not exist", kind, id), err)
+	default:
+		return errors.NewExternalError(service, fmt.Sprintf("failed to look up %s", kind), err)
+	}
+}
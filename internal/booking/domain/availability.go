@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxAvailabilityWindow bounds a single availability query.
+const MaxAvailabilityWindow = 31 * 24 * time.Hour
+
+// TimeSlot is a window in which Available units of a resource are free.
+type TimeSlot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Available int       `json:"available"`
+}
+
+type Availability struct {
+	ResourceID    string     `json:"resource_id"`
+	From          time.Time  `json:"from"`
+	To            time.Time  `json:"to"`
+	Capacity      int        `json:"capacity"`
+	BufferMinutes int        `json:"buffer_minutes"`
+	Slots         []TimeSlot `json:"slots"`
+}
+
+// FreeSlots splits [from, to) into the windows in which at least one unit
+// of capacity is free. Each active booking blocks the resource for buffer
+// on both sides, so any booking placed entirely inside a returned slot
+// passes FitsCapacity. Adjacent windows with equal availability are merged.
+func FreeSlots(from, to time.Time, bookings []*Booking, rules ResourceRules) []TimeSlot {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+
+	capacity := rules.Capacity
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	edges := make([]edge, 0, len(bookings)*2)
+	for _, booking := range bookings {
+		if !booking.IsActive() {
+			continue
+		}
+
+		start, end := booking.StartTime.Add(-rules.Buffer), booking.EndTime.Add(rules.Buffer)
+		if !start.Before(to) || !end.After(from) {
+			continue
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		edges = append(edges, edge{at: start, delta: 1}, edge{at: end, delta: -1})
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	slots := make([]TimeSlot, 0)
+	emit := func(start, end time.Time, used int) {
+		available := capacity - used
+		if available <= 0 || !start.Before(end) {
+			return
+		}
+		if n := len(slots); n > 0 && slots[n-1].End.Equal(start) && slots[n-1].Available == available {
+			slots[n-1].End = end
+			return
+		}
+		slots = append(slots, TimeSlot{Start: start, End: end, Available: available})
+	}
+
+	cursor, used := from, 0
+	for _, e := range edges {
+		emit(cursor, e.at, used)
+		cursor = e.at
+		used += e.delta
+	}
+	emit(cursor, to, used)
+
+	return slots
+}
@@ -1,17 +1,42 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
 
 type BookingStatus string
 
 const (
 	BookingStatusPending   BookingStatus = "pending"
 	BookingStatusConfirmed BookingStatus = "confirmed"
-	BookingStatusCancelled BookingStatus = "cancelled"
-	BookingStatusCompleted BookingStatus = "completed"
-	BookingStatusFailed    BookingStatus = "failed"
+	// BookingStatusInProgress bookings were checked in and not yet checked
+	// out.
+	BookingStatusInProgress BookingStatus = "in_progress"
+	BookingStatusCancelled  BookingStatus = "cancelled"
+	BookingStatusCompleted  BookingStatus = "completed"
+	BookingStatusFailed     BookingStatus = "failed"
+	// BookingStatusNoShow bookings were confirmed but never checked in.
+	BookingStatusNoShow BookingStatus = "no_show"
 )
 
+var BookingStatuses = []BookingStatus{
+	BookingStatusPending,
+	BookingStatusConfirmed,
+	BookingStatusInProgress,
+	BookingStatusCancelled,
+	BookingStatusCompleted,
+	BookingStatusFailed,
+	BookingStatusNoShow,
+}
+
+func (s BookingStatus) Valid() bool {
+	return slices.Contains(BookingStatuses, s)
+}
+
 type Booking struct {
 	ID            string        `json:"id" db:"id"`
 	UserID        string        `json:"user_id" db:"user_id"`
@@ -19,39 +44,87 @@ type Booking struct {
 	StartTime     time.Time     `json:"start_time" db:"start_time"`
 	EndTime       time.Time     `json:"end_time" db:"end_time"`
 	Status        BookingStatus `json:"status" db:"status"`
-	Amount        float64       `json:"amount" db:"amount"`
-	Currency      string        `json:"currency" db:"currency"`
+	Amount        money.Money   `json:"amount" db:"amount"`
 	PaymentID     *string       `json:"payment_id,omitempty" db:"payment_id"`
 	ReservationID *string       `json:"reservation_id,omitempty" db:"reservation_id"`
 	Notes         string        `json:"notes,omitempty" db:"notes"`
 	Metadata      string        `json:"metadata,omitempty" db:"metadata"`
-	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
-	UserName      string        `json:"user_name,omitempty" db:"user_name"`
-	UserEmail     string        `json:"user_email,omitempty" db:"omitempty"`
-	ResourceName  string        `json:"resource_name,omitempty" db:"resource_name"`
+	// SeriesID links the occurrences of a recurring booking.
+	SeriesID *string `json:"series_id,omitempty" db:"series_id"`
+	// Set once the booking is cancelled.
+	CancellationReason string      `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	CancellationFee    money.Money `json:"cancellation_fee,omitzero" db:"cancellation_fee"`
+	CancelledAt        *time.Time  `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CheckedInAt        *time.Time  `json:"checked_in_at,omitempty" db:"checked_in_at"`
+	CheckedOutAt       *time.Time  `json:"checked_out_at,omitempty" db:"checked_out_at"`
+	DeletedAt          *time.Time  `json:"deleted_at,omitempty" db:"deleted_at"`
+	CreatedAt          time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at" db:"updated_at"`
+	// Version is incremented by every update of the booking.
+	Version      int64  `json:"version" db:"version"`
+	UserName     string `json:"user_name,omitempty" db:"user_name"`
+	UserEmail    string `json:"user_email,omitempty" db:"omitempty"`
+	ResourceName string `json:"resource_name,omitempty" db:"resource_name"`
+	// Warnings name the details of a listed booking that could not be
+	// filled in, such as WarningUserUnavailable.
+	Warnings []string `json:"warnings,omitempty" db:"-"`
 }
 
+// WarningUserUnavailable marks a listed booking whose user could not be
+// looked up; its user_name and user_email are empty.
+const WarningUserUnavailable = "user_unavailable"
+
 type CreateBookingRequest struct {
-	UserID     string    `json:"user_id" validate:"required"`
-	ResourceID string    `json:"resource_id" validate:"required"`
+	UserID     string    `json:"user_id,omitempty" validate:"omitempty,uuid"`
+	ResourceID string    `json:"resource_id" validate:"required,uuid"`
 	StartTime  time.Time `json:"start_time" validate:"required"`
-	EndTime    time.Time `json:"end_time" validate:"required"`
+	EndTime    time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
 	Notes      string    `json:"notes,omitempty"`
+	PromoCode  string    `json:"promo_code,omitempty" validate:"omitempty,max=64"`
+	// Recurrence repeats the booking as a series. Only accepted when
+	// creating a series.
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
 }
 
 type UpdateBookingRequest struct {
 	StartTime *time.Time `json:"start_time,omitempty"`
 	EndTime   *time.Time `json:"end_time,omitempty"`
 	Notes     *string    `json:"notes,omitempty"`
+	// Version, when set, is the version of the booking the change was
+	// based on. It is taken from the If-Match header.
+	Version int64 `json:"-"`
 }
 
 type CancelBookingRequest struct {
 	Reason string `json:"reason" validate:"required"`
+	// Version, when set, is the version of the booking the cancellation
+	// was based on. It is taken from the If-Match header.
+	Version int64 `json:"-"`
+}
+
+// ResourceRules are the scheduling constraints of a bookable resource.
+type ResourceRules struct {
+	Capacity int
+	Buffer   time.Duration
 }
 
+// BookingComment is an append-only annotation on a booking. Unlike Notes,
+// comments are never edited or removed so the full history is retained.
+type BookingComment struct {
+	ID        string    `json:"id" db:"id"`
+	BookingID string    `json:"booking_id" db:"booking_id"`
+	AuthorID  string    `json:"author_id" db:"author_id"`
+	Text      string    `json:"text" db:"text"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type AddCommentRequest struct {
+	Text string `json:"text" validate:"required,max=2000"`
+}
+
+// IsActive reports whether the booking occupies its slot.
 func (b *Booking) IsActive() bool {
-	return b.Status == BookingStatusPending || b.Status == BookingStatusConfirmed
+	return b.Status == BookingStatusPending || b.Status == BookingStatusConfirmed || b.Status == BookingStatusInProgress
 }
 
 func (b *Booking) CanBeCancelled() bool {
@@ -62,6 +135,20 @@ func (b *Booking) CanBeUpdated() bool {
 	return b.Status == BookingStatusPending
 }
 
+func (b *Booking) CanBeCheckedIn() bool {
+	return b.Status == BookingStatusConfirmed
+}
+
+func (b *Booking) CanBeCheckedOut() bool {
+	return b.Status == BookingStatusInProgress
+}
+
+// HasConfirmation reports whether the booking was confirmed, so that its
+// confirmation documents can be handed out.
+func (b *Booking) HasConfirmation() bool {
+	return b.Status == BookingStatusConfirmed || b.Status == BookingStatusInProgress || b.Status == BookingStatusCompleted
+}
+
 func (b *Booking) Duration() time.Duration {
 	return b.EndTime.Sub(b.StartTime)
 }
@@ -71,3 +158,69 @@ func (b *Booking) IsOverlapping(other *Booking) bool {
 		b.StartTime.Before(other.EndTime) &&
 		b.EndTime.After(other.StartTime)
 }
+
+// IsOverlappingWithBuffer is like IsOverlapping but requires buffer of
+// turnaround time after each booking before the next one may start.
+func (b *Booking) IsOverlappingWithBuffer(other *Booking, buffer time.Duration) bool {
+	return b.ResourceID == other.ResourceID &&
+		b.StartTime.Before(other.EndTime.Add(buffer)) &&
+		b.EndTime.Add(buffer).After(other.StartTime)
+}
+
+// PeakConcurrency returns the highest number of active bookings from others
+// that overlap b's window on the same resource at any single instant. Each
+// booking occupies the resource for buffer beyond its end time. A saved b
+// found among others is not counted against itself.
+func (b *Booking) PeakConcurrency(others []*Booking, buffer time.Duration) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+
+	windowEnd := b.EndTime.Add(buffer)
+
+	edges := make([]edge, 0, len(others)*2)
+	for _, other := range others {
+		if (other.ID != "" && other.ID == b.ID) || !other.IsActive() || !b.IsOverlappingWithBuffer(other, buffer) {
+			continue
+		}
+
+		start, end := other.StartTime, other.EndTime.Add(buffer)
+		if start.Before(b.StartTime) {
+			start = b.StartTime
+		}
+		if end.After(windowEnd) {
+			end = windowEnd
+		}
+		edges = append(edges, edge{at: start, delta: 1}, edge{at: end, delta: -1})
+	}
+
+	// Ends sort before starts at the same instant so back-to-back bookings
+	// are not counted as concurrent.
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	current, peak := 0, 0
+	for _, e := range edges {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+
+	return peak
+}
+
+// FitsCapacity reports whether b can be added alongside others without the
+// resource exceeding its concurrent booking capacity.
+func (b *Booking) FitsCapacity(others []*Booking, rules ResourceRules) bool {
+	capacity := rules.Capacity
+	if capacity < 1 {
+		capacity = 1
+	}
+	return b.PeakConcurrency(others, rules.Buffer) < capacity
+}
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+var testDay = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+// booking returns an active booking of resource r-1 from hour start to
+// hour end of testDay.
+func booking(id string, start, end float64) *Booking {
+	return &Booking{
+		ID:         id,
+		ResourceID: "r-1",
+		Status:     BookingStatusConfirmed,
+		StartTime:  testDay.Add(time.Duration(start * float64(time.Hour))),
+		EndTime:    testDay.Add(time.Duration(end * float64(time.Hour))),
+	}
+}
+
+func TestFitsCapacity(t *testing.T) {
+	cancelled := booking("c", 9, 11)
+	cancelled.Status = BookingStatusCancelled
+
+	otherResource := booking("o", 9, 11)
+	otherResource.ResourceID = "r-2"
+
+	tests := []struct {
+		name     string
+		booking  *Booking
+		others   []*Booking
+		capacity int
+		wantPeak int
+		wantFits bool
+	}{
+		{
+			name:     "empty resource",
+			booking:  booking("", 9, 10),
+			capacity: 1,
+			wantPeak: 0,
+			wantFits: true,
+		},
+		{
+			name:     "overlap with capacity 1",
+			booking:  booking("", 9, 10),
+			others:   []*Booking{booking("a", 9.5, 10.5)},
+			capacity: 1,
+			wantPeak: 1,
+			wantFits: false,
+		},
+		{
+			name:     "zero capacity counts as 1",
+			booking:  booking("", 9, 10),
+			others:   []*Booking{booking("a", 9.5, 10.5)},
+			capacity: 0,
+			wantPeak: 1,
+			wantFits: false,
+		},
+		{
+			name:     "within capacity",
+			booking:  booking("", 9, 12),
+			others:   []*Booking{booking("a", 9, 10), booking("b", 9.5, 11)},
+			capacity: 3,
+			wantPeak: 2,
+			wantFits: true,
+		},
+		{
+			name:     "capacity plus one",
+			booking:  booking("", 9, 12),
+			others:   []*Booking{booking("a", 9, 10), booking("b", 9.5, 11), booking("c", 9.75, 10.25)},
+			capacity: 3,
+			wantPeak: 3,
+			wantFits: false,
+		},
+		{
+			name:     "bookings in sequence do not add up",
+			booking:  booking("", 9, 12),
+			others:   []*Booking{booking("a", 9, 10), booking("b", 10, 11), booking("c", 11, 12)},
+			capacity: 2,
+			wantPeak: 1,
+			wantFits: true,
+		},
+		{
+			name:     "inactive and other resources are ignored",
+			booking:  booking("", 9, 12),
+			others:   []*Booking{cancelled, otherResource},
+			capacity: 1,
+			wantPeak: 0,
+			wantFits: true,
+		},
+		{
+			name:     "saved booking is not counted against itself",
+			booking:  booking("a", 9, 10),
+			others:   []*Booking{booking("a", 9, 10)},
+			capacity: 1,
+			wantPeak: 0,
+			wantFits: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if peak := tt.booking.PeakConcurrency(tt.others, 0); peak != tt.wantPeak {
+				t.Errorf("PeakConcurrency() = %d, want %d", peak, tt.wantPeak)
+			}
+			if fits := tt.booking.FitsCapacity(tt.others, ResourceRules{Capacity: tt.capacity}); fits != tt.wantFits {
+				t.Errorf("FitsCapacity() = %v, want %v", fits, tt.wantFits)
+			}
+		})
+	}
+}
+
+func TestOverlapWithBuffer(t *testing.T) {
+	const buffer = 15 * time.Minute
+
+	tests := []struct {
+		name     string
+		booking  *Booking
+		other    *Booking
+		wantFits bool
+	}{
+		{
+			name:     "exactly buffer after",
+			booking:  booking("", 10.25, 11),
+			other:    booking("a", 9, 10),
+			wantFits: true,
+		},
+		{
+			name:     "exactly buffer before",
+			booking:  booking("", 8, 8.75),
+			other:    booking("a", 9, 10),
+			wantFits: true,
+		},
+		{
+			name:     "within buffer after",
+			booking:  booking("", 10.1, 11),
+			other:    booking("a", 9, 10),
+			wantFits: false,
+		},
+		{
+			name:     "within buffer before",
+			booking:  booking("", 8, 8.9),
+			other:    booking("a", 9, 10),
+			wantFits: false,
+		},
+		{
+			name:     "back to back",
+			booking:  booking("", 10, 11),
+			other:    booking("a", 9, 10),
+			wantFits: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if overlaps := tt.booking.IsOverlappingWithBuffer(tt.other, buffer); overlaps == tt.wantFits {
+				t.Errorf("IsOverlappingWithBuffer() = %v, want %v", overlaps, !tt.wantFits)
+			}
+			rules := ResourceRules{Capacity: 1, Buffer: buffer}
+			if fits := tt.booking.FitsCapacity([]*Booking{tt.other}, rules); fits != tt.wantFits {
+				t.Errorf("FitsCapacity() = %v, want %v", fits, tt.wantFits)
+			}
+			// Without a buffer only the bookings themselves must not overlap
+			if !tt.booking.FitsCapacity([]*Booking{tt.other}, ResourceRules{Capacity: 1}) {
+				t.Error("FitsCapacity() without buffer = false, want true")
+			}
+		})
+	}
+}
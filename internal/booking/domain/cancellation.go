@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+// FeeTier charges Percent of the booking amount when a booking is
+// cancelled less than Within before it starts.
+type FeeTier struct {
+	Within  time.Duration
+	Percent float64
+}
+
+// CancellationPolicy decides what a cancellation costs. Cancelling at
+// least FreeWindow before start_time is free; closer to the start the
+// narrowest tier that still covers the remaining time applies.
+type CancellationPolicy struct {
+	FreeWindow time.Duration
+	Tiers      []FeeTier
+}
+
+// CancellationQuote is the outcome of applying a policy to a booking.
+type CancellationQuote struct {
+	FeePercent float64     `json:"fee_percent"`
+	Fee        money.Money `json:"fee"`
+	Refund     money.Money `json:"refund"`
+}
+
+// Quote prices cancelling booking at the given time. Bookings that have
+// already started fall into the narrowest tier.
+func (p CancellationPolicy) Quote(booking *Booking, at time.Time) CancellationQuote {
+	remaining := booking.StartTime.Sub(at)
+
+	percent := 0.0
+	if remaining < p.FreeWindow {
+		narrowest := time.Duration(math.MaxInt64)
+		for _, tier := range p.Tiers {
+			if remaining < tier.Within && tier.Within < narrowest {
+				narrowest = tier.Within
+				percent = tier.Percent
+			}
+		}
+	}
+
+	fee := booking.Amount.Percent(percent)
+	return CancellationQuote{
+		FeePercent: percent,
+		Fee:        fee,
+		Refund:     money.New(booking.Amount.Amount-fee.Amount, booking.Amount.Currency),
+	}
+}
+
+// ParseFeeTiers parses a comma separated list of "duration:percent" pairs,
+// e.g. "24h:50,2h:100".
+func ParseFeeTiers(s string) ([]FeeTier, error) {
+	tiers := make([]FeeTier, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		within, percent, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid fee tier %q: expected duration:percent", part)
+		}
+
+		d, err := time.ParseDuration(within)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid fee tier %q: bad duration", part)
+		}
+
+		pct, err := strconv.ParseFloat(percent, 64)
+		if err != nil || pct < 0 || pct > 100 {
+			return nil, fmt.Errorf("invalid fee tier %q: percent must be between 0 and 100", part)
+		}
+
+		tiers = append(tiers, FeeTier{Within: d, Percent: pct})
+	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].Within > tiers[j].Within
+	})
+
+	return tiers, nil
+}
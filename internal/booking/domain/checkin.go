@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// CheckInPolicy decides when a booking can be checked in. Check-in opens
+// Early before start_time and closes Grace after it, or at end_time for
+// shorter bookings. Confirmed bookings nobody checked in by then are
+// no-shows.
+type CheckInPolicy struct {
+	Early time.Duration
+	Grace time.Duration
+}
+
+// Opens returns when booking can first be checked in.
+func (p CheckInPolicy) Opens(booking *Booking) time.Time {
+	return booking.StartTime.Add(-p.Early)
+}
+
+// Closes returns when booking can last be checked in.
+func (p CheckInPolicy) Closes(booking *Booking) time.Time {
+	closes := booking.StartTime.Add(p.Grace)
+	if closes.After(booking.EndTime) {
+		return booking.EndTime
+	}
+	return closes
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// UserData is the part of a data export the booking service holds.
+type UserData struct {
+	Bookings []*Booking        `json:"bookings"`
+	Comments []*BookingComment `json:"comments"`
+	Waitlist []*WaitlistEntry  `json:"waitlist"`
+	Reviews  []*UserReview     `json:"reviews"`
+}
+
+// UserReview is a review a user wrote, as included in their data export.
+type UserReview struct {
+	ID         string    `json:"id"`
+	BookingID  string    `json:"booking_id"`
+	ResourceID string    `json:"resource_id"`
+	Rating     int       `json:"rating"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+}
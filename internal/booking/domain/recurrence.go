@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily  RecurrenceFrequency = "daily"
+	RecurrenceWeekly RecurrenceFrequency = "weekly"
+)
+
+const (
+	// MaxOccurrences bounds the bookings a series expands to.
+	MaxOccurrences = 100
+	// MaxSeriesSpan bounds how far after its first booking a series may
+	// run.
+	MaxSeriesSpan = 366 * 24 * time.Hour
+)
+
+// Recurrence repeats a booking like a simplified iCalendar RRULE: every
+// Interval days or weeks, on Weekdays for weekly series, until Count
+// bookings were made or Until is passed. Weekdays follow time.Weekday (0 is
+// Sunday) and default to the weekday of the first booking. Days are
+// counted in UTC, like the resource open hours.
+type Recurrence struct {
+	Frequency RecurrenceFrequency `json:"frequency" validate:"required,oneof=daily weekly"`
+	Interval  int                 `json:"interval,omitempty" validate:"min=0,max=52"`
+	Weekdays  []int               `json:"weekdays,omitempty" validate:"max=7,dive,min=0,max=6"`
+	Count     int                 `json:"count,omitempty" validate:"required_without=Until,omitempty,min=2,max=100"`
+	Until     *time.Time          `json:"until,omitempty" validate:"required_without=Count"`
+}
+
+// BookingSeries is the set of bookings created from one recurring request.
+// Each occurrence is a booking of its own that can be cancelled on its
+// own.
+type BookingSeries struct {
+	ID       string     `json:"id"`
+	Bookings []*Booking `json:"bookings"`
+}
+
+// SeriesScope selects the occurrences of a series a cancellation applies
+// to, counted from the occurrence it names.
+type SeriesScope string
+
+const (
+	ScopeThisOccurrence   SeriesScope = "this_occurrence"
+	ScopeThisAndFollowing SeriesScope = "this_and_following"
+	ScopeAllInSeries      SeriesScope = "all_in_series"
+)
+
+// CancelSeriesRequest cancels the occurrences of a series in Scope, all of
+// them when it is empty. The narrower scopes name the occurrence they
+// start at with BookingID.
+type CancelSeriesRequest struct {
+	Reason    string      `json:"reason" validate:"required"`
+	Scope     SeriesScope `json:"scope,omitempty" validate:"omitempty,oneof=this_occurrence this_and_following all_in_series"`
+	BookingID string      `json:"booking_id,omitempty" validate:"required_if=Scope this_occurrence,required_if=Scope this_and_following,omitempty,uuid"`
+}
+
+// Includes reports whether booking is in the scope starting at the
+// occurrence from, which is nil for ScopeAllInSeries.
+func (s SeriesScope) Includes(booking, from *Booking) bool {
+	switch s {
+	case ScopeThisOccurrence:
+		return booking.ID == from.ID
+	case ScopeThisAndFollowing:
+		return !booking.StartTime.Before(from.StartTime)
+	default:
+		return true
+	}
+}
+
+// Occurrences returns the start times of the bookings of a series whose
+// first booking starts at start. As in an RRULE, start is always the first
+// occurrence even when it is not on one of Weekdays.
+func (r *Recurrence) Occurrences(start time.Time) ([]time.Time, error) {
+	start = start.UTC()
+	if len(r.Weekdays) > 0 && r.Frequency != RecurrenceWeekly {
+		return nil, fmt.Errorf("weekdays only apply to weekly recurrences")
+	}
+
+	horizon := start.Add(MaxSeriesSpan)
+	if r.Until != nil {
+		if r.Until.After(horizon) {
+			return nil, fmt.Errorf("recurrence must end within %d days", int(MaxSeriesSpan.Hours()/24))
+		}
+		horizon = *r.Until
+	}
+
+	interval := max(r.Interval, 1)
+	weekdays := r.Weekdays
+	if len(weekdays) == 0 {
+		weekdays = []int{int(start.Weekday())}
+	}
+
+	// Without a count stop one past the limit to tell it was exceeded
+	limit := r.Count
+	if limit == 0 {
+		limit = MaxOccurrences + 1
+	}
+
+	occurrences := []time.Time{start}
+	for day := 1; len(occurrences) < limit; day++ {
+		t := start.AddDate(0, 0, day)
+		if t.After(horizon) {
+			break
+		}
+
+		switch r.Frequency {
+		case RecurrenceDaily:
+			if day%interval != 0 {
+				continue
+			}
+		case RecurrenceWeekly:
+			// Weeks start on Sunday, as weekdays are numbered
+			week := (day + int(start.Weekday())) / 7
+			if week%interval != 0 || !slices.Contains(weekdays, int(t.Weekday())) {
+				continue
+			}
+		}
+		occurrences = append(occurrences, t)
+	}
+
+	if len(occurrences) > MaxOccurrences {
+		return nil, fmt.Errorf("recurrence must not have more than %d occurrences", MaxOccurrences)
+	}
+	if len(occurrences) < r.Count {
+		return nil, fmt.Errorf("recurrence must end within %d days", int(MaxSeriesSpan.Hours()/24))
+	}
+	if len(occurrences) < 2 {
+		return nil, fmt.Errorf("recurrence must have at least two occurrences")
+	}
+
+	return occurrences, nil
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/documents"
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// BookingSortFields are the fields ListBookings can sort by.
+var BookingSortFields = []string{"created_at", "start_time"}
+
+// ListBookingsFilter narrows ListBookings. Zero fields match every booking;
+// From and To select bookings that overlap the window.
+type ListBookingsFilter struct {
+	UserID     string
+	ResourceID string
+	SeriesID   string
+	Status     BookingStatus
+	From       *time.Time
+	To         *time.Time
+}
+
+// BookingService is the application API of the booking module. The HTTP
+// handler depends on it and service.BookingService implements it.
+type BookingService interface {
+	CreateBooking(ctx context.Context, req *CreateBookingRequest) (*Booking, error)
+	CreateSeries(ctx context.Context, req *CreateBookingRequest) (*BookingSeries, error)
+	GetSeries(ctx context.Context, id string) (*BookingSeries, error)
+	CancelSeries(ctx context.Context, id string, req *CancelSeriesRequest) (*BookingSeries, error)
+	GetBooking(ctx context.Context, id string) (*Booking, error)
+	GetConfirmation(ctx context.Context, id string) (*documents.Confirmation, error)
+	UpdateBooking(ctx context.Context, id string, req *UpdateBookingRequest) (*Booking, error)
+	CancelBooking(ctx context.Context, id string, req *CancelBookingRequest) (*Booking, error)
+	CheckIn(ctx context.Context, id string) (*Booking, error)
+	CheckOut(ctx context.Context, id string) (*Booking, error)
+	DeleteBooking(ctx context.Context, id string) error
+	RestoreBooking(ctx context.Context, id string) (*Booking, error)
+	ListBookings(ctx context.Context, filter ListBookingsFilter, params pagination.Params) ([]*Booking, *pagination.Result, error)
+	ExportBookings(ctx context.Context, filter ListBookingsFilter, w export.Writer) (int, error)
+	AddComment(ctx context.Context, bookingID, authorID string, req *AddCommentRequest) (*BookingComment, error)
+	ListComments(ctx context.Context, bookingID string) ([]*BookingComment, error)
+	GetAvailability(ctx context.Context, resourceID string, from, to time.Time) (*Availability, error)
+}
+
+// WaitlistService is the waitlist API of the booking module. The HTTP
+// handler depends on it and service.WaitlistService implements it.
+type WaitlistService interface {
+	JoinWaitlist(ctx context.Context, userID string, req *JoinWaitlistRequest) (*WaitlistEntry, error)
+	ListWaitlist(ctx context.Context, userID string) ([]*WaitlistEntry, error)
+	LeaveWaitlist(ctx context.Context, userID, id string) error
+}
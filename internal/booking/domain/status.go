@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// BookingStage is a step of a booking through the booking saga and its
+// life after, as followed by clients streaming its status.
+type BookingStage string
+
+const (
+	BookingStageRequested         BookingStage = "requested"
+	BookingStageReserved          BookingStage = "reserved"
+	BookingStageReservationFailed BookingStage = "reservation_failed"
+	BookingStagePaid              BookingStage = "paid"
+	BookingStagePaymentFailed     BookingStage = "payment_failed"
+	BookingStageConfirmed         BookingStage = "confirmed"
+	BookingStageUpdated           BookingStage = "updated"
+	BookingStageCancelled         BookingStage = "cancelled"
+	// BookingStageExpired bookings lost their slot because they were not
+	// paid before the hold expired.
+	BookingStageExpired    BookingStage = "expired"
+	BookingStageCheckedIn  BookingStage = "checked_in"
+	BookingStageCheckedOut BookingStage = "checked_out"
+	BookingStageNoShow     BookingStage = "no_show"
+)
+
+// StatusUpdate reports that a booking reached a stage. Status is the
+// status of the booking after it, when the stage sets one.
+type StatusUpdate struct {
+	BookingID string        `json:"booking_id"`
+	Stage     BookingStage  `json:"stage"`
+	Status    BookingStatus `json:"status,omitempty"`
+	Reason    string        `json:"reason,omitempty"`
+	At        time.Time     `json:"at"`
+}
+
+// Final reports whether the booking cannot change status after the
+// update.
+func (u *StatusUpdate) Final() bool {
+	return u.Status.Final()
+}
+
+// Final reports whether a booking in the status cannot change status
+// anymore.
+func (s BookingStatus) Final() bool {
+	switch s {
+	case BookingStatusCancelled, BookingStatusCompleted, BookingStatusFailed, BookingStatusNoShow:
+		return true
+	}
+	return false
+}
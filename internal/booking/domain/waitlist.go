@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+type WaitlistStatus string
+
+const (
+	// WaitlistStatusWaiting entries queue for their slot to free up.
+	WaitlistStatusWaiting WaitlistStatus = "waiting"
+	// WaitlistStatusOffered entries hold a freed slot for their user until
+	// the offer expires.
+	WaitlistStatusOffered WaitlistStatus = "offered"
+	WaitlistStatusBooked  WaitlistStatus = "booked"
+	WaitlistStatusLeft    WaitlistStatus = "left"
+	WaitlistStatusExpired WaitlistStatus = "expired"
+)
+
+// WaitlistEntry queues a user for a slot of a resource that was fully
+// booked when they asked for it. Entries are offered the slot in the order
+// they joined when a cancellation frees it.
+type WaitlistEntry struct {
+	ID             string         `json:"id" db:"id"`
+	UserID         string         `json:"user_id" db:"user_id"`
+	ResourceID     string         `json:"resource_id" db:"resource_id"`
+	StartTime      time.Time      `json:"start_time" db:"start_time"`
+	EndTime        time.Time      `json:"end_time" db:"end_time"`
+	Status         WaitlistStatus `json:"status" db:"status"`
+	OfferExpiresAt *time.Time     `json:"offer_expires_at,omitempty" db:"offer_expires_at"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+type JoinWaitlistRequest struct {
+	ResourceID string    `json:"resource_id" validate:"required,uuid"`
+	StartTime  time.Time `json:"start_time" validate:"required"`
+	EndTime    time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+}
+
+func (e *WaitlistEntry) IsActive() bool {
+	return e.Status == WaitlistStatusWaiting || e.Status == WaitlistStatusOffered
+}
+
+// Hold returns the slot an offered entry holds as a pending booking, so it
+// occupies capacity like one until the offer is taken or expires.
+func (e *WaitlistEntry) Hold() *Booking {
+	return &Booking{
+		ID:         e.ID,
+		UserID:     e.UserID,
+		ResourceID: e.ResourceID,
+		StartTime:  e.StartTime,
+		EndTime:    e.EndTime,
+		Status:     BookingStatusPending,
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/booking/service"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler adapts inventory, booking and user events consumed from
+// Kafka to booking, waitlist and privacy service calls.
+type EventHandler struct {
+	service  *service.BookingService
+	waitlist *service.WaitlistService
+	privacy  *service.PrivacyService
+	logger   *logger.Logger
+}
+
+func NewEventHandler(service *service.BookingService, waitlist *service.WaitlistService, privacy *service.PrivacyService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{
+		service:  service,
+		waitlist: waitlist,
+		privacy:  privacy,
+		logger:   logger,
+	}
+}
+
+// HandleInventoryReleased fails a booking whose hold expired before it was
+// paid. Releases for other reasons follow from the booking's own state
+// changes.
+func (h *EventHandler) HandleInventoryReleased(ctx context.Context, event events.InventoryReleasedEvent) error {
+	if event.Data.Reason != events.ReleaseReasonHoldExpired {
+		return nil
+	}
+
+	err := h.service.FailBooking(ctx, event.Data.BookingID, event.Data.Reason)
+	if err != nil && errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+		h.logger.WithContext(ctx).With("booking_id", event.Data.BookingID).Warn("hold expired for unknown booking")
+		return nil
+	}
+	return err
+}
+
+// HandleBookingCancelled offers the slot a cancellation freed to the
+// waitlist.
+func (h *EventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	err := h.waitlist.OfferFreedSlot(ctx, event.Data.BookingID)
+	if err != nil && errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+		h.logger.WithContext(ctx).With("booking_id", event.Data.BookingID).Warn("cancelled booking no longer exists")
+		return nil
+	}
+	return err
+}
+
+// HandleUserDataExportRequested sends the bookings part of a data export.
+func (h *EventHandler) HandleUserDataExportRequested(ctx context.Context, event events.UserDataExportRequestedEvent) error {
+	return h.privacy.ExportUserData(ctx, event.Data.ExportID, event.Data.UserID)
+}
+
+// HandleUserDeleted erases the personal data of a deleted user.
+func (h *EventHandler) HandleUserDeleted(ctx context.Context, event events.UserDeletedEvent) error {
+	return h.privacy.EraseUserData(ctx, event.Data.UserID)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/pkg/grpc/bookingpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type BookingGRPCServer struct {
+	bookingpb.UnimplementedBookingServiceServer
+	service domain.BookingService
+}
+
+func NewBookingGRPCServer(service domain.BookingService) *BookingGRPCServer {
+	return &BookingGRPCServer{service: service}
+}
+
+func (s *BookingGRPCServer) GetBooking(ctx context.Context, req *bookingpb.GetBookingRequest) (*bookingpb.GetBookingResponse, error) {
+	if err := grpcserver.RequireUUID("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	booking, err := s.service.GetBooking(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcserver.ToStatus(err)
+	}
+
+	return &bookingpb.GetBookingResponse{
+		Booking: &bookingpb.Booking{
+			Id:         booking.ID,
+			UserId:     booking.UserID,
+			ResourceId: booking.ResourceID,
+			StartTime:  timestamppb.New(booking.StartTime),
+			EndTime:    timestamppb.New(booking.EndTime),
+			Status:     string(booking.Status),
+			Amount:     booking.Amount.Float(),
+			Currency:   string(booking.Amount.Currency),
+			CreatedAt:  timestamppb.New(booking.CreatedAt),
+			UpdatedAt:  timestamppb.New(booking.UpdatedAt),
+		},
+	}, nil
+}
+
+func (s *BookingGRPCServer) GetAvailability(ctx context.Context, req *bookingpb.GetAvailabilityRequest) (*bookingpb.GetAvailabilityResponse, error) {
+	if err := grpcserver.RequireUUID("resource_id", req.GetResourceId()); err != nil {
+		return nil, err
+	}
+	if req.GetFrom() == nil || req.GetTo() == nil {
+		return nil, status.Error(codes.InvalidArgument, "from and to are required")
+	}
+
+	availability, err := s.service.GetAvailability(ctx, req.GetResourceId(), req.GetFrom().AsTime(), req.GetTo().AsTime())
+	if err != nil {
+		return nil, grpcserver.ToStatus(err)
+	}
+
+	slots := make([]*bookingpb.TimeSlot, len(availability.Slots))
+	for i, slot := range availability.Slots {
+		slots[i] = &bookingpb.TimeSlot{
+			Start:     timestamppb.New(slot.Start),
+			End:       timestamppb.New(slot.End),
+			Available: int32(slot.Available),
+		}
+	}
+
+	return &bookingpb.GetAvailabilityResponse{
+		ResourceId:    availability.ResourceID,
+		Capacity:      int32(availability.Capacity),
+		BufferMinutes: int32(availability.BufferMinutes),
+		Slots:         slots,
+	}, nil
+}
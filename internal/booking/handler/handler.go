@@ -0,0 +1,512 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/documents"
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type BookingHandler struct {
+	service domain.BookingService
+	logger  *logger.Logger
+	tracer  trace.Tracer
+}
+
+func NewBookingHandler(service domain.BookingService, logger *logger.Logger, tracer trace.Tracer) *BookingHandler {
+	return &BookingHandler{
+		service: service,
+		logger:  logger,
+		tracer:  tracer,
+	}
+}
+
+func (h *BookingHandler) CreateBooking(c *gin.Context) {
+	var req domain.CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	userID, err := bookingUser(c, req.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	req.UserID = userID
+
+	booking, err := h.service.CreateBooking(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.CreatedWithLocation(c, path.Join(c.Request.URL.Path, booking.ID), booking)
+}
+
+// CreateSeries books every occurrence of a recurring booking.
+func (h *BookingHandler) CreateSeries(c *gin.Context) {
+	var req domain.CreateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	userID, err := bookingUser(c, req.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	req.UserID = userID
+
+	series, err := h.service.CreateSeries(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.CreatedWithLocation(c, path.Join(c.Request.URL.Path, series.ID), series)
+}
+
+func (h *BookingHandler) GetSeries(c *gin.Context) {
+	series, err := h.ownSeries(c, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, series)
+}
+
+// CancelSeries cancels the upcoming occurrences of a recurring booking.
+func (h *BookingHandler) CancelSeries(c *gin.Context) {
+	var req domain.CancelSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := h.ownSeries(c, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	series, err := h.service.CancelSeries(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, series)
+}
+
+func (h *BookingHandler) GetBooking(c *gin.Context) {
+	booking, err := h.ownBooking(c, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.SuccessWithETag(c, booking.Version, booking)
+}
+
+// GetConfirmationPDF serves the PDF receipt of a confirmed booking.
+func (h *BookingHandler) GetConfirmationPDF(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.ownBooking(c, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	confirmation, err := h.service.GetConfirmation(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+documents.Filename(confirmation.BookingID, "pdf")+`"`)
+	c.Data(http.StatusOK, documents.ContentTypePDF, documents.PDF(c.Request.Context(), confirmation))
+}
+
+// GetConfirmationICS serves a confirmed booking as an iCalendar event.
+func (h *BookingHandler) GetConfirmationICS(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.ownBooking(c, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	confirmation, err := h.service.GetConfirmation(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+documents.Filename(confirmation.BookingID, "ics")+`"`)
+	c.Data(http.StatusOK, documents.ContentTypeICS, documents.ICS(confirmation))
+}
+
+// UpdateBooking moves or annotates a booking. With If-Match, the change is
+// only made if the booking is still at that version.
+func (h *BookingHandler) UpdateBooking(c *gin.Context) {
+	id := c.Param("id")
+
+	var req domain.UpdateBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	version, err := response.IfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	req.Version = version
+
+	if _, err := h.ownBooking(c, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	booking, err := h.service.UpdateBooking(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.SuccessWithETag(c, booking.Version, booking)
+}
+
+// CancelBooking cancels a booking. With If-Match, it is only cancelled if
+// it is still at that version.
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	id := c.Param("id")
+
+	var req domain.CancelBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	version, err := response.IfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	req.Version = version
+
+	if _, err := h.ownBooking(c, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	booking, err := h.service.CancelBooking(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.SuccessWithETag(c, booking.Version, booking)
+}
+
+// CheckIn starts a confirmed booking within its check-in window. Staff
+// check in guests on their behalf.
+func (h *BookingHandler) CheckIn(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.ownBooking(c, id, auth.RoleStaff); err != nil {
+		c.Error(err)
+		return
+	}
+
+	booking, err := h.service.CheckIn(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, booking)
+}
+
+// CheckOut completes a booking that was checked in.
+func (h *BookingHandler) CheckOut(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.ownBooking(c, id, auth.RoleStaff); err != nil {
+		c.Error(err)
+		return
+	}
+
+	booking, err := h.service.CheckOut(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, booking)
+}
+
+// DeleteBooking soft-deletes a booking. Admins only.
+func (h *BookingHandler) DeleteBooking(c *gin.Context) {
+	if err := h.service.DeleteBooking(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreBooking brings back a deleted booking. Admins only.
+func (h *BookingHandler) RestoreBooking(c *gin.Context) {
+	booking, err := h.service.RestoreBooking(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, booking)
+}
+
+// ListBookings lists bookings with page or cursor pagination, sorted by
+// created_at or start_time with a "-" prefix for descending order. Filters
+// are user_id, resource_id, series_id, status and an RFC 3339 from/to
+// window. Only admins may list other users' bookings; everyone else sees
+// their own.
+func (h *BookingHandler) ListBookings(c *gin.Context) {
+	filter, err := listBookingsFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !middleware.HasRole(c, auth.RoleAdmin) {
+		if filter.UserID != "" && filter.UserID != c.GetString("user_id") {
+			c.Error(errors.NewForbiddenError("you can only list your own bookings"))
+			return
+		}
+		filter.UserID = c.GetString("user_id")
+	}
+
+	h.listBookings(c, filter)
+}
+
+// ListUserBookings lists the bookings of the user in the path. It accepts
+// the same filters as ListBookings except user_id.
+func (h *BookingHandler) ListUserBookings(c *gin.Context) {
+	id := c.Param("id")
+	if c.GetString("user_id") != id && !middleware.HasRole(c, auth.RoleAdmin) {
+		c.Error(errors.NewForbiddenError("you can only list your own bookings"))
+		return
+	}
+
+	filter, err := listBookingsFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	filter.UserID = id
+
+	h.listBookings(c, filter)
+}
+
+func (h *BookingHandler) listBookings(c *gin.Context, filter domain.ListBookingsFilter) {
+	params, err := pagination.FromQuery(c, domain.BookingSortFields, pagination.Sort{Field: "created_at", Desc: true})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	bookings, result, err := h.service.ListBookings(c.Request.Context(), filter, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, bookings, pagination.Response(params, result))
+}
+
+func listBookingsFilter(c *gin.Context) (domain.ListBookingsFilter, error) {
+	filter := domain.ListBookingsFilter{
+		UserID:     c.Query("user_id"),
+		ResourceID: c.Query("resource_id"),
+		SeriesID:   c.Query("series_id"),
+		Status:     domain.BookingStatus(c.Query("status")),
+	}
+
+	for name, id := range map[string]string{"user_id": filter.UserID, "resource_id": filter.ResourceID, "series_id": filter.SeriesID} {
+		if id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return filter, errors.NewValidationError("validation failed", validation.Invalid(name, "uuid", ""))
+		}
+	}
+
+	if filter.Status != "" && !filter.Status.Valid() {
+		return filter, errors.NewValidationError("validation failed", validation.Invalid("status", "invalid", ""))
+	}
+
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := c.Query(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, errors.NewValidationError("validation failed", validation.Invalid(name, "rfc3339", ""))
+			}
+			*target = &parsed
+		}
+	}
+
+	return filter, nil
+}
+
+// ExportSource reads the ListBookings filters of an admin export of
+// bookings.
+func (h *BookingHandler) ExportSource(c *gin.Context) (export.Source, error) {
+	filter, err := listBookingsFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, w export.Writer) (int, error) {
+		return h.service.ExportBookings(ctx, filter, w)
+	}, nil
+}
+
+// AddComment appends a comment to a booking. Support staff annotate the
+// bookings of any user.
+func (h *BookingHandler) AddComment(c *gin.Context) {
+	id := c.Param("id")
+
+	var req domain.AddCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if _, err := h.ownBooking(c, id, auth.RoleStaff); err != nil {
+		c.Error(err)
+		return
+	}
+
+	comment, err := h.service.AddComment(c.Request.Context(), id, c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Comments are only listed with their booking; there is no URL of
+	// their own for a Location header
+	response.Created(c, comment)
+}
+
+// ListComments returns the comments of a booking, oldest first, to its
+// owner and to staff.
+func (h *BookingHandler) ListComments(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.ownBooking(c, id, auth.RoleStaff); err != nil {
+		c.Error(err)
+		return
+	}
+
+	comments, err := h.service.ListComments(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, comments)
+}
+
+// bookingUser returns the user a new booking is made for: the
+// authenticated user, unless an admin books for userID.
+func bookingUser(c *gin.Context, userID string) (string, error) {
+	if userID == "" || userID == c.GetString("user_id") {
+		return c.GetString("user_id"), nil
+	}
+	if !middleware.HasRole(c, auth.RoleAdmin) {
+		return "", errors.NewForbiddenError("you can only book for yourself")
+	}
+	return userID, nil
+}
+
+// ownBooking returns booking id when the authenticated user owns it, is an
+// admin or has one of roles, and fails with a forbidden error otherwise.
+func (h *BookingHandler) ownBooking(c *gin.Context, id string, roles ...string) (*domain.Booking, error) {
+	booking, err := h.service.GetBooking(c.Request.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canAccess(c, booking.UserID, roles...) {
+		return nil, errors.NewForbiddenError("you can only access your own bookings")
+	}
+	return booking, nil
+}
+
+// ownSeries returns series id when the authenticated user owns its
+// bookings or is an admin, and fails with a forbidden error otherwise.
+func (h *BookingHandler) ownSeries(c *gin.Context, id string) (*domain.BookingSeries, error) {
+	series, err := h.service.GetSeries(c.Request.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, booking := range series.Bookings {
+		if !canAccess(c, booking.UserID) {
+			return nil, errors.NewForbiddenError("you can only access your own bookings")
+		}
+	}
+	return series, nil
+}
+
+// canAccess reports whether the authenticated user is userID, an admin or
+// has one of roles.
+func canAccess(c *gin.Context, userID string, roles ...string) bool {
+	return userID == c.GetString("user_id") || middleware.HasRole(c, append(roles, auth.RoleAdmin)...)
+}
+
+// GetAvailability lists free slots of a resource between the RFC 3339
+// from and to query parameters. The window defaults to the next 24 hours.
+func (h *BookingHandler) GetAvailability(c *gin.Context) {
+	from := time.Now().UTC()
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.ValidationError(c, validation.Invalid("from", "rfc3339", ""))
+			return
+		}
+		from = parsed
+	}
+
+	to := from.Add(24 * time.Hour)
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.ValidationError(c, validation.Invalid("to", "rfc3339", ""))
+			return
+		}
+		to = parsed
+	}
+
+	availability, err := h.service.GetAvailability(c.Request.Context(), c.Param("id"), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, availability)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const testBookingID = "0b9d3c4e-8f21-4a6b-9c7d-1e2f3a4b5c6d"
+
+// fakeBookingService serves one booking of user u-1 and its comments; its
+// other methods are not used.
+type fakeBookingService struct {
+	domain.BookingService
+}
+
+func (fakeBookingService) GetBooking(_ context.Context, id string) (*domain.Booking, error) {
+	return &domain.Booking{ID: id, UserID: "u-1"}, nil
+}
+
+func (fakeBookingService) AddComment(_ context.Context, bookingID, authorID string, req *domain.AddCommentRequest) (*domain.BookingComment, error) {
+	return &domain.BookingComment{BookingID: bookingID, AuthorID: authorID, Text: req.Text}, nil
+}
+
+func (fakeBookingService) ListComments(_ context.Context, bookingID string) ([]*domain.BookingComment, error) {
+	return []*domain.BookingComment{}, nil
+}
+
+func TestCommentAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		userID  string
+		role    string
+		allowed bool
+	}{
+		{name: "owner", userID: "u-1", role: auth.RoleUser, allowed: true},
+		{name: "staff", userID: "s-1", role: auth.RoleStaff, allowed: true},
+		{name: "admin", userID: "a-1", role: auth.RoleAdmin, allowed: true},
+		{name: "other user", userID: "u-2", role: auth.RoleUser, allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New("test", "error")
+			h := NewBookingHandler(fakeBookingService{}, log, noop.NewTracerProvider().Tracer("test"))
+
+			router := gin.New()
+			router.Use(middleware.Errors(log, false), func(c *gin.Context) {
+				c.Set("user_id", tt.userID)
+				c.Set("user_role", tt.role)
+			})
+			router.POST("/bookings/:id/comments", h.AddComment)
+			router.GET("/bookings/:id/comments", h.ListComments)
+
+			requests := []struct {
+				req        *http.Request
+				wantStatus int
+			}{
+				{httptest.NewRequest(http.MethodPost, "/bookings/"+testBookingID+"/comments", strings.NewReader(`{"text":"guest asked for a late check-out"}`)), http.StatusCreated},
+				{httptest.NewRequest(http.MethodGet, "/bookings/"+testBookingID+"/comments", nil), http.StatusOK},
+			}
+			for _, r := range requests {
+				r.req.Header.Set("Content-Type", "application/json")
+				want := r.wantStatus
+				if !tt.allowed {
+					want = http.StatusForbidden
+				}
+
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, r.req)
+				if recorder.Code != want {
+					t.Errorf("%s status = %d, want %d: %s", r.req.Method, recorder.Code, want, recorder.Body)
+				}
+			}
+		})
+	}
+}
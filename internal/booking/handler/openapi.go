@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	exportdomain "github.com/dmehra2102/booking-system/internal/export/domain"
+	exporthandler "github.com/dmehra2102/booking-system/internal/export/handler"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the booking service HTTP API for the OpenAPI document.
+// Keep it in sync with setupRouter in cmd/booking.
+func Routes() []openapi.Route {
+	window := []openapi.Param{
+		{Name: "from", Description: "Start of the window (RFC 3339)", Format: "date-time"},
+		{Name: "to", Description: "End of the window (RFC 3339)", Format: "date-time"},
+	}
+
+	filters := append([]openapi.Param{
+		{Name: "resource_id", Format: "uuid"},
+		{Name: "series_id", Description: "Occurrences of a recurring booking", Format: "uuid"},
+		{Name: "status", Enum: bookingStatuses()},
+	}, window...)
+
+	routes := []openapi.Route{
+		{Method: http.MethodGet, Path: "/api/v1/resources/:id/availability", Summary: "List free slots of a resource", Tag: "availability",
+			Response: domain.Availability{}, Query: window},
+
+		{Method: http.MethodPost, Path: "/api/v1/bookings", Summary: "Create a booking", Tag: "bookings", Auth: true,
+			Request: domain.CreateBookingRequest{}, Response: domain.Booking{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/api/v1/bookings/series", Summary: "Create a recurring booking series", Tag: "bookings", Auth: true,
+			Request: domain.CreateBookingRequest{}, Response: domain.BookingSeries{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/bookings/series/:id", Summary: "Get a recurring booking series", Tag: "bookings", Auth: true,
+			Response: domain.BookingSeries{}},
+		{Method: http.MethodPost, Path: "/api/v1/bookings/series/:id/cancel", Summary: "Cancel one, the following or all upcoming bookings of a series", Tag: "bookings", Auth: true,
+			Request: domain.CancelSeriesRequest{}, Response: domain.BookingSeries{}},
+		{Method: http.MethodGet, Path: "/api/v1/bookings", Summary: "List bookings", Tag: "bookings", Auth: true,
+			Response: domain.Booking{}, List: true,
+			Query: append([]openapi.Param{{Name: "user_id", Description: "Admins only; defaults to the caller", Format: "uuid"}}, filters...)},
+		{Method: http.MethodGet, Path: "/api/v1/bookings/:id", Summary: "Get a booking", Tag: "bookings", Auth: true,
+			Response: domain.Booking{}},
+		{Method: http.MethodGet, Path: "/api/v1/bookings/:id/confirmation.pdf", Summary: "Download the PDF receipt of a confirmed booking", Tag: "bookings", Auth: true},
+		{Method: http.MethodGet, Path: "/api/v1/bookings/:id/confirmation.ics", Summary: "Download a confirmed booking as an iCalendar event", Tag: "bookings", Auth: true},
+		{Method: http.MethodPut, Path: "/api/v1/bookings/:id", Summary: "Update a booking", Tag: "bookings", Auth: true,
+			Request: domain.UpdateBookingRequest{}, Response: domain.Booking{}},
+		{Method: http.MethodPost, Path: "/api/v1/bookings/:id/cancel", Summary: "Cancel a booking", Tag: "bookings", Auth: true,
+			Request: domain.CancelBookingRequest{}, Response: domain.Booking{}},
+		{Method: http.MethodPost, Path: "/api/v1/bookings/:id/check-in", Summary: "Check in to a confirmed booking", Tag: "bookings", Auth: true,
+			Response: domain.Booking{}},
+		{Method: http.MethodPost, Path: "/api/v1/bookings/:id/check-out", Summary: "Check out of a booking", Tag: "bookings", Auth: true,
+			Response: domain.Booking{}},
+		{Method: http.MethodDelete, Path: "/api/v1/bookings/:id", Summary: "Delete a booking", Tag: "bookings", Auth: true, Admin: true,
+			Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/api/v1/bookings/:id/restore", Summary: "Restore a deleted booking", Tag: "bookings", Auth: true, Admin: true,
+			Response: domain.Booking{}},
+		{Method: http.MethodPost, Path: "/api/v1/bookings/:id/comments", Summary: "Comment on a booking", Tag: "bookings", Auth: true,
+			Request: domain.AddCommentRequest{}, Response: domain.BookingComment{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/bookings/:id/comments", Summary: "List comments on a booking", Tag: "bookings", Auth: true,
+			Response: []domain.BookingComment{}},
+		{Method: http.MethodGet, Path: "/api/v1/bookings/:id/stream", Summary: "Follow the status of a booking as server-sent events", Tag: "bookings", Auth: true},
+		{Method: http.MethodGet, Path: "/api/v1/users/:id/bookings", Summary: "List a user's bookings", Tag: "bookings", Auth: true,
+			Response: domain.Booking{}, List: true, Query: filters},
+
+		{Method: http.MethodPost, Path: "/api/v1/waitlist", Summary: "Join the waitlist for a fully booked slot", Tag: "waitlist", Auth: true,
+			Request: domain.JoinWaitlistRequest{}, Response: domain.WaitlistEntry{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/waitlist", Summary: "List your waitlist entries", Tag: "waitlist", Auth: true,
+			Response: []domain.WaitlistEntry{}},
+		{Method: http.MethodDelete, Path: "/api/v1/waitlist/:id", Summary: "Leave the waitlist", Tag: "waitlist", Auth: true,
+			Status: http.StatusNoContent},
+	}
+
+	return append(routes, exporthandler.Routes(exportdomain.DatasetBookings,
+		append([]openapi.Param{{Name: "user_id", Format: "uuid"}}, filters...))...)
+}
+
+func bookingStatuses() []string {
+	statuses := make([]string, 0, len(domain.BookingStatuses))
+	for _, status := range domain.BookingStatuses {
+		statuses = append(statuses, string(status))
+	}
+	return statuses
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// ResponseCache drops cached responses, as middleware.ResponseCache does.
+type ResponseCache interface {
+	Invalidate(ctx context.Context, scope string) error
+}
+
+// ResponseCacheEventHandler invalidates the cached availability of a
+// resource when one of its bookings takes or frees time, or when the
+// resource itself changes.
+type ResponseCacheEventHandler struct {
+	availability ResponseCache
+}
+
+func NewResponseCacheEventHandler(availability ResponseCache) *ResponseCacheEventHandler {
+	return &ResponseCacheEventHandler{availability: availability}
+}
+
+func (h *ResponseCacheEventHandler) HandleBookingRequested(ctx context.Context, event events.BookingRequestedEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
+
+func (h *ResponseCacheEventHandler) HandleBookingConfirmed(ctx context.Context, event events.BookingConfirmedEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
+
+func (h *ResponseCacheEventHandler) HandleBookingUpdated(ctx context.Context, event events.BookingUpdatedEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
+
+func (h *ResponseCacheEventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
+
+func (h *ResponseCacheEventHandler) HandleBookingCheckedOut(ctx context.Context, event events.BookingCheckedOutEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
+
+func (h *ResponseCacheEventHandler) HandleBookingNoShow(ctx context.Context, event events.BookingNoShowEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
+
+func (h *ResponseCacheEventHandler) HandleResourceUpdated(ctx context.Context, event events.ResourceUpdatedEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
+
+func (h *ResponseCacheEventHandler) HandleResourceDeleted(ctx context.Context, event events.ResourceDeletedEvent) error {
+	return h.availability.Invalidate(ctx, event.Data.ResourceID)
+}
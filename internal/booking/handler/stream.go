@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/booking/service"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// streamKeepAlive is the interval of comments keeping idle streams
+	// open through proxies.
+	streamKeepAlive = 15 * time.Second
+	// maxStreamDuration bounds a stream; EventSource clients reconnect on
+	// their own.
+	maxStreamDuration = 30 * time.Minute
+)
+
+type StreamHandler struct {
+	status *service.StatusService
+	logger *logger.Logger
+}
+
+func NewStreamHandler(status *service.StatusService, logger *logger.Logger) *StreamHandler {
+	return &StreamHandler{
+		status: status,
+		logger: logger,
+	}
+}
+
+// StreamStatus streams the status of a booking as server-sent events: a
+// "booking" event with the booking as it is now, then a "status" event
+// for every stage it reaches. The stream ends once the booking can no
+// longer change status.
+func (h *StreamHandler) StreamStatus(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), maxStreamDuration)
+	defer cancel()
+
+	booking, updates, stop, err := h.status.Follow(ctx, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer stop()
+
+	if c.GetString("user_id") != booking.UserID && !middleware.HasRole(c, auth.RoleAdmin) {
+		c.Error(errors.NewForbiddenError("you can only follow your own bookings"))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("booking", booking)
+	c.Writer.Flush()
+	if booking.Status.Final() {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			c.SSEvent("status", update)
+			c.Writer.Flush()
+			if update.Final() {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// StatusEventHandler turns the events of the booking saga into status
+// updates of the bookings they concern.
+type StatusEventHandler struct {
+	status *service.StatusService
+}
+
+func NewStatusEventHandler(status *service.StatusService) *StatusEventHandler {
+	return &StatusEventHandler{status: status}
+}
+
+func (h *StatusEventHandler) publish(ctx context.Context, base events.BaseEvent, bookingID string, stage domain.BookingStage, status domain.BookingStatus, reason string) error {
+	return h.status.Publish(ctx, &domain.StatusUpdate{
+		BookingID: bookingID,
+		Stage:     stage,
+		Status:    status,
+		Reason:    reason,
+		At:        base.Timestamp,
+	})
+}
+
+func (h *StatusEventHandler) HandleBookingRequested(ctx context.Context, event events.BookingRequestedEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageRequested, domain.BookingStatusPending, "")
+}
+
+func (h *StatusEventHandler) HandleInventoryReserved(ctx context.Context, event events.InventoryReservedEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageReserved, "", "")
+}
+
+func (h *StatusEventHandler) HandleInventoryReservationFailed(ctx context.Context, event events.InventoryReservationFailedEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageReservationFailed, "", event.Data.Reason)
+}
+
+// HandleInventoryReleased reports bookings whose hold expired unpaid, which
+// the booking service fails. Other releases follow from a booking
+// update reported by its own event.
+func (h *StatusEventHandler) HandleInventoryReleased(ctx context.Context, event events.InventoryReleasedEvent) error {
+	if event.Data.Reason != events.ReleaseReasonHoldExpired {
+		return nil
+	}
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageExpired, domain.BookingStatusFailed, event.Data.Reason)
+}
+
+func (h *StatusEventHandler) HandlePaymentProcessed(ctx context.Context, event events.PaymentProcessedEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStagePaid, "", "")
+}
+
+func (h *StatusEventHandler) HandlePaymentFailed(ctx context.Context, event events.PaymentFailedEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStagePaymentFailed, "", event.Data.Reason)
+}
+
+func (h *StatusEventHandler) HandleBookingConfirmed(ctx context.Context, event events.BookingConfirmedEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageConfirmed, domain.BookingStatusConfirmed, "")
+}
+
+func (h *StatusEventHandler) HandleBookingUpdated(ctx context.Context, event events.BookingUpdatedEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageUpdated, domain.BookingStatus(event.Data.Status), "")
+}
+
+func (h *StatusEventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageCancelled, domain.BookingStatusCancelled, event.Data.Reason)
+}
+
+func (h *StatusEventHandler) HandleBookingCheckedIn(ctx context.Context, event events.BookingCheckedInEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageCheckedIn, domain.BookingStatusInProgress, "")
+}
+
+func (h *StatusEventHandler) HandleBookingCheckedOut(ctx context.Context, event events.BookingCheckedOutEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageCheckedOut, domain.BookingStatusCompleted, "")
+}
+
+func (h *StatusEventHandler) HandleBookingNoShow(ctx context.Context, event events.BookingNoShowEvent) error {
+	return h.publish(ctx, event.BaseEvent, event.Data.BookingID, domain.BookingStageNoShow, domain.BookingStatusNoShow, "")
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// WaitlistHandler serves the caller's own waitlist entries.
+type WaitlistHandler struct {
+	service domain.WaitlistService
+	logger  *logger.Logger
+}
+
+func NewWaitlistHandler(service domain.WaitlistService, logger *logger.Logger) *WaitlistHandler {
+	return &WaitlistHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// JoinWaitlist queues the caller for a fully booked slot.
+func (h *WaitlistHandler) JoinWaitlist(c *gin.Context) {
+	var req domain.JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	entry, err := h.service.JoinWaitlist(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, entry)
+}
+
+func (h *WaitlistHandler) ListWaitlist(c *gin.Context) {
+	entries, err := h.service.ListWaitlist(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, entries)
+}
+
+func (h *WaitlistHandler) LeaveWaitlist(c *gin.Context) {
+	if err := h.service.LeaveWaitlist(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -9,6 +9,10 @@ import (
 	"github.com/dmehra2102/booking-system/internal/booking/domain"
 	"github.com/dmehra2102/booking-system/internal/common/database"
 	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -25,27 +29,33 @@ func NewPostgresBookingRepository(db *database.PostgresDB, tracer trace.Tracer)
 	}
 }
 
-func (r *PostgresBookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
-	ctx, span := r.tracer.Start(ctx, "booking.repository.create")
-	defer span.End()
+const insertBookingQuery = `
+	INSERT INTO bookings (
+		id, tenant_id, user_id, resource_id, start_time, end_time, status,
+		amount, currency, notes, metadata, series_id, created_at, updated_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+`
 
+// insertBookingArgs assigns the booking its ID and timestamps and returns
+// the arguments of insertBookingQuery for the tenant of ctx.
+func insertBookingArgs(ctx context.Context, booking *domain.Booking) []any {
 	booking.ID = uuid.New().String()
 	booking.CreatedAt = time.Now().UTC()
 	booking.UpdatedAt = time.Now().UTC()
+	booking.Version = 1
 
-	query := `
-		INSERT INTO bookings (
-			id, user_id, resource_id, start_time, end_time, status,
-			amount, currency, notes, metadata, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`
+	return []any{
+		booking.ID, tenancy.ID(ctx), booking.UserID, booking.ResourceID, booking.StartTime,
+		booking.EndTime, booking.Status, booking.Amount.Decimal(), booking.Amount.Currency,
+		booking.Notes, booking.Metadata, booking.SeriesID, booking.CreatedAt, booking.UpdatedAt,
+	}
+}
 
-	_, err := r.db.Exec(ctx, query,
-		booking.ID, booking.UserID, booking.ResourceID, booking.StartTime,
-		booking.EndTime, booking.Status, booking.Amount, booking.Currency,
-		booking.Notes, booking.Metadata, booking.CreatedAt, booking.UpdatedAt,
-	)
+func (r *PostgresBookingRepository) Create(ctx context.Context, booking *domain.Booking) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.create")
+	defer tracing.End(span, &err)
 
+	_, err = r.db.Exec(ctx, "booking.create", insertBookingQuery, insertBookingArgs(ctx, booking)...)
 	if err != nil {
 		return errors.NewInternalError("failed to create booking", err)
 	}
@@ -53,39 +63,101 @@ func (r *PostgresBookingRepository) Create(ctx context.Context, booking *domain.
 	return nil
 }
 
-func (r *PostgresBookingRepository) GetByID(ctx context.Context, id string) (*domain.Booking, error) {
-	ctx,span := r.tracer.Start(ctx,"booking.repository.get_by_id")
-	defer span.End()
+// CreateSeries inserts the occurrences of a recurring booking under a new
+// series ID. Either all of them are created or none is.
+func (r *PostgresBookingRepository) CreateSeries(ctx context.Context, bookings []*domain.Booking) (_ string, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.create_series")
+	defer tracing.End(span, &err)
+
+	seriesID := uuid.New().String()
+	err = r.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, booking := range bookings {
+			booking.SeriesID = &seriesID
+			if _, err := r.db.Exec(ctx, "booking.create", insertBookingQuery, insertBookingArgs(ctx, booking)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errors.NewInternalError("failed to create booking series", err)
+	}
 
-	query := `
-		SELECT b.id, b.user_id, b.resource_id, b.start_time, b.end_time, b.status,
-				b.amount, b.currency, b.payment_id, b.reservation_id, b.notes,
-				b.metadata, b.created_at, b.updated_at,
-				u.name as user_name, u.email as user_email,
-				r.name as resource_name
-		FROM bookings b
-		LEFT JOIN users u ON b.user_id = u.id
-		LEFT JOIN resources r ON b.resource_id = r.id
-		WHERE b.id = $1
-	`
+	return seriesID, nil
+}
+
+// WithTx runs fn in a transaction. Calls made with the context passed to
+// fn, on this or any other Postgres repository, take part in it.
+func (r *PostgresBookingRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.db.WithTx(ctx, fn)
+}
+
+// ListSeries returns the occurrences of a recurring booking in start time
+// order.
+func (r *PostgresBookingRepository) ListSeries(ctx context.Context, seriesID string) (_ []*domain.Booking, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.list_series")
+	defer tracing.End(span, &err)
+
+	query := selectBookingQuery + ` WHERE b.series_id = $1 AND b.tenant_id = $2 AND b.deleted_at IS NULL ORDER BY b.start_time ASC, b.id ASC`
 
+	rows, err := r.db.Query(ctx, "booking.list_series", query, seriesID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list booking series", err)
+	}
+	bookings, err := database.ScanAll(rows, scanBooking)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan bookings", err)
+	}
+	if len(bookings) == 0 {
+		return nil, errors.NewNotFoundError("booking series")
+	}
+
+	return bookings, nil
+}
+
+const selectBookingQuery = `
+	SELECT b.id, b.user_id, b.resource_id, b.start_time, b.end_time, b.status,
+			b.amount, b.currency, b.payment_id, b.reservation_id, b.notes,
+			b.metadata, b.series_id, b.cancellation_reason, b.cancellation_fee, b.cancelled_at,
+			b.checked_in_at, b.checked_out_at, b.deleted_at, b.created_at, b.updated_at, b.version,
+			u.name as user_name, u.email as user_email,
+			r.name as resource_name
+	FROM bookings b
+	LEFT JOIN users u ON b.user_id = u.id AND u.tenant_id = b.tenant_id
+	LEFT JOIN resources r ON b.resource_id = r.id AND r.tenant_id = b.tenant_id
+`
+
+// bookingColumns are the columns Update may set.
+var bookingColumns = database.NewColumns(
+	"start_time", "end_time", "notes", "amount", "status",
+	"cancellation_reason", "cancellation_fee", "cancelled_at", "checked_in_at", "checked_out_at", "reminded_at", "updated_at",
+)
+
+func scanBooking(row database.Scanner) (*domain.Booking, error) {
 	booking := &domain.Booking{}
-	var paymentID, reservationID sql.NullString
+	var paymentID, reservationID, seriesID sql.NullString
 	var userName, userEmail, resourceName sql.NullString
+	var cancelledAt, checkedInAt, checkedOutAt, deletedAt sql.NullTime
+	var amount, currency, cancellationFee string
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := row.Scan(
 		&booking.ID, &booking.UserID, &booking.ResourceID, &booking.StartTime,
-		&booking.EndTime, &booking.Status, &booking.Amount, &booking.Currency,
-		&paymentID, &reservationID, &booking.Notes, &booking.Metadata,
-		&booking.CreatedAt, &booking.UpdatedAt,
+		&booking.EndTime, &booking.Status, &amount, &currency,
+		&paymentID, &reservationID, &booking.Notes, &booking.Metadata, &seriesID,
+		&booking.CancellationReason, &cancellationFee, &cancelledAt,
+		&checkedInAt, &checkedOutAt, &deletedAt, &booking.CreatedAt, &booking.UpdatedAt, &booking.Version,
 		&userName, &userEmail, &resourceName,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.NewNotFoundError("booking")
-		}
-		return nil, errors.NewInternalError("failed to get boooking", err)
+		return nil, err
+	}
+
+	// Amounts are NUMERIC and parse exactly into minor units
+	if booking.Amount, err = money.Parse(amount, money.Currency(currency)); err != nil {
+		return nil, err
+	}
+	if booking.CancellationFee, err = money.Parse(cancellationFee, money.Currency(currency)); err != nil {
+		return nil, err
 	}
 
 	// Handle nullable fields
@@ -95,6 +167,21 @@ func (r *PostgresBookingRepository) GetByID(ctx context.Context, id string) (*do
 	if reservationID.Valid {
 		booking.ReservationID = &reservationID.String
 	}
+	if seriesID.Valid {
+		booking.SeriesID = &seriesID.String
+	}
+	if cancelledAt.Valid {
+		booking.CancelledAt = &cancelledAt.Time
+	}
+	if checkedInAt.Valid {
+		booking.CheckedInAt = &checkedInAt.Time
+	}
+	if checkedOutAt.Valid {
+		booking.CheckedOutAt = &checkedOutAt.Time
+	}
+	if deletedAt.Valid {
+		booking.DeletedAt = &deletedAt.Time
+	}
 	if userName.Valid {
 		booking.UserName = userName.String
 	}
@@ -108,30 +195,137 @@ func (r *PostgresBookingRepository) GetByID(ctx context.Context, id string) (*do
 	return booking, nil
 }
 
-func (r *PostgresBookingRepository) Update(ctx context.Context, id string, updates map[string]any) error {
-	ctx,span := r.tracer.Start(ctx,"booking.repository.update")
-	defer span.End()
+func (r *PostgresBookingRepository) GetByID(ctx context.Context, id string) (_ *domain.Booking, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.get_by_id", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
 
-	if len(updates) == 0 {
-		return nil
+	query := selectBookingQuery + ` WHERE b.id = $1 AND b.tenant_id = $2 AND b.deleted_at IS NULL`
+
+	booking, err := scanBooking(r.db.QueryRow(ctx, "booking.get_by_id", query, database.Prepared(id, tenancy.ID(ctx))...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("booking")
+		}
+		return nil, errors.NewInternalError("failed to get boooking", err)
 	}
 
-	updates["updated_at"] = time.Now().UTC()
+	return booking, nil
+}
+
+// bookingSortColumns maps the sortable fields to their columns and SQL
+// types for keyset comparisons.
+var bookingSortColumns = map[string][2]string{
+	"created_at": {"b.created_at", "timestamptz"},
+	"start_time": {"b.start_time", "timestamptz"},
+}
 
-	setParts := make([]string, 0, len(updates))
-	args := make([]any, 0, len(updates)+1)
-	argIndex := 1
+func (r *PostgresBookingRepository) List(ctx context.Context, filter domain.ListBookingsFilter, params pagination.Params) (_ []*domain.Booking, _ *pagination.Result, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.list")
+	defer tracing.End(span, &err)
 
-	for field, value := range updates {
-		setParts = append(setParts, fmt.Sprintf("%s = %d", field, argIndex))
+	sortField := params.Sort.Field
+	if _, ok := bookingSortColumns[sortField]; !ok {
+		sortField = "created_at"
+	}
+	column := bookingSortColumns[sortField]
+
+	conditions := []string{"b.deleted_at IS NULL"}
+	args := make([]any, 0)
+	add := func(condition string, value any) {
 		args = append(args, value)
-		argIndex++
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	add("b.tenant_id = $%d", tenancy.ID(ctx))
+
+	// user_id and resource_id lead the (user_id, start_time) and
+	// (resource_id, start_time, end_time) indexes, and the window filter is
+	// written against start_time so those indexes can serve it.
+	if filter.UserID != "" {
+		add("b.user_id = $%d", filter.UserID)
+	}
+	if filter.ResourceID != "" {
+		add("b.resource_id = $%d", filter.ResourceID)
+	}
+	if filter.SeriesID != "" {
+		add("b.series_id = $%d", filter.SeriesID)
+	}
+	if filter.Status != "" {
+		add("b.status = $%d", filter.Status)
+	}
+	if filter.To != nil {
+		add("b.start_time < $%d", *filter.To)
+	}
+	if filter.From != nil {
+		add("b.end_time > $%d", *filter.From)
+	}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "booking.count", "SELECT COUNT(*) FROM bookings b "+database.Where(conditions), args...).Scan(&total); err != nil {
+		return nil, nil, errors.NewInternalError("failed to count bookings", err)
+	}
+
+	if after, afterArgs := params.After(column[0], column[1], "b.id", len(args)+1); after != "" {
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
 	}
 
-	query := fmt.Sprintf("UPDATE bookings SET %s WHERE id = %d", joinStrings(setParts,", "), argIndex)
-	args = append(args, id)
+	// Fetch one extra row to learn whether another page follows.
+	query := selectBookingQuery + fmt.Sprintf(`
+		%s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, database.Where(conditions), params.OrderBy(column[0], "b.id"), len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, "booking.list", query, append(args, params.PageSize+1, params.Offset())...)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to list bookings", err)
+	}
+	bookings, err := database.ScanAll(rows, scanBooking)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to scan bookings", err)
+	}
+
+	result := &pagination.Result{Total: total}
+	if len(bookings) > params.PageSize {
+		bookings = bookings[:params.PageSize]
+		last := bookings[len(bookings)-1]
+		result.NextCursor = params.Next(bookingSortValue(last, sortField), last.ID)
+	}
+
+	return bookings, result, nil
+}
+
+func bookingSortValue(booking *domain.Booking, field string) string {
+	if field == "start_time" {
+		return booking.StartTime.Format(time.RFC3339Nano)
+	}
+	return booking.CreatedAt.Format(time.RFC3339Nano)
+}
+
+// Update sets the given columns of the booking if it is still at version,
+// and increments the version. A booking that changed since it was read at
+// version is reported as a conflict.
+func (r *PostgresBookingRepository) Update(ctx context.Context, id string, version int64, updates map[string]any) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.update", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updates["updated_at"] = time.Now().UTC()
+
+	var args database.Args
+	set, err := bookingColumns.Set(updates, &args)
+	if err != nil {
+		return errors.NewInternalError("invalid booking update", err)
+	}
+	query := "UPDATE bookings SET " + set + ", version = version + 1" +
+		" WHERE id = " + args.Add(id) + " AND tenant_id = " + args.Add(tenancy.ID(ctx)) +
+		" AND version = " + args.Add(version) + " AND deleted_at IS NULL"
 
-	result,err := r.db.Exec(ctx, query, args...)
+	result, err := r.db.Exec(ctx, "booking.update", query, args...)
 	if err != nil {
 		return errors.NewInternalError("failed to update booking", err)
 	}
@@ -142,24 +336,35 @@ func (r *PostgresBookingRepository) Update(ctx context.Context, id string, updat
 	}
 
 	if rowsAffected == 0 {
-		return errors.NewNotFoundError("booking")
+		var exists bool
+		err := r.db.QueryRow(ctx, "booking.exists", `SELECT EXISTS (SELECT 1 FROM bookings WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL)`, id, tenancy.ID(ctx)).Scan(&exists)
+		if err != nil {
+			return errors.NewInternalError("failed to check update result", err)
+		}
+		if !exists {
+			return errors.NewNotFoundError("booking")
+		}
+		return errors.NewConflictError("booking was changed by another request, reload it and try again")
 	}
 
 	return nil
 }
 
-func (r *PostgresBookingRepository) Delete(ctx context.Context, id string) error {
-	ctx,span := r.tracer.Start(ctx, "booking.repository.delete")
-	defer span.End()
+// Delete soft-deletes the booking. It disappears from reads until it is
+// restored or removed for good by PurgeDeleted.
+func (r *PostgresBookingRepository) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.delete", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
 
-	query :=  `DELETE FROM bookings WHERE id = $1`
+	now := time.Now().UTC()
+	query := `UPDATE bookings SET deleted_at = $1, updated_at = $1, version = version + 1 WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL`
 
-	result,err := r.db.Exec(ctx,query,id)
+	result, err := r.db.Exec(ctx, "booking.delete", query, now, id, tenancy.ID(ctx))
 	if err != nil {
 		return errors.NewInternalError("failed to delete booking", err)
 	}
 
-	rowsAffected,err := result.RowsAffected()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return errors.NewInternalError("failed to check delete result", err)
 	}
@@ -171,16 +376,230 @@ func (r *PostgresBookingRepository) Delete(ctx context.Context, id string) error
 	return nil
 }
 
-func joinStrings(strs []string, sep string) string {
-	if len(strs) == 0 {
-		return ""
+// Restore undoes Delete.
+func (r *PostgresBookingRepository) Restore(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.restore", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	query := `UPDATE bookings SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.Exec(ctx, "booking.restore", query, time.Now().UTC(), id, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to restore booking", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check restore result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("deleted booking")
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes the bookings soft-deleted before the
+// given time, along with their comments, and returns how many were removed.
+func (r *PostgresBookingRepository) PurgeDeleted(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.purge_deleted")
+	defer tracing.End(span, &err)
+
+	result, err := r.db.Exec(ctx, "booking.purge_deleted", `DELETE FROM bookings WHERE deleted_at < $1 AND tenant_id = $2`, before, tenancy.ID(ctx))
+	if err != nil {
+		return 0, errors.NewInternalError("failed to purge deleted bookings", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.NewInternalError("failed to check purge result", err)
+	}
+
+	return purged, nil
+}
+
+// ListOverlapping returns the active bookings on a resource whose window
+// intersects [start, end). Callers widen the window by the resource buffer
+// so bookings inside the turnaround period are included.
+func (r *PostgresBookingRepository) ListOverlapping(ctx context.Context, resourceID string, start, end time.Time) (_ []*domain.Booking, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.list_overlapping", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, resource_id, start_time, end_time, status
+		FROM bookings
+		WHERE resource_id = $1
+		  AND tenant_id = $7
+		  AND status IN ($2, $3, $4)
+		  AND start_time < $6
+		  AND end_time > $5
+		  AND deleted_at IS NULL
+	`
+
+	rows, err := r.db.Query(ctx, "booking.list_overlapping", query, database.Prepared(resourceID,
+		domain.BookingStatusPending, domain.BookingStatusConfirmed, domain.BookingStatusInProgress, start, end, tenancy.ID(ctx),
+	)...)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list overlapping bookings", err)
+	}
+	bookings, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Booking, error) {
+		booking := &domain.Booking{}
+		err := row.Scan(
+			&booking.ID, &booking.UserID, &booking.ResourceID,
+			&booking.StartTime, &booking.EndTime, &booking.Status,
+		)
+		return booking, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan overlapping bookings", err)
+	}
+
+	return bookings, nil
+}
+
+// MarkNoShows marks the confirmed bookings that started before
+// startedBefore, or ended by now, as no-shows and returns them.
+func (r *PostgresBookingRepository) MarkNoShows(ctx context.Context, startedBefore, now time.Time) (_ []*domain.Booking, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.mark_no_shows")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE bookings
+		SET status = $1, updated_at = $4, version = version + 1
+		WHERE status = $2
+		  AND tenant_id = $5
+		  AND (start_time < $3 OR end_time <= $4)
+		  AND deleted_at IS NULL
+		RETURNING id, user_id, resource_id, start_time, end_time, status, amount, currency
+	`
+
+	rows, err := r.db.Query(ctx, "booking.mark_no_shows", query, domain.BookingStatusNoShow, domain.BookingStatusConfirmed, startedBefore, now, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to mark no-shows", err)
+	}
+	bookings, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Booking, error) {
+		booking := &domain.Booking{}
+		var amount, currency string
+		err := row.Scan(
+			&booking.ID, &booking.UserID, &booking.ResourceID,
+			&booking.StartTime, &booking.EndTime, &booking.Status, &amount, &currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+		booking.Amount, err = money.Parse(amount, money.Currency(currency))
+		return booking, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan no-show bookings", err)
+	}
+
+	return bookings, nil
+}
+
+// MarkReminded marks the confirmed bookings starting after now and by
+// startsBy that were not reminded of yet as reminded and returns them.
+func (r *PostgresBookingRepository) MarkReminded(ctx context.Context, now, startsBy time.Time) (_ []*domain.Booking, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.mark_reminded")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE bookings
+		SET reminded_at = $2
+		WHERE status = $1
+		  AND tenant_id = $4
+		  AND start_time > $2 AND start_time <= $3
+		  AND reminded_at IS NULL
+		  AND deleted_at IS NULL
+		RETURNING id, user_id, resource_id, start_time, end_time
+	`
+
+	rows, err := r.db.Query(ctx, "booking.mark_reminded", query, domain.BookingStatusConfirmed, now, startsBy, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to mark reminded bookings", err)
 	}
-	if len(strs) == 1 {
-		return strs[0]
+	bookings, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Booking, error) {
+		booking := &domain.Booking{}
+		err := row.Scan(&booking.ID, &booking.UserID, &booking.ResourceID, &booking.StartTime, &booking.EndTime)
+		return booking, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan reminded bookings", err)
 	}
-	result := strs[0]
-	for i := 1; i < len(strs); i++ {
-		result += sep + strs[i]
+
+	return bookings, nil
+}
+
+// GetResourceRules returns the scheduling constraints of a resource.
+// Resources without an explicit capacity hold a single booking at a time and
+// have no turnaround buffer by default.
+func (r *PostgresBookingRepository) GetResourceRules(ctx context.Context, resourceID string) (_ *domain.ResourceRules, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.get_resource_rules", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT COALESCE(capacity, 1), COALESCE(buffer_minutes, 0)
+		FROM resources WHERE id = $1 AND tenant_id = $2 AND active = true
+	`
+
+	var capacity, bufferMinutes int
+	if err := r.db.QueryRow(ctx, "booking.get_resource_rules", query, resourceID, tenancy.ID(ctx)).Scan(&capacity, &bufferMinutes); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("resource")
+		}
+		return nil, errors.NewInternalError("failed to get resource rules", err)
 	}
-	return result
-}
\ No newline at end of file
+
+	return &domain.ResourceRules{
+		Capacity: capacity,
+		Buffer:   time.Duration(bufferMinutes) * time.Minute,
+	}, nil
+}
+
+func (r *PostgresBookingRepository) AddComment(ctx context.Context, comment *domain.BookingComment) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.add_comment")
+	defer tracing.End(span, &err)
+
+	comment.ID = uuid.New().String()
+	comment.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO booking_comments (id, tenant_id, booking_id, author_id, text, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = r.db.Exec(ctx, "booking.add_comment", query, comment.ID, tenancy.ID(ctx), comment.BookingID, comment.AuthorID, comment.Text, comment.CreatedAt)
+	if err != nil {
+		return errors.NewInternalError("failed to add booking comment", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresBookingRepository) ListComments(ctx context.Context, bookingID string) (_ []*domain.BookingComment, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.list_comments", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, booking_id, author_id, text, created_at
+		FROM booking_comments
+		WHERE booking_id = $1 AND tenant_id = $2
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(ctx, "booking.list_comments", query, bookingID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list booking comments", err)
+	}
+	comments, err := database.ScanAll(rows, func(row database.Scanner) (*domain.BookingComment, error) {
+		comment := &domain.BookingComment{}
+		err := row.Scan(&comment.ID, &comment.BookingID, &comment.AuthorID, &comment.Text, &comment.CreatedAt)
+		return comment, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan booking comments", err)
+	}
+
+	return comments, nil
+}
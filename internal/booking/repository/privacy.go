@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresPrivacyRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresPrivacyRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresPrivacyRepository {
+	return &PostgresPrivacyRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+// GetUserData returns the bookings, comments, waitlist entries and reviews
+// of userID, including soft-deleted bookings.
+func (r *PostgresPrivacyRepository) GetUserData(ctx context.Context, userID string) (_ *domain.UserData, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.get_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	tenantID := tenancy.ID(ctx)
+	data := &domain.UserData{}
+
+	rows, err := r.db.Query(ctx, "booking.user_data_bookings", selectBookingQuery+` WHERE b.user_id = $1 AND b.tenant_id = $2 ORDER BY b.created_at`, userID, tenantID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list bookings of user", err)
+	}
+	if data.Bookings, err = database.ScanAll(rows, scanBooking); err != nil {
+		return nil, errors.NewInternalError("failed to scan bookings of user", err)
+	}
+
+	rows, err = r.db.Query(ctx, "booking.user_data_comments", `
+		SELECT id, booking_id, author_id, text, created_at
+		FROM booking_comments
+		WHERE author_id = $1 AND tenant_id = $2
+		ORDER BY created_at
+	`, userID, tenantID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list booking comments of user", err)
+	}
+	data.Comments, err = database.ScanAll(rows, func(row database.Scanner) (*domain.BookingComment, error) {
+		comment := &domain.BookingComment{}
+		err := row.Scan(&comment.ID, &comment.BookingID, &comment.AuthorID, &comment.Text, &comment.CreatedAt)
+		return comment, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan booking comments of user", err)
+	}
+
+	rows, err = r.db.Query(ctx, "booking.user_data_waitlist", selectWaitlistQuery+` WHERE user_id = $1 AND tenant_id = $2 ORDER BY created_at`, userID, tenantID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list waitlist entries of user", err)
+	}
+	if data.Waitlist, err = database.ScanAll(rows, scanWaitlistEntry); err != nil {
+		return nil, errors.NewInternalError("failed to scan waitlist entries of user", err)
+	}
+
+	rows, err = r.db.Query(ctx, "booking.user_data_reviews", `
+		SELECT id, booking_id, resource_id, rating, comment, created_at
+		FROM reviews
+		WHERE user_id = $1 AND tenant_id = $2
+		ORDER BY created_at
+	`, userID, tenantID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list reviews of user", err)
+	}
+	data.Reviews, err = database.ScanAll(rows, func(row database.Scanner) (*domain.UserReview, error) {
+		review := &domain.UserReview{}
+		err := row.Scan(&review.ID, &review.BookingID, &review.ResourceID, &review.Rating, &review.Comment, &review.CreatedAt)
+		return review, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan reviews of user", err)
+	}
+
+	return data, nil
+}
+
+// eraseUserDataQueries remove the free text users wrote from the records
+// the business keeps, and delete the records only the user needs.
+var eraseUserDataQueries = []struct{ name, query string }{
+	{"booking.erase_bookings", `
+		UPDATE bookings SET notes = '', metadata = '', cancellation_reason = '', updated_at = NOW()
+		WHERE user_id = $1 AND tenant_id = $2 AND (notes <> '' OR metadata <> '' OR cancellation_reason <> '')
+	`},
+	{"booking.erase_comments", `UPDATE booking_comments SET text = '' WHERE author_id = $1 AND tenant_id = $2 AND text <> ''`},
+	{"booking.erase_reviews", `UPDATE reviews SET comment = '' WHERE user_id = $1 AND tenant_id = $2 AND comment <> ''`},
+	{"booking.erase_waitlist", `DELETE FROM waitlist_entries WHERE user_id = $1 AND tenant_id = $2`},
+	{"booking.erase_calendar_connection", `DELETE FROM calendar_connections WHERE user_id = $1 AND tenant_id = $2`},
+}
+
+// EraseUserData erases the personal data of userID and returns the number
+// of records it changed. Bookings and ratings are kept for the resources'
+// schedules and statistics.
+func (r *PostgresPrivacyRepository) EraseUserData(ctx context.Context, userID string) (erased int, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.erase_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	err = r.db.WithTx(ctx, func(ctx context.Context) error {
+		erased = 0
+		for _, q := range eraseUserDataQueries {
+			result, err := r.db.Exec(ctx, q.name, q.query, userID, tenancy.ID(ctx))
+			if err != nil {
+				return errors.NewInternalError("failed to erase user data", err)
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return errors.NewInternalError("failed to check erase result", err)
+			}
+			erased += int(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return erased, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+)
+
+// statusBuffer is the number of updates kept for a subscriber that reads
+// them slower than they arrive.
+const statusBuffer = 16
+
+// RedisStatusStream passes status updates of bookings over Redis pub/sub,
+// so a client following a booking on any replica sees the updates
+// published by the replica that consumed the event.
+type RedisStatusStream struct {
+	redis  *database.RedisClient
+	logger *logger.Logger
+}
+
+func NewRedisStatusStream(redis *database.RedisClient, logger *logger.Logger) *RedisStatusStream {
+	return &RedisStatusStream{
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+func statusChannel(ctx context.Context, bookingID string) string {
+	return "booking_status:" + tenancy.ID(ctx) + ":" + bookingID
+}
+
+func (s *RedisStatusStream) Publish(ctx context.Context, update *domain.StatusUpdate) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return errors.NewInternalError("failed to encode status update", err)
+	}
+
+	if err := s.redis.Publish(ctx, statusChannel(ctx, update.BookingID), payload); err != nil {
+		return errors.NewInternalError("failed to publish status update", err)
+	}
+	return nil
+}
+
+// Subscribe returns the updates of bookingID published from now on. The
+// channel is closed when the subscription ends; stop ends it.
+func (s *RedisStatusStream) Subscribe(ctx context.Context, bookingID string) (_ <-chan *domain.StatusUpdate, stop func(), _ error) {
+	pubsub, err := s.redis.Subscribe(ctx, statusChannel(ctx, bookingID))
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to subscribe to status updates", err)
+	}
+
+	updates := make(chan *domain.StatusUpdate, statusBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(updates)
+		for message := range pubsub.Channel() {
+			update := &domain.StatusUpdate{}
+			if err := json.Unmarshal([]byte(message.Payload), update); err != nil {
+				s.logger.WithContext(ctx).WithError(err).With("booking_id", bookingID).Warn("skipping undecodable status update")
+				continue
+			}
+
+			select {
+			case updates <- update:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return updates, func() {
+		once.Do(func() {
+			close(done)
+			pubsub.Close()
+		})
+	}, nil
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresWaitlistRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresWaitlistRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresWaitlistRepository {
+	return &PostgresWaitlistRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+const waitlistColumns = `id, user_id, resource_id, start_time, end_time, status, offer_expires_at, created_at, updated_at`
+
+const selectWaitlistQuery = `SELECT ` + waitlistColumns + ` FROM waitlist_entries`
+
+func scanWaitlistEntry(row database.Scanner) (*domain.WaitlistEntry, error) {
+	entry := &domain.WaitlistEntry{}
+	var offerExpiresAt sql.NullTime
+
+	err := row.Scan(
+		&entry.ID, &entry.UserID, &entry.ResourceID, &entry.StartTime, &entry.EndTime,
+		&entry.Status, &offerExpiresAt, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if offerExpiresAt.Valid {
+		entry.OfferExpiresAt = &offerExpiresAt.Time
+	}
+	return entry, nil
+}
+
+func (r *PostgresWaitlistRepository) scanEntries(rows *sql.Rows) ([]*domain.WaitlistEntry, error) {
+	defer rows.Close()
+
+	entries := make([]*domain.WaitlistEntry, 0)
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to scan waitlist entry", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to list waitlist entries", err)
+	}
+
+	return entries, nil
+}
+
+// Create adds an entry to the waitlist. A user can only wait once for the
+// same slot.
+func (r *PostgresWaitlistRepository) Create(ctx context.Context, entry *domain.WaitlistEntry) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.create_waitlist_entry", trace.WithAttributes(tracing.ResourceID.String(entry.ResourceID)))
+	defer tracing.End(span, &err)
+
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now().UTC()
+	entry.UpdatedAt = entry.CreatedAt
+
+	query := `
+		INSERT INTO waitlist_entries (
+			id, tenant_id, user_id, resource_id, start_time, end_time, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, resource_id, start_time, end_time) WHERE status IN ('waiting', 'offered') DO NOTHING
+	`
+
+	result, err := r.db.Exec(ctx, "booking.create_waitlist_entry", query,
+		entry.ID, tenancy.ID(ctx), entry.UserID, entry.ResourceID, entry.StartTime,
+		entry.EndTime, entry.Status, entry.CreatedAt, entry.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create waitlist entry", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewConflictError("you are already on the waitlist for this slot")
+	}
+
+	return nil
+}
+
+func (r *PostgresWaitlistRepository) GetByID(ctx context.Context, id string) (_ *domain.WaitlistEntry, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.get_waitlist_entry")
+	defer tracing.End(span, &err)
+
+	entry, err := scanWaitlistEntry(r.db.QueryRow(ctx, "booking.get_waitlist_entry", selectWaitlistQuery+` WHERE id = $1 AND tenant_id = $2`, id, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("waitlist entry")
+		}
+		return nil, errors.NewInternalError("failed to get waitlist entry", err)
+	}
+
+	return entry, nil
+}
+
+// ListByUser returns the active entries of a user, oldest first.
+func (r *PostgresWaitlistRepository) ListByUser(ctx context.Context, userID string) (_ []*domain.WaitlistEntry, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.list_user_waitlist", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := selectWaitlistQuery + `
+		WHERE user_id = $1 AND tenant_id = $4 AND status IN ($2, $3)
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(ctx, "booking.list_user_waitlist", query, userID, domain.WaitlistStatusWaiting, domain.WaitlistStatusOffered, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list waitlist entries", err)
+	}
+	return r.scanEntries(rows)
+}
+
+// ListWaiting returns the entries waiting for a slot of the resource that
+// overlaps [start, end), in the order they joined.
+func (r *PostgresWaitlistRepository) ListWaiting(ctx context.Context, resourceID string, start, end time.Time) (_ []*domain.WaitlistEntry, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.list_waiting", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	query := selectWaitlistQuery + `
+		WHERE resource_id = $1
+		  AND tenant_id = $5
+		  AND status = $2
+		  AND start_time < $4
+		  AND end_time > $3
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.Query(ctx, "booking.list_waiting", query, resourceID, domain.WaitlistStatusWaiting, start, end, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list waitlist entries", err)
+	}
+	return r.scanEntries(rows)
+}
+
+// ListOffered returns the entries holding a slot of the resource that
+// overlaps [start, end) at now.
+func (r *PostgresWaitlistRepository) ListOffered(ctx context.Context, resourceID string, start, end, now time.Time) (_ []*domain.WaitlistEntry, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.list_offered", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	query := selectWaitlistQuery + `
+		WHERE resource_id = $1
+		  AND tenant_id = $6
+		  AND status = $2
+		  AND offer_expires_at > $5
+		  AND start_time < $4
+		  AND end_time > $3
+	`
+
+	rows, err := r.db.Query(ctx, "booking.list_offered", query, resourceID, domain.WaitlistStatusOffered, start, end, now, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list waitlist offers", err)
+	}
+	return r.scanEntries(rows)
+}
+
+// UpdateStatus moves an active entry to status. offerExpiresAt is only
+// kept for offers.
+func (r *PostgresWaitlistRepository) UpdateStatus(ctx context.Context, id string, status domain.WaitlistStatus, offerExpiresAt *time.Time) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.update_waitlist_status")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE waitlist_entries
+		SET status = $2, offer_expires_at = $3, updated_at = $4
+		WHERE id = $1 AND tenant_id = $7 AND status IN ($5, $6)
+	`
+
+	result, err := r.db.Exec(ctx, "booking.update_waitlist_status", query, id, status, offerExpiresAt, time.Now().UTC(),
+		domain.WaitlistStatusWaiting, domain.WaitlistStatusOffered, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to update waitlist entry", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewConflictError("waitlist entry is no longer active")
+	}
+
+	return nil
+}
+
+// ClaimOffers marks the offers a user holds on a slot of the resource that
+// overlaps [start, end) as booked.
+func (r *PostgresWaitlistRepository) ClaimOffers(ctx context.Context, userID, resourceID string, start, end time.Time) (err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.claim_waitlist_offers", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE waitlist_entries
+		SET status = $3, offer_expires_at = NULL, updated_at = $7
+		WHERE user_id = $1
+		  AND tenant_id = $8
+		  AND resource_id = $2
+		  AND status = $4
+		  AND start_time < $6
+		  AND end_time > $5
+	`
+
+	_, err = r.db.Exec(ctx, "booking.claim_waitlist_offers", query, userID, resourceID, domain.WaitlistStatusBooked,
+		domain.WaitlistStatusOffered, start, end, time.Now().UTC(), tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to claim waitlist offers", err)
+	}
+
+	return nil
+}
+
+// ExpireOffers marks the offers that expired by now as expired and returns
+// them.
+func (r *PostgresWaitlistRepository) ExpireOffers(ctx context.Context, now time.Time) (_ []*domain.WaitlistEntry, err error) {
+	ctx, span := r.tracer.Start(ctx, "booking.repository.expire_waitlist_offers")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE waitlist_entries
+		SET status = $1, updated_at = $3
+		WHERE status = $2 AND tenant_id = $4 AND offer_expires_at <= $3
+		RETURNING ` + waitlistColumns
+
+	rows, err := r.db.Query(ctx, "booking.expire_waitlist_offers", query, domain.WaitlistStatusExpired, domain.WaitlistStatusOffered, now, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to expire waitlist offers", err)
+	}
+	return r.scanEntries(rows)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CheckIn starts a confirmed booking. It is accepted within the check-in
+// window of the booking only.
+func (s *BookingService) CheckIn(ctx context.Context, id string) (_ *domain.Booking, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.check_in", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !booking.CanBeCheckedIn() {
+		return nil, errors.NewConflictError("only confirmed bookings can be checked in")
+	}
+
+	checkedInAt := time.Now().UTC()
+	if opens := s.checkIn.Opens(booking); checkedInAt.Before(opens) {
+		return nil, errors.NewConflictError("check-in opens at " + opens.Format(time.RFC3339))
+	}
+	if closes := s.checkIn.Closes(booking); checkedInAt.After(closes) {
+		return nil, errors.NewConflictError("check-in closed at " + closes.Format(time.RFC3339))
+	}
+
+	err = s.repo.Update(ctx, id, booking.Version, map[string]any{
+		"status":        domain.BookingStatusInProgress,
+		"checked_in_at": checkedInAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	before := *booking
+	booking.Status = domain.BookingStatusInProgress
+	booking.CheckedInAt = &checkedInAt
+	booking.Version++
+	audit.Log(ctx, "booking.check_in", "booking", id, &before, booking)
+
+	event := events.BookingCheckedInEvent{
+		BaseEvent: events.NewBaseEvent(events.BookingCheckedIn, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.BookingCheckedInData{
+			BookingID:   booking.ID,
+			UserID:      booking.UserID,
+			ResourceID:  booking.ResourceID,
+			StartTime:   booking.StartTime,
+			EndTime:     booking.EndTime,
+			CheckedInAt: checkedInAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.BookingCheckedIn, booking.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish booking checked in event")
+	}
+
+	s.metrics.BookingsTotal.WithLabelValues(string(booking.Status), "default", tenancy.ID(ctx)).Inc()
+	s.logger.WithContext(ctx).With("booking_id", id).Info("booking checked in")
+
+	return booking, nil
+}
+
+// CheckOut completes a booking that was checked in. Checking out before
+// end_time frees the rest of the slot; checking out after it is recorded
+// as is.
+func (s *BookingService) CheckOut(ctx context.Context, id string) (_ *domain.Booking, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.check_out", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !booking.CanBeCheckedOut() {
+		return nil, errors.NewConflictError("only checked in bookings can be checked out")
+	}
+
+	checkedOutAt := time.Now().UTC()
+	err = s.repo.Update(ctx, id, booking.Version, map[string]any{
+		"status":         domain.BookingStatusCompleted,
+		"checked_out_at": checkedOutAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	before := *booking
+	booking.Status = domain.BookingStatusCompleted
+	booking.CheckedOutAt = &checkedOutAt
+	booking.Version++
+	audit.Log(ctx, "booking.check_out", "booking", id, &before, booking)
+
+	event := events.BookingCheckedOutEvent{
+		BaseEvent: events.NewBaseEvent(events.BookingCheckedOut, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.BookingCheckedOutData{
+			BookingID:    booking.ID,
+			UserID:       booking.UserID,
+			ResourceID:   booking.ResourceID,
+			StartTime:    booking.StartTime,
+			EndTime:      booking.EndTime,
+			Amount:       booking.Amount,
+			CheckedInAt:  *booking.CheckedInAt,
+			CheckedOutAt: checkedOutAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.BookingCheckedOut, booking.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish booking checked out event")
+	}
+
+	s.metrics.BookingsTotal.WithLabelValues(string(booking.Status), "default", tenancy.ID(ctx)).Inc()
+	s.logger.WithContext(ctx).With("booking_id", id).Info("booking checked out")
+
+	return booking, nil
+}
+
+// MarkNoShows marks the confirmed bookings whose check-in window closed
+// without a check-in as no-shows. It returns the number of bookings
+// marked.
+func (s *BookingService) MarkNoShows(ctx context.Context) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.mark_no_shows")
+	defer tracing.End(span, &err)
+
+	now := time.Now().UTC()
+	bookings, err := s.repo.MarkNoShows(ctx, now.Add(-s.checkIn.Grace), now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, booking := range bookings {
+		event := events.BookingNoShowEvent{
+			BaseEvent: events.NewBaseEvent(events.BookingNoShow, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+			Data: events.BookingNoShowData{
+				BookingID:  booking.ID,
+				UserID:     booking.UserID,
+				ResourceID: booking.ResourceID,
+				StartTime:  booking.StartTime,
+				EndTime:    booking.EndTime,
+				Amount:     booking.Amount,
+				MarkedAt:   now,
+			},
+		}
+
+		if err := s.publisher.Publish(ctx, events.BookingNoShow, booking.ID, event); err != nil {
+			s.logger.WithContext(ctx).WithError(err).Error("failed to publish booking no-show event")
+		}
+
+		s.metrics.BookingsTotal.WithLabelValues(string(domain.BookingStatusNoShow), "default", tenancy.ID(ctx)).Inc()
+	}
+
+	return len(bookings), nil
+}
+
+// NoShowJob returns the scheduled job that marks bookings nobody checked
+// in as no-shows, running every interval.
+func NoShowJob(s *BookingService, logger *logger.Logger, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "booking_no_shows",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			marked, err := s.MarkNoShows(ctx)
+			if err != nil {
+				return err
+			}
+
+			if marked > 0 {
+				logger.WithContext(ctx).With("marked", strconv.Itoa(marked)).Info("marked bookings as no-shows")
+			}
+			return nil
+		},
+	}
+}
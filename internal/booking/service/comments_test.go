@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// commentRepository keeps the comments of one booking in memory; its
+// other methods are not used.
+type commentRepository struct {
+	BookingRepository
+	booking  *domain.Booking
+	comments []*domain.BookingComment
+	now      time.Time
+}
+
+func (r *commentRepository) GetByID(_ context.Context, id string) (*domain.Booking, error) {
+	if id != r.booking.ID {
+		return nil, errors.NewNotFoundError("booking")
+	}
+	return r.booking, nil
+}
+
+func (r *commentRepository) AddComment(_ context.Context, comment *domain.BookingComment) error {
+	r.now = r.now.Add(time.Second)
+	comment.ID = comment.Text
+	comment.CreatedAt = r.now
+	r.comments = append(r.comments, comment)
+	return nil
+}
+
+// ListComments returns the comments oldest first, like the database.
+func (r *commentRepository) ListComments(_ context.Context, bookingID string) ([]*domain.BookingComment, error) {
+	comments := slices.Clone(r.comments)
+	slices.SortFunc(comments, func(a, b *domain.BookingComment) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return comments, nil
+}
+
+func TestComments(t *testing.T) {
+	type add struct {
+		bookingID string
+		authorID  string
+		text      string
+		wantErr   errors.ErrorType
+	}
+
+	tests := []struct {
+		name string
+		adds []add
+		want []string
+	}{
+		{
+			name: "appended in order",
+			adds: []add{
+				{bookingID: "b-1", authorID: "staff-1", text: "guest called"},
+				{bookingID: "b-1", authorID: "staff-2", text: "late arrival"},
+				{bookingID: "b-1", authorID: "staff-1", text: "key handed over"},
+			},
+			want: []string{"guest called", "late arrival", "key handed over"},
+		},
+		{
+			name: "empty text",
+			adds: []add{
+				{bookingID: "b-1", authorID: "staff-1", text: "guest called"},
+				{bookingID: "b-1", authorID: "staff-1", text: "", wantErr: errors.ErrorTypeValidation},
+			},
+			want: []string{"guest called"},
+		},
+		{
+			name: "unknown booking",
+			adds: []add{
+				{bookingID: "b-2", authorID: "staff-1", text: "guest called", wantErr: errors.ErrorTypeNotFound},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &commentRepository{booking: &domain.Booking{ID: "b-1"}, now: time.Now()}
+			s := NewBookingService(repo, nil, nil, nil, nil, domain.CancellationPolicy{}, domain.CheckInPolicy{}, nil,
+				logger.New("test", "error"), nil, noop.NewTracerProvider().Tracer("test"))
+			ctx := context.Background()
+
+			for _, a := range tt.adds {
+				comment, err := s.AddComment(ctx, a.bookingID, a.authorID, &domain.AddCommentRequest{Text: a.text})
+				if a.wantErr != "" {
+					if err == nil || errors.GetAppError(err).Type != a.wantErr {
+						t.Fatalf("AddComment(%q) error = %v, want %s", a.text, err, a.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("AddComment(%q) error = %v", a.text, err)
+				}
+				if comment.AuthorID != a.authorID || comment.CreatedAt.IsZero() {
+					t.Errorf("AddComment(%q) = author %q at %v, want author %q with a time", a.text, comment.AuthorID, comment.CreatedAt, a.authorID)
+				}
+			}
+
+			comments, err := s.ListComments(ctx, "b-1")
+			if err != nil {
+				t.Fatalf("ListComments() error = %v", err)
+			}
+			texts := make([]string, len(comments))
+			for i, comment := range comments {
+				texts[i] = comment.Text
+			}
+			if !slices.Equal(texts, tt.want) {
+				t.Errorf("ListComments() = %q, want %q", texts, tt.want)
+			}
+		})
+	}
+}
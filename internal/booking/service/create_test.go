@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// createRepository books into an empty resource; its other methods are not
+// used.
+type createRepository struct {
+	BookingRepository
+	created []*domain.Booking
+}
+
+func (r *createRepository) GetResourceRules(context.Context, string) (*domain.ResourceRules, error) {
+	return &domain.ResourceRules{Capacity: 1}, nil
+}
+
+func (r *createRepository) ListOverlapping(context.Context, string, time.Time, time.Time) ([]*domain.Booking, error) {
+	return nil, nil
+}
+
+func (r *createRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *createRepository) Create(_ context.Context, booking *domain.Booking) error {
+	booking.ID = "b-1"
+	r.created = append(r.created, booking)
+	return nil
+}
+
+// emptyWaitlist has no offers; its other methods are not used.
+type emptyWaitlist struct {
+	WaitlistRepository
+}
+
+func (emptyWaitlist) ListOffered(context.Context, string, time.Time, time.Time, time.Time) ([]*domain.WaitlistEntry, error) {
+	return nil, nil
+}
+
+func (emptyWaitlist) ClaimOffers(context.Context, string, string, time.Time, time.Time) error {
+	return nil
+}
+
+// recordingPublisher records the types of the events published.
+type recordingPublisher struct {
+	published []events.EventType
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, eventType events.EventType, _ string, _ any) error {
+	p.published = append(p.published, eventType)
+	return nil
+}
+
+func TestCreateBookingTiming(t *testing.T) {
+	start := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Hour)
+
+	tests := []struct {
+		name       string
+		req        *domain.CreateBookingRequest
+		wantErr    bool
+		wantPhases []string
+	}{
+		{
+			name: "created",
+			req: &domain.CreateBookingRequest{
+				UserID:     "6f1c2a8e-3b7d-4e59-9a0c-2d4f6b8e1a3c",
+				ResourceID: "9a2b3c4d-5e6f-4a1b-8c2d-3e4f5a6b7c8d",
+				StartTime:  start,
+				EndTime:    start.Add(time.Hour),
+			},
+			wantPhases: []string{"validation", "db", "kafka"},
+		},
+		{
+			name: "invalid",
+			req: &domain.CreateBookingRequest{
+				ResourceID: "9a2b3c4d-5e6f-4a1b-8c2d-3e4f5a6b7c8d",
+				StartTime:  start.Add(-48 * time.Hour),
+				EndTime:    start.Add(-47 * time.Hour),
+			},
+			wantErr:    true,
+			wantPhases: []string{"validation"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			s := NewBookingService(&createRepository{}, nil, nil, nil, emptyWaitlist{}, domain.CancellationPolicy{}, domain.CheckInPolicy{}, publisher,
+				logger.New("test", "error"), metrics.New("booking_test"), noop.NewTracerProvider().Tracer("test"))
+
+			ctx, breadcrumbs := timing.NewContext(context.Background())
+			if _, err := s.CreateBooking(ctx, tt.req); (err != nil) != tt.wantErr {
+				t.Fatalf("CreateBooking() error = %v, want error: %v", err, tt.wantErr)
+			}
+
+			var phases []string
+			for field := range breadcrumbs.Fields() {
+				phases = append(phases, field)
+			}
+			slices.Sort(phases)
+
+			want := make([]string, 0, len(tt.wantPhases))
+			for _, phase := range tt.wantPhases {
+				want = append(want, phase+"_ms")
+			}
+			slices.Sort(want)
+
+			if !slices.Equal(phases, want) {
+				t.Errorf("phases = %q, want %q", phases, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// exportPageSize is the number of bookings read per query while exporting.
+const exportPageSize = 500
+
+var bookingExportHeader = []string{
+	"id", "user_id", "user_email", "resource_id", "resource_name", "status",
+	"start_time", "end_time", "amount", "currency", "cancellation_fee",
+	"cancelled_at", "checked_in_at", "checked_out_at", "created_at",
+}
+
+// ExportBookings writes the bookings matching filter to w, oldest first.
+// It pages through them with a cursor so the export is never held in
+// memory, flushing w after every page.
+func (s *BookingService) ExportBookings(ctx context.Context, filter domain.ListBookingsFilter, w export.Writer) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.export")
+	defer tracing.End(span, &err)
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return 0, errors.NewValidationError("to must be after from", nil)
+	}
+
+	if err := w.Write(bookingExportHeader); err != nil {
+		return 0, errors.NewInternalError("failed to write export", err)
+	}
+
+	params := pagination.Params{Page: 1, PageSize: exportPageSize, Sort: pagination.Sort{Field: "created_at"}}
+	written := 0
+	for {
+		bookings, result, err := s.repo.List(ctx, filter, params)
+		if err != nil {
+			return written, err
+		}
+
+		for _, b := range bookings {
+			if err := w.Write(bookingExportRow(b)); err != nil {
+				return written, errors.NewInternalError("failed to write export", err)
+			}
+			written++
+		}
+		if err := w.Flush(); err != nil {
+			return written, errors.NewInternalError("failed to write export", err)
+		}
+
+		if result.NextCursor == "" {
+			return written, nil
+		}
+		if params.Cursor, err = pagination.DecodeCursor(result.NextCursor); err != nil {
+			return written, err
+		}
+	}
+}
+
+func bookingExportRow(b *domain.Booking) []string {
+	return []string{
+		b.ID, b.UserID, b.UserEmail, b.ResourceID, b.ResourceName, string(b.Status),
+		formatTime(&b.StartTime), formatTime(&b.EndTime), b.Amount.Decimal(), string(b.Amount.Currency),
+		b.CancellationFee.Decimal(), formatTime(b.CancelledAt), formatTime(b.CheckedInAt),
+		formatTime(b.CheckedOutAt), formatTime(&b.CreatedAt),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// listRepository returns fixed bookings; its other methods are not used.
+type listRepository struct {
+	BookingRepository
+	bookings []*domain.Booking
+}
+
+func (r *listRepository) List(context.Context, domain.ListBookingsFilter, pagination.Params) ([]*domain.Booking, *pagination.Result, error) {
+	return r.bookings, &pagination.Result{}, nil
+}
+
+// userDirectory knows some users, hangs on others until the lookup times
+// out and fails to look up the rest.
+type userDirectory struct {
+	names   map[string]string
+	hang    map[string]bool
+	mu      sync.Mutex
+	lookups []string
+}
+
+func (d *userDirectory) LookupUser(ctx context.Context, userID string) (string, string, error) {
+	d.mu.Lock()
+	d.lookups = append(d.lookups, userID)
+	d.mu.Unlock()
+
+	if d.hang[userID] {
+		<-ctx.Done()
+		return "", "", ctx.Err()
+	}
+	name, ok := d.names[userID]
+	if !ok {
+		return "", "", errors.NewExternalError("user-service", "failed to look up user", nil)
+	}
+	return name, userID + "@example.com", nil
+}
+
+func TestListBookingsAddsUsers(t *testing.T) {
+	type row struct {
+		userName string
+		warnings []string
+	}
+
+	tests := []struct {
+		name        string
+		local       map[string]string
+		remote      map[string]string
+		hang        map[string]bool
+		noDirectory bool
+		want        map[string]row
+		wantLookups []string
+	}{
+		{
+			name:  "users known locally",
+			local: map[string]string{"u-1": "Ada", "u-2": "Grace"},
+			want: map[string]row{
+				"b-1": {userName: "Ada"},
+				"b-2": {userName: "Grace"},
+				"b-3": {userName: "Ada"},
+			},
+		},
+		{
+			name:   "user looked up",
+			local:  map[string]string{"u-1": "Ada"},
+			remote: map[string]string{"u-2": "Grace"},
+			want: map[string]row{
+				"b-1": {userName: "Ada"},
+				"b-2": {userName: "Grace"},
+				"b-3": {userName: "Ada"},
+			},
+			wantLookups: []string{"u-2"},
+		},
+		{
+			name:   "one lookup fails",
+			remote: map[string]string{"u-1": "Ada"},
+			want: map[string]row{
+				"b-1": {userName: "Ada"},
+				"b-2": {warnings: []string{domain.WarningUserUnavailable}},
+				"b-3": {userName: "Ada"},
+			},
+			wantLookups: []string{"u-1", "u-2"},
+		},
+		{
+			name:   "lookup hangs",
+			remote: map[string]string{"u-1": "Ada"},
+			hang:   map[string]bool{"u-2": true},
+			want: map[string]row{
+				"b-1": {userName: "Ada"},
+				"b-2": {warnings: []string{domain.WarningUserUnavailable}},
+				"b-3": {userName: "Ada"},
+			},
+			wantLookups: []string{"u-1", "u-2"},
+		},
+		{
+			name:        "no directory",
+			noDirectory: true,
+			want: map[string]row{
+				"b-1": {},
+				"b-2": {},
+				"b-3": {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookings := []*domain.Booking{
+				{ID: "b-1", UserID: "u-1", UserName: tt.local["u-1"]},
+				{ID: "b-2", UserID: "u-2", UserName: tt.local["u-2"]},
+				{ID: "b-3", UserID: "u-1", UserName: tt.local["u-1"]},
+			}
+			directory := &userDirectory{names: tt.remote, hang: tt.hang}
+
+			s := NewBookingService(&listRepository{bookings: bookings}, nil, nil, nil, nil, domain.CancellationPolicy{}, domain.CheckInPolicy{}, nil,
+				logger.New("test", "error"), nil, noop.NewTracerProvider().Tracer("test"))
+			if !tt.noDirectory {
+				s.SetUserDirectory(directory)
+			}
+
+			started := time.Now()
+			got, _, err := s.ListBookings(context.Background(), domain.ListBookingsFilter{}, pagination.Params{})
+			if err != nil {
+				t.Fatalf("ListBookings() error = %v", err)
+			}
+			if elapsed := time.Since(started); elapsed > 2*userLookupTimeout {
+				t.Errorf("ListBookings() took %v, want at most about %v", elapsed, userLookupTimeout)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ListBookings() returned %d bookings, want %d", len(got), len(tt.want))
+			}
+			for _, booking := range got {
+				want := tt.want[booking.ID]
+				if booking.UserName != want.userName || !slices.Equal(booking.Warnings, want.warnings) {
+					t.Errorf("booking %s has user %q and warnings %q, want %q and %q", booking.ID, booking.UserName, booking.Warnings, want.userName, want.warnings)
+				}
+			}
+			slices.Sort(directory.lookups)
+			if !slices.Equal(directory.lookups, tt.wantLookups) {
+				t.Errorf("looked up %q, want %q", directory.lookups, tt.wantLookups)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PrivacyRepository interface {
+	GetUserData(ctx context.Context, userID string) (*domain.UserData, error)
+	EraseUserData(ctx context.Context, userID string) (int, error)
+}
+
+// PrivacyService answers data exports and erasures of users with the
+// bookings part of their personal data.
+type PrivacyService struct {
+	repo      PrivacyRepository
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	tracer    trace.Tracer
+}
+
+func NewPrivacyService(repo PrivacyRepository, publisher messagebus.Publisher, logger *logger.Logger, tracer trace.Tracer) *PrivacyService {
+	return &PrivacyService{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		tracer:    tracer,
+	}
+}
+
+// ExportUserData sends the bookings part of the data export exportID.
+func (s *PrivacyService) ExportUserData(ctx context.Context, exportID, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.export_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	data, err := s.repo.GetUserData(ctx, userID)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(data)
+	if err != nil {
+		return errors.NewInternalError("failed to encode user data", err)
+	}
+
+	event := events.UserDataExportPartEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataExportPart, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataExportPartData{
+			ExportID: exportID,
+			UserID:   userID,
+			Part:     events.DataPartBookings,
+			Content:  content,
+		},
+	}
+	if err := s.publisher.Publish(ctx, events.UserDataExportPart, userID, event); err != nil {
+		return errors.NewInternalError("failed to send data export part", err)
+	}
+
+	return nil
+}
+
+// EraseUserData erases the personal data of a deleted user and reports
+// it.
+func (s *PrivacyService) EraseUserData(ctx context.Context, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.erase_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	erased, err := s.repo.EraseUserData(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	event := events.UserDataErasedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataErased, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataErasedData{
+			UserID:   userID,
+			Part:     events.DataPartBookings,
+			Records:  erased,
+			Retained: []string{"bookings and ratings, without notes and comments, for schedules and statistics"},
+			ErasedAt: time.Now().UTC(),
+		},
+	}
+	if err := s.publisher.Publish(ctx, events.UserDataErased, userID, event); err != nil {
+		return errors.NewInternalError("failed to report erasure", err)
+	}
+
+	s.logger.WithContext(ctx).With("user_id", userID).Info("erased personal data of deleted user")
+
+	return nil
+}
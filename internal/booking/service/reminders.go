@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// SendReminders publishes a reminder for every confirmed booking starting
+// within lead that was not reminded of yet. Bookings are marked before the
+// reminders are published, so a failed publish skips a reminder rather
+// than repeating one. It returns the number of bookings reminded of.
+func (s *BookingService) SendReminders(ctx context.Context, lead time.Duration) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.send_reminders")
+	defer tracing.End(span, &err)
+
+	now := time.Now().UTC()
+	bookings, err := s.repo.MarkReminded(ctx, now, now.Add(lead))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, booking := range bookings {
+		event := events.BookingReminderEvent{
+			BaseEvent: events.NewBaseEvent(events.BookingReminder, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+			Data: events.BookingReminderData{
+				BookingID:  booking.ID,
+				UserID:     booking.UserID,
+				ResourceID: booking.ResourceID,
+				StartTime:  booking.StartTime,
+				EndTime:    booking.EndTime,
+			},
+		}
+
+		if err := s.publisher.Publish(ctx, events.BookingReminder, booking.ID, event); err != nil {
+			s.logger.WithContext(ctx).WithError(err).With("booking_id", booking.ID).Error("failed to publish booking reminder event")
+		}
+	}
+
+	return len(bookings), nil
+}
+
+// ReminderJob returns the scheduled job that reminds users of bookings
+// starting within lead, running every interval.
+func ReminderJob(s *BookingService, logger *logger.Logger, lead, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "booking_reminders",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			reminded, err := s.SendReminders(ctx, lead)
+			if err != nil {
+				return err
+			}
+
+			if reminded > 0 {
+				logger.WithContext(ctx).With("reminded", strconv.Itoa(reminded)).Info("sent booking reminders")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+)
+
+// RetentionJob returns the scheduled job that hard-deletes bookings
+// soft-deleted more than retention ago, running every interval.
+func RetentionJob(s *BookingService, logger *logger.Logger, retention, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "booking_retention",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			purged, err := s.PurgeDeletedBookings(ctx, retention)
+			if err != nil {
+				return err
+			}
+
+			if purged > 0 {
+				logger.WithContext(ctx).With("purged", strconv.FormatInt(purged, 10)).Info("purged deleted bookings past retention")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// seriesRepository keeps the bookings of one series in memory; its other
+// methods are not used.
+type seriesRepository struct {
+	BookingRepository
+	bookings []*domain.Booking
+}
+
+func (r *seriesRepository) ListSeries(context.Context, string) ([]*domain.Booking, error) {
+	bookings := make([]*domain.Booking, len(r.bookings))
+	for i, booking := range r.bookings {
+		copied := *booking
+		bookings[i] = &copied
+	}
+	return bookings, nil
+}
+
+func (r *seriesRepository) GetByID(_ context.Context, id string) (*domain.Booking, error) {
+	for _, booking := range r.bookings {
+		if booking.ID == id {
+			copied := *booking
+			return &copied, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("booking")
+}
+
+func (r *seriesRepository) Update(_ context.Context, id string, _ int64, updates map[string]any) error {
+	for _, booking := range r.bookings {
+		if booking.ID == id {
+			booking.Status = updates["status"].(domain.BookingStatus)
+		}
+	}
+	return nil
+}
+
+// cancelledPublisher records the bookings booking.cancelled was published
+// for.
+type cancelledPublisher struct {
+	cancelled []string
+}
+
+func (p *cancelledPublisher) Publish(_ context.Context, eventType events.EventType, key string, _ any) error {
+	if eventType == events.BookingCancelled {
+		p.cancelled = append(p.cancelled, key)
+	}
+	return nil
+}
+
+func TestCancelSeries(t *testing.T) {
+	// A weekly series whose first occurrence already took place
+	ids := []string{
+		"0b4e3a52-8f0e-4a51-9d1a-6c2f1e7b9a01",
+		"0b4e3a52-8f0e-4a51-9d1a-6c2f1e7b9a02",
+		"0b4e3a52-8f0e-4a51-9d1a-6c2f1e7b9a03",
+		"0b4e3a52-8f0e-4a51-9d1a-6c2f1e7b9a04",
+	}
+	series := func() []*domain.Booking {
+		start := time.Now().UTC().Add(-6 * 24 * time.Hour)
+		bookings := make([]*domain.Booking, len(ids))
+		for i, id := range ids {
+			bookings[i] = &domain.Booking{
+				ID:        id,
+				Status:    domain.BookingStatusConfirmed,
+				StartTime: start.Add(time.Duration(i) * 7 * 24 * time.Hour),
+				EndTime:   start.Add(time.Duration(i)*7*24*time.Hour + time.Hour),
+			}
+		}
+		bookings[0].Status = domain.BookingStatusCompleted
+		return bookings
+	}
+
+	tests := []struct {
+		name          string
+		req           domain.CancelSeriesRequest
+		wantCancelled []string
+		wantErr       errors.ErrorType
+	}{
+		{
+			name:          "whole series by default",
+			req:           domain.CancelSeriesRequest{Reason: "moving"},
+			wantCancelled: ids[1:],
+		},
+		{
+			name:          "all in series",
+			req:           domain.CancelSeriesRequest{Reason: "moving", Scope: domain.ScopeAllInSeries},
+			wantCancelled: ids[1:],
+		},
+		{
+			name:          "this occurrence",
+			req:           domain.CancelSeriesRequest{Reason: "ill", Scope: domain.ScopeThisOccurrence, BookingID: ids[2]},
+			wantCancelled: ids[2:3],
+		},
+		{
+			name:          "this and following",
+			req:           domain.CancelSeriesRequest{Reason: "moving", Scope: domain.ScopeThisAndFollowing, BookingID: ids[2]},
+			wantCancelled: ids[2:],
+		},
+		{
+			name:    "past occurrence",
+			req:     domain.CancelSeriesRequest{Reason: "ill", Scope: domain.ScopeThisOccurrence, BookingID: ids[0]},
+			wantErr: errors.ErrorTypeConfict,
+		},
+		{
+			name:    "occurrence of another series",
+			req:     domain.CancelSeriesRequest{Reason: "ill", Scope: domain.ScopeThisOccurrence, BookingID: "0b4e3a52-8f0e-4a51-9d1a-6c2f1e7b9aff"},
+			wantErr: errors.ErrorTypeNotFound,
+		},
+		{
+			name:    "scope without occurrence",
+			req:     domain.CancelSeriesRequest{Reason: "ill", Scope: domain.ScopeThisAndFollowing},
+			wantErr: errors.ErrorTypeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &seriesRepository{bookings: series()}
+			publisher := &cancelledPublisher{}
+			s := NewBookingService(repo, nil, nil, nil, nil, domain.CancellationPolicy{}, domain.CheckInPolicy{}, publisher,
+				logger.New("test", "error"), metrics.New("booking-service"), noop.NewTracerProvider().Tracer("test"))
+
+			result, err := s.CancelSeries(context.Background(), "series-1", &tt.req)
+			if tt.wantErr != "" {
+				if err == nil || errors.GetAppError(err).Type != tt.wantErr {
+					t.Fatalf("CancelSeries() error = %v, want %s", err, tt.wantErr)
+				}
+				if len(publisher.cancelled) != 0 {
+					t.Errorf("cancelled %q, want none", publisher.cancelled)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CancelSeries() error = %v", err)
+			}
+
+			var cancelled []string
+			for _, booking := range result.Bookings {
+				if booking.Status == domain.BookingStatusCancelled {
+					cancelled = append(cancelled, booking.ID)
+				}
+			}
+			if !slices.Equal(cancelled, tt.wantCancelled) {
+				t.Errorf("cancelled bookings = %q, want %q", cancelled, tt.wantCancelled)
+			}
+			if !slices.Equal(publisher.cancelled, tt.wantCancelled) {
+				t.Errorf("booking.cancelled published for %q, want %q", publisher.cancelled, tt.wantCancelled)
+			}
+		})
+	}
+}
@@ -0,0 +1,1056 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/featureflags"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	pricingdomain "github.com/dmehra2102/booking-system/internal/pricing/domain"
+	"github.com/dmehra2102/booking-system/pkg/documents"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultCurrency = money.USD
+
+const (
+	// resourceLockTTL bounds how long a crashed replica can block a resource.
+	resourceLockTTL = 10 * time.Second
+	// resourceLockWait is how long a request queues behind another writer.
+	resourceLockWait = 5 * time.Second
+	// userLookupTimeout bounds the user lookups of one booking list.
+	userLookupTimeout = 2 * time.Second
+	// userLookupConcurrency bounds the users looked up at once.
+	userLookupConcurrency = 8
+)
+
+type BookingRepository interface {
+	Create(ctx context.Context, booking *domain.Booking) error
+	CreateSeries(ctx context.Context, bookings []*domain.Booking) (string, error)
+	ListSeries(ctx context.Context, seriesID string) ([]*domain.Booking, error)
+	GetByID(ctx context.Context, id string) (*domain.Booking, error)
+	Update(ctx context.Context, id string, version int64, updates map[string]any) error
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	List(ctx context.Context, filter domain.ListBookingsFilter, params pagination.Params) ([]*domain.Booking, *pagination.Result, error)
+	ListOverlapping(ctx context.Context, resourceID string, start, end time.Time) ([]*domain.Booking, error)
+	MarkNoShows(ctx context.Context, startedBefore, now time.Time) ([]*domain.Booking, error)
+	MarkReminded(ctx context.Context, now, startsBy time.Time) ([]*domain.Booking, error)
+	GetResourceRules(ctx context.Context, resourceID string) (*domain.ResourceRules, error)
+	AddComment(ctx context.Context, comment *domain.BookingComment) error
+	ListComments(ctx context.Context, bookingID string) ([]*domain.BookingComment, error)
+
+	// WithTx runs fn in a transaction that the calls of every repository
+	// made with the context passed to fn take part in.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ReferenceValidator confirms that the user and resource a booking refers
+// to exist in the services that own them.
+type ReferenceValidator interface {
+	ValidateUser(ctx context.Context, userID string) error
+	ValidateResource(ctx context.Context, resourceID string) error
+}
+
+// UserDirectory looks up the users the local copy of the user table does
+// not know yet, e.g. while their user.created event is on its way.
+type UserDirectory interface {
+	LookupUser(ctx context.Context, userID string) (name, email string, err error)
+}
+
+// Locker serialises work on a key across service replicas.
+type Locker interface {
+	AcquireLock(ctx context.Context, key string, ttl time.Duration) (*database.Lock, error)
+	ReleaseLock(ctx context.Context, lock *database.Lock) error
+}
+
+// Pricer prices bookings and redeems the promo codes applied to them.
+type Pricer interface {
+	Quote(ctx context.Context, req *pricingdomain.QuoteRequest) (*pricingdomain.Quote, error)
+	Redeem(ctx context.Context, code string) error
+}
+
+var _ domain.BookingService = (*BookingService)(nil)
+
+type BookingService struct {
+	repo       BookingRepository
+	references ReferenceValidator
+	locker     Locker
+	pricer     Pricer
+	waitlist   WaitlistRepository
+	users      UserDirectory
+	policy     domain.CancellationPolicy
+	checkIn    domain.CheckInPolicy
+	publisher  messagebus.Publisher
+	logger     *logger.Logger
+	metrics    *metrics.Metrics
+	tracer     trace.Tracer
+}
+
+// NewBookingService creates the booking service. references may be nil, in
+// which case only the local database is consulted. locker may be nil when
+// a single replica runs. pricer may be nil, leaving bookings unpriced.
+func NewBookingService(
+	repo BookingRepository,
+	references ReferenceValidator,
+	locker Locker,
+	pricer Pricer,
+	waitlist WaitlistRepository,
+	policy domain.CancellationPolicy,
+	checkIn domain.CheckInPolicy,
+	publisher messagebus.Publisher,
+	logger *logger.Logger,
+	metrics *metrics.Metrics,
+	tracer trace.Tracer,
+) *BookingService {
+	return &BookingService{
+		repo:       repo,
+		references: references,
+		locker:     locker,
+		pricer:     pricer,
+		waitlist:   waitlist,
+		policy:     policy,
+		checkIn:    checkIn,
+		publisher:  publisher,
+		logger:     logger,
+		metrics:    metrics,
+		tracer:     tracer,
+	}
+}
+
+// SetUserDirectory completes listed bookings whose user is missing from
+// the local copy of the user table with a lookup in users.
+func (s *BookingService) SetUserDirectory(users UserDirectory) {
+	s.users = users
+}
+
+func (s *BookingService) CreateBooking(ctx context.Context, req *domain.CreateBookingRequest) (_ *domain.Booking, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.create")
+	defer tracing.End(span, &err)
+
+	start := time.Now()
+	defer func() {
+		s.metrics.BookingDuration.WithLabelValues("create").Observe(time.Since(start).Seconds())
+	}()
+
+	// Validate Request
+	doneValidating := timing.Track(ctx, "validation")
+	err = s.validateCreate(ctx, req)
+	doneValidating()
+	if err != nil {
+		return nil, err
+	}
+
+	booking := &domain.Booking{
+		UserID:     req.UserID,
+		ResourceID: req.ResourceID,
+		StartTime:  req.StartTime.UTC(),
+		EndTime:    req.EndTime.UTC(),
+		Status:     domain.BookingStatusPending,
+		Amount:     money.Zero(defaultCurrency),
+		Notes:      req.Notes,
+	}
+
+	quote, err := s.price(ctx, booking, req.PromoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lockResource(ctx, booking.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	doneChecking := timing.Track(ctx, "db")
+	err = s.checkAvailability(ctx, booking)
+	doneChecking()
+	if err != nil {
+		return nil, err
+	}
+
+	// Redeeming fails if the code ran out since it was quoted
+	if quote != nil && quote.PromoCode != "" {
+		if err := s.pricer.Redeem(ctx, quote.PromoCode); err != nil {
+			return nil, err
+		}
+	}
+
+	// A slot offered from the waitlist is no longer held once it is booked
+	doneWriting := timing.Track(ctx, "db")
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, booking); err != nil {
+			return err
+		}
+		return s.waitlist.ClaimOffers(ctx, booking.UserID, booking.ResourceID, booking.StartTime, booking.EndTime)
+	})
+	doneWriting()
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(
+		tracing.BookingID.String(booking.ID),
+		tracing.UserID.String(booking.UserID),
+		tracing.ResourceID.String(booking.ResourceID),
+	)
+
+	s.created(ctx, booking)
+	s.logger.WithContext(ctx).With("booking_id", booking.ID).Info("booking created successfully")
+
+	return booking, nil
+}
+
+// CreateSeries books every occurrence of a recurring booking. The series
+// is created only if each occurrence fits the resource capacity; otherwise
+// the conflicting occurrences are reported and nothing is booked. A promo
+// code applies to every occurrence and is redeemed once.
+func (s *BookingService) CreateSeries(ctx context.Context, req *domain.CreateBookingRequest) (_ *domain.BookingSeries, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.create_series")
+	defer tracing.End(span, &err)
+
+	start := time.Now()
+	defer func() {
+		s.metrics.BookingDuration.WithLabelValues("create_series").Observe(time.Since(start).Seconds())
+	}()
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+	if req.Recurrence == nil {
+		return nil, errors.NewValidationError("recurrence is required", nil)
+	}
+	if err := validateWindow(req.StartTime, req.EndTime); err != nil {
+		return nil, err
+	}
+
+	starts, err := req.Recurrence.Occurrences(req.StartTime)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error(), nil)
+	}
+
+	if err := s.validateReferences(ctx, req); err != nil {
+		return nil, err
+	}
+
+	duration := req.EndTime.Sub(req.StartTime)
+	bookings := make([]*domain.Booking, 0, len(starts))
+	promoCode := ""
+	for _, occurrence := range starts {
+		booking := &domain.Booking{
+			UserID:     req.UserID,
+			ResourceID: req.ResourceID,
+			StartTime:  occurrence,
+			EndTime:    occurrence.Add(duration),
+			Status:     domain.BookingStatusPending,
+			Amount:     money.Zero(defaultCurrency),
+			Notes:      req.Notes,
+		}
+
+		quote, err := s.price(ctx, booking, req.PromoCode)
+		if err != nil {
+			return nil, err
+		}
+		if quote != nil {
+			promoCode = quote.PromoCode
+		}
+		bookings = append(bookings, booking)
+	}
+
+	unlock, err := s.lockResource(ctx, req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := s.checkSeriesAvailability(ctx, bookings); err != nil {
+		return nil, err
+	}
+
+	if promoCode != "" {
+		if err := s.pricer.Redeem(ctx, promoCode); err != nil {
+			return nil, err
+		}
+	}
+
+	var seriesID string
+	err = s.repo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if seriesID, err = s.repo.CreateSeries(ctx, bookings); err != nil {
+			return err
+		}
+		for _, booking := range bookings {
+			if err := s.waitlist.ClaimOffers(ctx, booking.UserID, booking.ResourceID, booking.StartTime, booking.EndTime); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(
+		tracing.UserID.String(req.UserID),
+		tracing.ResourceID.String(req.ResourceID),
+	)
+
+	for _, booking := range bookings {
+		s.created(ctx, booking)
+	}
+	s.logger.WithContext(ctx).With("series_id", seriesID).
+		With("occurrences", strconv.Itoa(len(bookings))).
+		Info("booking series created successfully")
+
+	return &domain.BookingSeries{ID: seriesID, Bookings: bookings}, nil
+}
+
+// validateCreate checks a request for a single booking and that the user
+// and resource it refers to exist.
+func (s *BookingService) validateCreate(ctx context.Context, req *domain.CreateBookingRequest) error {
+	if err := validation.ValidateStruct(req); err != nil {
+		return errors.NewValidationError("validation failed", err)
+	}
+	if req.Recurrence != nil {
+		return errors.NewValidationError("recurring bookings must be created as a series", nil)
+	}
+	if err := validateWindow(req.StartTime, req.EndTime); err != nil {
+		return err
+	}
+	return s.validateReferences(ctx, req)
+}
+
+func (s *BookingService) validateReferences(ctx context.Context, req *domain.CreateBookingRequest) error {
+	if s.references == nil {
+		return nil
+	}
+	if err := s.references.ValidateUser(ctx, req.UserID); err != nil {
+		return err
+	}
+	return s.references.ValidateResource(ctx, req.ResourceID)
+}
+
+// price sets the amount of a new booking from its quote, which is returned
+// so the promo code it applied can be redeemed. Without a pricer, or with
+// the pricing engine flag off, bookings are free and the quote is nil.
+func (s *BookingService) price(ctx context.Context, booking *domain.Booking, promoCode string) (*pricingdomain.Quote, error) {
+	if s.pricer == nil || !featureflags.Enabled(ctx, featureflags.PricingEngine) {
+		return nil, nil
+	}
+
+	quote, err := s.pricer.Quote(ctx, &pricingdomain.QuoteRequest{
+		ResourceID: booking.ResourceID,
+		StartTime:  booking.StartTime,
+		EndTime:    booking.EndTime,
+		PromoCode:  promoCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	booking.Amount = quote.Total
+	return quote, nil
+}
+
+// validateWindow checks that a booking from start to end lies in the
+// future and ends after it starts, for new bookings and moved ones alike.
+func validateWindow(start, end time.Time) error {
+	if !start.After(time.Now()) {
+		return errors.NewValidationError("start_time must be in the future", nil)
+	}
+	if !end.After(start) {
+		return errors.NewValidationError("end_time must be after start_time", nil)
+	}
+	return nil
+}
+
+// reprice adjusts the amount of a booking moved from the window of before
+// by the difference between the current prices of the two windows, so a
+// discount applied when it was booked carries over. The amount never drops
+// below zero.
+func (s *BookingService) reprice(ctx context.Context, before, booking *domain.Booking) error {
+	if s.pricer == nil || !featureflags.Enabled(ctx, featureflags.PricingEngine) {
+		return nil
+	}
+
+	quote := func(b *domain.Booking) (money.Money, error) {
+		quote, err := s.pricer.Quote(ctx, &pricingdomain.QuoteRequest{
+			ResourceID: b.ResourceID,
+			StartTime:  b.StartTime,
+			EndTime:    b.EndTime,
+		})
+		if err != nil {
+			return money.Money{}, err
+		}
+		return quote.Total, nil
+	}
+
+	previous, err := quote(before)
+	if err != nil {
+		return err
+	}
+	current, err := quote(booking)
+	if err != nil {
+		return err
+	}
+
+	delta, err := current.Sub(previous)
+	if err != nil {
+		return errors.NewInternalError("failed to price booking change", err)
+	}
+	amount, err := booking.Amount.Add(delta)
+	if err != nil {
+		return errors.NewConflictError("resource is no longer priced in the currency of the booking")
+	}
+
+	if amount.Amount < 0 {
+		amount = money.Zero(amount.Currency)
+	}
+	booking.Amount = amount
+	return nil
+}
+
+// created records and announces a booking that was just saved.
+func (s *BookingService) created(ctx context.Context, booking *domain.Booking) {
+	audit.Log(ctx, "booking.create", "booking", booking.ID, nil, booking)
+
+	// Publish event
+	event := events.BookingRequestedEvent{
+		BaseEvent: events.NewBaseEvent(events.BookingRequested, "booking-service", trace.SpanFromContext(ctx).SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.BookingRequestedData{
+			BookingID:  booking.ID,
+			UserID:     booking.UserID,
+			ResourceID: booking.ResourceID,
+			StartTime:  booking.StartTime,
+			EndTime:    booking.EndTime,
+			Amount:     booking.Amount,
+			Status:     string(booking.Status),
+		},
+	}
+
+	donePublishing := timing.Track(ctx, "kafka")
+	err := s.publisher.Publish(ctx, events.BookingRequested, booking.ID, event)
+	donePublishing()
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish booking requested event")
+	}
+
+	s.metrics.BookingsTotal.WithLabelValues(string(booking.Status), "default", tenancy.ID(ctx)).Inc()
+}
+
+// GetSeries returns the occurrences of a recurring booking.
+func (s *BookingService) GetSeries(ctx context.Context, id string) (_ *domain.BookingSeries, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.get_series")
+	defer tracing.End(span, &err)
+
+	bookings, err := s.repo.ListSeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.BookingSeries{ID: id, Bookings: bookings}, nil
+}
+
+// CancelSeries cancels the occurrences of a recurring booking in the scope
+// of req that have not started yet, each under the cancellation policy and
+// with a booking.cancelled event of its own.
+func (s *BookingService) CancelSeries(ctx context.Context, id string, req *domain.CancelSeriesRequest) (_ *domain.BookingSeries, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.cancel_series")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	bookings, err := s.repo.ListSeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var from *domain.Booking
+	if req.Scope == domain.ScopeThisOccurrence || req.Scope == domain.ScopeThisAndFollowing {
+		i := slices.IndexFunc(bookings, func(b *domain.Booking) bool { return b.ID == req.BookingID })
+		if i < 0 {
+			return nil, errors.NewNotFoundError("booking in series")
+		}
+		from = bookings[i]
+	}
+
+	now := time.Now().UTC()
+	cancel := &domain.CancelBookingRequest{Reason: req.Reason}
+	cancelled := 0
+	for i, booking := range bookings {
+		if !req.Scope.Includes(booking, from) || !booking.CanBeCancelled() || !booking.StartTime.After(now) {
+			continue
+		}
+
+		updated, err := s.CancelBooking(ctx, booking.ID, cancel)
+		if err != nil {
+			return nil, err
+		}
+		bookings[i] = updated
+		cancelled++
+	}
+
+	if cancelled == 0 {
+		return nil, errors.NewConflictError("series has no upcoming bookings to cancel")
+	}
+
+	s.logger.WithContext(ctx).With("series_id", id).
+		With("scope", string(cmp.Or(req.Scope, domain.ScopeAllInSeries))).
+		With("cancelled", strconv.Itoa(cancelled)).
+		Info("booking series cancelled")
+
+	return &domain.BookingSeries{ID: id, Bookings: bookings}, nil
+}
+
+func (s *BookingService) GetBooking(ctx context.Context, id string) (_ *domain.Booking, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.get", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetConfirmation returns what the confirmation documents of a booking
+// show. Bookings that were never confirmed have none.
+func (s *BookingService) GetConfirmation(ctx context.Context, id string) (_ *documents.Confirmation, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.get_confirmation", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !booking.HasConfirmation() {
+		return nil, errors.NewConflictError("booking is not confirmed")
+	}
+
+	return &documents.Confirmation{
+		BookingID:    booking.ID,
+		ResourceID:   booking.ResourceID,
+		ResourceName: booking.ResourceName,
+		UserName:     booking.UserName,
+		UserEmail:    booking.UserEmail,
+		StartTime:    booking.StartTime,
+		EndTime:      booking.EndTime,
+		Amount:       booking.Amount,
+	}, nil
+}
+
+// UpdateBooking moves or annotates a pending booking. A new window must lie
+// in the future and be available, and is repriced; booking.updated carries the previous window
+// and amount so the payment service can settle the difference.
+func (s *BookingService) UpdateBooking(ctx context.Context, id string, req *domain.UpdateBookingRequest) (_ *domain.Booking, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.update", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Version != 0 && req.Version != booking.Version {
+		return nil, errors.NewPreconditionFailedError("booking was changed since it was read")
+	}
+	if !booking.CanBeUpdated() {
+		return nil, errors.NewConflictError("booking can no longer be updated")
+	}
+	before := *booking
+
+	updates := make(map[string]any)
+	if req.StartTime != nil {
+		booking.StartTime = req.StartTime.UTC()
+		updates["start_time"] = booking.StartTime
+		// Remind of the new start again
+		updates["reminded_at"] = nil
+	}
+	if req.EndTime != nil {
+		booking.EndTime = req.EndTime.UTC()
+		updates["end_time"] = booking.EndTime
+	}
+	if req.Notes != nil {
+		updates["notes"] = *req.Notes
+	}
+
+	if len(updates) == 0 {
+		return booking, nil
+	}
+
+	if req.StartTime != nil || req.EndTime != nil {
+		if err := validateWindow(booking.StartTime, booking.EndTime); err != nil {
+			return nil, err
+		}
+
+		unlock, err := s.lockResource(ctx, booking.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		if err := s.checkAvailability(ctx, booking); err != nil {
+			return nil, err
+		}
+
+		if err := s.reprice(ctx, &before, booking); err != nil {
+			return nil, err
+		}
+		if booking.Amount != before.Amount {
+			updates["amount"] = booking.Amount.Decimal()
+		}
+	}
+
+	if err := s.repo.Update(ctx, id, booking.Version, updates); err != nil {
+		return nil, err
+	}
+
+	updatedBooking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "booking.update", "booking", id, &before, updatedBooking)
+
+	event := events.BookingUpdatedEvent{
+		BaseEvent: events.NewBaseEvent(events.BookingUpdated, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.BookingUpdatedData{
+			BookingID:  updatedBooking.ID,
+			UserID:     updatedBooking.UserID,
+			ResourceID: updatedBooking.ResourceID,
+			StartTime:  updatedBooking.StartTime,
+			EndTime:    updatedBooking.EndTime,
+			Amount:     updatedBooking.Amount,
+			Status:     string(updatedBooking.Status),
+			Previous: events.BookingPreviousData{
+				StartTime: before.StartTime,
+				EndTime:   before.EndTime,
+				Amount:    before.Amount,
+			},
+			UpdatedAt: updatedBooking.UpdatedAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.BookingUpdated, updatedBooking.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish booking updated event")
+	}
+
+	s.logger.WithContext(ctx).With("booking_id", id).Info("booking updated successfully")
+
+	return updatedBooking, nil
+}
+
+// CancelBooking cancels the booking, charging the fee set by the
+// cancellation policy. Payment service refunds the remainder when it
+// consumes booking.cancelled.
+func (s *BookingService) CancelBooking(ctx context.Context, id string, req *domain.CancelBookingRequest) (_ *domain.Booking, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.cancel", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Version != 0 && req.Version != booking.Version {
+		return nil, errors.NewPreconditionFailedError("booking was changed since it was read")
+	}
+	if !booking.CanBeCancelled() {
+		return nil, errors.NewConflictError("booking can no longer be cancelled")
+	}
+
+	cancelledAt := time.Now().UTC()
+	quote := s.policy.Quote(booking, cancelledAt)
+
+	err = s.repo.Update(ctx, id, booking.Version, map[string]any{
+		"status":              domain.BookingStatusCancelled,
+		"cancellation_reason": req.Reason,
+		"cancellation_fee":    quote.Fee.Decimal(),
+		"cancelled_at":        cancelledAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	before := *booking
+	booking.Status = domain.BookingStatusCancelled
+	booking.CancellationReason = req.Reason
+	booking.CancellationFee = quote.Fee
+	booking.CancelledAt = &cancelledAt
+	booking.Version++
+	audit.Log(ctx, "booking.cancel", "booking", id, &before, booking)
+
+	// Publish event
+	event := events.BookingCancelledEvent{
+		BaseEvent: events.NewBaseEvent(events.BookingCancelled, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.BookingCancelledData{
+			BookingID:       booking.ID,
+			UserID:          booking.UserID,
+			ResourceID:      booking.ResourceID,
+			Reason:          req.Reason,
+			CancellationFee: quote.Fee,
+			RefundAmount:    quote.Refund,
+			CancelledAt:     cancelledAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.BookingCancelled, booking.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish booking cancelled event")
+	}
+
+	s.metrics.BookingsTotal.WithLabelValues(string(booking.Status), "default", tenancy.ID(ctx)).Inc()
+	s.logger.WithContext(ctx).With("booking_id", id).
+		With("cancellation_fee", quote.Fee.String()).
+		Info("booking cancelled successfully")
+
+	return booking, nil
+}
+
+// FailBooking marks a pending booking as failed, e.g. when its slot was
+// released because payment did not arrive in time. Bookings that already
+// moved on are left alone, so redelivered events are harmless.
+func (s *BookingService) FailBooking(ctx context.Context, id, reason string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.fail", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if booking.Status != domain.BookingStatusPending {
+		return nil
+	}
+
+	if err := s.repo.Update(ctx, id, booking.Version, map[string]any{"status": domain.BookingStatusFailed}); err != nil {
+		return err
+	}
+
+	s.metrics.BookingsTotal.WithLabelValues(string(domain.BookingStatusFailed), "default", tenancy.ID(ctx)).Inc()
+	s.logger.WithContext(ctx).With("booking_id", id).With("reason", reason).Info("booking failed")
+
+	return nil
+}
+
+// DeleteBooking soft-deletes a booking that is no longer active. Active
+// bookings must be cancelled first so their reservation and payment are
+// released.
+func (s *BookingService) DeleteBooking(ctx context.Context, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.delete", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if booking.IsActive() {
+		return errors.NewConflictError("active bookings must be cancelled before they are deleted")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	audit.Log(ctx, "booking.delete", "booking", id, booking, nil)
+
+	s.logger.WithContext(ctx).With("booking_id", id).Info("booking deleted successfully")
+
+	return nil
+}
+
+// RestoreBooking undoes DeleteBooking until the retention job has purged
+// the booking.
+func (s *BookingService) RestoreBooking(ctx context.Context, id string) (_ *domain.Booking, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.restore", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	audit.Log(ctx, "booking.restore", "booking", id, nil, booking)
+
+	s.logger.WithContext(ctx).With("booking_id", id).Info("booking restored successfully")
+
+	return booking, nil
+}
+
+// PurgeDeletedBookings permanently removes bookings deleted more than
+// retention ago.
+func (s *BookingService) PurgeDeletedBookings(ctx context.Context, retention time.Duration) (_ int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.purge_deleted")
+	defer tracing.End(span, &err)
+
+	return s.repo.PurgeDeleted(ctx, time.Now().UTC().Add(-retention))
+}
+
+func (s *BookingService) ListBookings(ctx context.Context, filter domain.ListBookingsFilter, params pagination.Params) (_ []*domain.Booking, _ *pagination.Result, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.list")
+	defer tracing.End(span, &err)
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, nil, errors.NewValidationError("to must be after from", nil)
+	}
+
+	params.Normalize()
+	bookings, result, err := s.repo.List(ctx, filter, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.addUsers(ctx, bookings)
+	return bookings, result, nil
+}
+
+// addUsers fills in the users of bookings the local copy of the user table
+// does not know. Bookings whose user cannot be looked up are still listed,
+// with a warning instead of the user. The lookups run in parallel and share
+// userLookupTimeout, so a slow user service delays the list by no more than
+// that.
+func (s *BookingService) addUsers(ctx context.Context, bookings []*domain.Booking) {
+	if s.users == nil {
+		return
+	}
+
+	type user struct {
+		name, email string
+		err         error
+	}
+	users := make(map[string]*user)
+	for _, booking := range bookings {
+		if booking.UserName == "" {
+			users[booking.UserID] = &user{}
+		}
+	}
+	if len(users) == 0 {
+		return
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, userLookupTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, userLookupConcurrency)
+	for userID, u := range users {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			u.name, u.email, u.err = s.users.LookupUser(lookupCtx, userID)
+			if u.err != nil {
+				s.logger.WithContext(ctx).WithError(u.err).With("user_id", userID).Warn("failed to look up user of listed bookings")
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, booking := range bookings {
+		u, ok := users[booking.UserID]
+		if !ok || booking.UserName != "" {
+			continue
+		}
+		if u.err != nil {
+			booking.Warnings = append(booking.Warnings, domain.WarningUserUnavailable)
+			continue
+		}
+		booking.UserName, booking.UserEmail = u.name, u.email
+	}
+}
+
+func (s *BookingService) AddComment(ctx context.Context, bookingID, authorID string, req *domain.AddCommentRequest) (_ *domain.BookingComment, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.add_comment", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	if _, err := s.repo.GetByID(ctx, bookingID); err != nil {
+		return nil, err
+	}
+
+	comment := &domain.BookingComment{
+		BookingID: bookingID,
+		AuthorID:  authorID,
+		Text:      req.Text,
+	}
+
+	if err := s.repo.AddComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+func (s *BookingService) ListComments(ctx context.Context, bookingID string) (_ []*domain.BookingComment, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.list_comments", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	if _, err := s.repo.GetByID(ctx, bookingID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListComments(ctx, bookingID)
+}
+
+// GetAvailability returns the free slots of a resource within [from, to),
+// applying the same capacity and buffer rules used when booking.
+func (s *BookingService) GetAvailability(ctx context.Context, resourceID string, from, to time.Time) (_ *domain.Availability, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.get_availability", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	from, to = from.UTC(), to.UTC()
+	if !to.After(from) {
+		return nil, errors.NewValidationError("to must be after from", nil)
+	}
+	if to.Sub(from) > domain.MaxAvailabilityWindow {
+		return nil, errors.NewValidationError("availability window must not exceed 31 days", nil)
+	}
+
+	rules, err := s.repo.GetResourceRules(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.occupying(ctx, resourceID, from.Add(-rules.Buffer), to.Add(rules.Buffer), "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Availability{
+		ResourceID:    resourceID,
+		From:          from,
+		To:            to,
+		Capacity:      rules.Capacity,
+		BufferMinutes: int(rules.Buffer / time.Minute),
+		Slots:         domain.FreeSlots(from, to, existing, *rules),
+	}, nil
+}
+
+// lockResource serialises the availability check and the write that follows
+// it for a resource, so concurrent requests on different replicas cannot
+// both claim the last free slot. The lock expires after resourceLockTTL, so
+// a request stalled for longer than that is no longer protected. The
+// returned func releases the lock.
+func (s *BookingService) lockResource(ctx context.Context, resourceID string) (func(), error) {
+	if s.locker == nil {
+		return func() {}, nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, resourceLockWait)
+	defer cancel()
+
+	lock, err := s.locker.AcquireLock(waitCtx, "booking:resource:"+resourceID, resourceLockTTL)
+	if err == database.ErrLockNotAcquired {
+		return nil, errors.NewConflictError("resource is busy, please retry")
+	}
+	if err != nil {
+		return nil, errors.NewInternalError("failed to lock resource", err)
+	}
+
+	return func() {
+		if err := s.locker.ReleaseLock(context.WithoutCancel(ctx), lock); err != nil {
+			s.logger.WithContext(ctx).WithError(err).Error("failed to release resource lock")
+		}
+	}, nil
+}
+
+// checkSeriesAvailability is checkAvailability for the occurrences of a
+// series, which must also fit alongside each other.
+func (s *BookingService) checkSeriesAvailability(ctx context.Context, bookings []*domain.Booking) error {
+	first, last := bookings[0], bookings[len(bookings)-1]
+
+	rules, err := s.repo.GetResourceRules(ctx, first.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.occupying(ctx, first.ResourceID,
+		first.StartTime.Add(-rules.Buffer), last.EndTime.Add(rules.Buffer), first.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	conflicts := make([]string, 0)
+	for i, booking := range bookings {
+		if !booking.FitsCapacity(slices.Concat(existing, bookings[:i]), *rules) {
+			conflicts = append(conflicts, booking.StartTime.Format(time.RFC3339))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return errors.NewConflictError("resource is not available for the occurrences starting " + strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
+
+// checkAvailability rejects the booking when adding it would exceed the
+// resource capacity, taking the resource turnaround buffer into account.
+func (s *BookingService) checkAvailability(ctx context.Context, booking *domain.Booking) error {
+	rules, err := s.repo.GetResourceRules(ctx, booking.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.occupying(ctx, booking.ResourceID,
+		booking.StartTime.Add(-rules.Buffer), booking.EndTime.Add(rules.Buffer), booking.UserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !booking.FitsCapacity(existing, *rules) {
+		return errors.NewConflictError("resource is not available for the requested time")
+	}
+
+	return nil
+}
+
+// occupying returns the bookings of a resource that overlap [start, end)
+// along with the slots held for waitlisted users other than userID, which
+// occupy capacity until their offer is taken or expires.
+func (s *BookingService) occupying(ctx context.Context, resourceID string, start, end time.Time, userID string) ([]*domain.Booking, error) {
+	existing, err := s.repo.ListOverlapping(ctx, resourceID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := s.waitlist.ListOffered(ctx, resourceID, start, end, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	for _, offer := range offers {
+		if offer.UserID != userID {
+			existing = append(existing, offer.Hold())
+		}
+	}
+
+	return existing, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StatusStream passes status updates of bookings from the replica that
+// learns about them to the replicas whose clients follow the booking.
+type StatusStream interface {
+	Publish(ctx context.Context, update *domain.StatusUpdate) error
+	Subscribe(ctx context.Context, bookingID string) (<-chan *domain.StatusUpdate, func(), error)
+}
+
+// StatusService lets clients follow a booking through the booking saga
+// instead of polling it.
+type StatusService struct {
+	repo   BookingRepository
+	stream StatusStream
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+func NewStatusService(repo BookingRepository, stream StatusStream, logger *logger.Logger, tracer trace.Tracer) *StatusService {
+	return &StatusService{
+		repo:   repo,
+		stream: stream,
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+// Publish sends update to the clients following its booking.
+func (s *StatusService) Publish(ctx context.Context, update *domain.StatusUpdate) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.publish_status", trace.WithAttributes(tracing.BookingID.String(update.BookingID)))
+	defer tracing.End(span, &err)
+
+	return s.stream.Publish(ctx, update)
+}
+
+// Follow returns booking id with the updates published after it was read.
+// The subscription starts before the booking is read, so no update falls
+// between the two; a client may see an update the booking already shows.
+// stop must be called once the caller no longer reads the updates.
+func (s *StatusService) Follow(ctx context.Context, id string) (_ *domain.Booking, _ <-chan *domain.StatusUpdate, stop func(), err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.follow_status", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	updates, stop, err := s.stream.Subscribe(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	booking, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		stop()
+		return nil, nil, nil, err
+	}
+
+	return booking, updates, stop, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestUpdateBookingWindow(t *testing.T) {
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name string
+		req  *domain.UpdateBookingRequest
+	}{
+		{
+			name: "moved into the past",
+			req:  &domain.UpdateBookingRequest{StartTime: ptr(time.Now().Add(-time.Hour)), EndTime: ptr(time.Now().Add(time.Hour))},
+		},
+		{
+			name: "start moved to now",
+			req:  &domain.UpdateBookingRequest{StartTime: ptr(time.Now())},
+		},
+		{
+			name: "end moved before start",
+			req:  &domain.UpdateBookingRequest{EndTime: ptr(start.Add(-time.Minute))},
+		},
+		{
+			name: "start moved past end",
+			req:  &domain.UpdateBookingRequest{StartTime: ptr(start.Add(2 * time.Hour))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &commentRepository{booking: &domain.Booking{
+				ID:        "b-1",
+				Status:    domain.BookingStatusPending,
+				StartTime: start,
+				EndTime:   start.Add(time.Hour),
+			}}
+			s := NewBookingService(repo, nil, nil, nil, nil, domain.CancellationPolicy{}, domain.CheckInPolicy{}, nil,
+				logger.New("test", "error"), nil, noop.NewTracerProvider().Tracer("test"))
+
+			_, err := s.UpdateBooking(context.Background(), "b-1", tt.req)
+			if err == nil || errors.GetAppError(err).Type != errors.ErrorTypeValidation {
+				t.Errorf("UpdateBooking() error = %v, want a validation error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WaitlistRepository interface {
+	Create(ctx context.Context, entry *domain.WaitlistEntry) error
+	GetByID(ctx context.Context, id string) (*domain.WaitlistEntry, error)
+	ListByUser(ctx context.Context, userID string) ([]*domain.WaitlistEntry, error)
+	ListWaiting(ctx context.Context, resourceID string, start, end time.Time) ([]*domain.WaitlistEntry, error)
+	ListOffered(ctx context.Context, resourceID string, start, end, now time.Time) ([]*domain.WaitlistEntry, error)
+	UpdateStatus(ctx context.Context, id string, status domain.WaitlistStatus, offerExpiresAt *time.Time) error
+	ClaimOffers(ctx context.Context, userID, resourceID string, start, end time.Time) error
+	ExpireOffers(ctx context.Context, now time.Time) ([]*domain.WaitlistEntry, error)
+}
+
+var _ domain.WaitlistService = (*WaitlistService)(nil)
+
+// WaitlistService queues users for fully booked slots. When a cancellation
+// frees a slot it is offered to the waiting users in the order they joined,
+// and held for each of them for offerTTL so they can book it first.
+type WaitlistService struct {
+	bookings  *BookingService
+	repo      WaitlistRepository
+	offerTTL  time.Duration
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	tracer    trace.Tracer
+}
+
+func NewWaitlistService(
+	bookings *BookingService,
+	repo WaitlistRepository,
+	offerTTL time.Duration,
+	publisher messagebus.Publisher,
+	logger *logger.Logger,
+	tracer trace.Tracer,
+) *WaitlistService {
+	return &WaitlistService{
+		bookings:  bookings,
+		repo:      repo,
+		offerTTL:  offerTTL,
+		publisher: publisher,
+		logger:    logger,
+		tracer:    tracer,
+	}
+}
+
+// JoinWaitlist queues the user for a slot. Slots that can be booked right
+// away are rejected so nobody waits for a free slot.
+func (s *WaitlistService) JoinWaitlist(ctx context.Context, userID string, req *domain.JoinWaitlistRequest) (_ *domain.WaitlistEntry, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.join_waitlist", trace.WithAttributes(
+		tracing.UserID.String(userID),
+		tracing.ResourceID.String(req.ResourceID),
+	))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	if s.bookings.references != nil {
+		if err := s.bookings.references.ValidateResource(ctx, req.ResourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	entry := &domain.WaitlistEntry{
+		UserID:     userID,
+		ResourceID: req.ResourceID,
+		StartTime:  req.StartTime.UTC(),
+		EndTime:    req.EndTime.UTC(),
+		Status:     domain.WaitlistStatusWaiting,
+	}
+
+	err = s.bookings.checkAvailability(ctx, entry.Hold())
+	if err == nil {
+		return nil, errors.NewConflictError("the slot is available and can be booked directly")
+	}
+	if errors.GetAppError(err).Type != errors.ErrorTypeConfict {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "waitlist.join", "waitlist_entry", entry.ID, nil, entry)
+	s.logger.WithContext(ctx).With("entry_id", entry.ID).Info("joined waitlist")
+
+	return entry, nil
+}
+
+// ListWaitlist returns the entries the user is still waiting on or has
+// been offered.
+func (s *WaitlistService) ListWaitlist(ctx context.Context, userID string) (_ []*domain.WaitlistEntry, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.list_waitlist", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// LeaveWaitlist removes the user's entry. A slot they were offered passes
+// on to the next user in line.
+func (s *WaitlistService) LeaveWaitlist(ctx context.Context, userID, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.leave_waitlist", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	entry, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Other users' entries are not disclosed
+	if entry.UserID != userID {
+		return errors.NewNotFoundError("waitlist entry")
+	}
+	if !entry.IsActive() {
+		return errors.NewConflictError("waitlist entry is no longer active")
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, domain.WaitlistStatusLeft, nil); err != nil {
+		return err
+	}
+	audit.Log(ctx, "waitlist.leave", "waitlist_entry", id, entry, nil)
+
+	s.logger.WithContext(ctx).With("entry_id", id).Info("left waitlist")
+
+	if entry.Status == domain.WaitlistStatusOffered {
+		return s.offerSlot(ctx, entry.ResourceID, entry.StartTime, entry.EndTime)
+	}
+	return nil
+}
+
+// OfferFreedSlot offers the slot of a cancelled booking to the waitlist.
+func (s *WaitlistService) OfferFreedSlot(ctx context.Context, bookingID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.offer_freed_slot", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	booking, err := s.bookings.repo.GetByID(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+
+	return s.offerSlot(ctx, booking.ResourceID, booking.StartTime, booking.EndTime)
+}
+
+// ExpireOffers ends the offers nobody took in time and offers their slots
+// to the next users in line. It returns the number of expired offers.
+func (s *WaitlistService) ExpireOffers(ctx context.Context) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "booking.service.expire_waitlist_offers")
+	defer tracing.End(span, &err)
+
+	expired, err := s.repo.ExpireOffers(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range expired {
+		if err := s.offerSlot(ctx, entry.ResourceID, entry.StartTime, entry.EndTime); err != nil {
+			return len(expired), err
+		}
+	}
+
+	return len(expired), nil
+}
+
+// offerSlot offers a freed slot of a resource to the waiting entries that
+// overlap it, in the order they joined, as long as their slot fits. Each
+// offer holds the slot for its user until it expires.
+func (s *WaitlistService) offerSlot(ctx context.Context, resourceID string, start, end time.Time) error {
+	unlock, err := s.bookings.lockResource(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	rules, err := s.bookings.repo.GetResourceRules(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	// Entries ending within the turnaround buffer were blocked by the slot too
+	waiting, err := s.repo.ListWaiting(ctx, resourceID, start.Add(-rules.Buffer), end.Add(rules.Buffer))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range waiting {
+		err := s.bookings.checkAvailability(ctx, entry.Hold())
+		if err != nil && errors.GetAppError(err).Type == errors.ErrorTypeConfict {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().UTC().Add(s.offerTTL)
+		if err := s.repo.UpdateStatus(ctx, entry.ID, domain.WaitlistStatusOffered, &expiresAt); err != nil {
+			return err
+		}
+
+		event := events.WaitlistOfferedEvent{
+			BaseEvent: events.NewBaseEvent(events.WaitlistOffered, "booking-service", trace.SpanFromContext(ctx).SpanContext().TraceID().String(), tenancy.ID(ctx)),
+			Data: events.WaitlistOfferedData{
+				EntryID:    entry.ID,
+				UserID:     entry.UserID,
+				ResourceID: entry.ResourceID,
+				StartTime:  entry.StartTime,
+				EndTime:    entry.EndTime,
+				ExpiresAt:  expiresAt,
+			},
+		}
+
+		if err := s.publisher.Publish(ctx, events.WaitlistOffered, entry.ID, event); err != nil {
+			s.logger.WithContext(ctx).WithError(err).Error("failed to publish waitlist offered event")
+		}
+
+		s.logger.WithContext(ctx).With("entry_id", entry.ID).Info("offered slot to waitlist")
+	}
+
+	return nil
+}
+
+// OfferExpiryJob returns the scheduled job that passes expired waitlist
+// offers on to the next users in line, running every interval.
+func OfferExpiryJob(s *WaitlistService, logger *logger.Logger, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "waitlist_offer_expiry",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			expired, err := s.ExpireOffers(ctx)
+			if expired > 0 {
+				logger.WithContext(ctx).With("expired", strconv.Itoa(expired)).Info("expired waitlist offers")
+			}
+			return err
+		},
+	}
+}
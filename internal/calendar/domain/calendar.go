@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ProviderGoogle is Google Calendar, the only calendar bookings are synced
+// to so far.
+const ProviderGoogle = "google"
+
+// DefaultCalendarID is the main calendar of a Google account.
+const DefaultCalendarID = "primary"
+
+// Connection links a user to the calendar their confirmed bookings are
+// pushed into. The OAuth tokens the user granted are never returned. A
+// connection whose authorization was revoked is disabled, with the reason
+// in LastError, until the user connects again.
+type Connection struct {
+	ID             string     `json:"id" db:"id"`
+	UserID         string     `json:"user_id" db:"user_id"`
+	Provider       string     `json:"provider" db:"provider"`
+	CalendarID     string     `json:"calendar_id" db:"calendar_id"`
+	AccessToken    string     `json:"-" db:"access_token"`
+	RefreshToken   string     `json:"-" db:"refresh_token"`
+	TokenExpiresAt time.Time  `json:"-" db:"token_expires_at"`
+	Enabled        bool       `json:"enabled" db:"enabled"`
+	LastError      string     `json:"last_error,omitempty" db:"last_error"`
+	LastSyncedAt   *time.Time `json:"last_synced_at,omitempty" db:"last_synced_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ConnectRequest completes the OAuth authorization of the user: Code is
+// the authorization code Google redirected to RedirectURI with.
+type ConnectRequest struct {
+	Code        string `json:"code" validate:"required"`
+	RedirectURI string `json:"redirect_uri" validate:"required,url"`
+	CalendarID  string `json:"calendar_id,omitempty" validate:"omitempty,max=255"`
+}
+
+// UpdateConnectionRequest changes the given fields of a connection.
+// Enabling it again clears the last error.
+type UpdateConnectionRequest struct {
+	Enabled    *bool   `json:"enabled,omitempty"`
+	CalendarID *string `json:"calendar_id,omitempty" validate:"omitempty,min=1,max=255"`
+}
+
+// Token is the OAuth token of a connection. RefreshToken is empty when
+// refreshing did not rotate it.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Event is a booking as it is shown in a calendar. An empty Summary keeps
+// the one the event has.
+type Event struct {
+	BookingID   string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// EventID returns the ID of the calendar event of a booking. It is derived
+// from the booking ID, so pushing a booking again updates its event, and
+// only uses the characters Google accepts in event IDs.
+func EventID(bookingID string) string {
+	return "bk" + strings.ReplaceAll(strings.ToLower(bookingID), "-", "")
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// CalendarService is the application API of the calendar module. The HTTP
+// handler depends on it and service.CalendarService implements it.
+type CalendarService interface {
+	Connect(ctx context.Context, userID string, req *ConnectRequest) (*Connection, error)
+	GetConnection(ctx context.Context, userID string) (*Connection, error)
+	UpdateConnection(ctx context.Context, userID string, req *UpdateConnectionRequest) (*Connection, error)
+	Disconnect(ctx context.Context, userID string) error
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/calendar/domain"
+	"github.com/dmehra2102/booking-system/internal/calendar/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler keeps the calendar events of bookings in step with the
+// booking events consumed from Kafka.
+type EventHandler struct {
+	service *service.CalendarService
+}
+
+func NewEventHandler(service *service.CalendarService) *EventHandler {
+	return &EventHandler{service: service}
+}
+
+func (h *EventHandler) HandleBookingConfirmed(ctx context.Context, event events.BookingConfirmedEvent) error {
+	summary := ""
+	if event.Data.ResourceName != "" {
+		summary = "Booking: " + event.Data.ResourceName
+	}
+
+	return h.service.SyncBooking(ctx, event.Data.UserID, &domain.Event{
+		BookingID: event.Data.BookingID,
+		Summary:   summary,
+		Start:     event.Data.StartTime,
+		End:       event.Data.EndTime,
+	})
+}
+
+// HandleBookingUpdated moves the event of a confirmed booking. Updates of
+// bookings that are not confirmed yet have no event to move.
+func (h *EventHandler) HandleBookingUpdated(ctx context.Context, event events.BookingUpdatedEvent) error {
+	if event.Data.Status != "confirmed" {
+		return nil
+	}
+
+	return h.service.SyncBooking(ctx, event.Data.UserID, &domain.Event{
+		BookingID: event.Data.BookingID,
+		Start:     event.Data.StartTime,
+		End:       event.Data.EndTime,
+	})
+}
+
+func (h *EventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	return h.service.RemoveBooking(ctx, event.Data.UserID, event.Data.BookingID)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/calendar/domain"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler serves the calendar connection of the authenticated
+// user.
+type CalendarHandler struct {
+	service domain.CalendarService
+	logger  *logger.Logger
+}
+
+func NewCalendarHandler(service domain.CalendarService, logger *logger.Logger) *CalendarHandler {
+	return &CalendarHandler{service: service, logger: logger}
+}
+
+// Connect completes the OAuth authorization the client started and
+// returns the connection, replacing the one the user had.
+func (h *CalendarHandler) Connect(c *gin.Context) {
+	var req domain.ConnectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	connection, err := h.service.Connect(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, connection)
+}
+
+func (h *CalendarHandler) GetConnection(c *gin.Context) {
+	connection, err := h.service.GetConnection(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, connection)
+}
+
+func (h *CalendarHandler) UpdateConnection(c *gin.Context) {
+	var req domain.UpdateConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	connection, err := h.service.UpdateConnection(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, connection)
+}
+
+func (h *CalendarHandler) Disconnect(c *gin.Context) {
+	if err := h.service.Disconnect(c.Request.Context(), c.GetString("user_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
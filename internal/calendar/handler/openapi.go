@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/calendar/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the calendar HTTP API for the OpenAPI document. The
+// routes are served by the booking service when calendar sync is
+// configured; keep them in sync with setupRouter in cmd/booking.
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPut, Path: "/api/v1/calendar", Summary: "Connect your Google Calendar", Tag: "calendar", Auth: true,
+			Request: domain.ConnectRequest{}, Response: domain.Connection{}},
+		{Method: http.MethodGet, Path: "/api/v1/calendar", Summary: "Get your calendar connection", Tag: "calendar", Auth: true,
+			Response: domain.Connection{}},
+		{Method: http.MethodPatch, Path: "/api/v1/calendar", Summary: "Enable, disable or retarget your calendar sync", Tag: "calendar", Auth: true,
+			Request: domain.UpdateConnectionRequest{}, Response: domain.Connection{}},
+		{Method: http.MethodDelete, Path: "/api/v1/calendar", Summary: "Disconnect your calendar", Tag: "calendar", Auth: true,
+			Status: http.StatusNoContent},
+	}
+}
@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/calendar/domain"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+)
+
+const (
+	googleTokenURL    = "https://oauth2.googleapis.com/token"
+	googleCalendarURL = "https://www.googleapis.com/calendar/v3"
+)
+
+var (
+	// ErrUnauthorized is returned when the access token was rejected.
+	ErrUnauthorized = errors.New("calendar access token was rejected")
+	// ErrRevoked is returned when the refresh token is no longer valid,
+	// because the user revoked the access or it expired.
+	ErrRevoked = errors.New("calendar authorization was revoked")
+)
+
+// GoogleCalendar exchanges and refreshes the OAuth tokens of users with the
+// client ID and secret of the deployment, and writes the events of
+// bookings through the Google Calendar API.
+type GoogleCalendar struct {
+	client       *httpclient.Client
+	clientID     string
+	clientSecret string
+}
+
+func NewGoogleCalendar(clientID, clientSecret string, client *httpclient.Client) *GoogleCalendar {
+	return &GoogleCalendar{client: client, clientID: clientID, clientSecret: clientSecret}
+}
+
+type googleToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type googleTokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Exchange trades the authorization code of a user for their tokens.
+func (g *GoogleCalendar) Exchange(ctx context.Context, code, redirectURI string) (*domain.Token, error) {
+	return g.token(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURI},
+	})
+}
+
+// Refresh obtains a new access token with refreshToken.
+func (g *GoogleCalendar) Refresh(ctx context.Context, refreshToken string) (*domain.Token, error) {
+	return g.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (g *GoogleCalendar) token(ctx context.Context, form url.Values) (*domain.Token, error) {
+	form.Set("client_id", g.clientID)
+	form.Set("client_secret", g.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Google token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain Google access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var body googleTokenError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+		if body.Error == "invalid_grant" {
+			return nil, ErrRevoked
+		}
+		return nil, fmt.Errorf("Google token endpoint responded %d %s: %s", resp.StatusCode, body.Error, body.Description)
+	}
+
+	var token googleToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode Google access token: %w", err)
+	}
+
+	return &domain.Token{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
+
+type googleEvent struct {
+	ID          string          `json:"id,omitempty"`
+	Summary     string          `json:"summary,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Start       googleEventTime `json:"start"`
+	End         googleEventTime `json:"end"`
+	Status      string          `json:"status"`
+}
+
+type googleEventTime struct {
+	DateTime time.Time `json:"dateTime"`
+}
+
+// PutEvent creates the event of a booking in calendarID, or updates it
+// when it exists, including when it was deleted before.
+func (g *GoogleCalendar) PutEvent(ctx context.Context, accessToken, calendarID string, event *domain.Event) error {
+	body := googleEvent{
+		ID:          domain.EventID(event.BookingID),
+		Summary:     event.Summary,
+		Description: event.Description,
+		Start:       googleEventTime{DateTime: event.Start.UTC()},
+		End:         googleEventTime{DateTime: event.End.UTC()},
+		Status:      "confirmed",
+	}
+
+	create := body
+	if create.Summary == "" {
+		create.Summary = "Booking"
+	}
+	status, err := g.do(ctx, accessToken, http.MethodPost, eventsURL(calendarID, ""), create)
+	if err != nil || status != http.StatusConflict {
+		return err
+	}
+
+	// Event IDs stay taken after deletion, so a conflict is a booking that
+	// was pushed before
+	body.ID = ""
+	_, err = g.do(ctx, accessToken, http.MethodPatch, eventsURL(calendarID, domain.EventID(event.BookingID)), body)
+	return err
+}
+
+// DeleteEvent deletes the event of a booking from calendarID. Events that
+// are already gone are not an error.
+func (g *GoogleCalendar) DeleteEvent(ctx context.Context, accessToken, calendarID, bookingID string) error {
+	status, err := g.do(ctx, accessToken, http.MethodDelete, eventsURL(calendarID, domain.EventID(bookingID)), nil)
+	if status == http.StatusNotFound || status == http.StatusGone {
+		return nil
+	}
+	return err
+}
+
+// do sends a request to the Calendar API. It returns the status along
+// with nil for 2xx responses and for 409, which callers handle.
+func (g *GoogleCalendar) do(ctx context.Context, accessToken, method, endpoint string, payload any) (int, error) {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode calendar event: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build calendar request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call Google Calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode == http.StatusConflict {
+		return resp.StatusCode, nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return resp.StatusCode, ErrUnauthorized
+	}
+
+	var failure struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+	return resp.StatusCode, fmt.Errorf("Google Calendar responded %d: %s", resp.StatusCode, failure.Error.Message)
+}
+
+func eventsURL(calendarID, eventID string) string {
+	endpoint := googleCalendarURL + "/calendars/" + url.PathEscape(calendarID) + "/events"
+	if eventID != "" {
+		endpoint += "/" + url.PathEscape(eventID)
+	}
+	return endpoint
+}
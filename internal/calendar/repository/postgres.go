@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/calendar/domain"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresCalendarRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresCalendarRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresCalendarRepository {
+	return &PostgresCalendarRepository{db: db, tracer: tracer}
+}
+
+// Connect saves the connection of a user, replacing the calendar and tokens
+// of the one they had.
+func (r *PostgresCalendarRepository) Connect(ctx context.Context, c *domain.Connection) (err error) {
+	ctx, span := r.tracer.Start(ctx, "calendar.repository.connect")
+	defer tracing.End(span, &err)
+
+	c.ID = uuid.New().String()
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+
+	query := `
+		INSERT INTO calendar_connections (
+			id, tenant_id, user_id, provider, calendar_id, access_token, refresh_token,
+			token_expires_at, enabled, last_error, last_synced_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET
+			provider = EXCLUDED.provider, calendar_id = EXCLUDED.calendar_id,
+			access_token = EXCLUDED.access_token, refresh_token = EXCLUDED.refresh_token,
+			token_expires_at = EXCLUDED.token_expires_at, enabled = EXCLUDED.enabled,
+			last_error = EXCLUDED.last_error, updated_at = EXCLUDED.updated_at
+		RETURNING id, last_synced_at, created_at
+	`
+
+	var lastSyncedAt sql.NullTime
+	err = r.db.QueryRow(ctx, "calendar.connect", query,
+		c.ID, tenancy.ID(ctx), c.UserID, c.Provider, c.CalendarID, c.AccessToken, c.RefreshToken,
+		c.TokenExpiresAt, c.Enabled, c.LastError, c.LastSyncedAt, c.CreatedAt, c.UpdatedAt,
+	).Scan(&c.ID, &lastSyncedAt, &c.CreatedAt)
+	if lastSyncedAt.Valid {
+		c.LastSyncedAt = &lastSyncedAt.Time
+	}
+	if err != nil {
+		return errors.NewInternalError("failed to save calendar connection", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresCalendarRepository) GetByUser(ctx context.Context, userID string) (_ *domain.Connection, err error) {
+	ctx, span := r.tracer.Start(ctx, "calendar.repository.get_by_user")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, provider, calendar_id, access_token, refresh_token, token_expires_at,
+			enabled, last_error, last_synced_at, created_at, updated_at
+		FROM calendar_connections
+		WHERE user_id = $1 AND tenant_id = $2
+	`
+
+	c := &domain.Connection{}
+	var lastSyncedAt sql.NullTime
+	err = r.db.QueryRow(ctx, "calendar.get_by_user", query, userID, tenancy.ID(ctx)).Scan(
+		&c.ID, &c.UserID, &c.Provider, &c.CalendarID, &c.AccessToken, &c.RefreshToken, &c.TokenExpiresAt,
+		&c.Enabled, &c.LastError, &lastSyncedAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("calendar connection")
+		}
+		return nil, errors.NewInternalError("failed to get calendar connection", err)
+	}
+	if lastSyncedAt.Valid {
+		c.LastSyncedAt = &lastSyncedAt.Time
+	}
+
+	return c, nil
+}
+
+// Update saves the calendar, tokens and sync state of a connection.
+func (r *PostgresCalendarRepository) Update(ctx context.Context, c *domain.Connection) (err error) {
+	ctx, span := r.tracer.Start(ctx, "calendar.repository.update")
+	defer tracing.End(span, &err)
+
+	c.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE calendar_connections
+		SET calendar_id = $2, access_token = $3, refresh_token = $4, token_expires_at = $5,
+			enabled = $6, last_error = $7, last_synced_at = $8, updated_at = $9
+		WHERE id = $1 AND tenant_id = $10
+	`
+
+	result, err := r.db.Exec(ctx, "calendar.update", query,
+		c.ID, c.CalendarID, c.AccessToken, c.RefreshToken, c.TokenExpiresAt,
+		c.Enabled, c.LastError, c.LastSyncedAt, c.UpdatedAt, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to update calendar connection", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("calendar connection")
+	}
+
+	return nil
+}
+
+func (r *PostgresCalendarRepository) Delete(ctx context.Context, userID string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "calendar.repository.delete")
+	defer tracing.End(span, &err)
+
+	result, err := r.db.Exec(ctx, "calendar.delete", `DELETE FROM calendar_connections WHERE user_id = $1 AND tenant_id = $2`, userID, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to delete calendar connection", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("calendar connection")
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/calendar/domain"
+	"github.com/dmehra2102/booking-system/internal/calendar/provider"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type CalendarRepository interface {
+	Connect(ctx context.Context, c *domain.Connection) error
+	GetByUser(ctx context.Context, userID string) (*domain.Connection, error)
+	Update(ctx context.Context, c *domain.Connection) error
+	Delete(ctx context.Context, userID string) error
+}
+
+// Provider is the calendar bookings are pushed into, as
+// provider.GoogleCalendar is.
+type Provider interface {
+	Exchange(ctx context.Context, code, redirectURI string) (*domain.Token, error)
+	Refresh(ctx context.Context, refreshToken string) (*domain.Token, error)
+	PutEvent(ctx context.Context, accessToken, calendarID string, event *domain.Event) error
+	DeleteEvent(ctx context.Context, accessToken, calendarID, bookingID string) error
+}
+
+// refreshMargin is how long before it expires an access token is
+// refreshed, so it does not expire while in use.
+const refreshMargin = time.Minute
+
+var _ domain.CalendarService = (*CalendarService)(nil)
+
+// CalendarService manages the calendar connections of users and keeps the
+// events of their confirmed bookings in sync.
+type CalendarService struct {
+	repo     CalendarRepository
+	provider Provider
+	logger   *logger.Logger
+	tracer   trace.Tracer
+}
+
+func NewCalendarService(repo CalendarRepository, provider Provider, logger *logger.Logger, tracer trace.Tracer) *CalendarService {
+	return &CalendarService{
+		repo:     repo,
+		provider: provider,
+		logger:   logger,
+		tracer:   tracer,
+	}
+}
+
+// Connect completes the authorization of a user and enables the sync of
+// their bookings, replacing the connection they had. Bookings confirmed
+// before are not pushed.
+func (s *CalendarService) Connect(ctx context.Context, userID string, req *domain.ConnectRequest) (_ *domain.Connection, err error) {
+	ctx, span := s.tracer.Start(ctx, "calendar.service.connect")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	token, err := s.provider.Exchange(ctx, req.Code, req.RedirectURI)
+	if err != nil {
+		if stderrors.Is(err, provider.ErrRevoked) {
+			return nil, errors.NewValidationError("authorization code is invalid or expired", nil)
+		}
+		return nil, errors.NewExternalError(domain.ProviderGoogle, "failed to complete calendar authorization", err)
+	}
+	// Without a refresh token the sync would stop when the first access
+	// token expires
+	if token.RefreshToken == "" {
+		return nil, errors.NewValidationError("authorization must grant offline access", nil)
+	}
+
+	calendarID := req.CalendarID
+	if calendarID == "" {
+		calendarID = domain.DefaultCalendarID
+	}
+
+	connection := &domain.Connection{
+		UserID:         userID,
+		Provider:       domain.ProviderGoogle,
+		CalendarID:     calendarID,
+		AccessToken:    token.AccessToken,
+		RefreshToken:   token.RefreshToken,
+		TokenExpiresAt: token.ExpiresAt.UTC(),
+		Enabled:        true,
+	}
+
+	if err := s.repo.Connect(ctx, connection); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "calendar_connection.connect", "calendar_connection", connection.ID, nil, connection)
+	s.logger.WithContext(ctx).With("user_id", userID).Info("calendar connected")
+
+	return connection, nil
+}
+
+func (s *CalendarService) GetConnection(ctx context.Context, userID string) (_ *domain.Connection, err error) {
+	ctx, span := s.tracer.Start(ctx, "calendar.service.get_connection")
+	defer tracing.End(span, &err)
+
+	return s.repo.GetByUser(ctx, userID)
+}
+
+// UpdateConnection changes the given fields of the connection of a user.
+// Events already pushed stay in the calendar they were pushed to.
+func (s *CalendarService) UpdateConnection(ctx context.Context, userID string, req *domain.UpdateConnectionRequest) (_ *domain.Connection, err error) {
+	ctx, span := s.tracer.Start(ctx, "calendar.service.update_connection")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	connection, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := *connection
+
+	if req.CalendarID != nil {
+		connection.CalendarID = *req.CalendarID
+	}
+	if req.Enabled != nil {
+		if *req.Enabled && !connection.Enabled {
+			connection.LastError = ""
+		}
+		connection.Enabled = *req.Enabled
+	}
+
+	if err := s.repo.Update(ctx, connection); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "calendar_connection.update", "calendar_connection", connection.ID, &before, connection)
+	s.logger.WithContext(ctx).With("user_id", userID).Info("calendar connection updated")
+
+	return connection, nil
+}
+
+// Disconnect removes the connection of a user. The events pushed before
+// stay in their calendar.
+func (s *CalendarService) Disconnect(ctx context.Context, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "calendar.service.disconnect")
+	defer tracing.End(span, &err)
+
+	connection, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "calendar_connection.disconnect", "calendar_connection", connection.ID, connection, nil)
+	s.logger.WithContext(ctx).With("user_id", userID).Info("calendar disconnected")
+
+	return nil
+}
+
+// SyncBooking creates or updates the event of a booking in the calendar of
+// its user. Users without an enabled connection are skipped.
+func (s *CalendarService) SyncBooking(ctx context.Context, userID string, event *domain.Event) (err error) {
+	ctx, span := s.tracer.Start(ctx, "calendar.service.sync_booking")
+	defer tracing.End(span, &err)
+
+	return s.sync(ctx, userID, event.BookingID, func(accessToken, calendarID string) error {
+		return s.provider.PutEvent(ctx, accessToken, calendarID, event)
+	})
+}
+
+// RemoveBooking deletes the event of a booking from the calendar of its
+// user. Users without an enabled connection are skipped.
+func (s *CalendarService) RemoveBooking(ctx context.Context, userID, bookingID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "calendar.service.remove_booking")
+	defer tracing.End(span, &err)
+
+	return s.sync(ctx, userID, bookingID, func(accessToken, calendarID string) error {
+		return s.provider.DeleteEvent(ctx, accessToken, calendarID, bookingID)
+	})
+}
+
+// sync runs call with a valid access token of the connection of userID and
+// records the outcome. The token is refreshed when it is about to expire
+// and once more when the provider rejects it. A connection whose
+// authorization was revoked is disabled; other failures are returned so
+// the event is retried.
+func (s *CalendarService) sync(ctx context.Context, userID, bookingID string, call func(accessToken, calendarID string) error) error {
+	connection, err := s.repo.GetByUser(ctx, userID)
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			return nil
+		}
+		return err
+	}
+	if !connection.Enabled {
+		return nil
+	}
+
+	log := s.logger.WithContext(ctx).With("user_id", userID).With("booking_id", bookingID)
+
+	err = s.refresh(ctx, connection, false)
+	if err == nil {
+		err = call(connection.AccessToken, connection.CalendarID)
+		if stderrors.Is(err, provider.ErrUnauthorized) {
+			if err = s.refresh(ctx, connection, true); err == nil {
+				err = call(connection.AccessToken, connection.CalendarID)
+			}
+		}
+	}
+
+	switch {
+	case err == nil:
+		now := time.Now().UTC()
+		connection.LastSyncedAt = &now
+		connection.LastError = ""
+	case stderrors.Is(err, provider.ErrRevoked):
+		connection.Enabled = false
+		connection.LastError = err.Error()
+		log.Warn("calendar authorization was revoked, disabled the connection")
+	default:
+		connection.LastError = err.Error()
+	}
+
+	if updateErr := s.repo.Update(ctx, connection); updateErr != nil {
+		log.WithError(updateErr).Error("failed to record calendar sync")
+	}
+
+	if err != nil && !stderrors.Is(err, provider.ErrRevoked) {
+		return errors.NewExternalError(domain.ProviderGoogle, "failed to sync calendar event", err)
+	}
+	return nil
+}
+
+// refresh renews the access token of connection when it expires within
+// refreshMargin, or when force is set.
+func (s *CalendarService) refresh(ctx context.Context, connection *domain.Connection, force bool) error {
+	if !force && time.Until(connection.TokenExpiresAt) > refreshMargin {
+		return nil
+	}
+
+	token, err := s.provider.Refresh(ctx, connection.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	connection.AccessToken = token.AccessToken
+	connection.TokenExpiresAt = token.ExpiresAt.UTC()
+	if token.RefreshToken != "" {
+		connection.RefreshToken = token.RefreshToken
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Entry records a single action taken by an actor on a resource.
+type Entry struct {
+	ID           string            `json:"id"`
+	TenantID     string            `json:"tenant_id"`
+	Service      string            `json:"service"`
+	ActorID      string            `json:"actor_id,omitempty"`
+	ActorRole    string            `json:"actor_role,omitempty"`
+	Action       string            `json:"action"`
+	ResourceType string            `json:"resource_type,omitempty"`
+	ResourceID   string            `json:"resource_id,omitempty"`
+	Changes      map[string]Change `json:"changes,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+	TraceID      string            `json:"trace_id,omitempty"`
+	IPAddress    string            `json:"ip_address,omitempty"`
+	Method       string            `json:"method,omitempty"`
+	Path         string            `json:"path,omitempty"`
+	StatusCode   int               `json:"status_code,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// Change is the before and after value of a single field.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Actor identifies who performed the audited request.
+type Actor struct {
+	ID   string
+	Role string
+}
+
+type contextKey struct{}
+
+// Scope collects the entries recorded while handling one request. The
+// actor is resolved lazily because authentication runs after the scope is
+// created.
+type Scope struct {
+	mu      sync.Mutex
+	actor   func() Actor
+	entries []*Entry
+}
+
+func NewContext(ctx context.Context, actor func() Actor) (context.Context, *Scope) {
+	s := &Scope{actor: actor}
+	return context.WithValue(ctx, contextKey{}, s), s
+}
+
+func FromContext(ctx context.Context) *Scope {
+	s, _ := ctx.Value(contextKey{}).(*Scope)
+	return s
+}
+
+// Log records that action was applied to the resource identified by
+// resourceType and resourceID. before and after are snapshots of the
+// resource; either may be nil for creations and deletions. Only fields
+// whose JSON value differs are kept. It is a no-op when ctx carries no
+// audit scope.
+func Log(ctx context.Context, action, resourceType, resourceID string, before, after any) {
+	s := FromContext(ctx)
+	if s == nil {
+		return
+	}
+
+	actor := s.actor()
+	entry := &Entry{
+		TenantID:     tenancy.ID(ctx),
+		ActorID:      actor.ID,
+		ActorRole:    actor.Role,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      Diff(before, after),
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	if span := trace.SpanFromContext(ctx).SpanContext(); span.IsValid() {
+		entry.TraceID = span.TraceID().String()
+	}
+
+	s.mu.Lock()
+	s.entries = append(s.entries, entry)
+	s.mu.Unlock()
+}
+
+// Entries returns the entries recorded in the scope so far.
+func (s *Scope) Entries() []*Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Entry(nil), s.entries...)
+}
+
+// Actor returns the actor of the request.
+func (s *Scope) Actor() Actor {
+	return s.actor()
+}
+
+// Diff compares the top-level JSON fields of before and after.
+func Diff(before, after any) map[string]Change {
+	from, to := toFields(before), toFields(after)
+
+	changes := make(map[string]Change)
+	for field, value := range from {
+		if other, ok := to[field]; !ok || !reflect.DeepEqual(value, other) {
+			changes[field] = Change{From: value, To: to[field]}
+		}
+	}
+	for field, value := range to {
+		if _, ok := from[field]; !ok {
+			changes[field] = Change{To: value}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func toFields(v any) map[string]any {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
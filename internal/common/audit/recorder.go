@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/google/uuid"
+)
+
+const (
+	recorderBufferSize = 1024
+	recorderBatchSize  = 100
+	recorderFlushEvery = time.Second
+)
+
+// Recorder writes audit entries to the audit_log table from a background
+// worker so that requests never wait on audit inserts. Entries are dropped
+// with a warning when the buffer is full.
+type Recorder struct {
+	db      *database.PostgresDB
+	logger  *logger.Logger
+	service string
+	entries chan *Entry
+	wg      sync.WaitGroup
+}
+
+func NewRecorder(db *database.PostgresDB, logger *logger.Logger, service string) *Recorder {
+	r := &Recorder{
+		db:      db,
+		logger:  logger,
+		service: service,
+		entries: make(chan *Entry, recorderBufferSize),
+	}
+
+	r.wg.Add(1)
+	go r.run()
+
+	return r
+}
+
+// Record queues entry for writing.
+func (r *Recorder) Record(entry *Entry) {
+	entry.ID = uuid.New().String()
+	entry.Service = r.service
+	if entry.TenantID == "" {
+		entry.TenantID = tenancy.DefaultID
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+
+	select {
+	case r.entries <- entry:
+	default:
+		r.logger.With("action", entry.Action).Warn("audit buffer full, dropping entry")
+	}
+}
+
+// Close stops accepting entries and waits for queued ones to be written.
+func (r *Recorder) Close() {
+	close(r.entries)
+	r.wg.Wait()
+}
+
+func (r *Recorder) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(recorderFlushEvery)
+	defer ticker.Stop()
+
+	batch := make([]*Entry, 0, recorderBatchSize)
+	for {
+		select {
+		case entry, ok := <-r.entries:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+
+			batch = append(batch, entry)
+			if len(batch) >= recorderBatchSize {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			r.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (r *Recorder) flush(batch []*Entry) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO audit_log (
+			id, tenant_id, service, actor_id, actor_role, action, resource_type, resource_id,
+			changes, request_id, trace_id, ip_address, method, path, status_code, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	for _, entry := range batch {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			r.logger.WithError(err).With("action", entry.Action).Error("failed to encode audit changes")
+			continue
+		}
+
+		_, err = r.db.Exec(ctx, "audit.insert", query,
+			entry.ID, entry.TenantID, entry.Service, entry.ActorID, entry.ActorRole, entry.Action,
+			entry.ResourceType, entry.ResourceID, changes, entry.RequestID, entry.TraceID,
+			entry.IPAddress, entry.Method, entry.Path, entry.StatusCode, entry.CreatedAt,
+		)
+		if err != nil {
+			r.logger.WithError(err).With("action", entry.Action).Error("failed to write audit entry")
+		}
+	}
+}
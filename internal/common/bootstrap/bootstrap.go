@@ -0,0 +1,4 @@
+// Package bootstrap sets up the infrastructure every service starts with
+// from its configuration, such as the message bus and the metrics server,
+// so the mains only wire up their own components.
+package bootstrap
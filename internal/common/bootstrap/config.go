@@ -0,0 +1,25 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/lifecycle"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+)
+
+// WatchConfig starts reloading the configuration and applies a new log
+// level right away; components subscribe to their own settings.
+func WatchConfig(cfg *config.Config, log *logger.Logger, lc *lifecycle.Manager) *config.Watcher {
+	watcher := config.NewWatcher(cfg, log)
+	watcher.OnChange(func(cfg *config.Config) {
+		log.SetLevel(cfg.LogLevel)
+	})
+
+	stopWatching := watcher.Watch(cfg.ConfigWatchInterval)
+	lc.OnStop("config watcher", func(context.Context) error {
+		stopWatching()
+		return nil
+	})
+	return watcher
+}
@@ -0,0 +1,115 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/kafka"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/nats"
+	"github.com/dmehra2102/booking-system/internal/common/rabbitmq"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/schemaregistry"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Codec returns the codec of events encoded with a schema registry, or nil
+// when none is configured and every topic carries JSON.
+func Codec(cfg *config.Config, log *logger.Logger, m *metrics.Metrics) (events.Codec, error) {
+	if cfg.SchemaRegistryURL == "" {
+		return nil, nil
+	}
+
+	client, err := schemaregistry.New(cfg.SchemaRegistryURL, httpclient.New("schema_registry", cfg.HTTPClientConfig(), log, m))
+	if err != nil {
+		return nil, err
+	}
+	return events.NewAvroCodec(client, events.DefaultRegistry), nil
+}
+
+// EnsureKafkaTopics creates the topics of every event type and their dead
+// letter topics, or only checks that they exist. Kafka is optional at
+// startup, so a failure is only logged.
+func EnsureKafkaTopics(cfg *config.Config, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	topics := cfg.KafkaTopics().All(events.DefaultRegistry.EventTypes())
+	if err := kafka.EnsureTopics(ctx, cfg.KafkaBrokers, topics, cfg.KafkaCreateTopics, log); err != nil {
+		log.WithError(err).Error("failed to ensure kafka topics")
+	}
+}
+
+// MessageBus connects to the broker MESSAGE_BUS selects. On Kafka the
+// topics are ensured first and events are published asynchronously when
+// KAFKA_PRODUCER_QUEUE_SIZE is set. NATS and RabbitMQ have to be
+// reachable at startup.
+func MessageBus(cfg *config.Config, log *logger.Logger, m *metrics.Metrics, tracer trace.Tracer) (messagebus.MessageBus, error) {
+	switch cfg.MessageBus {
+	case messagebus.NATS:
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		bus, err := nats.New(ctx, nats.Config{
+			URL:       cfg.NATSURL,
+			Stream:    cfg.NATSStream,
+			Retention: cfg.NATSStreamRetention,
+			Format:    cfg.EventFormat(),
+			Retry:     cfg.RetryPolicy(),
+		}, log, m, tracer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+		}
+		return bus, nil
+	case messagebus.RabbitMQ:
+		bus, err := rabbitmq.New(rabbitmq.Config{
+			URL:    cfg.RabbitMQURL,
+			Format: cfg.EventFormat(),
+			Retry:  cfg.RetryPolicy(),
+		}, log, m, tracer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		}
+		return bus, nil
+	}
+
+	codec, err := Codec(cfg, log, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure schema registry: %w", err)
+	}
+
+	EnsureKafkaTopics(cfg, log)
+	producer := kafka.NewProducer(cfg.KafkaBrokers, cfg.KafkaTopics(), cfg.EventFormat(), codec, cfg.RetryPolicy(), cfg.BreakerConfig(), log, m, tracer)
+	if cfg.KafkaProducerQueueSize > 0 {
+		producer.StartAsync(cfg.KafkaProducerQueueSize)
+	}
+	return kafka.NewBus(producer, kafka.BusConfig{
+		Brokers:     cfg.KafkaBrokers,
+		Topics:      cfg.KafkaTopics(),
+		LagInterval: cfg.KafkaConsumerLagInterval,
+		Retry:       cfg.RetryPolicy(),
+	}, log, m, tracer), nil
+}
+
+// ProcessedStore returns the store consumers remember handled events in,
+// or nil when they handle every delivery. Services without Redis pass a
+// nil redisClient and keep them in Postgres.
+func ProcessedStore(cfg *config.Config, log *logger.Logger, db *database.PostgresDB, redisClient *database.RedisClient, jobs *scheduler.Scheduler) messagebus.ProcessedStore {
+	switch {
+	case cfg.KafkaProcessedStore == "none":
+		return nil
+	case cfg.KafkaProcessedStore == "redis" && redisClient != nil:
+		return kafka.NewRedisProcessedStore(redisClient)
+	}
+
+	store := kafka.NewPostgresProcessedStore(db)
+	jobs.Add(kafka.ProcessedPurgeJob(store, log, cfg.KafkaProcessedPurgeInterval))
+	return store
+}
@@ -0,0 +1,49 @@
+package bootstrap
+
+import (
+	"testing"
+
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/kafka"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestProcessedStore(t *testing.T) {
+	tests := []struct {
+		name        string
+		store       string
+		redisClient *database.RedisClient
+		want        string
+	}{
+		{name: "none", store: "none", redisClient: &database.RedisClient{}, want: "none"},
+		{name: "postgres", store: "postgres", redisClient: &database.RedisClient{}, want: "postgres"},
+		{name: "redis", store: "redis", redisClient: &database.RedisClient{}, want: "redis"},
+		{name: "redis without a client", store: "redis", want: "postgres"},
+	}
+
+	log := logger.New("test", "error")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{KafkaProcessedStore: tt.store}
+			jobs := scheduler.New("test", nil, log, nil, noop.NewTracerProvider().Tracer("test"))
+
+			var got string
+			switch store := ProcessedStore(cfg, log, &database.PostgresDB{}, tt.redisClient, jobs).(type) {
+			case nil:
+				got = "none"
+			case *kafka.PostgresProcessedStore:
+				got = "postgres"
+			case *kafka.RedisProcessedStore:
+				got = "redis"
+			default:
+				t.Fatalf("ProcessedStore() = %T", store)
+			}
+			if got != tt.want {
+				t.Errorf("ProcessedStore() is %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
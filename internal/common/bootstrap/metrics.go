@@ -0,0 +1,27 @@
+package bootstrap
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+)
+
+// StartMetricsServer serves the metrics on cfg.MetricsPort in the
+// background. The process exits when the port cannot be served.
+func StartMetricsServer(cfg *config.Config, log *logger.Logger, m *metrics.Metrics) *http.Server {
+	server := m.NewServer(cfg.MetricsPort)
+
+	go func() {
+		log.Info(fmt.Sprintf("Serving metrics on port %s", cfg.MetricsPort))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(fmt.Sprintf("Failed to start metrics server: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return server
+}
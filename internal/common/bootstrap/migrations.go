@@ -0,0 +1,38 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dmehra2102/booking-system/internal/common/config"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/migrations"
+)
+
+// RunMigrations applies pending migrations when AUTO_MIGRATE is set and
+// handles the "migrate up|down [steps]|status" subcommand. It reports
+// whether the process was started only to run the subcommand.
+func RunMigrations(cfg *config.Config, log *logger.Logger, db *database.PostgresDB) (bool, error) {
+	isCommand := len(os.Args) > 1 && os.Args[1] == "migrate"
+	if !isCommand && !cfg.AutoMigrate {
+		return false, nil
+	}
+
+	migrator, err := database.NewMigrator(db, migrations.FS, log)
+	if err != nil {
+		return false, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	args := []string{"up"}
+	if isCommand {
+		args = os.Args[2:]
+	}
+
+	if err := migrator.Run(context.Background(), args); err != nil {
+		return false, err
+	}
+
+	return isCommand, nil
+}
@@ -0,0 +1,34 @@
+package buildinfo
+
+import (
+	"runtime"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+)
+
+// Version and Commit are injected at build time, e.g.
+//
+//	go build -ldflags "-X github.com/dmehra2102/booking-system/internal/common/buildinfo.Version=1.2.0 \
+//	  -X github.com/dmehra2102/booking-system/internal/common/buildinfo.Commit=$(git rev-parse --short HEAD)"
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
+
+// Fields returns the build information as structured log fields.
+func Fields() map[string]any {
+	return map[string]any{
+		"version":    Version,
+		"commit":     Commit,
+		"go_version": runtime.Version(),
+	}
+}
+
+// LogStartup logs that the service is starting, with its build, the
+// environment it runs in and its log level.
+func LogStartup(log *logger.Logger, environment string) {
+	log.WithFields(Fields()).
+		With("environment", environment).
+		With("log_level", log.Level()).
+		Info("service starting")
+}
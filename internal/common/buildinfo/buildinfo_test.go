@@ -0,0 +1,53 @@
+package buildinfo
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/testutil"
+)
+
+func TestStartupLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		commit   string
+		logLevel string
+	}{
+		{name: "injected", version: "1.2.0", commit: "9305e0c", logLevel: "info"},
+		{name: "not injected", version: "dev", commit: "unknown", logLevel: "debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, commit := Version, Commit
+			Version, Commit = tt.version, tt.commit
+			defer func() { Version, Commit = version, commit }()
+
+			line := testutil.CaptureStdout(t, func() {
+				LogStartup(logger.New("booking-service", tt.logLevel), "production")
+			})
+
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("log line %q is not JSON: %v", line, err)
+			}
+			want := map[string]any{
+				"service":     "booking-service",
+				"message":     "service starting",
+				"version":     tt.version,
+				"commit":      tt.commit,
+				"go_version":  runtime.Version(),
+				"environment": "production",
+				"log_level":   tt.logLevel,
+			}
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("%s = %v, want %v", key, entry[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache stores JSON encoded values of T in Redis under
+// "cache:<name>:<tenant>:<id>", so a tenant never reads another's entry.
+// It is best effort: Redis or decoding failures are logged and treated as
+// misses so callers fall back to the source of truth.
+type Cache[T any] struct {
+	redis   *database.RedisClient
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+	name    string
+	ttl     time.Duration
+}
+
+func New[T any](redis *database.RedisClient, logger *logger.Logger, metrics *metrics.Metrics, name string, ttl time.Duration) *Cache[T] {
+	return &Cache[T]{
+		redis:   redis,
+		logger:  logger,
+		metrics: metrics,
+		name:    name,
+		ttl:     ttl,
+	}
+}
+
+func (c *Cache[T]) key(ctx context.Context, id string) string {
+	return "cache:" + c.name + ":" + tenancy.ID(ctx) + ":" + id
+}
+
+// Get returns the cached value for id and whether it was found.
+func (c *Cache[T]) Get(ctx context.Context, id string) (*T, bool) {
+	raw, err := c.redis.Get(ctx, c.key(ctx, id))
+	if err != nil {
+		if err != redis.Nil {
+			c.metrics.CacheRequests.WithLabelValues(c.name, "error").Inc()
+		} else {
+			c.metrics.CacheRequests.WithLabelValues(c.name, "miss").Inc()
+		}
+		return nil, false
+	}
+
+	value := new(T)
+	if err := json.Unmarshal([]byte(raw), value); err != nil {
+		c.logger.WithContext(ctx).WithError(err).With("cache", c.name).Warn("failed to decode cached value")
+		c.metrics.CacheRequests.WithLabelValues(c.name, "error").Inc()
+		return nil, false
+	}
+
+	c.metrics.CacheRequests.WithLabelValues(c.name, "hit").Inc()
+	return value, true
+}
+
+func (c *Cache[T]) Set(ctx context.Context, id string, value *T) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).With("cache", c.name).Warn("failed to encode cache value")
+		return
+	}
+
+	// Failures are already logged by the redis client
+	_ = c.redis.Set(ctx, c.key(ctx, id), raw, c.ttl)
+}
+
+// Delete invalidates the cached values for ids.
+func (c *Cache[T]) Delete(ctx context.Context, ids ...string) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = c.key(ctx, id)
+	}
+
+	_ = c.redis.Delete(ctx, keys...)
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSecretsManagerProvider reads secrets from an AWS Secrets Manager
+// secret whose SecretString is a JSON object of variable names. Requests
+// are signed with the standard AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN variables, which are read on every refresh so
+// rotated session credentials are picked up.
+type AWSSecretsManagerProvider struct {
+	region   string
+	secretID string
+	endpoint string
+	client   *http.Client
+}
+
+func NewAWSSecretsManagerProvider(region, secretID string) (*AWSSecretsManagerProvider, error) {
+	if region == "" || secretID == "" {
+		return nil, errors.New("AWS_REGION and AWS_SECRET_ID are required for the aws secret provider")
+	}
+
+	return &AWSSecretsManagerProvider{
+		region:   region,
+		secretID: secretID,
+		endpoint: fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region),
+		client:   &http.Client{Timeout: secretsTimeout},
+	}, nil
+}
+
+func (p *AWSSecretsManagerProvider) Name() string {
+	return "aws-secrets-manager"
+}
+
+func (p *AWSSecretsManagerProvider) Secrets(ctx context.Context) (map[string]string, error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+
+	payload, err := json.Marshal(map[string]string{"SecretId": p.secretID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signV4(req, payload, accessKey, secretKey, p.region, "secretsmanager", time.Now().UTC())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("secrets manager answered %s: %s", resp.Status, body)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+	return decodeSecrets([]byte(secret.SecretString))
+}
+
+var _ SecretProvider = (*AWSSecretsManagerProvider)(nil)
+
+// signV4 adds an AWS Signature Version 4 Authorization header to req,
+// signing every header set so far.
+func signV4(req *http.Request, payload []byte, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	canonicalHeaders, signedHeaders := canonicalize(req.Header, names)
+
+	canonicalRequest := req.Method + "\n" +
+		"/\n" +
+		req.URL.RawQuery + "\n" +
+		canonicalHeaders + "\n" +
+		signedHeaders + "\n" +
+		hexSHA256(payload)
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+
+	// net/http takes the host from the request, not the header
+	req.Header.Del("Host")
+}
+
+// canonicalize lists the headers as "name:value" lines sorted by their
+// lower case names, and the names joined by semicolons.
+func canonicalize(header http.Header, names []string) (canonical, signed string) {
+	lower := make([]string, len(names))
+	values := make(map[string]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+		values[lower[i]] = strings.TrimSpace(strings.Join(header.Values(name), ","))
+	}
+	sort.Strings(lower)
+
+	var b strings.Builder
+	for _, name := range lower {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+	return b.String(), strings.Join(lower, ";")
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
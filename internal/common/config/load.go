@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// minJWTSecretLength is the shortest JWT secret accepted in production,
+// 256 bits for HS256.
+const minJWTSecretLength = 32
+
+const redacted = "[REDACTED]"
+
+// source provides raw variable values. Sources are consulted in order and
+// the first one holding a variable wins.
+type source interface {
+	Lookup(key string) (string, bool)
+}
+
+type envSource struct{}
+
+func (envSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// secretSource offers the secret store values for the secret fields only,
+// so the store cannot change ports or feature flags.
+type secretSource struct {
+	secrets *Secrets
+}
+
+func (s secretSource) Lookup(key string) (string, bool) {
+	return s.secrets.Lookup(key)
+}
+
+// fileSource holds the variables of a YAML config file, a flat mapping of
+// variable names to values:
+//
+//	SERVICE_PORT: 8080
+//	KAFKA_BROKERS: [kafka-1:9092, kafka-2:9092]
+type fileSource map[string]string
+
+func (f fileSource) Lookup(key string) (string, bool) {
+	value, ok := f[key]
+	return value, ok
+}
+
+func loadFile(path string) (fileSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	file := make(fileSource, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+			file[strings.ToUpper(key)] = ""
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			file[strings.ToUpper(key)] = strings.Join(items, ",")
+		default:
+			file[strings.ToUpper(key)] = fmt.Sprint(v)
+		}
+	}
+	return file, nil
+}
+
+// field describes a Config field from its tags.
+type field struct {
+	index        int
+	env          string
+	defaultValue string
+	desc         string
+	requiredIn   string
+	secret       bool
+	reload       bool
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func fields() []field {
+	t := reflect.TypeOf(Config{})
+
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		if tag.Get("env") == "" {
+			continue
+		}
+
+		fields = append(fields, field{
+			index:        i,
+			env:          tag.Get("env"),
+			defaultValue: tag.Get("default"),
+			desc:         tag.Get("desc"),
+			requiredIn:   tag.Get("required"),
+			secret:       tag.Get("secret") == "true",
+			reload:       tag.Get("reload") == "true",
+		})
+	}
+	return fields
+}
+
+// load sets every field from the first source holding its variable, or
+// its default. Blank values count as unset, except for lists, where a
+// blank value yields an empty list.
+func (c *Config) load(sources []source) error {
+	c.explicit = make(map[string]bool)
+	v := reflect.ValueOf(c).Elem()
+
+	var errs []error
+	for _, f := range fields() {
+		target := v.Field(f.index)
+		isList := target.Kind() == reflect.Slice
+
+		raw, explicit := f.defaultValue, false
+		for _, src := range sources {
+			if _, ok := src.(secretSource); ok && !f.secret {
+				continue
+			}
+			if value, ok := src.Lookup(f.env); ok && (value != "" || isList) {
+				raw, explicit = value, true
+				break
+			}
+		}
+		c.explicit[f.env] = explicit
+
+		if err := set(target, raw); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", f.env, raw, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func set(target reflect.Value, raw string) error {
+	if target.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(d))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(raw)
+	case reflect.Int:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(i))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		target.SetBool(b)
+	case reflect.Slice:
+		items := make([]string, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		target.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", target.Type())
+	}
+	return nil
+}
+
+// Redacted returns the effective configuration by variable name for
+// logging. Secrets are masked, as are passwords in URLs.
+func (c *Config) Redacted() map[string]any {
+	v := reflect.ValueOf(c).Elem()
+
+	values := make(map[string]any)
+	for _, f := range fields() {
+		value := v.Field(f.index).Interface()
+
+		switch typed := value.(type) {
+		case time.Duration:
+			value = typed.String()
+		case []string:
+			if f.secret {
+				items := make([]string, 0, len(typed))
+				for _, item := range typed {
+					items = append(items, redactURL(item))
+				}
+				typed = items
+			}
+			value = strings.Join(typed, ",")
+		case string:
+			if f.secret && typed != "" {
+				value = redactURL(typed)
+			}
+		}
+		values[f.env] = value
+	}
+	return values
+}
+
+// redactURL keeps the location of a connection URL visible, masking its
+// password. Anything else is masked completely.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return redacted
+	}
+	return u.Redacted()
+}
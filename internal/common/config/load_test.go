@@ -0,0 +1,75 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadKafkaBrokers(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        fileSource
+		environment string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name: "unset uses the default",
+			file: fileSource{},
+			want: []string{"localhost:29092"},
+		},
+		{
+			name: "empty",
+			file: fileSource{"KAFKA_BROKERS": ""},
+			want: []string{},
+		},
+		{
+			name: "whitespace",
+			file: fileSource{"KAFKA_BROKERS": "  "},
+			want: []string{},
+		},
+		{
+			name: "empty entries are dropped",
+			file: fileSource{"KAFKA_BROKERS": " kafka-1:9092 ,, kafka-2:9092,"},
+			want: []string{"kafka-1:9092", "kafka-2:9092"},
+		},
+		{
+			name:        "empty in production",
+			file:        fileSource{"KAFKA_BROKERS": " "},
+			environment: "production",
+			want:        []string{},
+			wantErr:     true,
+		},
+		{
+			name:        "set in production",
+			file:        fileSource{"KAFKA_BROKERS": "kafka-1:9092"},
+			environment: "production",
+			want:        []string{"kafka-1:9092"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.environment != "" {
+				tt.file["ENVIRONMENT"] = tt.environment
+			}
+
+			cfg := &Config{}
+			if err := cfg.load([]source{tt.file}); err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+			if !slices.Equal(cfg.KafkaBrokers, tt.want) {
+				t.Errorf("KafkaBrokers = %q, want %q", cfg.KafkaBrokers, tt.want)
+			}
+
+			// Production settings unrelated to the brokers are missing, so
+			// only the broker errors are looked at
+			err := cfg.Validate()
+			gotErr := err != nil && strings.Contains(err.Error(), "KAFKA_BROKERS")
+			if gotErr != tt.wantErr {
+				t.Errorf("Validate() error = %v, want a KAFKA_BROKERS error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+)
+
+const secretsTimeout = 10 * time.Second
+
+// SecretProvider reads secret variables, such as JWT_SECRET or
+// POSTGRES_URL, from a secret store. Secrets are keyed by variable name.
+type SecretProvider interface {
+	Name() string
+	Secrets(ctx context.Context) (map[string]string, error)
+}
+
+// newSecretProvider returns the provider selected by SECRETS_PROVIDER, or
+// nil when secrets come from the environment.
+func newSecretProvider(c *Config) (SecretProvider, error) {
+	switch c.SecretsProvider {
+	case "":
+		return nil, nil
+	case "vault":
+		return NewVaultProvider(c.VaultAddr, c.VaultToken, c.VaultSecretPath)
+	case "aws":
+		return NewAWSSecretsManagerProvider(c.AWSRegion, c.AWSSecretID)
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_PROVIDER %q, expected vault or aws", c.SecretsProvider)
+	}
+}
+
+// Secrets holds the values last read from a SecretProvider and keeps them
+// current. Components that can swap credentials at runtime subscribe with
+// OnChange; the others pick rotated values up on restart.
+type Secrets struct {
+	provider SecretProvider
+
+	mu          sync.RWMutex
+	values      map[string]string
+	subscribers []func(changed map[string]string)
+}
+
+func NewSecrets(provider SecretProvider) *Secrets {
+	return &Secrets{provider: provider, values: make(map[string]string)}
+}
+
+// Lookup returns the value of a secret variable, making Secrets a config
+// source.
+func (s *Secrets) Lookup(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// OnChange registers fn to be called with the secrets that changed after a
+// refresh.
+func (s *Secrets) OnChange(fn func(changed map[string]string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Refresh reads the secrets again and notifies subscribers of changes. On
+// failure the previous values are kept.
+func (s *Secrets) Refresh(ctx context.Context) (changed map[string]string, err error) {
+	values, err := s.provider.Secrets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets from %s: %w", s.provider.Name(), err)
+	}
+
+	s.mu.Lock()
+	changed = make(map[string]string)
+	for key, value := range values {
+		if old, ok := s.values[key]; !ok || old != value {
+			changed[key] = value
+		}
+	}
+	s.values = values
+	subscribers := slices.Clone(s.subscribers)
+	s.mu.Unlock()
+
+	if len(changed) > 0 {
+		for _, fn := range subscribers {
+			fn(changed)
+		}
+	}
+	return changed, nil
+}
+
+// Watch refreshes the secrets every interval in the background until the
+// returned function is called.
+func (s *Secrets) Watch(interval time.Duration, log *logger.Logger) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	log = log.With("secret_provider", s.provider.Name())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			refreshCtx, cancelRefresh := context.WithTimeout(ctx, secretsTimeout)
+			changed, err := s.Refresh(refreshCtx)
+			cancelRefresh()
+
+			if err != nil {
+				log.WithError(err).Warn("failed to refresh secrets, keeping previous values")
+				continue
+			}
+			if len(changed) > 0 {
+				keys := slices.Sorted(maps.Keys(changed))
+				log.With("keys", strings.Join(keys, ",")).Info("secrets rotated")
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// decodeSecrets turns a JSON object of variable names into secret values.
+// Non-string values are formatted, so ports and flags may be stored as
+// numbers and booleans.
+func decodeSecrets(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	return stringify(raw), nil
+}
+
+func stringify(raw map[string]any) map[string]string {
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if s, ok := value.(string); ok {
+			values[key] = s
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
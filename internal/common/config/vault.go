@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// VaultProvider reads secrets from a HashiCorp Vault KV secret. Both KV
+// version 1 and 2 engines are supported; with version 2 the path includes
+// the "data" segment, e.g. "secret/data/booking-system".
+type VaultProvider struct {
+	addr   string
+	token  string
+	path   string
+	client *http.Client
+}
+
+func NewVaultProvider(addr, token, path string) (*VaultProvider, error) {
+	if addr == "" || token == "" || path == "" {
+		return nil, errors.New("VAULT_ADDR, VAULT_TOKEN and VAULT_SECRET_PATH are required for the vault secret provider")
+	}
+
+	return &VaultProvider{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		path:   strings.Trim(path, "/"),
+		client: &http.Client{Timeout: secretsTimeout},
+	}, nil
+}
+
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+func (p *VaultProvider) Secrets(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+p.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault answered %s", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	// KV version 2 nests the secret under data.data next to its metadata
+	if nested, ok := secret.Data["data"].(map[string]any); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return stringify(nested), nil
+		}
+	}
+	return stringify(secret.Data), nil
+}
+
+var _ SecretProvider = (*VaultProvider)(nil)
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+)
+
+// Watcher reloads the configuration on SIGHUP and whenever CONFIG_FILE
+// changes, and notifies subscribers so operators can tune a running
+// service. Only fields tagged reload:"true" are applied; changes to other
+// fields are logged and take effect on restart. Environment variables
+// cannot change in a running process, so reloads pick up edits of the
+// config file and values rotated in the secret store.
+type Watcher struct {
+	path    string
+	log     *logger.Logger
+	current atomic.Pointer[Config]
+
+	// reloadMu serializes reloads, mu guards the subscribers
+	reloadMu    sync.Mutex
+	mu          sync.Mutex
+	subscribers []func(cfg *Config)
+}
+
+func NewWatcher(cfg *Config, log *logger.Logger) *Watcher {
+	w := &Watcher{path: os.Getenv("CONFIG_FILE"), log: log}
+	w.current.Store(cfg)
+	return w
+}
+
+// Current returns the configuration with the reloaded settings applied.
+// The returned value must not be modified.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// OnChange registers fn to be called with the new configuration after a
+// reload changed any of the reloadable settings.
+func (w *Watcher) OnChange(fn func(cfg *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload reads the configuration again and applies the reloadable
+// settings that changed, returning their variable names. A configuration
+// that fails to load or validate is rejected as a whole.
+func (w *Watcher) Reload() (changed []string, err error) {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	current := w.current.Load()
+
+	sources, err := fileSources()
+	if err != nil {
+		return nil, err
+	}
+	if current.secrets != nil {
+		sources = slices.Insert(sources, 1, source(secretSource{current.secrets}))
+	}
+
+	next := &Config{secrets: current.secrets}
+	if err := next.load(sources); err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	applied := *current
+	target, loaded := reflect.ValueOf(&applied).Elem(), reflect.ValueOf(next).Elem()
+
+	var restart []string
+	for _, f := range fields() {
+		if reflect.DeepEqual(target.Field(f.index).Interface(), loaded.Field(f.index).Interface()) {
+			continue
+		}
+		if !f.reload {
+			restart = append(restart, f.env)
+			continue
+		}
+		target.Field(f.index).Set(loaded.Field(f.index))
+		changed = append(changed, f.env)
+	}
+
+	if len(restart) > 0 {
+		w.log.With("keys", strings.Join(restart, ",")).Warn("config changes take effect on restart")
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	w.current.Store(&applied)
+
+	w.mu.Lock()
+	subscribers := slices.Clone(w.subscribers)
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(&applied)
+	}
+	return changed, nil
+}
+
+// Watch reloads on SIGHUP and, when CONFIG_FILE is set, every time its
+// modification time changes, checked every interval. It runs in the
+// background until the returned function is called.
+func (w *Watcher) Watch(interval time.Duration) (stop func()) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	var poll <-chan time.Time
+	var ticker *time.Ticker
+	if w.path != "" && interval > 0 {
+		ticker = time.NewTicker(interval)
+		poll = ticker.C
+	}
+
+	done := make(chan struct{})
+	go func() {
+		modified := w.modTime()
+		for {
+			select {
+			case <-done:
+				return
+			case <-hangup:
+				w.log.Info("SIGHUP received, reloading configuration")
+			case <-poll:
+				mod := w.modTime()
+				if mod.Equal(modified) {
+					continue
+				}
+				modified = mod
+			}
+
+			changed, err := w.Reload()
+			if err != nil {
+				w.log.WithError(err).Error("failed to reload configuration, keeping current settings")
+				continue
+			}
+			if len(changed) > 0 {
+				w.log.With("keys", strings.Join(changed, ",")).Info("configuration reloaded")
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(hangup)
+			if ticker != nil {
+				ticker.Stop()
+			}
+			close(done)
+		})
+	}
+}
+
+func (w *Watcher) modTime() time.Time {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// openPool opens a pgx connection pool to url and the *sql.DB that runs
+// statements on it, so repositories keep the database/sql API. Closing the
+// *sql.DB leaves the pool open.
+func openPool(url string) (*pgxpool.Pool, *sql.DB, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config.MaxConns = 25
+	config.MaxConnLifetime = 5 * time.Minute
+	// Describe each statement once per connection rather than on every
+	// run, without keeping a prepared statement for every query; hot
+	// queries opt into that with Prepared.
+	config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pool, stdlib.OpenDBFromPool(pool), nil
+}
+
+func poolStats(pool *pgxpool.Pool) func() metrics.PoolStats {
+	return func() metrics.PoolStats {
+		stat := pool.Stat()
+		return metrics.PoolStats{
+			Acquired:     stat.AcquiredConns(),
+			Idle:         stat.IdleConns(),
+			Max:          stat.MaxConns(),
+			Waits:        stat.EmptyAcquireCount(),
+			WaitDuration: stat.EmptyAcquireWaitTime(),
+		}
+	}
+}
+
+// Prepared marks a statement to be prepared once per connection and reused
+// on later runs, for hot queries. Pass it the arguments of the statement:
+//
+//	row := db.QueryRow(ctx, "user.get_by_id", query, database.Prepared(id)...)
+func Prepared(args ...any) []any {
+	return append([]any{pgx.QueryExecModeCacheStatement}, args...)
+}
+
+var (
+	typeMapMu sync.Mutex
+	typeMap   = pgtype.NewMap()
+)
+
+// Array returns a scan destination for a Postgres array into dest, a
+// pointer to a slice. Slices can be passed as arguments as they are.
+func Array(dest any) sql.Scanner {
+	return scannerFunc(func(src any) error {
+		// A pgtype.Map must not be used concurrently
+		typeMapMu.Lock()
+		defer typeMapMu.Unlock()
+		return typeMap.SQLScanner(dest).Scan(src)
+	})
+}
+
+type scannerFunc func(src any) error
+
+func (f scannerFunc) Scan(src any) error {
+	return f(src)
+}
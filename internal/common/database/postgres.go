@@ -3,41 +3,108 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/dmehra2102/booking-system/internal/common/logger"
 	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/resilience"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type PostgresDB struct {
 	db      *sql.DB
+	pool    *pgxpool.Pool
 	logger  *logger.Logger
 	metrics *metrics.Metrics
 	tracer  trace.Tracer
+	retry   retry.Policy
+	breaker *resilience.Breaker
+
+	replicas     []*replica
+	nextReplica  atomic.Uint64
+	stopReplicas chan struct{}
+
+	slowQuery time.Duration
+}
+
+// transientCodes are Postgres errors after which the statement is known
+// not to have taken effect, so it is safe to run it again.
+var transientCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"53300": true, // too_many_connections
+	"57P03": true, // cannot_connect_now
+	"08001": true, // sqlclient_unable_to_establish_sqlconnection
+	"08004": true, // sqlserver_rejected_establishment_of_sqlconnection
+}
+
+// IsTransient reports whether err is a Postgres error worth retrying.
+func IsTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && transientCodes[pgErr.Code]
+}
+
+// isFailure reports whether err indicates a problem with Postgres itself
+// rather than with the statement, for the circuit breaker.
+func isFailure(err error) bool {
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, sql.ErrTxDone) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// The first two characters of a code are its class
+		switch pgErr.Code[:2] {
+		case "08", "53", "57", "58", "XX":
+			return true
+		}
+		return false
+	}
+	return true
 }
 
-func NewPostgresDB(url string, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) (*PostgresDB, error) {
-	db, err := sql.Open("postgres", url)
+// NewPostgresDB connects to url. Query, Exec and BeginTx are retried with
+// retryPolicy when they fail with a transient error outside a transaction, and all statements
+// fail fast with resilience.ErrOpen while the circuit breaker is open. The
+// statistics of the connection pool are reported as the "primary" pool of
+// metrics.DBConnections.
+func NewPostgresDB(url string, retryPolicy retry.Policy, breakerConfig resilience.BreakerConfig, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) (*PostgresDB, error) {
+	pool, db, err := openPool(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
 	}
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
-
 	// Test connection
 	if err := db.Ping(); err != nil {
+		db.Close()
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping postgres: %w", err)
 	}
 
+	metrics.DBConnections.Add("primary", poolStats(pool))
+
+	retryPolicy.Retryable = IsTransient
+	breakerConfig.IsFailure = isFailure
+
 	return &PostgresDB{
 		db:      db,
+		pool:    pool,
 		logger:  logger,
 		metrics: metrics,
 		tracer:  tracer,
+		retry:   retryPolicy,
+		breaker: resilience.NewBreaker("postgres", breakerConfig, logger, metrics),
 	}, nil
 }
 
@@ -45,75 +112,201 @@ func (p *PostgresDB) DB() *sql.DB {
 	return p.db
 }
 
+// Close closes the primary and the replicas.
 func (p *PostgresDB) Close() error {
-	return p.db.Close()
-}
+	if p.stopReplicas != nil {
+		close(p.stopReplicas)
+	}
 
-func (p *PostgresDB) Health() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	errs := []error{p.db.Close()}
+	p.pool.Close()
+	for _, r := range p.replicas {
+		errs = append(errs, r.db.Close())
+		r.pool.Close()
+	}
+	return errors.Join(errs...)
+}
 
+func (p *PostgresDB) Health(ctx context.Context) error {
 	return p.db.PingContext(ctx)
 }
 
-func (p *PostgresDB) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	ctx, span := p.tracer.Start(ctx, "postgres.query")
+// Query runs query, a statement identified as name (e.g. "user.list") in
+// spans, metrics and the slow query log.
+func (p *PostgresDB) Query(ctx context.Context, name, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := p.startSpan(ctx, name, query)
 	defer span.End()
+	defer timing.Track(ctx, "db")()
 
 	start := time.Now()
-	rows, err := p.db.QueryContext(ctx, query, args...)
-	duration := time.Since(start).Seconds()
+	var rows *sql.Rows
+	var err error
+	if !p.onReplica(ctx, func(db *sql.DB) error {
+		rows, err = db.QueryContext(ctx, query, args...)
+		return err
+	}) {
+		err = p.retryPolicy(ctx).Do(ctx, func(ctx context.Context) error {
+			return p.breaker.Execute(ctx, func(ctx context.Context) error {
+				var err error
+				rows, err = p.conn(ctx).QueryContext(ctx, query, args...)
+				return err
+			})
+		})
+	}
+	duration := time.Since(start)
+	p.logSlow(ctx, name, query, args, duration)
 
 	if err != nil {
-		p.metrics.DBQueries.WithLabelValues("query", "error").Inc()
-		p.logger.WithContext(ctx).WithError(err).Error("database query failed")
+		p.metrics.DBQueries.WithLabelValues(name, "error").Inc()
+		tracing.RecordError(span, err)
+		p.logger.WithContext(ctx).With("statement", name).WithError(err).Error("database query failed")
 		return nil, err
 	}
 
-	p.metrics.DBQueries.WithLabelValues("query", "success").Inc()
-	p.metrics.DBQueryDuration.WithLabelValues("query").Observe(duration)
+	p.metrics.DBQueries.WithLabelValues(name, "success").Inc()
+	p.metrics.DBQueryDuration.WithLabelValues(name).Observe(duration.Seconds())
 
 	return rows, nil
 }
 
-func (p *PostgresDB) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
-	ctx, span := p.tracer.Start(ctx, "postgres.query_row")
+// QueryRow runs query, a statement identified as name, for a single row.
+func (p *PostgresDB) QueryRow(ctx context.Context, name, query string, args ...any) *sql.Row {
+	ctx, span := p.startSpan(ctx, name, query)
 	defer span.End()
+	defer timing.Track(ctx, "db")()
 
 	start := time.Now()
-	row := p.db.QueryRowContext(ctx, query, args...)
-	duration := time.Since(start).Seconds()
+	var row *sql.Row
+	if p.onReplica(ctx, func(db *sql.DB) error {
+		row = db.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	}) {
+		duration := time.Since(start)
+		p.logSlow(ctx, name, query, args, duration)
+		tracing.RecordError(span, row.Err())
+		p.metrics.DBQueries.WithLabelValues(name, "success").Inc()
+		p.metrics.DBQueryDuration.WithLabelValues(name).Observe(duration.Seconds())
+		return row
+	}
 
-	p.metrics.DBQueries.WithLabelValues("query", "success").Inc()
-	p.metrics.DBQueryDuration.WithLabelValues("query").Observe(duration)
+	done, err := p.breaker.Allow()
+	if err != nil {
+		tracing.RecordError(span, err)
+
+		// A *sql.Row cannot carry an error of our own, so run the query
+		// with a cancelled context: it fails without reaching Postgres.
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		return p.conn(ctx).QueryRowContext(cancelled, query, args...)
+	}
+
+	row = p.conn(ctx).QueryRowContext(ctx, query, args...)
+	duration := time.Since(start)
+	p.logSlow(ctx, name, query, args, duration)
+	done(row.Err())
+	tracing.RecordError(span, row.Err())
+
+	p.metrics.DBQueries.WithLabelValues(name, "success").Inc()
+	p.metrics.DBQueryDuration.WithLabelValues(name).Observe(duration.Seconds())
 
 	return row
 }
 
-func (p *PostgresDB) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
-	ctx, span := p.tracer.Start(ctx, "postgres.exec")
+// Exec runs query, a statement identified as name, on the primary.
+func (p *PostgresDB) Exec(ctx context.Context, name, query string, args ...any) (sql.Result, error) {
+	ctx, span := p.startSpan(ctx, name, query)
 	defer span.End()
+	defer timing.Track(ctx, "db")()
 
 	start := time.Now()
-	result, err := p.db.ExecContext(ctx, query, args...)
-	duration := time.Since(start).Seconds()
+	var result sql.Result
+	err := p.retryPolicy(ctx).Do(ctx, func(ctx context.Context) error {
+		return p.breaker.Execute(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = p.conn(ctx).ExecContext(ctx, query, args...)
+			return err
+		})
+	})
+	duration := time.Since(start)
+	p.logSlow(ctx, name, query, args, duration)
 
 	if err != nil {
-		p.metrics.DBQueries.WithLabelValues("exec", "error").Inc()
-		p.logger.WithContext(ctx).WithError(err).Error("database exec failed")
+		p.metrics.DBQueries.WithLabelValues(name, "error").Inc()
+		tracing.RecordError(span, err)
+		p.logger.WithContext(ctx).With("statement", name).WithError(err).Error("database exec failed")
 		return nil, err
 	}
 
-	p.metrics.DBQueries.WithLabelValues("exec", "success").Inc()
-	p.metrics.DBQueryDuration.WithLabelValues("exec").Observe(duration)
+	p.metrics.DBQueries.WithLabelValues(name, "success").Inc()
+	p.metrics.DBQueryDuration.WithLabelValues(name).Observe(duration.Seconds())
 
 	return result, nil
 }
 
+// SetSlowQueryThreshold logs the statements that take longer than
+// threshold, with their parameters redacted. Zero turns the log off.
+func (p *PostgresDB) SetSlowQueryThreshold(threshold time.Duration) {
+	p.slowQuery = threshold
+}
+
+func (p *PostgresDB) logSlow(ctx context.Context, name, query string, args []any, duration time.Duration) {
+	if p.slowQuery <= 0 || duration < p.slowQuery {
+		return
+	}
+
+	p.logger.WithContext(ctx).WithFields(map[string]any{
+		"statement":   name,
+		"query":       strings.Join(strings.Fields(query), " "),
+		"params":      redactParams(args),
+		"duration_ms": duration.Milliseconds(),
+	}).Warn("slow database query")
+}
+
+// redactParams describes the parameters of a statement by type only, as
+// they may hold personal data or credentials.
+func redactParams(args []any) []string {
+	params := make([]string, 0, len(args))
+	for _, arg := range args {
+		if _, ok := arg.(pgx.QueryExecMode); ok {
+			continue
+		}
+		params = append(params, fmt.Sprintf("$%d %T", len(params)+1, arg))
+	}
+	return params
+}
+
+// startSpan starts the span of the statement name, tagged with its SQL
+// operation. Arguments are never recorded.
+func (p *PostgresDB) startSpan(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	return p.tracer.Start(ctx, "postgres."+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation(query)),
+		semconv.DBQueryText(query),
+	))
+}
+
+// operation returns the SQL verb of query, e.g. SELECT.
+func operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
 // BeginTx starts a new transaction
 func (p *PostgresDB) BeginTx(ctx context.Context) (*sql.Tx, error) {
-	ctx, span := p.tracer.Start(ctx, "postgres.begin_tx")
+	ctx, span := p.tracer.Start(ctx, "postgres.begin_tx", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemPostgreSQL))
 	defer span.End()
 
-	return p.db.BeginTx(ctx, nil)
+	var tx *sql.Tx
+	err := p.retry.Do(ctx, func(ctx context.Context) error {
+		return p.breaker.Execute(ctx, func(ctx context.Context) error {
+			var err error
+			tx, err = p.db.BeginTx(ctx, nil)
+			return err
+		})
+	})
+	tracing.RecordError(span, err)
+	return tx, err
 }
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrUnknownColumn is returned for an update of a column that is not
+// whitelisted.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// Scanner is a *sql.Row or *sql.Rows.
+type Scanner interface {
+	Scan(dest ...any) error
+}
+
+// ScanAll scans every row with scan and closes rows. It returns an empty
+// slice rather than nil when there are no rows, so lists encode as [].
+func ScanAll[T any](rows *sql.Rows, scan func(Scanner) (T, error)) ([]T, error) {
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Args collects the arguments of a statement.
+//
+//	var args database.Args
+//	query := "SELECT ... WHERE id = " + args.Add(id)
+//	rows, err := db.Query(ctx, "user.list", query, args...)
+type Args []any
+
+// Add appends value and returns its placeholder.
+func (a *Args) Add(value any) string {
+	*a = append(*a, value)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// Where joins conditions into a WHERE clause, or returns "" without any.
+func Where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// Columns is the whitelist of the columns an update may set. Column names
+// cannot be passed as arguments, so keys of an update map reach the SQL
+// only after they are checked against it.
+type Columns map[string]bool
+
+func NewColumns(names ...string) Columns {
+	columns := make(Columns, len(names))
+	for _, name := range names {
+		columns[name] = true
+	}
+	return columns
+}
+
+// Set returns the SET list "column = $n, ..." of updates, in column order
+// so equal updates build equal statements, and adds the values to args.
+func (c Columns) Set(updates map[string]any, args *Args) (string, error) {
+	parts := make([]string, 0, len(updates))
+	for _, column := range slices.Sorted(maps.Keys(updates)) {
+		if !c[column] {
+			return "", fmt.Errorf("%w %q", ErrUnknownColumn, column)
+		}
+		parts = append(parts, column+" = "+args.Add(updates[column]))
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint
+// violation.
+func IsUniqueViolation(err error) bool {
+	return hasCode(err, "23505")
+}
+
+// IsForeignKeyViolation reports whether err is a Postgres foreign key
+// violation, e.g. a reference to a row that does not exist.
+func IsForeignKeyViolation(err error) bool {
+	return hasCode(err, "23503")
+}
+
+func hasCode(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
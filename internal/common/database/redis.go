@@ -2,15 +2,78 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/dmehra2102/booking-system/internal/common/logger"
 	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/resilience"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// slidingWindowScript records a request in a sorted set scored by arrival
+// time and admits it if fewer than limit requests arrived within the
+// window. It returns {allowed, remaining, retry_after_ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+// releaseLockScript deletes the lock key only if it is still held by the
+// owner, so an expired lock taken over by someone else is left alone.
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// claimLeaseScript sets the lease key to the owner if it is free, or
+// extends it if the owner already holds it. It returns 1 when the owner
+// holds the lease afterwards, otherwise 0.
+var claimLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+var ErrLockNotAcquired = errors.New("lock not acquired")
+
+// lockRetryInterval is how long AcquireLock waits between attempts.
+const lockRetryInterval = 50 * time.Millisecond
+
+// Lock is a held distributed lock. It expires after its ttl even while the
+// holder is still working, so it only keeps holders apart that finish
+// within the ttl.
+type Lock struct {
+	Key   string
+	owner string
+}
+
 type RedisClient struct {
 	client  *redis.Client
 	logger  *logger.Logger
@@ -18,7 +81,9 @@ type RedisClient struct {
 	tracer  trace.Tracer
 }
 
-func NewRedisClient(url string, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) (*RedisClient, error) {
+// NewRedisClient connects to url. Commands fail fast with
+// resilience.ErrOpen while the circuit breaker is open.
+func NewRedisClient(url string, breakerConfig resilience.BreakerConfig, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) (*RedisClient, error) {
 	opt, err := redis.ParseURL(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
@@ -29,10 +94,13 @@ func NewRedisClient(url string, logger *logger.Logger, metrics *metrics.Metrics,
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := client.Ping(ctx); err != nil {
+	if err := client.Ping(ctx).Err(); err != nil {
 		return nil, fmt.Errorf("failed to ping redis: %v", err)
 	}
 
+	breakerConfig.IsFailure = isRedisFailure
+	client.AddHook(breakerHook{breaker: resilience.NewBreaker("redis", breakerConfig, logger, metrics)})
+
 	return &RedisClient{
 		client:  client,
 		logger:  logger,
@@ -49,15 +117,12 @@ func (r *RedisClient) Close() error {
 	return r.client.Close()
 }
 
-func (r *RedisClient) Health() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
+func (r *RedisClient) Health(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
 
 func (r *RedisClient) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
-	ctx, span := r.tracer.Start(ctx, "redis.set")
+	ctx, span := r.startSpan(ctx, "redis.set")
 	defer span.End()
 
 	start := time.Now()
@@ -67,6 +132,7 @@ func (r *RedisClient) Set(ctx context.Context, key string, value any, expiration
 	status := "success"
 	if err != nil {
 		status = "error"
+		tracing.RecordError(span, err)
 		r.logger.WithContext(ctx).WithError(err).Error("redis set failed")
 	}
 
@@ -76,8 +142,36 @@ func (r *RedisClient) Set(ctx context.Context, key string, value any, expiration
 	return err
 }
 
+// SetIfAbsent sets key to value unless it holds a value already, which it
+// returns instead, reporting whether key was set.
+func (r *RedisClient) SetIfAbsent(ctx context.Context, key string, value any, expiration time.Duration) (string, bool, error) {
+	ctx, span := r.startSpan(ctx, "redis.set_if_absent")
+	defer span.End()
+
+	start := time.Now()
+	existing, err := r.client.SetArgs(ctx, key, value, redis.SetArgs{Mode: "NX", Get: true, TTL: expiration}).Result()
+	duration := time.Since(start).Seconds()
+
+	set := false
+	if err == redis.Nil {
+		set, err = true, nil
+	}
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis set if absent failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_set_if_absent", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_set_if_absent").Observe(duration)
+
+	return existing, set, err
+}
+
 func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
-	ctx, span := r.tracer.Start(ctx, "redis.get")
+	ctx, span := r.startSpan(ctx, "redis.get")
 	defer span.End()
 
 	start := time.Now()
@@ -87,6 +181,7 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	status := "success"
 	if err != nil && err != redis.Nil {
 		status = "error"
+		tracing.RecordError(span, err)
 		r.logger.WithContext(ctx).WithError(err).Error("redis got failed")
 	}
 
@@ -96,8 +191,31 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	return result, err
 }
 
+// GetDel returns the value of key and deletes it atomically, so only one
+// caller ever observes it.
+func (r *RedisClient) GetDel(ctx context.Context, key string) (string, error) {
+	ctx, span := r.startSpan(ctx, "redis.getdel")
+	defer span.End()
+
+	start := time.Now()
+	result, err := r.client.GetDel(ctx, key).Result()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil && err != redis.Nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis getdel failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_getdel", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_getdel").Observe(duration)
+
+	return result, err
+}
+
 func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
-	ctx, span := r.tracer.Start(ctx, "redis.delete")
+	ctx, span := r.startSpan(ctx, "redis.delete")
 	defer span.End()
 
 	start := time.Now()
@@ -107,6 +225,7 @@ func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
 	status := "success"
 	if err != nil {
 		status = "error"
+		tracing.RecordError(span, err)
 		r.logger.WithContext(ctx).WithError(err).Error("redis delete failed")
 	}
 
@@ -115,3 +234,261 @@ func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
 
 	return err
 }
+
+func (r *RedisClient) Exists(ctx context.Context, keys ...string) (int64, error) {
+	ctx, span := r.startSpan(ctx, "redis.exists")
+	defer span.End()
+
+	start := time.Now()
+	count, err := r.client.Exists(ctx, keys...).Result()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis exists failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_exists", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_exists").Observe(duration)
+
+	return count, err
+}
+
+// Incr increments the counter at key, which starts from 0 when missing,
+// and returns its new value.
+func (r *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	ctx, span := r.startSpan(ctx, "redis.incr")
+	defer span.End()
+
+	start := time.Now()
+	value, err := r.client.Incr(ctx, key).Result()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis incr failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_incr", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_incr").Observe(duration)
+
+	return value, err
+}
+
+// IncrExpire increments the counter at key like Incr and lets it expire
+// after expiration, which each increment restarts.
+func (r *RedisClient) IncrExpire(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	ctx, span := r.startSpan(ctx, "redis.incr_expire")
+	defer span.End()
+
+	start := time.Now()
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, expiration)
+		return nil
+	})
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis incr expire failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_incr_expire", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_incr_expire").Observe(duration)
+
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// AllowRequest applies a sliding window limit of limit requests per window
+// to key. When the request is rejected, retryAfter is the time until the
+// oldest request in the window expires.
+func (r *RedisClient) AllowRequest(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, retryAfter time.Duration, err error) {
+	ctx, span := r.startSpan(ctx, "redis.allow_request")
+	defer span.End()
+
+	start := time.Now()
+	result, err := slidingWindowScript.Run(ctx, r.client, []string{key},
+		start.UnixMilli(), window.Milliseconds(), limit, uuid.New().String(),
+	).Int64Slice()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis rate limit failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_rate_limit", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_rate_limit").Observe(duration)
+
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	return result[0] == 1, int(result[1]), time.Duration(result[2]) * time.Millisecond, nil
+}
+
+// AcquireLock takes the lock on key for ttl, retrying until it is free or
+// ctx is done, in which case ErrLockNotAcquired is returned. Bound the wait
+// with a context deadline.
+func (r *RedisClient) AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	ctx, span := r.startSpan(ctx, "redis.acquire_lock")
+	defer span.End()
+
+	owner := uuid.New().String()
+
+	for {
+		start := time.Now()
+		acquired, err := r.client.SetNX(ctx, "lock:"+key, owner, ttl).Result()
+		duration := time.Since(start).Seconds()
+
+		status := "success"
+		if err != nil && ctx.Err() == nil {
+			status = "error"
+			tracing.RecordError(span, err)
+			r.logger.WithContext(ctx).WithError(err).Error("redis acquire lock failed")
+		}
+
+		r.metrics.DBQueries.WithLabelValues("redis_acquire_lock", status).Inc()
+		r.metrics.DBQueryDuration.WithLabelValues("redis_acquire_lock").Observe(duration)
+
+		if err == nil && acquired {
+			return &Lock{Key: key, owner: owner}, nil
+		}
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrLockNotAcquired
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// ReleaseLock releases lock if it is still held by its owner. Releasing an
+// expired lock is not an error.
+func (r *RedisClient) ReleaseLock(ctx context.Context, lock *Lock) error {
+	ctx, span := r.startSpan(ctx, "redis.release_lock")
+	defer span.End()
+
+	start := time.Now()
+	err := releaseLockScript.Run(ctx, r.client, []string{"lock:" + lock.Key}, lock.owner).Err()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis release lock failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_release_lock", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_release_lock").Observe(duration)
+
+	return err
+}
+
+// ClaimLease takes or renews the lease on key for owner, reporting whether
+// owner holds it. Unlike a lock the lease is meant to be held across many
+// operations, e.g. by the leader of a group of instances, and is renewed
+// well before ttl runs out.
+func (r *RedisClient) ClaimLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	ctx, span := r.startSpan(ctx, "redis.claim_lease")
+	defer span.End()
+
+	start := time.Now()
+	held, err := claimLeaseScript.Run(ctx, r.client, []string{"lease:" + key}, owner, ttl.Milliseconds()).Int64()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis claim lease failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_claim_lease", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_claim_lease").Observe(duration)
+
+	return held == 1, err
+}
+
+// ReleaseLease gives up the lease on key if owner still holds it.
+func (r *RedisClient) ReleaseLease(ctx context.Context, key, owner string) error {
+	ctx, span := r.startSpan(ctx, "redis.release_lease")
+	defer span.End()
+
+	start := time.Now()
+	err := releaseLockScript.Run(ctx, r.client, []string{"lease:" + key}, owner).Err()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis release lease failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_release_lease", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_release_lease").Observe(duration)
+
+	return err
+}
+
+// Publish sends message to the subscribers of channel on any Redis
+// client. Messages are not kept for subscribers that join later.
+func (r *RedisClient) Publish(ctx context.Context, channel string, message any) error {
+	ctx, span := r.startSpan(ctx, "redis.publish")
+	defer span.End()
+
+	start := time.Now()
+	err := r.client.Publish(ctx, channel, message).Err()
+	duration := time.Since(start).Seconds()
+
+	status := "success"
+	if err != nil {
+		status = "error"
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis publish failed")
+	}
+
+	r.metrics.DBQueries.WithLabelValues("redis_publish", status).Inc()
+	r.metrics.DBQueryDuration.WithLabelValues("redis_publish").Observe(duration)
+
+	return err
+}
+
+// Subscribe subscribes to channels on a connection of its own, once the
+// subscription is confirmed. The caller must close the returned PubSub.
+func (r *RedisClient) Subscribe(ctx context.Context, channels ...string) (*redis.PubSub, error) {
+	ctx, span := r.startSpan(ctx, "redis.subscribe")
+	defer span.End()
+
+	pubsub := r.client.Subscribe(ctx, channels...)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		tracing.RecordError(span, err)
+		r.logger.WithContext(ctx).WithError(err).Error("redis subscribe failed")
+		return nil, err
+	}
+
+	return pubsub, nil
+}
+
+func (r *RedisClient) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return r.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemRedis))
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/dmehra2102/booking-system/internal/common/resilience"
+	"github.com/redis/go-redis/v9"
+)
+
+// breakerHook runs every Redis command through a circuit breaker. Misses
+// and errors returned by Redis itself (wrong type, script errors) do not
+// count as failures.
+type breakerHook struct {
+	breaker *resilience.Breaker
+}
+
+func isRedisFailure(err error) bool {
+	if errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var redisErr redis.Error
+	return !errors.As(err, &redisErr)
+}
+
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := h.breaker.Execute(ctx, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+		if errors.Is(err, resilience.ErrOpen) {
+			// Commands report their own error, not the hook's
+			cmd.SetErr(err)
+		}
+		return err
+	}
+}
+
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := h.breaker.Execute(ctx, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+		if errors.Is(err, resilience.ErrOpen) {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replica is a read-only copy of the primary that reads are spread over.
+type replica struct {
+	name    string
+	db      *sql.DB
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+type primaryKey struct{}
+
+// UsePrimary returns a context whose reads go to the primary rather than a
+// replica, for reads that must see the writes made before them.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// ConnectReplicas opens the read replicas at urls. Query and QueryRow are
+// then spread over the healthy replicas round-robin, except in a
+// transaction or with a context from UsePrimary; Exec and transactions
+// always run on the primary. Replicas are pinged every checkInterval until
+// Close. One that fails a ping or a read is skipped until it answers a
+// ping again, and reads fall back to the primary while none is healthy.
+// Their pools are reported as "replica_<n>" in metrics.DBConnections.
+func (p *PostgresDB) ConnectReplicas(urls []string, checkInterval time.Duration) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	for i, url := range urls {
+		pool, db, err := openPool(url)
+		if err != nil {
+			return fmt.Errorf("failed to open postgres replica connection: %w", err)
+		}
+
+		r := &replica{name: strconv.Itoa(i), db: db, pool: pool}
+		p.metrics.DBConnections.Add("replica_"+r.name, poolStats(pool))
+
+		ctx, cancel := context.WithTimeout(context.Background(), checkInterval)
+		if err := db.PingContext(ctx); err != nil {
+			p.logger.With("replica", r.name).WithError(err).Warn("postgres replica is unavailable, reading from the primary")
+		} else {
+			r.healthy.Store(true)
+		}
+		cancel()
+
+		p.replicas = append(p.replicas, r)
+	}
+
+	p.stopReplicas = make(chan struct{})
+	go p.checkReplicas(checkInterval)
+
+	return nil
+}
+
+// ReplicaHealth fails when replicas are connected but none is healthy.
+// Reads still work on the primary then, so it suits an optional check.
+func (p *PostgresDB) ReplicaHealth(ctx context.Context) error {
+	if len(p.replicas) == 0 {
+		return nil
+	}
+	for _, r := range p.replicas {
+		if r.healthy.Load() {
+			return nil
+		}
+	}
+	return errors.New("no postgres replica is healthy")
+}
+
+func (p *PostgresDB) checkReplicas(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopReplicas:
+			return
+		case <-ticker.C:
+		}
+
+		for _, r := range p.replicas {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := r.db.PingContext(ctx)
+			cancel()
+
+			healthy := err == nil
+			if r.healthy.Swap(healthy) == healthy {
+				continue
+			}
+			if healthy {
+				p.logger.With("replica", r.name).Info("postgres replica recovered")
+			} else {
+				p.logger.With("replica", r.name).WithError(err).Warn("postgres replica failed its health check, reading from the primary")
+			}
+		}
+	}
+}
+
+// reader returns the replica the next read with ctx goes to, or nil when
+// it goes to the primary.
+func (p *PostgresDB) reader(ctx context.Context) *replica {
+	if len(p.replicas) == 0 {
+		return nil
+	}
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return nil
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return nil
+	}
+
+	n := uint64(len(p.replicas))
+	start := p.nextReplica.Add(1)
+	for i := range n {
+		if r := p.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// onReplica runs read on a replica when ctx may read from one. It reports
+// false when the read is left to the primary: without a healthy replica,
+// or when the replica failed, which is then skipped until it recovers.
+func (p *PostgresDB) onReplica(ctx context.Context, read func(db *sql.DB) error) bool {
+	r := p.reader(ctx)
+	if r == nil {
+		return false
+	}
+
+	err := read(r.db)
+	if err == nil || ctx.Err() != nil || !isFailure(err) {
+		return true
+	}
+
+	if r.healthy.CompareAndSwap(true, false) {
+		p.logger.WithContext(ctx).With("replica", r.name).WithError(err).Warn("postgres replica failed, reading from the primary")
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+)
+
+type txKey struct{}
+
+// conn is what statements run on: the database, or a transaction.
+type conn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// WithTx runs fn in a transaction, which is committed if fn returns nil and
+// rolled back if it returns an error or panics. Query, QueryRow and Exec
+// with the context passed to fn run in the transaction, so repositories
+// need no changes to take part in it:
+//
+//	err := db.WithTx(ctx, func(ctx context.Context) error {
+//		if err := bookings.Create(ctx, booking); err != nil {
+//			return err
+//		}
+//		return waitlist.ClaimOffers(ctx, userID, resourceID, start, end)
+//	})
+//
+// A WithTx inside fn joins the transaction already running instead of
+// starting another one.
+func (p *PostgresDB) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := p.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// conn returns the transaction of ctx, if any, or the database.
+func (p *PostgresDB) conn(ctx context.Context) conn {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return p.db
+}
+
+// retryPolicy returns the policy statements run with. Statements in a
+// transaction are never retried: after an error Postgres aborts the
+// transaction, so only running all of it again could succeed.
+func (p *PostgresDB) retryPolicy(ctx context.Context) retry.Policy {
+	policy := p.retry
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		policy.MaxAttempts = 1
+	}
+	return policy
+}
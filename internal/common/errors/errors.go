@@ -16,8 +16,33 @@ const (
 	ErrorTypeForbidden    ErrorType = "FORBIDDEN"
 	ErrorTypeInternal     ErrorType = "INTERNAL_ERROR"
 	ErrorTypeExternal     ErrorType = "EXTERNAL_ERROR"
+	ErrorTypeRateLimited  ErrorType = "RATE_LIMITED"
+	// ErrorTypePreconditionFailed is returned when the If-Match version of
+	// a request no longer matches the resource.
+	ErrorTypePreconditionFailed ErrorType = "PRECONDITION_FAILED"
+	// ErrorTypeTimeout is returned when a request is not served in time.
+	ErrorTypeTimeout ErrorType = "TIMEOUT"
+	// ErrorTypePayloadTooLarge is returned for request bodies over the
+	// size limit.
+	ErrorTypePayloadTooLarge ErrorType = "PAYLOAD_TOO_LARGE"
 )
 
+// statusByType is the HTTP status of each error type, for AppErrors built
+// without a Code.
+var statusByType = map[ErrorType]int{
+	ErrorTypeValidation:         http.StatusBadRequest,
+	ErrorTypeNotFound:           http.StatusNotFound,
+	ErrorTypeConfict:            http.StatusConflict,
+	ErrorTypeUnauthorized:       http.StatusUnauthorized,
+	ErrorTypeForbidden:          http.StatusForbidden,
+	ErrorTypeInternal:           http.StatusInternalServerError,
+	ErrorTypeExternal:           http.StatusBadGateway,
+	ErrorTypeRateLimited:        http.StatusTooManyRequests,
+	ErrorTypePreconditionFailed: http.StatusPreconditionFailed,
+	ErrorTypeTimeout:            http.StatusGatewayTimeout,
+	ErrorTypePayloadTooLarge:    http.StatusRequestEntityTooLarge,
+}
+
 type AppError struct {
 	Type    ErrorType `json:"type"`
 	Message string    `json:"message"`
@@ -62,6 +87,14 @@ func NewConflictError(message string) *AppError {
 	}
 }
 
+func NewPreconditionFailedError(message string) *AppError {
+	return &AppError{
+		Type:    ErrorTypePreconditionFailed,
+		Message: message,
+		Code:    http.StatusPreconditionFailed,
+	}
+}
+
 func NewUnauthorizedError(message string) *AppError {
 	return &AppError{
 		Type:    ErrorTypeUnauthorized,
@@ -96,6 +129,31 @@ func NewExternalError(service, message string, err error) *AppError {
 	}
 }
 
+func NewRateLimitError(message string) *AppError {
+	return &AppError{
+		Type:    ErrorTypeRateLimited,
+		Message: message,
+		Code:    http.StatusTooManyRequests,
+	}
+}
+
+func NewTimeoutError(message string, err error) *AppError {
+	return &AppError{
+		Type:    ErrorTypeTimeout,
+		Message: message,
+		Code:    http.StatusGatewayTimeout,
+		Err:     err,
+	}
+}
+
+func NewPayloadTooLargeError(message string) *AppError {
+	return &AppError{
+		Type:    ErrorTypePayloadTooLarge,
+		Message: message,
+		Code:    http.StatusRequestEntityTooLarge,
+	}
+}
+
 var (
 	ErrInvalidInput       = errors.New("invalid input")
 	ErrResourceNotFound   = errors.New("resource not found")
@@ -118,3 +176,36 @@ func GetAppError(err error) *AppError {
 	}
 	return NewInternalError("Unknown error occurred", err)
 }
+
+// HTTPStatus returns the status err is answered with: the Code of an
+// AppError, or the status of its type when it has none. Errors that are
+// not AppErrors are internal.
+func HTTPStatus(err error) int {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return http.StatusInternalServerError
+	}
+	if appErr.Code != 0 {
+		return appErr.Code
+	}
+	if status, ok := statusByType[appErr.Type]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// IsTransient reports whether retrying the operation that failed with err
+// may succeed. Errors describing a problem with the request itself are
+// permanent; internal, external and unclassified errors are not.
+func IsTransient(err error) bool {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return true
+	}
+
+	switch appErr.Type {
+	case ErrorTypeValidation, ErrorTypeNotFound, ErrorTypeConfict, ErrorTypePreconditionFailed, ErrorTypeUnauthorized, ErrorTypeForbidden, ErrorTypePayloadTooLarge:
+		return false
+	}
+	return true
+}
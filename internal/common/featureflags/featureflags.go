@@ -0,0 +1,226 @@
+// Package featureflags turns features on and off at runtime, for all
+// traffic or for some tenants and users, so they can be rolled out
+// gradually. Rules are read from a Provider and evaluated per request; the
+// flags of a request travel in its context.
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+)
+
+const refreshTimeout = 10 * time.Second
+
+// Flag names a feature.
+type Flag string
+
+// PricingEngine prices bookings from rate cards and promo codes. Bookings
+// of tenants and users it is off for are free, as before pricing existed.
+const PricingEngine Flag = "pricing_engine"
+
+// defaults are the values of the known flags where no rule applies, e.g.
+// for a flag missing from the provider or outside of a request.
+var defaults = map[Flag]bool{
+	PricingEngine: true,
+}
+
+// Default returns the value of flag when no rule applies. Unknown flags
+// are off.
+func Default(flag Flag) bool {
+	return defaults[flag]
+}
+
+// Rule decides a flag. A disabled rule turns the flag off for everyone
+// and an enabled rule without targets turns it on for everyone. With
+// targets, it is on for the listed tenants and users, and for Percentage
+// percent of the others, picked by a stable hash of tenant and user.
+type Rule struct {
+	Enabled    bool     `json:"enabled"`
+	Tenants    []string `json:"tenants,omitempty"`
+	Users      []string `json:"users,omitempty"`
+	Percentage int      `json:"percentage,omitempty"`
+}
+
+func (r Rule) targeted() bool {
+	return len(r.Tenants) > 0 || len(r.Users) > 0 || r.Percentage > 0
+}
+
+// Evaluate reports whether flag is on for a user of a tenant. userID is
+// empty for anonymous requests.
+func (r Rule) Evaluate(flag Flag, tenantID, userID string) bool {
+	if !r.Enabled {
+		return false
+	}
+	if !r.targeted() {
+		return true
+	}
+	if slices.Contains(r.Tenants, tenantID) || (userID != "" && slices.Contains(r.Users, userID)) {
+		return true
+	}
+	return r.Percentage > 0 && bucket(flag, tenantID, userID) < uint32(r.Percentage)
+}
+
+// bucket places a user in one of 100 buckets. Each flag buckets users
+// independently, so the same users are not always the first to get new
+// features.
+func bucket(flag Flag, tenantID, userID string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(string(flag) + ":" + tenantID + ":" + userID))
+	return h.Sum32() % 100
+}
+
+// ParseRules reads rules written either as a JSON object of rules keyed by
+// flag, e.g. {"pricing_engine":{"enabled":true,"percentage":25}}, or as
+// comma separated flag=true|false pairs turning flags on or off for
+// everyone. An empty string has no rules.
+func ParseRules(s string) (map[Flag]Rule, error) {
+	s = strings.TrimSpace(s)
+	rules := make(map[Flag]Rule)
+	if s == "" {
+		return rules, nil
+	}
+
+	if strings.HasPrefix(s, "{") {
+		if err := json.Unmarshal([]byte(s), &rules); err != nil {
+			return nil, fmt.Errorf("invalid feature flag rules: %w", err)
+		}
+		for flag, rule := range rules {
+			if rule.Percentage < 0 || rule.Percentage > 100 {
+				return nil, fmt.Errorf("percentage of %s must be between 0 and 100", flag)
+			}
+		}
+		return rules, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid feature flag %q, expected flag=true|false", pair)
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of feature flag %s: %w", name, err)
+		}
+		rules[Flag(name)] = Rule{Enabled: enabled}
+	}
+	return rules, nil
+}
+
+// Provider reads the rules of the flags from where they are managed.
+// Flags without a rule keep their default.
+type Provider interface {
+	Name() string
+	Rules(ctx context.Context) (map[Flag]Rule, error)
+}
+
+// Flags holds the rules last read from a Provider and keeps them current.
+type Flags struct {
+	provider Provider
+
+	mu    sync.RWMutex
+	rules map[Flag]Rule
+}
+
+func New(provider Provider) *Flags {
+	return &Flags{provider: provider, rules: make(map[Flag]Rule)}
+}
+
+// Refresh reads the rules again. On failure the previous rules are kept.
+func (f *Flags) Refresh(ctx context.Context) error {
+	rules, err := f.provider.Rules(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read feature flags from %s: %w", f.provider.Name(), err)
+	}
+
+	f.mu.Lock()
+	f.rules = rules
+	f.mu.Unlock()
+	return nil
+}
+
+// Watch refreshes the rules every interval in the background until the
+// returned function is called.
+func (f *Flags) Watch(interval time.Duration, log *logger.Logger) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	log = log.With("feature_flag_provider", f.provider.Name())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			refreshCtx, cancelRefresh := context.WithTimeout(ctx, refreshTimeout)
+			if err := f.Refresh(refreshCtx); err != nil && ctx.Err() == nil {
+				log.WithError(err).Warn("failed to refresh feature flags, keeping the previous rules")
+			}
+			cancelRefresh()
+		}
+	}()
+
+	return cancel
+}
+
+// Enabled reports whether flag is on for a user of a tenant.
+func (f *Flags) Enabled(flag Flag, tenantID, userID string) bool {
+	f.mu.RLock()
+	rule, ok := f.rules[flag]
+	f.mu.RUnlock()
+
+	if !ok {
+		return Default(flag)
+	}
+	return rule.Evaluate(flag, tenantID, userID)
+}
+
+// Evaluate returns the value of every known flag and every flag with a
+// rule for a user of a tenant.
+func (f *Flags) Evaluate(tenantID, userID string) Set {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	set := make(Set, len(defaults)+len(f.rules))
+	for flag, value := range defaults {
+		set[flag] = value
+	}
+	for flag, rule := range f.rules {
+		set[flag] = rule.Evaluate(flag, tenantID, userID)
+	}
+	return set
+}
+
+// Set holds the values of the flags for one request.
+type Set map[Flag]bool
+
+type contextKey struct{}
+
+// WithSet returns a context carrying the flags of a request.
+func WithSet(ctx context.Context, set Set) context.Context {
+	return context.WithValue(ctx, contextKey{}, set)
+}
+
+// Enabled reports whether flag is on in ctx. Contexts without flags, such
+// as those of event handlers and jobs, and flags the set lacks use the
+// default.
+func Enabled(ctx context.Context, flag Flag) bool {
+	if set, ok := ctx.Value(contextKey{}).(Set); ok {
+		if value, ok := set[flag]; ok {
+			return value
+		}
+	}
+	return Default(flag)
+}
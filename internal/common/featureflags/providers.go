@@ -0,0 +1,60 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/redis/go-redis/v9"
+)
+
+// EnvProvider reads the rules from a setting, such as FEATURE_FLAGS, in
+// the format of ParseRules. The setting is read again on every refresh, so
+// reloaded configuration applies without a restart.
+type EnvProvider struct {
+	rules func() string
+}
+
+func NewEnvProvider(rules func() string) *EnvProvider {
+	return &EnvProvider{rules: rules}
+}
+
+func (p *EnvProvider) Name() string {
+	return "env"
+}
+
+func (p *EnvProvider) Rules(context.Context) (map[Flag]Rule, error) {
+	return ParseRules(p.rules())
+}
+
+// RedisProvider reads the rules from a Redis key holding a JSON object of
+// rules keyed by flag, shared by all replicas and services. A missing key
+// has no rules.
+type RedisProvider struct {
+	client *database.RedisClient
+	key    string
+}
+
+func NewRedisProvider(client *database.RedisClient, key string) *RedisProvider {
+	return &RedisProvider{client: client, key: key}
+}
+
+func (p *RedisProvider) Name() string {
+	return "redis"
+}
+
+func (p *RedisProvider) Rules(ctx context.Context) (map[Flag]Rule, error) {
+	value, err := p.client.Get(ctx, p.key)
+	if errors.Is(err, redis.Nil) {
+		return make(map[Flag]Rule), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(value)
+}
+
+var (
+	_ Provider = (*EnvProvider)(nil)
+	_ Provider = (*RedisProvider)(nil)
+)
@@ -0,0 +1,146 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+)
+
+// UnleashProvider reads the rules from the client API of an Unleash
+// server. Each feature toggle becomes a rule:
+//
+//   - the "default" strategy turns the flag on for everyone
+//   - "userWithId" targets the users in its userIds parameter
+//   - "tenantWithId", a custom strategy, targets the tenants in its
+//     tenantIds parameter
+//   - "flexibleRollout" and "gradualRolloutUserId" target the percentage
+//     in their rollout or percentage parameter
+//
+// Other strategies and strategy constraints are not supported; a toggle
+// using only those is off.
+type UnleashProvider struct {
+	url     string
+	token   string
+	appName string
+	client  *httpclient.Client
+}
+
+func NewUnleashProvider(url, token, appName string, client *httpclient.Client) (*UnleashProvider, error) {
+	if url == "" || token == "" {
+		return nil, errors.New("UNLEASH_URL and UNLEASH_API_TOKEN are required for the unleash feature flag provider")
+	}
+
+	return &UnleashProvider{
+		url:     strings.TrimRight(url, "/"),
+		token:   token,
+		appName: appName,
+		client:  client,
+	}, nil
+}
+
+func (p *UnleashProvider) Name() string {
+	return "unleash"
+}
+
+type unleashStrategy struct {
+	Name       string            `json:"name"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+type unleashFeature struct {
+	Name       string            `json:"name"`
+	Enabled    bool              `json:"enabled"`
+	Strategies []unleashStrategy `json:"strategies"`
+}
+
+func (p *UnleashProvider) Rules(ctx context.Context) (map[Flag]Rule, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/api/client/features", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", p.token)
+	req.Header.Set("UNLEASH-APPNAME", p.appName)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unleash answered %s", resp.Status)
+	}
+
+	var toggles struct {
+		Features []unleashFeature `json:"features"`
+	}
+	if err := json.Unmarshal(body, &toggles); err != nil {
+		return nil, fmt.Errorf("invalid unleash response: %w", err)
+	}
+
+	rules := make(map[Flag]Rule, len(toggles.Features))
+	for _, feature := range toggles.Features {
+		rules[Flag(feature.Name)] = unleashRule(feature)
+	}
+	return rules, nil
+}
+
+// unleashRule converts the strategies of a toggle, any of which turns it
+// on, into a rule.
+func unleashRule(feature unleashFeature) Rule {
+	if !feature.Enabled {
+		return Rule{}
+	}
+	// Unleash treats a toggle without strategies as on for everyone
+	if len(feature.Strategies) == 0 {
+		return Rule{Enabled: true}
+	}
+
+	rule := Rule{Enabled: true}
+	for _, strategy := range feature.Strategies {
+		switch strategy.Name {
+		case "default":
+			return Rule{Enabled: true}
+		case "userWithId":
+			rule.Users = append(rule.Users, splitIDs(strategy.Parameters["userIds"])...)
+		case "tenantWithId":
+			rule.Tenants = append(rule.Tenants, splitIDs(strategy.Parameters["tenantIds"])...)
+		case "flexibleRollout", "gradualRolloutUserId":
+			percentage := strategy.Parameters["rollout"]
+			if percentage == "" {
+				percentage = strategy.Parameters["percentage"]
+			}
+			if n, err := strconv.Atoi(percentage); err == nil && n > 0 {
+				rule.Percentage = max(rule.Percentage, min(n, 100))
+			}
+		}
+	}
+
+	if !rule.targeted() {
+		return Rule{}
+	}
+	return rule
+}
+
+func splitIDs(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+var _ Provider = (*UnleashProvider)(nil)
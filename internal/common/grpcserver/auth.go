@@ -0,0 +1,126 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serviceTokenTTL bounds how long a captured service token can be replayed.
+const serviceTokenTTL = time.Minute
+
+// healthMethodPrefix names the methods of the standard health service,
+// which probes call without a token.
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// serviceClaims identify the service making a call, as Subject, and the
+// tenant it acts for. Each token is issued for one method, its Audience.
+type serviceClaims struct {
+	TenantID string `json:"tenant_id"`
+	jwt.RegisteredClaims
+}
+
+type callerKey struct{}
+
+// Caller returns the service that made the gRPC call being served.
+func Caller(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+// signServiceToken issues the token service sends to call method for
+// tenantID.
+func signServiceToken(secret, service, tenantID, method string, now time.Time) (string, error) {
+	claims := serviceClaims{
+		TenantID: tenantID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   service,
+			Audience:  jwt.ClaimStrings{method},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(serviceTokenTTL)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// authenticate verifies the service token of a call to method and returns
+// ctx acting for the tenant and caller named in it. The tenant is only
+// taken from the signed token, never from other metadata.
+func authenticate(ctx context.Context, secret, method string) (context.Context, error) {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing service token")
+	}
+	raw, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid service token")
+	}
+
+	claims := &serviceClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		return []byte(secret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(method),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Subject == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid service token")
+	}
+
+	if claims.TenantID != "" {
+		ctx = tenancy.WithID(ctx, claims.TenantID)
+	}
+	return context.WithValue(ctx, callerKey{}, claims.Subject), nil
+}
+
+// authInterceptor refuses calls without a valid service token, except to
+// the health service.
+func authInterceptor(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, secret, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuthInterceptor is authInterceptor for streaming calls.
+func streamAuthInterceptor(secret string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+			return handler(srv, stream)
+		}
+
+		if _, err := authenticate(stream.Context(), secret, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// serviceTokenInterceptor signs each call of service with a token for its
+// method and the tenant of its context.
+func serviceTokenInterceptor(service, secret string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		token, err := signServiceToken(secret, service, tenancy.ID(ctx), method, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to sign service token: %w", err)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,109 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testSecret = "test-service-secret-of-32-bytes!"
+	testMethod = "/booking.v1.BookingService/GetBooking"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	token := func(secret, tenantID, method string, issued time.Time) string {
+		t.Helper()
+		token, err := signServiceToken(secret, "graphql-gateway", tenantID, method, issued)
+		if err != nil {
+			t.Fatalf("signServiceToken() error = %v", err)
+		}
+		return token
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		method     string
+		md         metadata.MD
+		wantCode   codes.Code
+		wantTenant string
+	}{
+		{
+			name:       "valid token",
+			method:     testMethod,
+			md:         metadata.Pairs("authorization", "Bearer "+token(testSecret, "acme", testMethod, now)),
+			wantTenant: "acme",
+		},
+		{
+			name:   "tenant in metadata is ignored",
+			method: testMethod,
+			md: metadata.Pairs(
+				"authorization", "Bearer "+token(testSecret, "acme", testMethod, now),
+				tenancy.MetadataKey, "globex",
+			),
+			wantTenant: "acme",
+		},
+		{
+			name:     "missing token",
+			method:   testMethod,
+			md:       metadata.Pairs(tenancy.MetadataKey, "acme"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong secret",
+			method:   testMethod,
+			md:       metadata.Pairs("authorization", "Bearer "+token("another-secret-of-at-least-32-bytes", "acme", testMethod, now)),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "token for another method",
+			method:   testMethod,
+			md:       metadata.Pairs("authorization", "Bearer "+token(testSecret, "acme", "/user.v1.UserService/GetUser", now)),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "expired token",
+			method:   testMethod,
+			md:       metadata.Pairs("authorization", "Bearer "+token(testSecret, "acme", testMethod, now.Add(-2*serviceTokenTTL))),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:   "health check without token",
+			method: "/grpc.health.v1.Health/Check",
+			md:     metadata.MD{},
+		},
+	}
+
+	interceptor := authInterceptor(testSecret)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenantID, caller string
+			handler := func(ctx context.Context, _ any) (any, error) {
+				tenantID, caller = tenancy.ID(ctx), Caller(ctx)
+				return nil, nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %s, want %s", code, tt.wantCode)
+			}
+			if tt.wantTenant == "" {
+				return
+			}
+			if tenantID != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", tenantID, tt.wantTenant)
+			}
+			if caller != "graphql-gateway" {
+				t.Errorf("caller = %q, want %q", caller, "graphql-gateway")
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// New returns a gRPC server with tracing, panic recovery, request logging
+// and the standard health service registered. Calls other than health
+// checks must carry a service token signed with secret, as those of Dial
+// do, and act for the tenant named in it.
+func New(log *logger.Logger, secret string) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			recoveryInterceptor(log),
+			loggingInterceptor(log),
+			authInterceptor(secret),
+		),
+		grpc.StreamInterceptor(streamAuthInterceptor(secret)),
+	)
+
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	return server
+}
+
+// Serve listens on port and serves in the background. Stop the server with
+// GracefulStop during shutdown.
+func Serve(server *grpc.Server, port string, log *logger.Logger) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on grpc port %s: %w", port, err)
+	}
+
+	go func() {
+		log.Info(fmt.Sprintf("🚀 Starting gRPC server on port %s", port))
+		if err := server.Serve(listener); err != nil {
+			log.WithError(err).Error("grpc server stopped")
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops server gracefully, waiting for pending RPCs, and forcibly
+// closes remaining connections once ctx is done.
+func Stop(ctx context.Context, server *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
+
+// Dial opens a traced client connection to target for service. Each call
+// carries a service token signed with secret that names service and the
+// tenant of the call's context. Connections are established lazily on the
+// first call.
+func Dial(target, service, secret string) (*grpc.ClientConn, error) {
+	return grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(serviceTokenInterceptor(service, secret)),
+	)
+}
+
+// ToStatus converts an application error into a gRPC status error so
+// clients see the same semantics as the REST API.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	appErr := errors.GetAppError(err)
+
+	code := codes.Internal
+	switch appErr.Type {
+	case errors.ErrorTypeValidation:
+		code = codes.InvalidArgument
+	case errors.ErrorTypeNotFound:
+		code = codes.NotFound
+	case errors.ErrorTypeConfict:
+		code = codes.AlreadyExists
+	case errors.ErrorTypePreconditionFailed:
+		code = codes.FailedPrecondition
+	case errors.ErrorTypeUnauthorized:
+		code = codes.Unauthenticated
+	case errors.ErrorTypeForbidden:
+		code = codes.PermissionDenied
+	case errors.ErrorTypeRateLimited:
+		code = codes.ResourceExhausted
+	case errors.ErrorTypeExternal:
+		code = codes.Unavailable
+	}
+
+	return status.Error(code, appErr.Message)
+}
+
+func recoveryInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithContext(ctx).WithFields(map[string]interface{}{
+					"method": info.FullMethod,
+					"panic":  fmt.Sprintf("%v", r),
+					"stack":  string(debug.Stack()),
+				}).Error("grpc handler panicked")
+
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func loggingInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		entry := log.WithContext(ctx).WithFields(map[string]interface{}{
+			"method":      info.FullMethod,
+			"code":        status.Code(err).String(),
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		if err != nil {
+			entry.WithError(err).Warn("grpc request failed")
+		} else {
+			entry.Debug("grpc request completed")
+		}
+
+		return resp, err
+	}
+}
+
+// RequireUUID returns an InvalidArgument status unless value is a UUID.
+func RequireUUID(field, value string) error {
+	if err := uuid.Validate(value); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid %s: must be a UUID", field)
+	}
+	return nil
+}
+
+// HealthCheck returns a check that asks the service behind conn for its
+// status through the standard gRPC health service.
+func HealthCheck(conn *grpc.ClientConn) func(ctx context.Context) error {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("service is %s", resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+// Package health aggregates dependency checks for the /health and /ready
+// endpoints.
+//
+// /health reports every check with its latency and always answers 200 while
+// the process can serve requests, so a failing dependency never gets the
+// service restarted. /ready answers 503 when any critical check fails so
+// that traffic is routed elsewhere until the dependency recovers.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/buildinfo"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultTimeout = 3 * time.Second
+
+// Check reports whether a dependency is usable. It should honour ctx
+// cancellation.
+type Check func(ctx context.Context) error
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the aggregated outcome of all checks. Status is unhealthy when
+// a critical check fails and degraded when only optional ones do.
+type Report struct {
+	Status  Status            `json:"status"`
+	Service string            `json:"service"`
+	Version string            `json:"version"`
+	Checks  map[string]Result `json:"checks"`
+}
+
+type check struct {
+	name     string
+	fn       Check
+	critical bool
+}
+
+// Registry holds the named checks of a service.
+type Registry struct {
+	service string
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+}
+
+func New(service string) *Registry {
+	return &Registry{service: service, timeout: defaultTimeout}
+}
+
+// Register adds a critical check; the service is not ready while it fails.
+func (r *Registry) Register(name string, fn Check) {
+	r.add(check{name: name, fn: fn, critical: true})
+}
+
+// RegisterOptional adds a check whose failure only degrades the service,
+// e.g. a downstream service with a local fallback.
+func (r *Registry) RegisterOptional(name string, fn Check) {
+	r.add(check{name: name, fn: fn})
+}
+
+func (r *Registry) add(c check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, c)
+}
+
+// Run executes all checks concurrently, each bounded by the registry
+// timeout.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:  StatusHealthy,
+		Service: r.service,
+		Version: buildinfo.Version,
+		Checks:  make(map[string]Result, len(checks)),
+	}
+	for i, c := range checks {
+		result := results[i]
+		report.Checks[c.name] = result
+
+		if result.Status == StatusDown {
+			if c.critical {
+				report.Status = StatusUnhealthy
+			} else if report.Status == StatusHealthy {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+
+	result := Result{
+		Status:    StatusUp,
+		Critical:  c.critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Health serves the liveness report.
+func (r *Registry) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, r.Run(c.Request.Context()))
+}
+
+// Ready serves the readiness report, answering 503 while a critical check
+// fails.
+func (r *Registry) Ready(c *gin.Context) {
+	report := r.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == StatusUnhealthy {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
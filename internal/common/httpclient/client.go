@@ -0,0 +1,281 @@
+// Package httpclient makes outbound HTTP calls to third parties with
+// tracing, retries, a circuit breaker per host and metrics.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/resilience"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// maxDrain bounds how much of a discarded response is read so its
+// connection can be reused.
+const maxDrain = 64 << 10
+
+// Config configures a Client.
+type Config struct {
+	// Timeout bounds each attempt, from sending the request to closing the
+	// response body, unless HostTimeouts has an entry for the host.
+	Timeout      time.Duration
+	HostTimeouts map[string]time.Duration
+
+	// Retry applies to idempotent requests and those with an
+	// Idempotency-Key header only. Requests are retried after network
+	// errors and 429, 502, 503 and 504 responses.
+	Retry retry.Policy
+
+	// Breaker configures the circuit breaker kept for every host. Network
+	// errors and 5xx responses count as failures.
+	Breaker resilience.BreakerConfig
+
+	// DisableRedirects returns redirect responses to the caller instead of
+	// following them.
+	DisableRedirects bool
+
+	// Streaming bounds attempts by Timeout only until the response headers
+	// arrive, so bodies such as event streams and large downloads are read
+	// for as long as they last. HostTimeouts do not apply.
+	Streaming bool
+}
+
+// Client sends requests for one integration, named in its spans, metrics
+// and breakers.
+type Client struct {
+	name    string
+	client  *http.Client
+	config  Config
+	retry   retry.Policy
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+
+	mu       sync.Mutex
+	breakers map[string]*resilience.Breaker
+}
+
+// New returns a Client named name. Requests are traced as client spans
+// with the trace context propagated to the host.
+func New(name string, config Config, logger *logger.Logger, metrics *metrics.Metrics) *Client {
+	base := http.DefaultTransport
+	if config.Streaming {
+		// Idle connections are still closed after the default timeout
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.ResponseHeaderTimeout = config.Timeout
+		base = t
+	}
+
+	client := &http.Client{
+		Transport: otelhttp.NewTransport(base,
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return name + " " + r.Method
+			}),
+		),
+	}
+	if config.DisableRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	policy := config.Retry
+	policy.Retryable = isRetryable
+	config.Breaker.IsFailure = isFailure
+
+	return &Client{
+		name:     name,
+		client:   client,
+		config:   config,
+		retry:    policy,
+		logger:   logger,
+		metrics:  metrics,
+		breakers: make(map[string]*resilience.Breaker),
+	}
+}
+
+// Timeout returns the time an attempt on host may take.
+func (c *Client) Timeout(host string) time.Duration {
+	if timeout, ok := c.config.HostTimeouts[host]; ok {
+		return timeout
+	}
+	return c.config.Timeout
+}
+
+// Do sends req and returns the response of the last attempt. A non-2xx
+// status is not an error; the caller must close the response body.
+// Requests fail fast with resilience.ErrOpen while the host's breaker is
+// open.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	defer timing.Track(ctx, "http")()
+
+	host := strings.ToLower(req.URL.Host)
+	breaker := c.breaker(host)
+
+	policy := c.retry
+	if !isIdempotent(req) {
+		policy.MaxAttempts = 1
+	}
+
+	start := time.Now()
+	var resp *http.Response
+	attempt := 0
+	err := policy.Do(ctx, func(ctx context.Context) error {
+		if resp != nil {
+			discard(resp)
+			resp = nil
+		}
+
+		attempt++
+		r := req.Clone(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return retry.Permanent(err)
+			}
+			r.Body = body
+		}
+
+		return breaker.Execute(ctx, func(ctx context.Context) error {
+			var err error
+			resp, err = c.send(r.WithContext(ctx), host)
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+				return &statusError{code: resp.StatusCode}
+			}
+			return nil
+		})
+	})
+	duration := time.Since(start).Seconds()
+
+	var statusErr *statusError
+	if err != nil && !errors.As(err, &statusErr) {
+		c.metrics.HTTPClientRequests.WithLabelValues(c.name, host, "error").Inc()
+		c.metrics.HTTPClientDuration.WithLabelValues(c.name, host).Observe(duration)
+		c.logger.WithContext(ctx).With("client", c.name).With("host", host).WithError(err).Warn("outbound http request failed")
+		return nil, err
+	}
+
+	c.metrics.HTTPClientRequests.WithLabelValues(c.name, host, strconv.Itoa(resp.StatusCode)).Inc()
+	c.metrics.HTTPClientDuration.WithLabelValues(c.name, host).Observe(duration)
+
+	return resp, nil
+}
+
+// send makes one attempt bounded by the host's timeout. The timeout keeps
+// running until the response body is closed, unless the client streams
+// bodies and its transport bounds the wait for the headers only.
+func (c *Client) send(req *http.Request, host string) (*http.Response, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if c.config.Streaming {
+		ctx, cancel = context.WithCancel(req.Context())
+	} else {
+		ctx, cancel = context.WithTimeout(req.Context(), c.Timeout(host))
+	}
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (c *Client) breaker(host string) *resilience.Breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker, ok := c.breakers[host]
+	if !ok {
+		breaker = resilience.NewBreaker(fmt.Sprintf("http:%s:%s", c.name, host), c.config.Breaker, c.logger, c.metrics)
+		c.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// statusError carries a response status worth retrying through the retry
+// policy and breaker. The response itself is kept by Do.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return "unexpected status " + strconv.Itoa(e.code)
+}
+
+// isRetryable reports whether an attempt may succeed when sent again.
+func isRetryable(err error) bool {
+	if errors.Is(err, resilience.ErrOpen) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.code {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// isFailure reports whether err indicates a problem with the host rather
+// than with the request, for the circuit breaker.
+func isFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500
+	}
+	return true
+}
+
+// isIdempotent reports whether req can be sent again without side effects
+// and, if it has a body, whether that body can be read again.
+func isIdempotent(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch strings.ToUpper(req.Method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrain))
+	resp.Body.Close()
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,33 @@
+package httpclient
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseHostTimeouts parses a comma separated list of "host=duration"
+// pairs, e.g. "api.stripe.com=30s,hooks.slack.com=5s".
+func ParseHostTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		host, timeout, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(host) == "" {
+			return nil, fmt.Errorf("invalid host timeout %q: expected host=duration", part)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(timeout))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid host timeout %q: bad duration", part)
+		}
+
+		timeouts[strings.ToLower(strings.TrimSpace(host))] = d
+	}
+
+	return timeouts, nil
+}
@@ -0,0 +1,176 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+// EnsureTopics checks that topics exist on brokers, creating the missing
+// ones when create is set and failing otherwise. Existing topics are
+// never altered; settings that differ from topics are only logged, as
+// changing partitions or retention of a live topic needs planning.
+func EnsureTopics(ctx context.Context, brokers []string, topics []Topic, create bool, logger *logger.Logger) error {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	client := &kafka.Client{Addr: kafka.TCP(brokers...), Timeout: 30 * time.Second}
+
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Name
+	}
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: names})
+	if err != nil {
+		return fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+
+	partitions := make(map[string]int, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		if topic.Error == nil {
+			partitions[topic.Name] = len(topic.Partitions)
+		}
+	}
+
+	var missing, existing []Topic
+	for _, topic := range topics {
+		if _, ok := partitions[topic.Name]; ok {
+			existing = append(existing, topic)
+		} else {
+			missing = append(missing, topic)
+		}
+	}
+
+	if len(missing) > 0 {
+		if !create {
+			missingNames := make([]string, len(missing))
+			for i, topic := range missing {
+				missingNames[i] = topic.Name
+			}
+			return fmt.Errorf("missing topics: %s", strings.Join(missingNames, ", "))
+		}
+		if err := createTopics(ctx, client, missing, logger); err != nil {
+			return err
+		}
+	}
+
+	return checkTopics(ctx, client, existing, partitions, logger)
+}
+
+func createTopics(ctx context.Context, client *kafka.Client, topics []Topic, logger *logger.Logger) error {
+	configs := make([]kafka.TopicConfig, len(topics))
+	for i, topic := range topics {
+		configs[i] = kafka.TopicConfig{
+			Topic:             topic.Name,
+			NumPartitions:     orUnset(topic.Partitions),
+			ReplicationFactor: orUnset(topic.ReplicationFactor),
+			ConfigEntries:     configEntries(topic),
+		}
+	}
+
+	resp, err := client.CreateTopics(ctx, &kafka.CreateTopicsRequest{Topics: configs})
+	if err != nil {
+		return fmt.Errorf("failed to create topics: %w", err)
+	}
+
+	var errs []error
+	for _, topic := range topics {
+		err := resp.Errors[topic.Name]
+		switch {
+		case err == nil:
+			logger.With("topic", topic.Name).Info("kafka topic created")
+		case errors.Is(err, kafka.TopicAlreadyExists):
+			// Created by another instance starting at the same time
+		default:
+			errs = append(errs, fmt.Errorf("failed to create topic %s: %w", topic.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkTopics logs the settings of existing topics that differ from the
+// configured ones.
+func checkTopics(ctx context.Context, client *kafka.Client, topics []Topic, partitions map[string]int, logger *logger.Logger) error {
+	if len(topics) == 0 {
+		return nil
+	}
+
+	resources := make([]kafka.DescribeConfigRequestResource, len(topics))
+	for i, topic := range topics {
+		resources[i] = kafka.DescribeConfigRequestResource{
+			ResourceType: kafka.ResourceTypeTopic,
+			ResourceName: topic.Name,
+			ConfigNames:  []string{"retention.ms", "cleanup.policy"},
+		}
+	}
+
+	resp, err := client.DescribeConfigs(ctx, &kafka.DescribeConfigsRequest{Resources: resources})
+	if err != nil {
+		return fmt.Errorf("failed to describe topics: %w", err)
+	}
+
+	actual := make(map[string]map[string]string, len(resp.Resources))
+	for _, resource := range resp.Resources {
+		if resource.Error != nil {
+			continue
+		}
+		entries := make(map[string]string, len(resource.ConfigEntries))
+		for _, entry := range resource.ConfigEntries {
+			entries[entry.ConfigName] = entry.ConfigValue
+		}
+		actual[resource.ResourceName] = entries
+	}
+
+	for _, topic := range topics {
+		log := logger.With("topic", topic.Name)
+
+		if topic.Partitions > 0 && partitions[topic.Name] != topic.Partitions {
+			log.With("configured", strconv.Itoa(topic.Partitions)).
+				With("actual", strconv.Itoa(partitions[topic.Name])).
+				Warn("kafka topic has a different number of partitions")
+		}
+
+		entries, ok := actual[topic.Name]
+		if !ok {
+			continue
+		}
+		for _, entry := range configEntries(topic) {
+			if value := entries[entry.ConfigName]; value != entry.ConfigValue {
+				log.With("config", entry.ConfigName).
+					With("configured", entry.ConfigValue).
+					With("actual", value).
+					Warn("kafka topic has a different configuration")
+			}
+		}
+	}
+
+	return nil
+}
+
+// configEntries returns the topic level settings of topic that are set.
+func configEntries(topic Topic) []kafka.ConfigEntry {
+	var entries []kafka.ConfigEntry
+	if topic.Retention > 0 {
+		entries = append(entries, kafka.ConfigEntry{ConfigName: "retention.ms", ConfigValue: strconv.FormatInt(topic.Retention.Milliseconds(), 10)})
+	}
+	if topic.CleanupPolicy != "" {
+		entries = append(entries, kafka.ConfigEntry{ConfigName: "cleanup.policy", ConfigValue: topic.CleanupPolicy})
+	}
+	return entries
+}
+
+// orUnset maps zero settings to -1, which leaves them to the brokers.
+func orUnset(n int) int {
+	if n > 0 {
+		return n
+	}
+	return -1
+}
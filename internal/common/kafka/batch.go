@@ -0,0 +1,182 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/segmentio/kafka-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrProducerClosed is returned by ProduceAsync once Close was called.
+var ErrProducerClosed = errors.New("kafka producer is closed")
+
+// Message is an event published by ProduceBatch.
+type Message struct {
+	EventType events.EventType
+	Key       string
+	Value     any
+}
+
+// DeliveryFunc is called once a message queued by ProduceAsync was written,
+// with the error that made the write fail, if any.
+type DeliveryFunc func(err error)
+
+// pending is a message queued by ProduceAsync.
+type pending struct {
+	msg      kafka.Message
+	delivery DeliveryFunc
+}
+
+// ProduceBatch publishes messages in a single write. Either all messages
+// are written or the batch fails; the messages of one key keep their
+// order.
+func (p *Producer) ProduceBatch(ctx context.Context, messages []Message) (err error) {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ctx, span := p.tracer.Start(ctx, "kafka.produce_batch", trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(
+		semconv.MessagingSystemKafka,
+		semconv.MessagingBatchMessageCount(len(messages)),
+	))
+	defer tracing.End(span, &err)
+	defer timing.Track(ctx, "kafka")()
+
+	msgs := make([]kafka.Message, 0, len(messages))
+	for _, m := range messages {
+		msg, err := p.message(ctx, m.EventType, m.Key, m.Value)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err := p.writeWithRetry(ctx, msgs...); err != nil {
+		p.countMessages(msgs, err)
+		p.logger.WithContext(ctx).WithError(err).With("messages", strconv.Itoa(len(msgs))).Error("failed to produce batch")
+		return fmt.Errorf("failed to produce batch of %d messages: %w", len(msgs), err)
+	}
+
+	p.countMessages(msgs, nil)
+	return nil
+}
+
+// StartAsync makes ProduceAsync queue up to queueSize messages and write
+// them in batches from the background. Close delivers what is still
+// queued.
+func (p *Producer) StartAsync(queueSize int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.queue != nil || p.closed {
+		return
+	}
+	p.queue = make(chan pending, queueSize)
+	p.sent = make(chan struct{})
+	go p.send()
+}
+
+// ProduceAsync queues an event of eventType and returns once it is queued;
+// delivery, which may be nil, is called from the background once it was
+// written. While the queue is full it blocks until there is room or ctx
+// is done. Only failures to queue the event are returned; without
+// StartAsync the event is written before it returns and the result goes
+// to delivery, or is returned when delivery is nil.
+func (p *Producer) ProduceAsync(ctx context.Context, eventType events.EventType, key string, value any, delivery DeliveryFunc) error {
+	p.mu.RLock()
+	queue := p.queue
+	p.mu.RUnlock()
+
+	if queue == nil {
+		err := p.Produce(ctx, eventType, key, value)
+		if delivery == nil {
+			return err
+		}
+		delivery(err)
+		return nil
+	}
+
+	msg, err := p.message(ctx, eventType, key, value)
+	if err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrProducerClosed
+	}
+
+	select {
+	case p.queue <- pending{msg: msg, delivery: delivery}:
+	default:
+		p.metrics.MessageErrors.WithLabelValues(msg.Topic, "queue_full").Inc()
+		select {
+		case p.queue <- pending{msg: msg, delivery: delivery}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	p.metrics.ProducerQueueSize.Set(float64(len(p.queue)))
+
+	return nil
+}
+
+// send writes the queued messages until the queue is closed, taking as
+// many at once as the writer batches.
+func (p *Producer) send() {
+	defer close(p.sent)
+
+	for first := range p.queue {
+		batch := []pending{first}
+	collect:
+		for len(batch) < p.writer.BatchSize {
+			select {
+			case next, ok := <-p.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, next)
+			default:
+				break collect
+			}
+		}
+		p.metrics.ProducerQueueSize.Set(float64(len(p.queue)))
+
+		msgs := make([]kafka.Message, len(batch))
+		for i, pending := range batch {
+			msgs[i] = pending.msg
+		}
+
+		// Written after the callers returned, so their contexts may be done
+		err := p.writeWithRetry(context.Background(), msgs...)
+		p.countMessages(msgs, err)
+
+		for _, pending := range batch {
+			if pending.delivery != nil {
+				pending.delivery(err)
+			} else if err != nil {
+				p.logger.WithError(err).With("topic", pending.msg.Topic).With("key", string(pending.msg.Key)).Error("failed to deliver message")
+			}
+		}
+	}
+}
+
+// countMessages records msgs as produced, or as failed when err is set.
+func (p *Producer) countMessages(msgs []kafka.Message, err error) {
+	for _, msg := range msgs {
+		if err != nil {
+			p.metrics.MessageErrors.WithLabelValues(msg.Topic, "produce").Inc()
+		} else {
+			p.metrics.MessagesProduced.WithLabelValues(msg.Topic).Inc()
+		}
+	}
+}
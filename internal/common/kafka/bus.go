@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BusConfig holds the settings of the consumers a Bus subscribes, see
+// ConsumerConfig.
+type BusConfig struct {
+	Brokers     []string
+	Topics      *Topics
+	LagInterval time.Duration
+	Retry       retry.Policy
+}
+
+// Bus is the messagebus.MessageBus on Kafka. Events are published with
+// producer, which also dead-letters the messages subscriptions give up on.
+type Bus struct {
+	producer *Producer
+	config   BusConfig
+	logger   *logger.Logger
+	metrics  *metrics.Metrics
+	tracer   trace.Tracer
+}
+
+func NewBus(producer *Producer, config BusConfig, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) *Bus {
+	return &Bus{
+		producer: producer,
+		config:   config,
+		logger:   logger,
+		metrics:  metrics,
+		tracer:   tracer,
+	}
+}
+
+// Producer returns the producer events are published with.
+func (b *Bus) Producer() *Producer {
+	return b.producer
+}
+
+func (b *Bus) Publish(ctx context.Context, eventType events.EventType, key string, value any) error {
+	return b.producer.Produce(ctx, eventType, key, value)
+}
+
+// PublishAsync queues the event when the producer was started with
+// StartAsync and writes it right away otherwise.
+func (b *Bus) PublishAsync(ctx context.Context, eventType events.EventType, key string, value any, delivery func(error)) error {
+	return b.producer.ProduceAsync(ctx, eventType, key, value, delivery)
+}
+
+// Subscribe returns a consumer of the topics config.EventTypes are
+// published to.
+func (b *Bus) Subscribe(config messagebus.SubscriptionConfig, handler messagebus.Handler) messagebus.Subscription {
+	consumer := NewConsumer(b.config.Brokers, ConsumerConfig{
+		GroupID:      config.Group,
+		EventTypes:   config.EventTypes,
+		Topics:       b.config.Topics,
+		Workers:      config.Workers,
+		DeadLetter:   b.producer,
+		Codec:        b.producer.Codec(),
+		Processed:    config.Processed,
+		ProcessedTTL: config.ProcessedTTL,
+		LagInterval:  b.config.LagInterval,
+	}, b.config.Retry, b.logger, b.metrics, b.tracer)
+	for _, eventType := range config.EventTypes {
+		consumer.RegisterHandler(string(eventType), handler)
+	}
+	return consumer
+}
+
+func (b *Bus) Ping(ctx context.Context) error {
+	return HealthCheck(b.config.Brokers)(ctx)
+}
+
+func (b *Bus) Close() error {
+	return b.producer.Close()
+}
@@ -3,27 +3,57 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dmehra2102/booking-system/internal/common/logger"
 	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/resilience"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type Producer struct {
-	writer     *kafka.Writer
-	logger     *logger.Logger
-	metrics    *metrics.Metrics
-	tracer     trace.Tracer
-	maxRetries int
+	writer  *kafka.Writer
+	topics  *Topics
+	format  events.Format
+	codec   events.Codec
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+	tracer  trace.Tracer
+	retry   retry.Policy
+	breaker *resilience.Breaker
+
+	// Set by StartAsync
+	mu     sync.RWMutex
+	closed bool
+	queue  chan pending
+	sent   chan struct{}
 }
 
-func NewProducer(brokers []string, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) *Producer {
+// NewProducer returns a producer that publishes events encoded in format
+// to the topics topics maps them to and retries failed writes with
+// retryPolicy. Events of topics configured for events.CodecAvro are
+// encoded with codec instead. Writes fail fast with resilience.ErrOpen
+// while the brokers are considered down.
+func NewProducer(brokers []string, topics *Topics, format events.Format, codec events.Codec, retryPolicy retry.Policy, breakerConfig resilience.BreakerConfig, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) *Producer {
 	writer := &kafka.Writer{
-		Addr:         kafka.TCP(brokers...),
-		Balancer:     &kafka.LeastBytes{},
+		Addr: kafka.TCP(brokers...),
+		// Events of one key stay on one partition, and so in order, even
+		// when they share a topic with other event types
+		Balancer:     &kafka.Hash{},
 		BatchSize:    100,
 		BatchTimeout: 10 * time.Millisecond,
 		ReadTimeout:  10 * time.Second,
@@ -37,76 +67,177 @@ func NewProducer(brokers []string, logger *logger.Logger, metrics *metrics.Metri
 	}
 
 	return &Producer{
-		writer:     writer,
-		logger:     logger,
-		metrics:    metrics,
-		tracer:     tracer,
-		maxRetries: 3,
+		writer:  writer,
+		topics:  topics,
+		format:  format,
+		codec:   codec,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+		retry:   retryPolicy,
+		breaker: resilience.NewBreaker("kafka", breakerConfig, logger, metrics),
 	}
 }
 
-func (p *Producer) Produce(ctx context.Context, topic, key string, value any) error {
-	ctx, span := p.tracer.Start(ctx, "kafka.produce")
+// Produce publishes an event of eventType to its topic. Events that must
+// be handled in order, such as those of one aggregate, have to share key.
+func (p *Producer) Produce(ctx context.Context, eventType events.EventType, key string, value any) error {
+	topic := p.topics.For(eventType)
+	ctx, span := p.tracer.Start(ctx, "kafka.produce", trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(
+		semconv.MessagingSystemKafka,
+		semconv.MessagingDestinationName(topic),
+		semconv.MessagingKafkaMessageKey(key),
+	))
 	defer span.End()
+	defer timing.Track(ctx, "kafka")()
+
+	msg, err := p.message(ctx, eventType, key, value)
+	if err != nil {
+		return err
+	}
+
+	err = p.writeWithRetry(ctx, msg)
+
+	if err != nil {
+		p.metrics.MessageErrors.WithLabelValues(topic, "produce").Inc()
+		tracing.RecordError(span, err)
+		p.logger.WithContext(ctx).WithError(err).Error("failed to produce message")
+		return fmt.Errorf("failed to produce message to topic %s: %w", topic, err)
+	}
+
+	p.metrics.MessagesProduced.WithLabelValues(topic).Inc()
+	p.logger.WithContext(ctx).With("topic", topic).With("key", key).Debug("message produced successfully")
+
+	return nil
+}
+
+// message encodes an event of eventType as the message written to its
+// topic, carrying the trace and tenant of ctx.
+func (p *Producer) message(ctx context.Context, eventType events.EventType, key string, value any) (kafka.Message, error) {
+	topic := p.topics.For(eventType)
 
 	payload, err := json.Marshal(value)
 	if err != nil {
 		p.metrics.MessageErrors.WithLabelValues(topic, "serialization").Inc()
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return kafka.Message{}, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	if err := events.DefaultRegistry.Validate(payload); err != nil {
+		p.metrics.MessageErrors.WithLabelValues(topic, "validation").Inc()
+		return kafka.Message{}, fmt.Errorf("refusing to produce message to topic %s: %w", topic, err)
+	}
+
+	var encoded []byte
+	var headers map[string]string
+	if p.topics.Config(topic).Codec == events.CodecAvro {
+		if p.codec == nil {
+			return kafka.Message{}, fmt.Errorf("topic %s is encoded with avro but no codec is configured", topic)
+		}
+		encoded, err = p.codec.Encode(ctx, topic, payload)
+		headers = map[string]string{events.ContentTypeHeader: events.AvroContentType}
+	} else {
+		encoded, headers, err = events.EncodeMessage(p.format, payload)
+	}
+	if err != nil {
+		p.metrics.MessageErrors.WithLabelValues(topic, "serialization").Inc()
+		return kafka.Message{}, fmt.Errorf("failed to encode message: %w", err)
 	}
 
 	msg := kafka.Message{
 		Topic: topic,
 		Key:   []byte(key),
-		Value: payload,
+		Value: encoded,
 		Time:  time.Now(),
 		Headers: []kafka.Header{
-			{Key: "content-type", Value: []byte("application/json")},
+			{Key: "message-type", Value: []byte(eventType)},
+			{Key: tenancy.MetadataKey, Value: []byte(tenancy.ID(ctx))},
 		},
 	}
-
-	if span.SpanContext().IsValid() {
-		msg.Headers = append(msg.Headers, kafka.Header{
-			Key:   "trace-id",
-			Value: []byte(span.SpanContext().TraceID().String()),
-		})
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: name, Value: []byte(headers[name])})
 	}
 
-	err = p.writeWithRetry(ctx, msg)
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{&msg.Headers})
 
-	if err != nil {
-		p.metrics.MessageErrors.WithLabelValues(topic, "produce").Inc()
-		p.logger.WithContext(ctx).WithError(err).Error("failed to produce message")
-		return fmt.Errorf("failed to produce message to topic %s: %w", topic, err)
+	return msg, nil
+}
+
+// DeadLetterTopic is where consumers park the messages of topic that can
+// never be processed, for inspection and replay.
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+// DeadLetter copies msg to the dead letter topic of its topic, recording
+// where it came from and why it failed in its headers.
+func (p *Producer) DeadLetter(ctx context.Context, msg kafka.Message, cause error) error {
+	headers := append(slices.Clone(msg.Headers),
+		kafka.Header{Key: "dlq-source-topic", Value: []byte(msg.Topic)},
+		kafka.Header{Key: "dlq-source-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: "dlq-source-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: "dlq-error", Value: []byte(cause.Error())},
+	)
+
+	deadLetter := kafka.Message{
+		Topic:   DeadLetterTopic(msg.Topic),
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Time:    time.Now(),
+		Headers: headers,
 	}
 
-	p.metrics.MessagesProduced.WithLabelValues(topic).Inc()
-	p.logger.WithContext(ctx).With("topic", topic).With("key", key).Debug("message produced successfully")
+	if err := p.writeWithRetry(ctx, deadLetter); err != nil {
+		p.metrics.MessageErrors.WithLabelValues(deadLetter.Topic, "produce").Inc()
+		return fmt.Errorf("failed to dead-letter message from topic %s: %w", msg.Topic, err)
+	}
+
+	p.metrics.MessagesProduced.WithLabelValues(deadLetter.Topic).Inc()
+	p.logger.WithContext(ctx).WithError(cause).With("topic", deadLetter.Topic).Warn("message dead-lettered")
 
 	return nil
 }
 
-func (p *Producer) writeWithRetry(ctx context.Context, msg kafka.Message) error {
-	var err error
-	for i := 0; i < p.maxRetries; i++ {
-		err := p.writer.WriteMessages(ctx, msg)
-		if err == nil {
-			return nil
-		}
+func (p *Producer) writeWithRetry(ctx context.Context, msgs ...kafka.Message) error {
+	policy := p.retry
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		p.logger.WithContext(ctx).WithError(err).
+			With("topic", msgs[0].Topic).
+			With("attempt", strconv.Itoa(attempt)).
+			With("backoff", delay.String()).
+			Warn("retrying kafka write")
+	}
 
-		if i < p.maxRetries-1 {
-			backoff := time.Duration(i+1) * time.Second
-			select {
-			case <-time.After(backoff):
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		}
+	policy.Retryable = func(err error) bool {
+		return !errors.Is(err, resilience.ErrOpen)
 	}
 
-	return err
+	return policy.Do(ctx, func(ctx context.Context) error {
+		return p.breaker.Execute(ctx, func(ctx context.Context) error {
+			return p.writer.WriteMessages(ctx, msgs...)
+		})
+	})
 }
 
+// Close delivers the messages still queued by ProduceAsync before closing
+// the writer.
 func (p *Producer) Close() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		if p.queue != nil {
+			close(p.queue)
+		}
+	}
+	p.mu.Unlock()
+
+	if p.sent != nil {
+		<-p.sent
+	}
 	return p.writer.Close()
 }
+
+// Codec returns the codec the producer encodes events with, which
+// consumers of the same service decode them with.
+func (p *Producer) Codec() events.Codec {
+	return p.codec
+}
@@ -0,0 +1,33 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// HealthCheck returns a check that succeeds when any of brokers accepts a
+// connection and answers a metadata request.
+func HealthCheck(brokers []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		err := errors.New("no kafka brokers configured")
+		for _, broker := range brokers {
+			var conn *kafka.Conn
+			conn, err = kafka.DialContext(ctx, "tcp", broker)
+			if err != nil {
+				continue
+			}
+
+			if deadline, ok := ctx.Deadline(); ok {
+				conn.SetDeadline(deadline)
+			}
+			_, err = conn.Brokers()
+			conn.Close()
+			if err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+}
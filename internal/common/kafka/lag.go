@@ -0,0 +1,177 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/segmentio/kafka-go"
+)
+
+// lagReporter periodically compares the offsets committed by a consumer
+// group with the last offsets of its partitions, and logs which member of
+// the group each partition was assigned to when that changes.
+type lagReporter struct {
+	client  *kafka.Client
+	group   string
+	topics  []string
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+
+	// assignments holds the partitions of each member at the last report
+	assignments map[string][]string
+}
+
+func newLagReporter(brokers []string, group string, topics []string, logger *logger.Logger, metrics *metrics.Metrics) *lagReporter {
+	return &lagReporter{
+		client:  &kafka.Client{Addr: kafka.TCP(brokers...), Timeout: 10 * time.Second},
+		group:   group,
+		topics:  topics,
+		logger:  logger,
+		metrics: metrics,
+	}
+}
+
+// run reports every interval until ctx is done.
+func (r *lagReporter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.reportLag(ctx); err != nil && ctx.Err() == nil {
+				r.logger.WithError(err).Warn("failed to report consumer lag")
+			}
+			if err := r.reportAssignments(ctx); err != nil && ctx.Err() == nil {
+				r.logger.WithError(err).Warn("failed to report partition assignments")
+			}
+		}
+	}
+}
+
+func (r *lagReporter) reportLag(ctx context.Context) error {
+	metadata, err := r.client.Metadata(ctx, &kafka.MetadataRequest{Topics: r.topics})
+	if err != nil {
+		return fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+
+	partitions := make(map[string][]int, len(metadata.Topics))
+	lastOffsets := make(map[string][]kafka.OffsetRequest, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+			lastOffsets[topic.Name] = append(lastOffsets[topic.Name], kafka.LastOffsetOf(partition.ID))
+		}
+	}
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	committed, err := r.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: r.group, Topics: partitions})
+	if err != nil {
+		return fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+	if committed.Error != nil {
+		return fmt.Errorf("failed to fetch committed offsets: %w", committed.Error)
+	}
+
+	last, err := r.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: lastOffsets})
+	if err != nil {
+		return fmt.Errorf("failed to list offsets: %w", err)
+	}
+
+	for topic, offsets := range last.Topics {
+		committedOffsets := make(map[int]int64, len(offsets))
+		for _, partition := range committed.Topics[topic] {
+			if partition.Error == nil {
+				committedOffsets[partition.Partition] = partition.CommittedOffset
+			}
+		}
+
+		for _, partition := range offsets {
+			offset, ok := committedOffsets[partition.Partition]
+			// Nothing committed yet; the group starts at the last offset
+			if partition.Error != nil || !ok || offset < 0 {
+				continue
+			}
+			r.metrics.ConsumerLag.WithLabelValues(r.group, topic, strconv.Itoa(partition.Partition)).Set(float64(max(partition.LastOffset-offset, 0)))
+		}
+	}
+
+	return nil
+}
+
+func (r *lagReporter) reportAssignments(ctx context.Context) error {
+	resp, err := r.client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: []string{r.group}})
+	if err != nil {
+		return fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+	if len(resp.Groups) == 0 {
+		return nil
+	}
+	group := resp.Groups[0]
+	if group.Error != nil {
+		return fmt.Errorf("failed to describe consumer group: %w", group.Error)
+	}
+
+	r.metrics.ConsumerGroupMembers.WithLabelValues(r.group).Set(float64(len(group.Members)))
+
+	assignments := make(map[string][]string, len(group.Members))
+	hosts := make(map[string]string, len(group.Members))
+	for _, member := range group.Members {
+		var partitions []string
+		for _, topic := range member.MemberAssignments.Topics {
+			for _, partition := range topic.Partitions {
+				partitions = append(partitions, topic.Topic+"/"+strconv.Itoa(partition))
+			}
+		}
+		slices.Sort(partitions)
+		assignments[member.MemberID] = partitions
+		hosts[member.MemberID] = member.ClientHost
+	}
+
+	// The first report only records the assignments
+	if r.assignments != nil {
+		members := slices.Sorted(maps.Keys(assignments))
+		for _, member := range members {
+			if assigned := difference(assignments[member], r.assignments[member]); len(assigned) > 0 {
+				r.logger.With("member", member).With("host", hosts[member]).
+					With("partitions", strings.Join(assigned, ",")).
+					Info("partitions assigned")
+			}
+		}
+		for _, member := range slices.Sorted(maps.Keys(r.assignments)) {
+			if revoked := difference(r.assignments[member], assignments[member]); len(revoked) > 0 {
+				r.logger.With("member", member).
+					With("partitions", strings.Join(revoked, ",")).
+					Info("partitions revoked")
+			}
+		}
+	}
+	r.assignments = assignments
+
+	return nil
+}
+
+// difference returns the partitions of a missing from b.
+func difference(a, b []string) []string {
+	var diff []string
+	for _, partition := range a {
+		if !slices.Contains(b, partition) {
+			diff = append(diff, partition)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+)
+
+// ProcessedStore remembers the events each consumer group handled.
+type ProcessedStore = messagebus.ProcessedStore
+
+// PostgresProcessedStore keeps processed events in the processed_messages
+// table. Expired rows are deleted by ProcessedPurgeJob.
+type PostgresProcessedStore struct {
+	db *database.PostgresDB
+}
+
+func NewPostgresProcessedStore(db *database.PostgresDB) *PostgresProcessedStore {
+	return &PostgresProcessedStore{db: db}
+}
+
+func (s *PostgresProcessedStore) Claim(ctx context.Context, group, eventID string, lease time.Duration) (bool, error) {
+	// Takes over claims that lapsed or events whose ttl ran out
+	query := `
+		INSERT INTO processed_messages (consumer_group, event_id, completed, expires_at)
+		VALUES ($1, $2, FALSE, $3)
+		ON CONFLICT (consumer_group, event_id) DO UPDATE
+		SET completed = FALSE, expires_at = EXCLUDED.expires_at
+		WHERE processed_messages.expires_at < NOW()
+		RETURNING TRUE
+	`
+
+	var claimed bool
+	err := s.db.QueryRow(ctx, "claim_processed_message", query, group, eventID, time.Now().Add(lease)).Scan(&claimed)
+	if err == nil {
+		return true, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	var completed bool
+	err = s.db.QueryRow(ctx, "get_processed_message",
+		`SELECT completed FROM processed_messages WHERE consumer_group = $1 AND event_id = $2`,
+		group, eventID,
+	).Scan(&completed)
+	if err == sql.ErrNoRows {
+		// Released in the meantime
+		return false, messagebus.ErrInProgress
+	}
+	if err != nil {
+		return false, err
+	}
+	if !completed {
+		return false, messagebus.ErrInProgress
+	}
+
+	return false, nil
+}
+
+func (s *PostgresProcessedStore) Complete(ctx context.Context, group, eventID string, ttl time.Duration) error {
+	query := `
+		UPDATE processed_messages SET completed = TRUE, expires_at = $3
+		WHERE consumer_group = $1 AND event_id = $2
+	`
+
+	_, err := s.db.Exec(ctx, "complete_processed_message", query, group, eventID, time.Now().Add(ttl))
+	return err
+}
+
+func (s *PostgresProcessedStore) Release(ctx context.Context, group, eventID string) error {
+	query := `DELETE FROM processed_messages WHERE consumer_group = $1 AND event_id = $2 AND NOT completed`
+
+	_, err := s.db.Exec(ctx, "release_processed_message", query, group, eventID)
+	return err
+}
+
+// Purge deletes the events whose ttl ran out and returns how many.
+func (s *PostgresProcessedStore) Purge(ctx context.Context) (int64, error) {
+	result, err := s.db.Exec(ctx, "purge_processed_messages", `DELETE FROM processed_messages WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ProcessedPurgeJob deletes expired processed events every interval.
+func ProcessedPurgeJob(s *PostgresProcessedStore, logger *logger.Logger, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "processed_messages_purge",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			purged, err := s.Purge(ctx)
+			if err != nil {
+				return err
+			}
+
+			if purged > 0 {
+				logger.WithContext(ctx).With("purged", strconv.FormatInt(purged, 10)).Info("purged processed messages")
+			}
+			return nil
+		},
+	}
+}
+
+// Values of processed events in Redis.
+const (
+	processingValue = "processing"
+	completedValue  = "completed"
+)
+
+// RedisProcessedStore keeps processed events as keys expiring with their
+// ttl, so they need no purging.
+type RedisProcessedStore struct {
+	redis *database.RedisClient
+}
+
+func NewRedisProcessedStore(redis *database.RedisClient) *RedisProcessedStore {
+	return &RedisProcessedStore{redis: redis}
+}
+
+func (s *RedisProcessedStore) Claim(ctx context.Context, group, eventID string, lease time.Duration) (bool, error) {
+	existing, claimed, err := s.redis.SetIfAbsent(ctx, processedKey(group, eventID), processingValue, lease)
+	if err != nil {
+		return false, err
+	}
+	if claimed {
+		return true, nil
+	}
+	if existing != completedValue {
+		return false, messagebus.ErrInProgress
+	}
+	return false, nil
+}
+
+func (s *RedisProcessedStore) Complete(ctx context.Context, group, eventID string, ttl time.Duration) error {
+	return s.redis.Set(ctx, processedKey(group, eventID), completedValue, ttl)
+}
+
+func (s *RedisProcessedStore) Release(ctx context.Context, group, eventID string) error {
+	return s.redis.Delete(ctx, processedKey(group, eventID))
+}
+
+func processedKey(group, eventID string) string {
+	return "processed:" + group + ":" + eventID
+}
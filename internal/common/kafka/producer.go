@@ -4,30 +4,85 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
 	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
 	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-type MessageHandler func(ctx context.Context, key, value []byte, headers map[string]string) error
+// MessageHandler handles the events of a message type.
+type MessageHandler = messagebus.Handler
 
+// ConsumerConfig describes a consumer group subscription to the topics
+// Topics maps EventTypes to. Messages are handled by Workers goroutines;
+// every message of a partition goes to the same worker, so each partition
+// is still handled in order. Messages that can never be processed are
+// copied to their dead letter topic with DeadLetter, or only logged when
+// it is nil. Events encoded with a schema registry, such as Avro, are
+// decoded with Codec. Events found in Processed are skipped, so redelivered ones
+// are handled once; they are remembered for ProcessedTTL. The lag of the
+// group and the assignment of its partitions are reported every
+// LagInterval, or never when it is zero.
+type ConsumerConfig struct {
+	GroupID      string
+	EventTypes   []events.EventType
+	Topics       *Topics
+	Workers      int
+	DeadLetter   *Producer
+	Codec        events.Codec
+	Processed    ProcessedStore
+	ProcessedTTL time.Duration
+	LagInterval  time.Duration
+}
+
+const workerQueueSize = 16
+
+// Consumer reads a set of topics as a member of a consumer group. Offsets
+// are committed only once a message has been handled, or is known never to
+// succeed, so a crash or shutdown redelivers unfinished messages.
 type Consumer struct {
 	reader     *kafka.Reader
 	logger     *logger.Logger
 	metrics    *metrics.Metrics
 	tracer     trace.Tracer
 	handlers   map[string]MessageHandler
-	maxRetries int
+	retry      retry.Policy
+	workers    int
+	deadLetter *Producer
+	codec      events.Codec
+
+	group        string
+	processed    ProcessedStore
+	processedTTL time.Duration
+
+	lag         *lagReporter
+	lagInterval time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	running  sync.WaitGroup
 }
 
-func NewConsumer(brokers []string, consumerGroup, topic string, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) *Consumer {
+func NewConsumer(brokers []string, config ConsumerConfig, retryPolicy retry.Policy, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) *Consumer {
+	topics := config.Topics.Subscribe(config.EventTypes)
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:          brokers,
-		GroupID:          consumerGroup,
-		Topic:            topic,
+		GroupID:          config.GroupID,
+		GroupTopics:      topics,
 		MinBytes:         1,
 		MaxBytes:         10e6,
 		CommitInterval:   time.Second,
@@ -43,13 +98,36 @@ func NewConsumer(brokers []string, consumerGroup, topic string, logger *logger.L
 		}),
 	})
 
+	workers := config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	logger = logger.With("consumer_group", config.GroupID)
+
+	var lag *lagReporter
+	if config.LagInterval > 0 {
+		lag = newLagReporter(brokers, config.GroupID, topics, logger, metrics)
+	}
+
 	return &Consumer{
 		reader:     reader,
 		logger:     logger,
 		metrics:    metrics,
 		tracer:     tracer,
 		handlers:   make(map[string]MessageHandler),
-		maxRetries: 3,
+		retry:      retryPolicy,
+		workers:    workers,
+		deadLetter: config.DeadLetter,
+		codec:      config.Codec,
+		stop:       make(chan struct{}),
+
+		group:        config.GroupID,
+		processed:    config.Processed,
+		processedTTL: config.ProcessedTTL,
+
+		lag:         lag,
+		lagInterval: config.LagInterval,
 	}
 }
 
@@ -57,91 +135,304 @@ func (c *Consumer) RegisterHandler(messageType string, handler MessageHandler) {
 	c.handlers[messageType] = handler
 }
 
+// Start consumes messages until ctx is cancelled or Shutdown is called.
+// Shutdown lets the messages being handled finish; cancelling ctx aborts
+// them.
 func (c *Consumer) Start(ctx context.Context) error {
-	c.logger.Info("starting kafka consumer")
+	c.running.Add(1)
+	defer c.running.Done()
+
+	c.logger.With("workers", strconv.Itoa(c.workers)).Info("starting kafka consumer")
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stop:
+			cancel()
+		case <-fetchCtx.Done():
+		}
+	}()
+
+	if c.lag != nil {
+		go c.lag.run(fetchCtx, c.lagInterval)
+	}
+
+	queues := make([]chan kafka.Message, c.workers)
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, workerQueueSize)
+		workers.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer workers.Done()
+			c.work(ctx, queue)
+		}(queues[i])
+	}
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		workers.Wait()
+	}()
 
 	for {
+		msg, err := c.reader.FetchMessage(fetchCtx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.logger.Info("consumer context cancelled, shutting down")
+				return ctx.Err()
+			}
+			if fetchCtx.Err() != nil {
+				c.logger.Info("consumer stopped")
+				return nil
+			}
+
+			c.metrics.MessageErrors.WithLabelValues(msg.Topic, "read").Inc()
+			c.logger.WithError(err).Error("failed to read message")
+			continue
+		}
+
 		select {
-		case <-ctx.Done():
-			c.logger.Info("consumer context cancelled, shutting down")
-			return ctx.Err()
+		case queues[partitionWorker(msg, c.workers)] <- msg:
+		case <-fetchCtx.Done():
+			// Left uncommitted; it is redelivered after a restart
+		}
+	}
+}
+
+// partitionWorker assigns every partition of every topic to a fixed
+// worker.
+func partitionWorker(msg kafka.Message, workers int) int {
+	h := fnv.New32a()
+	h.Write([]byte(msg.Topic))
+	return int((h.Sum32() + uint32(msg.Partition)) % uint32(workers))
+}
+
+func (c *Consumer) work(ctx context.Context, queue <-chan kafka.Message) {
+	for msg := range queue {
+		select {
+		case <-c.stop:
+			// Queued messages stay uncommitted and are redelivered
+			return
 		default:
-			err := c.processMessage(ctx)
-			if err != nil {
-				c.logger.WithError(err).Error("error processing message")
-			}
 		}
+
+		c.handle(ctx, msg)
 	}
 }
 
-func (c *Consumer) processMessage(ctx context.Context) error {
-	msg, err := c.reader.ReadMessage(ctx)
-	if err != nil {
-		c.metrics.MessageErrors.WithLabelValues(msg.Topic, "read").Inc()
-		return fmt.Errorf("failed to read message: %w", err)
+// handle processes msg and commits it. A message that still fails with a
+// transient error once retries are exhausted is kept uncommitted and tried
+// again after a pause, holding back its partition, rather than being lost.
+// One that can never succeed is dead-lettered first.
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message) {
+	for {
+		err := c.processMessage(ctx, msg)
+		if err != nil && !messagebus.IsPermanent(err) {
+			if !c.pause(ctx) {
+				return
+			}
+			continue
+		}
+
+		if err != nil {
+			c.logger.WithError(err).With("topic", msg.Topic).Error("skipping message that cannot be processed")
+
+			if c.deadLetter != nil {
+				if err := c.deadLetter.DeadLetter(ctx, msg, err); err != nil {
+					c.logger.WithError(err).With("topic", msg.Topic).Error("failed to dead-letter message")
+					if !c.pause(ctx) {
+						return
+					}
+					continue
+				}
+			}
+		}
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			c.logger.WithError(err).With("topic", msg.Topic).Error("failed to commit message")
+		}
+		return
 	}
+}
 
-	headers := make(map[string]string)
-	for _, header := range msg.Headers {
-		headers[string(header.Key)] = string(header.Value)
+// pause waits before a message is tried again and reports false when the
+// consumer is stopping instead.
+func (c *Consumer) pause(ctx context.Context) bool {
+	select {
+	case <-time.After(c.retry.MaxInterval):
+		return true
+	case <-c.stop:
+		return false
+	case <-ctx.Done():
+		return false
 	}
+}
+
+func (c *Consumer) processMessage(ctx context.Context, msg kafka.Message) error {
+	headers := messageHeaders(msg)
 
-	ctx, span := c.tracer.Start(ctx, fmt.Sprintf("kafka.consume.%s", msg.Topic))
+	// Continue the trace of the producer
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{&msg.Headers})
+	if tenantID := headers[tenancy.MetadataKey]; tenantID != "" {
+		ctx = tenancy.WithID(ctx, tenantID)
+	}
+	ctx, span := c.tracer.Start(ctx, fmt.Sprintf("kafka.consume.%s", msg.Topic), trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		semconv.MessagingSystemKafka,
+		semconv.MessagingDestinationName(msg.Topic),
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+		semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+	))
 	defer span.End()
 
 	c.logger.WithContext(ctx).With("topic", msg.Topic).With("partition", fmt.Sprintf("%d", msg.Partition)).With("offset", fmt.Sprintf("%d", msg.Offset)).Debug("processing message")
 
-	// Process message with retry logic
-	err = c.processWithRetry(ctx, msg.Key, msg.Value, headers)
+	// Handlers only ever see the latest version of an event
+	value, err := decodeEvent(ctx, c.codec, msg.Value, headers)
+	if err != nil {
+		c.metrics.MessageErrors.WithLabelValues(msg.Topic, "validation").Inc()
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	// Topics shared by several event types carry events the group is not
+	// interested in
+	messageType := messageType(value, headers)
+	handler, exists := c.handlers[messageType]
+	if !exists {
+		c.logger.WithContext(ctx).With("message_type", messageType).Debug("skipping message without handler")
+		return nil
+	}
+
+	// Handlers act on the current state, which a replica may lag behind
+	ctx = database.UsePrimary(ctx)
+
+	eventID := eventID(value)
+	if c.processed != nil && eventID != "" {
+		claimed, err := c.processed.Claim(ctx, c.group, eventID, messagebus.ProcessingLease)
+		if err != nil {
+			tracing.RecordError(span, err)
+			c.logger.WithContext(ctx).WithError(err).With("event_id", eventID).Warn("failed to claim message")
+			return err
+		}
+		if !claimed {
+			c.metrics.MessagesDuplicate.WithLabelValues(msg.Topic, c.group).Inc()
+			c.logger.WithContext(ctx).With("event_id", eventID).Debug("skipping message processed before")
+			return nil
+		}
+	}
+
+	err = c.processWithRetry(ctx, handler, msg.Key, value, headers)
 	if err != nil {
 		c.metrics.MessageErrors.WithLabelValues(msg.Topic, "process").Inc()
+		tracing.RecordError(span, err)
 		c.logger.WithContext(ctx).WithError(err).Error("failed to process message after retries")
 
+		if c.processed != nil && eventID != "" {
+			if err := c.processed.Release(ctx, c.group, eventID); err != nil {
+				c.logger.WithContext(ctx).WithError(err).With("event_id", eventID).Warn("failed to release message claim")
+			}
+		}
 		return err
 	}
 
+	if c.processed != nil && eventID != "" {
+		// The message is handled either way; a redelivery waits for the
+		// claim to lapse and is handled again
+		if err := c.processed.Complete(ctx, c.group, eventID, c.processedTTL); err != nil {
+			c.logger.WithContext(ctx).WithError(err).With("event_id", eventID).Warn("failed to mark message processed")
+		}
+	}
+
 	c.metrics.MessagesConsumed.WithLabelValues(msg.Topic).Inc()
 	return nil
 }
 
-func (c *Consumer) processWithRetry(ctx context.Context, key, value []byte, headers map[string]string) error {
-	var err error
+// messageHeaders returns the headers of msg by key.
+func messageHeaders(msg kafka.Message) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	return headers
+}
 
-	for i := 0; i < c.maxRetries; i++ {
-		messageType := headers["message-type"]
-		if messageType == "" {
-			var payload map[string]any
-			if err := json.Unmarshal(value, &payload); err == nil {
-				if mt, ok := payload["type"].(string); ok {
-					messageType = mt
-				}
-			}
+// decodeEvent returns the event of a message of any events.Format and
+// codec in the native format, upcast to the latest version of its type.
+// Without codec only JSON messages can be decoded.
+func decodeEvent(ctx context.Context, codec events.Codec, value []byte, headers map[string]string) ([]byte, error) {
+	native, err := events.DecodeMessage(value, headers)
+	if err != nil {
+		return nil, err
+	}
+	if codec != nil {
+		if native, err = codec.Decode(ctx, native); err != nil {
+			return nil, err
 		}
+	}
+	return events.DefaultRegistry.Upcast(native)
+}
 
-		if handler, exists := c.handlers[messageType]; exists {
-			err = handler(ctx, key, value, headers)
-			if err == nil {
-				return nil
-			}
-		} else {
-			c.logger.WithContext(ctx).With("message_type", messageType).Warn("no handler found for message type")
-			return fmt.Errorf("no handler found for message type: %s", messageType)
-		}
+// messageType returns the type of a message from its headers, or from its
+// payload for messages produced without the header.
+func messageType(value []byte, headers map[string]string) string {
+	if messageType := headers["message-type"]; messageType != "" {
+		return messageType
+	}
 
-		// Wait before retry with exponential backoff
-		if i < c.maxRetries-1 {
-			backoff := time.Duration(i+1) * time.Second
-			c.logger.WithContext(ctx).With("attempt", fmt.Sprintf("%d", i+1)).With("backoff", backoff.String()).Warn("retrying message processing")
+	var payload struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(value, &payload)
+	return payload.Type
+}
 
-			select {
-			case <-time.After(backoff):
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		}
+// eventID returns the ID of the event in a message, or "" when it has
+// none.
+func eventID(value []byte) string {
+	var payload struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(value, &payload)
+	return payload.ID
+}
+
+// processWithRetry dispatches the message to handler, retrying transient
+// failures.
+func (c *Consumer) processWithRetry(ctx context.Context, handler MessageHandler, key, value []byte, headers map[string]string) error {
+	policy := c.retry
+	policy.Retryable = errors.IsTransient
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		c.logger.WithContext(ctx).WithError(err).
+			With("attempt", strconv.Itoa(attempt)).
+			With("backoff", delay.String()).
+			Warn("retrying message processing")
+	}
+
+	return policy.Do(ctx, func(ctx context.Context) error {
+		return handler(ctx, key, value, headers)
+	})
+}
+
+// Shutdown stops fetching, waits for the messages being handled and
+// closes the reader, which flushes pending commits. It gives up waiting
+// when ctx is done.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		c.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("consumer did not finish in-flight messages: %w", ctx.Err())
 	}
 
-	return fmt.Errorf("failed to process message after %d retries: %w", c.maxRetries, err)
+	return c.Close()
 }
 
 func (c *Consumer) Close() error {
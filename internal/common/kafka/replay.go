@@ -0,0 +1,234 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/segmentio/kafka-go"
+)
+
+// FirstOffset starts a replay at the first message of every partition.
+const FirstOffset = kafka.FirstOffset
+
+// ReplayConfig selects the messages a Replayer reads. Every partition of
+// Topics is read from From, or from Offset when From is zero, up to the
+// last message it held when the replay started.
+type ReplayConfig struct {
+	Topics []string
+	// Offset is FirstOffset to read the partitions from the start
+	Offset int64
+	From   time.Time
+	// DryRun only counts the messages that would be handled
+	DryRun bool
+	// Progress is logged every ProgressInterval
+	ProgressInterval time.Duration
+}
+
+// ReplayStats counts the messages of a replay.
+type ReplayStats struct {
+	// Read messages, of which Handled had a handler and Failed failed
+	Read    int64
+	Handled int64
+	Failed  int64
+}
+
+// Replayer feeds the messages of topics through a set of handlers outside
+// of any consumer group, to rebuild projections such as the search index.
+// Offsets are not committed, so consumers are unaffected.
+type Replayer struct {
+	brokers  []string
+	codec    events.Codec
+	logger   *logger.Logger
+	handlers map[string]MessageHandler
+}
+
+// NewReplayer returns a Replayer decoding messages with codec, which may
+// be nil when all topics carry JSON.
+func NewReplayer(brokers []string, codec events.Codec, logger *logger.Logger) *Replayer {
+	return &Replayer{
+		brokers:  brokers,
+		codec:    codec,
+		logger:   logger,
+		handlers: make(map[string]MessageHandler),
+	}
+}
+
+func (r *Replayer) RegisterHandler(messageType string, handler MessageHandler) {
+	r.handlers[messageType] = handler
+}
+
+// Replay reads the messages config selects, partition by partition, so
+// the messages of a partition are handled in order. Messages that fail
+// are logged and counted; the replay goes on.
+func (r *Replayer) Replay(ctx context.Context, config ReplayConfig) (ReplayStats, error) {
+	var stats replayCounters
+
+	if config.ProgressInterval > 0 {
+		ticker := time.NewTicker(config.ProgressInterval)
+		done := make(chan struct{})
+		defer func() {
+			ticker.Stop()
+			close(done)
+		}()
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					r.logProgress(stats.snapshot(), "replay in progress")
+				}
+			}
+		}()
+	}
+
+	client := &kafka.Client{Addr: kafka.TCP(r.brokers...), Timeout: 30 * time.Second}
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: config.Topics})
+	if err != nil {
+		return stats.snapshot(), fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			return stats.snapshot(), fmt.Errorf("failed to get metadata of topic %s: %w", topic.Name, topic.Error)
+		}
+		for _, partition := range topic.Partitions {
+			if err := r.replayPartition(ctx, config, topic.Name, partition.ID, &stats); err != nil {
+				return stats.snapshot(), err
+			}
+		}
+	}
+
+	result := stats.snapshot()
+	r.logProgress(result, "replay finished")
+	return result, nil
+}
+
+func (r *Replayer) replayPartition(ctx context.Context, config ReplayConfig, topic string, partition int, stats *replayCounters) error {
+	start, end, err := r.offsets(ctx, config, topic, partition)
+	if err != nil {
+		return fmt.Errorf("failed to get offsets of %s/%d: %w", topic, partition, err)
+	}
+
+	log := r.logger.With("topic", topic).With("partition", strconv.Itoa(partition))
+	if start < 0 || start >= end {
+		log.Info("nothing to replay")
+		return nil
+	}
+	log.With("from", strconv.FormatInt(start, 10)).With("to", strconv.FormatInt(end, 10)).Info("replaying partition")
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   r.brokers,
+		Topic:     topic,
+		Partition: partition,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(start); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read %s/%d: %w", topic, partition, err)
+		}
+
+		stats.read.Add(1)
+		r.handle(ctx, config.DryRun, msg, stats)
+
+		if msg.Offset >= end-1 {
+			return nil
+		}
+	}
+}
+
+// offsets returns the offset config starts reading partition at and the
+// offset after its last message. The start is negative when no message
+// was written after config.From.
+func (r *Replayer) offsets(ctx context.Context, config ReplayConfig, topic string, partition int) (start, end int64, err error) {
+	for _, broker := range r.brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialLeader(ctx, "tcp", broker, topic, partition)
+		if err != nil {
+			continue
+		}
+		defer conn.Close()
+
+		var first int64
+		first, end, err = conn.ReadOffsets()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		switch {
+		case !config.From.IsZero():
+			start, err = conn.ReadOffset(config.From)
+		case config.Offset == FirstOffset:
+			start = first
+		default:
+			start = max(config.Offset, first)
+		}
+		return start, end, err
+	}
+	if err == nil {
+		err = errors.New("no kafka brokers configured")
+	}
+	return 0, 0, err
+}
+
+func (r *Replayer) handle(ctx context.Context, dryRun bool, msg kafka.Message, stats *replayCounters) {
+	headers := messageHeaders(msg)
+
+	value, err := decodeEvent(ctx, r.codec, msg.Value, headers)
+	if err != nil {
+		stats.failed.Add(1)
+		r.logger.WithError(err).With("topic", msg.Topic).With("offset", strconv.FormatInt(msg.Offset, 10)).Warn("skipping invalid message")
+		return
+	}
+
+	handler, ok := r.handlers[messageType(value, headers)]
+	if !ok {
+		return
+	}
+	stats.handled.Add(1)
+	if dryRun {
+		return
+	}
+
+	if tenantID := headers[tenancy.MetadataKey]; tenantID != "" {
+		ctx = tenancy.WithID(ctx, tenantID)
+	}
+	if err := handler(database.UsePrimary(ctx), msg.Key, value, headers); err != nil {
+		stats.failed.Add(1)
+		r.logger.WithContext(ctx).WithError(err).With("topic", msg.Topic).With("offset", strconv.FormatInt(msg.Offset, 10)).Warn("failed to replay message")
+	}
+}
+
+func (r *Replayer) logProgress(stats ReplayStats, message string) {
+	r.logger.
+		With("read", strconv.FormatInt(stats.Read, 10)).
+		With("handled", strconv.FormatInt(stats.Handled, 10)).
+		With("failed", strconv.FormatInt(stats.Failed, 10)).
+		Info(message)
+}
+
+// replayCounters are the ReplayStats updated while the progress is
+// logged.
+type replayCounters struct {
+	read, handled, failed atomic.Int64
+}
+
+func (c *replayCounters) snapshot() ReplayStats {
+	return ReplayStats{Read: c.read.Load(), Handled: c.handled.Load(), Failed: c.failed.Load()}
+}
@@ -0,0 +1,217 @@
+package kafka
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// Cleanup policies of topics.
+const (
+	CleanupDelete  = "delete"
+	CleanupCompact = "compact"
+)
+
+// Topic describes a topic as it is created. Zero fields are left to the
+// defaults of the brokers.
+type Topic struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+	Retention         time.Duration
+	CleanupPolicy     string
+	// Codec of the events written to the topic, events.CodecJSON or
+	// events.CodecAvro
+	Codec string
+}
+
+// Topics maps event types to the topics they are published to. Events
+// sharing a topic keep their relative order when they share a key, so
+// the events of one aggregate, keyed by its ID, are handled in the order
+// they happened. Event types no route matches are published to a topic
+// named after them.
+type Topics struct {
+	defaults Topic
+	topics   map[string]Topic
+	routes   []route
+}
+
+// route sends the event types starting with prefix to topic, or only the
+// event type prefix when exact is set.
+type route struct {
+	prefix string
+	exact  bool
+	topic  string
+}
+
+// NewTopics returns the topic registry of topics, which are created with
+// defaults where they leave settings zero, and of routes from event type
+// patterns to topic names. A pattern ending in "*" matches every event
+// type starting with the rest; the longest match wins.
+func NewTopics(defaults Topic, topics []Topic, routes map[string]string) *Topics {
+	t := &Topics{defaults: defaults, topics: make(map[string]Topic, len(topics))}
+	for _, topic := range topics {
+		t.topics[topic.Name] = topic
+	}
+
+	for pattern, topic := range routes {
+		prefix, wildcard := strings.CutSuffix(pattern, "*")
+		t.routes = append(t.routes, route{prefix: prefix, exact: !wildcard, topic: topic})
+	}
+	// Exact routes first, then the longest prefixes
+	slices.SortFunc(t.routes, func(a, b route) int {
+		if a.exact != b.exact {
+			if a.exact {
+				return -1
+			}
+			return 1
+		}
+		return len(b.prefix) - len(a.prefix)
+	})
+
+	return t
+}
+
+// For returns the topic events of eventType are published to.
+func (t *Topics) For(eventType events.EventType) string {
+	for _, r := range t.routes {
+		if r.exact && string(eventType) == r.prefix || !r.exact && strings.HasPrefix(string(eventType), r.prefix) {
+			return r.topic
+		}
+	}
+	return string(eventType)
+}
+
+// Subscribe returns the topics carrying eventTypes, each once.
+func (t *Topics) Subscribe(eventTypes []events.EventType) []string {
+	topics := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if topic := t.For(eventType); !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// Config returns the settings topic is created with.
+func (t *Topics) Config(topic string) Topic {
+	config := t.defaults
+	if configured, ok := t.topics[topic]; ok {
+		if configured.Partitions > 0 {
+			config.Partitions = configured.Partitions
+		}
+		if configured.ReplicationFactor > 0 {
+			config.ReplicationFactor = configured.ReplicationFactor
+		}
+		if configured.Retention > 0 {
+			config.Retention = configured.Retention
+		}
+		if configured.CleanupPolicy != "" {
+			config.CleanupPolicy = configured.CleanupPolicy
+		}
+		if configured.Codec != "" {
+			config.Codec = configured.Codec
+		}
+	}
+	config.Name = topic
+	return config
+}
+
+// All returns the settings of the topics of eventTypes and of their dead
+// letter topics, sorted by name.
+func (t *Topics) All(eventTypes []events.EventType) []Topic {
+	names := t.Subscribe(eventTypes)
+	for _, name := range names {
+		names = append(names, DeadLetterTopic(name))
+	}
+	slices.Sort(names)
+
+	topics := make([]Topic, 0, len(names))
+	for _, name := range slices.Compact(names) {
+		topics = append(topics, t.Config(name))
+	}
+	return topics
+}
+
+// ParseTopics parses a comma separated list of topics as
+// "name:partitions:retention:cleanup_policy:codec", where every field
+// after the name may be left empty or out, e.g.
+// "bookings:12:168h:delete:avro,users:6::compact".
+func ParseTopics(s string) ([]Topic, error) {
+	var topics []Topic
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Split(part, ":")
+		if len(fields) > 5 || strings.TrimSpace(fields[0]) == "" {
+			return nil, fmt.Errorf("invalid topic %q: expected name:partitions:retention:cleanup_policy:codec", part)
+		}
+		fields = append(fields, make([]string, 5-len(fields))...)
+
+		topic := Topic{Name: strings.TrimSpace(fields[0])}
+		if v := strings.TrimSpace(fields[1]); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid topic %q: bad partitions", part)
+			}
+			topic.Partitions = n
+		}
+		if v := strings.TrimSpace(fields[2]); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid topic %q: bad retention", part)
+			}
+			topic.Retention = d
+		}
+		if v := strings.TrimSpace(fields[3]); v != "" {
+			if v != CleanupDelete && v != CleanupCompact {
+				return nil, fmt.Errorf("invalid topic %q: cleanup policy must be %s or %s", part, CleanupDelete, CleanupCompact)
+			}
+			topic.CleanupPolicy = v
+		}
+		if v := strings.TrimSpace(fields[4]); v != "" {
+			codec, err := events.ParseCodec(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid topic %q: %w", part, err)
+			}
+			topic.Codec = codec
+		}
+
+		topics = append(topics, topic)
+	}
+
+	return topics, nil
+}
+
+// ParseTopicRoutes parses a comma separated list of "event_type=topic"
+// pairs, where the event type may end in "*" to match a prefix, e.g.
+// "booking.*=bookings,waitlist.*=bookings".
+func ParseTopicRoutes(s string) (map[string]string, error) {
+	routes := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		pattern, topic, ok := strings.Cut(part, "=")
+		pattern, topic = strings.TrimSpace(pattern), strings.TrimSpace(topic)
+		if !ok || pattern == "" || topic == "" {
+			return nil, fmt.Errorf("invalid topic route %q: expected event_type=topic", part)
+		}
+		if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+			return nil, fmt.Errorf("invalid topic route %q: only a trailing * is supported", part)
+		}
+
+		routes[pattern] = topic
+	}
+
+	return routes, nil
+}
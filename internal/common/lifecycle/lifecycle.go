@@ -0,0 +1,144 @@
+// Package lifecycle coordinates the ordered shutdown of a service.
+//
+// Components register a stop hook as they are started. On SIGINT or
+// SIGTERM the hooks run one at a time in reverse registration order, like
+// deferred calls, so the HTTP server registered last stops accepting
+// requests first and the database opened first is closed last. Each hook
+// is bounded by a timeout; a hook that overruns is logged as stuck and
+// shutdown moves on to the next component.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+)
+
+// ErrShuttingDown is reported by Check once shutdown has begun.
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// Hook stops a component. It should return once ctx is done even if the
+// component has not fully stopped.
+type Hook func(ctx context.Context) error
+
+type component struct {
+	name    string
+	timeout time.Duration
+	hook    Hook
+}
+
+type Manager struct {
+	logger     *logger.Logger
+	timeout    time.Duration
+	drainDelay time.Duration
+
+	mu           sync.Mutex
+	components   []component
+	shuttingDown bool
+	once         sync.Once
+}
+
+// New returns a manager whose hooks default to timeout. drainDelay is
+// waited after shutdown begins and before the first hook runs, giving load
+// balancers time to observe the failing readiness check.
+func New(logger *logger.Logger, timeout, drainDelay time.Duration) *Manager {
+	return &Manager{logger: logger, timeout: timeout, drainDelay: drainDelay}
+}
+
+// OnStop registers hook with the default timeout.
+func (m *Manager) OnStop(name string, hook Hook) {
+	m.OnStopWithTimeout(name, m.timeout, hook)
+}
+
+func (m *Manager) OnStopWithTimeout(name string, timeout time.Duration, hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.components = append(m.components, component{name: name, timeout: timeout, hook: hook})
+}
+
+// Close adapts an io.Closer style function to a Hook.
+func Close(fn func() error) Hook {
+	return func(context.Context) error {
+		return fn()
+	}
+}
+
+// Check fails once shutdown has begun, so that readiness turns false
+// while components drain.
+func (m *Manager) Check(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shuttingDown {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// Wait blocks until SIGINT or SIGTERM and then shuts down.
+func (m *Manager) Wait() {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	signal.Stop(quit)
+
+	m.logger.With("signal", sig.String()).Info("🛑 Shutting down gracefully...")
+	m.Shutdown()
+}
+
+// Shutdown runs the stop hooks in reverse registration order. It is safe
+// to call more than once; only the first call has an effect.
+func (m *Manager) Shutdown() {
+	m.once.Do(func() {
+		m.mu.Lock()
+		m.shuttingDown = true
+		components := append([]component(nil), m.components...)
+		m.mu.Unlock()
+
+		if m.drainDelay > 0 {
+			time.Sleep(m.drainDelay)
+		}
+
+		start := time.Now()
+		for i := len(components) - 1; i >= 0; i-- {
+			m.stop(components[i])
+		}
+
+		m.logger.With("duration", time.Since(start).String()).Info("✅ Shutdown complete")
+	})
+}
+
+func (m *Manager) stop(c component) {
+	log := m.logger.With("component", c.name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			log.WithError(err).Error("component stopped with error")
+			return
+		}
+		log.With("duration", time.Since(start).String()).Debug("component stopped")
+
+	case <-ctx.Done():
+		// The hook keeps running in the background; nothing more can be
+		// done for it without blocking the remaining components.
+		log.With("timeout", c.timeout.String()).Error(fmt.Sprintf("%s did not stop in time", c.name))
+	}
+}
@@ -4,74 +4,118 @@ import (
 	"context"
 	"os"
 	"strings"
+	"sync/atomic"
 
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// Logger writes structured logs. Loggers derived with With, WithError,
+// WithFields or WithContext share the minimum level of the logger they
+// were derived from, so SetLevel applies to all of them.
 type Logger struct {
 	logger zerolog.Logger
+	level  *atomic.Int32
 }
 
 func New(serviceName, logLevel string) *Logger {
 
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 
-	level := zerolog.InfoLevel
+	logger := zerolog.New(os.Stdout).
+		With().
+		Timestamp().Str("service", serviceName).
+		Logger()
+
+	l := &Logger{logger: logger, level: new(atomic.Int32)}
+	l.SetLevel(logLevel)
+	return l
+}
+
+func parseLevel(logLevel string) zerolog.Level {
 	switch strings.ToLower(logLevel) {
 	case "debug":
-		level = zerolog.DebugLevel
-	case "info":
-		level = zerolog.InfoLevel
+		return zerolog.DebugLevel
 	case "warn", "warning":
-		level = zerolog.WarnLevel
+		return zerolog.WarnLevel
 	case "error":
-		level = zerolog.ErrorLevel
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.InfoLevel
 	}
+}
 
-	logger := zerolog.New(os.Stdout).
-		Level(level).
-		With().
-		Timestamp().Str("service", serviceName).
-		Logger()
+// SetLevel changes the minimum level of the logger and every logger
+// derived from it, e.g. when the configuration is reloaded. Unknown
+// levels fall back to info.
+func (l *Logger) SetLevel(logLevel string) {
+	l.level.Store(int32(parseLevel(logLevel)))
+}
+
+func (l *Logger) enabled(level zerolog.Level) bool {
+	return level >= zerolog.Level(l.level.Load())
+}
 
-	return &Logger{logger: logger}
+func (l *Logger) derive(logger zerolog.Logger) *Logger {
+	return &Logger{logger: logger, level: l.level}
 }
 
+// WithContext adds the trace and the tenant of ctx to the logs.
 func (l *Logger) WithContext(ctx context.Context) *Logger {
 	span := trace.SpanFromContext(ctx)
+	tenantID, hasTenant := tenancy.FromContext(ctx)
+	if !span.SpanContext().IsValid() && !hasTenant {
+		return l
+	}
+
+	logger := l.logger.With()
 	if span.SpanContext().IsValid() {
-		logger := l.logger.With().
+		logger = logger.
 			Str("trace_id", span.SpanContext().TraceID().String()).
-			Str("span_id", span.SpanContext().SpanID().String()).
-			Logger()
-		return &Logger{logger: logger}
+			Str("span_id", span.SpanContext().SpanID().String())
 	}
-	return l
+	if hasTenant {
+		logger = logger.Str("tenant_id", tenantID)
+	}
+	return l.derive(logger.Logger())
+}
+
+// Level returns the effective minimum level of the logger.
+func (l *Logger) Level() string {
+	return zerolog.Level(l.level.Load()).String()
 }
 
 func (l *Logger) Debug(msg string) {
-	l.logger.Debug().Msg(msg)
+	if l.enabled(zerolog.DebugLevel) {
+		l.logger.Debug().Msg(msg)
+	}
 }
 
 func (l *Logger) Info(msg string) {
-	l.logger.Info().Msg(msg)
+	if l.enabled(zerolog.InfoLevel) {
+		l.logger.Info().Msg(msg)
+	}
 }
 
 func (l *Logger) Warn(msg string) {
-	l.logger.Warn().Msg(msg)
+	if l.enabled(zerolog.WarnLevel) {
+		l.logger.Warn().Msg(msg)
+	}
 }
 
 func (l *Logger) Error(msg string) {
-	l.logger.Error().Msg(msg)
+	if l.enabled(zerolog.ErrorLevel) {
+		l.logger.Error().Msg(msg)
+	}
 }
 
 func (l *Logger) With(key, value string) *Logger {
-	return &Logger{logger: l.logger.With().Str(key, value).Logger()}
+	return l.derive(l.logger.With().Str(key, value).Logger())
 }
 
 func (l *Logger) WithError(err error) *Logger {
-	return &Logger{logger: l.logger.With().Err(err).Logger()}
+	return l.derive(l.logger.With().Err(err).Logger())
 }
 
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
@@ -79,7 +123,7 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	for k, v := range fields {
 		event = event.Interface(k, v)
 	}
-	return &Logger{logger: event.Logger()}
+	return l.derive(event.Logger())
 }
 
 var globalLogger *Logger
@@ -0,0 +1,224 @@
+// Package messagebus abstracts the broker services publish events to and
+// consume them from. Kafka is the default; small deployments can run on
+// NATS JetStream or RabbitMQ instead, selected by MESSAGE_BUS.
+package messagebus
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Names of the buses.
+const (
+	Kafka    = "kafka"
+	NATS     = "nats"
+	RabbitMQ = "rabbitmq"
+)
+
+// Headers every bus sends with an event.
+const (
+	MessageTypeHeader = "message-type"
+	// MessageKeyHeader carries the key on brokers without message keys
+	MessageKeyHeader = "message-key"
+)
+
+// Handler handles the encoded event of a message. Failures that are
+// transient per errors.IsTransient are retried; others are given up on.
+type Handler func(ctx context.Context, key, value []byte, headers map[string]string) error
+
+// Publisher publishes events. Events that must be handled in order, such
+// as those of one aggregate, have to share key.
+type Publisher interface {
+	Publish(ctx context.Context, eventType events.EventType, key string, value any) error
+}
+
+// AsyncPublisher is implemented by buses that can queue events and write
+// them in the background; delivery, which may be nil, receives the result.
+type AsyncPublisher interface {
+	PublishAsync(ctx context.Context, eventType events.EventType, key string, value any, delivery func(error)) error
+}
+
+// SubscriptionConfig selects the events a group of consumers handles.
+// Each event is handled by one member of Group, by up to Workers at once;
+// events of one key are handled in order. Events found in Processed are
+// skipped, so redelivered ones are handled once; they are remembered for
+// ProcessedTTL.
+type SubscriptionConfig struct {
+	Group        string
+	EventTypes   []events.EventType
+	Workers      int
+	Processed    ProcessedStore
+	ProcessedTTL time.Duration
+}
+
+// ProcessedStore remembers the events each consumer group handled, so a
+// message redelivered after a rebalance or restart is not handled twice.
+// An event is claimed before it is handled and completed after; a claim
+// that is neither completed nor released lapses after its lease, so the
+// event is handled again when the consumer crashed.
+type ProcessedStore interface {
+	// Claim claims eventID for group. It reports false when the event was
+	// handled already and fails with ErrInProgress while another claim
+	// holds it.
+	Claim(ctx context.Context, group, eventID string, lease time.Duration) (bool, error)
+	// Complete marks a claimed event as handled for ttl.
+	Complete(ctx context.Context, group, eventID string, ttl time.Duration) error
+	// Release gives up a claim when handling the event failed.
+	Release(ctx context.Context, group, eventID string) error
+}
+
+// ErrInProgress marks messages another consumer is handling, e.g. the
+// previous owner of a partition after a rebalance. It is transient, so
+// the message is tried again once that consumer finished or gave up.
+var ErrInProgress = stderrors.New("message is being processed by another consumer")
+
+// ProcessingLease bounds how long the claim on an event outlives a
+// consumer that crashed while handling it.
+const ProcessingLease = 5 * time.Minute
+
+// Subscription consumes the events of a SubscriptionConfig. Start blocks
+// until ctx is done or Shutdown is called; Shutdown waits for the events
+// being handled until ctx is done.
+type Subscription interface {
+	Start(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// MessageBus publishes events and subscribes handlers to them.
+type MessageBus interface {
+	Publisher
+	Subscribe(config SubscriptionConfig, handler Handler) Subscription
+	// Ping fails unless the broker is reachable
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Message is an event encoded for a bus.
+type Message struct {
+	Value   []byte
+	Headers map[string]string
+	// ID of the event, which brokers deduplicate redeliveries by
+	ID string
+}
+
+// Encode validates an event of eventType and encodes it in format, with
+// the headers carrying its type, key, tenant and the trace of ctx.
+func Encode(ctx context.Context, format events.Format, eventType events.EventType, key string, value any) (Message, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if err := events.DefaultRegistry.Validate(payload); err != nil {
+		return Message{}, fmt.Errorf("refusing to publish %s: %w", eventType, err)
+	}
+
+	var envelope struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(payload, &envelope)
+
+	encoded, headers, err := events.EncodeMessage(format, payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to encode message: %w", err)
+	}
+	headers[MessageTypeHeader] = string(eventType)
+	headers[MessageKeyHeader] = key
+	headers[tenancy.MetadataKey] = tenancy.ID(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+
+	return Message{Value: encoded, Headers: headers, ID: envelope.ID}, nil
+}
+
+// Context returns ctx continuing the trace and carrying the tenant of a
+// message with headers.
+func Context(ctx context.Context, headers map[string]string) context.Context {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+	if tenantID := headers[tenancy.MetadataKey]; tenantID != "" {
+		ctx = tenancy.WithID(ctx, tenantID)
+	}
+	return ctx
+}
+
+// Process decodes the event of a message, upcast to the latest version of
+// its type, and hands it to handler on the primary database, retrying
+// transient failures with policy. Events config.Processed knows are
+// skipped.
+func Process(ctx context.Context, config SubscriptionConfig, handler Handler, policy retry.Policy, log *logger.Logger, key, value []byte, headers map[string]string) error {
+	value, err := events.DecodeMessage(value, headers)
+	if err != nil {
+		return err
+	}
+	if value, err = events.DefaultRegistry.Upcast(value); err != nil {
+		return err
+	}
+
+	// Handlers act on the current state, which a replica may lag behind
+	ctx = database.UsePrimary(ctx)
+
+	var envelope struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(value, &envelope)
+	eventID := envelope.ID
+
+	if config.Processed != nil && eventID != "" {
+		claimed, err := config.Processed.Claim(ctx, config.Group, eventID, ProcessingLease)
+		if err != nil {
+			log.WithContext(ctx).WithError(err).With("event_id", eventID).Warn("failed to claim message")
+			return err
+		}
+		if !claimed {
+			log.WithContext(ctx).With("event_id", eventID).Debug("skipping message processed before")
+			return nil
+		}
+	}
+
+	policy.Retryable = errors.IsTransient
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		log.WithContext(ctx).WithError(err).
+			With("attempt", strconv.Itoa(attempt)).
+			With("backoff", delay.String()).
+			Warn("retrying message processing")
+	}
+	err = policy.Do(ctx, func(ctx context.Context) error {
+		return handler(ctx, key, value, headers)
+	})
+
+	if config.Processed != nil && eventID != "" {
+		if err != nil {
+			if err := config.Processed.Release(ctx, config.Group, eventID); err != nil {
+				log.WithContext(ctx).WithError(err).With("event_id", eventID).Warn("failed to release message claim")
+			}
+		} else if err := config.Processed.Complete(ctx, config.Group, eventID, config.ProcessedTTL); err != nil {
+			log.WithContext(ctx).WithError(err).With("event_id", eventID).Warn("failed to mark message processed")
+		}
+	}
+	return err
+}
+
+// Worker assigns every key to a fixed one of workers, so the events of a
+// key are handled in order.
+func Worker(key []byte, workers int) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(workers))
+}
+
+// IsPermanent reports whether retrying a failed message cannot help.
+func IsPermanent(err error) bool {
+	return stderrors.Is(err, events.ErrInvalidEvent) || !errors.IsTransient(err)
+}
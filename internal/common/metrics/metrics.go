@@ -3,10 +3,12 @@ package metrics
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -16,29 +18,87 @@ type Metrics struct {
 	RequestsTotal    *prometheus.CounterVec
 	RequestDuration  *prometheus.HistogramVec
 	RequestsInFlight prometheus.Gauge
+	RateLimited      *prometheus.CounterVec
 
 	// User metrics
 	UsersTotal   *prometheus.CounterVec
 	UsersDeleted *prometheus.CounterVec
 
+	// Authentication metrics. A surge of AuthFailures across many accounts
+	// points at credential stuffing.
+	AuthFailures    *prometheus.CounterVec
+	AccountLockouts *prometheus.CounterVec
+
 	// Business metrics
 	BookingsTotal   *prometheus.CounterVec
 	BookingDuration *prometheus.HistogramVec
 
+	// Cache metrics
+	CacheRequests *prometheus.CounterVec
+
+	// Notification metrics
+	NotificationsTotal *prometheus.CounterVec
+
 	// kafka metrics
 	MessagesProduced *prometheus.CounterVec
 	MessagesConsumed *prometheus.CounterVec
 	MessageErrors    *prometheus.CounterVec
+	// Messages skipped as they were handled before, e.g. redelivered
+	// after a rebalance
+	MessagesDuplicate *prometheus.CounterVec
+	// Messages consumer groups are behind by, per partition, and the
+	// members sharing the partitions of a group
+	ConsumerLag          *prometheus.GaugeVec
+	ConsumerGroupMembers *prometheus.GaugeVec
+	// Messages queued by the async producer and not written yet
+	ProducerQueueSize prometheus.Gauge
+
+	// Event handler metrics
+	EventsHandled         *prometheus.CounterVec
+	EventHandlingDuration *prometheus.HistogramVec
 
-	// Database metrics
-	DBConnections   prometheus.Gauge
+	// Scheduler metrics
+	JobRuns         *prometheus.CounterVec
+	JobDuration     *prometheus.HistogramVec
+	SchedulerLeader prometheus.Gauge
+
+	// Database metrics. DBConnections reports the connection pools added
+	// to it.
+	DBConnections   *PoolCollector
 	DBQueries       *prometheus.CounterVec
 	DBQueryDuration *prometheus.HistogramVec
+
+	// Outbound HTTP metrics
+	HTTPClientRequests *prometheus.CounterVec
+	HTTPClientDuration *prometheus.HistogramVec
+
+	// Resilience metrics
+	CircuitBreakerState       *prometheus.GaugeVec
+	CircuitBreakerTransitions *prometheus.CounterVec
+
+	registry *prometheus.Registry
 }
 
+// New registers the metrics of a service on a registry of its own, so
+// several services can run in one process.
 func New(serviceName string) *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	factory := promauto.With(registry)
+
+	// Metric names may only contain underscores
+	serviceName = strings.ReplaceAll(serviceName, "-", "_")
+
+	dbConnections := newPoolCollector(serviceName)
+	registry.MustRegister(dbConnections)
+
 	return &Metrics{
-		RequestsTotal: promauto.NewCounterVec(
+		registry: registry,
+		RequestsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -47,7 +107,7 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"method", "path", "status"},
 		),
-		RequestDuration: promauto.NewHistogramVec(
+		RequestDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -57,7 +117,7 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"method", "path"},
 		),
-		RequestsInFlight: promauto.NewGauge(
+		RequestsInFlight: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -65,34 +125,61 @@ func New(serviceName string) *Metrics {
 				Help:      "Number of HTTP requests currently being processed",
 			},
 		),
-		UsersTotal: promauto.NewCounterVec(
+		RateLimited: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "http_requests_rate_limited_total",
+				Help:      "Total number of HTTP requests rejected by a rate limit rule",
+			},
+			[]string{"rule"},
+		),
+		UsersTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
 				Name:      "total_users_created",
-				Help:      "Total number of users created",
+				Help:      "Total number of users created by tenant",
 			},
-			[]string{"topic"},
+			[]string{"tenant"},
 		),
-		UsersDeleted: promauto.NewCounterVec(
+		UsersDeleted: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
 				Name:      "total_users_deleted",
-				Help:      "Total number of users deleted",
+				Help:      "Total number of users deleted by tenant",
 			},
-			[]string{"topic"},
+			[]string{"tenant"},
+		),
+		AuthFailures: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "auth_failures_total",
+				Help:      "Total number of rejected logins by reason and tenant",
+			},
+			[]string{"reason", "tenant"},
+		),
+		AccountLockouts: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "account_lockouts_total",
+				Help:      "Total number of accounts locked after failed logins by tenant",
+			},
+			[]string{"tenant"},
 		),
-		BookingsTotal: promauto.NewCounterVec(
+		BookingsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
 				Name:      "booking_total",
 				Help:      "Total number of bookingd",
 			},
-			[]string{"status", "resource_type"},
+			[]string{"status", "resource_type", "tenant"},
 		),
-		BookingDuration: promauto.NewHistogramVec(
+		BookingDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -102,7 +189,25 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"operation"},
 		),
-		MessagesProduced: promauto.NewCounterVec(
+		CacheRequests: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "cache_requests_total",
+				Help:      "Total number of cache lookups by cache and result (hit, miss, error)",
+			},
+			[]string{"cache", "result"},
+		),
+		NotificationsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "notifications_total",
+				Help:      "Total number of notifications by channel, outcome (sent, failed, duplicate) and tenant",
+			},
+			[]string{"channel", "status", "tenant"},
+		),
+		MessagesProduced: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -111,7 +216,7 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"topic"},
 		),
-		MessagesConsumed: promauto.NewCounterVec(
+		MessagesConsumed: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -120,7 +225,7 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"topic"},
 		),
-		MessageErrors: promauto.NewCounterVec(
+		MessageErrors: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -129,15 +234,89 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"topic", "error_type"},
 		),
-		DBConnections: promauto.NewGauge(
+		MessagesDuplicate: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "kafka_messages_duplicate_total",
+				Help:      "Total number of Kafka messages skipped as already processed by consumer group",
+			},
+			[]string{"topic", "consumer_group"},
+		),
+		ConsumerLag: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "kafka_consumer_lag",
+				Help:      "Messages consumer groups have not committed yet, by partition",
+			},
+			[]string{"consumer_group", "topic", "partition"},
+		),
+		ConsumerGroupMembers: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "kafka_consumer_group_members",
+				Help:      "Members of consumer groups",
+			},
+			[]string{"consumer_group"},
+		),
+		ProducerQueueSize: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "kafka_producer_queue_size",
+				Help:      "Messages queued by the async producer",
+			},
+		),
+		EventsHandled: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "events_handled_total",
+				Help:      "Total number of consumed events handled by event type and outcome",
+			},
+			[]string{"event_type", "outcome"},
+		),
+		EventHandlingDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "event_handling_duration_seconds",
+				Help:      "Duration of event handlers in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"event_type"},
+		),
+		JobRuns: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "scheduler_job_runs_total",
+				Help:      "Total number of scheduled job runs by job and outcome",
+			},
+			[]string{"job", "outcome"},
+		),
+		JobDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "scheduler_job_duration_seconds",
+				Help:      "Duration of scheduled job runs in seconds",
+				Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 15, 60, 300},
+			},
+			[]string{"job"},
+		),
+		SchedulerLeader: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
-				Name:      "database_connections",
-				Help:      "Number of active database connection",
+				Name:      "scheduler_leader",
+				Help:      "Whether this instance runs the scheduled jobs (1) or stands by (0)",
 			},
 		),
-		DBQueries: promauto.NewCounterVec(
+		DBConnections: dbConnections,
+		DBQueries: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -146,7 +325,7 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"operation", "status"},
 		),
-		DBQueryDuration: promauto.NewHistogramVec(
+		DBQueryDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: "booking_system",
 				Subsystem: serviceName,
@@ -156,6 +335,43 @@ func New(serviceName string) *Metrics {
 			},
 			[]string{"operation"},
 		),
+		HTTPClientRequests: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "http_client_requests_total",
+				Help:      "Total number of outbound HTTP requests by client, host and status (the code, or error)",
+			},
+			[]string{"client", "host", "status"},
+		),
+		HTTPClientDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "http_client_request_duration_seconds",
+				Help:      "Duration of outbound HTTP requests in seconds, retries included",
+				Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"client", "host"},
+		),
+		CircuitBreakerState: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "circuit_breaker_state",
+				Help:      "Circuit breaker state by dependency (0 closed, 1 half open, 2 open)",
+			},
+			[]string{"dependency"},
+		),
+		CircuitBreakerTransitions: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "booking_system",
+				Subsystem: serviceName,
+				Name:      "circuit_breaker_transitions_total",
+				Help:      "Total number of circuit breaker state changes by dependency and new state",
+			},
+			[]string{"dependency", "state"},
+		),
 	}
 }
 
@@ -184,7 +400,20 @@ func (m *Metrics) GinMiddleware() gin.HandlerFunc {
 	}
 }
 
-// Handler for Prometheus metrics endpoint
+// Handler serves the metrics of the service's registry.
 func (m *Metrics) Handler() http.Handler {
-	return promhttp.Handler()
+	return promhttp.InstrumentMetricHandler(m.registry, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// NewServer returns a server exposing the metrics at /metrics on port,
+// apart from the API.
+func (m *Metrics) NewServer(port string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
 }
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryPerMetrics(t *testing.T) {
+	// Both instances register the same metric names; with the global
+	// registry the second New would panic
+	first := New("booking-service")
+	second := New("booking-service")
+	other := New("user-service")
+
+	first.RequestsTotal.WithLabelValues("GET", "/bookings", "200").Add(3)
+	second.RequestsTotal.WithLabelValues("GET", "/bookings", "200").Add(5)
+
+	tests := []struct {
+		name    string
+		metrics *Metrics
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "first",
+			metrics: first,
+			want:    []string{`booking_system_booking_service_http_requests_total{method="GET",path="/bookings",status="200"} 3`, "go_goroutines"},
+			notWant: []string{"booking_system_user_service_"},
+		},
+		{
+			name:    "second",
+			metrics: second,
+			want:    []string{`booking_system_booking_service_http_requests_total{method="GET",path="/bookings",status="200"} 5`},
+		},
+		{
+			name:    "other service",
+			metrics: other,
+			want:    []string{"booking_system_user_service_"},
+			notWant: []string{"booking_system_booking_service_"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tt.metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+			}
+
+			body, _ := io.ReadAll(recorder.Body)
+			for _, want := range tt.want {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("metrics do not contain %q", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(body), notWant) {
+					t.Errorf("metrics contain %q", notWant)
+				}
+			}
+		})
+	}
+}
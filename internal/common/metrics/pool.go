@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStats are the statistics of a database connection pool.
+type PoolStats struct {
+	Acquired int32
+	Idle     int32
+	Max      int32
+	// Waits counts the acquires that had to wait for a connection, for
+	// WaitDuration in total.
+	Waits        int64
+	WaitDuration time.Duration
+}
+
+// PoolCollector reports the statistics of connection pools, read from the
+// pools whenever the metrics are scraped.
+type PoolCollector struct {
+	connections  *prometheus.Desc
+	waits        *prometheus.Desc
+	waitDuration *prometheus.Desc
+
+	mu    sync.Mutex
+	pools map[string]func() PoolStats
+}
+
+func newPoolCollector(serviceName string) *PoolCollector {
+	name := func(name string) string {
+		return prometheus.BuildFQName("booking_system", serviceName, name)
+	}
+
+	return &PoolCollector{
+		connections: prometheus.NewDesc(name("database_connections"),
+			"Database connections by state", []string{"pool", "state"}, nil),
+		waits: prometheus.NewDesc(name("database_connection_waits_total"),
+			"Database connection acquires that waited for a free connection", []string{"pool"}, nil),
+		waitDuration: prometheus.NewDesc(name("database_connection_wait_seconds_total"),
+			"Time spent waiting for a free database connection", []string{"pool"}, nil),
+		pools: make(map[string]func() PoolStats),
+	}
+}
+
+// Add reports the pool named name, whose statistics stats returns.
+func (c *PoolCollector) Add(name string, stats func() PoolStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pools[name] = stats
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.waits
+	ch <- c.waitDuration
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, stats := range c.pools {
+		s := stats()
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(s.Acquired), name, "acquired")
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(s.Idle), name, "idle")
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(s.Max), name, "max")
+		ch <- prometheus.MustNewConstMetric(c.waits, prometheus.CounterValue, float64(s.Waits), name)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, s.WaitDuration.Seconds(), name)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog logs every request once it completes, with its request ID so
+// it can be followed into the logs of the services it reached. Server
+// errors are logged as errors.
+func AccessLog(logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		log := logger.WithContext(c.Request.Context()).WithFields(map[string]any{
+			"request_id":  c.GetString("request_id"),
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+			"status":      status,
+			"duration_ms": time.Since(start).Milliseconds(),
+			"bytes":       c.Writer.Size(),
+			"client_ip":   c.ClientIP(),
+		})
+		if userID := c.GetString("user_id"); userID != "" {
+			log = log.With("user_id", userID)
+		}
+
+		if status >= 500 {
+			log.Error("request completed")
+			return
+		}
+		log.Info("request completed")
+	}
+}
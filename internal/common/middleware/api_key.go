@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves API keys to the user and tenant they act
+// as.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*auth.APIKeyPrincipal, error)
+}
+
+// APIKeyAuth authenticates requests carrying an X-API-Key header and hands
+// every other request to fallback, normally AuthMiddleware. A key acts as
+// its owner, further limited by the key's scopes.
+func APIKeyAuth(keys APIKeyAuthenticator, fallback gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(APIKeyHeader)
+		if key == "" {
+			fallback(ctx)
+			return
+		}
+
+		principal, err := keys.Authenticate(ctx.Request.Context(), key)
+		if err != nil {
+			response.Error(ctx, err)
+			ctx.Abort()
+			return
+		}
+
+		if !principal.Allows(ctx.Request.Method) {
+			response.Error(ctx, errors.NewForbiddenError("api key scope does not allow this request"))
+			ctx.Abort()
+			return
+		}
+
+		if !authenticateTenant(ctx, principal.TenantID) {
+			return
+		}
+
+		ctx.Set("user_id", principal.UserID)
+		ctx.Set("user_email", principal.UserEmail)
+		ctx.Set("user_role", principal.UserRole)
+		ctx.Set("api_key_id", principal.KeyID)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/gin-gonic/gin"
+)
+
+// Audit opens an audit scope for every request so services can record
+// domain changes with audit.Log. Once the request completes the entries
+// are stamped with the request details and queued on recorder. Mutating
+// requests that recorded nothing, including rejected ones, still produce a
+// single entry named after the route.
+func Audit(recorder *audit.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, scope := audit.NewContext(c.Request.Context(), func() audit.Actor {
+			return audit.Actor{ID: c.GetString("user_id"), Role: c.GetString("user_role")}
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		entries := scope.Entries()
+		if len(entries) == 0 {
+			if !isMutating(c.Request.Method) || c.FullPath() == "" {
+				return
+			}
+
+			actor := scope.Actor()
+			entries = []*audit.Entry{{
+				ActorID:   actor.ID,
+				ActorRole: actor.Role,
+				Action:    c.Request.Method + " " + c.FullPath(),
+			}}
+		}
+
+		for _, entry := range entries {
+			if entry.TenantID == "" {
+				entry.TenantID = tenancy.ID(c.Request.Context())
+			}
+			entry.RequestID = c.GetString("request_id")
+			entry.IPAddress = c.ClientIP()
+			entry.Method = c.Request.Method
+			entry.Path = c.Request.URL.Path
+			entry.StatusCode = c.Writer.Status()
+			recorder.Record(entry)
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/dmehra2102/booking-system/internal/common/errors"
@@ -10,36 +9,70 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware requires a valid, unrevoked access token whose session
+// has not been ended. revocations may be nil for services that do not
+// support logout.
+func AuthMiddleware(jwtSecret string, revocations auth.RevocationStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
-			response.Error(ctx, http.StatusUnauthorized, errors.NewUnauthorizedError("missing authorization header"))
+			response.Error(ctx, errors.NewUnauthorizedError("missing authorization header"))
 			ctx.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			response.Error(ctx, http.StatusUnauthorized, errors.NewUnauthorizedError("invalid authorization format"))
+			response.Error(ctx, errors.NewUnauthorizedError("invalid authorization format"))
 			ctx.Abort()
 			return
 		}
 
 		claims, err := auth.ValidateToken(tokenString, jwtSecret)
 		if err != nil {
-			response.Error(ctx, http.StatusUnauthorized, errors.NewUnauthorizedError("invalid token"))
+			response.Error(ctx, errors.NewUnauthorizedError("invalid token"))
 			ctx.Abort()
 			return
 		}
 
-		ctx.Set("user_id", claims.UserID)
-		ctx.Set("user_email", claims.Email)
+		if revocations != nil {
+			revoked, err := auth.IsTokenRevoked(ctx.Request.Context(), revocations, claims)
+			if err != nil {
+				response.Error(ctx, errors.NewInternalError("failed to verify token", err))
+				ctx.Abort()
+				return
+			}
+			if revoked {
+				response.Error(ctx, errors.NewUnauthorizedError("token has been revoked"))
+				ctx.Abort()
+				return
+			}
+		}
+
+		if !authenticateTenant(ctx, claims.Tenant()) {
+			return
+		}
+
+		setClaims(ctx, claims)
 		ctx.Next()
 	}
 }
 
-func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// VerifyTokenMiddleware rejects requests with an invalid or revoked token
+// like AuthMiddleware and lets requests without one through, for a
+// gateway leaving it to the services which routes require a token.
+func VerifyTokenMiddleware(jwtSecret string, revocations auth.RevocationStore) gin.HandlerFunc {
+	authenticate := AuthMiddleware(jwtSecret, revocations)
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			ctx.Next()
+			return
+		}
+		authenticate(ctx)
+	}
+}
+
+func OptionalAuthMiddleware(jwtSecret string, revocations auth.RevocationStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -50,12 +83,33 @@ func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString != authHeader {
 			claims, err := auth.ValidateToken(tokenString, jwtSecret)
-			if err == nil {
-				ctx.Set("user_id", claims.UserID)
-				ctx.Set("user_email", claims.Email)
+			if err == nil && !isRevoked(ctx, revocations, claims) {
+				if !authenticateTenant(ctx, claims.Tenant()) {
+					return
+				}
+				setClaims(ctx, claims)
 			}
 		}
 
 		ctx.Next()
 	}
 }
+
+func isRevoked(ctx *gin.Context, revocations auth.RevocationStore, claims *auth.Claims) bool {
+	if revocations == nil {
+		return false
+	}
+	revoked, err := auth.IsTokenRevoked(ctx.Request.Context(), revocations, claims)
+	return err != nil || revoked
+}
+
+func setClaims(ctx *gin.Context, claims *auth.Claims) {
+	ctx.Set("user_id", claims.UserID)
+	ctx.Set("user_email", claims.Email)
+	ctx.Set("user_role", claims.Role)
+	ctx.Set("token_id", claims.ID)
+	ctx.Set("session_id", claims.SessionID)
+	if claims.ExpiresAt != nil {
+		ctx.Set("token_expires_at", claims.ExpiresAt.Time)
+	}
+}
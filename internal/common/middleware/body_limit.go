@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize refuses requests whose body is larger than limit bytes with
+// 413. Bodies of unknown length are cut off at the limit, which fails
+// binding them with an *http.MaxBytesError that response.ValidationError
+// answers with 413 as well.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			response.Error(c, errors.NewPayloadTooLargeError("request body is too large"))
+			c.Abort()
+			return
+		}
+
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "[REDACTED]"
+
+// jsonMember matches the members of a JSON object whose value is a string
+// or a scalar, to redact bodies cut off at the size limit, which do not
+// parse.
+var jsonMember = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"?|[^\s,}\]"]+)`)
+
+// bodyLogWriter keeps the first limit bytes of a response.
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *bodyLogWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) keep(data []byte) {
+	if room := w.limit + 1 - w.body.Len(); room > 0 {
+		w.body.Write(data[:min(len(data), room)])
+	}
+}
+
+// BodyLog logs the request and response bodies of each request with its
+// request ID while enabled reports true, which is checked per request so
+// logging can be switched on by a configuration reload. Only the first
+// limit bytes of each body are logged, and the values of fields whose name
+// contains one of redact, in any case, are replaced. Bodies that are not
+// JSON, form or text, and event streams, are left out.
+func BodyLog(log *logger.Logger, enabled func() bool, limit int, redact []string) gin.HandlerFunc {
+	redact = lowerAll(redact)
+
+	return func(c *gin.Context) {
+		if !enabled() || strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			c.Next()
+			return
+		}
+
+		var requestBody []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			requestBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(requestBody), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+
+		c.Next()
+
+		log.WithContext(c.Request.Context()).WithFields(map[string]any{
+			"request_id":    c.GetString("request_id"),
+			"method":        c.Request.Method,
+			"path":          c.Request.URL.Path,
+			"status":        writer.Status(),
+			"request_body":  loggedBody(requestBody, c.Request.Header.Get("Content-Type"), limit, redact),
+			"response_body": loggedBody(writer.body.Bytes(), writer.Header().Get("Content-Type"), limit, redact),
+		}).Info("request bodies")
+	}
+}
+
+// readCloser reads the part of a body that was logged before the rest.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// loggedBody returns body as it is logged: redacted, cut to limit bytes and
+// marked as truncated when it was longer.
+func loggedBody(body []byte, contentType string, limit int, redact []string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	truncated := len(body) > limit
+	if truncated {
+		body = body[:limit]
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var logged string
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		logged = redactJSON(body, redact)
+	case mediaType == "application/x-www-form-urlencoded":
+		logged = redactForm(body, redact)
+	case strings.HasPrefix(mediaType, "text/"):
+		logged = string(body)
+	case mediaType == "":
+		return "[body without content type omitted]"
+	default:
+		return fmt.Sprintf("[%s body omitted]", mediaType)
+	}
+
+	if truncated {
+		logged += "…[truncated]"
+	}
+	return logged
+}
+
+func redactJSON(body []byte, redact []string) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		if encoded, err := json.Marshal(redactValue(value, redact)); err == nil {
+			return string(encoded)
+		}
+	}
+
+	return jsonMember.ReplaceAllStringFunc(string(body), func(member string) string {
+		parts := jsonMember.FindStringSubmatch(member)
+		if !sensitive(parts[1], redact) {
+			return member
+		}
+		return `"` + parts[1] + `"` + parts[2] + `"` + redacted + `"`
+	})
+}
+
+func redactValue(value any, redact []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if sensitive(key, redact) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field, redact)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item, redact)
+		}
+	}
+	return value
+}
+
+func redactForm(body []byte, redact []string) string {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "[malformed form body omitted]"
+	}
+	for key := range values {
+		if sensitive(key, redact) {
+			values[key] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
+
+func sensitive(name string, redact []string) bool {
+	name = strings.ToLower(name)
+	for _, part := range redact {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Errors lets handlers return errors with c.Error instead of answering
+// them: when a request ends with an error and no response, the last error
+// is answered with the status errors.HTTPStatus maps it to. Server errors
+// are logged with their cause. With hideInternal, as in production, they
+// are answered with a generic message.
+func Errors(logger *logger.Logger, hideInternal bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hideInternal {
+			response.HideInternalErrors(c)
+		}
+
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil {
+			return
+		}
+		if !c.Writer.Written() {
+			response.Error(c, last.Err)
+		}
+
+		if errors.HTTPStatus(last.Err) >= http.StatusInternalServerError {
+			logger.WithContext(c.Request.Context()).WithError(last.Err).WithFields(map[string]any{
+				"request_id": c.GetString("request_id"),
+				"method":     c.Request.Method,
+				"path":       c.Request.URL.Path,
+			}).Error("request failed")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/featureflags"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlags evaluates the flags for the tenant and user of a request
+// and puts them in its context, where featureflags.Enabled reads them. It
+// must run after AuthMiddleware to target users.
+func FeatureFlags(flags *featureflags.Flags) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		set := flags.Evaluate(tenancy.ID(ctx.Request.Context()), ctx.GetString("user_id"))
+		ctx.Request = ctx.Request.WithContext(featureflags.WithSet(ctx.Request.Context(), set))
+		ctx.Next()
+	}
+}
+
+// RequireFeature answers 404 to requests flag is off for, hiding a route
+// from tenants and users it has not been rolled out to. It must run after
+// FeatureFlags.
+func RequireFeature(flag featureflags.Flag) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !featureflags.Enabled(ctx.Request.Context(), flag) {
+			response.Error(ctx, errors.NewNotFoundError("route"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
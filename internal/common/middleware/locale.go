@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale puts the supported locale that suits the Accept-Language header
+// of a request best in its context, which error messages are translated
+// into, and names it in the Content-Language header.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Default.Match(c.GetHeader("Accept-Language"))
+
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
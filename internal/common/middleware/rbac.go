@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets requests through whose authenticated role is one of
+// roles. It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !HasRole(ctx, roles...) {
+			response.Error(ctx, errors.NewForbiddenError("insufficient permissions"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// HasRole reports whether the authenticated user has one of roles.
+func HasRole(ctx *gin.Context, roles ...string) bool {
+	role := ctx.GetString("user_role")
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/gin-gonic/gin"
+)
+
+// ReadYourWrites sends the reads of requests that change state to the
+// Postgres primary, so they see the writes they make and validate writes
+// against current data. Safe requests may read from a replica.
+func ReadYourWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			c.Request = c.Request.WithContext(database.UsePrimary(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"runtime/debug"
+	"strconv"
 
 	"github.com/dmehra2102/booking-system/internal/common/errors"
 	"github.com/dmehra2102/booking-system/internal/common/logger"
@@ -14,16 +15,32 @@ func Recovery(logger *logger.Logger) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
+				// Handlers such as reverse proxies abort on purpose
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
 				stack := debug.Stack()
-				logger.WithContext(ctx.Request.Context()).
+				log := logger.WithContext(ctx.Request.Context()).
 					WithFields(map[string]any{
 						"panic": err,
 						"stack": string(stack),
 						"path":  ctx.Request.URL.Path,
-					}).
-					Error("panic recovered")
+					})
+
+				// The handler already started the response, so a JSON error
+				// would only be appended to a truncated body. Aborting the
+				// handler makes net/http drop the connection, so the client
+				// cannot take the truncated body for a complete one.
+				if ctx.Writer.Written() {
+					log.With("status", strconv.Itoa(ctx.Writer.Status())).
+						Error("panic recovered after response was written, aborting")
+					panic(http.ErrAbortHandler)
+				}
+
+				log.Error("panic recovered")
 
-				response.Error(ctx, http.StatusInternalServerError, errors.NewInternalError("internal server error", nil))
+				response.Error(ctx, errors.NewInternalError("internal server error", nil))
 				ctx.Abort()
 			}
 		}()
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/testutil"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		timeout     bool
+		handler     gin.HandlerFunc
+		wantStatus  int
+		wantBody    string
+		wantAborted bool
+		wantLog     string
+	}{
+		{
+			name:       "panic before writing",
+			handler:    func(c *gin.Context) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "internal server error",
+			wantLog:    `"message":"panic recovered"`,
+		},
+		{
+			name: "panic after partial body",
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "partial")
+				c.Writer.Flush()
+				panic("boom")
+			},
+			wantAborted: true,
+			wantLog:     "panic recovered after response was written, aborting",
+		},
+		{
+			name:    "panic after partial body behind timeout",
+			timeout: true,
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, "partial")
+				c.Writer.Flush()
+				panic("boom")
+			},
+			wantAborted: true,
+			wantLog:     "panic recovered after response was written, aborting",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				status  int
+				body    []byte
+				readErr error
+			)
+			logs := testutil.CaptureStdout(t, func() {
+				router := gin.New()
+				router.Use(Recovery(logger.New("test", "info")))
+				if tt.timeout {
+					router.Use(Timeout(time.Second))
+				}
+				router.GET("/", tt.handler)
+
+				server := httptest.NewServer(router)
+				defer server.Close()
+
+				resp, err := server.Client().Get(server.URL)
+				if err != nil {
+					t.Fatalf("GET error = %v", err)
+				}
+				defer resp.Body.Close()
+
+				status = resp.StatusCode
+				body, readErr = io.ReadAll(resp.Body)
+			})
+
+			if tt.wantAborted {
+				// The body was flushed in chunks, so the dropped connection
+				// shows as a missing final chunk
+				if readErr == nil {
+					t.Errorf("body = %q read completely, want the connection aborted", body)
+				}
+			} else {
+				if readErr != nil {
+					t.Fatalf("reading body error = %v", readErr)
+				}
+				if status != tt.wantStatus {
+					t.Errorf("status = %d, want %d", status, tt.wantStatus)
+				}
+				if !strings.Contains(string(body), tt.wantBody) {
+					t.Errorf("body = %q, want it to contain %q", body, tt.wantBody)
+				}
+			}
+			if !strings.Contains(logs, tt.wantLog) {
+				t.Errorf("logs = %q, want them to contain %q", logs, tt.wantLog)
+			}
+		})
+	}
+}
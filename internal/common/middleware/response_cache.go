@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxCachedResponseSize keeps large responses, such as exports, out of the
+// response cache.
+const maxCachedResponseSize = 1 << 20
+
+// ResponseCacheRule caches the responses of a group of routes for TTL.
+// Name separates the entries of different rules and labels the cache
+// metrics. Responses of a rule with a ScopeParam are invalidated per value
+// of that route parameter, such as the resource of an availability route,
+// and all together otherwise.
+type ResponseCacheRule struct {
+	Name       string
+	TTL        time.Duration
+	ScopeParam string
+}
+
+// ResponseCache stores the successful responses of expensive idempotent
+// GET routes in Redis, keyed by tenant, path and query. Entries are
+// invalidated by bumping a generation counter that is part of their key,
+// so stale entries are never read again and expire on their own. Like the
+// entity caches it is best effort: Redis failures are logged by the client
+// and the request is served by its handler.
+type ResponseCache struct {
+	redis   *database.RedisClient
+	metrics *metrics.Metrics
+	log     *logger.Logger
+	rule    ResponseCacheRule
+}
+
+func NewResponseCache(redis *database.RedisClient, m *metrics.Metrics, log *logger.Logger, rule ResponseCacheRule) *ResponseCache {
+	return &ResponseCache{redis: redis, metrics: m, log: log, rule: rule}
+}
+
+// cachedResponse is a response as it is stored in Redis.
+type cachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	ETag        string `json:"etag,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// responseCacheWriter keeps a copy of the response body until it grows
+// past maxCachedResponseSize.
+type responseCacheWriter struct {
+	gin.ResponseWriter
+	body     []byte
+	tooLarge bool
+}
+
+func (w *responseCacheWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCacheWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseCacheWriter) keep(data []byte) {
+	if w.tooLarge {
+		return
+	}
+	if len(w.body)+len(data) > maxCachedResponseSize {
+		w.tooLarge, w.body = true, nil
+		return
+	}
+	w.body = append(w.body, data...)
+}
+
+// Handler answers GET requests from the cache, sets X-Cache to HIT or MISS,
+// and stores 200 responses of misses. Requests sent with
+// "Cache-Control: no-cache" skip the lookup but still refresh the entry.
+// A rule with a non-positive TTL is disabled.
+func (rc *ResponseCache) Handler() gin.HandlerFunc {
+	rule := rc.rule
+
+	return func(c *gin.Context) {
+		if rule.TTL <= 0 || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		scope := ""
+		if rule.ScopeParam != "" {
+			scope = c.Param(rule.ScopeParam)
+		}
+
+		generation, err := rc.redis.Get(ctx, rc.generationKey(ctx, scope))
+		if err != nil && err != redis.Nil {
+			rc.metrics.CacheRequests.WithLabelValues(rule.Name, "error").Inc()
+			c.Next()
+			return
+		}
+		key := rc.entryKey(ctx, scope, generation, c.Request)
+
+		if !strings.Contains(strings.ToLower(c.GetHeader("Cache-Control")), "no-cache") && rc.serve(c, key) {
+			c.Abort()
+			return
+		}
+
+		c.Header("X-Cache", "MISS")
+		writer := &responseCacheWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.Status() != http.StatusOK || writer.tooLarge || len(c.Errors) > 0 {
+			return
+		}
+		raw, err := json.Marshal(cachedResponse{
+			Status:      writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			ETag:        writer.Header().Get("ETag"),
+			Body:        writer.body,
+		})
+		if err != nil {
+			rc.log.WithContext(ctx).WithError(err).With("cache", rule.Name).Warn("failed to encode cached response")
+			return
+		}
+
+		// Failures are already logged by the redis client
+		_ = rc.redis.Set(ctx, key, raw, rule.TTL)
+	}
+}
+
+// serve answers the request with the response cached under key and
+// reports whether there was one.
+func (rc *ResponseCache) serve(c *gin.Context, key string) bool {
+	ctx := c.Request.Context()
+
+	raw, err := rc.redis.Get(ctx, key)
+	if err != nil {
+		if err != redis.Nil {
+			rc.metrics.CacheRequests.WithLabelValues(rc.rule.Name, "error").Inc()
+		} else {
+			rc.metrics.CacheRequests.WithLabelValues(rc.rule.Name, "miss").Inc()
+		}
+		return false
+	}
+
+	var cached cachedResponse
+	if err := json.Unmarshal([]byte(raw), &cached); err != nil {
+		rc.log.WithContext(ctx).WithError(err).With("cache", rc.rule.Name).Warn("failed to decode cached response")
+		rc.metrics.CacheRequests.WithLabelValues(rc.rule.Name, "error").Inc()
+		return false
+	}
+
+	rc.metrics.CacheRequests.WithLabelValues(rc.rule.Name, "hit").Inc()
+	c.Header("X-Cache", "HIT")
+	if cached.ETag != "" && response.NotModified(c, cached.ETag) {
+		return true
+	}
+	c.Data(cached.Status, cached.ContentType, cached.Body)
+	return true
+}
+
+// Invalidate drops the cached responses of scope, the value of the rule's
+// ScopeParam, for the tenant of ctx. Rules without a ScopeParam are
+// invalidated with an empty scope.
+func (rc *ResponseCache) Invalidate(ctx context.Context, scope string) error {
+	_, err := rc.redis.Incr(ctx, rc.generationKey(ctx, scope))
+	return err
+}
+
+func (rc *ResponseCache) generationKey(ctx context.Context, scope string) string {
+	return "httpcache:" + rc.rule.Name + ":" + tenancy.ID(ctx) + ":" + scope + ":generation"
+}
+
+// entryKey hashes the path and the query, with its parameters sorted so
+// that their order does not matter.
+func (rc *ResponseCache) entryKey(ctx context.Context, scope, generation string, r *http.Request) string {
+	sum := sha256.Sum256([]byte(r.URL.Path + "?" + r.URL.Query().Encode()))
+	return "httpcache:" + rc.rule.Name + ":" + tenancy.ID(ctx) + ":" + scope + ":" + generation + ":" + hex.EncodeToString(sum[:])
+}
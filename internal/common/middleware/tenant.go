@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Tenant puts the tenant named by the X-Tenant-ID header in the request
+// context, for anonymous requests such as logins and catalog browsing.
+// Requests without the header act for tenancy.DefaultID. Authentication
+// replaces the tenant with the one of the token or API key.
+func Tenant() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(tenancy.Header)
+		if id == "" {
+			ctx.Next()
+			return
+		}
+
+		if err := uuid.Validate(id); err != nil {
+			response.Error(ctx, errors.NewValidationError("invalid "+tenancy.Header+" header: must be a UUID", nil))
+			ctx.Abort()
+			return
+		}
+
+		setTenant(ctx, id)
+		ctx.Next()
+	}
+}
+
+// RequireTenant only lets requests of the tenant id through, e.g.
+// tenancy.DefaultID for the administration of tenants. It must run after
+// AuthMiddleware.
+func RequireTenant(id string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if tenancy.ID(ctx.Request.Context()) != id {
+			response.Error(ctx, errors.NewForbiddenError("insufficient permissions"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// authenticateTenant sets the tenant of the credentials of a request. It
+// aborts the request and returns false when the X-Tenant-ID header names
+// another tenant.
+func authenticateTenant(ctx *gin.Context, id string) bool {
+	if header := ctx.GetHeader(tenancy.Header); header != "" && header != id {
+		response.Error(ctx, errors.NewForbiddenError("credentials belong to another tenant"))
+		ctx.Abort()
+		return false
+	}
+
+	setTenant(ctx, id)
+	return true
+}
+
+func setTenant(ctx *gin.Context, id string) {
+	ctx.Set("tenant_id", id)
+	ctx.Request = ctx.Request.WithContext(tenancy.WithID(ctx.Request.Context(), id))
+}
@@ -2,7 +2,7 @@ package middleware
 
 import (
 	"context"
-	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dmehra2102/booking-system/internal/common/errors"
@@ -10,18 +10,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Timeout answers 504 to requests not served within timeout. Event streams
+// and downloads are left to run until their handler or the client ends
+// them.
 func Timeout(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if isStreaming(c) {
+			c.Next()
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 
 		c.Request = c.Request.WithContext(ctx)
 
-		finished := make(chan struct{})
+		// Buffered so the handler can finish after a timeout was answered
+		finished := make(chan struct{}, 1)
+		panicked := make(chan any, 1)
 		go func() {
 			defer func() {
-				if err := recover(); err != nil {
-					// TODO : Handle panic
+				if p := recover(); p != nil {
+					panicked <- p
 				}
 			}()
 			c.Next()
@@ -30,12 +40,32 @@ func Timeout(timeout time.Duration) gin.HandlerFunc {
 
 		select {
 		case <-finished:
+		case p := <-panicked:
+			// Panic again in the goroutine of the request, so Recovery
+			// handles it
+			panic(p)
 		case <-ctx.Done():
 			if ctx.Err() == context.DeadlineExceeded {
-				response.Error(c, http.StatusGatewayTimeout,
-					errors.NewInternalError("request timeout", ctx.Err()))
+				response.Error(c, errors.NewTimeoutError("request timeout", ctx.Err()))
 				c.Abort()
 			}
 		}
 	}
 }
+
+// streamingSuffixes end the paths of event streams and of exports and
+// downloads, which are written while they are generated or read.
+var streamingSuffixes = []string{"/stream", "/export", "/download"}
+
+func isStreaming(c *gin.Context) bool {
+	if strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		return true
+	}
+	path := strings.TrimRight(c.Request.URL.Path, "/")
+	for _, suffix := range streamingSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/gin-gonic/gin"
+)
+
+// timingWriter sets the Server-Timing header right before the status line
+// is written, once the handler has recorded its phases.
+type timingWriter struct {
+	gin.ResponseWriter
+	breadcrumbs *timing.Breadcrumbs
+}
+
+func (w *timingWriter) WriteHeader(code int) {
+	if !w.breadcrumbs.Empty() {
+		w.Header().Set("Server-Timing", w.breadcrumbs.ServerTiming())
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Timing collects per-phase breadcrumbs for each request and logs them once
+// the request completes. When exposeHeader reports true the phases are
+// also returned to the client in a Server-Timing header; it is checked per
+// request so the header can be toggled by a configuration reload.
+func Timing(log *logger.Logger, exposeHeader func() bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, breadcrumbs := timing.NewContext(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		if exposeHeader() {
+			c.Writer = &timingWriter{ResponseWriter: c.Writer, breadcrumbs: breadcrumbs}
+		}
+
+		c.Next()
+
+		if breadcrumbs.Empty() {
+			return
+		}
+
+		log.WithContext(c.Request.Context()).
+			WithFields(breadcrumbs.Fields()).
+			With("method", c.Request.Method).
+			With("path", c.FullPath()).
+			Debug("request phase timings")
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/testutil"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTiming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// createBooking spends time in the phases of a create request
+	createBooking := func(c *gin.Context) {
+		for _, phase := range []string{"validation", "db", "kafka"} {
+			done := timing.Track(c.Request.Context(), phase)
+			time.Sleep(time.Millisecond)
+			done()
+		}
+		c.Status(http.StatusCreated)
+	}
+
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		expose     bool
+		wantLogs   []string
+		wantHeader []string
+	}{
+		{
+			name:     "create request",
+			handler:  createBooking,
+			wantLogs: []string{"request phase timings", `"validation_ms":`, `"db_ms":`, `"kafka_ms":`, `"path":"/bookings"`},
+		},
+		{
+			name:       "create request with header",
+			handler:    createBooking,
+			expose:     true,
+			wantLogs:   []string{`"db_ms":`, `"kafka_ms":`},
+			wantHeader: []string{"validation;dur=", "db;dur=", "kafka;dur="},
+		},
+		{
+			name:    "no phases",
+			handler: func(c *gin.Context) { c.Status(http.StatusCreated) },
+			expose:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorder *httptest.ResponseRecorder
+			logs := testutil.CaptureStdout(t, func() {
+				router := gin.New()
+				router.Use(Timing(logger.New("test", "debug"), func() bool { return tt.expose }))
+				router.POST("/bookings", tt.handler)
+
+				recorder = httptest.NewRecorder()
+				router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/bookings", nil))
+			})
+
+			for _, want := range tt.wantLogs {
+				if !strings.Contains(logs, want) {
+					t.Errorf("logs = %q, want them to contain %q", logs, want)
+				}
+			}
+			if len(tt.wantLogs) == 0 && logs != "" {
+				t.Errorf("logs = %q, want none", logs)
+			}
+
+			header := recorder.Header().Get("Server-Timing")
+			for _, want := range tt.wantHeader {
+				if !strings.Contains(header, want) {
+					t.Errorf("Server-Timing = %q, want it to contain %q", header, want)
+				}
+			}
+			if len(tt.wantHeader) == 0 && header != "" {
+				t.Errorf("Server-Timing = %q, want none", header)
+			}
+		})
+	}
+}
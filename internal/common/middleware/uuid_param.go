@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UUIDParams rejects requests whose named path parameters are present but
+// are not valid UUIDs, so malformed ids never reach the database.
+func UUIDParams(names ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		for _, name := range names {
+			value := ctx.Param(name)
+			if value == "" {
+				continue
+			}
+
+			if err := uuid.Validate(value); err != nil {
+				response.Error(ctx, errors.NewValidationError(fmt.Sprintf("invalid %s: must be a UUID", name), err))
+				ctx.Abort()
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
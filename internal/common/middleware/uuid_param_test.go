@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUUIDParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "valid id",
+			path:       "/bookings/6f1c2a8e-3b7d-4e59-9a0c-2d4f6b8e1a3c",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "upper case id",
+			path:       "/bookings/6F1C2A8E-3B7D-4E59-9A0C-2D4F6B8E1A3C",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "malformed id",
+			path:       "/bookings/not-a-uuid",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "numeric id",
+			path:       "/bookings/42",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "truncated id",
+			path:       "/bookings/6f1c2a8e-3b7d-4e59-9a0c-2d4f6b8e1a3",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			router := gin.New()
+			router.GET("/bookings/:id", UUIDParams("id"), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if wantReached := tt.wantStatus == http.StatusOK; reached != wantReached {
+				t.Errorf("handler reached = %v, want %v", reached, wantReached)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+// Package nats implements the message bus on NATS JetStream.
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Subjects of the stream. Events are published to "events.<type>";
+// subscriptions park the ones they can never handle under "dlq.", for
+// inspection and replay.
+const (
+	subjectPrefix    = "events."
+	deadLetterPrefix = "dlq."
+)
+
+const (
+	workerQueueSize = 16
+
+	// duplicateWindow is how long the stream remembers the IDs of
+	// published events, so a publish retried after a lost ack is stored
+	// once.
+	duplicateWindow = 2 * time.Minute
+)
+
+// Config configures a Bus. Events are kept in Stream for Retention, or
+// until the limits of the server when it is zero, and published in
+// Format. Publishes and failed messages are retried with Retry.
+type Config struct {
+	URL       string
+	Stream    string
+	Retention time.Duration
+	Format    events.Format
+	Retry     retry.Policy
+}
+
+// Bus is the messagebus.MessageBus on NATS JetStream. Every subscription
+// is a durable consumer of the stream named after its group, so its
+// members share the events and resume where the group left off.
+type Bus struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	config  Config
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+	tracer  trace.Tracer
+}
+
+// New connects to the server at config.URL and creates or updates the
+// stream of events.
+func New(ctx context.Context, config Config, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) (*Bus, error) {
+	conn, err := nats.Connect(config.URL,
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.WithError(err).Warn("disconnected from nats")
+			}
+		}),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			logger.Info("reconnected to nats")
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       config.Stream,
+		Subjects:   []string{subjectPrefix + ">", deadLetterPrefix + subjectPrefix + ">"},
+		Storage:    jetstream.FileStorage,
+		MaxAge:     config.Retention,
+		Duplicates: duplicateWindow,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create stream %s: %w", config.Stream, err)
+	}
+
+	return &Bus{
+		conn:    conn,
+		js:      js,
+		config:  config,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+	}, nil
+}
+
+func (b *Bus) Publish(ctx context.Context, eventType events.EventType, key string, value any) (err error) {
+	subject := subjectPrefix + string(eventType)
+
+	ctx, span := b.tracer.Start(ctx, "nats.publish", trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(
+		semconv.MessagingSystemKey.String(messagebus.NATS),
+		semconv.MessagingDestinationName(subject),
+	))
+	defer tracing.End(span, &err)
+
+	msg, err := messagebus.Encode(ctx, b.config.Format, eventType, key, value)
+	if err != nil {
+		b.metrics.MessageErrors.WithLabelValues(subject, "serialization").Inc()
+		return err
+	}
+
+	if err := b.publish(ctx, subject, msg.Value, msg.Headers, msg.ID); err != nil {
+		b.metrics.MessageErrors.WithLabelValues(subject, "produce").Inc()
+		return fmt.Errorf("failed to publish %s: %w", eventType, err)
+	}
+
+	b.metrics.MessagesProduced.WithLabelValues(subject).Inc()
+	return nil
+}
+
+func (b *Bus) publish(ctx context.Context, subject string, value []byte, headers map[string]string, id string) error {
+	msg := nats.NewMsg(subject)
+	msg.Data = value
+	for name, value := range headers {
+		msg.Header.Set(name, value)
+	}
+
+	var opts []jetstream.PublishOpt
+	if id != "" {
+		opts = append(opts, jetstream.WithMsgID(id))
+	}
+
+	return b.config.Retry.Do(ctx, func(ctx context.Context) error {
+		_, err := b.js.PublishMsg(ctx, msg, opts...)
+		return err
+	})
+}
+
+// Subscribe returns a subscription to the subjects of config.EventTypes.
+// Messages are acknowledged once handled; transient failures are
+// redelivered after the retry policy's MaxInterval, others are copied to
+// the dead letter subject and dropped.
+func (b *Bus) Subscribe(config messagebus.SubscriptionConfig, handler messagebus.Handler) messagebus.Subscription {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+
+	return &subscription{
+		bus:     b,
+		config:  config,
+		handler: handler,
+		logger:  b.logger.With("consumer_group", config.Group),
+		stop:    make(chan struct{}),
+	}
+}
+
+func (b *Bus) Ping(ctx context.Context) error {
+	_, err := b.js.AccountInfo(ctx)
+	return err
+}
+
+// Close publishes the buffered messages and closes the connection.
+func (b *Bus) Close() error {
+	return b.conn.Drain()
+}
+
+type subscription struct {
+	bus     *Bus
+	config  messagebus.SubscriptionConfig
+	handler messagebus.Handler
+	logger  *logger.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	running  sync.WaitGroup
+}
+
+// Start consumes messages until ctx is cancelled or Shutdown is called.
+func (s *subscription) Start(ctx context.Context) error {
+	s.running.Add(1)
+	defer s.running.Done()
+
+	subjects := make([]string, len(s.config.EventTypes))
+	for i, eventType := range s.config.EventTypes {
+		subjects[i] = subjectPrefix + string(eventType)
+	}
+
+	consumer, err := s.bus.js.CreateOrUpdateConsumer(ctx, s.bus.config.Stream, jetstream.ConsumerConfig{
+		Durable:        s.config.Group,
+		FilterSubjects: subjects,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        messagebus.ProcessingLease,
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+		MaxAckPending:  s.config.Workers * workerQueueSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consumer %s: %w", s.config.Group, err)
+	}
+
+	s.logger.With("workers", strconv.Itoa(s.config.Workers)).Info("starting nats consumer")
+
+	queues := make([]chan jetstream.Msg, s.config.Workers)
+	for i := range queues {
+		queues[i] = make(chan jetstream.Msg, workerQueueSize)
+	}
+
+	consuming, err := consumer.Consume(func(msg jetstream.Msg) {
+		key := []byte(msg.Headers().Get(messagebus.MessageKeyHeader))
+		select {
+		case queues[messagebus.Worker(key, len(queues))] <- msg:
+		case <-s.stop:
+			// Left unacknowledged; it is redelivered
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", s.config.Group, err)
+	}
+	defer consuming.Stop()
+
+	var workers sync.WaitGroup
+	for _, queue := range queues {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			s.work(ctx, queue)
+		}()
+	}
+	defer workers.Wait()
+
+	select {
+	case <-ctx.Done():
+		s.logger.Info("consumer context cancelled, shutting down")
+		return ctx.Err()
+	case <-s.stop:
+		s.logger.Info("consumer stopped")
+		return nil
+	}
+}
+
+func (s *subscription) work(ctx context.Context, queue <-chan jetstream.Msg) {
+	for {
+		select {
+		case msg := <-queue:
+			s.handle(ctx, msg)
+		case <-s.stop:
+			// Queued messages stay unacknowledged and are redelivered
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handle processes msg and acknowledges it. A message that still fails
+// with a transient error once retries are exhausted is redelivered after a
+// pause rather than being lost. One that can never succeed is
+// dead-lettered first.
+func (s *subscription) handle(ctx context.Context, msg jetstream.Msg) {
+	subject := msg.Subject()
+	headers := make(map[string]string, len(msg.Headers()))
+	for name := range msg.Headers() {
+		headers[name] = msg.Headers().Get(name)
+	}
+
+	ctx = messagebus.Context(ctx, headers)
+	ctx, span := s.bus.tracer.Start(ctx, "nats.consume."+subject, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		semconv.MessagingSystemKey.String(messagebus.NATS),
+		semconv.MessagingDestinationName(subject),
+	))
+	defer span.End()
+
+	key := []byte(headers[messagebus.MessageKeyHeader])
+	err := messagebus.Process(ctx, s.config, s.handler, s.bus.config.Retry, s.logger, key, msg.Data(), headers)
+	if err == nil {
+		s.bus.metrics.MessagesConsumed.WithLabelValues(subject).Inc()
+		if err := msg.Ack(); err != nil {
+			s.logger.WithContext(ctx).WithError(err).With("subject", subject).Error("failed to acknowledge message")
+		}
+		return
+	}
+
+	s.bus.metrics.MessageErrors.WithLabelValues(subject, "process").Inc()
+	tracing.RecordError(span, err)
+
+	if !messagebus.IsPermanent(err) {
+		s.logger.WithContext(ctx).WithError(err).With("subject", subject).Error("failed to process message after retries")
+		msg.NakWithDelay(s.bus.config.Retry.MaxInterval)
+		return
+	}
+
+	s.logger.WithContext(ctx).WithError(err).With("subject", subject).Error("skipping message that cannot be processed")
+	if err := s.deadLetter(ctx, msg, headers, err); err != nil {
+		s.logger.WithContext(ctx).WithError(err).With("subject", subject).Error("failed to dead-letter message")
+		msg.NakWithDelay(s.bus.config.Retry.MaxInterval)
+		return
+	}
+	msg.Term()
+}
+
+// deadLetter copies msg to the dead letter subject of its subject,
+// recording why it failed in its headers.
+func (s *subscription) deadLetter(ctx context.Context, msg jetstream.Msg, headers map[string]string, cause error) error {
+	subject := deadLetterPrefix + msg.Subject()
+	headers["dlq-consumer-group"] = s.config.Group
+	headers["dlq-error"] = cause.Error()
+
+	if err := s.bus.publish(ctx, subject, msg.Data(), headers, ""); err != nil {
+		s.bus.metrics.MessageErrors.WithLabelValues(subject, "produce").Inc()
+		return err
+	}
+
+	s.bus.metrics.MessagesProduced.WithLabelValues(subject).Inc()
+	s.logger.WithContext(ctx).WithError(cause).With("subject", subject).Warn("message dead-lettered")
+	return nil
+}
+
+// Shutdown stops consuming and waits for the messages being handled until
+// ctx is done.
+func (s *subscription) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
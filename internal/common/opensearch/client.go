@@ -0,0 +1,214 @@
+// Package opensearch is a small client of the OpenSearch REST API, covering
+// the document and search calls of the search indexes.
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// ErrNotFound is returned by Get for a document that does not exist.
+var ErrNotFound = errors.New("document not found")
+
+// Error is a non-2xx response of OpenSearch.
+type Error struct {
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("opensearch returned %d: %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// Client calls the cluster at a base URL. Credentials in the URL are sent
+// as basic authentication.
+type Client struct {
+	baseURL  string
+	username string
+	password string
+	http     *httpclient.Client
+}
+
+func New(rawURL string, client *httpclient.Client) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid opensearch url: %w", err)
+	}
+
+	c := &Client{http: client}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+		u.User = nil
+	}
+	c.baseURL = strings.TrimSuffix(u.String(), "/")
+
+	return c, nil
+}
+
+// CreateIndex creates index with the given settings and mappings unless
+// it exists already.
+func (c *Client) CreateIndex(ctx context.Context, index string, body any) error {
+	err := c.do(ctx, http.MethodPut, "/"+url.PathEscape(index), body, nil)
+
+	var osErr *Error
+	if errors.As(err, &osErr) && osErr.Type == "resource_already_exists_exception" {
+		return nil
+	}
+	return err
+}
+
+// PutMapping adds the fields of mapping to the mapping of index. Fields
+// that exist already must keep their type.
+func (c *Client) PutMapping(ctx context.Context, index string, mapping any) error {
+	return c.do(ctx, http.MethodPut, "/"+url.PathEscape(index)+"/_mapping", mapping, nil)
+}
+
+// Put stores doc as the document id of index, replacing any previous one.
+func (c *Client) Put(ctx context.Context, index, id string, doc any) error {
+	return c.do(ctx, http.MethodPut, docPath(index, id), doc, nil)
+}
+
+// Update merges the fields of doc into the document id of index. A missing
+// document is created from upsert, or from doc itself when upsert is nil.
+func (c *Client) Update(ctx context.Context, index, id string, doc, upsert any) error {
+	body := map[string]any{"doc": doc}
+	if upsert != nil {
+		body["upsert"] = upsert
+	} else {
+		body["doc_as_upsert"] = true
+	}
+
+	// Retry on version conflicts with concurrent updates of the document
+	path := "/" + url.PathEscape(index) + "/_update/" + url.PathEscape(id) + "?retry_on_conflict=3"
+	return c.do(ctx, http.MethodPost, path, body, nil)
+}
+
+// Get decodes the document id of index into dest.
+func (c *Client) Get(ctx context.Context, index, id string, dest any) error {
+	var result struct {
+		Found  bool            `json:"found"`
+		Source json.RawMessage `json:"_source"`
+	}
+
+	err := c.do(ctx, http.MethodGet, docPath(index, id), nil, &result)
+	var osErr *Error
+	if errors.As(err, &osErr) && osErr.Status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !result.Found {
+		return ErrNotFound
+	}
+
+	return json.Unmarshal(result.Source, dest)
+}
+
+// Delete removes the document id of index. Deleting a missing document is
+// not an error.
+func (c *Client) Delete(ctx context.Context, index, id string) error {
+	err := c.do(ctx, http.MethodDelete, docPath(index, id), nil, nil)
+
+	var osErr *Error
+	if errors.As(err, &osErr) && osErr.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// UpdateByQuery runs a painless script with params on every document of
+// index matching query.
+func (c *Client) UpdateByQuery(ctx context.Context, index string, query any, script string, params map[string]any) error {
+	body := map[string]any{
+		"query":  query,
+		"script": map[string]any{"lang": "painless", "source": script, "params": params},
+	}
+
+	// Documents changed meanwhile keep their newer version
+	path := "/" + url.PathEscape(index) + "/_update_by_query?conflicts=proceed"
+	return c.do(ctx, http.MethodPost, path, body, nil)
+}
+
+// Search runs a search request on index and decodes the response into
+// dest.
+func (c *Client) Search(ctx context.Context, index string, body, dest any) error {
+	return c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", body, dest)
+}
+
+// Health fails unless the cluster is reachable and not red.
+func (c *Client) Health(ctx context.Context) error {
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/_cluster/health", nil, &health); err != nil {
+		return err
+	}
+	if health.Status == "red" {
+		return errors.New("opensearch cluster is red")
+	}
+	return nil
+}
+
+func docPath(index, id string) string {
+	return "/" + url.PathEscape(index) + "/_doc/" + url.PathEscape(id)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, dest any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode opensearch request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var failure struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&failure)
+		return &Error{Status: resp.StatusCode, Type: failure.Error.Type, Reason: failure.Error.Reason}
+	}
+
+	if dest == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("failed to decode opensearch response: %w", err)
+	}
+	return nil
+}
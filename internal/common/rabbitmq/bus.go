@@ -0,0 +1,411 @@
+// Package rabbitmq implements the message bus on RabbitMQ.
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	amqp "github.com/rabbitmq/amqp091-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exchanges of the bus. Events are published to the topic exchange
+// "events" with their type as routing key; subscriptions park the ones
+// they can never handle in the queue "<group>.dlq" through "events.dlx",
+// for inspection and replay.
+const (
+	exchange           = "events"
+	deadLetterExchange = "events.dlx"
+)
+
+const workerQueueSize = 16
+
+// Config configures a Bus. Events are published in Format; publishes and
+// failed messages are retried with Retry.
+type Config struct {
+	URL    string
+	Format events.Format
+	Retry  retry.Policy
+}
+
+// Bus is the messagebus.MessageBus on RabbitMQ. Every subscription is a
+// durable queue named after its group, so its members share the events
+// and those published while it is down wait for it. Publishes are
+// confirmed by the broker. A lost connection is not reestablished; Ping
+// fails until the service is restarted.
+type Bus struct {
+	conn    *amqp.Connection
+	config  Config
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+	tracer  trace.Tracer
+
+	// The publishing channel, which is not safe for concurrent use
+	mu      sync.Mutex
+	channel *amqp.Channel
+}
+
+// New connects to the broker at config.URL and declares the exchanges of
+// events.
+func New(config Config, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) (*Bus, error) {
+	conn, err := amqp.Dial(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rabbitmq: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open rabbitmq channel: %w", err)
+	}
+	if err := channel.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	if err := channel.ExchangeDeclare(exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+	}
+	if err := channel.ExchangeDeclare(deadLetterExchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", deadLetterExchange, err)
+	}
+
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		if err := <-closed; err != nil {
+			logger.WithError(err).Error("rabbitmq connection closed")
+		}
+	}()
+
+	return &Bus{
+		conn:    conn,
+		config:  config,
+		logger:  logger,
+		metrics: metrics,
+		tracer:  tracer,
+		channel: channel,
+	}, nil
+}
+
+func (b *Bus) Publish(ctx context.Context, eventType events.EventType, key string, value any) (err error) {
+	ctx, span := b.tracer.Start(ctx, "rabbitmq.publish", trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(exchange),
+		semconv.MessagingRabbitmqDestinationRoutingKey(string(eventType)),
+	))
+	defer tracing.End(span, &err)
+
+	msg, err := messagebus.Encode(ctx, b.config.Format, eventType, key, value)
+	if err != nil {
+		b.metrics.MessageErrors.WithLabelValues(string(eventType), "serialization").Inc()
+		return err
+	}
+
+	headers := make(amqp.Table, len(msg.Headers))
+	for name, value := range msg.Headers {
+		headers[name] = value
+	}
+
+	err = b.publish(ctx, exchange, string(eventType), amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.Headers[events.ContentTypeHeader],
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.ID,
+		Timestamp:    time.Now(),
+		Type:         string(eventType),
+		Body:         msg.Value,
+	})
+	if err != nil {
+		b.metrics.MessageErrors.WithLabelValues(string(eventType), "produce").Inc()
+		return fmt.Errorf("failed to publish %s: %w", eventType, err)
+	}
+
+	b.metrics.MessagesProduced.WithLabelValues(string(eventType)).Inc()
+	return nil
+}
+
+// publish publishes msg and waits for the broker to confirm it.
+func (b *Bus) publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	return b.config.Retry.Do(ctx, func(ctx context.Context) error {
+		b.mu.Lock()
+		confirmation, err := b.channel.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, msg)
+		b.mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		acked, err := confirmation.WaitContext(ctx)
+		if err != nil {
+			return err
+		}
+		if !acked {
+			return errors.New("message was rejected by the broker")
+		}
+		return nil
+	})
+}
+
+// Subscribe returns a subscription to config.EventTypes. Messages are
+// acknowledged once handled; transient failures are requeued after the
+// retry policy's MaxInterval, others are copied to the dead letter queue
+// of the group and dropped.
+func (b *Bus) Subscribe(config messagebus.SubscriptionConfig, handler messagebus.Handler) messagebus.Subscription {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+
+	return &subscription{
+		bus:     b,
+		config:  config,
+		handler: handler,
+		logger:  b.logger.With("consumer_group", config.Group),
+		stop:    make(chan struct{}),
+	}
+}
+
+func (b *Bus) Ping(ctx context.Context) error {
+	if b.conn.IsClosed() {
+		return errors.New("rabbitmq connection is closed")
+	}
+	return nil
+}
+
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.channel.Close()
+	return b.conn.Close()
+}
+
+type subscription struct {
+	bus     *Bus
+	config  messagebus.SubscriptionConfig
+	handler messagebus.Handler
+	logger  *logger.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	running  sync.WaitGroup
+}
+
+// Start declares the queue of the group, binds it to its event types and
+// consumes it until ctx is cancelled or Shutdown is called.
+func (s *subscription) Start(ctx context.Context) error {
+	s.running.Add(1)
+	defer s.running.Done()
+
+	channel, err := s.bus.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open rabbitmq channel: %w", err)
+	}
+	// Messages that were not acknowledged are requeued
+	defer channel.Close()
+
+	deliveries, err := s.declare(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	s.logger.With("workers", strconv.Itoa(s.config.Workers)).Info("starting rabbitmq consumer")
+
+	queues := make([]chan amqp.Delivery, s.config.Workers)
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan amqp.Delivery, workerQueueSize)
+		workers.Add(1)
+		go func(queue <-chan amqp.Delivery) {
+			defer workers.Done()
+			s.work(ctx, queue)
+		}(queues[i])
+	}
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		workers.Wait()
+	}()
+
+	for {
+		select {
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return errors.New("rabbitmq channel closed")
+			}
+
+			key, _ := delivery.Headers[messagebus.MessageKeyHeader].(string)
+			select {
+			case queues[messagebus.Worker([]byte(key), len(queues))] <- delivery:
+			case <-s.stop:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case <-s.stop:
+			s.logger.Info("consumer stopped")
+			return nil
+		case <-ctx.Done():
+			s.logger.Info("consumer context cancelled, shutting down")
+			return ctx.Err()
+		}
+	}
+}
+
+// declare declares the queue and dead letter queue of the group and
+// starts consuming the queue.
+func (s *subscription) declare(ctx context.Context, channel *amqp.Channel) (<-chan amqp.Delivery, error) {
+	queue := s.config.Group
+	if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+	for _, eventType := range s.config.EventTypes {
+		if err := channel.QueueBind(queue, string(eventType), exchange, false, nil); err != nil {
+			return nil, fmt.Errorf("failed to bind queue %s to %s: %w", queue, eventType, err)
+		}
+	}
+
+	deadLetter := queue + ".dlq"
+	if _, err := channel.QueueDeclare(deadLetter, true, false, false, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to declare queue %s: %w", deadLetter, err)
+	}
+	if err := channel.QueueBind(deadLetter, s.config.Group, deadLetterExchange, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to bind queue %s: %w", deadLetter, err)
+	}
+
+	if err := channel.Qos(s.config.Workers*workerQueueSize, 0, false); err != nil {
+		return nil, fmt.Errorf("failed to set prefetch of %s: %w", queue, err)
+	}
+
+	deliveries, err := channel.ConsumeWithContext(ctx, queue, "", false, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume %s: %w", queue, err)
+	}
+	return deliveries, nil
+}
+
+func (s *subscription) work(ctx context.Context, queue <-chan amqp.Delivery) {
+	for delivery := range queue {
+		select {
+		case <-s.stop:
+			// Queued messages stay unacknowledged and are requeued
+			return
+		default:
+		}
+
+		s.handle(ctx, delivery)
+	}
+}
+
+// handle processes delivery and acknowledges it. A message that still
+// fails with a transient error once retries are exhausted is requeued
+// after a pause, holding back the events of its worker, rather than being
+// lost. One that can never succeed is dead-lettered first.
+func (s *subscription) handle(ctx context.Context, delivery amqp.Delivery) {
+	headers := make(map[string]string, len(delivery.Headers))
+	for name, value := range delivery.Headers {
+		if value, ok := value.(string); ok {
+			headers[name] = value
+		}
+	}
+	if delivery.ContentType != "" {
+		headers[events.ContentTypeHeader] = delivery.ContentType
+	}
+
+	ctx = messagebus.Context(ctx, headers)
+	ctx, span := s.bus.tracer.Start(ctx, "rabbitmq.consume."+delivery.RoutingKey, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(s.config.Group),
+		semconv.MessagingRabbitmqDestinationRoutingKey(delivery.RoutingKey),
+	))
+	defer span.End()
+
+	key := []byte(headers[messagebus.MessageKeyHeader])
+	err := messagebus.Process(ctx, s.config, s.handler, s.bus.config.Retry, s.logger, key, delivery.Body, headers)
+	if err == nil {
+		s.bus.metrics.MessagesConsumed.WithLabelValues(delivery.RoutingKey).Inc()
+		if err := delivery.Ack(false); err != nil {
+			s.logger.WithContext(ctx).WithError(err).With("routing_key", delivery.RoutingKey).Error("failed to acknowledge message")
+		}
+		return
+	}
+
+	s.bus.metrics.MessageErrors.WithLabelValues(delivery.RoutingKey, "process").Inc()
+	tracing.RecordError(span, err)
+
+	if messagebus.IsPermanent(err) {
+		s.logger.WithContext(ctx).WithError(err).With("routing_key", delivery.RoutingKey).Error("skipping message that cannot be processed")
+		if err = s.deadLetter(ctx, delivery, err); err == nil {
+			delivery.Ack(false)
+			return
+		}
+		s.logger.WithContext(ctx).WithError(err).With("routing_key", delivery.RoutingKey).Error("failed to dead-letter message")
+	} else {
+		s.logger.WithContext(ctx).WithError(err).With("routing_key", delivery.RoutingKey).Error("failed to process message after retries")
+	}
+
+	select {
+	case <-time.After(s.bus.config.Retry.MaxInterval):
+	case <-s.stop:
+	case <-ctx.Done():
+	}
+	delivery.Nack(false, true)
+}
+
+// deadLetter copies delivery to the dead letter queue of the group,
+// recording why it failed in its headers.
+func (s *subscription) deadLetter(ctx context.Context, delivery amqp.Delivery, cause error) error {
+	headers := make(amqp.Table, len(delivery.Headers)+2)
+	for name, value := range delivery.Headers {
+		headers[name] = value
+	}
+	headers["dlq-source-routing-key"] = delivery.RoutingKey
+	headers["dlq-error"] = cause.Error()
+
+	err := s.bus.publish(ctx, deadLetterExchange, s.config.Group, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  delivery.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    delivery.MessageId,
+		Timestamp:    time.Now(),
+		Type:         delivery.Type,
+		Body:         delivery.Body,
+	})
+	if err != nil {
+		s.bus.metrics.MessageErrors.WithLabelValues(s.config.Group+".dlq", "produce").Inc()
+		return err
+	}
+
+	s.bus.metrics.MessagesProduced.WithLabelValues(s.config.Group + ".dlq").Inc()
+	s.logger.WithContext(ctx).WithError(cause).With("queue", s.config.Group+".dlq").Warn("message dead-lettered")
+	return nil
+}
+
+// Shutdown stops consuming and waits for the messages being handled until
+// ctx is done.
+func (s *subscription) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
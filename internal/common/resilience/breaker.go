@@ -0,0 +1,196 @@
+// Package resilience protects the service from slow or failing
+// dependencies.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+)
+
+// ErrOpen is returned without calling the dependency while its breaker is
+// open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// BreakerConfig configures a Breaker. After FailureThreshold consecutive
+// failures the breaker opens and rejects calls for OpenTimeout. It then
+// lets HalfOpenProbes calls through; if they all succeed it closes again,
+// any failure reopens it.
+type BreakerConfig struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenProbes   int
+
+	// IsFailure decides which errors count against the dependency; nil
+	// counts every error. Errors caused by the caller, such as a missing
+	// row or a cancelled request, should not trip the breaker.
+	IsFailure func(err error) bool
+}
+
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenProbes:   1,
+	}
+}
+
+// Breaker is a circuit breaker for a single dependency. Its state is
+// exported as the circuit_breaker_state gauge (0 closed, 1 half open,
+// 2 open).
+type Breaker struct {
+	name    string
+	config  BreakerConfig
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	probes    int
+	successes int
+	openedAt  time.Time
+}
+
+func NewBreaker(name string, config BreakerConfig, logger *logger.Logger, metrics *metrics.Metrics) *Breaker {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	if config.HalfOpenProbes < 1 {
+		config.HalfOpenProbes = 1
+	}
+
+	b := &Breaker{name: name, config: config, logger: logger, metrics: metrics}
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(StateClosed))
+	return b
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh(time.Now())
+	return b.state
+}
+
+// Execute calls fn unless the breaker is open and records its outcome.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+// Allow reserves a call for callers that cannot wrap it in a function. The
+// returned done must be called with the call's error.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh(time.Now())
+
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.probes >= b.config.HalfOpenProbes {
+			return nil, ErrOpen
+		}
+		b.probes++
+	}
+
+	return b.record, nil
+}
+
+func (b *Breaker) record(err error) {
+	failed := err != nil && (b.config.IsFailure == nil || b.config.IsFailure(err))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.transition(StateOpen)
+		}
+
+	case StateHalfOpen:
+		if failed {
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenProbes {
+			b.transition(StateClosed)
+		}
+
+	case StateOpen:
+		// A call admitted before the breaker opened; the outcome no longer
+		// matters.
+	}
+}
+
+// refresh moves an open breaker to half open once OpenTimeout has passed.
+func (b *Breaker) refresh(now time.Time) {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.transition(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+	if to == StateOpen {
+		b.openedAt = time.Now()
+	}
+
+	b.metrics.CircuitBreakerState.WithLabelValues(b.name).Set(float64(to))
+	b.metrics.CircuitBreakerTransitions.WithLabelValues(b.name, to.String()).Inc()
+
+	log := b.logger.With("dependency", b.name).With("from", from.String()).With("to", to.String())
+	if to == StateOpen {
+		log.Warn("circuit breaker opened")
+	} else {
+		log.Info("circuit breaker state changed")
+	}
+}
@@ -0,0 +1,250 @@
+// Package scheduler runs periodic background jobs, such as expiring
+// reservations or purging deleted records.
+//
+// When several instances of a service run, they elect a leader through a
+// Redis lease and only the leader runs the jobs; the others stand by and
+// take over within a lease period if the leader goes away. Each run is
+// traced, timed and counted by outcome.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// leaseTTL is how long a leader that stopped renewing keeps its lease,
+	// and so how long the jobs may go without a leader.
+	leaseTTL = 15 * time.Second
+	// leaseRenewInterval leaves room for two failed renewals before the
+	// lease expires.
+	leaseRenewInterval = leaseTTL / 3
+	// defaultJobTimeout bounds runs of jobs without a timeout of their own.
+	defaultJobTimeout = 5 * time.Minute
+)
+
+// Job is a unit of periodic work.
+type Job struct {
+	// Name identifies the job in logs, metrics and traces.
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Timeout bounds a single run; defaultJobTimeout when zero.
+	Timeout time.Duration
+}
+
+// Elector grants a lease to one owner at a time. database.RedisClient
+// implements it.
+type Elector interface {
+	ClaimLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, key, owner string) error
+}
+
+type Scheduler struct {
+	key     string
+	id      string
+	elector Elector
+	logger  *logger.Logger
+	metrics *metrics.Metrics
+	tracer  trace.Tracer
+
+	jobs   []Job
+	leader atomic.Bool
+
+	// runCtx is cancelled when shutdown runs out of time, aborting the
+	// jobs still running
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	stop       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// New returns a scheduler for the instances of the named service. With a
+// nil elector every instance considers itself the leader, which suits a
+// single replica.
+func New(name string, elector Elector, logger *logger.Logger, metrics *metrics.Metrics, tracer trace.Tracer) *Scheduler {
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		key:        "scheduler:" + name,
+		id:         uuid.New().String(),
+		elector:    elector,
+		logger:     logger.With("scheduler", name),
+		metrics:    metrics,
+		tracer:     tracer,
+		runCtx:     runCtx,
+		cancelRuns: cancelRuns,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Add registers job. Jobs must be added before Start.
+func (s *Scheduler) Add(job Job) {
+	if job.Timeout <= 0 {
+		job.Timeout = defaultJobTimeout
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start begins the leader election and the schedules of the added jobs.
+func (s *Scheduler) Start() {
+	if s.elector == nil {
+		s.setLeader(true)
+	} else {
+		s.wg.Add(1)
+		go s.elect()
+	}
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.schedule(job)
+	}
+}
+
+// Shutdown stops scheduling new runs and waits for the running ones to
+// finish. If ctx is done first they are cancelled. The lease is released
+// so another instance can take over right away.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	close(s.stop)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.cancelRuns()
+		err = ctx.Err()
+	}
+	s.cancelRuns()
+
+	if s.elector != nil && s.leader.Load() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if releaseErr := s.elector.ReleaseLease(releaseCtx, s.key, s.id); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}
+	return err
+}
+
+// elect claims or renews the lease until the scheduler stops. Failing to
+// reach the elector counts as losing the lease, so two instances never
+// both believe they lead.
+func (s *Scheduler) elect() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(s.runCtx, leaseRenewInterval)
+		held, err := s.elector.ClaimLease(ctx, s.key, s.id, leaseTTL)
+		cancel()
+
+		if err != nil {
+			s.logger.WithError(err).Warn("failed to claim scheduler lease")
+		}
+		s.setLeader(held && err == nil)
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) setLeader(leader bool) {
+	if s.leader.Swap(leader) == leader {
+		return
+	}
+
+	if leader {
+		s.metrics.SchedulerLeader.Set(1)
+		s.logger.Info("scheduler leadership acquired, running jobs")
+	} else {
+		s.metrics.SchedulerLeader.Set(0)
+		s.logger.Info("scheduler leadership lost, standing by")
+	}
+}
+
+func (s *Scheduler) schedule(job Job) {
+	defer s.wg.Done()
+
+	for {
+		next := job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.With("job", job.Name).Error("job schedule never fires, not scheduling it")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !s.leader.Load() {
+			s.metrics.JobRuns.WithLabelValues(job.Name, "skipped").Inc()
+			continue
+		}
+		s.run(job)
+	}
+}
+
+func (s *Scheduler) run(job Job) {
+	ctx, cancel := context.WithTimeout(s.runCtx, job.Timeout)
+	defer cancel()
+
+	ctx, span := s.tracer.Start(ctx, "scheduler.job."+job.Name, trace.WithAttributes(attribute.String("job", job.Name)))
+	log := s.logger.WithContext(ctx).With("job", job.Name)
+
+	// Jobs act on the current state, which a replica may lag behind
+	start := time.Now()
+	err := runJob(database.UsePrimary(ctx), job)
+	duration := time.Since(start)
+
+	tracing.RecordError(span, err)
+	span.End()
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		log.WithError(err).Error("scheduled job failed")
+	} else {
+		log.With("duration", duration.String()).Debug("scheduled job completed")
+	}
+
+	s.metrics.JobRuns.WithLabelValues(job.Name, outcome).Inc()
+	s.metrics.JobDuration.WithLabelValues(job.Name).Observe(duration.Seconds())
+}
+
+// runJob runs job, turning a panic into an error so one faulty job does
+// not take the service down.
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
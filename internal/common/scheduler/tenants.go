@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+)
+
+// TenantLister lists the tenants jobs run for.
+type TenantLister interface {
+	ActiveIDs(ctx context.Context) ([]string, error)
+}
+
+// PerTenant runs job once for every active tenant, with the tenant in the
+// context, so the repositories it calls see the data of that tenant only.
+// A failing tenant does not stop the others; the errors are joined.
+func PerTenant(job Job, tenants TenantLister) Job {
+	run := job.Run
+	job.Run = func(ctx context.Context) error {
+		ids, err := tenants.ActiveIDs(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list tenants: %w", err)
+		}
+
+		var errs []error
+		for _, id := range ids {
+			if err := run(tenancy.WithID(ctx, id)); err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", id, err))
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return errors.Join(errs...)
+	}
+	return job
+}
@@ -0,0 +1,182 @@
+// Package schemaregistry is a small client of the Confluent Schema
+// Registry REST API, covering the calls producers and consumers of
+// registered schemas need.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// Error codes of the registry for missing subjects and schemas.
+const (
+	codeSubjectNotFound = 40401
+	codeSchemaNotFound  = 40403
+)
+
+// ErrNotFound is returned by Schema for an unknown schema ID.
+var ErrNotFound = errors.New("schema not found")
+
+// Error is a non-2xx response of the registry.
+type Error struct {
+	Status  int
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("schema registry returned %d: %d: %s", e.Status, e.Code, e.Message)
+}
+
+// Client calls the registry at a base URL. Credentials in the URL are sent
+// as basic authentication.
+type Client struct {
+	baseURL  string
+	username string
+	password string
+	http     *httpclient.Client
+}
+
+func New(rawURL string, client *httpclient.Client) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema registry url: %w", err)
+	}
+
+	c := &Client{http: client}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+		u.User = nil
+	}
+	c.baseURL = strings.TrimSuffix(u.String(), "/")
+
+	return c, nil
+}
+
+// schemaRequest is the body of the calls taking a schema. The schema type
+// is left out for Avro, the registry's default.
+type schemaRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+func newSchemaRequest(schemaType, schema string) schemaRequest {
+	if schemaType == "AVRO" {
+		schemaType = ""
+	}
+	return schemaRequest{Schema: schema, SchemaType: schemaType}
+}
+
+// Register adds schema to subject unless it is registered already and
+// returns its ID. The registry refuses schemas incompatible with those of
+// the subject under its compatibility level.
+func (c *Client) Register(ctx context.Context, subject, schemaType, schema string) (int, error) {
+	var result struct {
+		ID int `json:"id"`
+	}
+	path := "/subjects/" + url.PathEscape(subject) + "/versions"
+	if err := c.do(ctx, http.MethodPost, path, newSchemaRequest(schemaType, schema), &result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
+
+// CheckCompatibility reports whether schema is compatible with the latest
+// version of subject. Schemas of new subjects are compatible.
+func (c *Client) CheckCompatibility(ctx context.Context, subject, schemaType, schema string) (bool, error) {
+	var result struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	path := "/compatibility/subjects/" + url.PathEscape(subject) + "/versions/latest"
+	err := c.do(ctx, http.MethodPost, path, newSchemaRequest(schemaType, schema), &result)
+
+	var regErr *Error
+	if errors.As(err, &regErr) && regErr.Code == codeSubjectNotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.IsCompatible, nil
+}
+
+// Schema returns the type and text of the schema with id.
+func (c *Client) Schema(ctx context.Context, id int) (schemaType, schema string, err error) {
+	var result struct {
+		SchemaType string `json:"schemaType"`
+		Schema     string `json:"schema"`
+	}
+	err = c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &result)
+
+	var regErr *Error
+	if errors.As(err, &regErr) && regErr.Code == codeSchemaNotFound {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if result.SchemaType == "" {
+		result.SchemaType = "AVRO"
+	}
+	return result.SchemaType, result.Schema, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, dest any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode schema registry request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var failure struct {
+			ErrorCode int    `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&failure)
+		return &Error{Status: resp.StatusCode, Code: failure.ErrorCode, Message: failure.Message}
+	}
+
+	if dest == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+	return nil
+}
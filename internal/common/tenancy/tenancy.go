@@ -0,0 +1,40 @@
+// Package tenancy carries the tenant a request or message acts for through
+// its context. Every business on a deployment is a tenant; repositories
+// scope their queries to the tenant of the context.
+package tenancy
+
+import "context"
+
+// DefaultID is the tenant that existed data was migrated to. It is used
+// for contexts without a tenant, such as requests that name none and
+// messages produced before tenants were introduced. Its admins manage the
+// other tenants.
+const DefaultID = "00000000-0000-0000-0000-000000000001"
+
+// Header names the tenant of anonymous HTTP requests. Authenticated
+// requests act for the tenant of their token or API key.
+const Header = "X-Tenant-ID"
+
+// MetadataKey carries the tenant in Kafka headers and gRPC metadata.
+const MetadataKey = "tenant-id"
+
+type contextKey struct{}
+
+// WithID returns a context acting for the tenant id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant set on ctx and whether there is one.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ID returns the tenant of ctx, or DefaultID when it has none.
+func ID(ctx context.Context) string {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+	return DefaultID
+}
@@ -0,0 +1,119 @@
+package timing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type contextKey struct{}
+
+// Breadcrumbs accumulates the time a request spends in named phases such
+// as validation, db and kafka. It is safe for concurrent use.
+type Breadcrumbs struct {
+	mu     sync.Mutex
+	order  []string
+	phases map[string]time.Duration
+	// active counts the running Tracks of each phase and entered holds
+	// when the first of them started.
+	active  map[string]int
+	entered map[string]time.Time
+}
+
+func NewContext(ctx context.Context) (context.Context, *Breadcrumbs) {
+	b := &Breadcrumbs{
+		phases:  make(map[string]time.Duration),
+		active:  make(map[string]int),
+		entered: make(map[string]time.Time),
+	}
+	return context.WithValue(ctx, contextKey{}, b), b
+}
+
+func FromContext(ctx context.Context) *Breadcrumbs {
+	b, _ := ctx.Value(contextKey{}).(*Breadcrumbs)
+	return b
+}
+
+// Track starts timing phase and returns a func that records the elapsed
+// time when called. It is a no-op when ctx carries no breadcrumbs. Tracks of
+// a phase that overlap, such as a service timing its repository calls
+// while the database client times each query, count the time once.
+func Track(ctx context.Context, phase string) func() {
+	b := FromContext(ctx)
+	if b == nil {
+		return func() {}
+	}
+
+	b.enter(phase, time.Now())
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.leave(phase, time.Now()) })
+	}
+}
+
+func (b *Breadcrumbs) enter(phase string, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.active[phase] == 0 {
+		b.entered[phase] = now
+	}
+	b.active[phase]++
+}
+
+func (b *Breadcrumbs) leave(phase string, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.active[phase]--
+	if b.active[phase] > 0 {
+		return
+	}
+	if _, ok := b.phases[phase]; !ok {
+		b.order = append(b.order, phase)
+	}
+	b.phases[phase] += now.Sub(b.entered[phase])
+}
+
+func (b *Breadcrumbs) Add(phase string, d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.phases[phase]; !ok {
+		b.order = append(b.order, phase)
+	}
+	b.phases[phase] += d
+}
+
+// Fields returns the phase durations in milliseconds keyed as <phase>_ms.
+func (b *Breadcrumbs) Fields() map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fields := make(map[string]any, len(b.phases))
+	for phase, d := range b.phases {
+		fields[phase+"_ms"] = float64(d.Microseconds()) / 1000
+	}
+	return fields
+}
+
+// ServerTiming formats the phases as a Server-Timing header value.
+func (b *Breadcrumbs) ServerTiming() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	parts := make([]string, 0, len(b.order))
+	for _, phase := range b.order {
+		parts = append(parts, fmt.Sprintf("%s;dur=%.3f", phase, float64(b.phases[phase].Microseconds())/1000))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (b *Breadcrumbs) Empty() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.phases) == 0
+}
@@ -0,0 +1,64 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTrackOverlapping(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(ctx context.Context)
+		min  time.Duration
+		max  time.Duration
+	}{
+		{
+			name: "sequential tracks add up",
+			run: func(ctx context.Context) {
+				for range 2 {
+					done := Track(ctx, "db")
+					time.Sleep(20 * time.Millisecond)
+					done()
+				}
+			},
+			min: 40 * time.Millisecond,
+			max: time.Second,
+		},
+		{
+			name: "nested tracks count once",
+			run: func(ctx context.Context) {
+				outer := Track(ctx, "db")
+				inner := Track(ctx, "db")
+				time.Sleep(20 * time.Millisecond)
+				inner()
+				outer()
+			},
+			min: 20 * time.Millisecond,
+			max: 39 * time.Millisecond,
+		},
+		{
+			name: "ending a track twice counts once",
+			run: func(ctx context.Context) {
+				done := Track(ctx, "db")
+				time.Sleep(20 * time.Millisecond)
+				done()
+				done()
+			},
+			min: 20 * time.Millisecond,
+			max: 39 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, b := NewContext(context.Background())
+			tt.run(ctx)
+
+			got := b.phases["db"]
+			if got < tt.min || got > tt.max {
+				t.Errorf("db = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
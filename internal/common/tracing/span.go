@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	stderrors "errors"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Keys of the business identifiers spans are tagged with, so the traces
+// of a user, booking, resource or payment can be searched for.
+const (
+	UserID     = attribute.Key("user_id")
+	BookingID  = attribute.Key("booking_id")
+	ResourceID = attribute.Key("resource_id")
+	PaymentID  = attribute.Key("payment_id")
+)
+
+// RecordError records err on span. Errors caused by the request, such as
+// validation failures or missing records, are only added as span events;
+// anything else also marks the span as failed.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	if isRequestError(err) {
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End records the error err points to and ends span. It is deferred right
+// after the span is started, with the named error result of the function:
+//
+//	ctx, span := s.tracer.Start(ctx, "booking.service.get")
+//	defer tracing.End(span, &err)
+func End(span trace.Span, err *error) {
+	RecordError(span, *err)
+	span.End()
+}
+
+func isRequestError(err error) bool {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		return false
+	}
+
+	switch appErr.Type {
+	case errors.ErrorTypeValidation, errors.ErrorTypeNotFound, errors.ErrorTypeConfict, errors.ErrorTypePreconditionFailed,
+		errors.ErrorTypeUnauthorized, errors.ErrorTypeForbidden, errors.ErrorTypeRateLimited:
+		return true
+	}
+	return false
+}
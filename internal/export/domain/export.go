@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/export"
+)
+
+type ExportStatus string
+
+const (
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+// Datasets that can be exported. Each is exported by the service that owns
+// it.
+const (
+	DatasetBookings = "bookings"
+	DatasetUsers    = "users"
+)
+
+// Export is a dataset written to a file in the background, for exports too
+// large to stream in a single request. The admin who requested it is
+// mailed a download link once it completes.
+type Export struct {
+	ID          string        `json:"id" db:"id"`
+	UserID      string        `json:"user_id" db:"user_id"`
+	Dataset     string        `json:"dataset" db:"dataset"`
+	Format      export.Format `json:"format" db:"format"`
+	Status      ExportStatus  `json:"status" db:"status"`
+	Rows        int           `json:"rows" db:"row_count"`
+	Error       string        `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// Filename is the name the export is downloaded as.
+func (e *Export) Filename() string {
+	return e.Format.Filename(e.Dataset + "-" + e.CreatedAt.Format("20060102-150405"))
+}
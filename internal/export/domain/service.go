@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/pkg/export"
+)
+
+// ExportService is the application API of the export module. The HTTP
+// handlers depend on it and service.ExportService implements it.
+type ExportService interface {
+	StartExport(ctx context.Context, userID, dataset string, format export.Format, source export.Source) (*Export, error)
+	GetExport(ctx context.Context, userID, id string) (*Export, error)
+	ExportFile(ctx context.Context, userID, id string) (*Export, string, error)
+}
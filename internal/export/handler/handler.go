@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"path"
+	"strconv"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/export/domain"
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// SourceFunc reads the filters of an export request and returns the source
+// of its rows.
+type SourceFunc func(c *gin.Context) (export.Source, error)
+
+// ExportHandler serves the exports of one dataset. Every service serves
+// the exports of the dataset it owns.
+type ExportHandler struct {
+	service domain.ExportService
+	dataset string
+	logger  *logger.Logger
+}
+
+func NewExportHandler(service domain.ExportService, dataset string, logger *logger.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		dataset: dataset,
+		logger:  logger,
+	}
+}
+
+// Export returns the handler exporting the rows newSource reads in the
+// format query parameter, csv or xlsx. The rows are streamed in the
+// response unless async is true, in which case they are written to a file
+// in the background and a download link is mailed once it is ready.
+// Exports that might not finish within the request timeout should be run
+// in the background.
+func (h *ExportHandler) Export(newSource SourceFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format, err := export.ParseFormat(c.Query("format"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		source, err := newSource(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		if async, _ := strconv.ParseBool(c.Query("async")); async {
+			e, err := h.service.StartExport(c.Request.Context(), c.GetString("user_id"), h.dataset, format, source)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+
+			response.Accepted(c, path.Join(path.Dir(c.Request.URL.Path), "exports", e.ID), e)
+			return
+		}
+
+		h.stream(c, format, source)
+	}
+}
+
+func (h *ExportHandler) stream(c *gin.Context, format export.Format, source export.Source) {
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+format.Filename(h.dataset)+`"`)
+
+	w := export.NewWriter(c.Writer, format)
+	rows, err := source(c.Request.Context(), w)
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Errors before the first page was written still get a proper response;
+	// later ones can only cut the download short
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		c.Error(err)
+		return
+	}
+
+	h.logger.WithContext(c.Request.Context()).WithError(err).
+		With("dataset", h.dataset).
+		With("rows", strconv.Itoa(rows)).
+		Error("export stream failed")
+	c.Abort()
+}
+
+// GetExport returns the status of a background export.
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	e, err := h.service.GetExport(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err == nil && e.Dataset != h.dataset {
+		err = errors.NewNotFoundError("export")
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, e)
+}
+
+// DownloadExport serves the file of a completed background export.
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	e, file, err := h.service.ExportFile(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err == nil && e.Dataset != h.dataset {
+		err = errors.NewNotFoundError("export")
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", e.Format.ContentType())
+	c.FileAttachment(file, e.Filename())
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/export/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the export API of dataset for the OpenAPI document.
+// filters are the query parameters narrowing the export. Keep it in sync
+// with setupRouter in the cmd of the service owning dataset.
+func Routes(dataset string, filters []openapi.Param) []openapi.Route {
+	base := "/api/v1/admin/" + dataset
+
+	return []openapi.Route{
+		{Method: http.MethodGet, Path: base + "/export", Summary: "Export " + dataset + " as CSV or XLSX", Tag: "exports", Auth: true, Admin: true,
+			Query: append([]openapi.Param{
+				{Name: "format", Description: "csv (default) or xlsx", Enum: []string{"csv", "xlsx"}},
+				{Name: "async", Description: "Write the export to a file in the background and mail a download link; responds 202 with the export", Type: "boolean"},
+			}, filters...)},
+		{Method: http.MethodGet, Path: base + "/exports/:id", Summary: "Get a background export of " + dataset, Tag: "exports", Auth: true, Admin: true,
+			Response: domain.Export{}},
+		{Method: http.MethodGet, Path: base + "/exports/:id/download", Summary: "Download a background export of " + dataset, Tag: "exports", Auth: true, Admin: true},
+	}
+}
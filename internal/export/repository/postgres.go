@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/export/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresExportRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresExportRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresExportRepository {
+	return &PostgresExportRepository{db: db, tracer: tracer}
+}
+
+func (r *PostgresExportRepository) Create(ctx context.Context, e *domain.Export) (err error) {
+	ctx, span := r.tracer.Start(ctx, "export.repository.create", trace.WithAttributes(tracing.UserID.String(e.UserID)))
+	defer tracing.End(span, &err)
+
+	e.ID = uuid.New().String()
+	e.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO exports (id, tenant_id, user_id, dataset, format, status, row_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.db.Exec(ctx, "export.create", query, e.ID, tenancy.ID(ctx), e.UserID, e.Dataset, e.Format, e.Status, e.Rows, e.CreatedAt)
+	if err != nil {
+		return errors.NewInternalError("failed to create export", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresExportRepository) GetByID(ctx context.Context, id string) (_ *domain.Export, err error) {
+	ctx, span := r.tracer.Start(ctx, "export.repository.get")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, dataset, format, status, row_count, error, created_at, completed_at
+		FROM exports
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	e := &domain.Export{}
+	var completedAt sql.NullTime
+	err = r.db.QueryRow(ctx, "export.get_by_id", query, id, tenancy.ID(ctx)).Scan(
+		&e.ID, &e.UserID, &e.Dataset, &e.Format, &e.Status,
+		&e.Rows, &e.Error, &e.CreatedAt, &completedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("export")
+		}
+		return nil, errors.NewInternalError("failed to get export", err)
+	}
+
+	if completedAt.Valid {
+		e.CompletedAt = &completedAt.Time
+	}
+	return e, nil
+}
+
+// Finish records the outcome of a running export.
+func (r *PostgresExportRepository) Finish(ctx context.Context, e *domain.Export) (err error) {
+	ctx, span := r.tracer.Start(ctx, "export.repository.finish")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE exports
+		SET status = $2, row_count = $3, error = $4, completed_at = $5
+		WHERE id = $1 AND tenant_id = $7 AND status = $6
+	`
+
+	result, err := r.db.Exec(ctx, "export.finish", query, e.ID, e.Status, e.Rows, e.Error, e.CompletedAt, domain.ExportStatusRunning, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to update export", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewConflictError("export is no longer running")
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/export/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ExportRepository interface {
+	Create(ctx context.Context, e *domain.Export) error
+	GetByID(ctx context.Context, id string) (*domain.Export, error)
+	Finish(ctx context.Context, e *domain.Export) error
+}
+
+var _ domain.ExportService = (*ExportService)(nil)
+
+// ExportService writes exports to files in dir in the background. Running
+// exports are given the shutdown timeout to finish and are failed when it
+// runs out.
+type ExportService struct {
+	repo        ExportRepository
+	dir         string
+	downloadURL string
+	source      string
+	publisher   messagebus.Publisher
+	logger      *logger.Logger
+	tracer      trace.Tracer
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewExportService returns an ExportService writing to dir, which is
+// created when missing. source names the service in published events.
+func NewExportService(
+	repo ExportRepository,
+	dir string,
+	downloadURL string,
+	source string,
+	publisher messagebus.Publisher,
+	logger *logger.Logger,
+	tracer trace.Tracer,
+) (*ExportService, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ExportService{
+		repo:        repo,
+		dir:         dir,
+		downloadURL: downloadURL,
+		source:      source,
+		publisher:   publisher,
+		logger:      logger,
+		tracer:      tracer,
+		ctx:         ctx,
+		cancel:      cancel,
+	}, nil
+}
+
+// StartExport records an export of dataset for the user and writes the
+// rows of source to its file in the background. The returned export is
+// still running.
+func (s *ExportService) StartExport(ctx context.Context, userID, dataset string, format export.Format, source export.Source) (_ *domain.Export, err error) {
+	ctx, span := s.tracer.Start(ctx, "export.service.start", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if s.ctx.Err() != nil {
+		return nil, errors.NewConflictError("the service is shutting down, retry the export")
+	}
+
+	e := &domain.Export{
+		UserID:  userID,
+		Dataset: dataset,
+		Format:  format,
+		Status:  domain.ExportStatusRunning,
+	}
+	if err := s.repo.Create(ctx, e); err != nil {
+		return nil, err
+	}
+	audit.Log(ctx, "export.start", "export", e.ID, nil, e)
+
+	// The export outlives the request but stays in its trace
+	runCtx := trace.ContextWithSpanContext(s.ctx, span.SpanContext())
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.run(runCtx, e, source)
+	}()
+
+	s.logger.WithContext(ctx).With("export_id", e.ID).With("dataset", dataset).Info("export started")
+
+	return e, nil
+}
+
+func (s *ExportService) run(ctx context.Context, e *domain.Export, source export.Source) {
+	ctx, span := s.tracer.Start(ctx, "export.service.run")
+	var err error
+	defer tracing.End(span, &err)
+
+	e.Rows, err = s.writeFile(ctx, e, source)
+
+	completedAt := time.Now().UTC()
+	e.CompletedAt = &completedAt
+	e.Status = domain.ExportStatusCompleted
+	if err != nil {
+		e.Status = domain.ExportStatusFailed
+		e.Error = errors.GetAppError(err).Message
+		if ctx.Err() != nil {
+			e.Error = "the export was interrupted by a shutdown"
+		}
+		os.Remove(s.path(e))
+		s.logger.WithContext(ctx).WithError(err).With("export_id", e.ID).Error("export failed")
+	}
+
+	// Record the outcome even when the export was cancelled
+	if err := s.repo.Finish(context.WithoutCancel(ctx), e); err != nil {
+		s.logger.WithContext(ctx).WithError(err).With("export_id", e.ID).Error("failed to record export outcome")
+		return
+	}
+	if e.Status != domain.ExportStatusCompleted {
+		return
+	}
+
+	event := events.ExportCompletedEvent{
+		BaseEvent: events.NewBaseEvent(events.ExportCompleted, s.source, span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.ExportCompletedData{
+			ExportID:    e.ID,
+			UserID:      e.UserID,
+			Dataset:     e.Dataset,
+			Format:      string(e.Format),
+			Rows:        e.Rows,
+			DownloadURL: s.downloadURL + e.ID,
+			CompletedAt: completedAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.ExportCompleted, e.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish export completed event")
+	}
+
+	s.logger.WithContext(ctx).With("export_id", e.ID).With("rows", strconv.Itoa(e.Rows)).Info("export completed")
+}
+
+func (s *ExportService) writeFile(ctx context.Context, e *domain.Export, source export.Source) (int, error) {
+	f, err := os.OpenFile(s.path(e), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return 0, errors.NewInternalError("failed to create export file", err)
+	}
+	defer f.Close()
+
+	w := export.NewWriter(f, e.Format)
+	rows, err := source(ctx, w)
+	if err != nil {
+		return rows, err
+	}
+	if err := w.Close(); err != nil {
+		return rows, errors.NewInternalError("failed to write export", err)
+	}
+	if err := f.Close(); err != nil {
+		return rows, errors.NewInternalError("failed to write export", err)
+	}
+
+	return rows, nil
+}
+
+// GetExport returns an export the user requested.
+func (s *ExportService) GetExport(ctx context.Context, userID, id string) (_ *domain.Export, err error) {
+	ctx, span := s.tracer.Start(ctx, "export.service.get", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exports are only disclosed to the admin who requested them
+	if e.UserID != userID {
+		return nil, errors.NewNotFoundError("export")
+	}
+
+	return e, nil
+}
+
+// ExportFile returns a completed export the user requested together with
+// the path of its file.
+func (s *ExportService) ExportFile(ctx context.Context, userID, id string) (_ *domain.Export, _ string, err error) {
+	e, err := s.GetExport(ctx, userID, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if e.Status != domain.ExportStatusCompleted {
+		return nil, "", errors.NewConflictError("export is " + string(e.Status))
+	}
+
+	path := s.path(e)
+	if _, err := os.Stat(path); err != nil {
+		return nil, "", errors.NewNotFoundError("export file")
+	}
+
+	return e, path, nil
+}
+
+func (s *ExportService) path(e *domain.Export) string {
+	return filepath.Join(s.dir, e.ID+"."+string(e.Format))
+}
+
+// Shutdown waits for running exports to finish, cancelling them when ctx
+// is done first.
+func (s *ExportService) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
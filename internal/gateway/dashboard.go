@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardBookings is the number of upcoming and of recent bookings on a
+// dashboard.
+const dashboardBookings = 5
+
+// Dashboard is the landing page of a signed in user: their profile with
+// their next and their latest bookings. The user and bookings are passed
+// on as the services return them.
+type Dashboard struct {
+	User             json.RawMessage `json:"user"`
+	UpcomingBookings json.RawMessage `json:"upcoming_bookings"`
+	UpcomingTotal    int64           `json:"upcoming_total"`
+	RecentBookings   json.RawMessage `json:"recent_bookings"`
+	TotalBookings    int64           `json:"total_bookings"`
+	// Unavailable lists the sections left out because their service
+	// failed
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+type DashboardHandler struct {
+	client     *httpclient.Client
+	userURL    string
+	bookingURL string
+	logger     *logger.Logger
+}
+
+// NewDashboardHandler reads users from the user service at userURL and
+// bookings from the booking service at bookingURL.
+func NewDashboardHandler(client *httpclient.Client, userURL, bookingURL string, logger *logger.Logger) *DashboardHandler {
+	return &DashboardHandler{
+		client:     client,
+		userURL:    strings.TrimRight(userURL, "/"),
+		bookingURL: strings.TrimRight(bookingURL, "/"),
+		logger:     logger,
+	}
+}
+
+// GetDashboard combines the user of the token with their bookings. The
+// calls run concurrently with the token of the request, so the services
+// authorize them as if the client had made them. The dashboard fails with
+// the user; failed booking lists are reported in Unavailable.
+func (h *DashboardHandler) GetDashboard(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := url.PathEscape(c.GetString("user_id"))
+	headers := http.Header{
+		"Authorization":            {c.GetHeader("Authorization")},
+		middleware.RequestIDHeader: {c.GetString("request_id")},
+		tenancy.Header:             {tenancy.ID(ctx)},
+	}
+
+	var (
+		wg                 sync.WaitGroup
+		dashboard          Dashboard
+		userErr            error
+		upcomingErr        error
+		recentErr          error
+		upcomingPagination *response.Pagination
+		recentPagination   *response.Pagination
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		dashboard.User, _, userErr = h.get(ctx, h.userURL+"/api/v1/users/"+userID, headers)
+	}()
+	go func() {
+		defer wg.Done()
+		query := url.Values{
+			"from":      {time.Now().UTC().Format(time.RFC3339)},
+			"status":    {"confirmed"},
+			"sort":      {"start_time"},
+			"page_size": {fmt.Sprint(dashboardBookings)},
+		}
+		dashboard.UpcomingBookings, upcomingPagination, upcomingErr = h.get(ctx, h.bookingURL+"/api/v1/users/"+userID+"/bookings?"+query.Encode(), headers)
+	}()
+	go func() {
+		defer wg.Done()
+		query := url.Values{"page_size": {fmt.Sprint(dashboardBookings)}}
+		dashboard.RecentBookings, recentPagination, recentErr = h.get(ctx, h.bookingURL+"/api/v1/users/"+userID+"/bookings?"+query.Encode(), headers)
+	}()
+	wg.Wait()
+
+	if userErr != nil {
+		response.Error(c, userErr)
+		return
+	}
+	if upcomingErr != nil {
+		dashboard.Unavailable = append(dashboard.Unavailable, "upcoming_bookings")
+	} else if upcomingPagination != nil {
+		dashboard.UpcomingTotal = upcomingPagination.Total
+	}
+	if recentErr != nil {
+		dashboard.Unavailable = append(dashboard.Unavailable, "recent_bookings")
+	} else if recentPagination != nil {
+		dashboard.TotalBookings = recentPagination.Total
+	}
+	for _, err := range []error{upcomingErr, recentErr} {
+		if err != nil {
+			h.logger.WithContext(ctx).WithError(err).With("request_id", c.GetString("request_id")).Warn("dashboard bookings unavailable")
+		}
+	}
+
+	response.Success(c, dashboard)
+}
+
+// envelope is the response of the services' HTTP APIs.
+type envelope struct {
+	Success    bool                 `json:"success"`
+	Data       json.RawMessage      `json:"data"`
+	Pagination *response.Pagination `json:"pagination"`
+	Error      *response.ErrorInfo  `json:"error"`
+}
+
+// get reads the data and pagination of a response of a service. Errors of
+// the service are returned as the AppError they were reported as.
+func (h *DashboardHandler) get(ctx context.Context, rawURL string, headers http.Header) (json.RawMessage, *response.Pagination, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to build request", err)
+	}
+	req.Header = headers.Clone()
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, nil, errors.NewExternalError(req.URL.Host, "service is unavailable", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, errors.NewExternalError(req.URL.Host, "failed to read response", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, nil, errors.NewExternalError(req.URL.Host, "invalid response", err)
+	}
+	if resp.StatusCode >= 300 || !env.Success {
+		if env.Error == nil || resp.StatusCode >= 500 {
+			return nil, nil, errors.NewExternalError(req.URL.Host, fmt.Sprintf("unexpected status %d", resp.StatusCode), nil)
+		}
+		return nil, nil, &errors.AppError{Type: errors.ErrorType(env.Error.Type), Message: env.Error.Message, Code: resp.StatusCode}
+	}
+
+	return env.Data, env.Pagination, nil
+}
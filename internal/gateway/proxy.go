@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/health"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
+	"github.com/dmehra2102/booking-system/internal/common/resilience"
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Proxy forwards requests to the services owning their paths.
+type Proxy struct {
+	router  *router
+	proxies map[string]*httputil.ReverseProxy
+	logger  *logger.Logger
+}
+
+// NewProxy proxies the routes to the services at the base URLs of
+// upstreams, keyed by service. Routes of services without a URL are not
+// served. Requests go through client, whose breakers fail fast while a
+// service is down.
+func NewProxy(routes []Route, upstreams map[string]string, client *httpclient.Client, logger *logger.Logger) (*Proxy, error) {
+	var served []Route
+	proxies := make(map[string]*httputil.ReverseProxy)
+	for _, route := range routes {
+		rawURL := upstreams[route.Service]
+		if rawURL == "" {
+			continue
+		}
+		served = append(served, route)
+		if _, ok := proxies[route.Service]; ok {
+			continue
+		}
+
+		target, err := url.Parse(rawURL)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return nil, fmt.Errorf("invalid URL of the %s service: %q", route.Service, rawURL)
+		}
+		proxies[route.Service] = newReverseProxy(route.Service, target, client, logger)
+	}
+
+	return &Proxy{router: newRouter(served), proxies: proxies, logger: logger}, nil
+}
+
+// Handle forwards the request to its service, or answers 404 when no
+// route matches its path. The request ID is sent along so the logs of the
+// gateway and the service can be joined.
+func (p *Proxy) Handle(c *gin.Context) {
+	service, ok := p.router.match(c.Request.URL.Path)
+	if !ok {
+		response.Error(c, errors.NewNotFoundError("route"))
+		return
+	}
+
+	c.Request.Header.Set(middleware.RequestIDHeader, c.GetString("request_id"))
+	c.Set("upstream", service)
+	p.proxies[service].ServeHTTP(c.Writer, c.Request)
+}
+
+func newReverseProxy(service string, target *url.URL, client *httpclient.Client, log *logger.Logger) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+		},
+		Transport: transport{client},
+		ModifyResponse: func(resp *http.Response) error {
+			// The gateway answers with its own request ID, CORS and
+			// Content-Language headers; browsers reject a response repeating
+			// them
+			resp.Header.Del(middleware.RequestIDHeader)
+			resp.Header.Del("Content-Language")
+			for name := range resp.Header {
+				if strings.HasPrefix(name, "Access-Control-") {
+					resp.Header.Del(name)
+				}
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if stderrors.Is(err, context.Canceled) {
+				// The client went away; there is no one to answer
+				return
+			}
+
+			errorType, status, message := errors.ErrorTypeExternal, http.StatusBadGateway, service+" service is unavailable"
+			var tooLarge *http.MaxBytesError
+			switch {
+			case stderrors.As(err, &tooLarge):
+				// Cut off by the MaxBodySize middleware
+				errorType, status, message = errors.ErrorTypePayloadTooLarge, http.StatusRequestEntityTooLarge, "request body is too large"
+			case stderrors.Is(err, resilience.ErrOpen) || stderrors.Is(err, context.DeadlineExceeded):
+				status = http.StatusServiceUnavailable
+			}
+			log.WithContext(r.Context()).WithError(err).
+				With("upstream", service).
+				With("request_id", r.Header.Get(middleware.RequestIDHeader)).
+				Warn("proxied request failed")
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(response.Response{
+				Success: false,
+				Error: &response.ErrorInfo{
+					Type:    string(errorType),
+					Message: i18n.T(r.Context(), message),
+				},
+				RequestID: r.Header.Get(middleware.RequestIDHeader),
+			})
+		},
+	}
+}
+
+// transport sends proxied requests through an httpclient.Client.
+type transport struct {
+	client *httpclient.Client
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.client.Do(req)
+}
+
+// HealthCheck reports whether the service at baseURL is ready to serve.
+func HealthCheck(client *httpclient.Client, baseURL string) health.Check {
+	readyURL := strings.TrimRight(baseURL, "/") + "/ready"
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, readyURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("not ready: status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
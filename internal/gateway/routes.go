@@ -0,0 +1,126 @@
+// Package gateway fronts the services with one public API: it proxies
+// each request to the service owning its path and serves endpoints that
+// combine several services.
+package gateway
+
+import (
+	"strings"
+)
+
+// Services the gateway routes to.
+const (
+	UserService      = "user"
+	ResourceService  = "resource"
+	BookingService   = "booking"
+	InventoryService = "inventory"
+	PaymentService   = "payment"
+)
+
+// Route sends the requests whose path matches Pattern to Service. Patterns
+// match whole path segments, "*" matching any one segment, and match the
+// paths below them too.
+type Route struct {
+	Pattern string
+	Service string
+}
+
+// Routes maps the public API onto the services. The routes of inventory
+// and payment are reserved for their APIs; both serve events only today.
+var Routes = []Route{
+	{"/api/v1/auth", UserService},
+	{"/api/v1/users", UserService},
+	{"/api/v1/api-keys", UserService},
+	{"/api/v1/audit-logs", UserService},
+	{"/api/v1/admin/users", UserService},
+	{"/api/v1/admin/tenants", UserService},
+	{"/api/v1/notification-defaults", UserService},
+
+	{"/api/v1/resources", ResourceService},
+
+	{"/api/v1/bookings", BookingService},
+	{"/api/v1/users/*/bookings", BookingService},
+	{"/api/v1/resources/*/availability", BookingService},
+	{"/api/v1/resources/*/reviews", BookingService},
+	{"/api/v1/resources/*/rating", BookingService},
+	{"/api/v1/resources/*/rate-card", BookingService},
+	{"/api/v1/reviews", BookingService},
+	{"/api/v1/waitlist", BookingService},
+	{"/api/v1/promo-codes", BookingService},
+	{"/api/v1/admin/stats", BookingService},
+	{"/api/v1/admin/bookings", BookingService},
+	{"/api/v1/admin/webhooks", BookingService},
+	{"/api/v1/admin/webhook-deliveries", BookingService},
+	{"/api/v1/calendar", BookingService},
+
+	{"/api/v1/inventory", InventoryService},
+	{"/api/v1/payments", PaymentService},
+}
+
+// router finds the route of a path. Of the patterns matching a path the
+// longest wins, and of equally long ones the one with fewer wildcards, so
+// "/api/v1/users/*/bookings" takes precedence over "/api/v1/users".
+type router struct {
+	routes []compiledRoute
+}
+
+type compiledRoute struct {
+	segments  []string
+	wildcards int
+	service   string
+}
+
+func newRouter(routes []Route) *router {
+	r := &router{routes: make([]compiledRoute, len(routes))}
+	for i, route := range routes {
+		segments := splitPath(route.Pattern)
+		r.routes[i] = compiledRoute{segments: segments, service: route.Service}
+		for _, segment := range segments {
+			if segment == "*" {
+				r.routes[i].wildcards++
+			}
+		}
+	}
+	return r
+}
+
+// match returns the service of path and whether any route matches it.
+func (r *router) match(path string) (string, bool) {
+	segments := splitPath(path)
+
+	var best *compiledRoute
+	for i := range r.routes {
+		route := &r.routes[i]
+		if !route.matches(segments) {
+			continue
+		}
+		if best == nil || len(route.segments) > len(best.segments) ||
+			len(route.segments) == len(best.segments) && route.wildcards < best.wildcards {
+			best = route
+		}
+	}
+
+	if best == nil {
+		return "", false
+	}
+	return best.service, true
+}
+
+func (r *compiledRoute) matches(segments []string) bool {
+	if len(segments) < len(r.segments) {
+		return false
+	}
+	for i, segment := range r.segments {
+		if segment != "*" && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
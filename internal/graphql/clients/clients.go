@@ -0,0 +1,415 @@
+// Package clients calls the services the GraphQL gateway fronts. Single
+// entities are read over the internal gRPC APIs; lists are read from the
+// public HTTP APIs with the credentials of the caller, so the services
+// apply their own authorization to them.
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/pkg/grpc/bookingpb"
+	"github.com/dmehra2102/booking-system/pkg/grpc/resourcepb"
+	"github.com/dmehra2102/booking-system/pkg/grpc/userpb"
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxResponseBody bounds how much of a service response is read.
+const maxResponseBody = 4 << 20
+
+type User struct {
+	ID        string
+	Email     string
+	Name      string
+	Role      string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Resource struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Location      string    `json:"location"`
+	Capacity      int       `json:"capacity"`
+	BufferMinutes int       `json:"buffer_minutes"`
+	PricePerHour  float64   `json:"price_per_hour"`
+	Currency      string    `json:"currency"`
+	Active        bool      `json:"active"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Booking struct {
+	ID         string
+	UserID     string
+	ResourceID string
+	StartTime  time.Time
+	EndTime    time.Time
+	Status     string
+	// Amount is in major units of Currency.
+	Amount    float64
+	Currency  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TimeSlot struct {
+	Start     time.Time
+	End       time.Time
+	Available int
+}
+
+type Availability struct {
+	ResourceID    string
+	Capacity      int
+	BufferMinutes int
+	Slots         []TimeSlot
+}
+
+// Page is a page of a list read from an HTTP API.
+type Page[T any] struct {
+	Items      []T
+	Page       int
+	PageSize   int
+	Total      int64
+	TotalPages int
+}
+
+// ListBookingsFilter narrows a list of bookings like the query parameters
+// of GET /api/v1/bookings.
+type ListBookingsFilter struct {
+	UserID     string
+	ResourceID string
+	Status     string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	PageSize   int
+}
+
+// ListResourcesFilter narrows a list of resources. Without text, type or
+// location all active resources are listed.
+type ListResourcesFilter struct {
+	Text     string
+	Type     string
+	Location string
+	Page     int
+	PageSize int
+}
+
+// Config holds the addresses of the services.
+type Config struct {
+	BookingServiceURL  string
+	ResourceServiceURL string
+}
+
+// Services reads users, resources and bookings from their services.
+type Services struct {
+	users     userpb.UserServiceClient
+	resources resourcepb.ResourceServiceClient
+	bookings  bookingpb.BookingServiceClient
+	http      *httpclient.Client
+	config    Config
+	retry     retry.Policy
+}
+
+func NewServices(users userpb.UserServiceClient, resources resourcepb.ResourceServiceClient, bookings bookingpb.BookingServiceClient, http *httpclient.Client, config Config, retryPolicy retry.Policy) *Services {
+	retryPolicy.Retryable = func(err error) bool {
+		return status.Code(err) == codes.Unavailable
+	}
+	config.BookingServiceURL = strings.TrimRight(config.BookingServiceURL, "/")
+	config.ResourceServiceURL = strings.TrimRight(config.ResourceServiceURL, "/")
+
+	return &Services{users: users, resources: resources, bookings: bookings, http: http, config: config, retry: retryPolicy}
+}
+
+type credentialsKey struct{}
+
+// WithCredentials returns a context whose HTTP calls send the
+// Authorization header of the caller.
+func WithCredentials(ctx context.Context, authorization string) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, authorization)
+}
+
+func (s *Services) GetUser(ctx context.Context, id string) (*User, error) {
+	var resp *userpb.GetUserResponse
+	err := s.retry.Do(ctx, func(ctx context.Context) (err error) {
+		resp, err = s.users.GetUser(ctx, &userpb.GetUserRequest{Id: id})
+		return err
+	})
+	if err != nil {
+		return nil, fromStatus(err, "user-service", "user")
+	}
+
+	user := resp.GetUser()
+	return &User{
+		ID:        user.GetId(),
+		Email:     user.GetEmail(),
+		Name:      user.GetName(),
+		Role:      user.GetRole(),
+		Active:    user.GetActive(),
+		CreatedAt: user.GetCreatedAt().AsTime(),
+		UpdatedAt: user.GetUpdatedAt().AsTime(),
+	}, nil
+}
+
+func (s *Services) GetResource(ctx context.Context, id string) (*Resource, error) {
+	var resp *resourcepb.GetResourceResponse
+	err := s.retry.Do(ctx, func(ctx context.Context) (err error) {
+		resp, err = s.resources.GetResource(ctx, &resourcepb.GetResourceRequest{Id: id})
+		return err
+	})
+	if err != nil {
+		return nil, fromStatus(err, "resource-service", "resource")
+	}
+
+	resource := resp.GetResource()
+	return &Resource{
+		ID:            resource.GetId(),
+		Name:          resource.GetName(),
+		Type:          resource.GetType(),
+		Location:      resource.GetLocation(),
+		Capacity:      int(resource.GetCapacity()),
+		BufferMinutes: int(resource.GetBufferMinutes()),
+		PricePerHour:  resource.GetPricePerHour(),
+		Currency:      resource.GetCurrency(),
+		Active:        resource.GetActive(),
+		UpdatedAt:     resource.GetUpdatedAt().AsTime(),
+	}, nil
+}
+
+func (s *Services) GetBooking(ctx context.Context, id string) (*Booking, error) {
+	var resp *bookingpb.GetBookingResponse
+	err := s.retry.Do(ctx, func(ctx context.Context) (err error) {
+		resp, err = s.bookings.GetBooking(ctx, &bookingpb.GetBookingRequest{Id: id})
+		return err
+	})
+	if err != nil {
+		return nil, fromStatus(err, "booking-service", "booking")
+	}
+
+	booking := resp.GetBooking()
+	return &Booking{
+		ID:         booking.GetId(),
+		UserID:     booking.GetUserId(),
+		ResourceID: booking.GetResourceId(),
+		StartTime:  booking.GetStartTime().AsTime(),
+		EndTime:    booking.GetEndTime().AsTime(),
+		Status:     booking.GetStatus(),
+		Amount:     booking.GetAmount(),
+		Currency:   booking.GetCurrency(),
+		CreatedAt:  booking.GetCreatedAt().AsTime(),
+		UpdatedAt:  booking.GetUpdatedAt().AsTime(),
+	}, nil
+}
+
+func (s *Services) GetAvailability(ctx context.Context, resourceID string, from, to time.Time) (*Availability, error) {
+	var resp *bookingpb.GetAvailabilityResponse
+	err := s.retry.Do(ctx, func(ctx context.Context) (err error) {
+		resp, err = s.bookings.GetAvailability(ctx, &bookingpb.GetAvailabilityRequest{
+			ResourceId: resourceID,
+			From:       timestamppb.New(from),
+			To:         timestamppb.New(to),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fromStatus(err, "booking-service", "resource")
+	}
+
+	availability := &Availability{
+		ResourceID:    resp.GetResourceId(),
+		Capacity:      int(resp.GetCapacity()),
+		BufferMinutes: int(resp.GetBufferMinutes()),
+		Slots:         make([]TimeSlot, len(resp.GetSlots())),
+	}
+	for i, slot := range resp.GetSlots() {
+		availability.Slots[i] = TimeSlot{Start: slot.GetStart().AsTime(), End: slot.GetEnd().AsTime(), Available: int(slot.GetAvailable())}
+	}
+	return availability, nil
+}
+
+// bookingJSON is a booking as the booking service HTTP API returns it.
+type bookingJSON struct {
+	ID         string      `json:"id"`
+	UserID     string      `json:"user_id"`
+	ResourceID string      `json:"resource_id"`
+	StartTime  time.Time   `json:"start_time"`
+	EndTime    time.Time   `json:"end_time"`
+	Status     string      `json:"status"`
+	Amount     money.Money `json:"amount"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// ListBookings lists the bookings the caller may see: their own, or any
+// for admins.
+func (s *Services) ListBookings(ctx context.Context, filter ListBookingsFilter) (*Page[*Booking], error) {
+	query := pageQuery(filter.Page, filter.PageSize)
+	path := "/api/v1/bookings"
+	if filter.UserID != "" {
+		path = "/api/v1/users/" + url.PathEscape(filter.UserID) + "/bookings"
+	}
+	setIf(query, "resource_id", filter.ResourceID)
+	setIf(query, "status", filter.Status)
+	if filter.From != nil {
+		query.Set("from", filter.From.Format(time.RFC3339))
+	}
+	if filter.To != nil {
+		query.Set("to", filter.To.Format(time.RFC3339))
+	}
+
+	var items []bookingJSON
+	page, err := getPage(ctx, s, "booking-service", s.config.BookingServiceURL+path, query, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings := &Page[*Booking]{Page: page.Page, PageSize: page.PageSize, Total: page.Total, TotalPages: page.TotalPages}
+	for _, b := range items {
+		bookings.Items = append(bookings.Items, &Booking{
+			ID:         b.ID,
+			UserID:     b.UserID,
+			ResourceID: b.ResourceID,
+			StartTime:  b.StartTime,
+			EndTime:    b.EndTime,
+			Status:     b.Status,
+			Amount:     b.Amount.Float(),
+			Currency:   string(b.Amount.Currency),
+			CreatedAt:  b.CreatedAt,
+			UpdatedAt:  b.UpdatedAt,
+		})
+	}
+	return bookings, nil
+}
+
+// ListResources lists the active resources, searched by relevance when
+// the filter has text, a type or a location.
+func (s *Services) ListResources(ctx context.Context, filter ListResourcesFilter) (*Page[*Resource], error) {
+	query := pageQuery(filter.Page, filter.PageSize)
+
+	if filter.Text == "" && filter.Type == "" && filter.Location == "" {
+		var items []*Resource
+		page, err := getPage(ctx, s, "resource-service", s.config.ResourceServiceURL+"/api/v1/resources", query, &items)
+		if err != nil {
+			return nil, err
+		}
+		return &Page[*Resource]{Items: items, Page: page.Page, PageSize: page.PageSize, Total: page.Total, TotalPages: page.TotalPages}, nil
+	}
+
+	setIf(query, "q", filter.Text)
+	setIf(query, "type", filter.Type)
+	setIf(query, "location", filter.Location)
+
+	var result struct {
+		Hits []*Resource `json:"hits"`
+	}
+	page, err := getPage(ctx, s, "resource-service", s.config.ResourceServiceURL+"/api/v1/resources/search", query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &Page[*Resource]{Items: result.Hits, Page: page.Page, PageSize: page.PageSize, Total: page.Total, TotalPages: page.TotalPages}, nil
+}
+
+type pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// getPage reads a paginated response of an HTTP API into data and returns
+// its pagination.
+func getPage(ctx context.Context, s *Services, service, rawURL string, query url.Values, data any) (*pagination, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to build request", err)
+	}
+	if authorization, ok := ctx.Value(credentialsKey{}).(string); ok && authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	req.Header.Set(tenancy.Header, tenancy.ID(ctx))
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, errors.NewExternalError(service, "service unavailable", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, errors.NewExternalError(service, "failed to read response", err)
+	}
+
+	var envelope struct {
+		Data       json.RawMessage `json:"data"`
+		Pagination *pagination     `json:"pagination"`
+		Error      *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, errors.NewExternalError(service, fmt.Sprintf("invalid response with status %d", resp.StatusCode), err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if envelope.Error == nil {
+			return nil, errors.NewExternalError(service, fmt.Sprintf("service answered %s", resp.Status), nil)
+		}
+		// Errors about the request are passed on as the service reported them
+		return nil, &errors.AppError{Type: errors.ErrorType(envelope.Error.Type), Message: envelope.Error.Message, Code: resp.StatusCode}
+	}
+
+	if err := json.Unmarshal(envelope.Data, data); err != nil {
+		return nil, errors.NewExternalError(service, "invalid response data", err)
+	}
+	if envelope.Pagination == nil {
+		envelope.Pagination = &pagination{}
+	}
+	return envelope.Pagination, nil
+}
+
+func pageQuery(page, pageSize int) url.Values {
+	query := url.Values{}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		query.Set("page_size", strconv.Itoa(pageSize))
+	}
+	return query
+}
+
+func setIf(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+// fromStatus maps a failed gRPC call to an application error.
+func fromStatus(err error, service, kind string) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return errors.NewNotFoundError(kind)
+	case codes.InvalidArgument:
+		return errors.NewValidationError(status.Convert(err).Message(), err)
+	default:
+		return errors.NewExternalError(service, fmt.Sprintf("failed to get %s", kind), err)
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/graphql/resolver"
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Persisted query errors, as named by the automatic persisted queries
+// protocol clients implement.
+const (
+	persistedQueryNotFound    = "PersistedQueryNotFound"
+	persistedQueryHashInvalid = "provided sha does not match query"
+)
+
+type GraphQLHandler struct {
+	schema     *graphql.Schema
+	resolver   *resolver.Resolver
+	redis      *database.RedisClient
+	queriesTTL time.Duration
+	logger     *logger.Logger
+}
+
+// NewGraphQLHandler serves queries of schema. Persisted queries are kept in
+// Redis for queriesTTL after they were last registered.
+func NewGraphQLHandler(schema *graphql.Schema, resolver *resolver.Resolver, redis *database.RedisClient, queriesTTL time.Duration, logger *logger.Logger) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema:     schema,
+		resolver:   resolver,
+		redis:      redis,
+		queriesTTL: queriesTTL,
+		logger:     logger,
+	}
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *struct {
+			SHA256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// Query executes a query sent as a JSON body, or in the query string of a
+// GET request with variables and extensions JSON encoded. A query can be
+// sent as the SHA-256 hash of one registered by sending it with its hash
+// before.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req request
+	if c.Request.Method == http.MethodGet {
+		if err := bindQueryString(c, &req); err != nil {
+			writeErrors(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		writeErrors(c, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	if pq := req.Extensions.PersistedQuery; pq != nil {
+		query, message := h.persistedQuery(ctx, pq.SHA256Hash, req.Query)
+		if message != "" {
+			// Clients answer PersistedQueryNotFound by sending the query, so
+			// it is reported like any other GraphQL error
+			writeErrors(c, http.StatusOK, message)
+			return
+		}
+		req.Query = query
+	}
+	if req.Query == "" {
+		writeErrors(c, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	var viewer *resolver.Viewer
+	if userID := c.GetString("user_id"); userID != "" {
+		viewer = &resolver.Viewer{UserID: userID, Role: c.GetString("user_role")}
+	}
+	ctx = h.resolver.NewContext(ctx, viewer, c.GetHeader("Authorization"))
+
+	c.JSON(http.StatusOK, h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+// persistedQuery resolves the query of hash, registering query under hash
+// when it is set. It returns the error to report on failure.
+func (h *GraphQLHandler) persistedQuery(ctx context.Context, hash, query string) (string, string) {
+	key := "graphql:persisted_query:" + hash
+
+	if query != "" {
+		sum := sha256.Sum256([]byte(query))
+		if hex.EncodeToString(sum[:]) != hash {
+			return "", persistedQueryHashInvalid
+		}
+		// Registering is best effort; the query runs either way
+		if err := h.redis.Set(ctx, key, query, h.queriesTTL); err != nil {
+			h.logger.WithContext(ctx).WithError(err).Warn("failed to store persisted query")
+		}
+		return query, ""
+	}
+
+	query, err := h.redis.Get(ctx, key)
+	if err != nil {
+		if err != redis.Nil {
+			h.logger.WithContext(ctx).WithError(err).Warn("failed to read persisted query")
+		}
+		return "", persistedQueryNotFound
+	}
+	return query, ""
+}
+
+func bindQueryString(c *gin.Context, req *request) error {
+	req.Query = c.Query("query")
+	req.OperationName = c.Query("operationName")
+	if variables := c.Query("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return fmt.Errorf("invalid variables: %v", err)
+		}
+	}
+	if extensions := c.Query("extensions"); extensions != "" {
+		if err := json.Unmarshal([]byte(extensions), &req.Extensions); err != nil {
+			return fmt.Errorf("invalid extensions: %v", err)
+		}
+	}
+	return nil
+}
+
+func writeErrors(c *gin.Context, status int, message string) {
+	c.JSON(status, &graphql.Response{Errors: []*errors.QueryError{{Message: message}}})
+}
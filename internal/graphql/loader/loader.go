@@ -0,0 +1,123 @@
+// Package loader batches and caches the lookups of one GraphQL request, so
+// a list of bookings resolving their users asks for each user once,
+// together with the other users of the list.
+package loader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of looking up one key.
+type Result[V any] struct {
+	Value V
+	Err   error
+}
+
+// BatchFunc looks up keys and returns one result per key, in the order of
+// keys.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) []Result[V]
+
+// FanOut makes a BatchFunc of a lookup of single keys, for services
+// without a batch API. The keys of a batch are looked up concurrently.
+func FanOut[K comparable, V any](get func(ctx context.Context, key K) (V, error)) BatchFunc[K, V] {
+	return func(ctx context.Context, keys []K) []Result[V] {
+		results := make([]Result[V], len(keys))
+
+		var wg sync.WaitGroup
+		for i, key := range keys {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i].Value, results[i].Err = get(ctx, key)
+			}()
+		}
+		wg.Wait()
+
+		return results
+	}
+}
+
+// Loader collects the keys loaded within wait of the first one into a
+// batch, up to maxBatch keys, and caches the results for its lifetime.
+// Create one Loader per request.
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[K]*pending[V]
+	current *batch[K, V]
+}
+
+type pending[V any] struct {
+	done   chan struct{}
+	result Result[V]
+}
+
+type batch[K comparable, V any] struct {
+	keys     []K
+	pending  []*pending[V]
+	dispatch sync.Once
+}
+
+func New[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, wait: wait, maxBatch: maxBatch, cache: make(map[K]*pending[V])}
+}
+
+// Load returns the value of key, waiting for the batch it joins.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	p, ok := l.cache[key]
+	if !ok {
+		p = &pending[V]{done: make(chan struct{})}
+		l.cache[key] = p
+		l.add(ctx, key, p)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return p.result.Value, p.result.Err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// add puts key in the current batch, starting one if needed. l.mu is held.
+func (l *Loader[K, V]) add(ctx context.Context, key K, p *pending[V]) {
+	if l.current == nil {
+		b := &batch[K, V]{}
+		l.current = b
+		time.AfterFunc(l.wait, func() { l.dispatch(ctx, b) })
+	}
+
+	b := l.current
+	b.keys = append(b.keys, key)
+	b.pending = append(b.pending, p)
+
+	if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+		l.current = nil
+		go l.dispatch(ctx, b)
+	}
+}
+
+// dispatch fetches a batch once, whether it filled up or its wait ended.
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	b.dispatch.Do(func() {
+		l.mu.Lock()
+		if l.current == b {
+			l.current = nil
+		}
+		l.mu.Unlock()
+
+		results := l.fetch(ctx, b.keys)
+		for i, p := range b.pending {
+			p.result = results[i]
+			close(p.done)
+		}
+	})
+}
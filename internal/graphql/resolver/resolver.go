@@ -0,0 +1,248 @@
+// Package resolver resolves the GraphQL schema of the gateway against the
+// users, resources and bookings of the services.
+package resolver
+
+import (
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/graphql/clients"
+	"github.com/dmehra2102/booking-system/internal/graphql/loader"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Lookups of one request made within batchWait of each other are batched,
+// up to maxBatch keys.
+const (
+	batchWait = 2 * time.Millisecond
+	maxBatch  = 100
+)
+
+// Viewer is the authenticated caller of a request.
+type Viewer struct {
+	UserID string
+	Role   string
+}
+
+func (v *Viewer) canRead(userID string) bool {
+	return v != nil && (v.UserID == userID || v.Role == auth.RoleAdmin)
+}
+
+// Resolver is the root resolver of the schema.
+type Resolver struct {
+	services *clients.Services
+}
+
+func New(services *clients.Services) *Resolver {
+	return &Resolver{services: services}
+}
+
+// Schema parses the schema with r as its root resolver. Queries nested
+// deeper than maxDepth are rejected.
+func Schema(r *Resolver, maxDepth int) (*graphql.Schema, error) {
+	return graphql.ParseSchema(schema, r, graphql.MaxDepth(maxDepth))
+}
+
+type requestKey struct{}
+
+// request holds the state of one GraphQL request.
+type request struct {
+	viewer    *Viewer
+	users     *loader.Loader[string, *clients.User]
+	resources *loader.Loader[string, *clients.Resource]
+	bookings  *loader.Loader[string, *clients.Booking]
+}
+
+// NewContext returns the context a query of viewer, nil when anonymous,
+// executes in. Calls to the HTTP APIs send authorization, the
+// Authorization header of the request.
+func (r *Resolver) NewContext(ctx context.Context, viewer *Viewer, authorization string) context.Context {
+	ctx = clients.WithCredentials(ctx, authorization)
+	return context.WithValue(ctx, requestKey{}, &request{
+		viewer:    viewer,
+		users:     loader.New(loader.FanOut(r.services.GetUser), batchWait, maxBatch),
+		resources: loader.New(loader.FanOut(r.services.GetResource), batchWait, maxBatch),
+		bookings:  loader.New(loader.FanOut(r.services.GetBooking), batchWait, maxBatch),
+	})
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// Me returns the authenticated user, or null for anonymous requests.
+func (r *Resolver) Me(ctx context.Context) (*userResolver, error) {
+	viewer := requestFrom(ctx).viewer
+	if viewer == nil {
+		return nil, nil
+	}
+	return r.user(ctx, viewer.UserID)
+}
+
+func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	return r.user(ctx, string(args.ID))
+}
+
+// user reads a user the viewer may see. The gRPC API of the user service
+// trusts its callers, so the gateway checks access itself.
+func (r *Resolver) user(ctx context.Context, id string) (*userResolver, error) {
+	req := requestFrom(ctx)
+	if req.viewer == nil {
+		return nil, resolverError(errors.NewUnauthorizedError("authentication required"))
+	}
+	if !req.viewer.canRead(id) {
+		return nil, resolverError(errors.NewForbiddenError("you can only read your own user"))
+	}
+
+	user, err := req.users.Load(ctx, id)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &userResolver{resolver: r, user: user}, nil
+}
+
+func (r *Resolver) Booking(ctx context.Context, args struct{ ID graphql.ID }) (*bookingResolver, error) {
+	req := requestFrom(ctx)
+	if req.viewer == nil {
+		return nil, resolverError(errors.NewUnauthorizedError("authentication required"))
+	}
+
+	booking, err := req.bookings.Load(ctx, string(args.ID))
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	// Other users' bookings are reported missing, as by the HTTP API
+	if !req.viewer.canRead(booking.UserID) {
+		return nil, resolverError(errors.NewNotFoundError("booking"))
+	}
+	return &bookingResolver{resolver: r, booking: booking}, nil
+}
+
+type bookingsArgs struct {
+	UserID     *graphql.ID
+	ResourceID *graphql.ID
+	Status     *string
+	From       *graphql.Time
+	To         *graphql.Time
+	Page       *int32
+	PageSize   *int32
+}
+
+func (r *Resolver) Bookings(ctx context.Context, args bookingsArgs) (*bookingPageResolver, error) {
+	filter := clients.ListBookingsFilter{
+		UserID:     idValue(args.UserID),
+		ResourceID: idValue(args.ResourceID),
+		Status:     stringValue(args.Status),
+		Page:       intValue(args.Page),
+		PageSize:   intValue(args.PageSize),
+	}
+	if args.From != nil {
+		filter.From = &args.From.Time
+	}
+	if args.To != nil {
+		filter.To = &args.To.Time
+	}
+
+	return r.listBookings(ctx, filter)
+}
+
+func (r *Resolver) listBookings(ctx context.Context, filter clients.ListBookingsFilter) (*bookingPageResolver, error) {
+	page, err := r.services.ListBookings(ctx, filter)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &bookingPageResolver{resolver: r, page: page}, nil
+}
+
+func (r *Resolver) Resource(ctx context.Context, args struct{ ID graphql.ID }) (*resourceResolver, error) {
+	resource, err := requestFrom(ctx).resources.Load(ctx, string(args.ID))
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &resourceResolver{resolver: r, resource: resource}, nil
+}
+
+type resourcesArgs struct {
+	Query    *string
+	Type     *string
+	Location *string
+	Page     *int32
+	PageSize *int32
+}
+
+func (r *Resolver) Resources(ctx context.Context, args resourcesArgs) (*resourcePageResolver, error) {
+	page, err := r.services.ListResources(ctx, clients.ListResourcesFilter{
+		Text:     stringValue(args.Query),
+		Type:     stringValue(args.Type),
+		Location: stringValue(args.Location),
+		Page:     intValue(args.Page),
+		PageSize: intValue(args.PageSize),
+	})
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &resourcePageResolver{resolver: r, page: page}, nil
+}
+
+type availabilityArgs struct {
+	ResourceID graphql.ID
+	From       graphql.Time
+	To         graphql.Time
+}
+
+func (r *Resolver) Availability(ctx context.Context, args availabilityArgs) (*availabilityResolver, error) {
+	return r.availability(ctx, string(args.ResourceID), args.From.Time, args.To.Time)
+}
+
+func (r *Resolver) availability(ctx context.Context, resourceID string, from, to time.Time) (*availabilityResolver, error) {
+	availability, err := r.services.GetAvailability(ctx, resourceID, from, to)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &availabilityResolver{availability: availability}, nil
+}
+
+// appError reports an application error to GraphQL clients by its message,
+// with its type as the "code" extension.
+type appError struct {
+	*errors.AppError
+}
+
+func (e appError) Error() string {
+	return e.Message
+}
+
+func (e appError) Extensions() map[string]any {
+	return map[string]any{"code": e.Type}
+}
+
+func resolverError(err error) error {
+	return appError{errors.GetAppError(err)}
+}
+
+func idValue(id *graphql.ID) string {
+	if id == nil {
+		return ""
+	}
+	return string(*id)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(n *int32) int {
+	if n == nil {
+		return 0
+	}
+	return int(*n)
+}
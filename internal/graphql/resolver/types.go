@@ -0,0 +1,162 @@
+package resolver
+
+import (
+	"context"
+	"math"
+
+	"github.com/dmehra2102/booking-system/internal/graphql/clients"
+	"github.com/graph-gophers/graphql-go"
+)
+
+type userResolver struct {
+	resolver *Resolver
+	user     *clients.User
+}
+
+func (u *userResolver) ID() graphql.ID          { return graphql.ID(u.user.ID) }
+func (u *userResolver) Email() string           { return u.user.Email }
+func (u *userResolver) Name() string            { return u.user.Name }
+func (u *userResolver) Role() string            { return u.user.Role }
+func (u *userResolver) Active() bool            { return u.user.Active }
+func (u *userResolver) CreatedAt() graphql.Time { return graphql.Time{Time: u.user.CreatedAt} }
+func (u *userResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: u.user.UpdatedAt} }
+
+type userBookingsArgs struct {
+	Status   *string
+	Page     *int32
+	PageSize *int32
+}
+
+func (u *userResolver) Bookings(ctx context.Context, args userBookingsArgs) (*bookingPageResolver, error) {
+	return u.resolver.listBookings(ctx, clients.ListBookingsFilter{
+		UserID:   u.user.ID,
+		Status:   stringValue(args.Status),
+		Page:     intValue(args.Page),
+		PageSize: intValue(args.PageSize),
+	})
+}
+
+type resourceResolver struct {
+	resolver *Resolver
+	resource *clients.Resource
+}
+
+func (r *resourceResolver) ID() graphql.ID          { return graphql.ID(r.resource.ID) }
+func (r *resourceResolver) Name() string            { return r.resource.Name }
+func (r *resourceResolver) Type() string            { return r.resource.Type }
+func (r *resourceResolver) Location() string        { return r.resource.Location }
+func (r *resourceResolver) Capacity() int32         { return int32(r.resource.Capacity) }
+func (r *resourceResolver) BufferMinutes() int32    { return int32(r.resource.BufferMinutes) }
+func (r *resourceResolver) PricePerHour() float64   { return r.resource.PricePerHour }
+func (r *resourceResolver) Currency() string        { return r.resource.Currency }
+func (r *resourceResolver) Active() bool            { return r.resource.Active }
+func (r *resourceResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.resource.UpdatedAt} }
+
+func (r *resourceResolver) Availability(ctx context.Context, args struct{ From, To graphql.Time }) (*availabilityResolver, error) {
+	return r.resolver.availability(ctx, r.resource.ID, args.From.Time, args.To.Time)
+}
+
+type bookingResolver struct {
+	resolver *Resolver
+	booking  *clients.Booking
+}
+
+func (b *bookingResolver) ID() graphql.ID          { return graphql.ID(b.booking.ID) }
+func (b *bookingResolver) UserID() graphql.ID      { return graphql.ID(b.booking.UserID) }
+func (b *bookingResolver) ResourceID() graphql.ID  { return graphql.ID(b.booking.ResourceID) }
+func (b *bookingResolver) StartTime() graphql.Time { return graphql.Time{Time: b.booking.StartTime} }
+func (b *bookingResolver) EndTime() graphql.Time   { return graphql.Time{Time: b.booking.EndTime} }
+func (b *bookingResolver) Status() string          { return b.booking.Status }
+func (b *bookingResolver) Amount() float64         { return b.booking.Amount }
+func (b *bookingResolver) Currency() string        { return b.booking.Currency }
+func (b *bookingResolver) CreatedAt() graphql.Time { return graphql.Time{Time: b.booking.CreatedAt} }
+func (b *bookingResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: b.booking.UpdatedAt} }
+
+// User is batched with the users of the other bookings of the query.
+// Bookings are only resolved for their owner or an admin, who can both
+// read the user.
+func (b *bookingResolver) User(ctx context.Context) (*userResolver, error) {
+	user, err := requestFrom(ctx).users.Load(ctx, b.booking.UserID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &userResolver{resolver: b.resolver, user: user}, nil
+}
+
+// Resource is batched with the resources of the other bookings of the
+// query.
+func (b *bookingResolver) Resource(ctx context.Context) (*resourceResolver, error) {
+	resource, err := requestFrom(ctx).resources.Load(ctx, b.booking.ResourceID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &resourceResolver{resolver: b.resolver, resource: resource}, nil
+}
+
+type availabilityResolver struct {
+	availability *clients.Availability
+}
+
+func (a *availabilityResolver) ResourceID() graphql.ID { return graphql.ID(a.availability.ResourceID) }
+func (a *availabilityResolver) Capacity() int32        { return int32(a.availability.Capacity) }
+func (a *availabilityResolver) BufferMinutes() int32   { return int32(a.availability.BufferMinutes) }
+
+func (a *availabilityResolver) Slots() []*timeSlotResolver {
+	slots := make([]*timeSlotResolver, len(a.availability.Slots))
+	for i := range a.availability.Slots {
+		slots[i] = &timeSlotResolver{slot: &a.availability.Slots[i]}
+	}
+	return slots
+}
+
+type timeSlotResolver struct {
+	slot *clients.TimeSlot
+}
+
+func (t *timeSlotResolver) Start() graphql.Time { return graphql.Time{Time: t.slot.Start} }
+func (t *timeSlotResolver) End() graphql.Time   { return graphql.Time{Time: t.slot.End} }
+func (t *timeSlotResolver) Available() int32    { return int32(t.slot.Available) }
+
+type pageInfoResolver struct {
+	page, pageSize, totalPages int
+	total                      int64
+}
+
+func (p *pageInfoResolver) Page() int32       { return int32(p.page) }
+func (p *pageInfoResolver) PageSize() int32   { return int32(p.pageSize) }
+func (p *pageInfoResolver) Total() int32      { return int32(min(p.total, math.MaxInt32)) }
+func (p *pageInfoResolver) TotalPages() int32 { return int32(p.totalPages) }
+
+type bookingPageResolver struct {
+	resolver *Resolver
+	page     *clients.Page[*clients.Booking]
+}
+
+func (p *bookingPageResolver) Items() []*bookingResolver {
+	items := make([]*bookingResolver, len(p.page.Items))
+	for i, booking := range p.page.Items {
+		items[i] = &bookingResolver{resolver: p.resolver, booking: booking}
+	}
+	return items
+}
+
+func (p *bookingPageResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{page: p.page.Page, pageSize: p.page.PageSize, total: p.page.Total, totalPages: p.page.TotalPages}
+}
+
+type resourcePageResolver struct {
+	resolver *Resolver
+	page     *clients.Page[*clients.Resource]
+}
+
+func (p *resourcePageResolver) Items() []*resourceResolver {
+	items := make([]*resourceResolver, len(p.page.Items))
+	for i, resource := range p.page.Items {
+		items[i] = &resourceResolver{resolver: p.resolver, resource: resource}
+	}
+	return items
+}
+
+func (p *resourcePageResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{page: p.page.Page, pageSize: p.page.PageSize, total: p.page.Total, totalPages: p.page.TotalPages}
+}
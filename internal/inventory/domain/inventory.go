@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationStatusReserved ReservationStatus = "reserved"
+	ReservationStatusReleased ReservationStatus = "released"
+)
+
+// Reservation holds a time slot of a resource for a booking. A slot can be
+// reserved by as many bookings at once as the resource capacity allows.
+// Until the booking is paid the reservation is only a hold that is
+// released automatically at ExpiresAt.
+type Reservation struct {
+	ID            string            `json:"id" db:"id"`
+	ResourceID    string            `json:"resource_id" db:"resource_id"`
+	BookingID     string            `json:"booking_id" db:"booking_id"`
+	StartTime     time.Time         `json:"start_time" db:"start_time"`
+	EndTime       time.Time         `json:"end_time" db:"end_time"`
+	Status        ReservationStatus `json:"status" db:"status"`
+	ReleaseReason string            `json:"release_reason,omitempty" db:"release_reason"`
+	ReservedAt    time.Time         `json:"reserved_at" db:"reserved_at"`
+	ExpiresAt     *time.Time        `json:"expires_at,omitempty" db:"expires_at"`
+	ReleasedAt    *time.Time        `json:"released_at,omitempty" db:"released_at"`
+}
+
+type ReserveRequest struct {
+	BookingID  string    `json:"booking_id" validate:"required"`
+	ResourceID string    `json:"resource_id" validate:"required"`
+	StartTime  time.Time `json:"start_time" validate:"required"`
+	EndTime    time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+}
+
+func (r *Reservation) IsActive() bool {
+	return r.Status == ReservationStatusReserved
+}
+
+// PeakConcurrency returns the highest number of active reservations from
+// others that overlap r's window at any single instant. Each reservation
+// occupies the resource for buffer beyond its end time. This is the rule
+// the booking service applies before a booking is created, so a booking it
+// accepts can also be reserved here.
+func (r *Reservation) PeakConcurrency(others []*Reservation, buffer time.Duration) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+
+	windowEnd := r.EndTime.Add(buffer)
+
+	edges := make([]edge, 0, len(others)*2)
+	for _, other := range others {
+		if !other.IsActive() || other.ResourceID != r.ResourceID {
+			continue
+		}
+
+		start, end := other.StartTime, other.EndTime.Add(buffer)
+		if !start.Before(windowEnd) || !end.After(r.StartTime) {
+			continue
+		}
+		if start.Before(r.StartTime) {
+			start = r.StartTime
+		}
+		if end.After(windowEnd) {
+			end = windowEnd
+		}
+		edges = append(edges, edge{at: start, delta: 1}, edge{at: end, delta: -1})
+	}
+
+	// Ends sort before starts at the same instant so back-to-back
+	// reservations are not counted as concurrent.
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	current, peak := 0, 0
+	for _, e := range edges {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+
+	return peak
+}
+
+// FitsCapacity reports whether r can be added alongside others without the
+// resource exceeding capacity concurrent reservations.
+func (r *Reservation) FitsCapacity(others []*Reservation, capacity int, buffer time.Duration) bool {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return r.PeakConcurrency(others, buffer) < capacity
+}
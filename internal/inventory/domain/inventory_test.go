@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+var testDay = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+// reservation returns an active reservation of resource r-1 from hour
+// start to hour end of testDay.
+func reservation(start, end float64) *Reservation {
+	return &Reservation{
+		ResourceID: "r-1",
+		Status:     ReservationStatusReserved,
+		StartTime:  testDay.Add(time.Duration(start * float64(time.Hour))),
+		EndTime:    testDay.Add(time.Duration(end * float64(time.Hour))),
+	}
+}
+
+func TestFitsCapacity(t *testing.T) {
+	released := reservation(9, 11)
+	released.Status = ReservationStatusReleased
+
+	otherResource := reservation(9, 11)
+	otherResource.ResourceID = "r-2"
+
+	tests := []struct {
+		name        string
+		reservation *Reservation
+		others      []*Reservation
+		capacity    int
+		buffer      time.Duration
+		wantPeak    int
+		wantFits    bool
+	}{
+		{
+			name:        "empty resource",
+			reservation: reservation(9, 10),
+			capacity:    1,
+			wantFits:    true,
+		},
+		{
+			name:        "overlap with capacity 1",
+			reservation: reservation(9, 10),
+			others:      []*Reservation{reservation(9.5, 10.5)},
+			capacity:    1,
+			wantPeak:    1,
+			wantFits:    false,
+		},
+		{
+			name:        "reservations in sequence do not add up",
+			reservation: reservation(9, 12),
+			others:      []*Reservation{reservation(9, 10), reservation(11, 12)},
+			capacity:    2,
+			wantPeak:    1,
+			wantFits:    true,
+		},
+		{
+			name:        "capacity reached at one instant",
+			reservation: reservation(9, 12),
+			others:      []*Reservation{reservation(9, 10), reservation(9.5, 11)},
+			capacity:    2,
+			wantPeak:    2,
+			wantFits:    false,
+		},
+		{
+			name:        "released and other resources are ignored",
+			reservation: reservation(9, 12),
+			others:      []*Reservation{released, otherResource},
+			capacity:    1,
+			wantFits:    true,
+		},
+		{
+			name:        "back to back",
+			reservation: reservation(10, 11),
+			others:      []*Reservation{reservation(9, 10)},
+			capacity:    1,
+			wantFits:    true,
+		},
+		{
+			name:        "within buffer",
+			reservation: reservation(10.1, 11),
+			others:      []*Reservation{reservation(9, 10)},
+			capacity:    1,
+			buffer:      15 * time.Minute,
+			wantPeak:    1,
+			wantFits:    false,
+		},
+		{
+			name:        "exactly buffer after",
+			reservation: reservation(10.25, 11),
+			others:      []*Reservation{reservation(9, 10)},
+			capacity:    1,
+			buffer:      15 * time.Minute,
+			wantFits:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if peak := tt.reservation.PeakConcurrency(tt.others, tt.buffer); peak != tt.wantPeak {
+				t.Errorf("PeakConcurrency() = %d, want %d", peak, tt.wantPeak)
+			}
+			if fits := tt.reservation.FitsCapacity(tt.others, tt.capacity, tt.buffer); fits != tt.wantFits {
+				t.Errorf("FitsCapacity() = %v, want %v", fits, tt.wantFits)
+			}
+		})
+	}
+}
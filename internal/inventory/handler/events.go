@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/inventory/domain"
+	"github.com/dmehra2102/booking-system/internal/inventory/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler adapts booking and payment events consumed from Kafka to
+// inventory service calls.
+type EventHandler struct {
+	service *service.InventoryService
+	logger  *logger.Logger
+}
+
+func NewEventHandler(service *service.InventoryService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *EventHandler) HandleBookingRequested(ctx context.Context, event events.BookingRequestedEvent) error {
+	_, err := h.service.Reserve(ctx, &domain.ReserveRequest{
+		BookingID:  event.Data.BookingID,
+		ResourceID: event.Data.ResourceID,
+		StartTime:  event.Data.StartTime,
+		EndTime:    event.Data.EndTime,
+	})
+
+	// An unavailable slot is a business outcome that has already been
+	// published, so it must not be retried.
+	if err != nil && !isFinal(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (h *EventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	return h.service.Release(ctx, event.Data.BookingID, events.ReleaseReasonBookingCancelled)
+}
+
+// HandlePaymentProcessed keeps the slot of a paid booking beyond its hold.
+func (h *EventHandler) HandlePaymentProcessed(ctx context.Context, event events.PaymentProcessedEvent) error {
+	return h.service.ConfirmHold(ctx, event.Data.BookingID)
+}
+
+func isFinal(err error) bool {
+	switch errors.GetAppError(err).Type {
+	case errors.ErrorTypeConfict, errors.ErrorTypeNotFound, errors.ErrorTypeValidation:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/inventory/service"
+	"github.com/dmehra2102/booking-system/pkg/grpc/inventorypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type InventoryGRPCServer struct {
+	inventorypb.UnimplementedInventoryServiceServer
+	service *service.InventoryService
+}
+
+func NewInventoryGRPCServer(service *service.InventoryService) *InventoryGRPCServer {
+	return &InventoryGRPCServer{service: service}
+}
+
+func (s *InventoryGRPCServer) GetReservation(ctx context.Context, req *inventorypb.GetReservationRequest) (*inventorypb.GetReservationResponse, error) {
+	if err := grpcserver.RequireUUID("booking_id", req.GetBookingId()); err != nil {
+		return nil, err
+	}
+
+	reservation, err := s.service.GetReservation(ctx, req.GetBookingId())
+	if err != nil {
+		return nil, grpcserver.ToStatus(err)
+	}
+
+	return &inventorypb.GetReservationResponse{
+		Reservation: &inventorypb.Reservation{
+			Id:         reservation.ID,
+			ResourceId: reservation.ResourceID,
+			BookingId:  reservation.BookingID,
+			StartTime:  timestamppb.New(reservation.StartTime),
+			EndTime:    timestamppb.New(reservation.EndTime),
+			Status:     string(reservation.Status),
+			ReservedAt: timestamppb.New(reservation.ReservedAt),
+		},
+	}, nil
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/inventory/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresInventoryRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresInventoryRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresInventoryRepository {
+	return &PostgresInventoryRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+// Reserve inserts the reservation if the resource still has capacity for
+// its window. The resource row is locked for the duration of the
+// transaction so concurrent reservations for the same resource serialize.
+func (r *PostgresInventoryRepository) Reserve(ctx context.Context, reservation *domain.Reservation) (err error) {
+	ctx, span := r.tracer.Start(ctx, "inventory.repository.reserve")
+	defer tracing.End(span, &err)
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return errors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	var capacity, bufferMinutes int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COALESCE(capacity, 1), COALESCE(buffer_minutes, 0) FROM resources WHERE id = $1 AND tenant_id = $2 AND active = true FOR UPDATE`,
+		reservation.ResourceID, tenancy.ID(ctx),
+	).Scan(&capacity, &bufferMinutes)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewNotFoundError("resource")
+		}
+		return errors.NewInternalError("failed to lock resource", err)
+	}
+	buffer := time.Duration(bufferMinutes) * time.Minute
+
+	// Reservations ending up to buffer before the window still occupy the
+	// resource during their turnaround time
+	rows, err := tx.QueryContext(ctx, selectReservationQuery+`
+		WHERE resource_id = $1 AND tenant_id = $5 AND status = $2
+		  AND start_time < $4 AND end_time > $3
+	`, reservation.ResourceID, domain.ReservationStatusReserved,
+		reservation.StartTime.Add(-buffer), reservation.EndTime.Add(buffer), tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to list overlapping reservations", err)
+	}
+	defer rows.Close()
+
+	overlapping := make([]*domain.Reservation, 0)
+	for rows.Next() {
+		other, err := scanReservation(rows)
+		if err != nil {
+			return errors.NewInternalError("failed to scan reservation", err)
+		}
+		overlapping = append(overlapping, other)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.NewInternalError("failed to iterate reservations", err)
+	}
+	rows.Close()
+
+	if !reservation.FitsCapacity(overlapping, capacity, buffer) {
+		return errors.NewConflictError("resource is not available for the requested time")
+	}
+
+	reservation.ID = uuid.New().String()
+	reservation.Status = domain.ReservationStatusReserved
+	reservation.ReservedAt = time.Now().UTC()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO reservations (id, tenant_id, resource_id, booking_id, start_time, end_time, status, reserved_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, reservation.ID, tenancy.ID(ctx), reservation.ResourceID, reservation.BookingID,
+		reservation.StartTime, reservation.EndTime, reservation.Status, reservation.ReservedAt, reservation.ExpiresAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create reservation", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("failed to commit reservation", err)
+	}
+
+	return nil
+}
+
+const selectReservationQuery = `
+	SELECT id, resource_id, booking_id, start_time, end_time, status, reserved_at, expires_at
+	FROM reservations
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReservation(row rowScanner) (*domain.Reservation, error) {
+	reservation := &domain.Reservation{}
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&reservation.ID, &reservation.ResourceID, &reservation.BookingID,
+		&reservation.StartTime, &reservation.EndTime, &reservation.Status, &reservation.ReservedAt,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		reservation.ExpiresAt = &expiresAt.Time
+	}
+	return reservation, nil
+}
+
+func (r *PostgresInventoryRepository) GetActiveByBookingID(ctx context.Context, bookingID string) (_ *domain.Reservation, err error) {
+	ctx, span := r.tracer.Start(ctx, "inventory.repository.get_active_by_booking_id", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	query := selectReservationQuery + ` WHERE booking_id = $1 AND tenant_id = $3 AND status = $2`
+
+	reservation, err := scanReservation(r.db.QueryRow(ctx, "inventory.get_active_by_booking_id", query, bookingID, domain.ReservationStatusReserved, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("reservation")
+		}
+		return nil, errors.NewInternalError("failed to get reservation", err)
+	}
+
+	return reservation, nil
+}
+
+// ListExpired returns up to limit active reservations whose hold expired
+// before now, oldest first.
+func (r *PostgresInventoryRepository) ListExpired(ctx context.Context, now time.Time, limit int) (_ []*domain.Reservation, err error) {
+	ctx, span := r.tracer.Start(ctx, "inventory.repository.list_expired")
+	defer tracing.End(span, &err)
+
+	query := selectReservationQuery + `
+		WHERE status = $1 AND tenant_id = $4 AND expires_at <= $2
+		ORDER BY expires_at
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, "inventory.list_expired", query, domain.ReservationStatusReserved, now, limit, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list expired reservations", err)
+	}
+	defer rows.Close()
+
+	reservations := make([]*domain.Reservation, 0)
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to scan reservation", err)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to iterate reservations", err)
+	}
+
+	return reservations, nil
+}
+
+// ClearExpiry turns the hold into a reservation that lasts until it is
+// released.
+func (r *PostgresInventoryRepository) ClearExpiry(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "inventory.repository.clear_expiry")
+	defer tracing.End(span, &err)
+
+	result, err := r.db.Exec(ctx, "inventory.clear_expiry",
+		`UPDATE reservations SET expires_at = NULL WHERE id = $1 AND tenant_id = $3 AND status = $2`,
+		id, domain.ReservationStatusReserved, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to clear reservation expiry", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check clear expiry result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("reservation")
+	}
+
+	return nil
+}
+
+func (r *PostgresInventoryRepository) Release(ctx context.Context, id, reason string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "inventory.repository.release")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE reservations SET status = $1, release_reason = $2, released_at = $3
+		WHERE id = $4 AND tenant_id = $6 AND status = $5
+	`
+
+	result, err := r.db.Exec(ctx, "inventory.release", query,
+		domain.ReservationStatusReleased, reason, time.Now().UTC(), id, domain.ReservationStatusReserved, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to release reservation", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check release result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("reservation")
+	}
+
+	return nil
+}
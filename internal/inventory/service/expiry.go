@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+)
+
+// HoldExpiryJob returns the scheduled job that releases expired holds,
+// running every interval. A run keeps going while full batches expire.
+func HoldExpiryJob(s *InventoryService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "reservation_hold_expiry",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			for {
+				expired, err := s.ExpireHolds(ctx)
+				if err != nil || expired < expireBatchSize {
+					return err
+				}
+			}
+		},
+	}
+}
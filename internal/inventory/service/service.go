@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/inventory/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type InventoryRepository interface {
+	Reserve(ctx context.Context, reservation *domain.Reservation) error
+	GetActiveByBookingID(ctx context.Context, bookingID string) (*domain.Reservation, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*domain.Reservation, error)
+	ClearExpiry(ctx context.Context, id string) error
+	Release(ctx context.Context, id, reason string) error
+}
+
+// expireBatchSize bounds the holds released by one ExpireHolds call.
+const expireBatchSize = 100
+
+type InventoryService struct {
+	repo      InventoryRepository
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	metrics   *metrics.Metrics
+	tracer    trace.Tracer
+	holdTTL   time.Duration
+}
+
+// NewInventoryService creates the inventory service. New reservations are
+// held for holdTTL pending payment; zero holds them until released.
+func NewInventoryService(
+	repo InventoryRepository,
+	publisher messagebus.Publisher,
+	logger *logger.Logger,
+	metrics *metrics.Metrics,
+	tracer trace.Tracer,
+	holdTTL time.Duration,
+) *InventoryService {
+	return &InventoryService{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		metrics:   metrics,
+		tracer:    tracer,
+		holdTTL:   holdTTL,
+	}
+}
+
+// Reserve holds the requested slot for a booking and publishes
+// inventory.reserved, or inventory.reservation_failed when the slot is
+// taken. Reserving an already reserved booking returns the existing
+// reservation so redelivered events are harmless.
+func (s *InventoryService) Reserve(ctx context.Context, req *domain.ReserveRequest) (_ *domain.Reservation, err error) {
+	ctx, span := s.tracer.Start(ctx, "inventory.service.reserve")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	if existing, err := s.repo.GetActiveByBookingID(ctx, req.BookingID); err == nil {
+		return existing, nil
+	}
+
+	reservation := &domain.Reservation{
+		ResourceID: req.ResourceID,
+		BookingID:  req.BookingID,
+		StartTime:  req.StartTime.UTC(),
+		EndTime:    req.EndTime.UTC(),
+	}
+	if s.holdTTL > 0 {
+		expiresAt := time.Now().UTC().Add(s.holdTTL)
+		reservation.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Reserve(ctx, reservation); err != nil {
+		if appErr := errors.GetAppError(err); appErr.Type == errors.ErrorTypeConfict || appErr.Type == errors.ErrorTypeNotFound {
+			s.publishReservationFailed(ctx, span, req, appErr.Message)
+		}
+		return nil, err
+	}
+
+	// Publish event
+	event := events.InventoryReservedEvent{
+		BaseEvent: events.NewBaseEvent(events.InventoryReserved, "inventory-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.InventoryReservedData{
+			ResourceID:    reservation.ResourceID,
+			BookingID:     reservation.BookingID,
+			StartTime:     reservation.StartTime,
+			EndTime:       reservation.EndTime,
+			ReservedAt:    reservation.ReservedAt,
+			ReservationID: reservation.ID,
+		},
+	}
+
+	s.publish(ctx, events.InventoryReserved, reservation.BookingID, event)
+
+	s.logger.WithContext(ctx).With("booking_id", reservation.BookingID).With("reservation_id", reservation.ID).Info("inventory reserved successfully")
+
+	return reservation, nil
+}
+
+// Release frees the slot held for a booking and publishes
+// inventory.released. Releasing a booking without an active reservation is
+// a no-op.
+func (s *InventoryService) Release(ctx context.Context, bookingID, reason string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "inventory.service.release", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	reservation, err := s.repo.GetActiveByBookingID(ctx, bookingID)
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.repo.Release(ctx, reservation.ID, reason); err != nil {
+		return err
+	}
+	s.publishReleased(ctx, span, reservation, reason)
+
+	s.logger.WithContext(ctx).With("booking_id", bookingID).With("reason", reason).Info("inventory released successfully")
+
+	return nil
+}
+
+// ConfirmHold keeps the slot of a paid booking until the reservation is
+// released. A hold that already expired is not revived; the booking has
+// been failed by then.
+func (s *InventoryService) ConfirmHold(ctx context.Context, bookingID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "inventory.service.confirm_hold", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	reservation, err := s.repo.GetActiveByBookingID(ctx, bookingID)
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			s.logger.WithContext(ctx).With("booking_id", bookingID).Warn("payment arrived without an active hold")
+			return nil
+		}
+		return err
+	}
+
+	if reservation.ExpiresAt == nil {
+		return nil
+	}
+
+	return s.repo.ClearExpiry(ctx, reservation.ID)
+}
+
+// ExpireHolds releases the holds whose booking was not paid in time and
+// publishes inventory.released with reason hold_expired for each. A hold
+// released concurrently, e.g. by another replica, is skipped, so every
+// expiry is published once.
+func (s *InventoryService) ExpireHolds(ctx context.Context) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "inventory.service.expire_holds")
+	defer tracing.End(span, &err)
+
+	reservations, err := s.repo.ListExpired(ctx, time.Now().UTC(), expireBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, reservation := range reservations {
+		if err := s.repo.Release(ctx, reservation.ID, events.ReleaseReasonHoldExpired); err != nil {
+			if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+				continue
+			}
+			return expired, err
+		}
+		s.publishReleased(ctx, span, reservation, events.ReleaseReasonHoldExpired)
+		expired++
+
+		s.logger.WithContext(ctx).With("booking_id", reservation.BookingID).With("reservation_id", reservation.ID).Info("reservation hold expired")
+	}
+
+	return expired, nil
+}
+
+// GetReservation returns the active reservation held for a booking.
+func (s *InventoryService) GetReservation(ctx context.Context, bookingID string) (_ *domain.Reservation, err error) {
+	ctx, span := s.tracer.Start(ctx, "inventory.service.get_reservation", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetActiveByBookingID(ctx, bookingID)
+}
+
+func (s *InventoryService) publishReleased(ctx context.Context, span trace.Span, reservation *domain.Reservation, reason string) {
+	event := events.InventoryReleasedEvent{
+		BaseEvent: events.NewBaseEvent(events.InventoryReleased, "inventory-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.InventoryReleasedData{
+			ResourceID:    reservation.ResourceID,
+			BookingID:     reservation.BookingID,
+			ReservationID: reservation.ID,
+			ReleasedAt:    time.Now().UTC(),
+			Reason:        reason,
+		},
+	}
+
+	s.publish(ctx, events.InventoryReleased, reservation.BookingID, event)
+}
+
+func (s *InventoryService) publishReservationFailed(ctx context.Context, span trace.Span, req *domain.ReserveRequest, reason string) {
+	event := events.InventoryReservationFailedEvent{
+		BaseEvent: events.NewBaseEvent(events.InventoryReservationFailed, "inventory-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.InventoryReservationFailedData{
+			ResourceID: req.ResourceID,
+			BookingID:  req.BookingID,
+			Reason:     reason,
+			FailedAt:   time.Now().UTC(),
+		},
+	}
+
+	s.publish(ctx, events.InventoryReservationFailed, req.BookingID, event)
+}
+
+// publish queues an event without waiting for it to be written when the
+// bus supports it, so bursts of reservations and expiries are not slowed
+// down by the broker. Failures are logged, as they were when events were
+// published synchronously.
+func (s *InventoryService) publish(ctx context.Context, eventType events.EventType, key string, event any) {
+	log := s.logger.WithContext(ctx).With("event_type", string(eventType)).With("booking_id", key)
+
+	var err error
+	if publisher, ok := s.publisher.(messagebus.AsyncPublisher); ok {
+		err = publisher.PublishAsync(ctx, eventType, key, event, func(err error) {
+			if err != nil {
+				log.WithError(err).Error("failed to publish inventory event")
+			}
+		})
+	} else {
+		err = s.publisher.Publish(ctx, eventType, key, event)
+	}
+	if err != nil {
+		log.WithError(err).Error("failed to publish inventory event")
+	}
+}
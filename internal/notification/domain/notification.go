@@ -0,0 +1,188 @@
+package domain
+
+import "time"
+
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+	ChannelPush  Channel = "push"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+	// DeliveryStatusDelivered notifications were reported delivered by
+	// the provider, which only text messages are
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+)
+
+// Notification is a delivery log entry. There is at most one entry per
+// source event and channel, which is what keeps at-least-once event
+// delivery from sending the same message twice.
+type Notification struct {
+	ID        string         `json:"id" db:"id"`
+	TenantID  string         `json:"tenant_id" db:"tenant_id"`
+	EventID   string         `json:"event_id" db:"event_id"`
+	UserID    string         `json:"user_id" db:"user_id"`
+	Channel   Channel        `json:"channel" db:"channel"`
+	Template  string         `json:"template" db:"template"`
+	Recipient string         `json:"recipient" db:"recipient"`
+	Subject   string         `json:"subject" db:"subject"`
+	Body      string         `json:"body" db:"body"`
+	Status    DeliveryStatus `json:"status" db:"status"`
+	Error     string         `json:"error,omitempty" db:"error"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	SentAt    *time.Time     `json:"sent_at,omitempty" db:"sent_at"`
+	// ProviderMessageID identifies the message in the delivery reports
+	// of the provider
+	ProviderMessageID string     `json:"provider_message_id,omitempty" db:"provider_message_id"`
+	DeliveredAt       *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// Recipient is the notification service's local copy of a user's contact
+// details and notification preferences, maintained from user events.
+type Recipient struct {
+	UserID string `json:"user_id" db:"user_id"`
+	Email  string `json:"email" db:"email"`
+	Name   string `json:"name" db:"name"`
+	Phone  string `json:"phone,omitempty" db:"phone"`
+	// Locale is the language tag text messages are written in
+	Locale string `json:"locale,omitempty" db:"locale"`
+	// SMSOptedOut is set when the user's number replied STOP to a text
+	// message, which keeps text messages from being sent to it
+	SMSOptedOut bool `json:"sms_opted_out" db:"sms_opted_out"`
+	// Channels are the channels the user wants to be notified over,
+	// unless Categories lists other channels for the category
+	Channels   Preferences              `json:"channels"`
+	Categories map[Category]Preferences `json:"categories"`
+	QuietHours *QuietHours              `json:"quiet_hours,omitempty"`
+	// PreferencesSet is false for users who never set preferences, who
+	// are notified according to the defaults of their tenant
+	PreferencesSet bool `json:"preferences_set" db:"preferences_set"`
+	// Devices are the devices push notifications are sent to. They are
+	// only loaded when the user wants push notifications.
+	Devices []*Device `json:"devices,omitempty"`
+}
+
+type Preferences struct {
+	Email bool `json:"email" db:"email_enabled"`
+	SMS   bool `json:"sms" db:"sms_enabled"`
+	Push  bool `json:"push" db:"push_enabled"`
+}
+
+// Enabled reports whether channel is one of the preferred channels.
+func (p Preferences) Enabled(channel Channel) bool {
+	switch channel {
+	case ChannelEmail:
+		return p.Email
+	case ChannelSMS:
+		return p.SMS
+	case ChannelPush:
+		return p.Push
+	}
+	return false
+}
+
+// ChannelsFor returns the channels the recipient is notified over about
+// category.
+func (r *Recipient) ChannelsFor(category Category) Preferences {
+	if channels, ok := r.Categories[category]; ok {
+		return channels
+	}
+	return r.Channels
+}
+
+// ApplyDefaults gives recipients who never set preferences those of
+// their tenant.
+func (r *Recipient) ApplyDefaults(defaults *Defaults) {
+	if r.PreferencesSet {
+		return
+	}
+	r.Channels = defaults.Channels
+	r.Categories = defaults.Categories
+	r.QuietHours = defaults.QuietHours
+}
+
+// Category groups notifications for the channels users choose. Account
+// mail has no category and is always sent by email.
+type Category string
+
+const (
+	CategoryBooking  Category = "booking"
+	CategoryWaitlist Category = "waitlist"
+	CategoryPayment  Category = "payment"
+	CategoryExport   Category = "export"
+)
+
+// Defaults are the preferences of the users of a tenant who never set
+// their own.
+type Defaults struct {
+	Channels   Preferences              `json:"channels"`
+	Categories map[Category]Preferences `json:"categories"`
+	QuietHours *QuietHours              `json:"quiet_hours,omitempty"`
+}
+
+// BuiltinDefaults apply to tenants that never set defaults.
+var BuiltinDefaults = Defaults{Channels: Preferences{Email: true, Push: true}}
+
+// QuietHours are a daily window, from Start to End as HH:MM in TimeZone,
+// in which only email is sent. The window spans midnight when End is
+// before Start.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"time_zone"`
+}
+
+// Contains reports whether t falls in the quiet hours. Malformed quiet
+// hours contain no time, so they never hold notifications back.
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+
+	location, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return false
+	}
+	start, err := time.Parse("15:04", q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", q.End)
+	if err != nil {
+		return false
+	}
+
+	local := t.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// Platform is the push service a device is reached through.
+type Platform string
+
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformWeb     Platform = "web"
+)
+
+// Device is an app installation of a recipient registered for push
+// notifications.
+type Device struct {
+	Token    string   `json:"token" db:"token"`
+	UserID   string   `json:"user_id" db:"user_id"`
+	Platform Platform `json:"platform" db:"platform"`
+}
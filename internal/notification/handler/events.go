@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+	"github.com/dmehra2102/booking-system/internal/notification/sender"
+	"github.com/dmehra2102/booking-system/internal/notification/service"
+	"github.com/dmehra2102/booking-system/internal/notification/templates"
+	"github.com/dmehra2102/booking-system/pkg/documents"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler turns user, booking, waitlist, payment and export events
+// consumed from Kafka into notifications. Account mail is always sent by
+// email; other notifications over the channels users chose for their
+// category.
+type EventHandler struct {
+	service *service.NotificationService
+	logger  *logger.Logger
+}
+
+func NewEventHandler(service *service.NotificationService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *EventHandler) HandleUserCreated(ctx context.Context, event events.UserCreatedEvent) error {
+	if err := h.service.RegisterRecipient(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, event.Data.Locale); err != nil {
+		return err
+	}
+
+	return h.service.SendEmail(ctx, event.ID, event.Data.UserID, templates.Welcome, map[string]any{})
+}
+
+// HandleVerificationRequested registers the address being verified before
+// mailing it, since the request may overtake user.created or user.updated.
+func (h *EventHandler) HandleVerificationRequested(ctx context.Context, event events.UserVerificationRequestedEvent) error {
+	if err := h.service.RegisterRecipient(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, ""); err != nil {
+		return err
+	}
+
+	return h.service.SendEmail(ctx, event.ID, event.Data.UserID, templates.VerifyEmail, map[string]any{
+		"VerificationURL": event.Data.VerificationURL,
+		"ExpiresAt":       event.Data.ExpiresAt,
+	})
+}
+
+func (h *EventHandler) HandlePasswordResetRequested(ctx context.Context, event events.UserPasswordResetRequestedEvent) error {
+	if err := h.service.RegisterRecipient(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, ""); err != nil {
+		return err
+	}
+
+	return h.service.SendEmail(ctx, event.ID, event.Data.UserID, templates.PasswordReset, map[string]any{
+		"ResetURL":  event.Data.ResetURL,
+		"ExpiresAt": event.Data.ExpiresAt,
+	})
+}
+
+func (h *EventHandler) HandleNewDeviceLogin(ctx context.Context, event events.UserNewDeviceLoginEvent) error {
+	if err := h.service.RegisterRecipient(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, ""); err != nil {
+		return err
+	}
+
+	return h.service.SendEmail(ctx, event.ID, event.Data.UserID, templates.NewDeviceLogin, map[string]any{
+		"IPAddress":  event.Data.IPAddress,
+		"UserAgent":  event.Data.UserAgent,
+		"LoggedInAt": event.Data.LoggedInAt,
+	})
+}
+
+func (h *EventHandler) HandleUserUpdated(ctx context.Context, event events.UserUpdatedEvent) error {
+	return h.service.RegisterRecipient(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, "")
+}
+
+// HandleUserDeleted erases the recipient of a deleted user with the
+// notifications sent to them.
+func (h *EventHandler) HandleUserDeleted(ctx context.Context, event events.UserDeletedEvent) error {
+	return h.service.EraseUserData(ctx, event.Data.UserID)
+}
+
+func (h *EventHandler) HandleDataExportRequested(ctx context.Context, event events.UserDataExportRequestedEvent) error {
+	return h.service.ExportUserData(ctx, event.Data.ExportID, event.Data.UserID)
+}
+
+func (h *EventHandler) HandleDataExportReady(ctx context.Context, event events.UserDataExportReadyEvent) error {
+	if err := h.service.RegisterRecipient(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, ""); err != nil {
+		return err
+	}
+
+	return h.service.SendEmail(ctx, event.ID, event.Data.UserID, templates.DataExportReady, map[string]any{
+		"DownloadURL": event.Data.DownloadURL,
+		"ExpiresAt":   event.Data.ExpiresAt,
+	})
+}
+
+func (h *EventHandler) HandleNotificationPreferencesUpdated(ctx context.Context, event events.UserNotificationPreferencesUpdatedEvent) error {
+	return h.service.SetPreferences(ctx, &domain.Recipient{
+		UserID: event.Data.UserID,
+		Phone:  event.Data.Phone,
+		Locale: event.Data.Locale,
+		Channels: domain.Preferences{
+			Email: event.Data.Email,
+			SMS:   event.Data.SMS,
+			Push:  event.Data.Push,
+		},
+		Categories: categories(event.Data.Categories),
+		QuietHours: quietHours(event.Data.QuietHours),
+	})
+}
+
+func (h *EventHandler) HandleNotificationDefaultsUpdated(ctx context.Context, event events.UserNotificationDefaultsUpdatedEvent) error {
+	return h.service.SetDefaults(ctx, &domain.Defaults{
+		Channels: domain.Preferences{
+			Email: event.Data.Email,
+			SMS:   event.Data.SMS,
+			Push:  event.Data.Push,
+		},
+		Categories: categories(event.Data.Categories),
+		QuietHours: quietHours(event.Data.QuietHours),
+	})
+}
+
+func (h *EventHandler) HandleDeviceRegistered(ctx context.Context, event events.UserDeviceRegisteredEvent) error {
+	return h.service.RegisterDevice(ctx, event.Data.UserID, event.Data.Token, domain.Platform(event.Data.Platform))
+}
+
+func (h *EventHandler) HandleDeviceRemoved(ctx context.Context, event events.UserDeviceRemovedEvent) error {
+	return h.service.RemoveDevice(ctx, event.Data.Token)
+}
+
+// HandleBookingConfirmed mails the PDF receipt and the iCalendar event of
+// the booking along with the confirmation.
+func (h *EventHandler) HandleBookingConfirmed(ctx context.Context, event events.BookingConfirmedEvent) error {
+	return h.service.Notify(ctx, event.ID, event.Data.UserID, domain.CategoryBooking, templates.BookingConfirmed, map[string]any{
+		"BookingID": event.Data.BookingID,
+		"StartTime": event.Data.StartTime,
+		"EndTime":   event.Data.EndTime,
+		"Amount":    event.Data.Amount.Decimal(),
+		"Currency":  event.Data.Amount.Currency,
+	}, func(ctx context.Context, recipient *domain.Recipient) []sender.Attachment {
+		confirmation := &documents.Confirmation{
+			BookingID:    event.Data.BookingID,
+			ResourceID:   event.Data.ResourceID,
+			ResourceName: event.Data.ResourceName,
+			UserName:     recipient.Name,
+			UserEmail:    recipient.Email,
+			StartTime:    event.Data.StartTime,
+			EndTime:      event.Data.EndTime,
+			Amount:       event.Data.Amount,
+			ConfirmedAt:  event.Data.ConfirmedAt,
+		}
+		return []sender.Attachment{
+			{Filename: documents.Filename(event.Data.BookingID, "pdf"), ContentType: documents.ContentTypePDF, Data: documents.PDF(ctx, confirmation)},
+			{Filename: documents.Filename(event.Data.BookingID, "ics"), ContentType: documents.ContentTypeICS, Data: documents.ICS(confirmation)},
+		}
+	})
+}
+
+func (h *EventHandler) HandleBookingReminder(ctx context.Context, event events.BookingReminderEvent) error {
+	return h.service.Notify(ctx, event.ID, event.Data.UserID, domain.CategoryBooking, templates.BookingReminder, map[string]any{
+		"BookingID": event.Data.BookingID,
+		"StartTime": event.Data.StartTime,
+		"EndTime":   event.Data.EndTime,
+	})
+}
+
+func (h *EventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	return h.service.Notify(ctx, event.ID, event.Data.UserID, domain.CategoryBooking, templates.BookingCancelled, map[string]any{
+		"BookingID": event.Data.BookingID,
+		"Reason":    event.Data.Reason,
+	})
+}
+
+func (h *EventHandler) HandleWaitlistOffered(ctx context.Context, event events.WaitlistOfferedEvent) error {
+	return h.service.Notify(ctx, event.ID, event.Data.UserID, domain.CategoryWaitlist, templates.WaitlistOffered, map[string]any{
+		"ResourceID": event.Data.ResourceID,
+		"StartTime":  event.Data.StartTime,
+		"EndTime":    event.Data.EndTime,
+		"ExpiresAt":  event.Data.ExpiresAt,
+	})
+}
+
+func (h *EventHandler) HandlePaymentProcessed(ctx context.Context, event events.PaymentProcessedEvent) error {
+	return h.service.Notify(ctx, event.ID, event.Data.UserID, domain.CategoryPayment, templates.PaymentProcessed, map[string]any{
+		"BookingID": event.Data.BookingID,
+		"PaymentID": event.Data.PaymentID,
+		"Amount":    event.Data.Amount.Decimal(),
+		"Currency":  event.Data.Amount.Currency,
+	})
+}
+
+func (h *EventHandler) HandlePaymentFailed(ctx context.Context, event events.PaymentFailedEvent) error {
+	return h.service.Notify(ctx, event.ID, event.Data.UserID, domain.CategoryPayment, templates.PaymentFailed, map[string]any{
+		"BookingID": event.Data.BookingID,
+		"Amount":    event.Data.Amount.Decimal(),
+		"Currency":  event.Data.Amount.Currency,
+		"Reason":    event.Data.Reason,
+	})
+}
+
+func (h *EventHandler) HandleExportCompleted(ctx context.Context, event events.ExportCompletedEvent) error {
+	return h.service.Notify(ctx, event.ID, event.Data.UserID, domain.CategoryExport, templates.ExportCompleted, map[string]any{
+		"Dataset":     event.Data.Dataset,
+		"Format":      event.Data.Format,
+		"Rows":        event.Data.Rows,
+		"DownloadURL": event.Data.DownloadURL,
+	})
+}
+
+func categories(data map[string]events.NotificationChannels) map[domain.Category]domain.Preferences {
+	categories := make(map[domain.Category]domain.Preferences, len(data))
+	for category, channels := range data {
+		categories[domain.Category(category)] = domain.Preferences{Email: channels.Email, SMS: channels.SMS, Push: channels.Push}
+	}
+	return categories
+}
+
+func quietHours(data *events.QuietHours) *domain.QuietHours {
+	if data == nil {
+		return nil
+	}
+	return &domain.QuietHours{Start: data.Start, End: data.End, TimeZone: data.TimeZone}
+}
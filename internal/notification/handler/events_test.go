@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+	"github.com/dmehra2102/booking-system/internal/notification/sender"
+	"github.com/dmehra2102/booking-system/internal/notification/service"
+	"github.com/dmehra2102/booking-system/internal/notification/templates"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// notificationRepository keeps recipients and claimed notifications in
+// memory; its other methods are not used.
+type notificationRepository struct {
+	service.NotificationRepository
+	recipients map[string]*domain.Recipient
+	claimed    map[string]bool
+}
+
+func (r *notificationRepository) UpsertRecipient(_ context.Context, recipient *domain.Recipient) error {
+	r.recipients[recipient.UserID] = recipient
+	return nil
+}
+
+func (r *notificationRepository) GetRecipient(_ context.Context, userID string) (*domain.Recipient, error) {
+	recipient := *r.recipients[userID]
+	return &recipient, nil
+}
+
+func (r *notificationRepository) Claim(_ context.Context, n *domain.Notification) (bool, error) {
+	key := n.EventID + "/" + string(n.Channel)
+	if r.claimed[key] {
+		return false, nil
+	}
+	r.claimed[key] = true
+	n.ID = key
+	return true, nil
+}
+
+func (r *notificationRepository) MarkSent(context.Context, string, string) error { return nil }
+
+type emailSender struct {
+	sent []string
+}
+
+func (s *emailSender) Send(_ context.Context, to, _, _ string, _ ...sender.Attachment) error {
+	s.sent = append(s.sent, to)
+	return nil
+}
+
+type publisher struct{}
+
+func (publisher) Publish(context.Context, events.EventType, string, any) error { return nil }
+
+func TestHandleUserCreatedOnce(t *testing.T) {
+	first := events.UserCreatedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserCreated, "user-service", "", "default"),
+		Data:      events.UserCreatedData{UserID: "u-1", Email: "ada@example.com", Name: "Ada"},
+	}
+	second := first
+	second.BaseEvent = events.NewBaseEvent(events.UserCreated, "user-service", "", "default")
+
+	tests := []struct {
+		name           string
+		events         []events.UserCreatedEvent
+		wantSent       int
+		wantDuplicates float64
+	}{
+		{
+			name:     "delivered once",
+			events:   []events.UserCreatedEvent{first},
+			wantSent: 1,
+		},
+		{
+			name:           "replayed",
+			events:         []events.UserCreatedEvent{first, first},
+			wantSent:       1,
+			wantDuplicates: 1,
+		},
+		{
+			name:           "replayed twice",
+			events:         []events.UserCreatedEvent{first, first, first},
+			wantSent:       1,
+			wantDuplicates: 2,
+		},
+		{
+			name:     "distinct events",
+			events:   []events.UserCreatedEvent{first, second},
+			wantSent: 2,
+		},
+	}
+
+	renderer, err := templates.NewRenderer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &notificationRepository{recipients: make(map[string]*domain.Recipient), claimed: make(map[string]bool)}
+			email := &emailSender{}
+			m := metrics.New("notification-service")
+			log := logger.New("test", "error")
+			svc := service.NewNotificationService(repo, renderer, []sender.Channel{sender.NewEmailChannel(email)}, publisher{}, log, m, noop.NewTracerProvider().Tracer("test"))
+			h := NewEventHandler(svc, log)
+
+			ctx := tenancy.WithID(context.Background(), "default")
+			for _, event := range tt.events {
+				if err := h.HandleUserCreated(ctx, event); err != nil {
+					t.Fatalf("HandleUserCreated() error = %v", err)
+				}
+			}
+
+			if len(email.sent) != tt.wantSent {
+				t.Errorf("sent %d emails, want %d", len(email.sent), tt.wantSent)
+			}
+			duplicates := testutil.ToFloat64(m.NotificationsTotal.WithLabelValues(string(domain.ChannelEmail), "duplicate", "default"))
+			if duplicates != tt.wantDuplicates {
+				t.Errorf("suppressed duplicates = %v, want %v", duplicates, tt.wantDuplicates)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+	"github.com/dmehra2102/booking-system/internal/notification/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Paths Twilio calls back on, below the public base URL of the service.
+const (
+	TwilioStatusPath  = "/api/v1/webhooks/twilio/status"
+	TwilioInboundPath = "/api/v1/webhooks/twilio/inbound"
+)
+
+// TwilioWebhookHandler receives the delivery reports of text messages and
+// the replies to them from Twilio. Requests are signed with the account's
+// auth token over the public URL they were sent to, so the handler needs
+// the base URL Twilio is configured with rather than the one it is served
+// on behind the gateway.
+type TwilioWebhookHandler struct {
+	service   *service.NotificationService
+	authToken string
+	baseURL   string
+	logger    *logger.Logger
+}
+
+func NewTwilioWebhookHandler(service *service.NotificationService, authToken, baseURL string, logger *logger.Logger) *TwilioWebhookHandler {
+	return &TwilioWebhookHandler{
+		service:   service,
+		authToken: authToken,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		logger:    logger,
+	}
+}
+
+// HandleStatus records the final status of a message. Twilio also reports
+// the intermediate queued, sending and sent statuses, which are ignored.
+func (h *TwilioWebhookHandler) HandleStatus(c *gin.Context) {
+	if !h.verify(c) {
+		return
+	}
+
+	var status domain.DeliveryStatus
+	reason := ""
+	switch c.PostForm("MessageStatus") {
+	case "delivered":
+		status = domain.DeliveryStatusDelivered
+	case "undelivered", "failed":
+		status = domain.DeliveryStatusFailed
+		reason = "undelivered, twilio error " + c.PostForm("ErrorCode")
+	default:
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	if err := h.service.HandleDeliveryReport(c.Request.Context(), c.PostForm("MessageSid"), status, reason); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// HandleInbound handles replies to text messages and answers with an
+// empty TwiML response, as the opt-out confirmation is sent by Twilio.
+func (h *TwilioWebhookHandler) HandleInbound(c *gin.Context) {
+	if !h.verify(c) {
+		return
+	}
+
+	if err := h.service.HandleInboundSMS(c.Request.Context(), c.PostForm("From"), c.PostForm("Body")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/xml", []byte(`<?xml version="1.0" encoding="UTF-8"?><Response/>`))
+}
+
+// verify checks the X-Twilio-Signature header of a request, aborting it
+// and returning false when the signature does not match.
+func (h *TwilioWebhookHandler) verify(c *gin.Context) bool {
+	if err := c.Request.ParseForm(); err != nil {
+		c.Error(errors.NewValidationError("invalid form body", err))
+		c.Abort()
+		return false
+	}
+
+	expected := twilioSignature(h.authToken, h.baseURL+c.Request.URL.RequestURI(), c.Request.PostForm)
+	if !hmac.Equal([]byte(c.GetHeader("X-Twilio-Signature")), []byte(expected)) {
+		h.logger.WithContext(c.Request.Context()).With("path", c.Request.URL.Path).Warn("rejected Twilio webhook with invalid signature")
+		c.Error(errors.NewForbiddenError("invalid signature"))
+		c.Abort()
+		return false
+	}
+
+	return true
+}
+
+// twilioSignature returns the base64 HMAC-SHA1, keyed with the auth token,
+// of the URL followed by the sorted form parameters, each name directly
+// followed by its value.
+func twilioSignature(authToken, endpoint string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(endpoint))
+	for _, key := range keys {
+		for _, value := range params[key] {
+			mac.Write([]byte(key))
+			mac.Write([]byte(value))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
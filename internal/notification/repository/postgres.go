@@ -0,0 +1,501 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+	"github.com/dmehra2102/booking-system/pkg/crypto"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresNotificationRepository stores notifications and their
+// recipients. The email addresses and phone numbers of recipients are
+// encrypted with fields; phone numbers are looked up by their hash under
+// index.
+type PostgresNotificationRepository struct {
+	db     *database.PostgresDB
+	fields *crypto.Envelope
+	index  *crypto.BlindIndex
+	tracer trace.Tracer
+}
+
+func NewPostgresNotificationRepository(db *database.PostgresDB, fields *crypto.Envelope, index *crypto.BlindIndex, tracer trace.Tracer) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{
+		db:     db,
+		fields: fields,
+		index:  index,
+		tracer: tracer,
+	}
+}
+
+// Claim records a pending delivery for the notification's event and
+// channel. It returns false when the event was already delivered (or is
+// being delivered), so the caller must not send it again. Deliveries that
+// previously failed can be claimed again for a retry.
+func (r *PostgresNotificationRepository) Claim(ctx context.Context, n *domain.Notification) (_ bool, err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.claim")
+	defer tracing.End(span, &err)
+
+	n.ID = uuid.New().String()
+	n.Status = domain.DeliveryStatusPending
+	n.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO notifications (
+			id, tenant_id, event_id, user_id, channel, template, recipient, subject, body, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (event_id, channel) DO UPDATE
+			SET status = EXCLUDED.status, error = ''
+			WHERE notifications.status = 'failed'
+		RETURNING id
+	`
+
+	err = r.db.QueryRow(ctx, "notification.claim", query,
+		n.ID, tenancy.ID(ctx), n.EventID, n.UserID, n.Channel, n.Template, n.Recipient,
+		n.Subject, n.Body, n.Status, n.CreatedAt,
+	).Scan(&n.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.NewInternalError("failed to claim notification", err)
+	}
+
+	return true, nil
+}
+
+// MarkSent records that notification id was handed to the provider, which
+// reports its delivery under providerMessageID when not empty.
+func (r *PostgresNotificationRepository) MarkSent(ctx context.Context, id, providerMessageID string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.mark_sent")
+	defer tracing.End(span, &err)
+
+	query := `UPDATE notifications SET status = $1, sent_at = $2, provider_message_id = NULLIF($3, '') WHERE id = $4 AND tenant_id = $5`
+
+	if _, err := r.db.Exec(ctx, "notification.mark_sent", query, domain.DeliveryStatusSent, time.Now().UTC(), providerMessageID, id, tenancy.ID(ctx)); err != nil {
+		return errors.NewInternalError("failed to mark notification sent", err)
+	}
+
+	return nil
+}
+
+// UpdateDeliveryStatus records the delivery report of the provider for a
+// sent notification and returns it. Reports for unknown messages, and
+// reports arriving after the final one, return a not found error.
+//
+// Providers report deliveries without a tenant, so the notification is
+// looked up in every tenant.
+func (r *PostgresNotificationRepository) UpdateDeliveryStatus(ctx context.Context, providerMessageID string, status domain.DeliveryStatus, reason string) (_ *domain.Notification, err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.update_delivery_status")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE notifications
+		SET status = $1, error = $2, delivered_at = CASE WHEN $1 = 'delivered' THEN $3::timestamptz END
+		WHERE provider_message_id = $4 AND status = 'sent'
+		RETURNING id, tenant_id, event_id, user_id, channel, template, recipient, subject, body, status, error,
+			created_at, sent_at, provider_message_id, delivered_at
+	`
+
+	n := &domain.Notification{}
+	err = r.db.QueryRow(ctx, "notification.update_delivery_status", query, status, reason, time.Now().UTC(), providerMessageID).Scan(
+		&n.ID, &n.TenantID, &n.EventID, &n.UserID, &n.Channel, &n.Template, &n.Recipient, &n.Subject, &n.Body, &n.Status, &n.Error,
+		&n.CreatedAt, &n.SentAt, &n.ProviderMessageID, &n.DeliveredAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("notification")
+		}
+		return nil, errors.NewInternalError("failed to update delivery status", err)
+	}
+
+	return n, nil
+}
+
+func (r *PostgresNotificationRepository) MarkFailed(ctx context.Context, id, reason string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.mark_failed")
+	defer tracing.End(span, &err)
+
+	query := `UPDATE notifications SET status = $1, error = $2 WHERE id = $3 AND tenant_id = $4`
+
+	if _, err := r.db.Exec(ctx, "notification.mark_failed", query, domain.DeliveryStatusFailed, reason, id, tenancy.ID(ctx)); err != nil {
+		return errors.NewInternalError("failed to mark notification failed", err)
+	}
+
+	return nil
+}
+
+// UpsertRecipient records the contact details of a recipient, and its
+// locale when not empty and the recipient did not choose one in their
+// preferences.
+func (r *PostgresNotificationRepository) UpsertRecipient(ctx context.Context, recipient *domain.Recipient) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.upsert_recipient")
+	defer tracing.End(span, &err)
+
+	email, err := r.fields.Encrypt(ctx, recipient.Email)
+	if err != nil {
+		return errors.NewInternalError("failed to encrypt email address", err)
+	}
+
+	query := `
+		INSERT INTO notification_recipients (user_id, tenant_id, email, name, locale)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+			SET email = EXCLUDED.email, name = EXCLUDED.name,
+				locale = CASE
+					WHEN EXCLUDED.locale <> '' AND NOT notification_recipients.preferences_set THEN EXCLUDED.locale
+					ELSE notification_recipients.locale
+				END
+			WHERE notification_recipients.tenant_id = EXCLUDED.tenant_id
+	`
+
+	if _, err := r.db.Exec(ctx, "notification.upsert_recipient", query,
+		recipient.UserID, tenancy.ID(ctx), email, recipient.Name, recipient.Locale,
+	); err != nil {
+		return errors.NewInternalError("failed to upsert recipient", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresNotificationRepository) GetRecipient(ctx context.Context, userID string) (_ *domain.Recipient, err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.get_recipient", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT user_id, email, name, phone, locale, sms_opted_out, email_enabled, sms_enabled, push_enabled,
+			categories, quiet_hours, preferences_set
+		FROM notification_recipients
+		WHERE user_id = $1 AND tenant_id = $2
+	`
+
+	recipient := &domain.Recipient{}
+	var categories, quietHours []byte
+	err = r.db.QueryRow(ctx, "notification.get_recipient", query, userID, tenancy.ID(ctx)).Scan(
+		&recipient.UserID, &recipient.Email, &recipient.Name, &recipient.Phone, &recipient.Locale, &recipient.SMSOptedOut,
+		&recipient.Channels.Email, &recipient.Channels.SMS, &recipient.Channels.Push,
+		&categories, &quietHours, &recipient.PreferencesSet,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("recipient")
+		}
+		return nil, errors.NewInternalError("failed to get recipient", err)
+	}
+
+	if err := decodeSettings(categories, quietHours, &recipient.Categories, &recipient.QuietHours); err != nil {
+		return nil, errors.NewInternalError("failed to decode recipient preferences", err)
+	}
+	if recipient.Email, err = r.fields.Decrypt(ctx, recipient.Email); err != nil {
+		return nil, errors.NewInternalError("failed to decrypt email address", err)
+	}
+	if recipient.Phone, err = r.fields.Decrypt(ctx, recipient.Phone); err != nil {
+		return nil, errors.NewInternalError("failed to decrypt phone number", err)
+	}
+
+	return recipient, nil
+}
+
+// UpsertPreferences records the channels, quiet hours, phone number and
+// locale of a recipient; an empty locale keeps the one the user
+// registered in. The recipient is created without contact details when
+// the preferences overtake the user's creation. A new phone number is
+// opted out when it opted out for another recipient.
+//
+// Numbers stored before they were encrypted have no hash and are matched
+// as they are, until the recipient's preferences are saved again.
+func (r *PostgresNotificationRepository) UpsertPreferences(ctx context.Context, recipient *domain.Recipient) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.upsert_preferences", trace.WithAttributes(tracing.UserID.String(recipient.UserID)))
+	defer tracing.End(span, &err)
+
+	categories, quietHours, err := encodeSettings(recipient.Categories, recipient.QuietHours)
+	if err != nil {
+		return errors.NewInternalError("failed to encode notification preferences", err)
+	}
+	phone, err := r.fields.Encrypt(ctx, recipient.Phone)
+	if err != nil {
+		return errors.NewInternalError("failed to encrypt phone number", err)
+	}
+
+	query := `
+		INSERT INTO notification_recipients (
+			user_id, tenant_id, email, name, phone, phone_hash, locale, sms_opted_out,
+			email_enabled, sms_enabled, push_enabled, categories, quiet_hours, preferences_set
+		)
+		SELECT $1, $2, '', '', $3, $4, $5,
+			EXISTS (
+				SELECT 1 FROM notification_recipients
+				WHERE ((phone_hash = $4 AND phone_hash <> '') OR (phone = $6 AND phone <> '')) AND sms_opted_out
+			),
+			$7, $8, $9, $10, $11, TRUE
+		ON CONFLICT (user_id) DO UPDATE
+			SET phone = EXCLUDED.phone, phone_hash = EXCLUDED.phone_hash,
+				locale = COALESCE(NULLIF(EXCLUDED.locale, ''), notification_recipients.locale),
+				email_enabled = EXCLUDED.email_enabled,
+				sms_enabled = EXCLUDED.sms_enabled, push_enabled = EXCLUDED.push_enabled,
+				categories = EXCLUDED.categories, quiet_hours = EXCLUDED.quiet_hours, preferences_set = TRUE,
+				sms_opted_out = CASE
+					WHEN notification_recipients.phone_hash = EXCLUDED.phone_hash AND EXCLUDED.phone_hash <> '' THEN notification_recipients.sms_opted_out
+					WHEN notification_recipients.phone = $6 AND $6 <> '' THEN notification_recipients.sms_opted_out
+					ELSE EXCLUDED.sms_opted_out
+				END
+			WHERE notification_recipients.tenant_id = EXCLUDED.tenant_id
+	`
+
+	if _, err := r.db.Exec(ctx, "notification.upsert_preferences", query,
+		recipient.UserID, tenancy.ID(ctx), phone, r.index.Hash(recipient.Phone), recipient.Locale, recipient.Phone,
+		recipient.Channels.Email, recipient.Channels.SMS, recipient.Channels.Push, categories, quietHours,
+	); err != nil {
+		return errors.NewInternalError("failed to upsert notification preferences", err)
+	}
+
+	return nil
+}
+
+// GetDefaults returns the notification defaults of the tenant, or the
+// built-in defaults when it never set any.
+func (r *PostgresNotificationRepository) GetDefaults(ctx context.Context) (_ *domain.Defaults, err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.get_defaults")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT email_enabled, sms_enabled, push_enabled, categories, quiet_hours
+		FROM notification_tenant_defaults
+		WHERE tenant_id = $1
+	`
+
+	defaults := &domain.Defaults{}
+	var categories, quietHours []byte
+	err = r.db.QueryRow(ctx, "notification.get_defaults", query, tenancy.ID(ctx)).Scan(
+		&defaults.Channels.Email, &defaults.Channels.SMS, &defaults.Channels.Push, &categories, &quietHours,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			builtin := domain.BuiltinDefaults
+			return &builtin, nil
+		}
+		return nil, errors.NewInternalError("failed to get notification defaults", err)
+	}
+
+	if err := decodeSettings(categories, quietHours, &defaults.Categories, &defaults.QuietHours); err != nil {
+		return nil, errors.NewInternalError("failed to decode notification defaults", err)
+	}
+
+	return defaults, nil
+}
+
+func (r *PostgresNotificationRepository) UpsertDefaults(ctx context.Context, defaults *domain.Defaults) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.upsert_defaults")
+	defer tracing.End(span, &err)
+
+	categories, quietHours, err := encodeSettings(defaults.Categories, defaults.QuietHours)
+	if err != nil {
+		return errors.NewInternalError("failed to encode notification defaults", err)
+	}
+
+	query := `
+		INSERT INTO notification_tenant_defaults (
+			tenant_id, email_enabled, sms_enabled, push_enabled, categories, quiet_hours, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE
+			SET email_enabled = EXCLUDED.email_enabled, sms_enabled = EXCLUDED.sms_enabled,
+				push_enabled = EXCLUDED.push_enabled, categories = EXCLUDED.categories,
+				quiet_hours = EXCLUDED.quiet_hours, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.Exec(ctx, "notification.upsert_defaults", query,
+		tenancy.ID(ctx), defaults.Channels.Email, defaults.Channels.SMS, defaults.Channels.Push,
+		categories, quietHours, time.Now().UTC(),
+	); err != nil {
+		return errors.NewInternalError("failed to upsert notification defaults", err)
+	}
+
+	return nil
+}
+
+// SetSMSOptOut records whether phone opted out of text messages. Opting
+// out applies to the number, so it covers the recipients of every tenant
+// that use it. Phone is matched by its hash, and as it is for numbers
+// stored before they were encrypted.
+func (r *PostgresNotificationRepository) SetSMSOptOut(ctx context.Context, phone string, optedOut bool) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.set_sms_opt_out")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE notification_recipients SET sms_opted_out = $1
+		WHERE (phone_hash = $2 AND phone_hash <> '') OR (phone = $3 AND phone <> '')
+	`
+
+	if _, err := r.db.Exec(ctx, "notification.set_sms_opt_out", query, optedOut, r.index.Hash(phone), phone); err != nil {
+		return errors.NewInternalError("failed to record text message opt-out", err)
+	}
+
+	return nil
+}
+
+// UpsertDevice records a device, moving its token to device.UserID when
+// another user registered it before.
+func (r *PostgresNotificationRepository) UpsertDevice(ctx context.Context, device *domain.Device) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.upsert_device", trace.WithAttributes(tracing.UserID.String(device.UserID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		INSERT INTO notification_devices (token, tenant_id, user_id, platform, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (token) DO UPDATE
+			SET tenant_id = EXCLUDED.tenant_id, user_id = EXCLUDED.user_id, platform = EXCLUDED.platform
+	`
+
+	if _, err := r.db.Exec(ctx, "notification.upsert_device", query, device.Token, tenancy.ID(ctx), device.UserID, device.Platform, time.Now().UTC()); err != nil {
+		return errors.NewInternalError("failed to upsert device", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresNotificationRepository) ListDevices(ctx context.Context, userID string) (_ []*domain.Device, err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.list_devices", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `SELECT token, user_id, platform FROM notification_devices WHERE user_id = $1 AND tenant_id = $2`
+
+	rows, err := r.db.Query(ctx, "notification.list_devices", query, userID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list devices", err)
+	}
+	devices, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Device, error) {
+		device := &domain.Device{}
+		err := row.Scan(&device.Token, &device.UserID, &device.Platform)
+		return device, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan devices", err)
+	}
+
+	return devices, nil
+}
+
+func (r *PostgresNotificationRepository) DeleteDevice(ctx context.Context, token string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.delete_device")
+	defer tracing.End(span, &err)
+
+	if _, err := r.db.Exec(ctx, "notification.delete_device", `DELETE FROM notification_devices WHERE token = $1 AND tenant_id = $2`, token, tenancy.ID(ctx)); err != nil {
+		return errors.NewInternalError("failed to delete device", err)
+	}
+
+	return nil
+}
+
+// DeleteRecipient removes a recipient with their devices and the
+// notifications sent to them, and returns the number of removed records.
+func (r *PostgresNotificationRepository) DeleteRecipient(ctx context.Context, userID string) (deleted int, err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.delete_recipient", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	queries := []struct{ name, query string }{
+		{"notification.delete_recipient", `DELETE FROM notification_recipients WHERE user_id = $1 AND tenant_id = $2`},
+		{"notification.delete_recipient_devices", `DELETE FROM notification_devices WHERE user_id = $1 AND tenant_id = $2`},
+		{"notification.delete_recipient_notifications", `DELETE FROM notifications WHERE user_id = $1 AND tenant_id = $2`},
+	}
+
+	err = r.db.WithTx(ctx, func(ctx context.Context) error {
+		deleted = 0
+		for _, q := range queries {
+			result, err := r.db.Exec(ctx, q.name, q.query, userID, tenancy.ID(ctx))
+			if err != nil {
+				return errors.NewInternalError("failed to delete recipient", err)
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return errors.NewInternalError("failed to check delete result", err)
+			}
+			deleted += int(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// ListNotifications returns the notifications sent to userID, oldest
+// first.
+func (r *PostgresNotificationRepository) ListNotifications(ctx context.Context, userID string) (_ []*domain.Notification, err error) {
+	ctx, span := r.tracer.Start(ctx, "notification.repository.list_notifications", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, tenant_id, event_id, user_id, channel, template, recipient, subject, body, status, error,
+			created_at, sent_at, COALESCE(provider_message_id, ''), delivered_at
+		FROM notifications
+		WHERE user_id = $1 AND tenant_id = $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, "notification.list_notifications", query, userID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list notifications", err)
+	}
+	notifications, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Notification, error) {
+		n := &domain.Notification{}
+		err := row.Scan(
+			&n.ID, &n.TenantID, &n.EventID, &n.UserID, &n.Channel, &n.Template, &n.Recipient, &n.Subject, &n.Body, &n.Status, &n.Error,
+			&n.CreatedAt, &n.SentAt, &n.ProviderMessageID, &n.DeliveredAt,
+		)
+		return n, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan notifications", err)
+	}
+
+	return notifications, nil
+}
+
+// Categories and quiet hours are stored as JSONB; quiet_hours is NULL
+// when there are none.
+func encodeSettings(categories map[domain.Category]domain.Preferences, quietHours *domain.QuietHours) ([]byte, []byte, error) {
+	if categories == nil {
+		categories = make(map[domain.Category]domain.Preferences)
+	}
+	encodedCategories, err := json.Marshal(categories)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var encodedQuietHours []byte
+	if quietHours != nil {
+		if encodedQuietHours, err = json.Marshal(quietHours); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return encodedCategories, encodedQuietHours, nil
+}
+
+func decodeSettings(categories, quietHours []byte, decodedCategories *map[domain.Category]domain.Preferences, decodedQuietHours **domain.QuietHours) error {
+	*decodedCategories = make(map[domain.Category]domain.Preferences)
+	if len(categories) > 0 {
+		if err := json.Unmarshal(categories, decodedCategories); err != nil {
+			return fmt.Errorf("failed to decode categories: %w", err)
+		}
+	}
+
+	if len(quietHours) > 0 {
+		if err := json.Unmarshal(quietHours, decodedQuietHours); err != nil {
+			return fmt.Errorf("failed to decode quiet hours: %w", err)
+		}
+	}
+
+	return nil
+}
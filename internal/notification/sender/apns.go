@@ -0,0 +1,160 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+	// apnsTokenLifetime renews provider tokens before APNs stops accepting
+	// them after an hour. APNs also refuses tokens renewed more often than
+	// every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsConfig identifies the token signing key and the app notifications
+// are pushed to.
+type APNsConfig struct {
+	// Key is the contents of the .p8 key file
+	Key        []byte
+	KeyID      string
+	TeamID     string
+	Topic      string
+	Production bool
+}
+
+// APNsSender pushes to iOS devices through APNs, authenticating with
+// provider tokens signed by the team's key.
+type APNsSender struct {
+	client *httpclient.Client
+	config APNsConfig
+	key    *ecdsa.PrivateKey
+	host   string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+func NewAPNsSender(config APNsConfig, client *httpclient.Client) (*APNsSender, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM(config.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse APNs key: %w", err)
+	}
+
+	host := apnsSandboxHost
+	if config.Production {
+		host = apnsProductionHost
+	}
+
+	return &APNsSender{
+		client: client,
+		config: config,
+		key:    key,
+		host:   host,
+	}, nil
+}
+
+func (s *APNsSender) Push(ctx context.Context, token string, n *PushNotification) error {
+	providerToken, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	// Custom data is passed next to the aps dictionary
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": n.Title, "body": n.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range n.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode APNs payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.host+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build APNs request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apns-topic", s.config.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send APNs notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	var reason struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&reason)
+
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		reason.Reason == "BadDeviceToken", reason.Reason == "DeviceTokenNotForTopic", reason.Reason == "Unregistered":
+		return ErrUnregistered
+	case reason.Reason == "ExpiredProviderToken":
+		s.resetToken()
+	}
+
+	return fmt.Errorf("APNs responded %d: %s", resp.StatusCode, reason.Reason)
+}
+
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Since(s.issuedAt) < apnsTokenLifetime {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.config.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.config.KeyID
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs provider token: %w", err)
+	}
+
+	s.token = signed
+	s.issuedAt = now
+	return s.token, nil
+}
+
+func (s *APNsSender) resetToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = ""
+}
@@ -0,0 +1,91 @@
+package sender
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+)
+
+// Message is a notification rendered for a recipient. Short is the text
+// shown where there is little room, in push notifications; SMS is the
+// text message in the recipient's language. Data is passed on to apps
+// with push notifications. Attachments are only sent by email.
+type Message struct {
+	Subject     string
+	Body        string
+	Short       string
+	SMS         string
+	Data        map[string]string
+	Attachments []Attachment
+}
+
+// Channel delivers messages over one medium.
+type Channel interface {
+	Name() domain.Channel
+	// Address returns where recipient is reached over the channel, or ""
+	// when they cannot be reached over it.
+	Address(recipient *domain.Recipient) string
+	// Send returns the provider's ID of the message when the provider
+	// reports its delivery later, or "".
+	Send(ctx context.Context, recipient *domain.Recipient, msg *Message) (string, error)
+}
+
+// EmailChannel mails the subject and body of messages.
+type EmailChannel struct {
+	sender EmailSender
+}
+
+func NewEmailChannel(sender EmailSender) *EmailChannel {
+	return &EmailChannel{sender: sender}
+}
+
+func (c *EmailChannel) Name() domain.Channel {
+	return domain.ChannelEmail
+}
+
+func (c *EmailChannel) Address(recipient *domain.Recipient) string {
+	return recipient.Email
+}
+
+func (c *EmailChannel) Send(ctx context.Context, recipient *domain.Recipient, msg *Message) (string, error) {
+	return "", c.sender.Send(ctx, recipient.Email, msg.Subject, msg.Body, msg.Attachments...)
+}
+
+// OptOutStore records the numbers that opted out of text messages.
+type OptOutStore interface {
+	SetSMSOptOut(ctx context.Context, phone string, optedOut bool) error
+}
+
+// SMSChannel texts messages to the phone number of the recipient, unless
+// the number opted out. Numbers the provider refuses as opted out are
+// recorded so they are not texted again.
+type SMSChannel struct {
+	sender  SMSSender
+	optOuts OptOutStore
+}
+
+func NewSMSChannel(sender SMSSender, optOuts OptOutStore) *SMSChannel {
+	return &SMSChannel{sender: sender, optOuts: optOuts}
+}
+
+func (c *SMSChannel) Name() domain.Channel {
+	return domain.ChannelSMS
+}
+
+func (c *SMSChannel) Address(recipient *domain.Recipient) string {
+	if recipient.SMSOptedOut {
+		return ""
+	}
+	return recipient.Phone
+}
+
+func (c *SMSChannel) Send(ctx context.Context, recipient *domain.Recipient, msg *Message) (string, error) {
+	id, err := c.sender.Send(ctx, recipient.Phone, msg.SMS)
+	if errors.Is(err, ErrOptedOut) {
+		if optErr := c.optOuts.SetSMSOptOut(ctx, recipient.Phone, true); optErr != nil {
+			return "", errors.Join(err, optErr)
+		}
+	}
+	return id, err
+}
@@ -0,0 +1,101 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+)
+
+// ErrUnregistered is returned by push senders for device tokens the push
+// service no longer accepts, e.g. because the app was uninstalled.
+var ErrUnregistered = errors.New("device token is no longer registered")
+
+// PushNotification is the alert shown on a device.
+type PushNotification struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+type PushSender interface {
+	Push(ctx context.Context, token string, n *PushNotification) error
+}
+
+// DeviceStore forgets the devices push services reject as unregistered.
+type DeviceStore interface {
+	DeleteDevice(ctx context.Context, token string) error
+}
+
+// PushChannel pushes messages to every device of a recipient, through the
+// sender of the device's platform. Devices whose platform has no sender
+// are skipped.
+type PushChannel struct {
+	senders map[domain.Platform]PushSender
+	devices DeviceStore
+	logger  *logger.Logger
+}
+
+func NewPushChannel(senders map[domain.Platform]PushSender, devices DeviceStore, logger *logger.Logger) *PushChannel {
+	return &PushChannel{
+		senders: senders,
+		devices: devices,
+		logger:  logger,
+	}
+}
+
+func (c *PushChannel) Name() domain.Channel {
+	return domain.ChannelPush
+}
+
+// Address returns the user ID of recipients with a device a sender is
+// configured for, as the notification goes to all their devices.
+func (c *PushChannel) Address(recipient *domain.Recipient) string {
+	for _, device := range recipient.Devices {
+		if c.senders[device.Platform] != nil {
+			return recipient.UserID
+		}
+	}
+	return ""
+}
+
+// Send fails only when the message reached none of the devices. Devices
+// reported as unregistered are forgotten.
+func (c *PushChannel) Send(ctx context.Context, recipient *domain.Recipient, msg *Message) (string, error) {
+	n := &PushNotification{
+		Title: msg.Subject,
+		Body:  msg.Short,
+		Data:  msg.Data,
+	}
+
+	var errs []error
+	delivered := 0
+	for _, device := range recipient.Devices {
+		sender := c.senders[device.Platform]
+		if sender == nil {
+			continue
+		}
+
+		err := sender.Push(ctx, device.Token, n)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, ErrUnregistered):
+			if err := c.devices.DeleteDevice(ctx, device.Token); err != nil {
+				c.logger.WithContext(ctx).WithError(err).With("user_id", recipient.UserID).Warn("failed to forget unregistered device")
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s device: %w", device.Platform, err))
+		}
+	}
+
+	if delivered == 0 {
+		return "", errors.Join(errs...)
+	}
+	if len(errs) > 0 {
+		c.logger.WithContext(ctx).WithError(errors.Join(errs...)).With("user_id", recipient.UserID).Warn("push notification missed some devices")
+	}
+	return "", nil
+}
@@ -0,0 +1,146 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type EmailSender interface {
+	Send(ctx context.Context, to, subject, body string, attachments ...Attachment) error
+}
+
+// Attachment is a file sent along with an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type SMTPSender struct {
+	addr string
+	host string
+	from string
+
+	mu   sync.RWMutex
+	auth smtp.Auth
+}
+
+// NewSMTPSender sends plain text mail through host:port. Authentication is
+// only used when a username is configured, which lets local relays such as
+// MailHog work without credentials.
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	s := &SMTPSender{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		host: host,
+		from: from,
+	}
+	s.SetCredentials(username, password)
+
+	return s
+}
+
+// SetCredentials replaces the credentials used by subsequent sends, e.g.
+// after they were rotated in the secret store.
+func (s *SMTPSender) SetCredentials(username, password string) {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, s.host)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.auth = auth
+}
+
+// Send mails body as plain text. With attachments, the mail is
+// multipart/mixed with the text first.
+func (s *SMTPSender) Send(ctx context.Context, to, subject, body string, attachments ...Attachment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	if len(attachments) == 0 {
+		msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+		msg.WriteString("\r\n")
+		msg.WriteString(body)
+	} else {
+		parts, boundary, err := multipartBody(body, attachments)
+		if err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n", boundary)
+		msg.WriteString("\r\n")
+		msg.Write(parts)
+	}
+
+	s.mu.RLock()
+	auth := s.auth
+	s.mu.RUnlock()
+
+	if err := smtp.SendMail(s.addr, auth, s.from, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// multipartBody writes the text and the base64 encoded attachments of a
+// multipart/mixed mail, and returns them with their boundary.
+func multipartBody(body string, attachments []Attachment) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	text, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {`text/plain; charset="utf-8"`},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := text.Write([]byte(body)); err != nil {
+		return nil, "", err
+	}
+
+	for _, attachment := range attachments {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Lines of encoded data must not exceed 76 characters
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+				return nil, "", err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := part.Write([]byte(encoded + "\r\n")); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.Boundary(), nil
+}
@@ -0,0 +1,92 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+)
+
+const (
+	twilioEndpoint = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+	// twilioUnsubscribed is the error code of messages to numbers that
+	// replied STOP to the sender
+	twilioUnsubscribed = 21610
+)
+
+// ErrOptedOut is returned by SMS senders for numbers that opted out of
+// text messages from the sender.
+var ErrOptedOut = errors.New("recipient opted out of text messages")
+
+// SMSSender sends text messages and returns the provider's message ID,
+// which its delivery reports refer to.
+type SMSSender interface {
+	Send(ctx context.Context, to, text string) (string, error)
+}
+
+// TwilioSender sends text messages through the Twilio messaging API.
+// When statusCallback is set, Twilio reports the delivery of each message
+// to it.
+type TwilioSender struct {
+	client         *httpclient.Client
+	accountSID     string
+	authToken      string
+	from           string
+	statusCallback string
+}
+
+func NewTwilioSender(accountSID, authToken, from, statusCallback string, client *httpclient.Client) *TwilioSender {
+	return &TwilioSender{
+		client:         client,
+		accountSID:     accountSID,
+		authToken:      authToken,
+		from:           from,
+		statusCallback: statusCallback,
+	}
+}
+
+func (s *TwilioSender) Send(ctx context.Context, to, text string) (string, error) {
+	form := url.Values{
+		"To":   {to},
+		"From": {s.from},
+		"Body": {text},
+	}
+	if s.statusCallback != "" {
+		form.Set("StatusCallback", s.statusCallback)
+	}
+
+	endpoint := fmt.Sprintf(twilioEndpoint, url.PathEscape(s.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build Twilio request: %w", err)
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send text message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		SID     string `json:"sid"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	if resp.StatusCode < 300 {
+		return body.SID, nil
+	}
+	if body.Code == twilioUnsubscribed {
+		return "", ErrOptedOut
+	}
+	return "", fmt.Errorf("twilio responded %d (code %d): %s", resp.StatusCode, body.Code, body.Message)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// userData is the notifications part of a data export.
+type userData struct {
+	Recipient     *domain.Recipient      `json:"recipient,omitempty"`
+	Notifications []*domain.Notification `json:"notifications"`
+}
+
+// ExportUserData sends the notifications part of the data export
+// exportID: the contact details and preferences of the user, and the
+// notifications sent to them.
+func (s *NotificationService) ExportUserData(ctx context.Context, exportID, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.export_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	var data userData
+	if data.Recipient, err = s.repo.GetRecipient(ctx, userID); err != nil && errors.GetAppError(err).Type != errors.ErrorTypeNotFound {
+		return err
+	}
+	if data.Recipient != nil {
+		if data.Recipient.Devices, err = s.repo.ListDevices(ctx, userID); err != nil {
+			return err
+		}
+	}
+	if data.Notifications, err = s.repo.ListNotifications(ctx, userID); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return errors.NewInternalError("failed to encode notifications", err)
+	}
+
+	event := events.UserDataExportPartEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataExportPart, "notification-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataExportPartData{
+			ExportID: exportID,
+			UserID:   userID,
+			Part:     events.DataPartNotifications,
+			Content:  content,
+		},
+	}
+	if err := s.publisher.Publish(ctx, events.UserDataExportPart, userID, event); err != nil {
+		return errors.NewInternalError("failed to send data export part", err)
+	}
+
+	return nil
+}
+
+// EraseUserData removes the recipient of a deleted user with their
+// devices and notifications, and reports it.
+func (s *NotificationService) EraseUserData(ctx context.Context, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.erase_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	erased, err := s.repo.DeleteRecipient(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	event := events.UserDataErasedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataErased, "notification-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataErasedData{
+			UserID:   userID,
+			Part:     events.DataPartNotifications,
+			Records:  erased,
+			ErasedAt: time.Now().UTC(),
+		},
+	}
+	if err := s.publisher.Publish(ctx, events.UserDataErased, userID, event); err != nil {
+		return errors.NewInternalError("failed to report erasure", err)
+	}
+
+	return nil
+}
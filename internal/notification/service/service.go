@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/notification/domain"
+	"github.com/dmehra2102/booking-system/internal/notification/sender"
+	"github.com/dmehra2102/booking-system/internal/notification/templates"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type NotificationRepository interface {
+	Claim(ctx context.Context, n *domain.Notification) (bool, error)
+	MarkSent(ctx context.Context, id, providerMessageID string) error
+	MarkFailed(ctx context.Context, id, reason string) error
+	UpdateDeliveryStatus(ctx context.Context, providerMessageID string, status domain.DeliveryStatus, reason string) (*domain.Notification, error)
+	UpsertRecipient(ctx context.Context, recipient *domain.Recipient) error
+	GetRecipient(ctx context.Context, userID string) (*domain.Recipient, error)
+	DeleteRecipient(ctx context.Context, userID string) (int, error)
+	ListNotifications(ctx context.Context, userID string) ([]*domain.Notification, error)
+	UpsertPreferences(ctx context.Context, recipient *domain.Recipient) error
+	GetDefaults(ctx context.Context) (*domain.Defaults, error)
+	UpsertDefaults(ctx context.Context, defaults *domain.Defaults) error
+	SetSMSOptOut(ctx context.Context, phone string, optedOut bool) error
+	UpsertDevice(ctx context.Context, device *domain.Device) error
+	ListDevices(ctx context.Context, userID string) ([]*domain.Device, error)
+	DeleteDevice(ctx context.Context, token string) error
+}
+
+// channelOrder is the order notifications are dispatched over the
+// channels a user prefers.
+var channelOrder = []domain.Channel{domain.ChannelEmail, domain.ChannelPush, domain.ChannelSMS}
+
+// Replies to text messages that opt the number out of them, and back in.
+// These are the keywords carriers require senders to honour.
+var (
+	optOutKeywords = map[string]bool{"STOP": true, "STOPALL": true, "UNSUBSCRIBE": true, "CANCEL": true, "END": true, "QUIT": true}
+	optInKeywords  = map[string]bool{"START": true, "YES": true, "UNSTOP": true}
+)
+
+// Attach renders files to attach to the email of a notification, with ctx
+// in the language of recipient.
+type Attach func(ctx context.Context, recipient *domain.Recipient) []sender.Attachment
+
+type NotificationService struct {
+	repo      NotificationRepository
+	renderer  *templates.Renderer
+	channels  map[domain.Channel]sender.Channel
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	metrics   *metrics.Metrics
+	tracer    trace.Tracer
+}
+
+// NewNotificationService delivers over channels, which must include an
+// email channel.
+func NewNotificationService(
+	repo NotificationRepository,
+	renderer *templates.Renderer,
+	channels []sender.Channel,
+	publisher messagebus.Publisher,
+	logger *logger.Logger,
+	metrics *metrics.Metrics,
+	tracer trace.Tracer,
+) *NotificationService {
+	byName := make(map[domain.Channel]sender.Channel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+
+	return &NotificationService{
+		repo:      repo,
+		renderer:  renderer,
+		channels:  byName,
+		publisher: publisher,
+		logger:    logger,
+		metrics:   metrics,
+		tracer:    tracer,
+	}
+}
+
+// RegisterRecipient records the contact details of a user. A locale is
+// the language the user registered in; it is ignored when empty and once
+// the user chose a language.
+func (s *NotificationService) RegisterRecipient(ctx context.Context, userID, email, name, locale string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.register_recipient", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.UpsertRecipient(ctx, &domain.Recipient{UserID: userID, Email: email, Name: name, Locale: locale})
+}
+
+// SetPreferences records the channels and quiet hours of a recipient,
+// and the phone number and language of their text messages.
+func (s *NotificationService) SetPreferences(ctx context.Context, prefs *domain.Recipient) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.set_preferences", trace.WithAttributes(tracing.UserID.String(prefs.UserID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.UpsertPreferences(ctx, prefs)
+}
+
+// SetDefaults records the preferences of the users of the tenant who
+// never set their own.
+func (s *NotificationService) SetDefaults(ctx context.Context, defaults *domain.Defaults) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.set_defaults")
+	defer tracing.End(span, &err)
+
+	return s.repo.UpsertDefaults(ctx, defaults)
+}
+
+// HandleDeliveryReport records the final delivery status the SMS provider
+// reported for a message. Reports of unknown messages and repeated
+// reports are ignored.
+func (s *NotificationService) HandleDeliveryReport(ctx context.Context, providerMessageID string, status domain.DeliveryStatus, reason string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.handle_delivery_report")
+	defer tracing.End(span, &err)
+
+	notification, err := s.repo.UpdateDeliveryStatus(ctx, providerMessageID, status, reason)
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			s.logger.WithContext(ctx).With("provider_message_id", providerMessageID).Info("delivery report for unknown or settled message, ignoring")
+			return nil
+		}
+		return err
+	}
+
+	// Messages that were sent are counted as delivered or undelivered, so
+	// failed counts only the messages the provider refused
+	ctx = tenancy.WithID(ctx, notification.TenantID)
+	outcome := "delivered"
+	if status == domain.DeliveryStatusFailed {
+		outcome = "undelivered"
+		s.publishFailed(ctx, span, notification, reason)
+	}
+	s.metrics.NotificationsTotal.WithLabelValues(string(notification.Channel), outcome, notification.TenantID).Inc()
+
+	s.logger.WithContext(ctx).With("notification_id", notification.ID).With("status", string(status)).Info("delivery report recorded")
+
+	return nil
+}
+
+// HandleInboundSMS handles a text message received from phone. Opt-out
+// keywords such as STOP stop text messages to the number, and opt-in
+// keywords such as START resume them; other messages are ignored.
+func (s *NotificationService) HandleInboundSMS(ctx context.Context, phone, body string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.handle_inbound_sms")
+	defer tracing.End(span, &err)
+
+	keyword := strings.ToUpper(strings.TrimSpace(body))
+	switch {
+	case optOutKeywords[keyword]:
+		err = s.repo.SetSMSOptOut(ctx, phone, true)
+	case optInKeywords[keyword]:
+		err = s.repo.SetSMSOptOut(ctx, phone, false)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithContext(ctx).With("keyword", keyword).Info("text message opt-out updated")
+
+	return nil
+}
+
+func (s *NotificationService) RegisterDevice(ctx context.Context, userID, token string, platform domain.Platform) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.register_device", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.UpsertDevice(ctx, &domain.Device{Token: token, UserID: userID, Platform: platform})
+}
+
+func (s *NotificationService) RemoveDevice(ctx context.Context, token string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.remove_device")
+	defer tracing.End(span, &err)
+
+	return s.repo.DeleteDevice(ctx, token)
+}
+
+// SendEmail renders account mail, such as password resets, for the user
+// and emails it at most once per source event, regardless of the user's
+// preferences. Redelivered events are counted as suppressed duplicates. A
+// failed send is returned so the consumer retries it.
+func (s *NotificationService) SendEmail(ctx context.Context, eventID, userID, template string, data map[string]any) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.send_email", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	recipient, err := s.recipient(ctx, userID, template)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	msg, err := s.render(template, data, recipient)
+	if err != nil {
+		return err
+	}
+
+	return s.deliver(ctx, span, s.channels[domain.ChannelEmail], eventID, recipient, template, msg)
+}
+
+// Notify renders template for the user and sends it over every channel
+// they chose for category that reaches them, at most once per source
+// event and channel. In the user's quiet hours only email is sent. When a
+// channel fails the error is returned after the others were tried, and
+// the retried event is only sent over the failed channels again. The files
+// of attach are added to the email.
+func (s *NotificationService) Notify(ctx context.Context, eventID, userID string, category domain.Category, template string, data map[string]any, attach ...Attach) (err error) {
+	ctx, span := s.tracer.Start(ctx, "notification.service.notify", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	recipient, err := s.recipient(ctx, userID, template)
+	if err != nil || recipient == nil {
+		return err
+	}
+
+	if !recipient.PreferencesSet {
+		defaults, err := s.repo.GetDefaults(ctx)
+		if err != nil {
+			return err
+		}
+		recipient.ApplyDefaults(defaults)
+	}
+
+	channels := recipient.ChannelsFor(category)
+	quiet := recipient.QuietHours.Contains(time.Now())
+
+	if channels.Push && !quiet && s.channels[domain.ChannelPush] != nil {
+		recipient.Devices, err = s.repo.ListDevices(ctx, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	msg, err := s.render(template, data, recipient)
+	if err != nil {
+		return err
+	}
+	if channels.Email {
+		localized := i18n.WithLocale(ctx, recipient.Locale)
+		for _, a := range attach {
+			msg.Attachments = append(msg.Attachments, a(localized, recipient)...)
+		}
+	}
+
+	var errs []error
+	sent := 0
+	for _, name := range channelOrder {
+		channel := s.channels[name]
+		if channel == nil || !channels.Enabled(name) {
+			continue
+		}
+		if quiet && name != domain.ChannelEmail {
+			s.metrics.NotificationsTotal.WithLabelValues(string(name), "quiet_hours", tenancy.ID(ctx)).Inc()
+			s.logger.WithContext(ctx).With("user_id", userID).With("template", template).With("channel", string(name)).Info("quiet hours, notification held back")
+			continue
+		}
+		if channel.Address(recipient) == "" {
+			continue
+		}
+
+		sent++
+		if err := s.deliver(ctx, span, channel, eventID, recipient, template, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if sent == 0 {
+		s.logger.WithContext(ctx).With("user_id", userID).With("template", template).Info("user cannot be reached over a preferred channel, skipping notification")
+	}
+
+	return stderrors.Join(errs...)
+}
+
+// recipient returns the contact details of userID, or nil when the user
+// is unknown and the notification is to be skipped.
+func (s *NotificationService) recipient(ctx context.Context, userID, template string) (*domain.Recipient, error) {
+	recipient, err := s.repo.GetRecipient(ctx, userID)
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			s.logger.WithContext(ctx).With("user_id", userID).With("template", template).Warn("no recipient known for user, skipping notification")
+			return nil, nil
+		}
+		return nil, err
+	}
+	return recipient, nil
+}
+
+// render renders template for recipient. The string values of data are
+// passed to apps with push notifications, along with the template as
+// "type".
+func (s *NotificationService) render(template string, data map[string]any, recipient *domain.Recipient) (*sender.Message, error) {
+	pushData := map[string]string{"type": template}
+	for key, value := range data {
+		if str, ok := value.(string); ok {
+			pushData[key] = str
+		}
+	}
+
+	data["Name"] = recipient.Name
+	data["Email"] = recipient.Email
+
+	subject, body, err := s.renderer.Render(template, recipient.Locale, data)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to render notification", err)
+	}
+	short, err := s.renderer.Short(template, recipient.Locale, data)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to render notification", err)
+	}
+	sms, err := s.renderer.SMS(template, recipient.Locale, data)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to render notification", err)
+	}
+
+	return &sender.Message{Subject: subject, Body: body, Short: short, SMS: sms, Data: pushData}, nil
+}
+
+// deliver sends msg over channel unless the event was delivered over it
+// before, and records the outcome.
+func (s *NotificationService) deliver(ctx context.Context, span trace.Span, channel sender.Channel, eventID string, recipient *domain.Recipient, template string, msg *sender.Message) error {
+	name := channel.Name()
+	content := msg.Short
+	switch name {
+	case domain.ChannelEmail:
+		content = msg.Body
+	case domain.ChannelSMS:
+		content = msg.SMS
+	}
+
+	notification := &domain.Notification{
+		EventID:   eventID,
+		UserID:    recipient.UserID,
+		Channel:   name,
+		Template:  template,
+		Recipient: channel.Address(recipient),
+		Subject:   msg.Subject,
+		Body:      content,
+	}
+
+	claimed, err := s.repo.Claim(ctx, notification)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		s.metrics.NotificationsTotal.WithLabelValues(string(name), "duplicate", tenancy.ID(ctx)).Inc()
+		s.logger.WithContext(ctx).With("event_id", eventID).With("template", template).With("channel", string(name)).Info("duplicate event, notification suppressed")
+		return nil
+	}
+
+	providerMessageID, err := channel.Send(ctx, recipient, msg)
+	if err != nil {
+		if markErr := s.repo.MarkFailed(ctx, notification.ID, err.Error()); markErr != nil {
+			s.logger.WithContext(ctx).WithError(markErr).Error("failed to record notification failure")
+		}
+
+		s.metrics.NotificationsTotal.WithLabelValues(string(name), "failed", tenancy.ID(ctx)).Inc()
+		s.publishFailed(ctx, span, notification, err.Error())
+		return errors.NewExternalError(provider(name), "failed to send "+string(name)+" notification", err)
+	}
+
+	if err := s.repo.MarkSent(ctx, notification.ID, providerMessageID); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to record notification delivery")
+	}
+
+	s.metrics.NotificationsTotal.WithLabelValues(string(name), "sent", tenancy.ID(ctx)).Inc()
+
+	// Publish event
+	event := events.NotificationSentEvent{
+		BaseEvent: events.NewBaseEvent(events.NotificationSent, "notification-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.NotificationSentData{
+			NotificationID: notification.ID,
+			UserID:         notification.UserID,
+			Type:           notification.Template,
+			Channel:        string(notification.Channel),
+			Subject:        notification.Subject,
+			Content:        notification.Body,
+			SentAt:         time.Now().UTC(),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.NotificationSent, notification.UserID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish notification sent event")
+	}
+
+	s.logger.WithContext(ctx).With("notification_id", notification.ID).With("template", template).With("channel", string(name)).Info("notification sent successfully")
+
+	return nil
+}
+
+// provider names the service a channel delivers through in errors.
+func provider(channel domain.Channel) string {
+	if channel == domain.ChannelEmail {
+		return "smtp"
+	}
+	return string(channel)
+}
+
+func (s *NotificationService) publishFailed(ctx context.Context, span trace.Span, notification *domain.Notification, reason string) {
+	event := events.NotificationFailedEvent{
+		BaseEvent: events.NewBaseEvent(events.NotificationFailed, "notification-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.NotificationFailedData{
+			NotificationID: notification.ID,
+			UserID:         notification.UserID,
+			Type:           notification.Template,
+			Channel:        string(notification.Channel),
+			Reason:         reason,
+			FailedAt:       time.Now().UTC(),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.NotificationFailed, notification.UserID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish notification failed event")
+	}
+}
@@ -0,0 +1,152 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+)
+
+const (
+	Welcome          = "welcome"
+	VerifyEmail      = "verify_email"
+	PasswordReset    = "password_reset"
+	NewDeviceLogin   = "new_device_login"
+	BookingConfirmed = "booking_confirmed"
+	BookingCancelled = "booking_cancelled"
+	BookingReminder  = "booking_reminder"
+	PaymentProcessed = "payment_processed"
+	PaymentFailed    = "payment_failed"
+	WaitlistOffered  = "waitlist_offered"
+	ExportCompleted  = "export_completed"
+	DataExportReady  = "data_export_ready"
+)
+
+//go:embed *.tmpl sms/*.tmpl
+var files embed.FS
+
+// Renderer renders the embedded templates in the language of the
+// recipient. Email templates define a "subject" and a "body" block, and
+// may define a "short" block for push notifications; text messages are
+// written in sms/, where the whole file is the message.
+//
+// A template <name>.tmpl is written in i18n.DefaultLocale and its
+// translations are named <name>.<locale>.tmpl. A locale without a
+// translation falls back to its base language, e.g. "pt-BR" to "pt", and
+// then to the default.
+type Renderer struct {
+	// emails and sms hold the templates by name and locale
+	emails map[string]map[string]*template.Template
+	sms    map[string]map[string]*template.Template
+}
+
+func NewRenderer() (*Renderer, error) {
+	emails, err := parseDir(".")
+	if err != nil {
+		return nil, err
+	}
+
+	sms, err := parseDir("sms")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Renderer{emails: emails, sms: sms}, nil
+}
+
+// parseDir parses the templates in dir by name and locale.
+func parseDir(dir string) (map[string]map[string]*template.Template, error) {
+	entries, err := files.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	templates := make(map[string]map[string]*template.Template)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		tmpl, err := template.ParseFS(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", entry.Name(), err)
+		}
+
+		name, locale, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".tmpl"), ".")
+		if !ok {
+			locale = i18n.DefaultLocale
+		}
+		if templates[name] == nil {
+			templates[name] = make(map[string]*template.Template)
+		}
+		templates[name][strings.ToLower(locale)] = tmpl
+	}
+
+	return templates, nil
+}
+
+// lookup returns the translation of a template that suits locale best.
+func lookup(templates map[string]map[string]*template.Template, name, locale string) (*template.Template, bool) {
+	for _, candidate := range i18n.Fallbacks(locale) {
+		if tmpl, ok := templates[name][candidate]; ok {
+			return tmpl, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Renderer) Render(name, locale string, data any) (subject, body string, err error) {
+	tmpl, ok := lookup(r.emails, name, locale)
+	if !ok {
+		return "", "", fmt.Errorf("unknown template %q", name)
+	}
+
+	var subjectBuf, bodyBuf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subjectBuf, "subject", data); err != nil {
+		return "", "", fmt.Errorf("failed to render subject of %s: %w", name, err)
+	}
+	if err := tmpl.ExecuteTemplate(&bodyBuf, "body", data); err != nil {
+		return "", "", fmt.Errorf("failed to render body of %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(subjectBuf.String()), bodyBuf.String(), nil
+}
+
+// Short renders the short form of a template, which is its subject when
+// the template has no "short" block.
+func (r *Renderer) Short(name, locale string, data any) (string, error) {
+	tmpl, ok := lookup(r.emails, name, locale)
+	if !ok {
+		return "", fmt.Errorf("unknown template %q", name)
+	}
+
+	block := "short"
+	if tmpl.Lookup(block) == nil {
+		block = "subject"
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, block, data); err != nil {
+		return "", fmt.Errorf("failed to render %s of %s: %w", block, name, err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// SMS renders the text message of a template, which is its short form
+// when sms/ has no template for it.
+func (r *Renderer) SMS(name, locale string, data any) (string, error) {
+	tmpl, ok := lookup(r.sms, name, locale)
+	if !ok {
+		return r.Short(name, locale, data)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render text message of %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
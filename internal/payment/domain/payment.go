@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending   PaymentStatus = "pending"
+	PaymentStatusSucceeded PaymentStatus = "succeeded"
+	PaymentStatusFailed    PaymentStatus = "failed"
+	PaymentStatusRefunded  PaymentStatus = "refunded"
+)
+
+type Payment struct {
+	ID            string        `json:"id" db:"id"`
+	BookingID     string        `json:"booking_id" db:"booking_id"`
+	UserID        string        `json:"user_id" db:"user_id"`
+	Amount        money.Money   `json:"amount" db:"amount"`
+	Status        PaymentStatus `json:"status" db:"status"`
+	Provider      string        `json:"provider" db:"provider"`
+	ProviderRef   string        `json:"provider_ref,omitempty" db:"provider_ref"`
+	FailureReason string        `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+func (p *Payment) IsSettled() bool {
+	return p.Status == PaymentStatusSucceeded || p.Status == PaymentStatusRefunded
+}
+
+func (p *Payment) CanBeRefunded() bool {
+	return p.Status == PaymentStatusSucceeded
+}
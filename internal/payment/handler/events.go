@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/payment/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler adapts booking, inventory and user events consumed from
+// Kafka to payment service calls.
+type EventHandler struct {
+	service *service.PaymentService
+	logger  *logger.Logger
+}
+
+func NewEventHandler(service *service.PaymentService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *EventHandler) HandleBookingRequested(ctx context.Context, event events.BookingRequestedEvent) error {
+	return h.service.RegisterIntent(ctx, event.Data.BookingID, event.Data.UserID, event.Data.Amount)
+}
+
+func (h *EventHandler) HandleInventoryReserved(ctx context.Context, event events.InventoryReservedEvent) error {
+	_, err := h.service.ProcessPayment(ctx, event.Data.BookingID)
+	return err
+}
+
+func (h *EventHandler) HandleBookingConfirmed(ctx context.Context, event events.BookingConfirmedEvent) error {
+	_, err := h.service.EnsurePaid(ctx, event.Data.BookingID, event.Data.UserID, event.Data.Amount)
+	return err
+}
+
+// HandleBookingCancelled refunds what remains of the payment after the
+// cancellation fee. Bookings that were never paid have nothing to refund.
+func (h *EventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	if !event.Data.RefundAmount.IsPositive() {
+		return nil
+	}
+
+	_, err := h.service.Refund(ctx, event.Data.BookingID, event.Data.RefundAmount)
+	if appErr := errors.GetAppError(err); appErr != nil &&
+		(appErr.Type == errors.ErrorTypeNotFound || appErr.Type == errors.ErrorTypeConfict) {
+		h.logger.WithContext(ctx).With("booking_id", event.Data.BookingID).Info("no settled payment to refund")
+		return nil
+	}
+	return err
+}
+
+// HandleBookingUpdated settles a repriced booking. Changes that leave the
+// amount alone are ignored, as are bookings without a payment to adjust.
+func (h *EventHandler) HandleBookingUpdated(ctx context.Context, event events.BookingUpdatedEvent) error {
+	if event.Data.Amount == event.Data.Previous.Amount {
+		return nil
+	}
+
+	_, err := h.service.Adjust(ctx, event.Data.BookingID, event.Data.Amount)
+	if appErr := errors.GetAppError(err); appErr != nil &&
+		(appErr.Type == errors.ErrorTypeNotFound || appErr.Type == errors.ErrorTypeConfict) {
+		h.logger.WithContext(ctx).With("booking_id", event.Data.BookingID).WithError(err).Warn("booking change left unsettled")
+		return nil
+	}
+	return err
+}
+
+// HandleUserDataExportRequested sends the payments part of a data export.
+func (h *EventHandler) HandleUserDataExportRequested(ctx context.Context, event events.UserDataExportRequestedEvent) error {
+	return h.service.ExportUserData(ctx, event.Data.ExportID, event.Data.UserID)
+}
+
+// HandleUserDeleted reports the erasure of a deleted user's payments.
+func (h *EventHandler) HandleUserDeleted(ctx context.Context, event events.UserDeletedEvent) error {
+	return h.service.EraseUserData(ctx, event.Data.UserID)
+}
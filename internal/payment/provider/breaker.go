@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dmehra2102/booking-system/internal/common/resilience"
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+// breakerProvider guards a provider with a circuit breaker so that an
+// unavailable gateway fails payments fast instead of tying up consumers.
+type breakerProvider struct {
+	PaymentProvider
+	breaker *resilience.Breaker
+}
+
+// IsFailure reports whether err from a provider indicates a gateway
+// problem. Declined charges are normal business outcomes.
+func IsFailure(err error) bool {
+	return !errors.Is(err, ErrDeclined) && !errors.Is(err, context.Canceled)
+}
+
+// WithBreaker wraps provider so every call goes through breaker, which
+// should be configured with IsFailure.
+func WithBreaker(provider PaymentProvider, breaker *resilience.Breaker) PaymentProvider {
+	return &breakerProvider{PaymentProvider: provider, breaker: breaker}
+}
+
+func (p *breakerProvider) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	var result *ChargeResult
+	err := p.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = p.PaymentProvider.Charge(ctx, req)
+		return err
+	})
+	return result, err
+}
+
+func (p *breakerProvider) Refund(ctx context.Context, reference string, amount money.Money) error {
+	return p.breaker.Execute(ctx, func(ctx context.Context) error {
+		return p.PaymentProvider.Refund(ctx, reference, amount)
+	})
+}
+
+func (p *breakerProvider) GetStatus(ctx context.Context, reference string) (Status, error) {
+	var status Status
+	err := p.breaker.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		status, err = p.PaymentProvider.GetStatus(ctx, reference)
+		return err
+	})
+	return status, err
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"github.com/google/uuid"
+)
+
+// MockProvider is an in-memory provider for development and local testing.
+// Charges above declineAbove are declined; a zero limit accepts everything.
+type MockProvider struct {
+	mu           sync.Mutex
+	charges      map[string]Status
+	declineAbove float64
+}
+
+func NewMockProvider(declineAbove float64) *MockProvider {
+	return &MockProvider{
+		charges:      make(map[string]Status),
+		declineAbove: declineAbove,
+	}
+}
+
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+func (p *MockProvider) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reference := "mock_" + uuid.New().String()
+	if p.declineAbove > 0 && req.Amount.Float() > p.declineAbove {
+		p.charges[reference] = StatusFailed
+		return nil, fmt.Errorf("%w: amount %s exceeds mock limit", ErrDeclined, req.Amount)
+	}
+
+	p.charges[reference] = StatusSucceeded
+	return &ChargeResult{Reference: reference, Status: StatusSucceeded}, nil
+}
+
+func (p *MockProvider) Refund(ctx context.Context, reference string, amount money.Money) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.charges[reference] != StatusSucceeded {
+		return fmt.Errorf("charge %s cannot be refunded", reference)
+	}
+
+	p.charges[reference] = StatusRefunded
+	return nil
+}
+
+func (p *MockProvider) GetStatus(ctx context.Context, reference string) (Status, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status, ok := p.charges[reference]
+	if !ok {
+		return "", fmt.Errorf("charge %s not found", reference)
+	}
+	return status, nil
+}
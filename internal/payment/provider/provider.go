@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusRefunded  Status = "refunded"
+)
+
+// ErrDeclined is returned by providers when a charge is refused for a
+// business reason (card declined, insufficient funds) rather than a
+// transport or provider failure.
+var ErrDeclined = errors.New("payment declined")
+
+type ChargeRequest struct {
+	PaymentID string
+	UserID    string
+	Amount    money.Money
+}
+
+type ChargeResult struct {
+	Reference string
+	Status    Status
+}
+
+// PaymentProvider is implemented by each payment gateway integration.
+type PaymentProvider interface {
+	Name() string
+	Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error)
+	Refund(ctx context.Context, reference string, amount money.Money) error
+	GetStatus(ctx context.Context, reference string) (Status, error)
+}
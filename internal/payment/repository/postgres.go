@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/payment/domain"
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresPaymentRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresPaymentRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresPaymentRepository {
+	return &PostgresPaymentRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+func (r *PostgresPaymentRepository) Create(ctx context.Context, payment *domain.Payment) (err error) {
+	ctx, span := r.tracer.Start(ctx, "payment.repository.create")
+	defer tracing.End(span, &err)
+
+	payment.ID = uuid.New().String()
+	payment.CreatedAt = time.Now().UTC()
+	payment.UpdatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO payments (
+			id, tenant_id, booking_id, user_id, amount, currency, status,
+			provider, provider_ref, failure_reason, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (booking_id) DO NOTHING
+	`
+
+	_, err = r.db.Exec(ctx, "payment.create", query,
+		payment.ID, tenancy.ID(ctx), payment.BookingID, payment.UserID, payment.Amount.Decimal(), payment.Amount.Currency,
+		payment.Status, payment.Provider, payment.ProviderRef, payment.FailureReason,
+		payment.CreatedAt, payment.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create payment", err)
+	}
+
+	return nil
+}
+
+const selectPaymentQuery = `
+	SELECT id, booking_id, user_id, amount, currency, status,
+		provider, provider_ref, failure_reason, created_at, updated_at
+	FROM payments
+`
+
+func scanPayment(row database.Scanner) (*domain.Payment, error) {
+	payment := &domain.Payment{}
+	var amount, currency string
+	err := row.Scan(
+		&payment.ID, &payment.BookingID, &payment.UserID, &amount, &currency,
+		&payment.Status, &payment.Provider, &payment.ProviderRef, &payment.FailureReason,
+		&payment.CreatedAt, &payment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Amount, err = money.Parse(amount, money.Currency(currency)); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (r *PostgresPaymentRepository) GetByBookingID(ctx context.Context, bookingID string) (_ *domain.Payment, err error) {
+	ctx, span := r.tracer.Start(ctx, "payment.repository.get_by_booking_id", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	query := selectPaymentQuery + ` WHERE booking_id = $1 AND tenant_id = $2`
+
+	payment, err := scanPayment(r.db.QueryRow(ctx, "payment.get_by_booking_id", query, bookingID, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("payment")
+		}
+		return nil, errors.NewInternalError("failed to get payment", err)
+	}
+
+	return payment, nil
+}
+
+// ListByUserID returns the payments of userID, oldest first.
+func (r *PostgresPaymentRepository) ListByUserID(ctx context.Context, userID string) (_ []*domain.Payment, err error) {
+	ctx, span := r.tracer.Start(ctx, "payment.repository.list_by_user_id", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := selectPaymentQuery + ` WHERE user_id = $1 AND tenant_id = $2 ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, "payment.list_by_user_id", query, userID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list payments", err)
+	}
+	payments, err := database.ScanAll(rows, scanPayment)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan payments", err)
+	}
+
+	return payments, nil
+}
+
+func (r *PostgresPaymentRepository) UpdateStatus(ctx context.Context, payment *domain.Payment) (err error) {
+	ctx, span := r.tracer.Start(ctx, "payment.repository.update_status")
+	defer tracing.End(span, &err)
+
+	payment.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE payments
+		SET status = $1, provider = $2, provider_ref = $3, failure_reason = $4, updated_at = $5
+		WHERE id = $6 AND tenant_id = $7
+	`
+
+	result, err := r.db.Exec(ctx, "payment.update_status", query,
+		payment.Status, payment.Provider, payment.ProviderRef, payment.FailureReason,
+		payment.UpdatedAt, payment.ID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to update payment", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check update result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("payment")
+	}
+
+	return nil
+}
+
+// UpdateAmount changes what a payment is for, e.g. after the booking was
+// repriced.
+func (r *PostgresPaymentRepository) UpdateAmount(ctx context.Context, payment *domain.Payment) (err error) {
+	ctx, span := r.tracer.Start(ctx, "payment.repository.update_amount")
+	defer tracing.End(span, &err)
+
+	payment.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE payments
+		SET amount = $1, currency = $2, updated_at = $3
+		WHERE id = $4 AND tenant_id = $5
+	`
+
+	result, err := r.db.Exec(ctx, "payment.update_amount", query,
+		payment.Amount.Decimal(), payment.Amount.Currency, payment.UpdatedAt, payment.ID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to update payment amount", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check update result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("payment")
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExportUserData sends the payments part of the data export exportID.
+func (s *PaymentService) ExportUserData(ctx context.Context, exportID, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "payment.service.export_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	payments, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(map[string]any{"payments": payments})
+	if err != nil {
+		return errors.NewInternalError("failed to encode payments", err)
+	}
+
+	event := events.UserDataExportPartEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataExportPart, "payment-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataExportPartData{
+			ExportID: exportID,
+			UserID:   userID,
+			Part:     events.DataPartPayments,
+			Content:  content,
+		},
+	}
+	if err := s.publisher.Publish(ctx, events.UserDataExportPart, userID, event); err != nil {
+		return errors.NewInternalError("failed to send data export part", err)
+	}
+
+	return nil
+}
+
+// EraseUserData reports the erasure of a deleted user. Payments hold no
+// personal data besides the user ID and have to be kept for accounting,
+// so nothing is erased.
+func (s *PaymentService) EraseUserData(ctx context.Context, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "payment.service.erase_user_data", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	event := events.UserDataErasedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataErased, "payment-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataErasedData{
+			UserID:   userID,
+			Part:     events.DataPartPayments,
+			Retained: []string{"payments, which accounting has to keep"},
+			ErasedAt: time.Now().UTC(),
+		},
+	}
+	if err := s.publisher.Publish(ctx, events.UserDataErased, userID, event); err != nil {
+		return errors.NewInternalError("failed to report erasure", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/payment/domain"
+	"github.com/dmehra2102/booking-system/internal/payment/provider"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *domain.Payment) error
+	GetByBookingID(ctx context.Context, bookingID string) (*domain.Payment, error)
+	ListByUserID(ctx context.Context, userID string) ([]*domain.Payment, error)
+	UpdateStatus(ctx context.Context, payment *domain.Payment) error
+	UpdateAmount(ctx context.Context, payment *domain.Payment) error
+}
+
+type PaymentService struct {
+	repo      PaymentRepository
+	provider  provider.PaymentProvider
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	metrics   *metrics.Metrics
+	tracer    trace.Tracer
+}
+
+func NewPaymentService(
+	repo PaymentRepository,
+	provider provider.PaymentProvider,
+	publisher messagebus.Publisher,
+	logger *logger.Logger,
+	metrics *metrics.Metrics,
+	tracer trace.Tracer,
+) *PaymentService {
+	return &PaymentService{
+		repo:      repo,
+		provider:  provider,
+		publisher: publisher,
+		logger:    logger,
+		metrics:   metrics,
+		tracer:    tracer,
+	}
+}
+
+// RegisterIntent records a pending payment for a booking so it can be
+// charged once the inventory has been reserved.
+func (s *PaymentService) RegisterIntent(ctx context.Context, bookingID, userID string, amount money.Money) (err error) {
+	ctx, span := s.tracer.Start(ctx, "payment.service.register_intent", trace.WithAttributes(tracing.BookingID.String(bookingID), tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	payment := &domain.Payment{
+		BookingID: bookingID,
+		UserID:    userID,
+		Amount:    amount,
+		Status:    domain.PaymentStatusPending,
+		Provider:  s.provider.Name(),
+	}
+
+	return s.repo.Create(ctx, payment)
+}
+
+// ProcessPayment charges the pending payment of a booking and publishes
+// payment.processed or payment.failed. Payments that already reached a
+// final state are left untouched so redelivered events do not charge twice.
+func (s *PaymentService) ProcessPayment(ctx context.Context, bookingID string) (_ *domain.Payment, err error) {
+	ctx, span := s.tracer.Start(ctx, "payment.service.process", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	payment, err := s.repo.GetByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != domain.PaymentStatusPending {
+		return payment, nil
+	}
+
+	result, err := s.provider.Charge(ctx, &provider.ChargeRequest{
+		PaymentID: payment.ID,
+		UserID:    payment.UserID,
+		Amount:    payment.Amount,
+	})
+	if err != nil {
+		if !stderrors.Is(err, provider.ErrDeclined) {
+			return nil, errors.NewExternalError(s.provider.Name(), "payment provider unavailable", err)
+		}
+
+		payment.Status = domain.PaymentStatusFailed
+		payment.FailureReason = err.Error()
+		if err := s.repo.UpdateStatus(ctx, payment); err != nil {
+			return nil, err
+		}
+
+		s.publishFailed(ctx, span, payment)
+		return payment, nil
+	}
+
+	payment.Status = domain.PaymentStatusSucceeded
+	payment.ProviderRef = result.Reference
+	if err := s.repo.UpdateStatus(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	event := events.PaymentProcessedEvent{
+		BaseEvent: events.NewBaseEvent(events.PaymentProcessed, "payment-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.PaymentProcessedData{
+			PaymentID:   payment.ID,
+			BookingID:   payment.BookingID,
+			UserID:      payment.UserID,
+			Amount:      payment.Amount,
+			Method:      payment.Provider,
+			Status:      string(payment.Status),
+			ProcessedAt: payment.UpdatedAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.PaymentProcessed, payment.BookingID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish payment processed event")
+	}
+
+	s.logger.WithContext(ctx).With("booking_id", bookingID).With("payment_id", payment.ID).Info("payment processed successfully")
+
+	return payment, nil
+}
+
+// EnsurePaid charges a confirmed booking that has no settled payment yet,
+// creating the payment record first if the booking skipped the request
+// phase.
+func (s *PaymentService) EnsurePaid(ctx context.Context, bookingID, userID string, amount money.Money) (_ *domain.Payment, err error) {
+	ctx, span := s.tracer.Start(ctx, "payment.service.ensure_paid", trace.WithAttributes(tracing.BookingID.String(bookingID), tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := s.RegisterIntent(ctx, bookingID, userID, amount); err != nil {
+		return nil, err
+	}
+
+	return s.ProcessPayment(ctx, bookingID)
+}
+
+// Refund returns amount of a succeeded payment to the customer and
+// publishes payment.refunded. A zero amount, or one above the payment,
+// refunds the payment in full.
+func (s *PaymentService) Refund(ctx context.Context, bookingID string, amount money.Money) (_ *domain.Payment, err error) {
+	ctx, span := s.tracer.Start(ctx, "payment.service.refund", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	payment, err := s.repo.GetByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !payment.CanBeRefunded() {
+		return nil, errors.NewConflictError("payment cannot be refunded")
+	}
+
+	if amount.Currency != payment.Amount.Currency && amount.IsPositive() {
+		return nil, errors.NewValidationError("refund currency does not match the payment", money.ErrCurrencyMismatch)
+	}
+	if !amount.IsPositive() || amount.Amount > payment.Amount.Amount {
+		amount = payment.Amount
+	}
+
+	if err := s.provider.Refund(ctx, payment.ProviderRef, amount); err != nil {
+		return nil, errors.NewExternalError(s.provider.Name(), "failed to refund payment", err)
+	}
+
+	payment.Status = domain.PaymentStatusRefunded
+	if err := s.repo.UpdateStatus(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	event := events.PaymentRefundedEvent{
+		BaseEvent: events.NewBaseEvent(events.PaymentRefunded, "payment-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.PaymentRefundedData{
+			PaymentID:  payment.ID,
+			BookingID:  payment.BookingID,
+			UserID:     payment.UserID,
+			Amount:     amount,
+			RefundedAt: time.Now().UTC(),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.PaymentRefunded, payment.BookingID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish payment refunded event")
+	}
+
+	s.logger.WithContext(ctx).With("booking_id", bookingID).With("payment_id", payment.ID).Info("payment refunded successfully")
+
+	return payment, nil
+}
+
+// Adjust settles a change of the booking amount to amount. A payment that
+// is still pending is charged the new amount later; a succeeded one is
+// charged the increase or refunded the decrease right away. Redelivered
+// events find the amount already adjusted and do nothing.
+func (s *PaymentService) Adjust(ctx context.Context, bookingID string, amount money.Money) (_ *domain.Payment, err error) {
+	ctx, span := s.tracer.Start(ctx, "payment.service.adjust", trace.WithAttributes(tracing.BookingID.String(bookingID)))
+	defer tracing.End(span, &err)
+
+	payment, err := s.repo.GetByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta, err := amount.Sub(payment.Amount)
+	if err != nil {
+		return nil, errors.NewValidationError("adjusted amount currency does not match the payment", err)
+	}
+	if delta.IsZero() {
+		return payment, nil
+	}
+
+	switch payment.Status {
+	case domain.PaymentStatusPending:
+		// Charged in full once the inventory is reserved
+
+	case domain.PaymentStatusSucceeded:
+		if delta.IsPositive() {
+			if err := s.chargeDifference(ctx, span, payment, delta); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := s.refundDifference(ctx, span, payment, money.New(-delta.Amount, delta.Currency)); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, errors.NewConflictError("payment can no longer be adjusted")
+	}
+
+	payment.Amount = amount
+	if err := s.repo.UpdateAmount(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).With("booking_id", bookingID).
+		With("payment_id", payment.ID).
+		With("delta", delta.String()).
+		Info("payment adjusted successfully")
+
+	return payment, nil
+}
+
+// chargeDifference charges the increase of a settled booking. A declined
+// charge is reported with payment.failed and leaves the payment as it was.
+func (s *PaymentService) chargeDifference(ctx context.Context, span trace.Span, payment *domain.Payment, delta money.Money) error {
+	result, err := s.provider.Charge(ctx, &provider.ChargeRequest{
+		PaymentID: payment.ID,
+		UserID:    payment.UserID,
+		Amount:    delta,
+	})
+	if err != nil {
+		if !stderrors.Is(err, provider.ErrDeclined) {
+			return errors.NewExternalError(s.provider.Name(), "payment provider unavailable", err)
+		}
+
+		declined := *payment
+		declined.Amount = delta
+		declined.FailureReason = err.Error()
+		s.publishFailed(ctx, span, &declined)
+		return errors.NewConflictError("charging the booking change was declined")
+	}
+
+	event := events.PaymentProcessedEvent{
+		BaseEvent: events.NewBaseEvent(events.PaymentProcessed, "payment-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.PaymentProcessedData{
+			PaymentID:   payment.ID,
+			BookingID:   payment.BookingID,
+			UserID:      payment.UserID,
+			Amount:      delta,
+			Method:      payment.Provider,
+			Status:      result.Reference,
+			ProcessedAt: time.Now().UTC(),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.PaymentProcessed, payment.BookingID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish payment processed event")
+	}
+	return nil
+}
+
+// refundDifference refunds the decrease of a settled booking. The payment
+// stays succeeded for the rest of its amount.
+func (s *PaymentService) refundDifference(ctx context.Context, span trace.Span, payment *domain.Payment, amount money.Money) error {
+	if err := s.provider.Refund(ctx, payment.ProviderRef, amount); err != nil {
+		return errors.NewExternalError(s.provider.Name(), "failed to refund payment", err)
+	}
+
+	event := events.PaymentRefundedEvent{
+		BaseEvent: events.NewBaseEvent(events.PaymentRefunded, "payment-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.PaymentRefundedData{
+			PaymentID:  payment.ID,
+			BookingID:  payment.BookingID,
+			UserID:     payment.UserID,
+			Amount:     amount,
+			RefundedAt: time.Now().UTC(),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.PaymentRefunded, payment.BookingID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish payment refunded event")
+	}
+	return nil
+}
+
+func (s *PaymentService) publishFailed(ctx context.Context, span trace.Span, payment *domain.Payment) {
+	event := events.PaymentFailedEvent{
+		BaseEvent: events.NewBaseEvent(events.PaymentFailed, "payment-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.PaymentFailedData{
+			PaymentID: payment.ID,
+			BookingID: payment.BookingID,
+			UserID:    payment.UserID,
+			Amount:    payment.Amount,
+			Reason:    payment.FailureReason,
+			FailedAt:  payment.UpdatedAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.PaymentFailed, payment.BookingID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish payment failed event")
+	}
+
+	s.logger.WithContext(ctx).With("booking_id", payment.BookingID).With("reason", payment.FailureReason).Warn("payment failed")
+}
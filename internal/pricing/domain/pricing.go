@@ -0,0 +1,261 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+// RateCard prices the bookings of a resource. Whole days are charged at
+// DailyRate when one is set and the remainder at HourlyRate, with the
+// highest matching multiplier applied to each hour. Times are evaluated in
+// UTC, like the resource open hours.
+type RateCard struct {
+	ResourceID  string         `json:"resource_id" db:"resource_id"`
+	Currency    money.Currency `json:"currency" db:"currency"`
+	HourlyRate  float64        `json:"hourly_rate" db:"hourly_rate"`
+	DailyRate   float64        `json:"daily_rate,omitempty" db:"daily_rate"`
+	Multipliers []Multiplier   `json:"multipliers" db:"multipliers"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Multiplier scales the hourly rate of the hours from StartHour up to
+// EndHour on Weekdays, e.g. {"name": "weekend", "weekdays": [0, 6],
+// "factor": 1.5} or {"name": "evening peak", "start_hour": 17,
+// "end_hour": 20, "factor": 1.25}. No weekdays means every day and an
+// EndHour of 0 means the end of the day. Weekdays follow time.Weekday
+// (0 is Sunday).
+type Multiplier struct {
+	Name      string  `json:"name" validate:"required,max=100"`
+	Weekdays  []int   `json:"weekdays,omitempty" validate:"dive,min=0,max=6"`
+	StartHour int     `json:"start_hour" validate:"min=0,max=23"`
+	EndHour   int     `json:"end_hour" validate:"min=0,max=24"`
+	Factor    float64 `json:"factor" validate:"gt=0"`
+}
+
+func (m Multiplier) applies(t time.Time) bool {
+	if len(m.Weekdays) > 0 && !slices.Contains(m.Weekdays, int(t.Weekday())) {
+		return false
+	}
+
+	end := m.EndHour
+	if end == 0 {
+		end = 24
+	}
+	return t.Hour() >= m.StartHour && t.Hour() < end
+}
+
+// PromoCode discounts the subtotal of a quote by PercentOff percent or by
+// AmountOff in Currency. It can be redeemed MaxRedemptions times, or
+// without limit when zero.
+type PromoCode struct {
+	Code           string         `json:"code" db:"code"`
+	PercentOff     float64        `json:"percent_off,omitempty" db:"percent_off"`
+	AmountOff      float64        `json:"amount_off,omitempty" db:"amount_off"`
+	Currency       money.Currency `json:"currency,omitempty" db:"currency"`
+	ValidFrom      *time.Time     `json:"valid_from,omitempty" db:"valid_from"`
+	ValidTo        *time.Time     `json:"valid_to,omitempty" db:"valid_to"`
+	MaxRedemptions int            `json:"max_redemptions,omitempty" db:"max_redemptions"`
+	Redemptions    int            `json:"redemptions" db:"redemptions"`
+	Active         bool           `json:"active" db:"active"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
+
+// Usable reports why the code cannot be applied at the given time to a
+// quote in currency, or "" if it can.
+func (p *PromoCode) Usable(at time.Time, currency money.Currency) string {
+	switch {
+	case !p.Active:
+		return "promo code is no longer active"
+	case p.ValidFrom != nil && at.Before(*p.ValidFrom):
+		return "promo code is not valid yet"
+	case p.ValidTo != nil && !at.Before(*p.ValidTo):
+		return "promo code has expired"
+	case p.MaxRedemptions > 0 && p.Redemptions >= p.MaxRedemptions:
+		return "promo code has been fully redeemed"
+	case p.AmountOff > 0 && p.Currency != currency:
+		return fmt.Sprintf("promo code only applies to %s prices", p.Currency)
+	}
+	return ""
+}
+
+// LineItem is one priced component of a quote.
+type LineItem struct {
+	Description string      `json:"description"`
+	Quantity    float64     `json:"quantity"`
+	Unit        string      `json:"unit"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Amount      money.Money `json:"amount"`
+}
+
+// Quote is the itemized price of booking a resource for a window.
+type Quote struct {
+	ResourceID string      `json:"resource_id"`
+	StartTime  time.Time   `json:"start_time"`
+	EndTime    time.Time   `json:"end_time"`
+	Items      []LineItem  `json:"items"`
+	Subtotal   money.Money `json:"subtotal"`
+	PromoCode  string      `json:"promo_code,omitempty"`
+	Discount   money.Money `json:"discount"`
+	Total      money.Money `json:"total"`
+	// DisplayTotal is the total converted to the requested display
+	// currency. It is informational; bookings are charged Total.
+	DisplayTotal *money.Money `json:"display_total,omitempty"`
+}
+
+type QuoteRequest struct {
+	ResourceID string    `json:"resource_id" validate:"required,uuid"`
+	StartTime  time.Time `json:"start_time" validate:"required"`
+	EndTime    time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+	PromoCode  string    `json:"promo_code,omitempty" validate:"omitempty,max=64"`
+	// DisplayCurrency adds the total converted to this currency.
+	DisplayCurrency string `json:"display_currency,omitempty" validate:"omitempty,currency"`
+}
+
+type UpdateRateCardRequest struct {
+	Currency    string       `json:"currency" validate:"required,currency"`
+	HourlyRate  float64      `json:"hourly_rate" validate:"min=0"`
+	DailyRate   float64      `json:"daily_rate,omitempty" validate:"min=0"`
+	Multipliers []Multiplier `json:"multipliers,omitempty" validate:"max=20,dive"`
+}
+
+type CreatePromoCodeRequest struct {
+	Code           string     `json:"code" validate:"required,min=3,max=64,alphanum"`
+	PercentOff     float64    `json:"percent_off,omitempty" validate:"min=0,max=100"`
+	AmountOff      float64    `json:"amount_off,omitempty" validate:"min=0"`
+	Currency       string     `json:"currency,omitempty" validate:"required_with=AmountOff,omitempty,currency"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidTo        *time.Time `json:"valid_to,omitempty"`
+	MaxRedemptions int        `json:"max_redemptions,omitempty" validate:"min=0"`
+}
+
+// Price itemizes booking the window [start, end) under card. Each hour,
+// or part of an hour, is charged at the hourly rate times the highest
+// multiplier in effect at its start. Whole days are charged at the daily
+// rate instead when that is cheaper than the first day by the hour.
+func (card *RateCard) Price(start, end time.Time) []LineItem {
+	start, end = start.UTC(), end.UTC()
+	items := make([]LineItem, 0)
+
+	if card.DailyRate > 0 {
+		days := int(end.Sub(start) / (24 * time.Hour))
+		if days > 0 && card.DailyRate < card.hourlyTotal(start, start.Add(24*time.Hour)) {
+			daily := money.FromFloat(card.DailyRate, card.Currency)
+			items = append(items, LineItem{
+				Description: "Daily rate",
+				Quantity:    float64(days),
+				Unit:        "day",
+				UnitPrice:   daily,
+				Amount:      daily.Mul(float64(days)),
+			})
+			start = start.AddDate(0, 0, days)
+		}
+	}
+
+	// Group the hours by the multiplier applied to them
+	hours := make(map[string]float64)
+	factors := make(map[string]float64)
+	order := make([]string, 0)
+	card.eachHour(start, end, func(fraction float64, m *Multiplier) {
+		name, factor := "Standard rate", 1.0
+		if m != nil {
+			name, factor = m.Name, m.Factor
+		}
+		if _, ok := hours[name]; !ok {
+			order = append(order, name)
+		}
+		hours[name] += fraction
+		factors[name] = factor
+	})
+
+	hourly := money.FromFloat(card.HourlyRate, card.Currency)
+	for _, name := range order {
+		items = append(items, LineItem{
+			Description: name,
+			Quantity:    math.Round(hours[name]*100) / 100,
+			Unit:        "hour",
+			UnitPrice:   hourly.Mul(factors[name]),
+			Amount:      hourly.Mul(factors[name] * hours[name]),
+		})
+	}
+
+	return items
+}
+
+func (card *RateCard) hourlyTotal(start, end time.Time) float64 {
+	total := 0.0
+	card.eachHour(start, end, func(fraction float64, m *Multiplier) {
+		factor := 1.0
+		if m != nil {
+			factor = m.Factor
+		}
+		total += card.HourlyRate * factor * fraction
+	})
+	return total
+}
+
+// eachHour calls fn with the fraction of every clock hour the window
+// covers and the multiplier in effect, nil for the standard rate.
+func (card *RateCard) eachHour(start, end time.Time, fn func(fraction float64, m *Multiplier)) {
+	for t := start; t.Before(end); {
+		next := t.Truncate(time.Hour).Add(time.Hour)
+		if next.After(end) {
+			next = end
+		}
+		fn(next.Sub(t).Hours(), card.multiplierAt(t))
+		t = next
+	}
+}
+
+func (card *RateCard) multiplierAt(t time.Time) *Multiplier {
+	var best *Multiplier
+	for i := range card.Multipliers {
+		m := &card.Multipliers[i]
+		if m.applies(t) && (best == nil || m.Factor > best.Factor) {
+			best = m
+		}
+	}
+	return best
+}
+
+// Discount returns what the code takes off subtotal, never more than the
+// subtotal itself. Amounts off must be in the subtotal's currency, as
+// Usable checks.
+func (p *PromoCode) Discount(subtotal money.Money) money.Money {
+	discount := money.FromFloat(p.AmountOff, subtotal.Currency)
+	if p.PercentOff > 0 {
+		discount = subtotal.Percent(p.PercentOff)
+	}
+	if discount.Amount > subtotal.Amount {
+		return subtotal
+	}
+	return discount
+}
+
+// NewQuote prices the window [start, end) under card and applies promo,
+// which may be nil.
+func NewQuote(card *RateCard, start, end time.Time, promo *PromoCode) *Quote {
+	q := &Quote{
+		ResourceID: card.ResourceID,
+		StartTime:  start,
+		EndTime:    end,
+		Items:      card.Price(start, end),
+		Subtotal:   money.Zero(card.Currency),
+		Discount:   money.Zero(card.Currency),
+	}
+
+	for _, item := range q.Items {
+		q.Subtotal.Amount += item.Amount.Amount
+	}
+
+	if promo != nil {
+		q.PromoCode = promo.Code
+		q.Discount = promo.Discount(q.Subtotal)
+	}
+	q.Total = money.New(q.Subtotal.Amount-q.Discount.Amount, card.Currency)
+
+	return q
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// PricingService is the application API of the pricing module. The HTTP
+// handler depends on it and service.PricingService implements it.
+type PricingService interface {
+	Quote(ctx context.Context, req *QuoteRequest) (*Quote, error)
+	GetRateCard(ctx context.Context, resourceID string) (*RateCard, error)
+	UpdateRateCard(ctx context.Context, resourceID string, req *UpdateRateCardRequest) (*RateCard, error)
+	CreatePromoCode(ctx context.Context, req *CreatePromoCodeRequest) (*PromoCode, error)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/pricing/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type PricingHandler struct {
+	service domain.PricingService
+	logger  *logger.Logger
+}
+
+func NewPricingHandler(service domain.PricingService, logger *logger.Logger) *PricingHandler {
+	return &PricingHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Quote returns the itemized price of a booking before it is made.
+func (h *PricingHandler) Quote(c *gin.Context) {
+	var req domain.QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	quote, err := h.service.Quote(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, quote)
+}
+
+func (h *PricingHandler) GetRateCard(c *gin.Context) {
+	card, err := h.service.GetRateCard(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, card)
+}
+
+// UpdateRateCard replaces the rate card of a resource. Admins only.
+func (h *PricingHandler) UpdateRateCard(c *gin.Context) {
+	var req domain.UpdateRateCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	card, err := h.service.UpdateRateCard(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, card)
+}
+
+// CreatePromoCode adds a promo code. Admins only.
+func (h *PricingHandler) CreatePromoCode(c *gin.Context) {
+	var req domain.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	promo, err := h.service.CreatePromoCode(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, promo)
+}
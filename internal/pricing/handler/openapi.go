@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/pricing/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the pricing HTTP API for the OpenAPI document. The
+// routes are served by the booking service; keep them in sync with
+// setupRouter in cmd/booking.
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/v1/bookings/quote", Summary: "Price a booking before making it", Tag: "pricing", Auth: true,
+			Request: domain.QuoteRequest{}, Response: domain.Quote{}},
+		{Method: http.MethodGet, Path: "/api/v1/resources/:id/rate-card", Summary: "Get the rate card of a resource", Tag: "pricing", Auth: true,
+			Response: domain.RateCard{}},
+		{Method: http.MethodPut, Path: "/api/v1/resources/:id/rate-card", Summary: "Replace the rate card of a resource", Tag: "pricing", Auth: true, Admin: true,
+			Request: domain.UpdateRateCardRequest{}, Response: domain.RateCard{}},
+		{Method: http.MethodPost, Path: "/api/v1/promo-codes", Summary: "Create a promo code", Tag: "pricing", Auth: true, Admin: true,
+			Request: domain.CreatePromoCodeRequest{}, Response: domain.PromoCode{}, Status: http.StatusCreated},
+	}
+}
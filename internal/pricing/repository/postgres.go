@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/pricing/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresPricingRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresPricingRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresPricingRepository {
+	return &PostgresPricingRepository{db: db, tracer: tracer}
+}
+
+// GetRateCard returns the rate card of an active resource. A resource
+// without one is charged its price_per_hour with no multipliers.
+func (r *PostgresPricingRepository) GetRateCard(ctx context.Context, resourceID string) (_ *domain.RateCard, err error) {
+	ctx, span := r.tracer.Start(ctx, "pricing.repository.get_rate_card", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT r.id,
+			COALESCE(c.currency, r.currency),
+			COALESCE(c.hourly_rate, r.price_per_hour),
+			COALESCE(c.daily_rate, 0),
+			COALESCE(c.multipliers, '[]'),
+			COALESCE(c.updated_at, r.updated_at)
+		FROM resources r
+		LEFT JOIN rate_cards c ON c.resource_id = r.id AND c.tenant_id = r.tenant_id
+		WHERE r.id = $1 AND r.tenant_id = $2 AND r.active = true
+	`
+
+	card := &domain.RateCard{}
+	var multipliers []byte
+	err = r.db.QueryRow(ctx, "pricing.get_rate_card", query, resourceID, tenancy.ID(ctx)).Scan(
+		&card.ResourceID, &card.Currency, &card.HourlyRate, &card.DailyRate,
+		&multipliers, &card.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("resource")
+		}
+		return nil, errors.NewInternalError("failed to get rate card", err)
+	}
+
+	card.Multipliers = make([]domain.Multiplier, 0)
+	if err := json.Unmarshal(multipliers, &card.Multipliers); err != nil {
+		return nil, errors.NewInternalError("failed to decode multipliers", err)
+	}
+
+	return card, nil
+}
+
+// SaveRateCard creates or replaces the rate card of a resource.
+func (r *PostgresPricingRepository) SaveRateCard(ctx context.Context, card *domain.RateCard) (err error) {
+	ctx, span := r.tracer.Start(ctx, "pricing.repository.save_rate_card", trace.WithAttributes(tracing.ResourceID.String(card.ResourceID)))
+	defer tracing.End(span, &err)
+
+	card.UpdatedAt = time.Now().UTC()
+
+	multipliers, err := json.Marshal(card.Multipliers)
+	if err != nil {
+		return errors.NewInternalError("failed to encode multipliers", err)
+	}
+
+	query := `
+		INSERT INTO rate_cards (resource_id, tenant_id, currency, hourly_rate, daily_rate, multipliers, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (resource_id) DO UPDATE SET
+			currency = EXCLUDED.currency,
+			hourly_rate = EXCLUDED.hourly_rate,
+			daily_rate = EXCLUDED.daily_rate,
+			multipliers = EXCLUDED.multipliers,
+			updated_at = EXCLUDED.updated_at
+		WHERE rate_cards.tenant_id = EXCLUDED.tenant_id
+	`
+
+	_, err = r.db.Exec(ctx, "pricing.save_rate_card", query,
+		card.ResourceID, tenancy.ID(ctx), card.Currency, card.HourlyRate, card.DailyRate,
+		multipliers, card.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to save rate card", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresPricingRepository) CreatePromoCode(ctx context.Context, promo *domain.PromoCode) (err error) {
+	ctx, span := r.tracer.Start(ctx, "pricing.repository.create_promo_code")
+	defer tracing.End(span, &err)
+
+	promo.CreatedAt = time.Now().UTC()
+	promo.Active = true
+
+	query := `
+		INSERT INTO promo_codes (
+			tenant_id, code, percent_off, amount_off, currency, valid_from, valid_to,
+			max_redemptions, active, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (tenant_id, code) DO NOTHING
+	`
+
+	result, err := r.db.Exec(ctx, "pricing.create_promo_code", query,
+		tenancy.ID(ctx), promo.Code, promo.PercentOff, promo.AmountOff, promo.Currency, promo.ValidFrom,
+		promo.ValidTo, promo.MaxRedemptions, promo.Active, promo.CreatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create promo code", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewConflictError("promo code already exists")
+	}
+
+	return nil
+}
+
+func (r *PostgresPricingRepository) GetPromoCode(ctx context.Context, code string) (_ *domain.PromoCode, err error) {
+	ctx, span := r.tracer.Start(ctx, "pricing.repository.get_promo_code")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT code, percent_off, amount_off, currency, valid_from, valid_to,
+			max_redemptions, redemptions, active, created_at
+		FROM promo_codes WHERE tenant_id = $1 AND code = $2
+	`
+
+	promo := &domain.PromoCode{}
+	var validFrom, validTo sql.NullTime
+	err = r.db.QueryRow(ctx, "pricing.get_promo_code", query, tenancy.ID(ctx), code).Scan(
+		&promo.Code, &promo.PercentOff, &promo.AmountOff, &promo.Currency, &validFrom,
+		&validTo, &promo.MaxRedemptions, &promo.Redemptions, &promo.Active, &promo.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("promo code")
+		}
+		return nil, errors.NewInternalError("failed to get promo code", err)
+	}
+
+	if validFrom.Valid {
+		promo.ValidFrom = &validFrom.Time
+	}
+	if validTo.Valid {
+		promo.ValidTo = &validTo.Time
+	}
+
+	return promo, nil
+}
+
+// Redeem counts a use of code. It fails with a conflict when the code ran
+// out of redemptions since it was read, so concurrent bookings cannot
+// overdraw it.
+func (r *PostgresPricingRepository) Redeem(ctx context.Context, code string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "pricing.repository.redeem")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE promo_codes SET redemptions = redemptions + 1
+		WHERE tenant_id = $1 AND code = $2 AND active = true
+			AND (max_redemptions = 0 OR redemptions < max_redemptions)
+	`
+
+	result, err := r.db.Exec(ctx, "pricing.redeem", query, tenancy.ID(ctx), code)
+	if err != nil {
+		return errors.NewInternalError("failed to redeem promo code", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewConflictError("promo code has been fully redeemed")
+	}
+
+	return nil
+}
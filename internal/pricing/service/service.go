@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/pricing/domain"
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxQuoteWindow bounds the windows that can be priced, which are
+// evaluated hour by hour.
+const maxQuoteWindow = 366 * 24 * time.Hour
+
+type PricingRepository interface {
+	GetRateCard(ctx context.Context, resourceID string) (*domain.RateCard, error)
+	SaveRateCard(ctx context.Context, card *domain.RateCard) error
+	CreatePromoCode(ctx context.Context, promo *domain.PromoCode) error
+	GetPromoCode(ctx context.Context, code string) (*domain.PromoCode, error)
+	Redeem(ctx context.Context, code string) error
+}
+
+var _ domain.PricingService = (*PricingService)(nil)
+
+type PricingService struct {
+	repo   PricingRepository
+	rates  money.RateProvider
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+// NewPricingService creates the pricing service. rates converts quote
+// totals to display currencies; without it only the resource's own
+// currency is quoted.
+func NewPricingService(repo PricingRepository, rates money.RateProvider, logger *logger.Logger, tracer trace.Tracer) *PricingService {
+	return &PricingService{
+		repo:   repo,
+		rates:  rates,
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+// Quote prices booking a resource for a window, applying the promo code
+// of the request if any. It does not redeem the code.
+func (s *PricingService) Quote(ctx context.Context, req *domain.QuoteRequest) (_ *domain.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "pricing.service.quote", trace.WithAttributes(tracing.ResourceID.String(req.ResourceID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+	if req.EndTime.Sub(req.StartTime) > maxQuoteWindow {
+		return nil, errors.NewValidationError("window is too long to price", nil)
+	}
+
+	card, err := s.repo.GetRateCard(ctx, req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var promo *domain.PromoCode
+	if req.PromoCode != "" {
+		if promo, err = s.promoCode(ctx, req.PromoCode, card.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	quote := domain.NewQuote(card, req.StartTime.UTC(), req.EndTime.UTC(), promo)
+
+	if req.DisplayCurrency != "" {
+		display, err := s.convert(ctx, quote.Total, req.DisplayCurrency)
+		if err != nil {
+			return nil, err
+		}
+		quote.DisplayTotal = &display
+	}
+
+	return quote, nil
+}
+
+func (s *PricingService) convert(ctx context.Context, amount money.Money, currency string) (money.Money, error) {
+	to, err := money.ParseCurrency(currency)
+	if err != nil {
+		return money.Money{}, errors.NewValidationError(err.Error(), nil)
+	}
+	if s.rates == nil && to != amount.Currency {
+		return money.Money{}, errors.NewValidationError("currency conversion is not configured", nil)
+	}
+
+	converted, err := money.Convert(ctx, s.rates, amount, to)
+	if err != nil {
+		return money.Money{}, errors.NewValidationError(err.Error(), nil)
+	}
+	return converted, nil
+}
+
+// promoCode looks up code and checks it can be applied now to a price in
+// currency.
+func (s *PricingService) promoCode(ctx context.Context, code string, currency money.Currency) (*domain.PromoCode, error) {
+	promo, err := s.repo.GetPromoCode(ctx, normalizeCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	if reason := promo.Usable(time.Now().UTC(), currency); reason != "" {
+		return nil, errors.NewValidationError(reason, nil)
+	}
+	return promo, nil
+}
+
+// Redeem counts a use of a promo code applied to a booking.
+func (s *PricingService) Redeem(ctx context.Context, code string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "pricing.service.redeem")
+	defer tracing.End(span, &err)
+
+	return s.repo.Redeem(ctx, normalizeCode(code))
+}
+
+func (s *PricingService) GetRateCard(ctx context.Context, resourceID string) (_ *domain.RateCard, err error) {
+	ctx, span := s.tracer.Start(ctx, "pricing.service.get_rate_card", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetRateCard(ctx, resourceID)
+}
+
+func (s *PricingService) UpdateRateCard(ctx context.Context, resourceID string, req *domain.UpdateRateCardRequest) (_ *domain.RateCard, err error) {
+	ctx, span := s.tracer.Start(ctx, "pricing.service.update_rate_card", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+	for _, m := range req.Multipliers {
+		if m.EndHour != 0 && m.EndHour <= m.StartHour {
+			return nil, errors.NewValidationError("multiplier "+m.Name+" must end after it starts", nil)
+		}
+	}
+
+	// Also confirms the resource exists
+	before, err := s.repo.GetRateCard(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &domain.RateCard{
+		ResourceID:  resourceID,
+		Currency:    money.Currency(strings.ToUpper(req.Currency)),
+		HourlyRate:  req.HourlyRate,
+		DailyRate:   req.DailyRate,
+		Multipliers: req.Multipliers,
+	}
+	if card.Multipliers == nil {
+		card.Multipliers = make([]domain.Multiplier, 0)
+	}
+
+	if err := s.repo.SaveRateCard(ctx, card); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "pricing.update_rate_card", "resource", resourceID, before, card)
+	s.logger.WithContext(ctx).With("resource_id", resourceID).Info("rate card updated")
+
+	return card, nil
+}
+
+func (s *PricingService) CreatePromoCode(ctx context.Context, req *domain.CreatePromoCodeRequest) (_ *domain.PromoCode, err error) {
+	ctx, span := s.tracer.Start(ctx, "pricing.service.create_promo_code")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+	if (req.PercentOff > 0) == (req.AmountOff > 0) {
+		return nil, errors.NewValidationError("exactly one of percent_off and amount_off must be set", nil)
+	}
+	if req.ValidFrom != nil && req.ValidTo != nil && !req.ValidTo.After(*req.ValidFrom) {
+		return nil, errors.NewValidationError("valid_to must be after valid_from", nil)
+	}
+
+	promo := &domain.PromoCode{
+		Code:           normalizeCode(req.Code),
+		PercentOff:     req.PercentOff,
+		AmountOff:      req.AmountOff,
+		Currency:       money.Currency(strings.ToUpper(req.Currency)),
+		ValidFrom:      req.ValidFrom,
+		ValidTo:        req.ValidTo,
+		MaxRedemptions: req.MaxRedemptions,
+	}
+
+	if err := s.repo.CreatePromoCode(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "pricing.create_promo_code", "promo_code", promo.Code, nil, promo)
+	s.logger.WithContext(ctx).With("code", promo.Code).Info("promo code created")
+
+	return promo, nil
+}
+
+// normalizeCode makes promo codes case insensitive.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,77 @@
+package domain
+
+import "time"
+
+const (
+	ResourceTypeRoom      = "room"
+	ResourceTypeDesk      = "desk"
+	ResourceTypeEquipment = "equipment"
+	ResourceTypeVehicle   = "vehicle"
+	ResourceTypeOther     = "other"
+)
+
+type Resource struct {
+	ID            string      `json:"id" db:"id"`
+	Name          string      `json:"name" db:"name"`
+	Type          string      `json:"type" db:"type"`
+	Description   string      `json:"description,omitempty" db:"description"`
+	Location      string      `json:"location,omitempty" db:"location"`
+	Capacity      int         `json:"capacity" db:"capacity"`
+	BufferMinutes int         `json:"buffer_minutes" db:"buffer_minutes"`
+	PricePerHour  float64     `json:"price_per_hour" db:"price_per_hour"`
+	Currency      string      `json:"currency" db:"currency"`
+	OpenHours     []OpenHours `json:"open_hours" db:"open_hours"`
+	Active        bool        `json:"active" db:"active"`
+	// The rating is copied from the review module as reviews come in.
+	RatingAverage float64   `json:"rating_average" db:"rating_average"`
+	RatingCount   int       `json:"rating_count" db:"rating_count"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OpenHours is a daily opening window in the resource's local time, e.g.
+// {"weekday": 1, "open": "09:00", "close": "17:00"}. Weekday follows
+// time.Weekday (0 is Sunday). A resource without open hours is always open.
+type OpenHours struct {
+	Weekday int    `json:"weekday" validate:"min=0,max=6"`
+	Open    string `json:"open" validate:"required,datetime=15:04"`
+	Close   string `json:"close" validate:"required,datetime=15:04"`
+}
+
+type CreateResourceRequest struct {
+	Name          string      `json:"name" validate:"required,min=2,max=200"`
+	Type          string      `json:"type" validate:"required,oneof=room desk equipment vehicle other"`
+	Description   string      `json:"description" validate:"max=2000"`
+	Location      string      `json:"location" validate:"max=500"`
+	Capacity      int         `json:"capacity" validate:"omitempty,min=1"`
+	BufferMinutes int         `json:"buffer_minutes" validate:"min=0,max=1440"`
+	PricePerHour  float64     `json:"price_per_hour" validate:"min=0"`
+	Currency      string      `json:"currency" validate:"omitempty,len=3"`
+	OpenHours     []OpenHours `json:"open_hours" validate:"omitempty,dive"`
+}
+
+// UpdateResourceRequest uses pointers so that zero values such as a zero
+// buffer or a free price can be set explicitly.
+type UpdateResourceRequest struct {
+	Name          *string      `json:"name" validate:"omitempty,min=2,max=200"`
+	Type          *string      `json:"type" validate:"omitempty,oneof=room desk equipment vehicle other"`
+	Description   *string      `json:"description" validate:"omitempty,max=2000"`
+	Location      *string      `json:"location" validate:"omitempty,max=500"`
+	Capacity      *int         `json:"capacity" validate:"omitempty,min=1"`
+	BufferMinutes *int         `json:"buffer_minutes" validate:"omitempty,min=0,max=1440"`
+	PricePerHour  *float64     `json:"price_per_hour" validate:"omitempty,min=0"`
+	Currency      *string      `json:"currency" validate:"omitempty,len=3"`
+	OpenHours     *[]OpenHours `json:"open_hours" validate:"omitempty,dive"`
+}
+
+// ValidOpenHours reports whether every window closes after it opens.
+// Windows that span midnight must be split across two weekdays.
+func ValidOpenHours(hours []OpenHours) bool {
+	for _, h := range hours {
+		// Zero-padded HH:MM strings order lexically
+		if h.Close <= h.Open {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package domain
+
+// SearchQuery narrows a resource search. Text is matched against the name,
+// location and description in that order of weight; zero fields match
+// every active resource.
+type SearchQuery struct {
+	Text        string `validate:"max=200"`
+	Type        string `validate:"omitempty,oneof=room desk equipment vehicle other"`
+	Location    string `validate:"max=500"`
+	MinCapacity int    `validate:"min=0"`
+}
+
+// SearchHit is a resource found by a search with its relevance, which is
+// zero without search text.
+type SearchHit struct {
+	*Resource
+	Rank float64 `json:"rank"`
+}
+
+// FacetCount is the number of resources matching a search that have Value.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchFacets count the matches by type and location. Each facet ignores
+// its own filter, so it lists the alternatives to the value picked.
+type SearchFacets struct {
+	Types     []FacetCount `json:"types"`
+	Locations []FacetCount `json:"locations"`
+}
+
+type SearchResult struct {
+	Hits   []*SearchHit `json:"hits"`
+	Facets SearchFacets `json:"facets"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "context"
+
+// ResourceService is the application API of the resource catalog. The HTTP
+// handler depends on it and service.ResourceService implements it.
+type ResourceService interface {
+	CreateResource(ctx context.Context, req *CreateResourceRequest) (*Resource, error)
+	GetResource(ctx context.Context, id string) (*Resource, error)
+	UpdateResource(ctx context.Context, id string, req *UpdateResourceRequest) (*Resource, error)
+	DeleteResource(ctx context.Context, id string) error
+	ListResources(ctx context.Context, page, pageSize int) ([]*Resource, int64, error)
+	SearchResources(ctx context.Context, query SearchQuery, page, pageSize int) (*SearchResult, int64, error)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/resource/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler adapts review events consumed from Kafka to resource
+// service calls.
+type EventHandler struct {
+	service *service.ResourceService
+	logger  *logger.Logger
+}
+
+func NewEventHandler(service *service.ResourceService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleReviewCreated keeps the catalog's copy of the resource rating
+// current.
+func (h *EventHandler) HandleReviewCreated(ctx context.Context, event events.ReviewCreatedEvent) error {
+	return h.service.UpdateRating(ctx, event.Data.ResourceID, event.Data.AverageRating, event.Data.RatingCount)
+}
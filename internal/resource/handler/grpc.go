@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/resource/domain"
+	"github.com/dmehra2102/booking-system/pkg/grpc/resourcepb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type ResourceGRPCServer struct {
+	resourcepb.UnimplementedResourceServiceServer
+	service domain.ResourceService
+}
+
+func NewResourceGRPCServer(service domain.ResourceService) *ResourceGRPCServer {
+	return &ResourceGRPCServer{service: service}
+}
+
+func (s *ResourceGRPCServer) GetResource(ctx context.Context, req *resourcepb.GetResourceRequest) (*resourcepb.GetResourceResponse, error) {
+	if err := grpcserver.RequireUUID("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	resource, err := s.service.GetResource(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcserver.ToStatus(err)
+	}
+
+	return &resourcepb.GetResourceResponse{
+		Resource: &resourcepb.Resource{
+			Id:            resource.ID,
+			Name:          resource.Name,
+			Type:          resource.Type,
+			Location:      resource.Location,
+			Capacity:      int32(resource.Capacity),
+			BufferMinutes: int32(resource.BufferMinutes),
+			PricePerHour:  resource.PricePerHour,
+			Currency:      resource.Currency,
+			Active:        resource.Active,
+			UpdatedAt:     timestamppb.New(resource.UpdatedAt),
+		},
+	}, nil
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/resource/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ResourceHandler struct {
+	service domain.ResourceService
+	logger  *logger.Logger
+	tracer  trace.Tracer
+}
+
+func NewResourceHandler(service domain.ResourceService, logger *logger.Logger, tracer trace.Tracer) *ResourceHandler {
+	return &ResourceHandler{
+		service: service,
+		logger:  logger,
+		tracer:  tracer,
+	}
+}
+
+func (h *ResourceHandler) CreateResource(c *gin.Context) {
+	var req domain.CreateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	resource, err := h.service.CreateResource(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.CreatedWithLocation(c, path.Join(c.Request.URL.Path, resource.ID), resource)
+}
+
+func (h *ResourceHandler) GetResource(c *gin.Context) {
+	resource, err := h.service.GetResource(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if response.NotModified(c, resourceETag(resource)) {
+		return
+	}
+	response.Success(c, resource)
+}
+
+func (h *ResourceHandler) UpdateResource(c *gin.Context) {
+	var req domain.UpdateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	resource, err := h.service.UpdateResource(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, resource)
+}
+
+func (h *ResourceHandler) DeleteResource(c *gin.Context) {
+	if err := h.service.DeleteResource(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ResourceHandler) ListResources(c *gin.Context) {
+	page, pageSize := pageParams(c)
+
+	resources, total, err := h.service.ListResources(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	parts := []any{page, pageSize, total}
+	for _, resource := range resources {
+		parts = append(parts, resourceETag(resource))
+	}
+	if response.NotModified(c, response.Digest(parts...)) {
+		return
+	}
+	response.Paginated(c, resources, paginationOf(page, pageSize, total))
+}
+
+// SearchResources searches the active resources by the text in q, ranked
+// by relevance, filtered by type, location and min_capacity.
+func (h *ResourceHandler) SearchResources(c *gin.Context) {
+	query := domain.SearchQuery{
+		Text:     c.Query("q"),
+		Type:     c.Query("type"),
+		Location: c.Query("location"),
+	}
+	if v := c.Query("min_capacity"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			response.ValidationError(c, validation.Invalid("min_capacity", "gte", "0"))
+			return
+		}
+		query.MinCapacity = parsed
+	}
+
+	page, pageSize := pageParams(c)
+
+	result, total, err := h.service.SearchResources(c.Request.Context(), query, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, result, paginationOf(page, pageSize, total))
+}
+
+// resourceETag tags a resource by its last update. The rating is copied in
+// without touching updated_at, so it is part of the tag.
+func resourceETag(resource *domain.Resource) string {
+	return response.Digest(resource.ID, resource.UpdatedAt.UnixMicro(), resource.RatingCount, resource.RatingAverage)
+}
+
+func pageParams(c *gin.Context) (int, int) {
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	return page, pageSize
+}
+
+func paginationOf(page, pageSize int, total int64) *response.Pagination {
+	return &response.Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// ResponseCache drops cached responses, as middleware.ResponseCache does.
+type ResponseCache interface {
+	Invalidate(ctx context.Context, scope string) error
+}
+
+// ResponseCacheEventHandler invalidates the cached resource listings when
+// a resource or its rating changes.
+type ResponseCacheEventHandler struct {
+	listings ResponseCache
+}
+
+func NewResponseCacheEventHandler(listings ResponseCache) *ResponseCacheEventHandler {
+	return &ResponseCacheEventHandler{listings: listings}
+}
+
+func (h *ResponseCacheEventHandler) HandleResourceCreated(ctx context.Context, _ events.ResourceCreatedEvent) error {
+	return h.listings.Invalidate(ctx, "")
+}
+
+func (h *ResponseCacheEventHandler) HandleResourceUpdated(ctx context.Context, _ events.ResourceUpdatedEvent) error {
+	return h.listings.Invalidate(ctx, "")
+}
+
+func (h *ResponseCacheEventHandler) HandleResourceDeleted(ctx context.Context, _ events.ResourceDeletedEvent) error {
+	return h.listings.Invalidate(ctx, "")
+}
+
+func (h *ResponseCacheEventHandler) HandleReviewCreated(ctx context.Context, _ events.ReviewCreatedEvent) error {
+	return h.listings.Invalidate(ctx, "")
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/cache"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/resource/domain"
+)
+
+// ResourceRepository is the persistence API decorated by
+// CachedResourceRepository.
+type ResourceRepository interface {
+	Create(ctx context.Context, resource *domain.Resource) error
+	GetByID(ctx context.Context, id string) (*domain.Resource, error)
+	Update(ctx context.Context, id string, updates map[string]any) error
+	UpdateRating(ctx context.Context, id string, average float64, count int) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, limit, offset int) ([]*domain.Resource, int64, error)
+}
+
+// CachedResourceRepository serves GetByID from Redis and invalidates the
+// entry whenever the resource is updated or deleted through it.
+type CachedResourceRepository struct {
+	ResourceRepository
+	cache *cache.Cache[domain.Resource]
+}
+
+func NewCachedResourceRepository(inner ResourceRepository, redis *database.RedisClient, ttl time.Duration, logger *logger.Logger, metrics *metrics.Metrics) *CachedResourceRepository {
+	return &CachedResourceRepository{
+		ResourceRepository: inner,
+		cache:              cache.New[domain.Resource](redis, logger, metrics, "resource", ttl),
+	}
+}
+
+func (r *CachedResourceRepository) GetByID(ctx context.Context, id string) (*domain.Resource, error) {
+	if resource, ok := r.cache.Get(ctx, id); ok {
+		return resource, nil
+	}
+
+	resource, err := r.ResourceRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(ctx, id, resource)
+
+	return resource, nil
+}
+
+func (r *CachedResourceRepository) Update(ctx context.Context, id string, updates map[string]any) error {
+	defer r.cache.Delete(ctx, id)
+	return r.ResourceRepository.Update(ctx, id, updates)
+}
+
+func (r *CachedResourceRepository) UpdateRating(ctx context.Context, id string, average float64, count int) error {
+	defer r.cache.Delete(ctx, id)
+	return r.ResourceRepository.UpdateRating(ctx, id, average, count)
+}
+
+func (r *CachedResourceRepository) Delete(ctx context.Context, id string) error {
+	defer r.cache.Delete(ctx, id)
+	return r.ResourceRepository.Delete(ctx, id)
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/opensearch"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/resource/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxFacetValues bounds the values returned for each facet.
+const maxFacetValues = 50
+
+// OpenSearchResourceSearch searches the resource documents the search
+// indexer keeps in OpenSearch. Hits are read back through the resource
+// repository, so they are as current as the catalog.
+type OpenSearchResourceSearch struct {
+	client    *opensearch.Client
+	index     string
+	resources ResourceRepository
+	tracer    trace.Tracer
+}
+
+func NewOpenSearchResourceSearch(client *opensearch.Client, index string, resources ResourceRepository, tracer trace.Tracer) *OpenSearchResourceSearch {
+	return &OpenSearchResourceSearch{client: client, index: index, resources: resources, tracer: tracer}
+}
+
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			ID    string  `json:"_id"`
+			Score float64 `json:"_score"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Values struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int64  `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"values"`
+	} `json:"aggregations"`
+}
+
+// Search returns a page of the resources matching query, the most
+// relevant first, with the facets of all matches and their total. Like
+// the Postgres search, the type and location filters are applied after
+// the facets are counted, so each facet ignores its own filter.
+func (r *OpenSearchResourceSearch) Search(ctx context.Context, query domain.SearchQuery, limit, offset int) (_ *domain.SearchResult, _ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.opensearch_search")
+	defer tracing.End(span, &err)
+
+	must := []any{map[string]any{"match_all": map[string]any{}}}
+	if query.Text != "" {
+		must = []any{map[string]any{"multi_match": map[string]any{
+			"query":  query.Text,
+			"fields": []string{"name^3", "location.text^2", "description"},
+		}}}
+	}
+	filter := []any{map[string]any{"term": map[string]any{"tenant_id": tenancy.ID(ctx)}}}
+	if query.MinCapacity > 0 {
+		filter = append(filter, map[string]any{"range": map[string]any{"capacity": map[string]any{"gte": query.MinCapacity}}})
+	}
+
+	typeFilter := []any{}
+	if query.Type != "" {
+		typeFilter = append(typeFilter, map[string]any{"term": map[string]any{"type": query.Type}})
+	}
+	locationFilter := []any{}
+	if query.Location != "" {
+		locationFilter = append(locationFilter, map[string]any{"term": map[string]any{
+			"location": map[string]any{"value": query.Location, "case_insensitive": true},
+		}})
+	}
+
+	body := map[string]any{
+		"from":             offset,
+		"size":             limit,
+		"track_total_hits": true,
+		"_source":          false,
+		"query":            map[string]any{"bool": map[string]any{"must": must, "filter": filter}},
+		"post_filter":      map[string]any{"bool": map[string]any{"filter": append(typeFilter, locationFilter...)}},
+		"sort": []any{
+			map[string]any{"_score": "desc"},
+			map[string]any{"name.keyword": "asc"},
+			map[string]any{"id": "asc"},
+		},
+		"aggs": map[string]any{
+			"type":     facetAggregation("type", locationFilter),
+			"location": facetAggregation("location", typeFilter),
+		},
+	}
+
+	var response searchResponse
+	if err := r.client.Search(ctx, r.index, body, &response); err != nil {
+		return nil, 0, errors.NewInternalError("failed to search resources", err)
+	}
+
+	result := &domain.SearchResult{Hits: make([]*domain.SearchHit, 0, len(response.Hits.Hits))}
+	for _, hit := range response.Hits.Hits {
+		resource, err := r.resources.GetByID(ctx, hit.ID)
+		if err != nil {
+			// Deleted since the search index saw it
+			if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+				continue
+			}
+			return nil, 0, err
+		}
+		result.Hits = append(result.Hits, &domain.SearchHit{Resource: resource, Rank: hit.Score})
+	}
+
+	result.Facets.Types = facetCounts(response, "type")
+	result.Facets.Locations = facetCounts(response, "location")
+
+	return result, response.Hits.Total.Value, nil
+}
+
+// facetAggregation counts the values of field among the matches that
+// pass filter.
+func facetAggregation(field string, filter []any) map[string]any {
+	return map[string]any{
+		"filter": map[string]any{"bool": map[string]any{"filter": filter}},
+		"aggs": map[string]any{
+			"values": map[string]any{"terms": map[string]any{
+				"field": field,
+				"size":  maxFacetValues,
+				"order": []any{map[string]any{"_count": "desc"}, map[string]any{"_key": "asc"}},
+			}},
+		},
+	}
+}
+
+// facetCounts reads the counts of the aggregation name. Resources without
+// a location are not counted, as in the Postgres search.
+func facetCounts(response searchResponse, name string) []domain.FacetCount {
+	buckets := response.Aggregations[name].Values.Buckets
+	counts := make([]domain.FacetCount, 0, len(buckets))
+	for _, bucket := range buckets {
+		if bucket.Key == "" {
+			continue
+		}
+		counts = append(counts, domain.FacetCount{Value: bucket.Key, Count: bucket.DocCount})
+	}
+	return counts
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/resource/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresResourceRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresResourceRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresResourceRepository {
+	return &PostgresResourceRepository{db: db, tracer: tracer}
+}
+
+const selectResourceColumns = `id, name, type, description, location, capacity, buffer_minutes,
+		price_per_hour, currency, open_hours, active, rating_average, rating_count,
+		created_at, updated_at`
+
+const selectResourceQuery = `
+	SELECT ` + selectResourceColumns + `
+	FROM resources
+`
+
+// resourceColumns are the columns Update may set.
+var resourceColumns = database.NewColumns(
+	"name", "type", "description", "location", "capacity", "buffer_minutes",
+	"price_per_hour", "currency", "open_hours", "updated_at",
+)
+
+// scanResource scans the selectResourceColumns of row and then extra.
+func scanResource(row database.Scanner, extra ...any) (*domain.Resource, error) {
+	resource := &domain.Resource{}
+	var openHours []byte
+
+	dest := []any{
+		&resource.ID, &resource.Name, &resource.Type, &resource.Description,
+		&resource.Location, &resource.Capacity, &resource.BufferMinutes,
+		&resource.PricePerHour, &resource.Currency, &openHours, &resource.Active,
+		&resource.RatingAverage, &resource.RatingCount, &resource.CreatedAt, &resource.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	resource.OpenHours = make([]domain.OpenHours, 0)
+	if len(openHours) > 0 {
+		if err := json.Unmarshal(openHours, &resource.OpenHours); err != nil {
+			return nil, fmt.Errorf("failed to decode open hours: %w", err)
+		}
+	}
+
+	return resource, nil
+}
+
+func (r *PostgresResourceRepository) Create(ctx context.Context, resource *domain.Resource) (err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.create")
+	defer tracing.End(span, &err)
+
+	resource.ID = uuid.New().String()
+	resource.CreatedAt = time.Now().UTC()
+	resource.UpdatedAt = resource.CreatedAt
+	resource.Active = true
+
+	openHours, err := json.Marshal(resource.OpenHours)
+	if err != nil {
+		return errors.NewInternalError("failed to encode open hours", err)
+	}
+
+	query := `
+		INSERT INTO resources (
+			id, tenant_id, name, type, description, location, capacity, buffer_minutes,
+			price_per_hour, currency, open_hours, active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err = r.db.Exec(ctx, "resource.create", query,
+		resource.ID, tenancy.ID(ctx), resource.Name, resource.Type, resource.Description, resource.Location,
+		resource.Capacity, resource.BufferMinutes, resource.PricePerHour, resource.Currency,
+		openHours, resource.Active, resource.CreatedAt, resource.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create resource", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresResourceRepository) GetByID(ctx context.Context, id string) (_ *domain.Resource, err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.get_by_id", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	resource, err := scanResource(r.db.QueryRow(ctx, "resource.get_by_id", selectResourceQuery+` WHERE id = $1 AND tenant_id = $2 AND active = true`, id, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("resource")
+		}
+		return nil, errors.NewInternalError("failed to get resource", err)
+	}
+
+	return resource, nil
+}
+
+func (r *PostgresResourceRepository) Update(ctx context.Context, id string, updates map[string]any) (err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.update", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	updates["updated_at"] = time.Now().UTC()
+
+	// open_hours is stored as JSONB
+	if hours, ok := updates["open_hours"].([]domain.OpenHours); ok {
+		encoded, err := json.Marshal(hours)
+		if err != nil {
+			return errors.NewInternalError("failed to encode open hours", err)
+		}
+		updates["open_hours"] = encoded
+	}
+
+	var args database.Args
+	set, err := resourceColumns.Set(updates, &args)
+	if err != nil {
+		return errors.NewInternalError("invalid resource update", err)
+	}
+	query := "UPDATE resources SET " + set + " WHERE id = " + args.Add(id) + " AND tenant_id = " + args.Add(tenancy.ID(ctx)) + " AND active = true"
+
+	result, err := r.db.Exec(ctx, "resource.update", query, args...)
+	if err != nil {
+		return errors.NewInternalError("failed to update resource", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check update result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("resource")
+	}
+
+	return nil
+}
+
+// UpdateRating stores the rating of a resource computed from count
+// reviews. Ratings from fewer reviews than the stored one are stale and
+// ignored.
+func (r *PostgresResourceRepository) UpdateRating(ctx context.Context, id string, average float64, count int) (err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.update_rating", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE resources
+		SET rating_average = $1, rating_count = $2
+		WHERE id = $3 AND tenant_id = $4 AND rating_count < $2
+	`
+
+	if _, err := r.db.Exec(ctx, "resource.update_rating", query, average, count, id, tenancy.ID(ctx)); err != nil {
+		return errors.NewInternalError("failed to update resource rating", err)
+	}
+
+	return nil
+}
+
+// Delete deactivates the resource. Rows are kept so existing bookings can
+// still resolve the resource name.
+func (r *PostgresResourceRepository) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.delete", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	query := `UPDATE resources SET active = false, updated_at = $1 WHERE id = $2 AND tenant_id = $3 AND active = true`
+
+	result, err := r.db.Exec(ctx, "resource.delete", query, time.Now().UTC(), id, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to delete resource", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check delete result", err)
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("resource")
+	}
+
+	return nil
+}
+
+func (r *PostgresResourceRepository) List(ctx context.Context, limit, offset int) (_ []*domain.Resource, _ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.list")
+	defer tracing.End(span, &err)
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "resource.count", `SELECT COUNT(*) FROM resources WHERE tenant_id = $1 AND active = true`, tenancy.ID(ctx)).Scan(&total); err != nil {
+		return nil, 0, errors.NewInternalError("failed to count resources", err)
+	}
+
+	query := selectResourceQuery + `
+		WHERE tenant_id = $3 AND active = true
+		ORDER BY name ASC, id ASC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(ctx, "resource.list", query, limit, offset, tenancy.ID(ctx))
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to list resources", err)
+	}
+	resources, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Resource, error) {
+		return scanResource(row)
+	})
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to scan resources", err)
+	}
+
+	return resources, total, nil
+}
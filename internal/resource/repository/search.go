@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/resource/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// textSearchConfig is the Postgres text search configuration of the
+// search_vector column.
+const textSearchConfig = "english"
+
+// PostgresResourceSearch searches resources with Postgres full-text search
+// on the generated search_vector column.
+type PostgresResourceSearch struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresResourceSearch(db *database.PostgresDB, tracer trace.Tracer) *PostgresResourceSearch {
+	return &PostgresResourceSearch{db: db, tracer: tracer}
+}
+
+// searchFilter builds the conditions of a search of the resources of a
+// tenant, with the search text as the first argument. Facets leave out
+// their own filter with skip.
+type searchFilter struct {
+	query  domain.SearchQuery
+	args   database.Args
+	text   string
+	tenant string
+}
+
+func newSearchFilter(ctx context.Context, query domain.SearchQuery) *searchFilter {
+	f := &searchFilter{query: query}
+	if query.Text != "" {
+		f.text = "websearch_to_tsquery('" + textSearchConfig + "', " + f.args.Add(query.Text) + ")"
+	}
+	f.tenant = "tenant_id = " + f.args.Add(tenancy.ID(ctx))
+	return f
+}
+
+func (f *searchFilter) where(skip string) string {
+	conditions := []string{f.tenant, "active = true"}
+	if f.text != "" {
+		conditions = append(conditions, "search_vector @@ "+f.text)
+	}
+	if f.query.Type != "" && skip != "type" {
+		conditions = append(conditions, "type = "+f.args.Add(f.query.Type))
+	}
+	if f.query.Location != "" && skip != "location" {
+		conditions = append(conditions, "lower(location) = lower("+f.args.Add(f.query.Location)+")")
+	}
+	if f.query.MinCapacity > 0 {
+		conditions = append(conditions, "capacity >= "+f.args.Add(f.query.MinCapacity))
+	}
+	return database.Where(conditions)
+}
+
+// Search returns a page of the active resources matching query, the most
+// relevant first, with the facets of all matches and their total.
+func (r *PostgresResourceSearch) Search(ctx context.Context, query domain.SearchQuery, limit, offset int) (_ *domain.SearchResult, _ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "resource.repository.search")
+	defer tracing.End(span, &err)
+
+	filter := newSearchFilter(ctx, query)
+	where := filter.where("")
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "resource.search_count", "SELECT COUNT(*) FROM resources "+where, filter.args...).Scan(&total); err != nil {
+		return nil, 0, errors.NewInternalError("failed to count search results", err)
+	}
+
+	rank := "0::real"
+	if filter.text != "" {
+		rank = "ts_rank(search_vector, " + filter.text + ")"
+	}
+
+	args := filter.args
+	statement := `
+		SELECT ` + selectResourceColumns + `, ` + rank + ` AS rank
+		FROM resources
+		` + where + `
+		ORDER BY rank DESC, name ASC, id ASC
+		LIMIT ` + args.Add(limit) + ` OFFSET ` + args.Add(offset)
+
+	rows, err := r.db.Query(ctx, "resource.search", statement, args...)
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to search resources", err)
+	}
+	hits, err := database.ScanAll(rows, func(row database.Scanner) (*domain.SearchHit, error) {
+		hit := &domain.SearchHit{}
+		resource, err := scanResource(row, &hit.Rank)
+		hit.Resource = resource
+		return hit, err
+	})
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to scan search results", err)
+	}
+
+	result := &domain.SearchResult{Hits: hits}
+	if result.Facets.Types, err = r.facet(ctx, query, "type"); err != nil {
+		return nil, 0, err
+	}
+	if result.Facets.Locations, err = r.facet(ctx, query, "location"); err != nil {
+		return nil, 0, err
+	}
+
+	return result, total, nil
+}
+
+// facet counts the matches of query by column, ignoring the filter on
+// column itself. Resources without a location are not counted.
+func (r *PostgresResourceSearch) facet(ctx context.Context, query domain.SearchQuery, column string) ([]domain.FacetCount, error) {
+	filter := newSearchFilter(ctx, query)
+	statement := `
+		SELECT ` + column + `, COUNT(*)
+		FROM resources
+		` + filter.where(column) + ` AND ` + column + ` <> ''
+		GROUP BY 1
+		ORDER BY 2 DESC, 1 ASC
+	`
+
+	rows, err := r.db.Query(ctx, "resource.search_facet_"+column, statement, filter.args...)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to count search facets", err)
+	}
+	counts, err := database.ScanAll(rows, func(row database.Scanner) (domain.FacetCount, error) {
+		var count domain.FacetCount
+		err := row.Scan(&count.Value, &count.Count)
+		return count, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan search facets", err)
+	}
+
+	return counts, nil
+}
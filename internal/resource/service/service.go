@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/resource/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultCurrency = "USD"
+
+type ResourceRepository interface {
+	Create(ctx context.Context, resource *domain.Resource) error
+	GetByID(ctx context.Context, id string) (*domain.Resource, error)
+	Update(ctx context.Context, id string, updates map[string]any) error
+	UpdateRating(ctx context.Context, id string, average float64, count int) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, limit, offset int) ([]*domain.Resource, int64, error)
+}
+
+// SearchIndex finds resources for SearchResources. It is implemented on
+// Postgres full-text search and on OpenSearch, chosen by SEARCH_BACKEND.
+type SearchIndex interface {
+	Search(ctx context.Context, query domain.SearchQuery, limit, offset int) (*domain.SearchResult, int64, error)
+}
+
+var _ domain.ResourceService = (*ResourceService)(nil)
+
+type ResourceService struct {
+	repo      ResourceRepository
+	search    SearchIndex
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	metrics   *metrics.Metrics
+	tracer    trace.Tracer
+}
+
+func NewResourceService(
+	repo ResourceRepository,
+	search SearchIndex,
+	publisher messagebus.Publisher,
+	logger *logger.Logger,
+	metrics *metrics.Metrics,
+	tracer trace.Tracer,
+) *ResourceService {
+	return &ResourceService{
+		repo:      repo,
+		search:    search,
+		publisher: publisher,
+		logger:    logger,
+		metrics:   metrics,
+		tracer:    tracer,
+	}
+}
+
+func (s *ResourceService) CreateResource(ctx context.Context, req *domain.CreateResourceRequest) (_ *domain.Resource, err error) {
+	ctx, span := s.tracer.Start(ctx, "resource.service.create")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	if !domain.ValidOpenHours(req.OpenHours) {
+		return nil, errors.NewValidationError("open hours must close after they open", nil)
+	}
+
+	resource := &domain.Resource{
+		Name:          req.Name,
+		Type:          req.Type,
+		Description:   req.Description,
+		Location:      req.Location,
+		Capacity:      req.Capacity,
+		BufferMinutes: req.BufferMinutes,
+		PricePerHour:  req.PricePerHour,
+		Currency:      strings.ToUpper(req.Currency),
+		OpenHours:     req.OpenHours,
+	}
+
+	if resource.Capacity == 0 {
+		resource.Capacity = 1
+	}
+	if resource.Currency == "" {
+		resource.Currency = defaultCurrency
+	}
+	if resource.OpenHours == nil {
+		resource.OpenHours = make([]domain.OpenHours, 0)
+	}
+
+	if err := s.repo.Create(ctx, resource); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "resource.create", "resource", resource.ID, nil, resource)
+
+	event := events.ResourceCreatedEvent{
+		BaseEvent: events.NewBaseEvent(events.ResourceCreated, "resource-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data:      toEventData(resource),
+	}
+
+	if err := s.publisher.Publish(ctx, events.ResourceCreated, resource.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish resource created event")
+	}
+
+	s.logger.WithContext(ctx).With("resource_id", resource.ID).Info("resource created successfully")
+
+	return resource, nil
+}
+
+func (s *ResourceService) GetResource(ctx context.Context, id string) (_ *domain.Resource, err error) {
+	ctx, span := s.tracer.Start(ctx, "resource.service.get", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ResourceService) UpdateResource(ctx context.Context, id string, req *domain.UpdateResourceRequest) (_ *domain.Resource, err error) {
+	ctx, span := s.tracer.Start(ctx, "resource.service.update", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	before, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]any)
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Type != nil {
+		updates["type"] = *req.Type
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Location != nil {
+		updates["location"] = *req.Location
+	}
+	if req.Capacity != nil {
+		updates["capacity"] = *req.Capacity
+	}
+	if req.BufferMinutes != nil {
+		updates["buffer_minutes"] = *req.BufferMinutes
+	}
+	if req.PricePerHour != nil {
+		updates["price_per_hour"] = *req.PricePerHour
+	}
+	if req.Currency != nil {
+		updates["currency"] = strings.ToUpper(*req.Currency)
+	}
+	if req.OpenHours != nil {
+		if !domain.ValidOpenHours(*req.OpenHours) {
+			return nil, errors.NewValidationError("open hours must close after they open", nil)
+		}
+		updates["open_hours"] = *req.OpenHours
+	}
+
+	if len(updates) == 0 {
+		return before, nil
+	}
+
+	if err := s.repo.Update(ctx, id, updates); err != nil {
+		return nil, err
+	}
+
+	resource, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "resource.update", "resource", id, before, resource)
+
+	event := events.ResourceUpdatedEvent{
+		BaseEvent: events.NewBaseEvent(events.ResourceUpdated, "resource-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data:      toEventData(resource),
+	}
+
+	if err := s.publisher.Publish(ctx, events.ResourceUpdated, resource.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish resource updated event")
+	}
+
+	s.logger.WithContext(ctx).With("resource_id", id).Info("resource updated successfully")
+
+	return resource, nil
+}
+
+// UpdateRating records the rating of a resource from its reviews. It does
+// not publish resource.updated as the resource itself did not change.
+func (s *ResourceService) UpdateRating(ctx context.Context, id string, average float64, count int) (err error) {
+	ctx, span := s.tracer.Start(ctx, "resource.service.update_rating", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	return s.repo.UpdateRating(ctx, id, average, count)
+}
+
+func (s *ResourceService) DeleteResource(ctx context.Context, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "resource.service.delete", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "resource.delete", "resource", id, nil, nil)
+
+	event := events.ResourceDeletedEvent{
+		BaseEvent: events.NewBaseEvent(events.ResourceDeleted, "resource-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.ResourceDeletedData{
+			ResourceID: id,
+			DeletedAt:  time.Now().UTC(),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.ResourceDeleted, id, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish resource deleted event")
+	}
+
+	s.logger.WithContext(ctx).With("resource_id", id).Info("resource deleted successfully")
+
+	return nil
+}
+
+func (s *ResourceService) ListResources(ctx context.Context, page, pageSize int) (_ []*domain.Resource, _ int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "resource.service.list")
+	defer tracing.End(span, &err)
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.List(ctx, pageSize, offset)
+}
+
+// SearchResources returns a page of the active resources matching query,
+// the most relevant first, with the facets and total of all matches.
+func (s *ResourceService) SearchResources(ctx context.Context, query domain.SearchQuery, page, pageSize int) (_ *domain.SearchResult, _ int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "resource.service.search")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(&query); err != nil {
+		return nil, 0, errors.NewValidationError("validation failed", err)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query.Text = strings.TrimSpace(query.Text)
+	return s.search.Search(ctx, query, pageSize, (page-1)*pageSize)
+}
+
+func toEventData(resource *domain.Resource) events.ResourceData {
+	return events.ResourceData{
+		ResourceID:    resource.ID,
+		Name:          resource.Name,
+		Type:          resource.Type,
+		Description:   resource.Description,
+		Location:      resource.Location,
+		Capacity:      resource.Capacity,
+		BufferMinutes: resource.BufferMinutes,
+		PricePerHour:  resource.PricePerHour,
+		Currency:      resource.Currency,
+		UpdatedAt:     resource.UpdatedAt,
+	}
+}
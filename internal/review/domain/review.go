@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// ReviewSortFields are the fields ListReviews can sort by.
+var ReviewSortFields = []string{"created_at", "rating"}
+
+// Review is a user's rating of a resource they booked. Only completed
+// bookings can be reviewed, each of them once.
+type Review struct {
+	ID         string    `json:"id" db:"id"`
+	BookingID  string    `json:"booking_id" db:"booking_id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	ResourceID string    `json:"resource_id" db:"resource_id"`
+	Rating     int       `json:"rating" db:"rating"`
+	Comment    string    `json:"comment,omitempty" db:"comment"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateReviewRequest struct {
+	BookingID string `json:"booking_id" validate:"required,uuid"`
+	Rating    int    `json:"rating" validate:"required,min=1,max=5"`
+	Comment   string `json:"comment,omitempty" validate:"max=2000"`
+}
+
+// ResourceRating aggregates the reviews of a resource. Resources without
+// reviews have a zero Count and Average.
+type ResourceRating struct {
+	ResourceID string    `json:"resource_id"`
+	Average    float64   `json:"average"`
+	Count      int       `json:"count"`
+	UpdatedAt  time.Time `json:"updated_at,omitzero"`
+}
+
+// NewResourceRating returns the rating of count reviews adding up to sum,
+// averaged to two decimals.
+func NewResourceRating(resourceID string, sum, count int, updatedAt time.Time) *ResourceRating {
+	rating := &ResourceRating{ResourceID: resourceID, Count: count, UpdatedAt: updatedAt}
+	if count > 0 {
+		rating.Average = math.Round(float64(sum)/float64(count)*100) / 100
+	}
+	return rating
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// ReviewService is the application API of the review module. The HTTP
+// handler depends on it and service.ReviewService implements it.
+type ReviewService interface {
+	CreateReview(ctx context.Context, userID string, req *CreateReviewRequest) (*Review, error)
+	ListReviews(ctx context.Context, resourceID string, params pagination.Params) ([]*Review, *pagination.Result, error)
+	GetRating(ctx context.Context, resourceID string) (*ResourceRating, error)
+}
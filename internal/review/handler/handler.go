@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/review/domain"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewHandler struct {
+	service domain.ReviewService
+	logger  *logger.Logger
+}
+
+func NewReviewHandler(service domain.ReviewService, logger *logger.Logger) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateReview rates the resource of one of the caller's completed
+// bookings.
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	var req domain.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	review, err := h.service.CreateReview(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Reviews are only listed per resource; there is no URL of their own
+	// for a Location header
+	response.Created(c, review)
+}
+
+// ListReviews lists the reviews of a resource with page or cursor
+// pagination, newest first unless sorted by created_at or rating.
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	params, err := pagination.FromQuery(c, domain.ReviewSortFields, pagination.Sort{Field: "created_at", Desc: true})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	reviews, result, err := h.service.ListReviews(c.Request.Context(), c.Param("id"), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, reviews, pagination.Response(params, result))
+}
+
+func (h *ReviewHandler) GetRating(c *gin.Context) {
+	rating, err := h.service.GetRating(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, rating)
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/review/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the review HTTP API for the OpenAPI document. The
+// routes are served by the booking service; keep them in sync with
+// setupRouter in cmd/booking.
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/v1/reviews", Summary: "Review a completed booking", Tag: "reviews", Auth: true,
+			Request: domain.CreateReviewRequest{}, Response: domain.Review{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/resources/:id/reviews", Summary: "List the reviews of a resource", Tag: "reviews",
+			Response: domain.Review{}, List: true},
+		{Method: http.MethodGet, Path: "/api/v1/resources/:id/rating", Summary: "Get the rating of a resource", Tag: "reviews",
+			Response: domain.ResourceRating{}},
+	}
+}
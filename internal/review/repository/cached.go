@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/cache"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/review/domain"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// ReviewRepository is the persistence API decorated by
+// CachedReviewRepository.
+type ReviewRepository interface {
+	Create(ctx context.Context, review *domain.Review) (*domain.ResourceRating, error)
+	List(ctx context.Context, resourceID string, params pagination.Params) ([]*domain.Review, *pagination.Result, error)
+	GetRating(ctx context.Context, resourceID string) (*domain.ResourceRating, error)
+}
+
+// CachedReviewRepository serves resource ratings from Redis and invalidates
+// the cached rating whenever a review is created through it.
+type CachedReviewRepository struct {
+	ReviewRepository
+	cache *cache.Cache[domain.ResourceRating]
+}
+
+func NewCachedReviewRepository(inner ReviewRepository, redis *database.RedisClient, ttl time.Duration, logger *logger.Logger, metrics *metrics.Metrics) *CachedReviewRepository {
+	return &CachedReviewRepository{
+		ReviewRepository: inner,
+		cache:            cache.New[domain.ResourceRating](redis, logger, metrics, "resource_rating", ttl),
+	}
+}
+
+func (r *CachedReviewRepository) Create(ctx context.Context, review *domain.Review) (*domain.ResourceRating, error) {
+	defer r.cache.Delete(ctx, review.ResourceID)
+	return r.ReviewRepository.Create(ctx, review)
+}
+
+func (r *CachedReviewRepository) GetRating(ctx context.Context, resourceID string) (*domain.ResourceRating, error) {
+	if rating, ok := r.cache.Get(ctx, resourceID); ok {
+		return rating, nil
+	}
+
+	rating, err := r.ReviewRepository.GetRating(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(ctx, resourceID, rating)
+
+	return rating, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/review/domain"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresReviewRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresReviewRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresReviewRepository {
+	return &PostgresReviewRepository{db: db, tracer: tracer}
+}
+
+// reviewSortColumns maps the sortable fields to their columns and SQL
+// types for keyset comparisons.
+var reviewSortColumns = map[string][2]string{
+	"created_at": {"created_at", "timestamptz"},
+	"rating":     {"rating", "smallint"},
+}
+
+// Create adds a review and counts it in the rating of its resource, which
+// it returns. A booking can only be reviewed once.
+func (r *PostgresReviewRepository) Create(ctx context.Context, review *domain.Review) (_ *domain.ResourceRating, err error) {
+	ctx, span := r.tracer.Start(ctx, "review.repository.create", trace.WithAttributes(tracing.ResourceID.String(review.ResourceID)))
+	defer tracing.End(span, &err)
+
+	review.ID = uuid.New().String()
+	review.CreatedAt = time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO reviews (id, tenant_id, booking_id, user_id, resource_id, rating, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (booking_id) DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		review.ID, tenancy.ID(ctx), review.BookingID, review.UserID, review.ResourceID,
+		review.Rating, review.Comment, review.CreatedAt,
+	)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to create review", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, errors.NewConflictError("booking was already reviewed")
+	}
+
+	query = `
+		INSERT INTO resource_ratings (resource_id, tenant_id, rating_sum, rating_count, updated_at)
+		VALUES ($1, $4, $2, 1, $3)
+		ON CONFLICT (resource_id) DO UPDATE SET
+			rating_sum = resource_ratings.rating_sum + EXCLUDED.rating_sum,
+			rating_count = resource_ratings.rating_count + 1,
+			updated_at = EXCLUDED.updated_at
+		RETURNING rating_sum, rating_count
+	`
+
+	var sum, count int
+	if err := tx.QueryRowContext(ctx, query, review.ResourceID, review.Rating, review.CreatedAt, tenancy.ID(ctx)).Scan(&sum, &count); err != nil {
+		return nil, errors.NewInternalError("failed to update resource rating", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.NewInternalError("failed to commit review", err)
+	}
+
+	return domain.NewResourceRating(review.ResourceID, sum, count, review.CreatedAt), nil
+}
+
+// List returns a page of the reviews of a resource.
+func (r *PostgresReviewRepository) List(ctx context.Context, resourceID string, params pagination.Params) (_ []*domain.Review, _ *pagination.Result, err error) {
+	ctx, span := r.tracer.Start(ctx, "review.repository.list", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	sortField := params.Sort.Field
+	if _, ok := reviewSortColumns[sortField]; !ok {
+		sortField = "created_at"
+	}
+	column := reviewSortColumns[sortField]
+
+	conditions := []string{"resource_id = $1", "tenant_id = $2"}
+	args := []any{resourceID, tenancy.ID(ctx)}
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "review.count", "SELECT COUNT(*) FROM reviews WHERE resource_id = $1 AND tenant_id = $2", args...).Scan(&total); err != nil {
+		return nil, nil, errors.NewInternalError("failed to count reviews", err)
+	}
+
+	if after, afterArgs := params.After(column[0], column[1], "id", len(args)+1); after != "" {
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	query := fmt.Sprintf(`
+		SELECT id, booking_id, user_id, resource_id, rating, comment, created_at
+		FROM reviews
+		WHERE %s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, strings.Join(conditions, " AND "), params.OrderBy(column[0], "id"), len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, "review.list", query, append(args, params.PageSize+1, params.Offset())...)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to list reviews", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]*domain.Review, 0)
+	for rows.Next() {
+		review := &domain.Review{}
+		err := rows.Scan(
+			&review.ID, &review.BookingID, &review.UserID, &review.ResourceID,
+			&review.Rating, &review.Comment, &review.CreatedAt,
+		)
+		if err != nil {
+			return nil, nil, errors.NewInternalError("failed to scan review", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.NewInternalError("failed to iterate reviews", err)
+	}
+
+	result := &pagination.Result{Total: total}
+	if len(reviews) > params.PageSize {
+		reviews = reviews[:params.PageSize]
+		last := reviews[len(reviews)-1]
+		result.NextCursor = params.Next(reviewSortValue(last, sortField), last.ID)
+	}
+
+	return reviews, result, nil
+}
+
+func reviewSortValue(review *domain.Review, field string) string {
+	if field == "rating" {
+		return strconv.Itoa(review.Rating)
+	}
+	return review.CreatedAt.Format(time.RFC3339Nano)
+}
+
+// GetRating returns the rating of a resource, which is zero until it is
+// first reviewed.
+func (r *PostgresReviewRepository) GetRating(ctx context.Context, resourceID string) (_ *domain.ResourceRating, err error) {
+	ctx, span := r.tracer.Start(ctx, "review.repository.get_rating", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	query := `SELECT rating_sum, rating_count, updated_at FROM resource_ratings WHERE resource_id = $1 AND tenant_id = $2`
+
+	var sum, count int
+	var updatedAt time.Time
+	err = r.db.QueryRow(ctx, "review.get_rating", query, resourceID, tenancy.ID(ctx)).Scan(&sum, &count, &updatedAt)
+	if err == sql.ErrNoRows {
+		return domain.NewResourceRating(resourceID, 0, 0, time.Time{}), nil
+	}
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get resource rating", err)
+	}
+
+	return domain.NewResourceRating(resourceID, sum, count, updatedAt), nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+
+	bookingdomain "github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/review/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *domain.Review) (*domain.ResourceRating, error)
+	List(ctx context.Context, resourceID string, params pagination.Params) ([]*domain.Review, *pagination.Result, error)
+	GetRating(ctx context.Context, resourceID string) (*domain.ResourceRating, error)
+}
+
+// BookingReader looks up the booking a review is for.
+type BookingReader interface {
+	GetBooking(ctx context.Context, id string) (*bookingdomain.Booking, error)
+}
+
+var _ domain.ReviewService = (*ReviewService)(nil)
+
+type ReviewService struct {
+	repo      ReviewRepository
+	bookings  BookingReader
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	tracer    trace.Tracer
+}
+
+func NewReviewService(repo ReviewRepository, bookings BookingReader, publisher messagebus.Publisher, logger *logger.Logger, tracer trace.Tracer) *ReviewService {
+	return &ReviewService{
+		repo:      repo,
+		bookings:  bookings,
+		publisher: publisher,
+		logger:    logger,
+		tracer:    tracer,
+	}
+}
+
+// CreateReview rates the resource of one of the user's completed bookings
+// and publishes review.created with the updated rating of the resource.
+func (s *ReviewService) CreateReview(ctx context.Context, userID string, req *domain.CreateReviewRequest) (_ *domain.Review, err error) {
+	ctx, span := s.tracer.Start(ctx, "review.service.create", trace.WithAttributes(
+		tracing.UserID.String(userID),
+		tracing.BookingID.String(req.BookingID),
+	))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	booking, err := s.bookings.GetBooking(ctx, req.BookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other users' bookings are not disclosed
+	if booking.UserID != userID {
+		return nil, errors.NewNotFoundError("booking")
+	}
+	if booking.Status != bookingdomain.BookingStatusCompleted {
+		return nil, errors.NewConflictError("only completed bookings can be reviewed")
+	}
+
+	review := &domain.Review{
+		BookingID:  booking.ID,
+		UserID:     userID,
+		ResourceID: booking.ResourceID,
+		Rating:     req.Rating,
+		Comment:    req.Comment,
+	}
+
+	rating, err := s.repo.Create(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "review.create", "review", review.ID, nil, review)
+
+	event := events.ReviewCreatedEvent{
+		BaseEvent: events.NewBaseEvent(events.ReviewCreated, "booking-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.ReviewCreatedData{
+			ReviewID:      review.ID,
+			BookingID:     review.BookingID,
+			UserID:        review.UserID,
+			ResourceID:    review.ResourceID,
+			Rating:        review.Rating,
+			AverageRating: rating.Average,
+			RatingCount:   rating.Count,
+			CreatedAt:     review.CreatedAt,
+		},
+	}
+
+	// Keyed by resource so the catalog receives its ratings in order
+	if err := s.publisher.Publish(ctx, events.ReviewCreated, review.ResourceID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish review created event")
+	}
+
+	s.logger.WithContext(ctx).With("review_id", review.ID).Info("review created successfully")
+
+	return review, nil
+}
+
+func (s *ReviewService) ListReviews(ctx context.Context, resourceID string, params pagination.Params) (_ []*domain.Review, _ *pagination.Result, err error) {
+	ctx, span := s.tracer.Start(ctx, "review.service.list", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	params.Normalize()
+	return s.repo.List(ctx, resourceID, params)
+}
+
+func (s *ReviewService) GetRating(ctx context.Context, resourceID string) (_ *domain.ResourceRating, err error) {
+	ctx, span := s.tracer.Start(ctx, "review.service.get_rating", trace.WithAttributes(tracing.ResourceID.String(resourceID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetRating(ctx, resourceID)
+}
@@ -0,0 +1,134 @@
+// Package domain holds the documents the search indexer keeps in
+// OpenSearch. They are denormalized: a booking carries the name of its
+// user and resource, so bookings can be searched by them.
+package domain
+
+import "time"
+
+// Indexes are the names of the search indexes, all starting with a common
+// prefix so several deployments can share a cluster.
+type Indexes struct {
+	Resources string
+	Bookings  string
+	Users     string
+}
+
+func NewIndexes(prefix string) Indexes {
+	return Indexes{
+		Resources: prefix + "-resources",
+		Bookings:  prefix + "-bookings",
+		Users:     prefix + "-users",
+	}
+}
+
+// Documents carry their tenant, which searches filter on.
+type ResourceDocument struct {
+	ID            string    `json:"id"`
+	TenantID      string    `json:"tenant_id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Description   string    `json:"description"`
+	Location      string    `json:"location"`
+	Capacity      int       `json:"capacity"`
+	BufferMinutes int       `json:"buffer_minutes"`
+	PricePerHour  float64   `json:"price_per_hour"`
+	Currency      string    `json:"currency"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type UserDocument struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookingDocument copies the user's name and email and the resource's
+// name, type and location, which are updated along with the user and
+// resource documents.
+type BookingDocument struct {
+	ID               string    `json:"id"`
+	TenantID         string    `json:"tenant_id"`
+	UserID           string    `json:"user_id"`
+	UserName         string    `json:"user_name,omitempty"`
+	UserEmail        string    `json:"user_email,omitempty"`
+	ResourceID       string    `json:"resource_id"`
+	ResourceName     string    `json:"resource_name,omitempty"`
+	ResourceType     string    `json:"resource_type,omitempty"`
+	ResourceLocation string    `json:"resource_location,omitempty"`
+	StartTime        time.Time `json:"start_time"`
+	EndTime          time.Time `json:"end_time"`
+	Status           string    `json:"status"`
+	Amount           int64     `json:"amount"`
+	Currency         string    `json:"currency"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Mappings returns the bodies the indexes are created with, by index
+// name. Text fields also keep a keyword for sorting and facets.
+func (i Indexes) Mappings() map[string]map[string]any {
+	return map[string]map[string]any{
+		i.Resources: mapping(map[string]any{
+			"id":             keyword,
+			"tenant_id":      keyword,
+			"name":           textWithKeyword,
+			"type":           keyword,
+			"description":    text,
+			"location":       keywordWithText,
+			"capacity":       map[string]any{"type": "integer"},
+			"buffer_minutes": map[string]any{"type": "integer"},
+			"price_per_hour": map[string]any{"type": "double"},
+			"currency":       keyword,
+			"updated_at":     date,
+		}),
+		i.Bookings: mapping(map[string]any{
+			"id":                keyword,
+			"tenant_id":         keyword,
+			"user_id":           keyword,
+			"user_name":         textWithKeyword,
+			"user_email":        keyword,
+			"resource_id":       keyword,
+			"resource_name":     textWithKeyword,
+			"resource_type":     keyword,
+			"resource_location": keywordWithText,
+			"start_time":        date,
+			"end_time":          date,
+			"status":            keyword,
+			"amount":            map[string]any{"type": "long"},
+			"currency":          keyword,
+			"updated_at":        date,
+		}),
+		i.Users: mapping(map[string]any{
+			"id":         keyword,
+			"tenant_id":  keyword,
+			"email":      keyword,
+			"name":       textWithKeyword,
+			"updated_at": date,
+		}),
+	}
+}
+
+var (
+	keyword = map[string]any{"type": "keyword"}
+	text    = map[string]any{"type": "text", "analyzer": "english"}
+	date    = map[string]any{"type": "date"}
+
+	textWithKeyword = map[string]any{
+		"type": "text", "analyzer": "english",
+		"fields": map[string]any{"keyword": keyword},
+	}
+	keywordWithText = map[string]any{
+		"type":   "keyword",
+		"fields": map[string]any{"text": text},
+	}
+)
+
+func mapping(properties map[string]any) map[string]any {
+	return map[string]any{
+		"mappings": map[string]any{
+			"dynamic":    "strict",
+			"properties": properties,
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/search/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// Booking statuses set by the events that carry no status of their own.
+const (
+	statusConfirmed  = "confirmed"
+	statusInProgress = "in_progress"
+	statusCancelled  = "cancelled"
+	statusCompleted  = "completed"
+	statusNoShow     = "no_show"
+)
+
+// EventHandler adapts the resource, user and booking events consumed from
+// Kafka to indexer calls.
+type EventHandler struct {
+	service *service.IndexerService
+	logger  *logger.Logger
+}
+
+func NewEventHandler(service *service.IndexerService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Register adds the handlers of the events the indexer keeps the search
+// indexes current with to d.
+func (h *EventHandler) Register(d *events.Dispatcher) {
+	events.On(d, events.ResourceCreated, h.HandleResourceCreated)
+	events.On(d, events.ResourceUpdated, h.HandleResourceUpdated)
+	events.On(d, events.ResourceDeleted, h.HandleResourceDeleted)
+	events.On(d, events.UserCreated, h.HandleUserCreated)
+	events.On(d, events.UserUpdated, h.HandleUserUpdated)
+	events.On(d, events.UserDeleted, h.HandleUserDeleted)
+	events.On(d, events.BookingRequested, h.HandleBookingRequested)
+	events.On(d, events.BookingConfirmed, h.HandleBookingConfirmed)
+	events.On(d, events.BookingUpdated, h.HandleBookingUpdated)
+	events.On(d, events.BookingCancelled, h.HandleBookingCancelled)
+	events.On(d, events.BookingCheckedIn, h.HandleBookingCheckedIn)
+	events.On(d, events.BookingCheckedOut, h.HandleBookingCheckedOut)
+	events.On(d, events.BookingNoShow, h.HandleBookingNoShow)
+}
+
+func (h *EventHandler) HandleResourceCreated(ctx context.Context, event events.ResourceCreatedEvent) error {
+	return h.service.IndexResource(ctx, event.Data)
+}
+
+func (h *EventHandler) HandleResourceUpdated(ctx context.Context, event events.ResourceUpdatedEvent) error {
+	return h.service.IndexResource(ctx, event.Data)
+}
+
+func (h *EventHandler) HandleResourceDeleted(ctx context.Context, event events.ResourceDeletedEvent) error {
+	return h.service.RemoveResource(ctx, event.Data.ResourceID)
+}
+
+func (h *EventHandler) HandleUserCreated(ctx context.Context, event events.UserCreatedEvent) error {
+	return h.service.IndexUser(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, event.Data.CreatedAt)
+}
+
+func (h *EventHandler) HandleUserUpdated(ctx context.Context, event events.UserUpdatedEvent) error {
+	return h.service.IndexUser(ctx, event.Data.UserID, event.Data.Email, event.Data.Name, event.Data.UpdatedAt)
+}
+
+func (h *EventHandler) HandleUserDeleted(ctx context.Context, event events.UserDeletedEvent) error {
+	return h.service.RemoveUser(ctx, event.Data.UserID)
+}
+
+func (h *EventHandler) HandleBookingRequested(ctx context.Context, event events.BookingRequestedEvent) error {
+	return h.service.IndexBooking(ctx, event.Data, event.Timestamp)
+}
+
+// HandleBookingConfirmed also sets the window and amount, so a booking is
+// searchable by them if its booking.requested is still to be indexed.
+func (h *EventHandler) HandleBookingConfirmed(ctx context.Context, event events.BookingConfirmedEvent) error {
+	return h.service.UpdateBooking(ctx, event.Data.BookingID, map[string]any{
+		"user_id":     event.Data.UserID,
+		"resource_id": event.Data.ResourceID,
+		"start_time":  event.Data.StartTime,
+		"end_time":    event.Data.EndTime,
+		"amount":      event.Data.Amount.Amount,
+		"currency":    string(event.Data.Amount.Currency),
+		"status":      statusConfirmed,
+	}, event.Data.ConfirmedAt)
+}
+
+func (h *EventHandler) HandleBookingUpdated(ctx context.Context, event events.BookingUpdatedEvent) error {
+	fields := map[string]any{
+		"start_time": event.Data.StartTime,
+		"end_time":   event.Data.EndTime,
+		"status":     event.Data.Status,
+	}
+	// Events upcast from 1.0 carry no amount
+	if event.Data.Amount.Currency != "" {
+		fields["amount"] = event.Data.Amount.Amount
+		fields["currency"] = string(event.Data.Amount.Currency)
+	}
+
+	return h.service.UpdateBooking(ctx, event.Data.BookingID, fields, event.Data.UpdatedAt)
+}
+
+func (h *EventHandler) HandleBookingCancelled(ctx context.Context, event events.BookingCancelledEvent) error {
+	return h.service.UpdateBooking(ctx, event.Data.BookingID, map[string]any{"status": statusCancelled}, event.Data.CancelledAt)
+}
+
+func (h *EventHandler) HandleBookingCheckedIn(ctx context.Context, event events.BookingCheckedInEvent) error {
+	return h.service.UpdateBooking(ctx, event.Data.BookingID, map[string]any{"status": statusInProgress}, event.Data.CheckedInAt)
+}
+
+func (h *EventHandler) HandleBookingCheckedOut(ctx context.Context, event events.BookingCheckedOutEvent) error {
+	return h.service.UpdateBooking(ctx, event.Data.BookingID, map[string]any{"status": statusCompleted}, event.Data.CheckedOutAt)
+}
+
+func (h *EventHandler) HandleBookingNoShow(ctx context.Context, event events.BookingNoShowEvent) error {
+	return h.service.UpdateBooking(ctx, event.Data.BookingID, map[string]any{"status": statusNoShow}, event.Data.MarkedAt)
+}
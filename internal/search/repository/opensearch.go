@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/opensearch"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/search/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OpenSearchIndexRepository writes the search documents. Changes to a user
+// or resource are copied to their bookings.
+type OpenSearchIndexRepository struct {
+	client  *opensearch.Client
+	indexes domain.Indexes
+	tracer  trace.Tracer
+}
+
+func NewOpenSearchIndexRepository(client *opensearch.Client, indexes domain.Indexes, tracer trace.Tracer) *OpenSearchIndexRepository {
+	return &OpenSearchIndexRepository{client: client, indexes: indexes, tracer: tracer}
+}
+
+// CreateIndexes creates the indexes that do not exist yet and adds new
+// fields to the mappings of the existing ones.
+func (r *OpenSearchIndexRepository) CreateIndexes(ctx context.Context) (err error) {
+	ctx, span := r.tracer.Start(ctx, "search.repository.create_indexes")
+	defer tracing.End(span, &err)
+
+	for index, body := range r.indexes.Mappings() {
+		if err := r.client.CreateIndex(ctx, index, body); err != nil {
+			return wrap("failed to create search index "+index, err)
+		}
+		if err := r.client.PutMapping(ctx, index, body["mappings"]); err != nil {
+			return wrap("failed to update the mapping of search index "+index, err)
+		}
+	}
+	return nil
+}
+
+func (r *OpenSearchIndexRepository) PutResource(ctx context.Context, doc *domain.ResourceDocument) (err error) {
+	ctx, span := r.tracer.Start(ctx, "search.repository.put_resource", trace.WithAttributes(tracing.ResourceID.String(doc.ID)))
+	defer tracing.End(span, &err)
+
+	if err := r.client.Put(ctx, r.indexes.Resources, doc.ID, doc); err != nil {
+		return wrap("failed to index resource", err)
+	}
+
+	err = r.client.UpdateByQuery(ctx, r.indexes.Bookings, term("resource_id", doc.ID), `
+		ctx._source.resource_name = params.name;
+		ctx._source.resource_type = params.type;
+		ctx._source.resource_location = params.location;
+	`, map[string]any{"name": doc.Name, "type": doc.Type, "location": doc.Location})
+	if err != nil {
+		return wrap("failed to update the resource of bookings", err)
+	}
+
+	return nil
+}
+
+func (r *OpenSearchIndexRepository) DeleteResource(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "search.repository.delete_resource", trace.WithAttributes(tracing.ResourceID.String(id)))
+	defer tracing.End(span, &err)
+
+	if err := r.client.Delete(ctx, r.indexes.Resources, id); err != nil {
+		return wrap("failed to delete resource from the search index", err)
+	}
+	return nil
+}
+
+func (r *OpenSearchIndexRepository) PutUser(ctx context.Context, doc *domain.UserDocument) (err error) {
+	ctx, span := r.tracer.Start(ctx, "search.repository.put_user", trace.WithAttributes(tracing.UserID.String(doc.ID)))
+	defer tracing.End(span, &err)
+
+	if err := r.client.Put(ctx, r.indexes.Users, doc.ID, doc); err != nil {
+		return wrap("failed to index user", err)
+	}
+
+	err = r.client.UpdateByQuery(ctx, r.indexes.Bookings, term("user_id", doc.ID), `
+		ctx._source.user_name = params.name;
+		ctx._source.user_email = params.email;
+	`, map[string]any{"name": doc.Name, "email": doc.Email})
+	if err != nil {
+		return wrap("failed to update the user of bookings", err)
+	}
+
+	return nil
+}
+
+func (r *OpenSearchIndexRepository) DeleteUser(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "search.repository.delete_user", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
+
+	if err := r.client.Delete(ctx, r.indexes.Users, id); err != nil {
+		return wrap("failed to delete user from the search index", err)
+	}
+	return nil
+}
+
+// CreateBooking indexes a new booking with the name of its user and
+// resource. Events of a booking come from several topics, so a status
+// change may have been indexed before: it is kept rather than replaced by
+// the status doc was created with.
+func (r *OpenSearchIndexRepository) CreateBooking(ctx context.Context, doc *domain.BookingDocument) (err error) {
+	ctx, span := r.tracer.Start(ctx, "search.repository.create_booking", trace.WithAttributes(tracing.BookingID.String(doc.ID)))
+	defer tracing.End(span, &err)
+
+	var user domain.UserDocument
+	if err := r.client.Get(ctx, r.indexes.Users, doc.UserID, &user); err != nil && !stderrors.Is(err, opensearch.ErrNotFound) {
+		return wrap("failed to get the user of a booking", err)
+	}
+	doc.UserName, doc.UserEmail = user.Name, user.Email
+
+	var resource domain.ResourceDocument
+	if err := r.client.Get(ctx, r.indexes.Resources, doc.ResourceID, &resource); err != nil && !stderrors.Is(err, opensearch.ErrNotFound) {
+		return wrap("failed to get the resource of a booking", err)
+	}
+	doc.ResourceName, doc.ResourceType, doc.ResourceLocation = resource.Name, resource.Type, resource.Location
+
+	fields := map[string]any{
+		"user_id": doc.UserID, "user_name": doc.UserName, "user_email": doc.UserEmail,
+		"resource_id": doc.ResourceID, "resource_name": doc.ResourceName,
+		"resource_type": doc.ResourceType, "resource_location": doc.ResourceLocation,
+	}
+	if err := r.client.Update(ctx, r.indexes.Bookings, doc.ID, fields, doc); err != nil {
+		return wrap("failed to index booking", err)
+	}
+	return nil
+}
+
+// UpdateBooking sets fields of the booking id, indexing it with only these
+// fields if it is not indexed yet.
+func (r *OpenSearchIndexRepository) UpdateBooking(ctx context.Context, id string, fields map[string]any) (err error) {
+	ctx, span := r.tracer.Start(ctx, "search.repository.update_booking", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	fields["id"] = id
+	if err := r.client.Update(ctx, r.indexes.Bookings, id, fields, nil); err != nil {
+		return wrap("failed to update indexed booking", err)
+	}
+	return nil
+}
+
+func term(field, value string) map[string]any {
+	return map[string]any{"term": map[string]any{field: value}}
+}
+
+// wrap classifies err for the Kafka consumer: requests OpenSearch rejects
+// as invalid are not retried.
+func wrap(message string, err error) error {
+	var osErr *opensearch.Error
+	if stderrors.As(err, &osErr) && osErr.Status == http.StatusBadRequest {
+		return errors.NewValidationError(message, err)
+	}
+	return errors.NewInternalError(message, err)
+}
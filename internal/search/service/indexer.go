@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/search/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type IndexRepository interface {
+	CreateIndexes(ctx context.Context) error
+	PutResource(ctx context.Context, doc *domain.ResourceDocument) error
+	DeleteResource(ctx context.Context, id string) error
+	PutUser(ctx context.Context, doc *domain.UserDocument) error
+	DeleteUser(ctx context.Context, id string) error
+	CreateBooking(ctx context.Context, doc *domain.BookingDocument) error
+	UpdateBooking(ctx context.Context, id string, fields map[string]any) error
+}
+
+// IndexerService keeps the search documents current with the resource,
+// user and booking events.
+type IndexerService struct {
+	repo   IndexRepository
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+func NewIndexerService(repo IndexRepository, logger *logger.Logger, tracer trace.Tracer) *IndexerService {
+	return &IndexerService{repo: repo, logger: logger, tracer: tracer}
+}
+
+// Setup creates the missing indexes. It is called before events are
+// consumed.
+func (s *IndexerService) Setup(ctx context.Context) error {
+	return s.repo.CreateIndexes(ctx)
+}
+
+func (s *IndexerService) IndexResource(ctx context.Context, data events.ResourceData) (err error) {
+	ctx, span := s.tracer.Start(ctx, "search.service.index_resource", trace.WithAttributes(tracing.ResourceID.String(data.ResourceID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.PutResource(ctx, &domain.ResourceDocument{
+		ID:            data.ResourceID,
+		TenantID:      tenancy.ID(ctx),
+		Name:          data.Name,
+		Type:          data.Type,
+		Description:   data.Description,
+		Location:      data.Location,
+		Capacity:      data.Capacity,
+		BufferMinutes: data.BufferMinutes,
+		PricePerHour:  data.PricePerHour,
+		Currency:      data.Currency,
+		UpdatedAt:     data.UpdatedAt,
+	})
+}
+
+func (s *IndexerService) RemoveResource(ctx context.Context, id string) error {
+	return s.repo.DeleteResource(ctx, id)
+}
+
+func (s *IndexerService) IndexUser(ctx context.Context, id, email, name string, updatedAt time.Time) (err error) {
+	ctx, span := s.tracer.Start(ctx, "search.service.index_user", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
+
+	return s.repo.PutUser(ctx, &domain.UserDocument{ID: id, TenantID: tenancy.ID(ctx), Email: email, Name: name, UpdatedAt: updatedAt})
+}
+
+func (s *IndexerService) RemoveUser(ctx context.Context, id string) error {
+	return s.repo.DeleteUser(ctx, id)
+}
+
+// IndexBooking indexes a requested booking with its user and resource.
+func (s *IndexerService) IndexBooking(ctx context.Context, data events.BookingRequestedData, requestedAt time.Time) (err error) {
+	ctx, span := s.tracer.Start(ctx, "search.service.index_booking", trace.WithAttributes(tracing.BookingID.String(data.BookingID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.CreateBooking(ctx, &domain.BookingDocument{
+		ID:         data.BookingID,
+		TenantID:   tenancy.ID(ctx),
+		UserID:     data.UserID,
+		ResourceID: data.ResourceID,
+		StartTime:  data.StartTime,
+		EndTime:    data.EndTime,
+		Status:     data.Status,
+		Amount:     data.Amount.Amount,
+		Currency:   string(data.Amount.Currency),
+		UpdatedAt:  requestedAt,
+	})
+}
+
+// UpdateBooking sets the fields of a booking that changed, e.g. its
+// status or its window. Bookings are matched to their user and resource
+// only once booking.requested is indexed.
+func (s *IndexerService) UpdateBooking(ctx context.Context, id string, fields map[string]any, at time.Time) (err error) {
+	ctx, span := s.tracer.Start(ctx, "search.service.update_booking", trace.WithAttributes(tracing.BookingID.String(id)))
+	defer tracing.End(span, &err)
+
+	fields["tenant_id"] = tenancy.ID(ctx)
+	fields["updated_at"] = at
+	return s.repo.UpdateBooking(ctx, id, fields)
+}
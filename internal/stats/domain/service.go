@@ -0,0 +1,13 @@
+package domain
+
+import "context"
+
+// StatsService is the admin statistics API. The HTTP handler depends on
+// it and service.StatsService implements it.
+type StatsService interface {
+	Bookings(ctx context.Context, window Window) (*BookingStats, error)
+	Revenue(ctx context.Context, window Window) (*RevenueStats, error)
+	Cancellations(ctx context.Context, window Window) (*CancellationStats, error)
+	Users(ctx context.Context, window Window) (*UserStats, error)
+	Occupancy(ctx context.Context, window Window) (*OccupancyStats, error)
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"time"
+
+	bookingdomain "github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+// MaxWindow bounds the windows statistics are computed for.
+const MaxWindow = 366 * 24 * time.Hour
+
+// DateFormat is the layout of the days statistics are grouped by, in UTC.
+const DateFormat = "2006-01-02"
+
+// OccupyingStatuses are the statuses of bookings that used or will use
+// their slot, counted for revenue and occupancy.
+var OccupyingStatuses = []bookingdomain.BookingStatus{
+	bookingdomain.BookingStatusConfirmed,
+	bookingdomain.BookingStatusInProgress,
+	bookingdomain.BookingStatusCompleted,
+}
+
+// Window is the [From, To) period statistics are computed for.
+type Window struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Days returns the UTC dates the window touches, in order.
+func (w Window) Days() []string {
+	days := make([]string, 0)
+	for day := w.From.UTC().Truncate(24 * time.Hour); day.Before(w.To); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(DateFormat))
+	}
+	return days
+}
+
+// BookingDay counts the bookings made on a day, in total and by their
+// current status.
+type BookingDay struct {
+	Date     string           `json:"date"`
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"by_status"`
+}
+
+type BookingStats struct {
+	Window Window        `json:"window"`
+	Days   []*BookingDay `json:"days"`
+}
+
+// ResourceRevenue is what the bookings of a resource starting within the
+// window earned in one currency: the amount of the bookings that were
+// used or are still to be used, and the fees of cancelled ones.
+type ResourceRevenue struct {
+	ResourceID       string      `json:"resource_id"`
+	ResourceName     string      `json:"resource_name,omitempty"`
+	Bookings         int64       `json:"bookings"`
+	BookingRevenue   money.Money `json:"booking_revenue"`
+	CancellationFees money.Money `json:"cancellation_fees"`
+	Total            money.Money `json:"total"`
+}
+
+type RevenueStats struct {
+	Window    Window             `json:"window"`
+	Resources []*ResourceRevenue `json:"resources"`
+}
+
+// CancellationStats relates the bookings made within the window to those
+// of them that were cancelled. Rate is a percentage.
+type CancellationStats struct {
+	Window    Window  `json:"window"`
+	Bookings  int64   `json:"bookings"`
+	Cancelled int64   `json:"cancelled"`
+	Rate      float64 `json:"rate"`
+}
+
+type UserDay struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+type UserStats struct {
+	Window Window     `json:"window"`
+	Total  int64      `json:"total"`
+	Days   []*UserDay `json:"days"`
+}
+
+// ResourceOccupancy compares the hours a resource was booked within the
+// window with the hours it could have been, its capacity times the length
+// of the window. Open hours are not taken into account. Percent is
+// rounded to two decimals.
+type ResourceOccupancy struct {
+	ResourceID     string  `json:"resource_id"`
+	ResourceName   string  `json:"resource_name"`
+	Capacity       int     `json:"capacity"`
+	BookedHours    float64 `json:"booked_hours"`
+	AvailableHours float64 `json:"available_hours"`
+	Percent        float64 `json:"percent"`
+}
+
+type OccupancyStats struct {
+	Window    Window               `json:"window"`
+	Resources []*ResourceOccupancy `json:"resources"`
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/stats/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultWindow is the period statistics cover when the request does not
+// say otherwise.
+const defaultWindow = 30 * 24 * time.Hour
+
+// StatsHandler serves the admin dashboard statistics. Every endpoint
+// takes an RFC 3339 from/to window that defaults to the last 30 days.
+type StatsHandler struct {
+	service domain.StatsService
+	logger  *logger.Logger
+}
+
+func NewStatsHandler(service domain.StatsService, logger *logger.Logger) *StatsHandler {
+	return &StatsHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *StatsHandler) Bookings(c *gin.Context) {
+	serve(c, h.service.Bookings)
+}
+
+func (h *StatsHandler) Revenue(c *gin.Context) {
+	serve(c, h.service.Revenue)
+}
+
+func (h *StatsHandler) Cancellations(c *gin.Context) {
+	serve(c, h.service.Cancellations)
+}
+
+func (h *StatsHandler) Users(c *gin.Context) {
+	serve(c, h.service.Users)
+}
+
+func (h *StatsHandler) Occupancy(c *gin.Context) {
+	serve(c, h.service.Occupancy)
+}
+
+// serve reads the window of the request and responds with the statistics
+// stats computes for it.
+func serve[T any](c *gin.Context, stats func(ctx context.Context, window domain.Window) (*T, error)) {
+	window, ok := windowFromQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := stats(c.Request.Context(), window)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+func windowFromQuery(c *gin.Context) (domain.Window, bool) {
+	window := domain.Window{To: time.Now().UTC()}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.ValidationError(c, validation.Invalid("to", "rfc3339", ""))
+			return window, false
+		}
+		window.To = parsed.UTC()
+	}
+
+	window.From = window.To.Add(-defaultWindow)
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.ValidationError(c, validation.Invalid("from", "rfc3339", ""))
+			return window, false
+		}
+		window.From = parsed.UTC()
+	}
+
+	return window, true
+}
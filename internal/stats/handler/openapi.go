@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/stats/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the admin statistics HTTP API for the OpenAPI document.
+// The routes are served by the booking service; keep them in sync with
+// setupRouter in cmd/booking.
+func Routes() []openapi.Route {
+	window := []openapi.Param{
+		{Name: "from", Description: "Start of the window (RFC 3339), defaults to 30 days before to", Format: "date-time"},
+		{Name: "to", Description: "End of the window (RFC 3339), defaults to now", Format: "date-time"},
+	}
+
+	return []openapi.Route{
+		{Method: http.MethodGet, Path: "/api/v1/admin/stats/bookings", Summary: "Count bookings made per day", Tag: "stats", Auth: true, Admin: true,
+			Response: domain.BookingStats{}, Query: window},
+		{Method: http.MethodGet, Path: "/api/v1/admin/stats/revenue", Summary: "Sum revenue by resource", Tag: "stats", Auth: true, Admin: true,
+			Response: domain.RevenueStats{}, Query: window},
+		{Method: http.MethodGet, Path: "/api/v1/admin/stats/cancellations", Summary: "Get the cancellation rate", Tag: "stats", Auth: true, Admin: true,
+			Response: domain.CancellationStats{}, Query: window},
+		{Method: http.MethodGet, Path: "/api/v1/admin/stats/users", Summary: "Count new users per day", Tag: "stats", Auth: true, Admin: true,
+			Response: domain.UserStats{}, Query: window},
+		{Method: http.MethodGet, Path: "/api/v1/admin/stats/occupancy", Summary: "Get the occupancy of each resource", Tag: "stats", Auth: true, Admin: true,
+			Response: domain.OccupancyStats{}, Query: window},
+	}
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+
+	bookingdomain "github.com/dmehra2102/booking-system/internal/booking/domain"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/stats/domain"
+	"github.com/dmehra2102/booking-system/pkg/money"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresStatsRepository aggregates the booking and user tables. Days are
+// UTC dates.
+type PostgresStatsRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresStatsRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresStatsRepository {
+	return &PostgresStatsRepository{db: db, tracer: tracer}
+}
+
+func occupyingStatuses() []string {
+	statuses := make([]string, len(domain.OccupyingStatuses))
+	for i, status := range domain.OccupyingStatuses {
+		statuses[i] = string(status)
+	}
+	return statuses
+}
+
+// BookingsPerDay counts the bookings made within the window by day and
+// current status.
+func (r *PostgresStatsRepository) BookingsPerDay(ctx context.Context, window domain.Window) (_ map[string]map[string]int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "stats.repository.bookings_per_day")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, status, COUNT(*)
+		FROM bookings
+		WHERE tenant_id = $3 AND created_at >= $1 AND created_at < $2 AND deleted_at IS NULL
+		GROUP BY day, status
+	`
+
+	rows, err := r.db.Query(ctx, "stats.bookings_per_day", query, window.From, window.To, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to count bookings", err)
+	}
+	defer rows.Close()
+
+	days := make(map[string]map[string]int64)
+	for rows.Next() {
+		var day, status string
+		var count int64
+		if err := rows.Scan(&day, &status, &count); err != nil {
+			return nil, errors.NewInternalError("failed to scan booking count", err)
+		}
+		if days[day] == nil {
+			days[day] = make(map[string]int64)
+		}
+		days[day][status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to count bookings", err)
+	}
+
+	return days, nil
+}
+
+// RevenueByResource sums the revenue of the bookings starting within the
+// window per resource and currency, highest first.
+func (r *PostgresStatsRepository) RevenueByResource(ctx context.Context, window domain.Window) (_ []*domain.ResourceRevenue, err error) {
+	ctx, span := r.tracer.Start(ctx, "stats.repository.revenue_by_resource")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT b.resource_id, COALESCE(MAX(r.name), ''), b.currency,
+			COUNT(*) FILTER (WHERE b.status = ANY($3)),
+			COALESCE(SUM(b.amount) FILTER (WHERE b.status = ANY($3)), 0),
+			COALESCE(SUM(b.cancellation_fee) FILTER (WHERE b.status = $4), 0)
+		FROM bookings b
+		LEFT JOIN resources r ON r.id = b.resource_id AND r.tenant_id = b.tenant_id
+		WHERE b.tenant_id = $5 AND b.start_time >= $1 AND b.start_time < $2
+		  AND (b.status = ANY($3) OR b.status = $4)
+		  AND b.deleted_at IS NULL
+		GROUP BY b.resource_id, b.currency
+		ORDER BY SUM(CASE WHEN b.status = $4 THEN b.cancellation_fee ELSE b.amount END) DESC, b.resource_id, b.currency
+	`
+
+	rows, err := r.db.Query(ctx, "stats.revenue_by_resource", query, window.From, window.To, occupyingStatuses(), bookingdomain.BookingStatusCancelled, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to sum revenue", err)
+	}
+	defer rows.Close()
+
+	resources := make([]*domain.ResourceRevenue, 0)
+	for rows.Next() {
+		revenue := &domain.ResourceRevenue{}
+		var currency, amount, fees string
+		if err := rows.Scan(&revenue.ResourceID, &revenue.ResourceName, &currency, &revenue.Bookings, &amount, &fees); err != nil {
+			return nil, errors.NewInternalError("failed to scan revenue", err)
+		}
+
+		if revenue.BookingRevenue, err = money.Parse(amount, money.Currency(currency)); err != nil {
+			return nil, errors.NewInternalError("failed to scan revenue", err)
+		}
+		if revenue.CancellationFees, err = money.Parse(fees, money.Currency(currency)); err != nil {
+			return nil, errors.NewInternalError("failed to scan revenue", err)
+		}
+		revenue.Total, _ = revenue.BookingRevenue.Add(revenue.CancellationFees)
+
+		resources = append(resources, revenue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to sum revenue", err)
+	}
+
+	return resources, nil
+}
+
+// Cancellations counts the bookings made within the window and how many of
+// them were cancelled.
+func (r *PostgresStatsRepository) Cancellations(ctx context.Context, window domain.Window) (bookings, cancelled int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "stats.repository.cancellations")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE status = $3)
+		FROM bookings
+		WHERE tenant_id = $4 AND created_at >= $1 AND created_at < $2 AND deleted_at IS NULL
+	`
+
+	err = r.db.QueryRow(ctx, "stats.cancellations", query, window.From, window.To, bookingdomain.BookingStatusCancelled, tenancy.ID(ctx)).Scan(&bookings, &cancelled)
+	if err != nil {
+		return 0, 0, errors.NewInternalError("failed to count cancellations", err)
+	}
+
+	return bookings, cancelled, nil
+}
+
+// NewUsersPerDay counts the users who signed up within the window by day.
+func (r *PostgresStatsRepository) NewUsersPerDay(ctx context.Context, window domain.Window) (_ map[string]int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "stats.repository.new_users_per_day")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*)
+		FROM users
+		WHERE tenant_id = $3 AND created_at >= $1 AND created_at < $2
+		GROUP BY day
+	`
+
+	rows, err := r.db.Query(ctx, "stats.new_users_per_day", query, window.From, window.To, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to count users", err)
+	}
+	defer rows.Close()
+
+	days := make(map[string]int64)
+	for rows.Next() {
+		var day string
+		var count int64
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, errors.NewInternalError("failed to scan user count", err)
+		}
+		days[day] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to count users", err)
+	}
+
+	return days, nil
+}
+
+// BookedHours returns the active resources with the hours their bookings
+// occupy within the window, clipped to it.
+func (r *PostgresStatsRepository) BookedHours(ctx context.Context, window domain.Window) (_ []*domain.ResourceOccupancy, err error) {
+	ctx, span := r.tracer.Start(ctx, "stats.repository.booked_hours")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT r.id, r.name, r.capacity,
+			COALESCE(SUM(EXTRACT(EPOCH FROM LEAST(b.end_time, $2) - GREATEST(b.start_time, $1))), 0) / 3600
+		FROM resources r
+		LEFT JOIN bookings b ON b.resource_id = r.id
+			AND b.tenant_id = r.tenant_id
+			AND b.status = ANY($3)
+			AND b.start_time < $2
+			AND b.end_time > $1
+			AND b.deleted_at IS NULL
+		WHERE r.tenant_id = $4 AND r.active = true
+		GROUP BY r.id, r.name, r.capacity
+		ORDER BY r.name ASC, r.id ASC
+	`
+
+	rows, err := r.db.Query(ctx, "stats.booked_hours", query, window.From, window.To, occupyingStatuses(), tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to sum booked hours", err)
+	}
+	defer rows.Close()
+
+	resources := make([]*domain.ResourceOccupancy, 0)
+	for rows.Next() {
+		occupancy := &domain.ResourceOccupancy{}
+		if err := rows.Scan(&occupancy.ResourceID, &occupancy.ResourceName, &occupancy.Capacity, &occupancy.BookedHours); err != nil {
+			return nil, errors.NewInternalError("failed to scan booked hours", err)
+		}
+		resources = append(resources, occupancy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to sum booked hours", err)
+	}
+
+	return resources, nil
+}
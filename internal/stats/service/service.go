@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/stats/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type StatsRepository interface {
+	BookingsPerDay(ctx context.Context, window domain.Window) (map[string]map[string]int64, error)
+	RevenueByResource(ctx context.Context, window domain.Window) ([]*domain.ResourceRevenue, error)
+	Cancellations(ctx context.Context, window domain.Window) (bookings, cancelled int64, err error)
+	NewUsersPerDay(ctx context.Context, window domain.Window) (map[string]int64, error)
+	BookedHours(ctx context.Context, window domain.Window) ([]*domain.ResourceOccupancy, error)
+}
+
+var _ domain.StatsService = (*StatsService)(nil)
+
+// StatsService computes the statistics of the admin dashboard on request
+// from aggregate queries. Daily series include the days without activity.
+type StatsService struct {
+	repo   StatsRepository
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+func NewStatsService(repo StatsRepository, logger *logger.Logger, tracer trace.Tracer) *StatsService {
+	return &StatsService{
+		repo:   repo,
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func validateWindow(window domain.Window) error {
+	if !window.To.After(window.From) {
+		return errors.NewValidationError("to must be after from", nil)
+	}
+	if window.To.Sub(window.From) > domain.MaxWindow {
+		return errors.NewValidationError("window must not be longer than 366 days", nil)
+	}
+	return nil
+}
+
+// percent returns part of whole as a percentage rounded to two decimals.
+func percent(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(part/whole*100*100) / 100
+}
+
+func (s *StatsService) Bookings(ctx context.Context, window domain.Window) (_ *domain.BookingStats, err error) {
+	ctx, span := s.tracer.Start(ctx, "stats.service.bookings")
+	defer tracing.End(span, &err)
+
+	if err := validateWindow(window); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.BookingsPerDay(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.BookingStats{Window: window, Days: make([]*domain.BookingDay, 0)}
+	for _, date := range window.Days() {
+		day := &domain.BookingDay{Date: date, ByStatus: make(map[string]int64)}
+		for status, count := range counts[date] {
+			day.ByStatus[status] = count
+			day.Total += count
+		}
+		stats.Days = append(stats.Days, day)
+	}
+
+	return stats, nil
+}
+
+func (s *StatsService) Revenue(ctx context.Context, window domain.Window) (_ *domain.RevenueStats, err error) {
+	ctx, span := s.tracer.Start(ctx, "stats.service.revenue")
+	defer tracing.End(span, &err)
+
+	if err := validateWindow(window); err != nil {
+		return nil, err
+	}
+
+	resources, err := s.repo.RevenueByResource(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.RevenueStats{Window: window, Resources: resources}, nil
+}
+
+func (s *StatsService) Cancellations(ctx context.Context, window domain.Window) (_ *domain.CancellationStats, err error) {
+	ctx, span := s.tracer.Start(ctx, "stats.service.cancellations")
+	defer tracing.End(span, &err)
+
+	if err := validateWindow(window); err != nil {
+		return nil, err
+	}
+
+	bookings, cancelled, err := s.repo.Cancellations(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CancellationStats{
+		Window:    window,
+		Bookings:  bookings,
+		Cancelled: cancelled,
+		Rate:      percent(float64(cancelled), float64(bookings)),
+	}, nil
+}
+
+func (s *StatsService) Users(ctx context.Context, window domain.Window) (_ *domain.UserStats, err error) {
+	ctx, span := s.tracer.Start(ctx, "stats.service.users")
+	defer tracing.End(span, &err)
+
+	if err := validateWindow(window); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.NewUsersPerDay(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.UserStats{Window: window, Days: make([]*domain.UserDay, 0)}
+	for _, date := range window.Days() {
+		stats.Days = append(stats.Days, &domain.UserDay{Date: date, Count: counts[date]})
+		stats.Total += counts[date]
+	}
+
+	return stats, nil
+}
+
+func (s *StatsService) Occupancy(ctx context.Context, window domain.Window) (_ *domain.OccupancyStats, err error) {
+	ctx, span := s.tracer.Start(ctx, "stats.service.occupancy")
+	defer tracing.End(span, &err)
+
+	if err := validateWindow(window); err != nil {
+		return nil, err
+	}
+
+	resources, err := s.repo.BookedHours(ctx, window)
+	if err != nil {
+		return nil, err
+	}
+
+	hours := window.To.Sub(window.From).Hours()
+	for _, resource := range resources {
+		resource.AvailableHours = hours * float64(max(resource.Capacity, 1))
+		resource.BookedHours = math.Round(resource.BookedHours*100) / 100
+		resource.Percent = percent(resource.BookedHours, resource.AvailableHours)
+	}
+
+	return &domain.OccupancyStats{Window: window, Resources: resources}, nil
+}
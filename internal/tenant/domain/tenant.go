@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	userdomain "github.com/dmehra2102/booking-system/internal/user/domain"
+)
+
+// Tenant is a business running on the deployment. Its users, resources,
+// bookings and the rest of its data are invisible to other tenants.
+// Deactivated tenants keep their data, but their users cannot log in and
+// their API keys stop working.
+type Tenant struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Slug      string    `json:"slug" db:"slug"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateTenantRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	Slug string `json:"slug" validate:"required,min=2,max=63,slug"`
+	// Admin, when set, is registered as the first admin of the tenant.
+	Admin *userdomain.CreateUserRequest `json:"admin,omitempty"`
+}
+
+type UpdateTenantRequest struct {
+	Name   string `json:"name" validate:"omitempty,min=2,max=100"`
+	Active *bool  `json:"active,omitempty"`
+}
+
+// CreatedTenant is returned on creation, with the admin that was
+// registered for the tenant if one was requested.
+type CreatedTenant struct {
+	*Tenant
+	Admin *userdomain.User `json:"admin,omitempty"`
+}
+
+// TenantService is the application API of the tenant module. Only admins
+// of tenancy.DefaultID reach it.
+type TenantService interface {
+	CreateTenant(ctx context.Context, req *CreateTenantRequest) (*CreatedTenant, error)
+	GetTenant(ctx context.Context, id string) (*Tenant, error)
+	ListTenants(ctx context.Context, page, pageSize int) ([]*Tenant, int64, error)
+	UpdateTenant(ctx context.Context, id string, req *UpdateTenantRequest) (*Tenant, error)
+	DeactivateTenant(ctx context.Context, id string) error
+}
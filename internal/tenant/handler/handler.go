@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/dmehra2102/booking-system/internal/tenant/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	service domain.TenantService
+}
+
+func NewTenantHandler(service domain.TenantService) *TenantHandler {
+	return &TenantHandler{service: service}
+}
+
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req domain.CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	tenant, err := h.service.CreateTenant(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.CreatedWithLocation(c, path.Join(c.Request.URL.Path, tenant.ID), tenant)
+}
+
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	tenants, total, err := h.service.ListTenants(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := &response.Pagination{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}
+
+	response.Paginated(c, tenants, pagination)
+}
+
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenant, err := h.service.GetTenant(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, tenant)
+}
+
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	var req domain.UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	tenant, err := h.service.UpdateTenant(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, tenant)
+}
+
+// DeactivateTenant locks the tenant out without deleting its data.
+func (h *TenantHandler) DeactivateTenant(c *gin.Context) {
+	if err := h.service.DeactivateTenant(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
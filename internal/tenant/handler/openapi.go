@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/tenant/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the tenant administration API for the OpenAPI
+// document. The routes are served by the user service to admins of the
+// default tenant; keep them in sync with setupRouter in cmd/user.
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/v1/admin/tenants", Summary: "Create a tenant", Tag: "tenants", Auth: true, Admin: true,
+			Request: domain.CreateTenantRequest{}, Response: domain.CreatedTenant{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/admin/tenants", Summary: "List tenants", Tag: "tenants", Auth: true, Admin: true,
+			Response: domain.Tenant{}, List: true},
+		{Method: http.MethodGet, Path: "/api/v1/admin/tenants/:id", Summary: "Get a tenant", Tag: "tenants", Auth: true, Admin: true,
+			Response: domain.Tenant{}},
+		{Method: http.MethodPut, Path: "/api/v1/admin/tenants/:id", Summary: "Update a tenant", Tag: "tenants", Auth: true, Admin: true,
+			Request: domain.UpdateTenantRequest{}, Response: domain.Tenant{}},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/tenants/:id", Summary: "Deactivate a tenant", Tag: "tenants", Auth: true, Admin: true,
+			Status: http.StatusNoContent},
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/tenant/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresTenantRepository stores the tenants. Unlike the other
+// repositories its queries are not scoped to the tenant of the context.
+type PostgresTenantRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresTenantRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresTenantRepository {
+	return &PostgresTenantRepository{db: db, tracer: tracer}
+}
+
+const selectTenantQuery = `SELECT id, name, slug, active, created_at, updated_at FROM tenants`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTenant(row rowScanner) (*domain.Tenant, error) {
+	tenant := &domain.Tenant{}
+	if err := row.Scan(&tenant.ID, &tenant.Name, &tenant.Slug, &tenant.Active, &tenant.CreatedAt, &tenant.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+func (r *PostgresTenantRepository) Create(ctx context.Context, tenant *domain.Tenant) (err error) {
+	ctx, span := r.tracer.Start(ctx, "tenant.repository.create")
+	defer tracing.End(span, &err)
+
+	tenant.ID = uuid.New().String()
+	tenant.Active = true
+	tenant.CreatedAt = time.Now().UTC()
+	tenant.UpdatedAt = tenant.CreatedAt
+
+	query := `
+		INSERT INTO tenants (id, name, slug, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = r.db.Exec(ctx, "tenant.create", query, tenant.ID, tenant.Name, tenant.Slug, tenant.Active, tenant.CreatedAt, tenant.UpdatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return errors.NewConflictError("tenant with this slug already exists")
+		}
+		return errors.NewInternalError("failed to create tenant", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresTenantRepository) GetByID(ctx context.Context, id string) (_ *domain.Tenant, err error) {
+	ctx, span := r.tracer.Start(ctx, "tenant.repository.get_by_id")
+	defer tracing.End(span, &err)
+
+	tenant, err := scanTenant(r.db.QueryRow(ctx, "tenant.get_by_id", selectTenantQuery+` WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("tenant")
+		}
+		return nil, errors.NewInternalError("failed to get tenant", err)
+	}
+
+	return tenant, nil
+}
+
+func (r *PostgresTenantRepository) List(ctx context.Context, limit, offset int) (_ []*domain.Tenant, _ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "tenant.repository.list")
+	defer tracing.End(span, &err)
+
+	var total int64
+	if err := r.db.QueryRow(ctx, "tenant.count", `SELECT COUNT(*) FROM tenants`).Scan(&total); err != nil {
+		return nil, 0, errors.NewInternalError("failed to count tenants", err)
+	}
+
+	query := selectTenantQuery + `
+		ORDER BY created_at, id
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Query(ctx, "tenant.list", query, limit, offset)
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to list tenants", err)
+	}
+	defer rows.Close()
+
+	tenants := make([]*domain.Tenant, 0)
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, 0, errors.NewInternalError("failed to scan tenant", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.NewInternalError("failed to iterate tenants", err)
+	}
+
+	return tenants, total, nil
+}
+
+func (r *PostgresTenantRepository) Update(ctx context.Context, tenant *domain.Tenant) (err error) {
+	ctx, span := r.tracer.Start(ctx, "tenant.repository.update")
+	defer tracing.End(span, &err)
+
+	tenant.UpdatedAt = time.Now().UTC()
+
+	query := `UPDATE tenants SET name = $2, active = $3, updated_at = $4 WHERE id = $1`
+
+	result, err := r.db.Exec(ctx, "tenant.update", query, tenant.ID, tenant.Name, tenant.Active, tenant.UpdatedAt)
+	if err != nil {
+		return errors.NewInternalError("failed to update tenant", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("tenant")
+	}
+
+	return nil
+}
+
+// ActiveIDs lists the active tenants, for the scheduled jobs that run for
+// each of them.
+func (r *PostgresTenantRepository) ActiveIDs(ctx context.Context) (_ []string, err error) {
+	ctx, span := r.tracer.Start(ctx, "tenant.repository.active_ids")
+	defer tracing.End(span, &err)
+
+	rows, err := r.db.Query(ctx, "tenant.active_ids", `SELECT id FROM tenants WHERE active ORDER BY id`)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list active tenants", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.NewInternalError("failed to scan tenant", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to iterate tenants", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/tenant/domain"
+	userdomain "github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *domain.Tenant) error
+	GetByID(ctx context.Context, id string) (*domain.Tenant, error)
+	List(ctx context.Context, limit, offset int) ([]*domain.Tenant, int64, error)
+	Update(ctx context.Context, tenant *domain.Tenant) error
+}
+
+// Users registers the first admin of a new tenant. The user service
+// implements it.
+type Users interface {
+	CreateUser(ctx context.Context, req *userdomain.CreateUserRequest) (*userdomain.User, error)
+	UpdateRole(ctx context.Context, id string, req *userdomain.UpdateRoleRequest) (*userdomain.User, error)
+}
+
+var _ domain.TenantService = (*TenantService)(nil)
+
+type TenantService struct {
+	repo   TenantRepository
+	users  Users
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+func NewTenantService(repo TenantRepository, users Users, logger *logger.Logger, tracer trace.Tracer) *TenantService {
+	return &TenantService{repo: repo, users: users, logger: logger, tracer: tracer}
+}
+
+// CreateTenant creates an active tenant and, when req.Admin is set,
+// registers that user as its admin. The tenant is kept if registering the
+// admin fails; the admin can then be added with another request.
+func (s *TenantService) CreateTenant(ctx context.Context, req *domain.CreateTenantRequest) (_ *domain.CreatedTenant, err error) {
+	ctx, span := s.tracer.Start(ctx, "tenant.service.create")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	tenant := &domain.Tenant{Name: req.Name, Slug: req.Slug}
+	if err := s.repo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "tenant.create", "tenant", tenant.ID, nil, tenant)
+	s.logger.WithContext(ctx).With("created_tenant_id", tenant.ID).Info("tenant created successfully")
+
+	created := &domain.CreatedTenant{Tenant: tenant}
+	if req.Admin == nil {
+		return created, nil
+	}
+
+	tenantCtx := tenancy.WithID(ctx, tenant.ID)
+	admin, err := s.users.CreateUser(tenantCtx, req.Admin)
+	if err != nil {
+		return nil, err
+	}
+	if created.Admin, err = s.users.UpdateRole(tenantCtx, admin.ID, &userdomain.UpdateRoleRequest{Role: auth.RoleAdmin}); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *TenantService) GetTenant(ctx context.Context, id string) (_ *domain.Tenant, err error) {
+	ctx, span := s.tracer.Start(ctx, "tenant.service.get")
+	defer tracing.End(span, &err)
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *TenantService) ListTenants(ctx context.Context, page, pageSize int) (_ []*domain.Tenant, _ int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "tenant.service.list")
+	defer tracing.End(span, &err)
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	return s.repo.List(ctx, pageSize, offset)
+}
+
+func (s *TenantService) UpdateTenant(ctx context.Context, id string, req *domain.UpdateTenantRequest) (_ *domain.Tenant, err error) {
+	ctx, span := s.tracer.Start(ctx, "tenant.service.update")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	if req.Active != nil && !*req.Active && id == tenancy.DefaultID {
+		return nil, errors.NewValidationError("the default tenant cannot be deactivated", nil)
+	}
+
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *tenant
+
+	if req.Name != "" {
+		tenant.Name = req.Name
+	}
+	if req.Active != nil {
+		tenant.Active = *req.Active
+	}
+
+	if err := s.repo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "tenant.update", "tenant", id, &before, tenant)
+	s.logger.WithContext(ctx).With("updated_tenant_id", id).Info("tenant updated successfully")
+
+	return tenant, nil
+}
+
+// DeactivateTenant locks a tenant out. Its data is kept and the tenant can
+// be reactivated with UpdateTenant.
+func (s *TenantService) DeactivateTenant(ctx context.Context, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "tenant.service.deactivate")
+	defer tracing.End(span, &err)
+
+	active := false
+	_, err = s.UpdateTenant(ctx, id, &domain.UpdateTenantRequest{Active: &active})
+	return err
+}
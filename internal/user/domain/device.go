@@ -0,0 +1,150 @@
+package domain
+
+import "time"
+
+// Platform is the push service a device is reached through.
+type Platform string
+
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformWeb     Platform = "web"
+)
+
+// Device is an app installation registered for push notifications. Token
+// is issued to the app by FCM or APNs.
+type Device struct {
+	ID         string    `json:"id" db:"id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	Token      string    `json:"token" db:"token"`
+	Platform   Platform  `json:"platform" db:"platform"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+type RegisterDeviceRequest struct {
+	Token    string   `json:"token" validate:"required,max=4096"`
+	Platform Platform `json:"platform" validate:"required,oneof=ios android web"`
+}
+
+// NotificationCategory groups the notifications users choose channels
+// for. Account mail, such as password resets, has no category: it is
+// always sent by email.
+type NotificationCategory string
+
+const (
+	NotificationCategoryBooking  NotificationCategory = "booking"
+	NotificationCategoryWaitlist NotificationCategory = "waitlist"
+	NotificationCategoryPayment  NotificationCategory = "payment"
+	NotificationCategoryExport   NotificationCategory = "export"
+)
+
+// NotificationChannels are the channels a notification is sent over.
+type NotificationChannels struct {
+	Email bool `json:"email" db:"email"`
+	SMS   bool `json:"sms" db:"sms"`
+	Push  bool `json:"push" db:"push"`
+}
+
+// QuietHours are a daily window in which text messages and push
+// notifications are held back and only email is sent. Start and End are
+// local times in TimeZone; the window spans midnight when End is before
+// Start.
+type QuietHours struct {
+	Start    string `json:"start" validate:"required,datetime=15:04"`
+	End      string `json:"end" validate:"required,datetime=15:04,nefield=Start"`
+	TimeZone string `json:"time_zone" validate:"required,timezone"`
+}
+
+// NotificationPreferences are the channels a user is notified over. The
+// embedded channels apply to the categories that Categories does not
+// list.
+type NotificationPreferences struct {
+	UserID string `json:"user_id" db:"user_id"`
+	NotificationChannels
+	Categories map[NotificationCategory]NotificationChannels `json:"categories" db:"categories"`
+	QuietHours *QuietHours                                   `json:"quiet_hours,omitempty" db:"quiet_hours"`
+	// Phone is the E.164 number text messages are sent to
+	Phone string `json:"phone,omitempty" db:"phone"`
+	// Locale is the BCP 47 language tag text messages are written in,
+	// empty for the default language
+	Locale    string    `json:"locale,omitempty" db:"locale"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UsesSMS reports whether any category is texted.
+func (p *NotificationPreferences) UsesSMS() bool {
+	if p.SMS {
+		return true
+	}
+	for _, channels := range p.Categories {
+		if channels.SMS {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationDefaults are the preferences of the users of a tenant who
+// did not set their own. Admins of the tenant manage them.
+type NotificationDefaults struct {
+	NotificationChannels
+	Categories map[NotificationCategory]NotificationChannels `json:"categories" db:"categories"`
+	QuietHours *QuietHours                                   `json:"quiet_hours,omitempty" db:"quiet_hours"`
+	UpdatedAt  time.Time                                     `json:"updated_at" db:"updated_at"`
+}
+
+// BuiltinNotificationDefaults are the defaults of tenants that did not
+// set any: email and push notifications for everything.
+func BuiltinNotificationDefaults() *NotificationDefaults {
+	return &NotificationDefaults{
+		NotificationChannels: NotificationChannels{Email: true, Push: true},
+		Categories:           make(map[NotificationCategory]NotificationChannels),
+	}
+}
+
+// Preferences returns the preferences the defaults give userID.
+func (d *NotificationDefaults) Preferences(userID string) *NotificationPreferences {
+	categories := make(map[NotificationCategory]NotificationChannels, len(d.Categories))
+	for category, channels := range d.Categories {
+		categories[category] = channels
+	}
+
+	var quietHours *QuietHours
+	if d.QuietHours != nil {
+		copied := *d.QuietHours
+		quietHours = &copied
+	}
+
+	return &NotificationPreferences{
+		UserID:               userID,
+		NotificationChannels: d.NotificationChannels,
+		Categories:           categories,
+		QuietHours:           quietHours,
+	}
+}
+
+// UpdateNotificationPreferencesRequest changes the preferences that are
+// set and keeps the others. Categories and QuietHours replace the stored
+// ones as a whole; an empty categories object removes the overrides and
+// ClearQuietHours removes the quiet hours.
+type UpdateNotificationPreferencesRequest struct {
+	Email           *bool                                         `json:"email,omitempty"`
+	SMS             *bool                                         `json:"sms,omitempty"`
+	Push            *bool                                         `json:"push,omitempty"`
+	Categories      map[NotificationCategory]NotificationChannels `json:"categories,omitempty" validate:"omitempty,dive,keys,oneof=booking waitlist payment export,endkeys"`
+	QuietHours      *QuietHours                                   `json:"quiet_hours,omitempty"`
+	ClearQuietHours bool                                          `json:"clear_quiet_hours,omitempty" validate:"excluded_with=QuietHours"`
+	Phone           *string                                       `json:"phone,omitempty" validate:"omitempty,e164"`
+	Locale          *string                                       `json:"locale,omitempty" validate:"omitempty,bcp47_language_tag"`
+}
+
+// UpdateNotificationDefaultsRequest replaces the notification defaults of
+// the tenant.
+type UpdateNotificationDefaultsRequest struct {
+	Email      bool                                          `json:"email"`
+	SMS        bool                                          `json:"sms"`
+	Push       bool                                          `json:"push"`
+	Categories map[NotificationCategory]NotificationChannels `json:"categories,omitempty" validate:"omitempty,dive,keys,oneof=booking waitlist payment export,endkeys"`
+	QuietHours *QuietHours                                   `json:"quiet_hours,omitempty"`
+}
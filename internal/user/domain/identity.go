@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// The identity providers users can log in with.
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+)
+
+// Identity links a user to their account at an identity provider.
+type Identity struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Provider  string    `json:"provider" db:"provider"`
+	Subject   string    `json:"subject" db:"subject"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ExternalIdentity is the account a provider authenticated.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OAuthCallbackRequest is the query string a provider redirects back with.
+// Error is set instead of Code when the user declined. BrowserSecret is not
+// part of the query but read from the cookie the login was started with.
+type OAuthCallbackRequest struct {
+	Code          string `form:"code"`
+	State         string `form:"state" validate:"required"`
+	Error         string `form:"error"`
+	BrowserSecret string `form:"-"`
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending DataExportStatus = "pending"
+	DataExportStatusReady   DataExportStatus = "ready"
+	DataExportStatusExpired DataExportStatus = "expired"
+)
+
+// DataExport is a copy of the personal data held about a user, assembled
+// from the parts the services holding it send. It is ready once every
+// part arrived, and can be downloaded until it expires.
+type DataExport struct {
+	ID     string           `json:"id" db:"id"`
+	UserID string           `json:"user_id" db:"user_id"`
+	Status DataExportStatus `json:"status" db:"status"`
+	// Parts lists the parts that arrived.
+	Parts       []string   `json:"parts" db:"-"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// Filename is the name the export is downloaded as.
+func (e *DataExport) Filename() string {
+	return "personal-data-" + e.CreatedAt.Format("20060102-150405") + ".zip"
+}
+
+// DataExportPart is the personal data one service holds about a user, as
+// a JSON document.
+type DataExportPart struct {
+	Part    string          `json:"part" db:"part"`
+	Content json.RawMessage `json:"content" db:"content"`
+}
+
+// ErasedPart records what a service erased of a deleted user's data.
+type ErasedPart struct {
+	Part     string    `json:"part" db:"part"`
+	Records  int       `json:"records" db:"records"`
+	Retained []string  `json:"retained,omitempty" db:"retained"`
+	ErasedAt time.Time `json:"erased_at" db:"erased_at"`
+}
+
+// ErasureCertificate attests that the personal data of a deleted user was
+// erased by every service holding some. It is written to the audit log
+// once the last service reported its erasure.
+type ErasureCertificate struct {
+	UserID      string        `json:"user_id"`
+	DeletedAt   time.Time     `json:"deleted_at"`
+	CompletedAt time.Time     `json:"completed_at"`
+	Parts       []*ErasedPart `json:"parts"`
+}
+
+// ProfileData is the part of a data export the user service holds.
+type ProfileData struct {
+	User                    *User                    `json:"user"`
+	NotificationPreferences *NotificationPreferences `json:"notification_preferences,omitempty"`
+	Devices                 []*Device                `json:"devices"`
+	Sessions                []*Session               `json:"sessions"`
+	Identities              []*Identity              `json:"identities"`
+	TwoFactorEnabled        bool                     `json:"two_factor_enabled"`
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// UserSortFields are the fields ListUsers can sort by.
+var UserSortFields = []string{"created_at", "name", "email"}
+
+// ListUsersFilter narrows ListUsers. Zero fields match every user.
+type ListUsersFilter struct {
+	Role        string
+	Active      *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// UserService is the application API of the user module. The HTTP handler
+// depends on it and service.UserService implements it.
+type UserService interface {
+	CreateUser(ctx context.Context, req *CreateUserRequest) (*User, error)
+	Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error)
+	RefreshToken(ctx context.Context, req *RefreshTokenRequest) (*LoginResponse, error)
+	Logout(ctx context.Context, userID, tokenID, sessionID string, tokenExpiresAt time.Time, req *LogoutRequest) error
+	GetUser(ctx context.Context, id string) (*User, error)
+	UpdateUser(ctx context.Context, id string, req *UpdateUserRequest) (*User, error)
+	UpdateRole(ctx context.Context, id string, req *UpdateRoleRequest) (*User, error)
+	DeleteUser(ctx context.Context, id string) error
+	VerifyEmail(ctx context.Context, req *VerifyEmailRequest) (*User, error)
+	ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+	ListUsers(ctx context.Context, filter ListUsersFilter, params pagination.Params) ([]*User, *pagination.Result, error)
+	ExportUsers(ctx context.Context, filter ListUsersFilter, w export.Writer) (int, error)
+}
+
+// OAuthService logs users in through external identity providers. The
+// HTTP handler depends on it and service.OAuthService implements it.
+type OAuthService interface {
+	AuthorizationURL(ctx context.Context, provider string) (url, browserSecret string, err error)
+	Callback(ctx context.Context, provider string, req *OAuthCallbackRequest) (*LoginResponse, error)
+}
+
+// TwoFactorService manages the TOTP enrollment of users and completes the
+// logins that need a second factor. The HTTP handler depends on it and
+// service.TwoFactorService implements it.
+type TwoFactorService interface {
+	Get(ctx context.Context, userID string) (*TwoFactor, error)
+	Setup(ctx context.Context, userID string) (*TwoFactorSetup, error)
+	Enable(ctx context.Context, userID string, req *TwoFactorCodeRequest) (*TwoFactorBackupCodes, error)
+	Disable(ctx context.Context, userID string, req *TwoFactorCodeRequest) error
+	RegenerateBackupCodes(ctx context.Context, userID string, req *TwoFactorCodeRequest) (*TwoFactorBackupCodes, error)
+	SetupLogin(ctx context.Context, req *TwoFactorTokenRequest) (*TwoFactorSetup, error)
+	CompleteLogin(ctx context.Context, req *TwoFactorLoginRequest) (*LoginResponse, error)
+}
+
+// SessionService lists and ends the login sessions of users. The HTTP
+// handler depends on it and service.SessionService implements it.
+type SessionService interface {
+	ListSessions(ctx context.Context, userID, currentSessionID string) ([]*Session, error)
+	EndSession(ctx context.Context, userID, sessionID string) error
+	EndAllSessions(ctx context.Context, userID string) error
+}
+
+// PrivacyService exports the personal data of users. The HTTP handler
+// depends on it and service.PrivacyService implements it.
+type PrivacyService interface {
+	RequestDataExport(ctx context.Context, userID string) (*DataExport, error)
+	GetDataExport(ctx context.Context, userID string) (*DataExport, error)
+	DownloadDataExport(ctx context.Context, userID string) (*DataExport, []*DataExportPart, error)
+}
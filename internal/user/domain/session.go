@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Session is a login of a user on one device. Its tokens stop working when
+// it is ended, by logging out or from another session.
+type Session struct {
+	ID         string    `json:"id" db:"id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	CreatedAt  time.Time `json:"issued_at" db:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	// Current marks the session of the request.
+	Current bool `json:"current" db:"-"`
+}
+
+// Client describes the device a login or refresh comes from.
+type Client struct {
+	IPAddress string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithClient returns ctx carrying the client of the request, which is
+// recorded in the sessions it starts or refreshes.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFrom returns the client carried by ctx.
+func ClientFrom(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// TwoFactor is the TOTP enrollment of a user. It is pending, and not asked
+// for at login, until the user proves with a code that their app has the
+// secret. Secret is encrypted and BackupCodes are hashed.
+type TwoFactor struct {
+	UserID       string     `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	BackupCodes  []string   `json:"-" db:"backup_codes"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty" db:"enabled_at"`
+}
+
+// TwoFactorSetup is the secret of a new enrollment, which is only
+// returned here.
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorBackupCodes are the codes that log a user in once each when
+// their authenticator app is not at hand. They are only returned when
+// generated.
+type TwoFactorBackupCodes struct {
+	BackupCodes []string `json:"backup_codes"`
+}
+
+// TwoFactorCodeRequest confirms a change of the enrollment with a code of
+// the authenticator app or, except for enabling it, a backup code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// TwoFactorTokenRequest sets up the enrollment a login requires, with the
+// token the first step of the login returned.
+type TwoFactorTokenRequest struct {
+	TwoFactorToken string `json:"two_factor_token" validate:"required"`
+}
+
+// TwoFactorLoginRequest completes a login with the second factor.
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
@@ -7,14 +7,18 @@ import (
 )
 
 type User struct {
-	ID        string    `json:"id" db:"id"`
-	Email     string    `json:"email" db:"email"`
-	Name      string    `json:"name" db:"name"`
-	Password  string    `json:"-" db:"password_hash"`
-	Role      string    `json:"role" db:"role"`
-	Active    bool      `json:"active" db:"active"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID            string    `json:"id" db:"id"`
+	TenantID      string    `json:"tenant_id" db:"tenant_id"`
+	Email         string    `json:"email" db:"email"`
+	Name          string    `json:"name" db:"name"`
+	Password      string    `json:"-" db:"password_hash"`
+	Role          string    `json:"role" db:"role"`
+	Active        bool      `json:"active" db:"active"`
+	EmailVerified bool      `json:"email_verified" db:"email_verified"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	// Version is incremented by every update of the user.
+	Version int64 `json:"version" db:"version"`
 }
 
 type CreateUserRequest struct {
@@ -26,17 +30,59 @@ type CreateUserRequest struct {
 type UpdateUserRequest struct {
 	Name  string `json:"name" validate:"omitempty,min=2,max=100"`
 	Email string `json:"email" validate:"omitempty,email"`
+	// Version, when set, is the version of the user the change was based
+	// on. It is taken from the If-Match header.
+	Version int64 `json:"-"`
 }
 
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user staff admin"`
+}
+
+// LoginRequest logs a user in with their password. CaptchaToken is the
+// solved CAPTCHA that logins to an account with repeated failures need.
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required"`
+	CaptchaToken string `json:"captcha_token,omitempty" validate:"omitempty,max=4096"`
 }
 
+// LoginResponse carries the token pair of a login. A login that needs a
+// second factor returns TwoFactorToken instead, with TwoFactorRequired
+// set, or TwoFactorSetupRequired when the role of the user requires an
+// enrollment they have not made yet. BackupCodes are set by the login that
+// completes such an enrollment.
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	User      *User     `json:"user"`
-	ExpiresAt time.Time `json:"expires_at"`
+	Token                  string    `json:"token,omitempty"`
+	RefreshToken           string    `json:"refresh_token,omitempty"`
+	User                   *User     `json:"user,omitempty"`
+	ExpiresAt              time.Time `json:"expires_at,omitzero"`
+	RefreshExpiresAt       time.Time `json:"refresh_expires_at,omitzero"`
+	TwoFactorRequired      bool      `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool      `json:"two_factor_setup_required,omitempty"`
+	TwoFactorToken         string    `json:"two_factor_token,omitempty"`
+	BackupCodes            []string  `json:"backup_codes,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 func (u *User) HashPassword() error {
@@ -55,12 +101,14 @@ func (u *User) CheckPassword(password string) bool {
 
 func (u *User) ToPublic() *User {
 	return &User{
-		ID:        u.ID,
-		Email:     u.Email,
-		Name:      u.Name,
-		Role:      u.Role,
-		Active:    u.Active,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:            u.ID,
+		Email:         u.Email,
+		Name:          u.Name,
+		Role:          u.Role,
+		Active:        u.Active,
+		EmailVerified: u.EmailVerified,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+		Version:       u.Version,
 	}
 }
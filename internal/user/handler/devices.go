@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/internal/user/service"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceHandler serves the push devices and notification preferences of
+// users. Users manage their own; admins those of any user, and the
+// defaults of their tenant.
+type DeviceHandler struct {
+	service *service.DeviceService
+	logger  *logger.Logger
+}
+
+func NewDeviceHandler(service *service.DeviceService, logger *logger.Logger) *DeviceHandler {
+	return &DeviceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *DeviceHandler) RegisterDevice(c *gin.Context) {
+	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
+
+	var req domain.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	device, err := h.service.RegisterDevice(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, device)
+}
+
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
+
+	devices, err := h.service.ListDevices(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, devices)
+}
+
+func (h *DeviceHandler) RemoveDevice(c *gin.Context) {
+	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
+
+	if err := h.service.RemoveDevice(c.Request.Context(), id, c.Param("device_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DeviceHandler) GetPreferences(c *gin.Context) {
+	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
+
+	prefs, err := h.service.GetPreferences(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, prefs)
+}
+
+func (h *DeviceHandler) UpdatePreferences(c *gin.Context) {
+	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
+
+	var req domain.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	prefs, err := h.service.UpdatePreferences(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, prefs)
+}
+
+func (h *DeviceHandler) GetDefaults(c *gin.Context) {
+	defaults, err := h.service.GetDefaults(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, defaults)
+}
+
+func (h *DeviceHandler) UpdateDefaults(c *gin.Context) {
+	var req domain.UpdateNotificationDefaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	defaults, err := h.service.UpdateDefaults(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, defaults)
+}
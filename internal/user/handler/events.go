@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler adapts the replies of other services to data exports and
+// erasures consumed from Kafka to privacy service calls.
+type EventHandler struct {
+	privacy *service.PrivacyService
+	logger  *logger.Logger
+}
+
+func NewEventHandler(privacy *service.PrivacyService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{privacy: privacy, logger: logger}
+}
+
+// HandleDataExportPart stores the part of a data export a service sent.
+func (h *EventHandler) HandleDataExportPart(ctx context.Context, event events.UserDataExportPartEvent) error {
+	return h.privacy.AddDataExportPart(ctx, event.Data)
+}
+
+// HandleDataErased records the erasure a service reported for a deleted
+// user.
+func (h *EventHandler) HandleDataErased(ctx context.Context, event events.UserDataErasedEvent) error {
+	return h.privacy.AddErasedPart(ctx, event.Data)
+}
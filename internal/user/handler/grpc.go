@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/internal/common/grpcserver"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/grpc/userpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UserGRPCServer serves the internal gRPC API. Callers are other services
+// on the private network, so no end-user authorization is applied.
+type UserGRPCServer struct {
+	userpb.UnimplementedUserServiceServer
+	service domain.UserService
+}
+
+func NewUserGRPCServer(service domain.UserService) *UserGRPCServer {
+	return &UserGRPCServer{service: service}
+}
+
+func (s *UserGRPCServer) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.GetUserResponse, error) {
+	if err := grpcserver.RequireUUID("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	user, err := s.service.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcserver.ToStatus(err)
+	}
+
+	return &userpb.GetUserResponse{
+		User: &userpb.User{
+			Id:        user.ID,
+			Email:     user.Email,
+			Name:      user.Name,
+			Role:      user.Role,
+			Active:    user.Active,
+			CreatedAt: timestamppb.New(user.CreatedAt),
+			UpdatedAt: timestamppb.New(user.UpdatedAt),
+		},
+	}, nil
+}
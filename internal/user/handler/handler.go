@@ -3,31 +3,30 @@ package handler
 import (
 	"context"
 	"net/http"
+	"path"
 	"strconv"
+	"time"
 
+	"github.com/dmehra2102/booking-system/internal/common/errors"
 	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/middleware"
 	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
 	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/dmehra2102/booking-system/pkg/validation"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
 )
 
-type UserService interface {
-	CreateUser(ctx context.Context, req *domain.CreateUserRequest) (*domain.User, error)
-	Login(ctx context.Context, req *domain.LoginRequest) (*domain.LoginResponse, error)
-	GetUser(ctx context.Context, id string) (*domain.User, error)
-	UpdateUser(ctx context.Context, id string, req *domain.UpdateUserRequest) (*domain.User, error)
-	DeleteUser(ctx context.Context, id string) error
-	ListUsers(ctx context.Context, page, pageSize int) ([]*domain.User, int64, error)
-}
-
 type UserHandler struct {
-	service UserService
+	service domain.UserService
 	logger  *logger.Logger
 	tracer  trace.Tracer
 }
 
-func NewUserHandler(service UserService, logger *logger.Logger, tracer trace.Tracer) *UserHandler {
+func NewUserHandler(service domain.UserService, logger *logger.Logger, tracer trace.Tracer) *UserHandler {
 	return &UserHandler{
 		service: service,
 		logger:  logger,
@@ -38,59 +37,173 @@ func NewUserHandler(service UserService, logger *logger.Logger, tracer trace.Tra
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req domain.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+		response.ValidationError(c, err)
 		return
 	}
 
 	user, err := h.service.CreateUser(c.Request.Context(), &req)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, err)
+		c.Error(err)
 		return
 	}
 
-	response.Created(c, user)
+	response.CreatedWithLocation(c, path.Join(c.Request.URL.Path, user.ID), user)
+}
+
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req domain.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	user, err := h.service.VerifyEmail(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, user)
+}
+
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req domain.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if err := h.service.ForgotPassword(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Accepted whether or not the address is registered
+	c.Status(http.StatusAccepted)
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 func (h *UserHandler) Login(c *gin.Context) {
 	var req domain.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+		response.ValidationError(c, err)
+		return
+	}
+
+	loginResp, err := h.service.Login(clientContext(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, loginResp)
+}
+
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req domain.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
 		return
 	}
 
-	loginResp, err := h.service.Login(c.Request.Context(), &req)
+	loginResp, err := h.service.RefreshToken(clientContext(c), &req)
 	if err != nil {
-		response.Error(c, http.StatusUnauthorized, err)
+		c.Error(err)
 		return
 	}
 
 	response.Success(c, loginResp)
 }
 
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req domain.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.ValidationError(c, err)
+			return
+		}
+	}
+
+	expiresAt := c.GetTime("token_expires_at")
+	if err := h.service.Logout(c.Request.Context(), c.GetString("user_id"), c.GetString("token_id"), c.GetString("session_id"), expiresAt, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
 
 	user, err := h.service.GetUser(c.Request.Context(), id)
 	if err != nil {
-		response.Error(c, http.StatusNotFound, err)
+		c.Error(err)
 		return
 	}
 
-	response.Success(c, user)
+	response.SuccessWithETag(c, user.Version, user)
 }
 
+// UpdateUser changes the name or email of a user. With If-Match, the
+// change is only made if the user is still at that version.
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
 
 	var req domain.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ValidationError(c, err.Error())
+		response.ValidationError(c, err)
+		return
+	}
+
+	version, err := response.IfMatch(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	req.Version = version
 
 	user, err := h.service.UpdateUser(c.Request.Context(), id, &req)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, err)
+		c.Error(err)
+		return
+	}
+
+	response.SuccessWithETag(c, user.Version, user)
+}
+
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	id := c.Param("id")
+
+	var req domain.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	user, err := h.service.UpdateRole(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -99,42 +212,93 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
+	if !authorizeSelf(c, id) {
+		return
+	}
 
 	if err := h.service.DeleteUser(c.Request.Context(), id); err != nil {
-		response.Error(c, http.StatusNotFound, err)
+		c.Error(err)
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// ListUsers lists users with page or cursor pagination. Filters are role,
+// active (true by default, "all" for every user) and an RFC 3339
+// created_from/created_to range; sort is created_at, name or email, with a
+// "-" prefix for descending order.
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	page := 1
-	if p := c.Query("page"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
-			page = parsed
+	params, err := pagination.FromQuery(c, domain.UserSortFields, pagination.Sort{Field: "created_at", Desc: true})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter, err := listUsersFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	users, result, err := h.service.ListUsers(c.Request.Context(), filter, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, users, pagination.Response(params, result))
+}
+
+func listUsersFilter(c *gin.Context) (domain.ListUsersFilter, error) {
+	filter := domain.ListUsersFilter{Role: c.Query("role")}
+
+	if filter.Role != "" && filter.Role != auth.RoleUser && filter.Role != auth.RoleStaff && filter.Role != auth.RoleAdmin {
+		return filter, errors.NewValidationError("validation failed", validation.Invalid("role", "oneof", "user, staff, admin"))
+	}
+
+	switch active := c.DefaultQuery("active", "true"); active {
+	case "all":
+	default:
+		parsed, err := strconv.ParseBool(active)
+		if err != nil {
+			return filter, errors.NewValidationError("validation failed", validation.Invalid("active", "oneof", "true, false, all"))
 		}
+		filter.Active = &parsed
 	}
 
-	pageSize := 20
-	if ps := c.Query("page_size"); ps != "" {
-		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
-			pageSize = parsed
+	for name, target := range map[string]**time.Time{"created_from": &filter.CreatedFrom, "created_to": &filter.CreatedTo} {
+		if v := c.Query(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, errors.NewValidationError("validation failed", validation.Invalid(name, "rfc3339", ""))
+			}
+			*target = &parsed
 		}
 	}
 
-	users, total, err := h.service.ListUsers(c.Request.Context(), page, pageSize)
+	return filter, nil
+}
+
+// ExportSource reads the ListUsers filters of an admin export of users.
+func (h *UserHandler) ExportSource(c *gin.Context) (export.Source, error) {
+	filter, err := listUsersFilter(c)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err)
-		return
+		return nil, err
 	}
 
-	pagination := &response.Pagination{
-		Page:       page,
-		PageSize:   pageSize,
-		Total:      total,
-		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	return func(ctx context.Context, w export.Writer) (int, error) {
+		return h.service.ExportUsers(ctx, filter, w)
+	}, nil
+}
+
+// authorizeSelf allows users to act on their own account only, unless they
+// are an admin. It writes a 403 and returns false otherwise.
+func authorizeSelf(c *gin.Context, id string) bool {
+	if c.GetString("user_id") == id || middleware.HasRole(c, auth.RoleAdmin) {
+		return true
 	}
 
-	response.Paginated(c, users, pagination)
+	c.Error(errors.NewForbiddenError("you can only access your own account"))
+	return false
 }
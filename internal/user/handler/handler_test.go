@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeUserService creates users with a fixed ID; its other methods are
+// not used.
+type fakeUserService struct {
+	domain.UserService
+}
+
+func (fakeUserService) CreateUser(_ context.Context, req *domain.CreateUserRequest) (*domain.User, error) {
+	return &domain.User{ID: "6f1c2a8e-3b7d-4e59-9a0c-2d4f6b8e1a3c", Email: req.Email, Name: req.Name}, nil
+}
+
+func TestCreateUserLocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:         "created",
+			body:         `{"email":"ada@example.com","name":"Ada","password":"Secret-pass-1"}`,
+			wantStatus:   http.StatusCreated,
+			wantLocation: "/api/v1/users/6f1c2a8e-3b7d-4e59-9a0c-2d4f6b8e1a3c",
+		},
+		{
+			name:       "invalid body",
+			body:       `{"email":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewUserHandler(fakeUserService{}, logger.New("test", "error"), noop.NewTracerProvider().Tracer("test"))
+			router := gin.New()
+			router.POST("/api/v1/users", h.CreateUser)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if location := recorder.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", location, tt.wantLocation)
+			}
+		})
+	}
+}
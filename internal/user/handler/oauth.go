@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// oauthCookie holds the browser secret of a login in progress. It is only
+// sent to the Authorize path of the provider and the callback below it.
+const oauthCookie = "oauth_login"
+
+// OAuthHandler serves social login. Browsers are sent to the provider by
+// Authorize and return to Callback, which answers like POST /auth/login.
+type OAuthHandler struct {
+	service domain.OAuthService
+	logger  *logger.Logger
+}
+
+func NewOAuthHandler(service domain.OAuthService, logger *logger.Logger) *OAuthHandler {
+	return &OAuthHandler{service: service, logger: logger}
+}
+
+// Authorize redirects to the login page of the provider. Browsers
+// navigating here cannot send the X-Tenant-ID header, so the tenant may be
+// named by ?tenant_id= instead.
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	ctx := c.Request.Context()
+	if id := c.Query("tenant_id"); id != "" {
+		if err := uuid.Validate(id); err != nil {
+			c.Error(errors.NewValidationError("invalid tenant_id: must be a UUID", nil))
+			return
+		}
+		ctx = tenancy.WithID(ctx, id)
+	}
+
+	url, browserSecret, err := h.service.AuthorizationURL(ctx, c.Param("provider"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Lax, not Strict, so that the cookie comes along on the redirect back
+	// from the provider
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthCookie,
+		Value:    browserSecret,
+		Path:     c.Request.URL.Path,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Redirect(http.StatusFound, url)
+}
+
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	var req domain.OAuthCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+	req.BrowserSecret, _ = c.Cookie(oauthCookie)
+
+	// The state is used up either way
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthCookie,
+		Path:     strings.TrimSuffix(c.Request.URL.Path, "/callback"),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	loginResp, err := h.service.Callback(clientContext(c), c.Param("provider"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, loginResp)
+}
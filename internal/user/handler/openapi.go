@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	exportdomain "github.com/dmehra2102/booking-system/internal/export/domain"
+	exporthandler "github.com/dmehra2102/booking-system/internal/export/handler"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the user service HTTP API for the OpenAPI document.
+// Keep it in sync with setupRouter in cmd/user.
+func Routes() []openapi.Route {
+	timestamp := func(name, description string) openapi.Param {
+		return openapi.Param{Name: name, Description: description, Format: "date-time"}
+	}
+
+	filters := []openapi.Param{
+		{Name: "role", Enum: []string{"user", "staff", "admin"}},
+		{Name: "active", Description: "true (default), false or all", Enum: []string{"true", "false", "all"}},
+		timestamp("created_from", "Only users created at or after this time"),
+		timestamp("created_to", "Only users created before this time"),
+	}
+
+	routes := []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/v1/users", Summary: "Register a user", Tag: "users",
+			Request: domain.CreateUserRequest{}, Response: domain.User{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/api/v1/users/verify-email", Summary: "Verify an email address", Tag: "users",
+			Request: domain.VerifyEmailRequest{}, Response: domain.User{}},
+
+		{Method: http.MethodPost, Path: "/api/v1/auth/login", Summary: "Log in", Tag: "auth",
+			Request: domain.LoginRequest{}, Response: domain.LoginResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/auth/refresh", Summary: "Exchange a refresh token", Tag: "auth",
+			Request: domain.RefreshTokenRequest{}, Response: domain.LoginResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/auth/forgot-password", Summary: "Request a password reset email", Tag: "auth",
+			Request: domain.ForgotPasswordRequest{}, Status: http.StatusAccepted},
+		{Method: http.MethodPost, Path: "/api/v1/auth/reset-password", Summary: "Reset a password", Tag: "auth",
+			Request: domain.ResetPasswordRequest{}, Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/api/v1/auth/logout", Summary: "Log out", Tag: "auth", Auth: true,
+			Request: domain.LogoutRequest{}, RequestOptional: true, Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/api/v1/auth/logout-all", Summary: "Log out on all devices", Tag: "auth", Auth: true,
+			Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/api/v1/users/me/sessions", Summary: "List your sessions", Tag: "auth", Auth: true,
+			Response: []domain.Session{}},
+		{Method: http.MethodDelete, Path: "/api/v1/users/me/sessions/:id", Summary: "End one of your sessions", Tag: "auth", Auth: true,
+			Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/api/v1/auth/oauth/:provider", Summary: "Start a login with Google or GitHub", Tag: "auth",
+			Status: http.StatusFound, Query: []openapi.Param{
+				{Name: "tenant_id", Description: "Tenant to log in to, for browsers that cannot send X-Tenant-ID", Format: "uuid"},
+			}},
+		{Method: http.MethodGet, Path: "/api/v1/auth/oauth/:provider/callback", Summary: "Complete a login with Google or GitHub", Tag: "auth",
+			Response: domain.LoginResponse{}, Query: []openapi.Param{
+				{Name: "code", Description: "Authorization code issued by the provider"},
+				{Name: "state", Description: "State the login was started with"},
+				{Name: "error", Description: "Set by the provider when the user declined"},
+			}},
+		{Method: http.MethodPost, Path: "/api/v1/auth/login/2fa", Summary: "Complete a login with a second factor", Tag: "auth",
+			Request: domain.TwoFactorLoginRequest{}, Response: domain.LoginResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/auth/login/2fa/setup", Summary: "Set up the second factor a login requires", Tag: "auth",
+			Request: domain.TwoFactorTokenRequest{}, Response: domain.TwoFactorSetup{}},
+
+		{Method: http.MethodGet, Path: "/api/v1/users/me/2fa", Summary: "Get your two-factor enrollment", Tag: "auth", Auth: true,
+			Response: domain.TwoFactor{}},
+		{Method: http.MethodPost, Path: "/api/v1/users/me/2fa/setup", Summary: "Start a two-factor enrollment", Tag: "auth", Auth: true,
+			Response: domain.TwoFactorSetup{}},
+		{Method: http.MethodPost, Path: "/api/v1/users/me/2fa/enable", Summary: "Enable two-factor authentication", Tag: "auth", Auth: true,
+			Request: domain.TwoFactorCodeRequest{}, Response: domain.TwoFactorBackupCodes{}},
+		{Method: http.MethodPost, Path: "/api/v1/users/me/2fa/disable", Summary: "Disable two-factor authentication", Tag: "auth", Auth: true,
+			Request: domain.TwoFactorCodeRequest{}, Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/api/v1/users/me/2fa/backup-codes", Summary: "Regenerate backup codes", Tag: "auth", Auth: true,
+			Request: domain.TwoFactorCodeRequest{}, Response: domain.TwoFactorBackupCodes{}},
+
+		{Method: http.MethodPost, Path: "/api/v1/users/me/data-export", Summary: "Request a copy of your personal data", Tag: "users", Auth: true,
+			Response: domain.DataExport{}, Status: http.StatusAccepted},
+		{Method: http.MethodGet, Path: "/api/v1/users/me/data-export", Summary: "Get your latest data export", Tag: "users", Auth: true,
+			Response: domain.DataExport{}},
+		{Method: http.MethodGet, Path: "/api/v1/users/me/data-export/download", Summary: "Download your personal data as a ZIP archive", Tag: "users", Auth: true},
+
+		{Method: http.MethodGet, Path: "/api/v1/users/:id", Summary: "Get a user", Tag: "users", Auth: true,
+			Response: domain.User{}},
+		{Method: http.MethodPut, Path: "/api/v1/users/:id", Summary: "Update a user", Tag: "users", Auth: true,
+			Request: domain.UpdateUserRequest{}, Response: domain.User{}},
+		{Method: http.MethodDelete, Path: "/api/v1/users/:id", Summary: "Delete a user", Tag: "users", Auth: true,
+			Status: http.StatusNoContent},
+
+		{Method: http.MethodPost, Path: "/api/v1/users/:id/devices", Summary: "Register a device for push notifications", Tag: "notifications", Auth: true,
+			Request: domain.RegisterDeviceRequest{}, Response: domain.Device{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/users/:id/devices", Summary: "List a user's devices", Tag: "notifications", Auth: true,
+			Response: []domain.Device{}},
+		{Method: http.MethodDelete, Path: "/api/v1/users/:id/devices/:device_id", Summary: "Remove a device", Tag: "notifications", Auth: true,
+			Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/api/v1/users/:id/notification-preferences", Summary: "Get notification preferences", Tag: "notifications", Auth: true,
+			Response: domain.NotificationPreferences{}},
+		{Method: http.MethodPut, Path: "/api/v1/users/:id/notification-preferences", Summary: "Update notification preferences", Tag: "notifications", Auth: true,
+			Request: domain.UpdateNotificationPreferencesRequest{}, Response: domain.NotificationPreferences{}},
+
+		{Method: http.MethodGet, Path: "/api/v1/users", Summary: "List users", Tag: "users", Auth: true, Admin: true,
+			Response: domain.User{}, List: true, Query: filters},
+		{Method: http.MethodPut, Path: "/api/v1/users/:id/role", Summary: "Change a user's role", Tag: "users", Auth: true, Admin: true,
+			Request: domain.UpdateRoleRequest{}, Response: domain.User{}},
+		{Method: http.MethodGet, Path: "/api/v1/notification-defaults", Summary: "Get the notification defaults of the tenant", Tag: "notifications", Auth: true, Admin: true,
+			Response: domain.NotificationDefaults{}},
+		{Method: http.MethodPut, Path: "/api/v1/notification-defaults", Summary: "Replace the notification defaults of the tenant", Tag: "notifications", Auth: true, Admin: true,
+			Request: domain.UpdateNotificationDefaultsRequest{}, Response: domain.NotificationDefaults{}},
+	}
+
+	return append(routes, exporthandler.Routes(exportdomain.DatasetUsers, filters)...)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"archive/zip"
+	"io"
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyHandler serves the data export of the authenticated user under
+// /users/me/data-export.
+type PrivacyHandler struct {
+	service domain.PrivacyService
+	logger  *logger.Logger
+}
+
+func NewPrivacyHandler(service domain.PrivacyService, logger *logger.Logger) *PrivacyHandler {
+	return &PrivacyHandler{service: service, logger: logger}
+}
+
+func (h *PrivacyHandler) RequestDataExport(c *gin.Context) {
+	export, err := h.service.RequestDataExport(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Accepted(c, c.Request.URL.Path, export)
+}
+
+func (h *PrivacyHandler) GetDataExport(c *gin.Context) {
+	export, err := h.service.GetDataExport(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, export)
+}
+
+// DownloadDataExport answers with a ZIP archive holding a JSON file for
+// every part of the ready export.
+func (h *PrivacyHandler) DownloadDataExport(c *gin.Context) {
+	export, parts, err := h.service.DownloadDataExport(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+export.Filename()+`"`)
+	c.Status(http.StatusOK)
+	if err := writeDataExport(c.Writer, parts); err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("failed to write data export")
+	}
+}
+
+func writeDataExport(w io.Writer, parts []*domain.DataExportPart) error {
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := archive.Create(part.Part + ".json")
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.Content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler serves the login sessions of the authenticated user
+// under /users/me/sessions.
+type SessionHandler struct {
+	service domain.SessionService
+	logger  *logger.Logger
+}
+
+func NewSessionHandler(service domain.SessionService, logger *logger.Logger) *SessionHandler {
+	return &SessionHandler{service: service, logger: logger}
+}
+
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.service.ListSessions(c.Request.Context(), c.GetString("user_id"), c.GetString("session_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+func (h *SessionHandler) EndSession(c *gin.Context) {
+	if err := h.service.EndSession(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EndAllSessions logs the user out on all their devices.
+func (h *SessionHandler) EndAllSessions(c *gin.Context) {
+	if err := h.service.EndAllSessions(c.Request.Context(), c.GetString("user_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// clientContext returns the context of the request carrying its client,
+// which is recorded in the session a login starts or refreshes.
+func clientContext(c *gin.Context) context.Context {
+	return domain.WithClient(c.Request.Context(), domain.Client{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler serves the two-factor enrollment of the authenticated
+// user under /users/me/2fa, and the second step of logins.
+type TwoFactorHandler struct {
+	service domain.TwoFactorService
+	logger  *logger.Logger
+}
+
+func NewTwoFactorHandler(service domain.TwoFactorService, logger *logger.Logger) *TwoFactorHandler {
+	return &TwoFactorHandler{service: service, logger: logger}
+}
+
+func (h *TwoFactorHandler) Get(c *gin.Context) {
+	tf, err := h.service.Get(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, tf)
+}
+
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	setup, err := h.service.Setup(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req domain.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	codes, err := h.service.Enable(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, codes)
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req domain.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if err := h.service.Disable(c.Request.Context(), c.GetString("user_id"), &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TwoFactorHandler) RegenerateBackupCodes(c *gin.Context) {
+	var req domain.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	codes, err := h.service.RegenerateBackupCodes(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, codes)
+}
+
+// SetupLogin starts the enrollment of a user whose login answered with
+// two_factor_setup_required.
+func (h *TwoFactorHandler) SetupLogin(c *gin.Context) {
+	var req domain.TwoFactorTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	setup, err := h.service.SetupLogin(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+func (h *TwoFactorHandler) CompleteLogin(c *gin.Context) {
+	var req domain.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	loginResp, err := h.service.CompleteLogin(clientContext(c), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, loginResp)
+}
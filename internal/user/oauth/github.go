@@ -0,0 +1,99 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+)
+
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubAPIURL   = "https://api.github.com"
+	githubScope    = "read:user user:email"
+)
+
+// GitHub authenticates users with GitHub. GitHub does not speak OpenID
+// Connect, so the account is read from its REST API.
+type GitHub struct {
+	client       *httpclient.Client
+	clientID     string
+	clientSecret string
+}
+
+func NewGitHub(clientID, clientSecret string, client *httpclient.Client) *GitHub {
+	return &GitHub{client: client, clientID: clientID, clientSecret: clientSecret}
+}
+
+func (g *GitHub) AuthCodeURL(state, redirectURI string) string {
+	return authCodeURL(githubAuthURL, g.clientID, redirectURI, githubScope, state)
+}
+
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// Identify exchanges the code and reads the account with the primary
+// email address, which GitHub reports as verified or not.
+func (g *GitHub) Identify(ctx context.Context, code, redirectURI string) (*domain.ExternalIdentity, error) {
+	token, err := exchange(ctx, g.client, githubTokenURL, g.clientID, g.clientSecret, code, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubUser
+	if err := g.get(ctx, token.AccessToken, "/user", &user); err != nil {
+		return nil, err
+	}
+	var emails []githubEmail
+	if err := g.get(ctx, token.AccessToken, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &domain.ExternalIdentity{
+		Provider: domain.ProviderGitHub,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func (g *GitHub) get(ctx context.Context, accessToken, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPIURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build GitHub request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	status, err := doJSON(g.client, req, out)
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		return fmt.Errorf("GitHub responded %d to %s", status, path)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package oauth authenticates users with external identity providers
+// through the OAuth 2.0 authorization code flow.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+)
+
+// tokenResponse is the part of a token endpoint response the providers
+// use.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// exchange trades an authorization code for the tokens of the user at
+// tokenURL, authenticating with the client ID and secret in the form.
+func exchange(ctx context.Context, client *httpclient.Client, tokenURL, clientID, clientSecret, code, redirectURI string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	status, err := doJSON(client, req, &token)
+	if err != nil {
+		return nil, err
+	}
+	// GitHub reports failed exchanges with 200 and an error field
+	if status >= 300 || token.Error != "" {
+		return nil, fmt.Errorf("token endpoint responded %d %s: %s", status, token.Error, token.Description)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned no access token")
+	}
+
+	return &token, nil
+}
+
+// doJSON sends req and decodes the response body into out. It returns the
+// status, leaving its handling to the caller.
+func doJSON(client *httpclient.Client, req *http.Request, out any) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil && resp.StatusCode < 300 {
+		return resp.StatusCode, fmt.Errorf("failed to decode response of %s: %w", req.URL.Host, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// authCodeURL returns endpoint with the parameters of an authorization
+// request added.
+func authCodeURL(endpoint, clientID, redirectURI, scope, state string) string {
+	return endpoint + "?" + url.Values{
+		"response_type": {"code"},
+		"client_id":     {clientID},
+		"redirect_uri":  {redirectURI},
+		"scope":         {scope},
+		"state":         {state},
+	}.Encode()
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/cache"
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// UserRepository is the persistence API decorated by CachedUserRepository.
+type UserRepository interface {
+	Create(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, id string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	Update(ctx context.Context, id string, version int64, updates map[string]any) error
+	Delete(ctx context.Context, id string) (int, error)
+	List(ctx context.Context, filter domain.ListUsersFilter, params pagination.Params) ([]*domain.User, *pagination.Result, error)
+}
+
+// cachedUser mirrors domain.User including the password hash, which the
+// domain type deliberately omits from its JSON form.
+type cachedUser struct {
+	ID            string    `json:"id"`
+	TenantID      string    `json:"tenant_id"`
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	PasswordHash  string    `json:"password_hash"`
+	Role          string    `json:"role"`
+	Active        bool      `json:"active"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int64     `json:"version"`
+}
+
+// CachedUserRepository serves GetByID from Redis and invalidates the entry
+// whenever the user is updated or deleted through it.
+type CachedUserRepository struct {
+	UserRepository
+	cache *cache.Cache[cachedUser]
+}
+
+func NewCachedUserRepository(inner UserRepository, redis *database.RedisClient, ttl time.Duration, logger *logger.Logger, metrics *metrics.Metrics) *CachedUserRepository {
+	return &CachedUserRepository{
+		UserRepository: inner,
+		cache:          cache.New[cachedUser](redis, logger, metrics, "user", ttl),
+	}
+}
+
+func (r *CachedUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	if cached, ok := r.cache.Get(ctx, id); ok {
+		return &domain.User{
+			ID:            cached.ID,
+			TenantID:      cached.TenantID,
+			Email:         cached.Email,
+			Name:          cached.Name,
+			Password:      cached.PasswordHash,
+			Role:          cached.Role,
+			Active:        cached.Active,
+			EmailVerified: cached.EmailVerified,
+			CreatedAt:     cached.CreatedAt,
+			UpdatedAt:     cached.UpdatedAt,
+			Version:       cached.Version,
+		}, nil
+	}
+
+	user, err := r.UserRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(ctx, id, &cachedUser{
+		ID:            user.ID,
+		TenantID:      user.TenantID,
+		Email:         user.Email,
+		Name:          user.Name,
+		PasswordHash:  user.Password,
+		Role:          user.Role,
+		Active:        user.Active,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Version:       user.Version,
+	})
+
+	return user, nil
+}
+
+func (r *CachedUserRepository) Update(ctx context.Context, id string, version int64, updates map[string]any) error {
+	defer r.cache.Delete(ctx, id)
+	return r.UserRepository.Update(ctx, id, version, updates)
+}
+
+func (r *CachedUserRepository) Delete(ctx context.Context, id string) (int, error) {
+	defer r.cache.Delete(ctx, id)
+	return r.UserRepository.Delete(ctx, id)
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/crypto"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresDeviceRepository stores the push devices and notification
+// preferences of users. Phone numbers are encrypted with fields.
+type PostgresDeviceRepository struct {
+	db     *database.PostgresDB
+	fields *crypto.Envelope
+	tracer trace.Tracer
+}
+
+func NewPostgresDeviceRepository(db *database.PostgresDB, fields *crypto.Envelope, tracer trace.Tracer) *PostgresDeviceRepository {
+	return &PostgresDeviceRepository{
+		db:     db,
+		fields: fields,
+		tracer: tracer,
+	}
+}
+
+// UpsertDevice registers device. A token that is registered already is
+// moved to device.UserID and keeps its ID.
+func (r *PostgresDeviceRepository) UpsertDevice(ctx context.Context, device *domain.Device) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.upsert_device", trace.WithAttributes(tracing.UserID.String(device.UserID)))
+	defer tracing.End(span, &err)
+
+	now := time.Now().UTC()
+	query := `
+		INSERT INTO user_devices (id, tenant_id, user_id, token, platform, created_at, last_seen_at)
+		SELECT $1, $2, $3, $4, $5, $6, $6
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $3 AND tenant_id = $2)
+		ON CONFLICT (token) DO UPDATE
+			SET tenant_id = EXCLUDED.tenant_id, user_id = EXCLUDED.user_id,
+				platform = EXCLUDED.platform, last_seen_at = EXCLUDED.last_seen_at
+		RETURNING id, created_at, last_seen_at
+	`
+
+	err = r.db.QueryRow(ctx, "user.upsert_device", query,
+		uuid.New().String(), tenancy.ID(ctx), device.UserID, device.Token, device.Platform, now,
+	).Scan(&device.ID, &device.CreatedAt, &device.LastSeenAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewNotFoundError("user")
+		}
+		return errors.NewInternalError("failed to register device", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresDeviceRepository) ListDevices(ctx context.Context, userID string) (_ []*domain.Device, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.list_devices", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, token, platform, created_at, last_seen_at
+		FROM user_devices
+		WHERE user_id = $1 AND tenant_id = $2
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, "user.list_devices", query, userID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list devices", err)
+	}
+	devices, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Device, error) {
+		device := &domain.Device{}
+		err := row.Scan(&device.ID, &device.UserID, &device.Token, &device.Platform, &device.CreatedAt, &device.LastSeenAt)
+		return device, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan devices", err)
+	}
+
+	return devices, nil
+}
+
+// DeleteDevice removes a device of userID and returns it.
+func (r *PostgresDeviceRepository) DeleteDevice(ctx context.Context, userID, id string) (_ *domain.Device, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.delete_device", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		DELETE FROM user_devices
+		WHERE id = $1 AND user_id = $2 AND tenant_id = $3
+		RETURNING id, user_id, token, platform, created_at, last_seen_at
+	`
+
+	device := &domain.Device{}
+	err = r.db.QueryRow(ctx, "user.delete_device", query, id, userID, tenancy.ID(ctx)).Scan(
+		&device.ID, &device.UserID, &device.Token, &device.Platform, &device.CreatedAt, &device.LastSeenAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("device")
+		}
+		return nil, errors.NewInternalError("failed to delete device", err)
+	}
+
+	return device, nil
+}
+
+// GetPreferences returns the notification preferences of userID, or the
+// defaults of the tenant when they never set any.
+func (r *PostgresDeviceRepository) GetPreferences(ctx context.Context, userID string) (_ *domain.NotificationPreferences, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.get_preferences", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT user_id, email, sms, push, categories, quiet_hours, phone, locale, updated_at
+		FROM user_notification_preferences
+		WHERE user_id = $1 AND tenant_id = $2
+	`
+
+	prefs := &domain.NotificationPreferences{}
+	var categories, quietHours []byte
+	err = r.db.QueryRow(ctx, "user.get_preferences", query, userID, tenancy.ID(ctx)).Scan(
+		&prefs.UserID, &prefs.Email, &prefs.SMS, &prefs.Push, &categories, &quietHours, &prefs.Phone, &prefs.Locale, &prefs.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			defaults, err := r.GetDefaults(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return defaults.Preferences(userID), nil
+		}
+		return nil, errors.NewInternalError("failed to get notification preferences", err)
+	}
+
+	if err := decodeNotificationSettings(categories, quietHours, &prefs.Categories, &prefs.QuietHours); err != nil {
+		return nil, errors.NewInternalError("failed to decode notification preferences", err)
+	}
+	if prefs.Phone, err = r.fields.Decrypt(ctx, prefs.Phone); err != nil {
+		return nil, errors.NewInternalError("failed to decrypt phone number", err)
+	}
+
+	return prefs, nil
+}
+
+func (r *PostgresDeviceRepository) UpsertPreferences(ctx context.Context, prefs *domain.NotificationPreferences) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.upsert_preferences", trace.WithAttributes(tracing.UserID.String(prefs.UserID)))
+	defer tracing.End(span, &err)
+
+	categories, quietHours, err := encodeNotificationSettings(prefs.Categories, prefs.QuietHours)
+	if err != nil {
+		return errors.NewInternalError("failed to encode notification preferences", err)
+	}
+	phone, err := r.fields.Encrypt(ctx, prefs.Phone)
+	if err != nil {
+		return errors.NewInternalError("failed to encrypt phone number", err)
+	}
+
+	prefs.UpdatedAt = time.Now().UTC()
+	query := `
+		INSERT INTO user_notification_preferences (
+			user_id, tenant_id, email, sms, push, categories, quiet_hours, phone, locale, updated_at
+		)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2)
+		ON CONFLICT (user_id) DO UPDATE
+			SET email = EXCLUDED.email, sms = EXCLUDED.sms, push = EXCLUDED.push,
+				categories = EXCLUDED.categories, quiet_hours = EXCLUDED.quiet_hours,
+				phone = EXCLUDED.phone, locale = EXCLUDED.locale, updated_at = EXCLUDED.updated_at
+			WHERE user_notification_preferences.tenant_id = EXCLUDED.tenant_id
+	`
+
+	result, err := r.db.Exec(ctx, "user.upsert_preferences", query,
+		prefs.UserID, tenancy.ID(ctx), prefs.Email, prefs.SMS, prefs.Push, categories, quietHours,
+		phone, prefs.Locale, prefs.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to save notification preferences", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("failed to check save result", err)
+	}
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("user")
+	}
+
+	return nil
+}
+
+// GetDefaults returns the notification defaults of the tenant, or the
+// built-in defaults when it never set any.
+func (r *PostgresDeviceRepository) GetDefaults(ctx context.Context) (_ *domain.NotificationDefaults, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.get_notification_defaults")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT email, sms, push, categories, quiet_hours, updated_at
+		FROM tenant_notification_defaults
+		WHERE tenant_id = $1
+	`
+
+	defaults := &domain.NotificationDefaults{}
+	var categories, quietHours []byte
+	err = r.db.QueryRow(ctx, "user.get_notification_defaults", query, tenancy.ID(ctx)).Scan(
+		&defaults.Email, &defaults.SMS, &defaults.Push, &categories, &quietHours, &defaults.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.BuiltinNotificationDefaults(), nil
+		}
+		return nil, errors.NewInternalError("failed to get notification defaults", err)
+	}
+
+	if err := decodeNotificationSettings(categories, quietHours, &defaults.Categories, &defaults.QuietHours); err != nil {
+		return nil, errors.NewInternalError("failed to decode notification defaults", err)
+	}
+
+	return defaults, nil
+}
+
+func (r *PostgresDeviceRepository) UpsertDefaults(ctx context.Context, defaults *domain.NotificationDefaults) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.upsert_notification_defaults")
+	defer tracing.End(span, &err)
+
+	categories, quietHours, err := encodeNotificationSettings(defaults.Categories, defaults.QuietHours)
+	if err != nil {
+		return errors.NewInternalError("failed to encode notification defaults", err)
+	}
+
+	defaults.UpdatedAt = time.Now().UTC()
+	query := `
+		INSERT INTO tenant_notification_defaults (tenant_id, email, sms, push, categories, quiet_hours, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE
+			SET email = EXCLUDED.email, sms = EXCLUDED.sms, push = EXCLUDED.push,
+				categories = EXCLUDED.categories, quiet_hours = EXCLUDED.quiet_hours, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.Exec(ctx, "user.upsert_notification_defaults", query,
+		tenancy.ID(ctx), defaults.Email, defaults.SMS, defaults.Push, categories, quietHours, defaults.UpdatedAt,
+	); err != nil {
+		return errors.NewInternalError("failed to save notification defaults", err)
+	}
+
+	return nil
+}
+
+// Categories and quiet hours are stored as JSONB; quiet_hours is NULL
+// when there are none.
+func encodeNotificationSettings(categories map[domain.NotificationCategory]domain.NotificationChannels, quietHours *domain.QuietHours) ([]byte, []byte, error) {
+	if categories == nil {
+		categories = make(map[domain.NotificationCategory]domain.NotificationChannels)
+	}
+	encodedCategories, err := json.Marshal(categories)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var encodedQuietHours []byte
+	if quietHours != nil {
+		if encodedQuietHours, err = json.Marshal(quietHours); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return encodedCategories, encodedQuietHours, nil
+}
+
+func decodeNotificationSettings(categories, quietHours []byte, decodedCategories *map[domain.NotificationCategory]domain.NotificationChannels, decodedQuietHours **domain.QuietHours) error {
+	*decodedCategories = make(map[domain.NotificationCategory]domain.NotificationChannels)
+	if len(categories) > 0 {
+		if err := json.Unmarshal(categories, decodedCategories); err != nil {
+			return fmt.Errorf("failed to decode categories: %w", err)
+		}
+	}
+
+	if len(quietHours) > 0 {
+		if err := json.Unmarshal(quietHours, decodedQuietHours); err != nil {
+			return fmt.Errorf("failed to decode quiet hours: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresIdentityRepository stores the identity provider accounts users
+// log in with.
+type PostgresIdentityRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresIdentityRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresIdentityRepository {
+	return &PostgresIdentityRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+// CreateIdentity links identity to its user. An account that is linked
+// already, or a second account of the same provider, is a conflict.
+func (r *PostgresIdentityRepository) CreateIdentity(ctx context.Context, identity *domain.Identity) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.create_identity", trace.WithAttributes(tracing.UserID.String(identity.UserID)))
+	defer tracing.End(span, &err)
+
+	identity.ID = uuid.New().String()
+	identity.CreatedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO user_identities (id, tenant_id, user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = r.db.Exec(ctx, "user.create_identity", query,
+		identity.ID, tenancy.ID(ctx), identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt,
+	)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return errors.NewConflictError("account is already linked to another account of this provider")
+		}
+		return errors.NewInternalError("failed to link identity", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresIdentityRepository) GetIdentity(ctx context.Context, provider, subject string) (_ *domain.Identity, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.get_identity")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2 AND tenant_id = $3
+	`
+
+	identity := &domain.Identity{}
+	err = r.db.QueryRow(ctx, "user.get_identity", query, provider, subject, tenancy.ID(ctx)).Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("identity")
+		}
+		return nil, errors.NewInternalError("failed to get identity", err)
+	}
+
+	return identity, nil
+}
+
+func (r *PostgresIdentityRepository) ListIdentities(ctx context.Context, userID string) (_ []*domain.Identity, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.list_identities", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE user_id = $1 AND tenant_id = $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, "user.list_identities", query, userID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list identities", err)
+	}
+	identities, err := database.ScanAll(rows, func(row database.Scanner) (*domain.Identity, error) {
+		identity := &domain.Identity{}
+		err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt)
+		return identity, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan identities", err)
+	}
+
+	return identities, nil
+}
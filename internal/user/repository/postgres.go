@@ -8,7 +8,11 @@ import (
 
 	"github.com/dmehra2102/booking-system/internal/common/database"
 	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
 	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -22,24 +26,46 @@ func NewPostgresUserRepository(db *database.PostgresDB, tracer trace.Tracer) *Po
 	return &PostgresUserRepository{db: db, tracer: tracer}
 }
 
-func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) error {
+const selectUserQuery = `
+	SELECT id, tenant_id, email, name, password_hash, role, active, email_verified, created_at, updated_at, version
+	FROM users
+`
+
+// userColumns are the columns Update may set.
+var userColumns = database.NewColumns("name", "email", "email_verified", "role", "password_hash", "updated_at")
+
+func scanUser(row database.Scanner) (*domain.User, error) {
+	user := &domain.User{}
+	err := row.Scan(
+		&user.ID, &user.TenantID, &user.Email, &user.Name, &user.Password, &user.Role,
+		&user.Active, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt, &user.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) (err error) {
 	ctx, span := r.tracer.Start(ctx, "repository.create")
-	defer span.End()
+	defer tracing.End(span, &err)
 
 	user.ID = uuid.New().String()
+	user.TenantID = tenancy.ID(ctx)
 	user.CreatedAt = time.Now().UTC()
 	user.UpdatedAt = time.Now().UTC()
 	user.Active = true
-	user.Role = "user"
+	user.Role = auth.RoleUser
+	user.Version = 1
 
 	query := `
-		INSERT INTO users (id, email, name,password_hash, role, active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (id, tenant_id, email, name, password_hash, role, active, email_verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
-	_, err := r.db.Exec(ctx, query, user.ID, user.Email, user.Name, user.Password, user.Role, user.Active, user.CreatedAt, user.UpdatedAt)
+	_, err = r.db.Exec(ctx, "user.create", query, user.ID, user.TenantID, user.Email, user.Name, user.Password, user.Role, user.Active, user.EmailVerified, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
-		if isDuplicateError(err) {
+		if database.IsUniqueViolation(err) {
 			return errors.NewConflictError("user with this email already exists")
 		}
 		return errors.NewInternalError("failed to create user", err)
@@ -48,21 +74,11 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User)
 	return nil
 }
 
-func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	ctx, span := r.tracer.Start(ctx, "user.repository.get_by_id")
-	defer span.End()
-
-	query := `
-		SELECT id, email, name, password_hash, role, active, created_at, updated_at
-		FROM users WHERE id = $1 AND active = true
-	`
-
-	user := &domain.User{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.Name, &user.Password,
-		&user.Role, &user.Active, &user.CreatedAt, &user.UpdatedAt,
-	)
+func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (_ *domain.User, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.get_by_id", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
 
+	user, err := scanUser(r.db.QueryRow(ctx, "user.get_by_id", selectUserQuery+` WHERE id = $1 AND tenant_id = $2 AND active = true`, database.Prepared(id, tenancy.ID(ctx))...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("user")
@@ -73,21 +89,17 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*domai
 	return user, nil
 }
 
-func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+// GetByEmail finds active users of active tenants only, so the users of a
+// deactivated tenant can no longer log in.
+func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (_ *domain.User, err error) {
 	ctx, span := r.tracer.Start(ctx, "user.repostiory.get_by_email")
-	defer span.End()
+	defer tracing.End(span, &err)
 
-	query := `
-		SELECT id, email, name, password_hash, role, active, created_at, updated_at
-		FROM users WHERE email = $1 AND active = true
+	query := selectUserQuery + `
+		WHERE email = $1 AND tenant_id = $2 AND active = true
+			AND EXISTS (SELECT 1 FROM tenants WHERE id = users.tenant_id AND active)
 	`
-
-	user := &domain.User{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.Name, &user.Password,
-		&user.Role, &user.Active, &user.CreatedAt, &user.UpdatedAt,
-	)
-
+	user, err := scanUser(r.db.QueryRow(ctx, "user.get_by_email", query, database.Prepared(email, tenancy.ID(ctx))...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("user")
@@ -98,9 +110,12 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 	return user, nil
 }
 
-func (r *PostgresUserRepository) Update(ctx context.Context, id string, updates map[string]any) error {
-	ctx, span := r.tracer.Start(ctx, "user.repository.update")
-	defer span.End()
+// Update sets the given columns of the user if it is still at version, and
+// increments the version. A user that changed since it was read at version
+// is reported as a conflict.
+func (r *PostgresUserRepository) Update(ctx context.Context, id string, version int64, updates map[string]any) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.update", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
 
 	if len(updates) == 0 {
 		return nil
@@ -108,20 +123,15 @@ func (r *PostgresUserRepository) Update(ctx context.Context, id string, updates
 
 	updates["updated_at"] = time.Now().UTC()
 
-	setParts := make([]string, 0, len(updates))
-	args := make([]any, 0, len(updates)+1)
-	argIndex := 1
-
-	for field, value := range updates {
-		setParts = append(setParts, fmt.Sprintf("%s = $%d", field, argIndex))
-		args = append(args, value)
-		argIndex++
+	var args database.Args
+	set, err := userColumns.Set(updates, &args)
+	if err != nil {
+		return errors.NewInternalError("invalid user update", err)
 	}
+	query := "UPDATE users SET " + set + ", version = version + 1" +
+		" WHERE id = " + args.Add(id) + " AND tenant_id = " + args.Add(tenancy.ID(ctx)) + " AND version = " + args.Add(version)
 
-	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $%d", joinStrings(setParts, ", "), argIndex)
-	args = append(args, id)
-
-	result, err := r.db.Exec(ctx, query, args...)
+	result, err := r.db.Exec(ctx, "user.update", query, args...)
 	if err != nil {
 		return errors.NewInternalError("failed to update user", err)
 	}
@@ -132,107 +142,169 @@ func (r *PostgresUserRepository) Update(ctx context.Context, id string, updates
 	}
 
 	if rowsAffected == 0 {
-		return errors.NewNotFoundError("user")
+		var exists bool
+		err := r.db.QueryRow(ctx, "user.exists", `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2)`, id, tenancy.ID(ctx)).Scan(&exists)
+		if err != nil {
+			return errors.NewInternalError("failed to check update result", err)
+		}
+		if !exists {
+			return errors.NewNotFoundError("user")
+		}
+		return errors.NewConflictError("user was changed by another request, reload it and try again")
 	}
 
 	return nil
 }
 
-func (r *PostgresUserRepository) Delete(ctx context.Context, id string) error {
-	ctx, span := r.tracer.Start(ctx, "user.repository.delete")
-	defer span.End()
+// anonymizedName replaces the name of deleted users.
+const anonymizedName = "Deleted user"
 
-	query := `UPDATE users SET active = false, updated_at = $1 WHERE id = $2`
+// personalDataTables hold personal data of users that is removed with
+// them.
+var personalDataTables = []string{
+	"user_notification_preferences", "user_devices", "user_sessions",
+	"user_two_factor", "user_identities", "user_data_exports",
+}
 
-	result, err := r.db.Exec(ctx, query, time.Now().UTC(), id)
-	if err != nil {
-		return errors.NewInternalError("failed to delete user", err)
-	}
+// Delete anonymizes a user: their email address, name and password are
+// replaced and the personal data stored with them is removed, revoking
+// their API keys. The user keeps their ID, which the records of other
+// services refer to. It returns the number of records erased.
+func (r *PostgresUserRepository) Delete(ctx context.Context, id string) (_ int, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.delete", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
+
+	now := time.Now().UTC()
+	erased := 0
+	err = r.db.WithTx(ctx, func(ctx context.Context) error {
+		query := `
+			UPDATE users
+			SET email = 'deleted-' || id || '@deleted.invalid', name = $1, password_hash = '',
+				email_verified = false, active = false, updated_at = $2, version = version + 1
+			WHERE id = $3 AND tenant_id = $4 AND active
+		`
+
+		result, err := r.db.Exec(ctx, "user.delete", query, anonymizedName, now, id, tenancy.ID(ctx))
+		if err != nil {
+			return errors.NewInternalError("failed to delete user", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return errors.NewInternalError("failed to check delete result", err)
+		}
+		if rowsAffected == 0 {
+			return errors.NewNotFoundError("user")
+		}
+		erased++
+
+		for _, table := range personalDataTables {
+			result, err := r.db.Exec(ctx, "user.delete_"+table, `DELETE FROM `+table+` WHERE user_id = $1 AND tenant_id = $2`, id, tenancy.ID(ctx))
+			if err != nil {
+				return errors.NewInternalError("failed to erase personal data", err)
+			}
+			rows, _ := result.RowsAffected()
+			erased += int(rows)
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		query = `UPDATE api_keys SET revoked_at = $1 WHERE user_id = $2 AND tenant_id = $3 AND revoked_at IS NULL`
+		result, err = r.db.Exec(ctx, "user.revoke_api_keys", query, now, id, tenancy.ID(ctx))
+		if err != nil {
+			return errors.NewInternalError("failed to revoke API keys", err)
+		}
+		rows, _ := result.RowsAffected()
+		erased += int(rows)
+
+		return nil
+	})
 	if err != nil {
-		return errors.NewInternalError("failed to check delete result", err)
+		return 0, err
 	}
 
-	if rowsAffected == 0 {
-		return errors.NewNotFoundError("user")
-	}
+	return erased, nil
+}
 
-	return nil
+// userSortColumns maps the sortable fields to their SQL types for keyset
+// comparisons.
+var userSortColumns = map[string]string{
+	"created_at": "timestamptz",
+	"name":       "text",
+	"email":      "text",
 }
 
-func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, int64, error) {
+func (r *PostgresUserRepository) List(ctx context.Context, filter domain.ListUsersFilter, params pagination.Params) (_ []*domain.User, _ *pagination.Result, err error) {
 	ctx, span := r.tracer.Start(ctx, "user.repository.list")
-	defer span.End()
+	defer tracing.End(span, &err)
 
-	countQuery := `SELECT COUNT(*) FROM users WHERE active = true`
-	var total int64
-	err := r.db.QueryRow(ctx, countQuery).Scan(&total)
-	if err != nil {
-		return nil, 0, errors.NewInternalError("failed to count users", err)
+	sortField := params.Sort.Field
+	if _, ok := userSortColumns[sortField]; !ok {
+		sortField = "created_at"
 	}
 
-	query := `
-		SELECT id, email, name, password_hash, role, active, created_at, updated_at
-		FROM users WHERE active = true
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSER $2
-	`
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
 
-	rows, err := r.db.Query(ctx, query, limit, offset)
-	if err != nil {
-		return nil, 0, errors.NewInternalError("failed to list users", err)
+	add("tenant_id = $%d", tenancy.ID(ctx))
+	if filter.Role != "" {
+		add("role = $%d", filter.Role)
+	}
+	if filter.Active != nil {
+		add("active = $%d", *filter.Active)
+	}
+	if filter.CreatedFrom != nil {
+		add("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("created_at < $%d", *filter.CreatedTo)
 	}
-	defer rows.Close()
 
-	users := make([]*domain.User, 0)
-	for rows.Next() {
-		user := &domain.User{}
-		err := rows.Scan(
-			&user.ID, &user.Email, &user.Name, &user.Password,
-			&user.Role, &user.Active, &user.CreatedAt, &user.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, errors.NewInternalError("failed to scan user", err)
-		}
-		users = append(users, user)
+	var total int64
+	err = r.db.QueryRow(ctx, "user.count", "SELECT COUNT(*) FROM users "+database.Where(conditions), args...).Scan(&total)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to count users", err)
 	}
 
-	return users, total, nil
-}
+	if after, afterArgs := params.After(sortField, userSortColumns[sortField], "id", len(args)+1); after != "" {
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
+	}
 
-// Helper functions
-func isDuplicateError(err error) bool {
-	// PostgreSQL duplicate key error code is 23505
-	return err != nil && (contains(err.Error(), "duplicate") ||
-		contains(err.Error(), "unique constraint") ||
-		contains(err.Error(), "23505"))
-}
+	// Fetch one extra row to learn whether another page follows.
+	query := selectUserQuery + fmt.Sprintf(`
+		%s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, database.Where(conditions), params.OrderBy(sortField, "id"), len(args)+1, len(args)+2)
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
-		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsSubstring(s, substr)))
-}
+	rows, err := r.db.Query(ctx, "user.list", query, append(args, params.PageSize+1, params.Offset())...)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to list users", err)
+	}
+	users, err := database.ScanAll(rows, scanUser)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to scan users", err)
+	}
 
-func containsSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
+	result := &pagination.Result{Total: total}
+	if len(users) > params.PageSize {
+		users = users[:params.PageSize]
+		last := users[len(users)-1]
+		result.NextCursor = params.Next(userSortValue(last, sortField), last.ID)
 	}
-	return false
+
+	return users, result, nil
 }
 
-func joinStrings(strs []string, sep string) string {
-	if len(strs) == 0 {
-		return ""
-	}
-	if len(strs) == 1 {
-		return strs[0]
-	}
-	result := strs[0]
-	for i := 1; i < len(strs); i++ {
-		result += sep + strs[i]
+func userSortValue(user *domain.User, field string) string {
+	switch field {
+	case "name":
+		return user.Name
+	case "email":
+		return user.Email
+	default:
+		return user.CreatedAt.Format(time.RFC3339Nano)
 	}
-	return result
 }
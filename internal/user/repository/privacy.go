@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresPrivacyRepository stores the data exports users requested and
+// the erasures of deleted users, with the parts the services sent for
+// them.
+type PostgresPrivacyRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresPrivacyRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresPrivacyRepository {
+	return &PostgresPrivacyRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+// CreateDataExport records a pending export, replacing the earlier
+// exports of the user.
+func (r *PostgresPrivacyRepository) CreateDataExport(ctx context.Context, e *domain.DataExport) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.create_data_export", trace.WithAttributes(tracing.UserID.String(e.UserID)))
+	defer tracing.End(span, &err)
+
+	e.ID = uuid.New().String()
+	e.Status = domain.DataExportStatusPending
+	e.Parts = []string{}
+	e.CreatedAt = time.Now().UTC()
+
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		query := `DELETE FROM user_data_exports WHERE user_id = $1 AND tenant_id = $2`
+		if _, err := r.db.Exec(ctx, "user.replace_data_exports", query, e.UserID, tenancy.ID(ctx)); err != nil {
+			return errors.NewInternalError("failed to replace data exports", err)
+		}
+
+		query = `
+			INSERT INTO user_data_exports (id, tenant_id, user_id, status, created_at)
+			SELECT $1, $2, $3, $4, $5
+			WHERE EXISTS (SELECT 1 FROM users WHERE id = $3 AND tenant_id = $2)
+		`
+
+		result, err := r.db.Exec(ctx, "user.create_data_export", query, e.ID, tenancy.ID(ctx), e.UserID, e.Status, e.CreatedAt)
+		if err != nil {
+			return errors.NewInternalError("failed to create data export", err)
+		}
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return errors.NewNotFoundError("user")
+		}
+
+		return nil
+	})
+}
+
+const selectDataExportQuery = `
+	SELECT e.id, e.user_id, e.status, e.created_at, e.completed_at, e.expires_at,
+		ARRAY(SELECT part FROM user_data_export_parts WHERE export_id = e.id ORDER BY part)
+	FROM user_data_exports e
+`
+
+func scanDataExport(row database.Scanner) (*domain.DataExport, error) {
+	e := &domain.DataExport{}
+	var completedAt, expiresAt sql.NullTime
+	if err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.CreatedAt, &completedAt, &expiresAt, database.Array(&e.Parts)); err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		e.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		e.ExpiresAt = &expiresAt.Time
+	}
+	return e, nil
+}
+
+// GetLatestDataExport returns the most recent export of userID.
+func (r *PostgresPrivacyRepository) GetLatestDataExport(ctx context.Context, userID string) (_ *domain.DataExport, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.get_latest_data_export", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := selectDataExportQuery + ` WHERE e.user_id = $1 AND e.tenant_id = $2 ORDER BY e.created_at DESC LIMIT 1`
+
+	e, err := scanDataExport(r.db.QueryRow(ctx, "user.get_latest_data_export", query, userID, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("data export")
+		}
+		return nil, errors.NewInternalError("failed to get data export", err)
+	}
+
+	return e, nil
+}
+
+// AddDataExportPart stores a part of a pending export of userID, replacing
+// the part when it arrived before, and returns the export. Exports that
+// were replaced or completed meanwhile are not found.
+func (r *PostgresPrivacyRepository) AddDataExportPart(ctx context.Context, exportID, userID string, part *domain.DataExportPart) (_ *domain.DataExport, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.add_data_export_part", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		INSERT INTO user_data_export_parts (export_id, part, content, received_at)
+		SELECT $1, $2, $3, $4
+		WHERE EXISTS (
+			SELECT 1 FROM user_data_exports
+			WHERE id = $1 AND user_id = $5 AND tenant_id = $6 AND status = 'pending'
+		)
+		ON CONFLICT (export_id, part) DO UPDATE SET content = EXCLUDED.content, received_at = EXCLUDED.received_at
+	`
+
+	result, err := r.db.Exec(ctx, "user.add_data_export_part", query,
+		exportID, part.Part, []byte(part.Content), time.Now().UTC(), userID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to store data export part", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, errors.NewNotFoundError("data export")
+	}
+
+	query = selectDataExportQuery + ` WHERE e.id = $1 AND e.tenant_id = $2`
+
+	e, err := scanDataExport(r.db.QueryRow(ctx, "user.get_data_export", query, exportID, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("data export")
+		}
+		return nil, errors.NewInternalError("failed to get data export", err)
+	}
+
+	return e, nil
+}
+
+// CompleteDataExport marks a pending export ready. It returns false when
+// the export was completed already, so only one caller announces it.
+func (r *PostgresPrivacyRepository) CompleteDataExport(ctx context.Context, id string, completedAt, expiresAt time.Time) (_ bool, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.complete_data_export")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE user_data_exports SET status = $1, completed_at = $2, expires_at = $3
+		WHERE id = $4 AND tenant_id = $5 AND status = 'pending'
+	`
+
+	result, err := r.db.Exec(ctx, "user.complete_data_export", query,
+		domain.DataExportStatusReady, completedAt, expiresAt, id, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return false, errors.NewInternalError("failed to complete data export", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *PostgresPrivacyRepository) ListDataExportParts(ctx context.Context, exportID string) (_ []*domain.DataExportPart, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.list_data_export_parts")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT p.part, p.content
+		FROM user_data_export_parts p
+		JOIN user_data_exports e ON e.id = p.export_id
+		WHERE p.export_id = $1 AND e.tenant_id = $2
+		ORDER BY p.part
+	`
+
+	rows, err := r.db.Query(ctx, "user.list_data_export_parts", query, exportID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list data export parts", err)
+	}
+	parts, err := database.ScanAll(rows, func(row database.Scanner) (*domain.DataExportPart, error) {
+		part := &domain.DataExportPart{}
+		var content []byte
+		err := row.Scan(&part.Part, &content)
+		part.Content = content
+		return part, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan data export parts", err)
+	}
+
+	return parts, nil
+}
+
+// DeleteExpiredDataExports removes the exports of every tenant that
+// expired before now.
+func (r *PostgresPrivacyRepository) DeleteExpiredDataExports(ctx context.Context, now time.Time) (_ int64, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.delete_expired_data_exports")
+	defer tracing.End(span, &err)
+
+	result, err := r.db.Exec(ctx, "user.delete_expired_data_exports", `DELETE FROM user_data_exports WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, errors.NewInternalError("failed to delete expired data exports", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+// CreateErasure records that userID was deleted at deletedAt, and
+// erasedPart as the first part erased.
+func (r *PostgresPrivacyRepository) CreateErasure(ctx context.Context, userID string, deletedAt time.Time, erasedPart *domain.ErasedPart) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.create_erasure", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		query := `
+			INSERT INTO user_erasures (user_id, tenant_id, deleted_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id) DO NOTHING
+		`
+		if _, err := r.db.Exec(ctx, "user.create_erasure", query, userID, tenancy.ID(ctx), deletedAt); err != nil {
+			return errors.NewInternalError("failed to record erasure", err)
+		}
+
+		_, err := r.AddErasedPart(ctx, userID, erasedPart)
+		return err
+	})
+}
+
+// AddErasedPart records what a service erased of userID's data, and
+// returns the certificate of the erasure so far. Parts reported again
+// replace the earlier report.
+func (r *PostgresPrivacyRepository) AddErasedPart(ctx context.Context, userID string, part *domain.ErasedPart) (_ *domain.ErasureCertificate, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.add_erased_part", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		INSERT INTO user_erased_parts (user_id, part, records, retained, erased_at)
+		SELECT $1, $2, $3, COALESCE($4::text[], '{}'), $5
+		WHERE EXISTS (SELECT 1 FROM user_erasures WHERE user_id = $1 AND tenant_id = $6)
+		ON CONFLICT (user_id, part) DO UPDATE
+			SET records = EXCLUDED.records, retained = EXCLUDED.retained, erased_at = EXCLUDED.erased_at
+	`
+
+	result, err := r.db.Exec(ctx, "user.add_erased_part", query,
+		userID, part.Part, part.Records, part.Retained, part.ErasedAt, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to record erased data", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, errors.NewNotFoundError("erasure")
+	}
+
+	certificate := &domain.ErasureCertificate{UserID: userID}
+	err = r.db.QueryRow(ctx, "user.get_erasure", `SELECT deleted_at FROM user_erasures WHERE user_id = $1 AND tenant_id = $2`,
+		userID, tenancy.ID(ctx),
+	).Scan(&certificate.DeletedAt)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get erasure", err)
+	}
+
+	query = `SELECT part, records, retained, erased_at FROM user_erased_parts WHERE user_id = $1 ORDER BY part`
+
+	partRows, err := r.db.Query(ctx, "user.list_erased_parts", query, userID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list erased data", err)
+	}
+	certificate.Parts, err = database.ScanAll(partRows, func(row database.Scanner) (*domain.ErasedPart, error) {
+		part := &domain.ErasedPart{}
+		err := row.Scan(&part.Part, &part.Records, database.Array(&part.Retained), &part.ErasedAt)
+		return part, err
+	})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to scan erased data", err)
+	}
+
+	return certificate, nil
+}
+
+// CompleteErasure marks the erasure of userID completed. It returns false
+// when it was completed already, so its certificate is written once.
+func (r *PostgresPrivacyRepository) CompleteErasure(ctx context.Context, userID string, completedAt time.Time) (_ bool, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.complete_erasure", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `UPDATE user_erasures SET completed_at = $1 WHERE user_id = $2 AND tenant_id = $3 AND completed_at IS NULL`
+
+	result, err := r.db.Exec(ctx, "user.complete_erasure", query, completedAt, userID, tenancy.ID(ctx))
+	if err != nil {
+		return false, errors.NewInternalError("failed to complete erasure", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
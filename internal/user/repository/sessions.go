@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresSessionRepository stores the login sessions of users. Expired
+// sessions are not returned, and are removed when the user starts a new
+// one.
+type PostgresSessionRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresSessionRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresSessionRepository {
+	return &PostgresSessionRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+func (r *PostgresSessionRepository) CreateSession(ctx context.Context, session *domain.Session) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.create_session", trace.WithAttributes(tracing.UserID.String(session.UserID)))
+	defer tracing.End(span, &err)
+
+	if _, err := r.db.Exec(ctx, "user.delete_expired_sessions",
+		`DELETE FROM user_sessions WHERE user_id = $1 AND tenant_id = $2 AND expires_at <= NOW()`,
+		session.UserID, tenancy.ID(ctx),
+	); err != nil {
+		return errors.NewInternalError("failed to delete expired sessions", err)
+	}
+
+	query := `
+		INSERT INTO user_sessions (id, tenant_id, user_id, ip_address, user_agent, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.db.Exec(ctx, "user.create_session", query,
+		session.ID, tenancy.ID(ctx), session.UserID, session.IPAddress, session.UserAgent,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create session", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresSessionRepository) GetSession(ctx context.Context, userID, id string) (_ *domain.Session, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.get_session", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, ip_address, user_agent, created_at, last_used_at, expires_at
+		FROM user_sessions
+		WHERE id = $1 AND user_id = $2 AND tenant_id = $3 AND expires_at > NOW()
+	`
+
+	session := &domain.Session{}
+	err = r.db.QueryRow(ctx, "user.get_session", query, id, userID, tenancy.ID(ctx)).Scan(
+		&session.ID, &session.UserID, &session.IPAddress, &session.UserAgent,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("session")
+		}
+		return nil, errors.NewInternalError("failed to get session", err)
+	}
+
+	return session, nil
+}
+
+func (r *PostgresSessionRepository) ListSessions(ctx context.Context, userID string) (_ []*domain.Session, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.list_sessions", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT id, user_id, ip_address, user_agent, created_at, last_used_at, expires_at
+		FROM user_sessions
+		WHERE user_id = $1 AND tenant_id = $2 AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, "user.list_sessions", query, userID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list sessions", err)
+	}
+	defer rows.Close()
+
+	sessions := []*domain.Session{}
+	for rows.Next() {
+		session := &domain.Session{}
+		if err := rows.Scan(
+			&session.ID, &session.UserID, &session.IPAddress, &session.UserAgent,
+			&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt,
+		); err != nil {
+			return nil, errors.NewInternalError("failed to scan session", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to list sessions", err)
+	}
+
+	return sessions, nil
+}
+
+// TouchSession records a refresh of the session from client and extends
+// it until expiresAt.
+func (r *PostgresSessionRepository) TouchSession(ctx context.Context, userID, id string, client domain.Client, expiresAt time.Time) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.touch_session", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE user_sessions
+		SET ip_address = $4, user_agent = $5, last_used_at = $6, expires_at = $7
+		WHERE id = $1 AND user_id = $2 AND tenant_id = $3 AND expires_at > NOW()
+	`
+
+	result, err := r.db.Exec(ctx, "user.touch_session", query,
+		id, userID, tenancy.ID(ctx), client.IPAddress, client.UserAgent, time.Now().UTC(), expiresAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to update session", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("session")
+	}
+
+	return nil
+}
+
+func (r *PostgresSessionRepository) DeleteSession(ctx context.Context, userID, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.delete_session", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	result, err := r.db.Exec(ctx, "user.delete_session",
+		`DELETE FROM user_sessions WHERE id = $1 AND user_id = $2 AND tenant_id = $3`,
+		id, userID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to delete session", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("session")
+	}
+
+	return nil
+}
+
+func (r *PostgresSessionRepository) DeleteSessions(ctx context.Context, userID string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.delete_sessions", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	_, err = r.db.Exec(ctx, "user.delete_sessions",
+		`DELETE FROM user_sessions WHERE user_id = $1 AND tenant_id = $2`,
+		userID, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to delete sessions", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PostgresTwoFactorRepository stores the TOTP enrollments of users. Codes
+// are used up with conditional updates, so concurrent logins cannot use
+// the same code twice.
+type PostgresTwoFactorRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresTwoFactorRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresTwoFactorRepository {
+	return &PostgresTwoFactorRepository{
+		db:     db,
+		tracer: tracer,
+	}
+}
+
+func (r *PostgresTwoFactorRepository) GetTwoFactor(ctx context.Context, userID string) (_ *domain.TwoFactor, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.get_two_factor", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT user_id, secret, enabled, backup_codes, last_used_step, created_at, enabled_at
+		FROM user_two_factor
+		WHERE user_id = $1 AND tenant_id = $2
+	`
+
+	tf := &domain.TwoFactor{}
+	var enabledAt sql.NullTime
+	err = r.db.QueryRow(ctx, "user.get_two_factor", query, userID, tenancy.ID(ctx)).Scan(
+		&tf.UserID, &tf.Secret, &tf.Enabled, database.Array(&tf.BackupCodes), &tf.LastUsedStep, &tf.CreatedAt, &enabledAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("two-factor enrollment")
+		}
+		return nil, errors.NewInternalError("failed to get two-factor enrollment", err)
+	}
+	if enabledAt.Valid {
+		tf.EnabledAt = &enabledAt.Time
+	}
+
+	return tf, nil
+}
+
+// SavePendingTwoFactor starts an enrollment with a new secret, replacing a
+// pending one. An enabled enrollment is a conflict.
+func (r *PostgresTwoFactorRepository) SavePendingTwoFactor(ctx context.Context, tf *domain.TwoFactor) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.save_pending_two_factor", trace.WithAttributes(tracing.UserID.String(tf.UserID)))
+	defer tracing.End(span, &err)
+
+	tf.Enabled = false
+	tf.BackupCodes = nil
+	tf.LastUsedStep = 0
+	tf.CreatedAt = time.Now().UTC()
+	tf.EnabledAt = nil
+
+	query := `
+		INSERT INTO user_two_factor (user_id, tenant_id, secret, enabled, backup_codes, last_used_step, created_at)
+		SELECT $1, $2, $3, false, '{}', 0, $4
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $1 AND tenant_id = $2)
+		ON CONFLICT (user_id) DO UPDATE
+			SET secret = EXCLUDED.secret, backup_codes = '{}', last_used_step = 0, created_at = EXCLUDED.created_at
+			WHERE NOT user_two_factor.enabled
+	`
+
+	result, err := r.db.Exec(ctx, "user.save_pending_two_factor", query, tf.UserID, tenancy.ID(ctx), tf.Secret, tf.CreatedAt)
+	if err != nil {
+		return errors.NewInternalError("failed to save two-factor enrollment", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	return nil
+}
+
+// EnableTwoFactor enables a pending enrollment with the backup codes and
+// the step of the code that confirmed it.
+func (r *PostgresTwoFactorRepository) EnableTwoFactor(ctx context.Context, userID string, step int64, backupCodes []string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.enable_two_factor", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE user_two_factor
+		SET enabled = true, enabled_at = $3, last_used_step = $4, backup_codes = $5
+		WHERE user_id = $1 AND tenant_id = $2 AND NOT enabled AND last_used_step < $4
+	`
+
+	result, err := r.db.Exec(ctx, "user.enable_two_factor", query, userID, tenancy.ID(ctx), time.Now().UTC(), step, backupCodes)
+	if err != nil {
+		return errors.NewInternalError("failed to enable two-factor authentication", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	return nil
+}
+
+// UseStep records that the code of step was used. It returns false when
+// the step or a later one was used already.
+func (r *PostgresTwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (_ bool, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.use_two_factor_step", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE user_two_factor SET last_used_step = $3
+		WHERE user_id = $1 AND tenant_id = $2 AND last_used_step < $3
+	`
+
+	result, err := r.db.Exec(ctx, "user.use_two_factor_step", query, userID, tenancy.ID(ctx), step)
+	if err != nil {
+		return false, errors.NewInternalError("failed to record two-factor code", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// UseBackupCode removes the backup code with hash. It returns false when
+// the user has no such code.
+func (r *PostgresTwoFactorRepository) UseBackupCode(ctx context.Context, userID, hash string) (_ bool, err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.use_backup_code", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE user_two_factor SET backup_codes = array_remove(backup_codes, $3)
+		WHERE user_id = $1 AND tenant_id = $2 AND enabled AND $3 = ANY (backup_codes)
+	`
+
+	result, err := r.db.Exec(ctx, "user.use_backup_code", query, userID, tenancy.ID(ctx), hash)
+	if err != nil {
+		return false, errors.NewInternalError("failed to record backup code", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *PostgresTwoFactorRepository) ReplaceBackupCodes(ctx context.Context, userID string, backupCodes []string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.replace_backup_codes", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	query := `UPDATE user_two_factor SET backup_codes = $3 WHERE user_id = $1 AND tenant_id = $2 AND enabled`
+
+	result, err := r.db.Exec(ctx, "user.replace_backup_codes", query, userID, tenancy.ID(ctx), backupCodes)
+	if err != nil {
+		return errors.NewInternalError("failed to replace backup codes", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("two-factor enrollment")
+	}
+
+	return nil
+}
+
+func (r *PostgresTwoFactorRepository) DeleteTwoFactor(ctx context.Context, userID string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "user.repository.delete_two_factor", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	result, err := r.db.Exec(ctx, "user.delete_two_factor", `DELETE FROM user_two_factor WHERE user_id = $1 AND tenant_id = $2`, userID, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to delete two-factor enrollment", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("two-factor enrollment")
+	}
+
+	return nil
+}
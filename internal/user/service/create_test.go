@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingPublisher records the types of the events published.
+type recordingPublisher struct {
+	published []events.EventType
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, eventType events.EventType, _ string, _ any) error {
+	p.published = append(p.published, eventType)
+	return nil
+}
+
+func TestCreateUserTiming(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *domain.CreateUserRequest
+		wantErr    bool
+		wantPhases []string
+	}{
+		{
+			name:       "created",
+			req:        &domain.CreateUserRequest{Email: "ada@example.com", Name: "Ada", Password: "Secret-pass-1"},
+			wantPhases: []string{"validation", "db", "kafka"},
+		},
+		{
+			name:       "invalid",
+			req:        &domain.CreateUserRequest{Email: "not an email", Name: "Ada", Password: "Secret-pass-1"},
+			wantErr:    true,
+			wantPhases: []string{"validation"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			s := NewUserService(&fakeUserRepository{}, publisher, nil, nil, logger.New("test", "error"), metrics.New("user_test"),
+				noop.NewTracerProvider().Tracer("test"), testJWTSecret, time.Minute, time.Hour, EmailVerification{Expiry: time.Hour}, PasswordReset{})
+
+			ctx, breadcrumbs := timing.NewContext(context.Background())
+			if _, err := s.CreateUser(ctx, tt.req); (err != nil) != tt.wantErr {
+				t.Fatalf("CreateUser() error = %v, want error: %v", err, tt.wantErr)
+			}
+
+			var phases []string
+			for field := range breadcrumbs.Fields() {
+				phases = append(phases, field)
+			}
+			slices.Sort(phases)
+
+			want := make([]string, 0, len(tt.wantPhases))
+			for _, phase := range tt.wantPhases {
+				want = append(want, phase+"_ms")
+			}
+			slices.Sort(want)
+
+			if !slices.Equal(phases, want) {
+				t.Errorf("phases = %q, want %q", phases, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type DeviceRepository interface {
+	UpsertDevice(ctx context.Context, device *domain.Device) error
+	ListDevices(ctx context.Context, userID string) ([]*domain.Device, error)
+	DeleteDevice(ctx context.Context, userID, id string) (*domain.Device, error)
+	GetPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error)
+	UpsertPreferences(ctx context.Context, prefs *domain.NotificationPreferences) error
+	GetDefaults(ctx context.Context) (*domain.NotificationDefaults, error)
+	UpsertDefaults(ctx context.Context, defaults *domain.NotificationDefaults) error
+}
+
+// DeviceService manages where users are notified: the devices they
+// receive push notifications on, the channels they prefer and the
+// defaults of their tenant. Changes are published for the notification
+// service.
+type DeviceService struct {
+	repo      DeviceRepository
+	publisher messagebus.Publisher
+	logger    *logger.Logger
+	tracer    trace.Tracer
+}
+
+func NewDeviceService(repo DeviceRepository, publisher messagebus.Publisher, logger *logger.Logger, tracer trace.Tracer) *DeviceService {
+	return &DeviceService{
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		tracer:    tracer,
+	}
+}
+
+// RegisterDevice adds a device of userID. Apps register their token on
+// every start, which refreshes the registration.
+func (s *DeviceService) RegisterDevice(ctx context.Context, userID string, req *domain.RegisterDeviceRequest) (_ *domain.Device, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.register_device", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	device := &domain.Device{
+		UserID:   userID,
+		Token:    req.Token,
+		Platform: req.Platform,
+	}
+	if err := s.repo.UpsertDevice(ctx, device); err != nil {
+		return nil, err
+	}
+
+	event := events.UserDeviceRegisteredEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDeviceRegistered, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDeviceRegisteredData{
+			UserID:       userID,
+			DeviceID:     device.ID,
+			Token:        device.Token,
+			Platform:     string(device.Platform),
+			RegisteredAt: device.LastSeenAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.UserDeviceRegistered, userID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish device registered event")
+	}
+
+	s.logger.WithContext(ctx).With("user_id", userID).With("device_id", device.ID).Info("device registered")
+
+	return device, nil
+}
+
+func (s *DeviceService) ListDevices(ctx context.Context, userID string) (_ []*domain.Device, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.list_devices", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.ListDevices(ctx, userID)
+}
+
+// RemoveDevice stops push notifications to a device, e.g. when the user
+// logs out of the app.
+func (s *DeviceService) RemoveDevice(ctx context.Context, userID, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.remove_device", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	device, err := s.repo.DeleteDevice(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	event := events.UserDeviceRemovedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDeviceRemoved, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDeviceRemovedData{
+			UserID:    userID,
+			DeviceID:  device.ID,
+			Token:     device.Token,
+			RemovedAt: time.Now().UTC(),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.UserDeviceRemoved, userID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish device removed event")
+	}
+
+	s.logger.WithContext(ctx).With("user_id", userID).With("device_id", id).Info("device removed")
+
+	return nil
+}
+
+func (s *DeviceService) GetPreferences(ctx context.Context, userID string) (_ *domain.NotificationPreferences, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.get_notification_preferences", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences changes the channels userID is notified over. Text
+// messages need a phone number. Users who never set preferences start
+// from the defaults of their tenant.
+func (s *DeviceService) UpdatePreferences(ctx context.Context, userID string, req *domain.UpdateNotificationPreferencesRequest) (_ *domain.NotificationPreferences, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.update_notification_preferences", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	prefs, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := *prefs
+
+	if req.Email != nil {
+		prefs.Email = *req.Email
+	}
+	if req.SMS != nil {
+		prefs.SMS = *req.SMS
+	}
+	if req.Push != nil {
+		prefs.Push = *req.Push
+	}
+	if req.Categories != nil {
+		prefs.Categories = req.Categories
+	}
+	switch {
+	case req.QuietHours != nil:
+		prefs.QuietHours = req.QuietHours
+	case req.ClearQuietHours:
+		prefs.QuietHours = nil
+	}
+	if req.Phone != nil {
+		prefs.Phone = *req.Phone
+	}
+	if req.Locale != nil {
+		prefs.Locale = *req.Locale
+	}
+	if prefs.UsesSMS() && prefs.Phone == "" {
+		return nil, errors.NewValidationError("a phone number is required for text messages", nil)
+	}
+
+	if err := s.repo.UpsertPreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+	audit.Log(ctx, "user.update_notification_preferences", "user", userID, &before, prefs)
+
+	event := events.UserNotificationPreferencesUpdatedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserNotificationPreferencesUpdated, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserNotificationPreferencesData{
+			UserID:     userID,
+			Email:      prefs.Email,
+			SMS:        prefs.SMS,
+			Push:       prefs.Push,
+			Categories: categoryEventData(prefs.Categories),
+			QuietHours: quietHoursEventData(prefs.QuietHours),
+			Phone:      prefs.Phone,
+			Locale:     prefs.Locale,
+			UpdatedAt:  prefs.UpdatedAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.UserNotificationPreferencesUpdated, userID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish notification preferences updated event")
+	}
+
+	return prefs, nil
+}
+
+func (s *DeviceService) GetDefaults(ctx context.Context) (_ *domain.NotificationDefaults, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.get_notification_defaults")
+	defer tracing.End(span, &err)
+
+	return s.repo.GetDefaults(ctx)
+}
+
+// UpdateDefaults replaces the notification preferences of the users of
+// the tenant who did not set their own.
+func (s *DeviceService) UpdateDefaults(ctx context.Context, req *domain.UpdateNotificationDefaultsRequest) (_ *domain.NotificationDefaults, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.update_notification_defaults")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	before, err := s.repo.GetDefaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := &domain.NotificationDefaults{
+		NotificationChannels: domain.NotificationChannels{Email: req.Email, SMS: req.SMS, Push: req.Push},
+		Categories:           req.Categories,
+		QuietHours:           req.QuietHours,
+	}
+	if defaults.Categories == nil {
+		defaults.Categories = make(map[domain.NotificationCategory]domain.NotificationChannels)
+	}
+
+	if err := s.repo.UpsertDefaults(ctx, defaults); err != nil {
+		return nil, err
+	}
+	audit.Log(ctx, "user.update_notification_defaults", "tenant", tenancy.ID(ctx), before, defaults)
+
+	event := events.UserNotificationDefaultsUpdatedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserNotificationDefaultsUpdated, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserNotificationDefaultsData{
+			Email:      defaults.Email,
+			SMS:        defaults.SMS,
+			Push:       defaults.Push,
+			Categories: categoryEventData(defaults.Categories),
+			QuietHours: quietHoursEventData(defaults.QuietHours),
+			UpdatedAt:  defaults.UpdatedAt,
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.UserNotificationDefaultsUpdated, tenancy.ID(ctx), event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish notification defaults updated event")
+	}
+
+	return defaults, nil
+}
+
+func categoryEventData(categories map[domain.NotificationCategory]domain.NotificationChannels) map[string]events.NotificationChannels {
+	data := make(map[string]events.NotificationChannels, len(categories))
+	for category, channels := range categories {
+		data[string(category)] = events.NotificationChannels{Email: channels.Email, SMS: channels.SMS, Push: channels.Push}
+	}
+	return data
+}
+
+func quietHoursEventData(quietHours *domain.QuietHours) *events.QuietHours {
+	if quietHours == nil {
+		return nil
+	}
+	return &events.QuietHours{Start: quietHours.Start, End: quietHours.End, TimeZone: quietHours.TimeZone}
+}
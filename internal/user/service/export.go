@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/export"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// exportPageSize is the number of users read per query while exporting.
+const exportPageSize = 500
+
+var userExportHeader = []string{"id", "email", "name", "role", "active", "email_verified", "created_at", "updated_at"}
+
+// ExportUsers writes the users matching filter to w, oldest first. It
+// pages through them with a cursor so the export is never held in memory,
+// flushing w after every page. Password hashes are never exported.
+func (s *UserService) ExportUsers(ctx context.Context, filter domain.ListUsersFilter, w export.Writer) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.export")
+	defer tracing.End(span, &err)
+
+	if err := w.Write(userExportHeader); err != nil {
+		return 0, errors.NewInternalError("failed to write export", err)
+	}
+
+	params := pagination.Params{Page: 1, PageSize: exportPageSize, Sort: pagination.Sort{Field: "created_at"}}
+	written := 0
+	for {
+		users, result, err := s.repo.List(ctx, filter, params)
+		if err != nil {
+			return written, err
+		}
+
+		for _, u := range users {
+			row := []string{
+				u.ID, u.Email, u.Name, u.Role, strconv.FormatBool(u.Active), strconv.FormatBool(u.EmailVerified),
+				u.CreatedAt.UTC().Format(time.RFC3339), u.UpdatedAt.UTC().Format(time.RFC3339),
+			}
+			if err := w.Write(row); err != nil {
+				return written, errors.NewInternalError("failed to write export", err)
+			}
+			written++
+		}
+		if err := w.Flush(); err != nil {
+			return written, errors.NewInternalError("failed to write export", err)
+		}
+
+		if result.NextCursor == "" {
+			return written, nil
+		}
+		if params.Cursor, err = pagination.DecodeCursor(result.NextCursor); err != nil {
+			return written, err
+		}
+	}
+}
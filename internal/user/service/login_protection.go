@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CaptchaVerifier checks the CAPTCHA a client solved, as
+// captcha.SiteVerify does.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, response, remoteIP string) (bool, error)
+}
+
+// LoginProtection configures the defenses of password logins against
+// guessing and credential stuffing. Failed logins are counted per account
+// until none failed for FailureWindow.
+type LoginProtection struct {
+	Attempts auth.LoginAttemptStore
+	// MaxFailures locks an account out for LockoutDuration, doubled with
+	// each further failure up to MaxLockoutDuration; 0 disables lockouts.
+	MaxFailures        int
+	FailureWindow      time.Duration
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+
+	// Captcha is asked for from CaptchaThreshold failures on; nil or a
+	// threshold of 0 disables it.
+	Captcha          CaptchaVerifier
+	CaptchaThreshold int
+
+	// KnownClients remembers the clients of logins for KnownClientTTL, so
+	// users are notified of logins from others; nil disables it.
+	KnownClients   auth.KnownClientStore
+	KnownClientTTL time.Duration
+}
+
+// SetLoginProtection enables lockouts, CAPTCHAs and new device
+// notifications for logins.
+func (s *UserService) SetLoginProtection(protection LoginProtection) {
+	s.protection = protection
+}
+
+// checkLoginAllowed rejects logins to a locked account, and logins to an
+// account under attack without a solved CAPTCHA. The checks fail open
+// when the attempts cannot be read, so an outage does not lock everyone
+// out.
+func (s *UserService) checkLoginAllowed(ctx context.Context, req *domain.LoginRequest) error {
+	p := s.protection
+	if p.Attempts == nil {
+		return nil
+	}
+	log := s.logger.WithContext(ctx)
+
+	lockedUntil, err := p.Attempts.LockedUntil(ctx, req.Email)
+	if err != nil {
+		log.WithError(err).Error("failed to read account lockout")
+	} else if time.Now().Before(lockedUntil) {
+		s.authFailed(ctx, "locked")
+		return errors.NewRateLimitError("account is temporarily locked after too many failed logins")
+	}
+
+	if p.Captcha == nil || p.CaptchaThreshold <= 0 {
+		return nil
+	}
+
+	failures, err := p.Attempts.Failures(ctx, req.Email)
+	if err != nil {
+		log.WithError(err).Error("failed to read failed logins")
+		return nil
+	}
+	if failures < int64(p.CaptchaThreshold) {
+		return nil
+	}
+
+	if req.CaptchaToken == "" {
+		s.authFailed(ctx, "captcha_required")
+		return captchaError("captcha verification required")
+	}
+	ok, err := p.Captcha.Verify(ctx, req.CaptchaToken, domain.ClientFrom(ctx).IPAddress)
+	if err != nil {
+		return errors.NewExternalError("captcha", "failed to verify captcha", err)
+	}
+	if !ok {
+		s.authFailed(ctx, "captcha_invalid")
+		return captchaError("invalid captcha")
+	}
+
+	return nil
+}
+
+// loginFailed counts a login with wrong credentials and locks the account
+// once it failed too often. It returns the error to answer the login with.
+func (s *UserService) loginFailed(ctx context.Context, email string) error {
+	s.authFailed(ctx, "invalid_credentials")
+	s.recordLoginFailure(ctx, email)
+	return errors.NewUnauthorizedError("invalid credentials")
+}
+
+// recordLoginFailure counts a failed login of the account, whichever step
+// of the login failed, and locks the account once it failed too often.
+func (s *UserService) recordLoginFailure(ctx context.Context, email string) {
+	p := s.protection
+	if p.Attempts == nil {
+		return
+	}
+	log := s.logger.WithContext(ctx)
+
+	failures, err := p.Attempts.RecordFailure(ctx, email, p.FailureWindow)
+	if err != nil {
+		log.WithError(err).Error("failed to record failed login")
+		return
+	}
+
+	if p.MaxFailures > 0 && failures >= int64(p.MaxFailures) {
+		duration := lockoutDuration(p, failures)
+		if err := p.Attempts.Lock(ctx, email, duration); err != nil {
+			log.WithError(err).Error("failed to lock account")
+		} else {
+			s.metrics.AccountLockouts.WithLabelValues(tenancy.ID(ctx)).Inc()
+			log.WithFields(map[string]any{"failures": failures, "lockout": duration.String()}).Warn("account locked after failed logins")
+		}
+	}
+}
+
+// loginSucceeded clears the failed logins of an account.
+func (s *UserService) loginSucceeded(ctx context.Context, email string) {
+	if s.protection.Attempts == nil {
+		return
+	}
+	if err := s.protection.Attempts.Reset(ctx, email); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to reset failed logins")
+	}
+}
+
+func (s *UserService) authFailed(ctx context.Context, reason string) {
+	s.metrics.AuthFailures.WithLabelValues(reason, tenancy.ID(ctx)).Inc()
+}
+
+// notifyNewClient publishes user.new_device_login when user logs in from
+// an IP address or device they did not use recently. The first login of a
+// user is not reported. Failures are logged; they never fail the login.
+func (s *UserService) notifyNewClient(ctx context.Context, user *domain.User) {
+	p := s.protection
+	client := domain.ClientFrom(ctx)
+	if p.KnownClients == nil || client == (domain.Client{}) {
+		return
+	}
+	log := s.logger.WithContext(ctx).With("user_id", user.ID)
+
+	isNew, isFirst, err := p.KnownClients.Remember(ctx, user.ID, client.IPAddress, client.UserAgent, p.KnownClientTTL)
+	if err != nil {
+		log.WithError(err).Error("failed to remember login client")
+		return
+	}
+	if !isNew || isFirst {
+		return
+	}
+
+	log.With("ip_address", client.IPAddress).Info("login from new device")
+
+	event := events.UserNewDeviceLoginEvent{
+		BaseEvent: events.NewBaseEvent(events.UserNewDeviceLogin, "user-service", trace.SpanFromContext(ctx).SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserNewDeviceLoginData{
+			UserID:     user.ID,
+			Email:      user.Email,
+			Name:       user.Name,
+			IPAddress:  client.IPAddress,
+			UserAgent:  client.UserAgent,
+			LoggedInAt: time.Now().UTC(),
+		},
+	}
+	if err := s.publisher.Publish(ctx, events.UserNewDeviceLogin, user.ID, event); err != nil {
+		log.WithError(err).Error("failed to publish new device login event")
+	}
+}
+
+// lockoutDuration doubles the lockout with each failure past the limit.
+func lockoutDuration(p LoginProtection, failures int64) time.Duration {
+	duration := p.LockoutDuration
+	for i := int64(p.MaxFailures); i < failures && duration < p.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+	return min(duration, p.MaxLockoutDuration)
+}
+
+// captchaError rejects a login that needs a CAPTCHA. Details tells clients
+// to show one, since the message is translated.
+func captchaError(message string) error {
+	err := errors.NewForbiddenError(message)
+	err.Details = "captcha_required"
+	return err
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type IdentityRepository interface {
+	CreateIdentity(ctx context.Context, identity *domain.Identity) error
+	GetIdentity(ctx context.Context, provider, subject string) (*domain.Identity, error)
+	ListIdentities(ctx context.Context, userID string) ([]*domain.Identity, error)
+}
+
+// OAuthProvider authenticates users with an identity provider, as
+// oauth.Google and oauth.GitHub do.
+type OAuthProvider interface {
+	AuthCodeURL(state, redirectURI string) string
+	Identify(ctx context.Context, code, redirectURI string) (*domain.ExternalIdentity, error)
+}
+
+var _ domain.OAuthService = (*OAuthService)(nil)
+
+// OAuthService logs users in with their accounts at identity providers. An
+// account seen for the first time is linked to the user with its verified
+// email address, or signs a new user up, so the user needs no password.
+// Logins are answered like password logins, including the second factor.
+type OAuthService struct {
+	users       *UserService
+	identities  IdentityRepository
+	states      auth.OAuthStateStore
+	providers   map[string]OAuthProvider
+	callbackURL string
+	stateExpiry time.Duration
+	logger      *logger.Logger
+	tracer      trace.Tracer
+}
+
+// NewOAuthService returns an OAuthService for providers, keyed by their
+// name. Providers redirect back to callbackURL followed by the provider
+// name and "/callback"; the user has stateExpiry to complete the login.
+func NewOAuthService(users *UserService, identities IdentityRepository, states auth.OAuthStateStore, providers map[string]OAuthProvider, callbackURL string, stateExpiry time.Duration, logger *logger.Logger, tracer trace.Tracer) *OAuthService {
+	return &OAuthService{
+		users:       users,
+		identities:  identities,
+		states:      states,
+		providers:   providers,
+		callbackURL: callbackURL,
+		stateExpiry: stateExpiry,
+		logger:      logger,
+		tracer:      tracer,
+	}
+}
+
+// AuthorizationURL starts a login with provider for the tenant of ctx and
+// returns the URL of the provider to send the user to, and the secret the
+// browser has to present on the callback.
+func (s *OAuthService) AuthorizationURL(ctx context.Context, provider string) (_, _ string, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.oauth_authorization_url")
+	defer tracing.End(span, &err)
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", errors.NewNotFoundError("identity provider")
+	}
+
+	state, browserSecret, err := s.states.Issue(ctx, provider, s.stateExpiry)
+	if err != nil {
+		return "", "", errors.NewInternalError("failed to start login", err)
+	}
+
+	return p.AuthCodeURL(state, s.redirectURI(provider)), browserSecret, nil
+}
+
+// Callback completes a login the provider redirected back from, in the
+// tenant it was started for.
+func (s *OAuthService) Callback(ctx context.Context, provider string, req *domain.OAuthCallbackRequest) (_ *domain.LoginResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.oauth_callback")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, errors.NewNotFoundError("identity provider")
+	}
+
+	tenantID, issuedFor, err := s.states.Consume(ctx, req.State, req.BrowserSecret)
+	if err != nil {
+		if stderrors.Is(err, auth.ErrInvalidOAuthState) {
+			return nil, errors.NewUnauthorizedError("invalid or expired login state")
+		}
+		return nil, errors.NewInternalError("failed to verify login state", err)
+	}
+	if issuedFor != provider {
+		return nil, errors.NewUnauthorizedError("invalid or expired login state")
+	}
+	ctx = tenancy.WithID(ctx, tenantID)
+
+	if req.Error != "" {
+		return nil, errors.NewUnauthorizedError("login was cancelled at the identity provider")
+	}
+	if req.Code == "" {
+		return nil, errors.NewValidationError("code is required", nil)
+	}
+
+	external, err := p.Identify(ctx, req.Code, s.redirectURI(provider))
+	if err != nil {
+		return nil, errors.NewExternalError(provider, "failed to authenticate with the identity provider", err)
+	}
+
+	user, err := s.resolve(ctx, span, external)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(tracing.UserID.String(user.ID))
+
+	response, err := s.users.completeLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).With("user_id", user.ID).With("provider", provider).Info("user logged in with identity provider")
+
+	return response, nil
+}
+
+// resolve returns the user of an external account. An account that is not
+// linked yet is linked to the user with its email address, which the
+// provider must have verified, or to a new user. Users who never verified
+// their address are not linked: whoever registered it could have chosen
+// the password, and would keep access after the owner of the address
+// logs in through the provider.
+func (s *OAuthService) resolve(ctx context.Context, span trace.Span, external *domain.ExternalIdentity) (*domain.User, error) {
+	identity, err := s.identities.GetIdentity(ctx, external.Provider, external.Subject)
+	if err == nil {
+		user, err := s.users.repo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+				return nil, errors.NewUnauthorizedError("invalid credentials")
+			}
+			return nil, err
+		}
+		return user, nil
+	}
+	if errors.GetAppError(err).Type != errors.ErrorTypeNotFound {
+		return nil, err
+	}
+
+	// Linking by an unverified address would hand the account to whoever
+	// claimed it at the provider
+	if external.Email == "" || !external.EmailVerified {
+		return nil, errors.NewForbiddenError("identity provider account has no verified email address")
+	}
+
+	user, err := s.users.repo.GetByEmail(ctx, external.Email)
+	switch {
+	case err == nil:
+		if !user.EmailVerified {
+			s.logger.WithContext(ctx).With("user_id", user.ID).With("provider", external.Provider).Warn("identity not linked to unverified account")
+			return nil, errors.NewConflictError("an account with this email address exists but is not verified; verify it and log in with its password first")
+		}
+	case errors.GetAppError(err).Type == errors.ErrorTypeNotFound:
+		user = &domain.User{
+			Email:         external.Email,
+			Name:          displayName(external),
+			EmailVerified: true,
+		}
+		// Without a password hash the user can only log in through the
+		// provider, until they reset their password
+		if err := s.users.repo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		s.users.created(ctx, span, user)
+	default:
+		return nil, err
+	}
+
+	identity = &domain.Identity{
+		UserID:   user.ID,
+		Provider: external.Provider,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	}
+	if err := s.identities.CreateIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "user.link_identity", "user", user.ID, nil, identity)
+	s.logger.WithContext(ctx).With("user_id", user.ID).With("provider", external.Provider).Info("identity linked")
+
+	return user, nil
+}
+
+// redirectURI is the URL provider redirects back to, which has to be
+// registered with it.
+func (s *OAuthService) redirectURI(provider string) string {
+	return s.callbackURL + provider + "/callback"
+}
+
+// displayName returns the name of a user signing up with external, which
+// falls back to their email address when the provider has no name of the
+// length users are required to have.
+func displayName(external *domain.ExternalIdentity) string {
+	name := strings.TrimSpace(external.Name)
+	if n := len([]rune(name)); n >= 2 && n <= 100 {
+		return name
+	}
+	local, _, _ := strings.Cut(external.Email, "@")
+	if len([]rune(local)) < 2 {
+		return external.Email
+	}
+	return local
+}
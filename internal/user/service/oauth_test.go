@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeUserRepository holds a single user; its other methods are not used.
+type fakeUserRepository struct {
+	UserRepository
+	user    *domain.User
+	updates []map[string]any
+}
+
+func (r *fakeUserRepository) Create(_ context.Context, user *domain.User) error {
+	user.ID = "u-1"
+	r.user = user
+	return nil
+}
+
+func (r *fakeUserRepository) GetByID(_ context.Context, id string) (*domain.User, error) {
+	if r.user == nil || r.user.ID != id {
+		return nil, errors.NewNotFoundError("user")
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepository) GetByEmail(_ context.Context, email string) (*domain.User, error) {
+	if r.user == nil || r.user.Email != email {
+		return nil, errors.NewNotFoundError("user")
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepository) Update(_ context.Context, _ string, _ int64, updates map[string]any) error {
+	r.updates = append(r.updates, updates)
+	return nil
+}
+
+// fakeIdentityRepository knows no identities and records the ones linked.
+type fakeIdentityRepository struct {
+	IdentityRepository
+	created []*domain.Identity
+}
+
+func (r *fakeIdentityRepository) GetIdentity(context.Context, string, string) (*domain.Identity, error) {
+	return nil, errors.NewNotFoundError("identity")
+}
+
+func (r *fakeIdentityRepository) CreateIdentity(_ context.Context, identity *domain.Identity) error {
+	r.created = append(r.created, identity)
+	return nil
+}
+
+func TestResolveExistingAccount(t *testing.T) {
+	tests := []struct {
+		name       string
+		verified   bool
+		wantErr    errors.ErrorType
+		wantLinked bool
+	}{
+		{
+			name:       "verified account is linked",
+			verified:   true,
+			wantLinked: true,
+		},
+		{
+			name:    "unverified account is not linked",
+			wantErr: errors.ErrorTypeConfict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepository{user: &domain.User{
+				ID:            "u-1",
+				Email:         "ada@example.com",
+				Password:      "hash chosen by whoever registered",
+				EmailVerified: tt.verified,
+			}}
+			identities := &fakeIdentityRepository{}
+			tracer := noop.NewTracerProvider().Tracer("test")
+			s := &OAuthService{
+				users:      &UserService{repo: users},
+				identities: identities,
+				logger:     logger.New("test", "error"),
+				tracer:     tracer,
+			}
+
+			_, span := tracer.Start(context.Background(), "test")
+			user, err := s.resolve(context.Background(), span, &domain.ExternalIdentity{
+				Provider:      domain.ProviderGoogle,
+				Subject:       "google-1",
+				Email:         "ada@example.com",
+				EmailVerified: true,
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || errors.GetAppError(err).Type != tt.wantErr {
+					t.Fatalf("resolve() error = %v, want %s", err, tt.wantErr)
+				}
+			} else if err != nil || user.ID != "u-1" {
+				t.Fatalf("resolve() = %v, %v, want user u-1", user, err)
+			}
+			if linked := len(identities.created) > 0; linked != tt.wantLinked {
+				t.Errorf("identity linked = %v, want %v", linked, tt.wantLinked)
+			}
+			if len(users.updates) > 0 {
+				t.Errorf("user updated with %v, want no updates", users.updates)
+			}
+		})
+	}
+}
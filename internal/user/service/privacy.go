@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PrivacyRepository interface {
+	CreateDataExport(ctx context.Context, e *domain.DataExport) error
+	GetLatestDataExport(ctx context.Context, userID string) (*domain.DataExport, error)
+	AddDataExportPart(ctx context.Context, exportID, userID string, part *domain.DataExportPart) (*domain.DataExport, error)
+	CompleteDataExport(ctx context.Context, id string, completedAt, expiresAt time.Time) (bool, error)
+	ListDataExportParts(ctx context.Context, exportID string) ([]*domain.DataExportPart, error)
+	DeleteExpiredDataExports(ctx context.Context, now time.Time) (int64, error)
+	CreateErasure(ctx context.Context, userID string, deletedAt time.Time, erasedPart *domain.ErasedPart) error
+	AddErasedPart(ctx context.Context, userID string, part *domain.ErasedPart) (*domain.ErasureCertificate, error)
+	CompleteErasure(ctx context.Context, userID string, completedAt time.Time) (bool, error)
+}
+
+// PersonalData are the repositories holding personal data of users
+// besides their profile, which their data exports include.
+type PersonalData struct {
+	Devices    DeviceRepository
+	Sessions   SessionRepository
+	Identities IdentityRepository
+	TwoFactor  TwoFactorRepository
+}
+
+// DataExportPolicy configures data exports.
+type DataExportPolicy struct {
+	// DownloadURL is the link mailed to users once their export is ready.
+	DownloadURL string
+	// Expiry is the time a ready export can be downloaded.
+	Expiry time.Duration
+}
+
+var _ domain.PrivacyService = (*PrivacyService)(nil)
+
+// PrivacyService serves the data protection rights of users. A data
+// export asks every service holding personal data of the user for its
+// part and is ready once all arrived. Deleting a user anonymizes them,
+// and every service erases their data and reports back; once all did, a
+// certificate of the erasure is written to the audit log.
+type PrivacyService struct {
+	users  *UserService
+	repo   PrivacyRepository
+	data   PersonalData
+	policy DataExportPolicy
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+// NewPrivacyService returns a PrivacyService and makes the deletion of
+// users track their erasure.
+func NewPrivacyService(users *UserService, repo PrivacyRepository, data PersonalData, policy DataExportPolicy, logger *logger.Logger, tracer trace.Tracer) *PrivacyService {
+	s := &PrivacyService{
+		users:  users,
+		repo:   repo,
+		data:   data,
+		policy: policy,
+		logger: logger,
+		tracer: tracer,
+	}
+	users.privacy = s
+	return s
+}
+
+// RequestDataExport starts an export of the personal data of userID,
+// replacing their earlier exports. A pending export is returned as it is.
+func (s *PrivacyService) RequestDataExport(ctx context.Context, userID string) (_ *domain.DataExport, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.request_data_export", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	latest, err := s.repo.GetLatestDataExport(ctx, userID)
+	if err == nil && latest.Status == domain.DataExportStatusPending {
+		return latest, nil
+	}
+	if err != nil && errors.GetAppError(err).Type != errors.ErrorTypeNotFound {
+		return nil, err
+	}
+
+	profile, err := s.profile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &domain.DataExport{UserID: userID}
+	if err := s.repo.CreateDataExport(ctx, e); err != nil {
+		return nil, err
+	}
+	if e, err = s.repo.AddDataExportPart(ctx, e.ID, userID, &domain.DataExportPart{Part: events.DataPartProfile, Content: profile}); err != nil {
+		return nil, err
+	}
+
+	event := events.UserDataExportRequestedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataExportRequested, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataExportRequestedData{
+			ExportID:    e.ID,
+			UserID:      userID,
+			RequestedAt: e.CreatedAt,
+		},
+	}
+	if err := s.users.publisher.Publish(ctx, events.UserDataExportRequested, userID, event); err != nil {
+		return nil, errors.NewInternalError("failed to request data export", err)
+	}
+
+	audit.Log(ctx, "user.request_data_export", "user", userID, nil, nil)
+	s.logger.WithContext(ctx).With("user_id", userID).With("export_id", e.ID).Info("data export requested")
+
+	return e, nil
+}
+
+// GetDataExport returns the latest data export of userID.
+func (s *PrivacyService) GetDataExport(ctx context.Context, userID string) (_ *domain.DataExport, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.get_data_export", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	e, err := s.repo.GetLatestDataExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if e.ExpiresAt != nil && time.Now().After(*e.ExpiresAt) {
+		e.Status = domain.DataExportStatusExpired
+	}
+
+	return e, nil
+}
+
+// DownloadDataExport returns the ready data export of userID with its
+// parts.
+func (s *PrivacyService) DownloadDataExport(ctx context.Context, userID string) (_ *domain.DataExport, _ []*domain.DataExportPart, err error) {
+	e, err := s.GetDataExport(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if e.Status != domain.DataExportStatusReady {
+		return nil, nil, errors.NewConflictError("data export is " + string(e.Status))
+	}
+
+	parts, err := s.repo.ListDataExportParts(ctx, e.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	audit.Log(ctx, "user.download_data_export", "user", userID, nil, nil)
+
+	return e, parts, nil
+}
+
+// AddDataExportPart stores the part a service sent for an export, and
+// announces the export once it is complete. Parts of exports that were
+// replaced meanwhile are dropped.
+func (s *PrivacyService) AddDataExportPart(ctx context.Context, data events.UserDataExportPartData) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.add_data_export_part", trace.WithAttributes(tracing.UserID.String(data.UserID)))
+	defer tracing.End(span, &err)
+
+	e, err := s.repo.AddDataExportPart(ctx, data.ExportID, data.UserID, &domain.DataExportPart{Part: data.Part, Content: data.Content})
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			s.logger.WithContext(ctx).With("export_id", data.ExportID).With("part", data.Part).Warn("dropped part of replaced data export")
+			return nil
+		}
+		return err
+	}
+
+	for _, part := range events.DataParts {
+		if !slices.Contains(e.Parts, part) {
+			return nil
+		}
+	}
+
+	completedAt := time.Now().UTC()
+	expiresAt := completedAt.Add(s.policy.Expiry)
+	completed, err := s.repo.CompleteDataExport(ctx, e.ID, completedAt, expiresAt)
+	if err != nil || !completed {
+		return err
+	}
+
+	user, err := s.users.repo.GetByID(ctx, data.UserID)
+	if err != nil {
+		return err
+	}
+
+	event := events.UserDataExportReadyEvent{
+		BaseEvent: events.NewBaseEvent(events.UserDataExportReady, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserDataExportReadyData{
+			ExportID:    e.ID,
+			UserID:      user.ID,
+			Email:       user.Email,
+			Name:        user.Name,
+			DownloadURL: s.policy.DownloadURL,
+			ExpiresAt:   expiresAt,
+		},
+	}
+	if err := s.users.publisher.Publish(ctx, events.UserDataExportReady, user.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish data export ready event")
+	}
+
+	s.logger.WithContext(ctx).With("user_id", user.ID).With("export_id", e.ID).Info("data export ready")
+
+	return nil
+}
+
+// AddErasedPart records what a service erased of a deleted user's data,
+// and writes the erasure certificate once every service reported.
+func (s *PrivacyService) AddErasedPart(ctx context.Context, data events.UserDataErasedData) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.add_erased_part", trace.WithAttributes(tracing.UserID.String(data.UserID)))
+	defer tracing.End(span, &err)
+
+	certificate, err := s.repo.AddErasedPart(ctx, data.UserID, &domain.ErasedPart{
+		Part:     data.Part,
+		Records:  data.Records,
+		Retained: data.Retained,
+		ErasedAt: data.ErasedAt,
+	})
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			s.logger.WithContext(ctx).With("user_id", data.UserID).With("part", data.Part).Warn("erasure reported for unknown deletion")
+			return nil
+		}
+		return err
+	}
+
+	for _, part := range events.DataParts {
+		if !slices.ContainsFunc(certificate.Parts, func(erased *domain.ErasedPart) bool { return erased.Part == part }) {
+			return nil
+		}
+	}
+
+	certificate.CompletedAt = time.Now().UTC()
+	completed, err := s.repo.CompleteErasure(ctx, data.UserID, certificate.CompletedAt)
+	if err != nil || !completed {
+		return err
+	}
+
+	audit.Log(ctx, "user.erasure_certificate", "user", data.UserID, nil, certificate)
+	s.logger.WithContext(ctx).With("user_id", data.UserID).Info("personal data erased")
+
+	return nil
+}
+
+// PurgeExpiredDataExports removes the data exports of every tenant that
+// expired.
+func (s *PrivacyService) PurgeExpiredDataExports(ctx context.Context) (_ int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.purge_expired_data_exports")
+	defer tracing.End(span, &err)
+
+	return s.repo.DeleteExpiredDataExports(ctx, time.Now().UTC())
+}
+
+// DataExportPurgeJob purges the expired data exports every interval.
+func DataExportPurgeJob(s *PrivacyService, logger *logger.Logger, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "data_export_purge",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			purged, err := s.PurgeExpiredDataExports(ctx)
+			if err != nil {
+				return err
+			}
+
+			if purged > 0 {
+				logger.WithContext(ctx).With("purged", strconv.FormatInt(purged, 10)).Info("purged expired data exports")
+			}
+			return nil
+		},
+	}
+}
+
+// startErasure records the deletion of userID, whose profile was erased
+// with erased records.
+func (s *PrivacyService) startErasure(ctx context.Context, userID string, deletedAt time.Time, erased int) error {
+	return s.repo.CreateErasure(ctx, userID, deletedAt, &domain.ErasedPart{
+		Part:     events.DataPartProfile,
+		Records:  erased,
+		ErasedAt: deletedAt,
+	})
+}
+
+// profile returns the profile part of the data export of userID.
+func (s *PrivacyService) profile(ctx context.Context, userID string) (json.RawMessage, error) {
+	user, err := s.users.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := domain.ProfileData{User: user.ToPublic()}
+	if data.NotificationPreferences, err = s.data.Devices.GetPreferences(ctx, userID); err != nil {
+		return nil, err
+	}
+	if data.Devices, err = s.data.Devices.ListDevices(ctx, userID); err != nil {
+		return nil, err
+	}
+	if data.Sessions, err = s.data.Sessions.ListSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+	if data.Identities, err = s.data.Identities.ListIdentities(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	tf, err := s.data.TwoFactor.GetTwoFactor(ctx, userID)
+	switch {
+	case err == nil:
+		data.TwoFactorEnabled = tf.Enabled
+	case errors.GetAppError(err).Type != errors.ErrorTypeNotFound:
+		return nil, err
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to encode profile", err)
+	}
+	return content, nil
+}
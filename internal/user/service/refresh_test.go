@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// memoryRevocationStore is an in-memory auth.RevocationStore.
+type memoryRevocationStore struct {
+	revoked map[string]bool
+	before  map[string]time.Time
+}
+
+func newMemoryRevocationStore() *memoryRevocationStore {
+	return &memoryRevocationStore{revoked: make(map[string]bool), before: make(map[string]time.Time)}
+}
+
+func (s *memoryRevocationStore) Revoke(_ context.Context, tokenID string, _ time.Time) error {
+	s.revoked[tokenID] = true
+	return nil
+}
+
+func (s *memoryRevocationStore) RevokeOnce(_ context.Context, tokenID string, _ time.Time) (bool, error) {
+	if s.revoked[tokenID] {
+		return false, nil
+	}
+	s.revoked[tokenID] = true
+	return true, nil
+}
+
+func (s *memoryRevocationStore) IsRevoked(_ context.Context, tokenID string) (bool, error) {
+	return s.revoked[tokenID], nil
+}
+
+func (s *memoryRevocationStore) RevokeUser(_ context.Context, userID string, _ time.Duration) error {
+	s.before[userID] = time.Now()
+	return nil
+}
+
+func (s *memoryRevocationStore) RevokedBefore(_ context.Context, userID string) (time.Time, error) {
+	return s.before[userID], nil
+}
+
+const testJWTSecret = "test-secret-of-at-least-32-characters"
+
+func TestRefreshTokenReuse(t *testing.T) {
+	tests := []struct {
+		name         string
+		sessionID    string
+		wantSession  bool
+		wantUserWide bool
+	}{
+		{name: "session is revoked", sessionID: "sess-1", wantSession: true},
+		{name: "token without a session revokes the user", wantUserWide: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revocations := newMemoryRevocationStore()
+			s := &UserService{
+				repo:             &fakeUserRepository{user: &domain.User{ID: "u-1", Email: "ada@example.com", Role: auth.RoleUser, Active: true}},
+				revocations:      revocations,
+				logger:           logger.New("test", "error"),
+				tracer:           noop.NewTracerProvider().Tracer("test"),
+				jwtSecret:        testJWTSecret,
+				jwtExpiry:        time.Minute,
+				jwtRefreshExpiry: time.Hour,
+			}
+			ctx := context.Background()
+
+			stolen, err := auth.GenerateRefreshToken("u-1", "", "ada@example.com", auth.RoleUser, tt.sessionID, testJWTSecret, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := s.RefreshToken(ctx, &domain.RefreshTokenRequest{RefreshToken: stolen}); err != nil {
+				t.Fatalf("first RefreshToken() error = %v", err)
+			}
+
+			_, err = s.RefreshToken(ctx, &domain.RefreshTokenRequest{RefreshToken: stolen})
+			if err == nil || errors.GetAppError(err).Type != errors.ErrorTypeUnauthorized {
+				t.Fatalf("reused RefreshToken() error = %v, want unauthorized", err)
+			}
+
+			if got := revocations.revoked[tt.sessionID]; got != tt.wantSession {
+				t.Errorf("session revoked = %v, want %v", got, tt.wantSession)
+			}
+			if got := !revocations.before["u-1"].IsZero(); got != tt.wantUserWide {
+				t.Errorf("user revoked = %v, want %v", got, tt.wantUserWide)
+			}
+		})
+	}
+}
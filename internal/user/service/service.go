@@ -2,15 +2,23 @@ package service
 
 import (
 	"context"
+	"net/url"
+	"sync/atomic"
 	"time"
 
+	"github.com/dmehra2102/booking-system/internal/common/audit"
 	"github.com/dmehra2102/booking-system/internal/common/errors"
-	"github.com/dmehra2102/booking-system/internal/common/kafka"
 	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/messagebus"
 	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/timing"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
 	"github.com/dmehra2102/booking-system/internal/user/domain"
 	"github.com/dmehra2102/booking-system/pkg/auth"
 	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
 	"github.com/dmehra2102/booking-system/pkg/validation"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -19,51 +27,115 @@ type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetByID(ctx context.Context, id string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
-	Update(ctx context.Context, id string, updates map[string]any) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, limit, offset int) ([]*domain.User, int64, error)
+	Update(ctx context.Context, id string, version int64, updates map[string]any) error
+	Delete(ctx context.Context, id string) (int, error)
+	List(ctx context.Context, filter domain.ListUsersFilter, params pagination.Params) ([]*domain.User, *pagination.Result, error)
 }
 
+// EmailVerification configures how new and changed email addresses are
+// verified.
+type EmailVerification struct {
+	// URL is the link mailed to users; the token is appended to it.
+	URL    string
+	Expiry time.Duration
+	// Required refuses logins of unverified users instead of only
+	// logging a warning.
+	Required bool
+}
+
+// PasswordReset configures the forgotten password flow.
+type PasswordReset struct {
+	// URL is the link mailed to users; the token is appended to it.
+	URL    string
+	Expiry time.Duration
+}
+
+var _ domain.UserService = (*UserService)(nil)
+
 type UserService struct {
-	repo      UserRepository
-	producer  *kafka.Producer
-	logger    *logger.Logger
-	metrics   *metrics.Metrics
-	tracer    trace.Tracer
-	jwtSecret string
-	jwtExpiry time.Duration
+	repo             UserRepository
+	publisher        messagebus.Publisher
+	revocations      auth.RevocationStore
+	resets           auth.PasswordResetStore
+	logger           *logger.Logger
+	metrics          *metrics.Metrics
+	tracer           trace.Tracer
+	jwtSecret        string
+	jwtExpiry        time.Duration
+	jwtRefreshExpiry time.Duration
+	verification     EmailVerification
+	passwordReset    PasswordReset
+
+	// verificationRequired starts as verification.Required and follows
+	// configuration reloads
+	verificationRequired atomic.Bool
+
+	// twoFactor asks logins for a second factor; it is set by
+	// NewTwoFactorService
+	twoFactor *TwoFactorService
+	// sessions tracks the sessions logins start; it is set by
+	// NewSessionService
+	sessions *SessionService
+	// protection is set by SetLoginProtection
+	protection LoginProtection
+	// privacy tracks the erasure of deleted users; it is set by
+	// NewPrivacyService
+	privacy *PrivacyService
 }
 
 func NewUserService(
 	repo UserRepository,
-	producer *kafka.Producer,
+	publisher messagebus.Publisher,
+	revocations auth.RevocationStore,
+	resets auth.PasswordResetStore,
 	logger *logger.Logger,
 	metrics *metrics.Metrics,
 	tracer trace.Tracer,
 	jwtSecret string,
 	jwtExpiry time.Duration,
+	jwtRefreshExpiry time.Duration,
+	verification EmailVerification,
+	passwordReset PasswordReset,
 ) *UserService {
-	return &UserService{
-		repo:      repo,
-		producer:  producer,
-		logger:    logger,
-		metrics:   metrics,
-		tracer:    tracer,
-		jwtSecret: jwtSecret,
-		jwtExpiry: jwtExpiry,
+	s := &UserService{
+		repo:             repo,
+		publisher:        publisher,
+		revocations:      revocations,
+		resets:           resets,
+		logger:           logger,
+		metrics:          metrics,
+		tracer:           tracer,
+		jwtSecret:        jwtSecret,
+		jwtExpiry:        jwtExpiry,
+		jwtRefreshExpiry: jwtRefreshExpiry,
+		verification:     verification,
+		passwordReset:    passwordReset,
 	}
+	s.verificationRequired.Store(verification.Required)
+	return s
+}
+
+// SetEmailVerificationRequired switches between refusing and only logging
+// logins of unverified users.
+func (s *UserService) SetEmailVerificationRequired(required bool) {
+	s.verificationRequired.Store(required)
 }
 
-func (s *UserService) CreateUser(ctx context.Context, req *domain.CreateUserRequest) (*domain.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, req *domain.CreateUserRequest) (_ *domain.User, err error) {
 	ctx, span := s.tracer.Start(ctx, "user.service.create")
-	defer span.End()
+	defer tracing.End(span, &err)
 
 	// Validate Request
-	if err := validation.ValidateStruct(req); err != nil {
+	doneValidating := timing.Track(ctx, "validation")
+	err = validation.ValidateStruct(req)
+	doneValidating()
+	if err != nil {
 		return nil, err
 	}
 
+	doneChecking := timing.Track(ctx, "db")
 	existingUser, err := s.repo.GetByEmail(ctx, req.Email)
+	doneChecking()
 	if err == nil && existingUser != nil {
 		return nil, errors.NewConflictError("user with this email already exists")
 	}
@@ -78,71 +150,270 @@ func (s *UserService) CreateUser(ctx context.Context, req *domain.CreateUserRequ
 		return nil, errors.NewInternalError("failed to hash password", err)
 	}
 
-	if err := s.repo.Create(ctx, newUser); err != nil {
+	doneWriting := timing.Track(ctx, "db")
+	err = s.repo.Create(ctx, newUser)
+	doneWriting()
+	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(tracing.UserID.String(newUser.ID))
+
+	s.created(ctx, span, newUser)
+	s.requestVerification(ctx, span, newUser)
+
+	return newUser.ToPublic(), nil
+}
+
+// created audits and publishes the creation of user, however they signed
+// up.
+func (s *UserService) created(ctx context.Context, span trace.Span, user *domain.User) {
+	audit.Log(ctx, "user.create", "user", user.ID, nil, user)
 
 	// Publish event
 	event := events.UserCreatedEvent{
-		BaseEvent: events.NewBaseEvent(events.UserCreated, "user-service", span.SpanContext().TraceID().String()),
+		BaseEvent: events.NewBaseEvent(events.UserCreated, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
 		Data: events.UserCreatedData{
-			UserID:    newUser.ID,
-			Email:     newUser.Email,
-			Name:      newUser.Name,
-			CreatedAt: newUser.CreatedAt,
+			UserID:    user.ID,
+			Email:     user.Email,
+			Name:      user.Name,
+			Locale:    i18n.Locale(ctx),
+			CreatedAt: user.CreatedAt,
 		},
 	}
 
-	if err := s.producer.Produce(ctx, string(events.UserCreated), newUser.ID, event); err != nil {
+	donePublishing := timing.Track(ctx, "kafka")
+	err := s.publisher.Publish(ctx, events.UserCreated, user.ID, event)
+	donePublishing()
+	if err != nil {
 		s.logger.WithContext(ctx).WithError(err).Error("failed to publish user created event")
 	}
 
-	s.metrics.UsersTotal.WithLabelValues("created", "user").Inc()
-	s.logger.WithContext(ctx).With("user_id", newUser.ID).Info("user created successfully")
-
-	return newUser.ToPublic(), nil
+	s.metrics.UsersTotal.WithLabelValues(tenancy.ID(ctx)).Inc()
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("user created successfully")
 }
 
-func (s *UserService) Login(ctx context.Context, req *domain.LoginRequest) (*domain.LoginResponse, error) {
+func (s *UserService) Login(ctx context.Context, req *domain.LoginRequest) (_ *domain.LoginResponse, err error) {
 	ctx, span := s.tracer.Start(ctx, "user.service.login")
-	defer span.End()
+	defer tracing.End(span, &err)
 
 	// Validate Request
 	if err := validation.ValidateStruct(req); err != nil {
 		return nil, errors.NewValidationError("validation failed", err)
 	}
 
+	if err := s.checkLoginAllowed(ctx, req); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.repo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, errors.NewUnauthorizedError("invalid credentials")
+		return nil, s.loginFailed(ctx, req.Email)
 	}
 
 	// Check password
 	if !user.CheckPassword(req.Password) {
-		return nil, errors.NewUnauthorizedError("invalid credentials")
+		return nil, s.loginFailed(ctx, req.Email)
+	}
+	s.loginSucceeded(ctx, req.Email)
+
+	if !user.EmailVerified {
+		if s.verificationRequired.Load() {
+			s.authFailed(ctx, "unverified")
+			return nil, errors.NewForbiddenError("email address has not been verified")
+		}
+		s.logger.WithContext(ctx).With("user_id", user.ID).Warn("login with unverified email address")
 	}
 
-	// Generate JWT token
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, s.jwtSecret, s.jwtExpiry)
+	// Generate JWT tokens
+	response, err := s.completeLogin(ctx, user)
 	if err != nil {
-		return nil, errors.NewInternalError("failed to generate token", err)
+		return nil, err
 	}
 
-	response := &domain.LoginResponse{
-		Token:     token,
-		User:      user,
-		ExpiresAt: time.Now().Add(s.jwtExpiry),
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("user logged in succcessfully")
+
+	return response, nil
+}
+
+// RefreshToken exchanges a valid refresh token for a new token pair. The
+// presented refresh token is revoked so each one can only be used once.
+func (s *UserService) RefreshToken(ctx context.Context, req *domain.RefreshTokenRequest) (_ *domain.LoginResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.refresh_token")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
 	}
 
-	s.logger.WithContext(ctx).With("user_id", user.ID).Info("user logged in succcessfully")
+	claims, err := auth.ValidateRefreshToken(req.RefreshToken, s.jwtSecret)
+	if err != nil {
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
+	ctx = tenancy.WithID(ctx, claims.Tenant())
+
+	revoked, err := auth.IsTokenRevoked(ctx, s.revocations, claims)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to verify refresh token", err)
+	}
+	if revoked {
+		// A token that was revoked on its own was rotated or logged out
+		// before, so whoever presents it again may have stolen it
+		if reused, err := s.revocations.IsRevoked(ctx, claims.ID); err == nil && reused {
+			s.refreshTokenReused(ctx, claims)
+		}
+		return nil, errors.NewUnauthorizedError("refresh token has been revoked")
+	}
+
+	// Reload the user so deactivated accounts and role changes take effect
+	user, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	// Concurrent refreshes with the same token pass the check above; only
+	// the first one to revoke it gets a new pair
+	rotated, err := s.revocations.RevokeOnce(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to rotate refresh token", err)
+	}
+	if !rotated {
+		s.refreshTokenReused(ctx, claims)
+		return nil, errors.NewUnauthorizedError("refresh token has been revoked")
+	}
+
+	response, err := s.issueTokens(ctx, user, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("token refreshed successfully")
 
 	return response, nil
 }
 
-func (s *UserService) GetUser(ctx context.Context, id string) (*domain.User, error) {
-	ctx, span := s.tracer.Start(ctx, "user.service.get")
-	defer span.End()
+// refreshTokenReused ends the session of a refresh token that was used
+// again after its rotation, which revokes every token issued for the
+// session: the one the legitimate client rotated to as well as any an
+// attacker obtained. Tokens issued before sessions were tracked have no
+// session, so all tokens of their user are revoked instead.
+func (s *UserService) refreshTokenReused(ctx context.Context, claims *auth.Claims) {
+	log := s.logger.WithContext(ctx).With("user_id", claims.UserID).With("session_id", claims.SessionID)
+
+	var err error
+	switch {
+	case claims.SessionID == "":
+		err = s.revocations.RevokeUser(ctx, claims.UserID, s.jwtRefreshExpiry)
+	case s.sessions != nil:
+		err = s.sessions.end(ctx, claims.UserID, claims.SessionID)
+		if err != nil && errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			err = s.revocations.Revoke(ctx, claims.SessionID, time.Now().Add(s.jwtRefreshExpiry))
+		}
+	default:
+		err = s.revocations.Revoke(ctx, claims.SessionID, time.Now().Add(s.jwtRefreshExpiry))
+	}
+	if err != nil {
+		log.WithError(err).Error("failed to revoke the session of a reused refresh token")
+		return
+	}
+
+	log.Warn("refresh token reused, session revoked")
+}
+
+// Logout ends the session of the access token used for the request and
+// revokes the token and, when given, the refresh token issued alongside
+// it. The refresh token must belong to the same user.
+func (s *UserService) Logout(ctx context.Context, userID, tokenID, sessionID string, tokenExpiresAt time.Time, req *domain.LogoutRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.logout")
+	defer tracing.End(span, &err)
+
+	var refreshClaims *auth.Claims
+	if req.RefreshToken != "" {
+		refreshClaims, err = auth.ValidateRefreshToken(req.RefreshToken, s.jwtSecret)
+		if err != nil {
+			return errors.NewValidationError("invalid refresh token", err)
+		}
+		if refreshClaims.UserID != userID {
+			return errors.NewForbiddenError("refresh token belongs to another user")
+		}
+	}
+
+	if err := s.revocations.Revoke(ctx, tokenID, tokenExpiresAt); err != nil {
+		return errors.NewInternalError("failed to revoke token", err)
+	}
+
+	if sessionID != "" && s.sessions != nil {
+		if err := s.sessions.end(ctx, userID, sessionID); err != nil && errors.GetAppError(err).Type != errors.ErrorTypeNotFound {
+			return err
+		}
+	}
+
+	if refreshClaims != nil {
+		if err := s.revocations.Revoke(ctx, refreshClaims.ID, refreshClaims.ExpiresAt.Time); err != nil {
+			return errors.NewInternalError("failed to revoke refresh token", err)
+		}
+	}
+
+	s.logger.WithContext(ctx).Info("user logged out successfully")
+
+	return nil
+}
+
+// completeLogin returns the token pair of a user who authenticated, or
+// the two-factor token of a login that needs a second factor.
+func (s *UserService) completeLogin(ctx context.Context, user *domain.User) (*domain.LoginResponse, error) {
+	if s.twoFactor != nil {
+		response, err := s.twoFactor.challengeLogin(ctx, user)
+		if response != nil || err != nil {
+			return response, err
+		}
+	}
+	return s.issueTokens(ctx, user, "")
+}
+
+// issueTokens returns a token pair for sessionID, which is refreshed, or
+// for a new session when sessionID is empty.
+func (s *UserService) issueTokens(ctx context.Context, user *domain.User, sessionID string) (*domain.LoginResponse, error) {
+	if sessionID == "" {
+		s.notifyNewClient(ctx, user)
+	}
+
+	if s.sessions != nil {
+		var err error
+		if sessionID == "" {
+			sessionID, err = s.sessions.start(ctx, user)
+		} else {
+			err = s.sessions.refresh(ctx, user.ID, sessionID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+
+	token, err := auth.GenerateToken(user.ID, user.TenantID, user.Email, user.Role, sessionID, s.jwtSecret, s.jwtExpiry)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate token", err)
+	}
+
+	refreshToken, err := auth.GenerateRefreshToken(user.ID, user.TenantID, user.Email, user.Role, sessionID, s.jwtSecret, s.jwtRefreshExpiry)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate refresh token", err)
+	}
+
+	return &domain.LoginResponse{
+		Token:            token,
+		RefreshToken:     refreshToken,
+		User:             user.ToPublic(),
+		ExpiresAt:        now.Add(s.jwtExpiry),
+		RefreshExpiresAt: now.Add(s.jwtRefreshExpiry),
+	}, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id string) (_ *domain.User, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.get", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
 
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -152,33 +423,37 @@ func (s *UserService) GetUser(ctx context.Context, id string) (*domain.User, err
 	return user.ToPublic(), nil
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, id string, req *domain.UpdateUserRequest) (*domain.User, error) {
-	ctx, span := s.tracer.Start(ctx, "user.service.update")
-	defer span.End()
+func (s *UserService) UpdateUser(ctx context.Context, id string, req *domain.UpdateUserRequest) (_ *domain.User, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.update", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
 
 	// validate request
 	if err := validation.ValidateStruct(req); err != nil {
 		return nil, errors.NewValidationError("validation failed", err)
 	}
 
-	_, err := s.repo.GetByID(ctx, id)
+	before, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if req.Version != 0 && req.Version != before.Version {
+		return nil, errors.NewPreconditionFailedError("user was changed since it was read")
+	}
 
 	updates := make(map[string]any)
 	if req.Name != "" {
 		updates["name"] = req.Name
 	}
-	if req.Email != "" {
+	if req.Email != "" && req.Email != before.Email {
 		updates["email"] = req.Email
+		updates["email_verified"] = false
 	}
 
 	if len(updates) == 0 {
 		return s.GetUser(ctx, id)
 	}
 
-	if err := s.repo.Update(ctx, id, updates); err != nil {
+	if err := s.repo.Update(ctx, id, before.Version, updates); err != nil {
 		return nil, err
 	}
 
@@ -187,8 +462,10 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, req *domain.Upd
 		return nil, err
 	}
 
+	audit.Log(ctx, "user.update", "user", id, before, updatedUser)
+
 	event := events.UserUpdatedEvent{
-		BaseEvent: events.NewBaseEvent(events.UserUpdated, "user-service", span.SpanContext().TraceID().String()),
+		BaseEvent: events.NewBaseEvent(events.UserUpdated, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
 		Data: events.UserUpdatedData{
 			UserID:    updatedUser.ID,
 			Email:     updatedUser.Email,
@@ -197,62 +474,272 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, req *domain.Upd
 		},
 	}
 
-	if err := s.producer.Produce(ctx, string(events.UserUpdated), updatedUser.ID, event); err != nil {
+	if err := s.publisher.Publish(ctx, events.UserUpdated, updatedUser.ID, event); err != nil {
 		s.logger.WithContext(ctx).WithError(err).Error("failed to publish user updated event")
 	}
 
+	if updatedUser.Email != before.Email {
+		s.requestVerification(ctx, span, updatedUser)
+	}
+
 	s.logger.WithContext(ctx).With("user_id", id).Info("user updated successfully")
 
 	return updatedUser.ToPublic(), nil
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, id string) error {
-	ctx, span := s.tracer.Start(ctx, "user.service.delete")
-	defer span.End()
+func (s *UserService) UpdateRole(ctx context.Context, id string, req *domain.UpdateRoleRequest) (_ *domain.User, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.update_role", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	before, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, id, before.Version, map[string]any{"role": req.Role}); err != nil {
+		return nil, err
+	}
+
+	updatedUser, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "user.update_role", "user", id, before, updatedUser)
+	s.logger.WithContext(ctx).With("user_id", id).With("role", req.Role).Info("user role updated successfully")
+
+	return updatedUser.ToPublic(), nil
+}
+
+// DeleteUser anonymizes a user and ends their sessions. The services
+// holding personal data of the user erase it when the deletion is
+// published, and report back for the erasure certificate.
+func (s *UserService) DeleteUser(ctx context.Context, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.delete", trace.WithAttributes(tracing.UserID.String(id)))
+	defer tracing.End(span, &err)
 
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	erased, err := s.repo.Delete(ctx, id)
+	if err != nil {
 		return err
 	}
+	if err := s.revocations.RevokeUser(ctx, id, s.jwtRefreshExpiry); err != nil {
+		return errors.NewInternalError("failed to revoke existing sessions", err)
+	}
+
+	deletedAt := time.Now().UTC()
+	if s.privacy != nil {
+		if err := s.privacy.startErasure(ctx, id, deletedAt, erased); err != nil {
+			return err
+		}
+	}
+
+	// The audit log keeps no copy of the erased profile
+	audit.Log(ctx, "user.delete", "user", id, nil, nil)
 
 	// Publish event
 	event := events.UserDeletedEvent{
-		BaseEvent: events.NewBaseEvent(events.UserDeleted, "user-service", span.SpanContext().TraceID().String()),
+		BaseEvent: events.NewBaseEvent(events.UserDeleted, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
 		Data: events.UserDeletedData{
 			UserID:    user.ID,
-			DeletedAt: time.Now().UTC(),
+			DeletedAt: deletedAt,
 		},
 	}
 
-	if err := s.producer.Produce(ctx, "user.deleted", user.ID, event); err != nil {
+	if err := s.publisher.Publish(ctx, events.UserDeleted, user.ID, event); err != nil {
 		s.logger.WithContext(ctx).WithError(err).Error("failed to publish user deleted event")
 	}
 
-	s.metrics.UsersDeleted.WithLabelValues("deleted", "user").Inc()
+	s.metrics.UsersDeleted.WithLabelValues(tenancy.ID(ctx)).Inc()
 	s.logger.WithContext(ctx).With("user_id", id).Info("user deleted successfully")
 
 	return nil
 }
 
-func (s *UserService) ListUsers(ctx context.Context, page, pageSize int) ([]*domain.User, int64, error) {
-	ctx, span := s.tracer.Start(ctx, "user.service.list")
-	defer span.End()
+// VerifyEmail marks the address a verification token was issued for as
+// verified. Tokens issued before the user changed their email are
+// rejected.
+func (s *UserService) VerifyEmail(ctx context.Context, req *domain.VerifyEmailRequest) (_ *domain.User, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.verify_email")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	claims, err := auth.ValidateEmailVerificationToken(req.Token, s.jwtSecret)
+	if err != nil {
+		return nil, errors.NewValidationError("invalid or expired verification token", err)
+	}
+	ctx = tenancy.WithID(ctx, claims.Tenant())
 
-	if page < 1 {
-		page = 1
+	user, err := s.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+
+	if user.Email != claims.Email {
+		return nil, errors.NewValidationError("verification token was issued for a different email address", nil)
 	}
 
-	offset := (page - 1) * pageSize
-	users, total, err := s.repo.List(ctx, pageSize, offset)
+	if user.EmailVerified {
+		return user.ToPublic(), nil
+	}
+
+	if err := s.repo.Update(ctx, user.ID, user.Version, map[string]any{"email_verified": true}); err != nil {
+		return nil, err
+	}
+
+	before := *user
+	user.EmailVerified = true
+	user.Version++
+	audit.Log(ctx, "user.verify_email", "user", user.ID, &before, user)
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("email address verified")
+
+	return user.ToPublic(), nil
+}
+
+// ForgotPassword mails a password reset link if an active account uses
+// the address. It succeeds either way so callers cannot probe which
+// addresses are registered.
+func (s *UserService) ForgotPassword(ctx context.Context, req *domain.ForgotPasswordRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.forgot_password")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return errors.NewValidationError("validation failed", err)
+	}
+
+	user, err := s.repo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Type == errors.ErrorTypeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.resets.Issue(ctx, user.ID, s.passwordReset.Expiry)
+	if err != nil {
+		return errors.NewInternalError("failed to issue password reset token", err)
+	}
+
+	event := events.UserPasswordResetRequestedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserPasswordResetRequested, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserPasswordResetRequestedData{
+			UserID:    user.ID,
+			Email:     user.Email,
+			Name:      user.Name,
+			ResetURL:  s.passwordReset.URL + url.QueryEscape(token),
+			ExpiresAt: time.Now().UTC().Add(s.passwordReset.Expiry),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.UserPasswordResetRequested, user.ID, event); err != nil {
+		return errors.NewInternalError("failed to request password reset email", err)
+	}
+
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("password reset requested")
+
+	return nil
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword and
+// signs the user out everywhere.
+func (s *UserService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.reset_password")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return errors.NewValidationError("validation failed", err)
+	}
+
+	tenantID, userID, err := s.resets.Consume(ctx, req.Token)
+	if err != nil {
+		if err == auth.ErrInvalidResetToken {
+			return errors.NewValidationError(err.Error(), nil)
+		}
+		return errors.NewInternalError("failed to verify password reset token", err)
+	}
+	ctx = tenancy.WithID(ctx, tenantID)
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user.Password = req.Password
+	if err := user.HashPassword(); err != nil {
+		return errors.NewInternalError("failed to hash password", err)
+	}
+
+	if err := s.repo.Update(ctx, user.ID, user.Version, map[string]any{"password_hash": user.Password}); err != nil {
+		return err
+	}
+
+	if err := s.endSessions(ctx, user.ID); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "user.reset_password", "user", user.ID, nil, nil)
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("password reset successfully")
+
+	return nil
+}
+
+// endSessions logs userID out everywhere by revoking all their tokens
+// issued up to now.
+func (s *UserService) endSessions(ctx context.Context, userID string) error {
+	if err := s.revocations.RevokeUser(ctx, userID, s.jwtRefreshExpiry); err != nil {
+		return errors.NewInternalError("failed to revoke existing sessions", err)
+	}
+
+	if s.sessions != nil {
+		return s.sessions.repo.DeleteSessions(ctx, userID)
+	}
+	return nil
+}
+
+// requestVerification mails user a link to verify their current email
+// address. Failures are logged; the user can still verify later.
+func (s *UserService) requestVerification(ctx context.Context, span trace.Span, user *domain.User) {
+	token, err := auth.GenerateEmailVerificationToken(user.ID, user.TenantID, user.Email, s.jwtSecret, s.verification.Expiry)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to generate verification token")
+		return
+	}
+
+	event := events.UserVerificationRequestedEvent{
+		BaseEvent: events.NewBaseEvent(events.UserVerificationRequested, "user-service", span.SpanContext().TraceID().String(), tenancy.ID(ctx)),
+		Data: events.UserVerificationRequestedData{
+			UserID:          user.ID,
+			Email:           user.Email,
+			Name:            user.Name,
+			VerificationURL: s.verification.URL + url.QueryEscape(token),
+			ExpiresAt:       time.Now().UTC().Add(s.verification.Expiry),
+		},
+	}
+
+	if err := s.publisher.Publish(ctx, events.UserVerificationRequested, user.ID, event); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to publish verification requested event")
+	}
+}
+
+func (s *UserService) ListUsers(ctx context.Context, filter domain.ListUsersFilter, params pagination.Params) (_ []*domain.User, _ *pagination.Result, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.list")
+	defer tracing.End(span, &err)
+
+	params.Normalize()
+	users, result, err := s.repo.List(ctx, filter, params)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
 	publicUsers := make([]*domain.User, len(users))
@@ -260,5 +747,5 @@ func (s *UserService) ListUsers(ctx context.Context, page, pageSize int) ([]*dom
 		publicUsers[i] = user.ToPublic()
 	}
 
-	return publicUsers, total, nil
+	return publicUsers, result, nil
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+)
+
+// TestImplementsDomainServices backs up the var _ assertions the handlers
+// rely on.
+func TestImplementsDomainServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		iface    reflect.Type
+		concrete reflect.Type
+	}{
+		{"UserService", reflect.TypeFor[domain.UserService](), reflect.TypeFor[*UserService]()},
+		{"TwoFactorService", reflect.TypeFor[domain.TwoFactorService](), reflect.TypeFor[*TwoFactorService]()},
+		{"SessionService", reflect.TypeFor[domain.SessionService](), reflect.TypeFor[*SessionService]()},
+		{"OAuthService", reflect.TypeFor[domain.OAuthService](), reflect.TypeFor[*OAuthService]()},
+		{"PrivacyService", reflect.TypeFor[domain.PrivacyService](), reflect.TypeFor[*PrivacyService]()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.concrete.Implements(tt.iface) {
+				t.Errorf("%s does not implement domain.%s", tt.concrete, tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.Session) error
+	ListSessions(ctx context.Context, userID string) ([]*domain.Session, error)
+	TouchSession(ctx context.Context, userID, id string, client domain.Client, expiresAt time.Time) error
+	DeleteSession(ctx context.Context, userID, id string) error
+	DeleteSessions(ctx context.Context, userID string) error
+}
+
+var _ domain.SessionService = (*SessionService)(nil)
+
+// SessionService tracks the login sessions of users. Every login starts a
+// session whose ID the issued tokens carry, and refreshing them keeps it
+// alive. Ending a session revokes its ID, which rejects its tokens
+// wherever they are checked.
+type SessionService struct {
+	users  *UserService
+	repo   SessionRepository
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+// NewSessionService returns a SessionService and makes the logins of users
+// start sessions.
+func NewSessionService(users *UserService, repo SessionRepository, logger *logger.Logger, tracer trace.Tracer) *SessionService {
+	s := &SessionService{
+		users:  users,
+		repo:   repo,
+		logger: logger,
+		tracer: tracer,
+	}
+	users.sessions = s
+	return s
+}
+
+// ListSessions returns the active sessions of userID, most recently used
+// first, marking currentSessionID as current.
+func (s *SessionService) ListSessions(ctx context.Context, userID, currentSessionID string) (_ []*domain.Session, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.list_sessions", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	sessions, err := s.repo.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// EndSession logs userID out of one of their sessions.
+func (s *SessionService) EndSession(ctx context.Context, userID, sessionID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.end_session", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := s.end(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "user.end_session", "user", userID, nil, nil)
+	s.logger.WithContext(ctx).With("user_id", userID).With("session_id", sessionID).Info("session ended")
+
+	return nil
+}
+
+// EndAllSessions logs userID out everywhere, including the session of the
+// request.
+func (s *SessionService) EndAllSessions(ctx context.Context, userID string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.end_all_sessions", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := s.users.endSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "user.end_all_sessions", "user", userID, nil, nil)
+	s.logger.WithContext(ctx).With("user_id", userID).Info("all sessions ended")
+
+	return nil
+}
+
+// start records a new session of user from the client of ctx.
+func (s *SessionService) start(ctx context.Context, user *domain.User) (string, error) {
+	client := domain.ClientFrom(ctx)
+	now := time.Now().UTC()
+
+	session := &domain.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.users.jwtRefreshExpiry),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return "", err
+	}
+
+	return session.ID, nil
+}
+
+// refresh extends a session whose tokens are being refreshed. A session
+// that was ended cannot be refreshed.
+func (s *SessionService) refresh(ctx context.Context, userID, sessionID string) error {
+	expiresAt := time.Now().UTC().Add(s.users.jwtRefreshExpiry)
+	if err := s.repo.TouchSession(ctx, userID, sessionID, domain.ClientFrom(ctx), expiresAt); err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			return errors.NewUnauthorizedError("session has ended")
+		}
+		return err
+	}
+	return nil
+}
+
+// end removes a session and revokes its ID for as long as its tokens could
+// be valid.
+func (s *SessionService) end(ctx context.Context, userID, sessionID string) error {
+	if err := s.repo.DeleteSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	if err := s.users.revocations.Revoke(ctx, sessionID, time.Now().Add(s.users.jwtRefreshExpiry)); err != nil {
+		return errors.NewInternalError("failed to revoke session", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/user/domain"
+	"github.com/dmehra2102/booking-system/pkg/auth"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TwoFactorRepository interface {
+	GetTwoFactor(ctx context.Context, userID string) (*domain.TwoFactor, error)
+	SavePendingTwoFactor(ctx context.Context, tf *domain.TwoFactor) error
+	EnableTwoFactor(ctx context.Context, userID string, step int64, backupCodes []string) error
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseBackupCode(ctx context.Context, userID, hash string) (bool, error)
+	ReplaceBackupCodes(ctx context.Context, userID string, backupCodes []string) error
+	DeleteTwoFactor(ctx context.Context, userID string) error
+}
+
+// TwoFactorPolicy configures two-factor authentication.
+type TwoFactorPolicy struct {
+	// Issuer names the service in authenticator apps.
+	Issuer string
+	// RequiredRoles are the roles that cannot log in without a second
+	// factor. Their users enroll during their next login.
+	RequiredRoles []string
+	// ChallengeExpiry is the time a user has for the second step of a
+	// login.
+	ChallengeExpiry time.Duration
+}
+
+// backupCodeCount is the number of backup codes generated at a time.
+const backupCodeCount = 10
+
+// maxChallengeFailures is the number of wrong codes after which a
+// two-factor token is revoked, so codes cannot be guessed with it.
+const maxChallengeFailures = 5
+
+var backupCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var _ domain.TwoFactorService = (*TwoFactorService)(nil)
+
+// TwoFactorService manages the TOTP enrollment of users and the second
+// step of their logins. Logins of enrolled users, and of users whose role
+// requires an enrollment, return a short-lived two-factor token instead of
+// a token pair, which CompleteLogin exchanges for the pair with a code.
+type TwoFactorService struct {
+	users  *UserService
+	repo   TwoFactorRepository
+	box    *auth.SecretBox
+	policy TwoFactorPolicy
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+// NewTwoFactorService returns a TwoFactorService encrypting secrets with
+// box, and makes the logins of users ask for the second factor.
+func NewTwoFactorService(users *UserService, repo TwoFactorRepository, box *auth.SecretBox, policy TwoFactorPolicy, logger *logger.Logger, tracer trace.Tracer) *TwoFactorService {
+	s := &TwoFactorService{
+		users:  users,
+		repo:   repo,
+		box:    box,
+		policy: policy,
+		logger: logger,
+		tracer: tracer,
+	}
+	users.twoFactor = s
+	return s
+}
+
+func (s *TwoFactorService) Get(ctx context.Context, userID string) (_ *domain.TwoFactor, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.get_two_factor", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetTwoFactor(ctx, userID)
+}
+
+// Setup starts an enrollment of userID with a new secret. It is enabled
+// by Enable once the authenticator app has the secret.
+func (s *TwoFactorService) Setup(ctx context.Context, userID string) (_ *domain.TwoFactorSetup, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.setup_two_factor", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	user, err := s.users.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.setup(ctx, user)
+}
+
+// Enable enables the pending enrollment of userID with a code of the
+// authenticator app, and returns the backup codes.
+func (s *TwoFactorService) Enable(ctx context.Context, userID string, req *domain.TwoFactorCodeRequest) (_ *domain.TwoFactorBackupCodes, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.enable_two_factor", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Enabled {
+		return nil, errors.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	codes, err := s.enable(ctx, tf, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TwoFactorBackupCodes{BackupCodes: codes}, nil
+}
+
+// Disable removes the enrollment of userID, confirmed with a code. Users
+// whose role requires two-factor authentication cannot disable it.
+func (s *TwoFactorService) Disable(ctx context.Context, userID string, req *domain.TwoFactorCodeRequest) (err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.disable_two_factor", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return errors.NewValidationError("validation failed", err)
+	}
+
+	user, err := s.users.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.required(user.Role) {
+		return errors.NewForbiddenError("two-factor authentication is required for your role")
+	}
+
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// A pending enrollment never protected anything
+	if tf.Enabled {
+		if err := s.verify(ctx, tf, req.Code); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteTwoFactor(ctx, userID); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "user.two_factor_disable", "user", userID, tf, nil)
+	s.logger.WithContext(ctx).With("user_id", userID).Info("two-factor authentication disabled")
+
+	return nil
+}
+
+// RegenerateBackupCodes replaces the backup codes of userID, confirmed
+// with a code.
+func (s *TwoFactorService) RegenerateBackupCodes(ctx context.Context, userID string, req *domain.TwoFactorCodeRequest) (_ *domain.TwoFactorBackupCodes, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.regenerate_backup_codes", trace.WithAttributes(tracing.UserID.String(userID)))
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	tf, err := s.repo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !tf.Enabled {
+		return nil, errors.NewNotFoundError("two-factor enrollment")
+	}
+	if err := s.verify(ctx, tf, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate backup codes", err)
+	}
+	if err := s.repo.ReplaceBackupCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "user.two_factor_backup_codes", "user", userID, nil, nil)
+	s.logger.WithContext(ctx).With("user_id", userID).Info("backup codes regenerated")
+
+	return &domain.TwoFactorBackupCodes{BackupCodes: codes}, nil
+}
+
+// SetupLogin starts the enrollment a login requires, for the holder of
+// its two-factor token.
+func (s *TwoFactorService) SetupLogin(ctx context.Context, req *domain.TwoFactorTokenRequest) (_ *domain.TwoFactorSetup, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.setup_two_factor_login")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	ctx, _, user, err := s.challenge(ctx, req.TwoFactorToken)
+	if err != nil {
+		return nil, err
+	}
+	if !s.required(user.Role) {
+		return nil, errors.NewUnauthorizedError("invalid two-factor token")
+	}
+
+	return s.setup(ctx, user)
+}
+
+// CompleteLogin exchanges a two-factor token and a code for a token pair.
+// A code of an enrollment the login required enables it, and the backup
+// codes are returned with the pair.
+func (s *TwoFactorService) CompleteLogin(ctx context.Context, req *domain.TwoFactorLoginRequest) (_ *domain.LoginResponse, err error) {
+	ctx, span := s.tracer.Start(ctx, "user.service.complete_two_factor_login")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	ctx, claims, user, err := s.challenge(ctx, req.TwoFactorToken)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(tracing.UserID.String(user.ID))
+
+	tf, err := s.repo.GetTwoFactor(ctx, user.ID)
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeNotFound {
+			return nil, errors.NewUnauthorizedError("two-factor authentication is not set up")
+		}
+		return nil, err
+	}
+
+	var codes []string
+	switch {
+	case tf.Enabled:
+		err = s.verify(ctx, tf, req.Code)
+	case s.required(user.Role):
+		codes, err = s.enable(ctx, tf, req.Code)
+	default:
+		err = errors.NewUnauthorizedError("invalid two-factor token")
+	}
+	if err != nil {
+		if errors.GetAppError(err).Type == errors.ErrorTypeUnauthorized {
+			s.challengeFailed(ctx, claims, user)
+		}
+		return nil, err
+	}
+
+	// The two-factor token is single-use like a refresh token
+	if err := s.users.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, errors.NewInternalError("failed to revoke two-factor token", err)
+	}
+
+	response, err := s.users.issueTokens(ctx, user, "")
+	if err != nil {
+		return nil, err
+	}
+	response.BackupCodes = codes
+
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("user logged in with second factor")
+
+	return response, nil
+}
+
+// challengeLogin returns the response of a login of user that needs a
+// second factor, or nil when it does not.
+func (s *TwoFactorService) challengeLogin(ctx context.Context, user *domain.User) (*domain.LoginResponse, error) {
+	enabled := false
+	tf, err := s.repo.GetTwoFactor(ctx, user.ID)
+	switch {
+	case err == nil:
+		enabled = tf.Enabled
+	case errors.GetAppError(err).Type != errors.ErrorTypeNotFound:
+		return nil, err
+	}
+
+	if !enabled && !s.required(user.Role) {
+		return nil, nil
+	}
+
+	token, err := auth.GenerateTwoFactorToken(user.ID, user.TenantID, user.Email, user.Role, s.users.jwtSecret, s.policy.ChallengeExpiry)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate two-factor token", err)
+	}
+
+	return &domain.LoginResponse{
+		TwoFactorRequired:      enabled,
+		TwoFactorSetupRequired: !enabled,
+		TwoFactorToken:         token,
+	}, nil
+}
+
+// challengeFailed counts a wrong code entered with the two-factor token of
+// claims, towards the lockout of user's account as well, and revokes the
+// token once it saw maxChallengeFailures wrong codes. Failures are logged;
+// the login fails anyway.
+func (s *TwoFactorService) challengeFailed(ctx context.Context, claims *auth.Claims, user *domain.User) {
+	s.users.authFailed(ctx, "invalid_two_factor_code")
+	s.users.recordLoginFailure(ctx, user.Email)
+
+	attempts := s.users.protection.Attempts
+	if attempts == nil {
+		return
+	}
+	log := s.logger.WithContext(ctx).With("user_id", user.ID)
+
+	failures, err := attempts.RecordChallengeFailure(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		log.WithError(err).Error("failed to record wrong two-factor code")
+		return
+	}
+	if failures < maxChallengeFailures {
+		return
+	}
+
+	if err := s.users.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		log.WithError(err).Error("failed to revoke two-factor token")
+		return
+	}
+	log.Warn("two-factor token revoked after wrong codes")
+}
+
+// challenge validates a two-factor token and returns ctx in its tenant
+// with the token's claims and user.
+func (s *TwoFactorService) challenge(ctx context.Context, token string) (context.Context, *auth.Claims, *domain.User, error) {
+	claims, err := auth.ValidateTwoFactorToken(token, s.users.jwtSecret)
+	if err != nil {
+		return ctx, nil, nil, errors.NewUnauthorizedError("invalid two-factor token")
+	}
+	ctx = tenancy.WithID(ctx, claims.Tenant())
+
+	revoked, err := auth.IsTokenRevoked(ctx, s.users.revocations, claims)
+	if err != nil {
+		return ctx, nil, nil, errors.NewInternalError("failed to verify two-factor token", err)
+	}
+	if revoked {
+		return ctx, nil, nil, errors.NewUnauthorizedError("invalid two-factor token")
+	}
+
+	user, err := s.users.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return ctx, nil, nil, errors.NewUnauthorizedError("invalid two-factor token")
+	}
+
+	return ctx, claims, user, nil
+}
+
+func (s *TwoFactorService) setup(ctx context.Context, user *domain.User) (*domain.TwoFactorSetup, error) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate two-factor secret", err)
+	}
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to encrypt two-factor secret", err)
+	}
+
+	if err := s.repo.SavePendingTwoFactor(ctx, &domain.TwoFactor{UserID: user.ID, Secret: sealed}); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithContext(ctx).With("user_id", user.ID).Info("two-factor enrollment started")
+
+	return &domain.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.policy.Issuer, user.Email, secret),
+	}, nil
+}
+
+// enable enables a pending enrollment with a code of the authenticator
+// app and returns the new backup codes.
+func (s *TwoFactorService) enable(ctx context.Context, tf *domain.TwoFactor, code string) ([]string, error) {
+	secret, err := s.box.Open(tf.Secret)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to decrypt two-factor secret", err)
+	}
+
+	step, ok := auth.ValidateTOTP(secret, normalizeCode(code), time.Now(), tf.LastUsedStep)
+	if !ok {
+		return nil, errors.NewUnauthorizedError("invalid two-factor code")
+	}
+
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return nil, errors.NewInternalError("failed to generate backup codes", err)
+	}
+	if err := s.repo.EnableTwoFactor(ctx, tf.UserID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "user.two_factor_enable", "user", tf.UserID, nil, nil)
+	s.logger.WithContext(ctx).With("user_id", tf.UserID).Info("two-factor authentication enabled")
+
+	return codes, nil
+}
+
+// verify accepts a code of the authenticator app or a backup code of an
+// enabled enrollment, and uses it up.
+func (s *TwoFactorService) verify(ctx context.Context, tf *domain.TwoFactor, code string) error {
+	code = normalizeCode(code)
+
+	var ok bool
+	if isTOTPCode(code) {
+		secret, err := s.box.Open(tf.Secret)
+		if err != nil {
+			return errors.NewInternalError("failed to decrypt two-factor secret", err)
+		}
+		step, valid := auth.ValidateTOTP(secret, code, time.Now(), tf.LastUsedStep)
+		if valid {
+			if ok, err = s.repo.UseStep(ctx, tf.UserID, step); err != nil {
+				return err
+			}
+		}
+	} else {
+		var err error
+		if ok, err = s.repo.UseBackupCode(ctx, tf.UserID, hashBackupCode(code)); err != nil {
+			return err
+		}
+		if ok {
+			s.logger.WithContext(ctx).With("user_id", tf.UserID).Warn("backup code used")
+		}
+	}
+
+	if !ok {
+		return errors.NewUnauthorizedError("invalid two-factor code")
+	}
+	return nil
+}
+
+func (s *TwoFactorService) required(role string) bool {
+	return slices.Contains(s.policy.RequiredRoles, role)
+}
+
+// generateBackupCodes returns new backup codes, formatted as XXXX-XXXX,
+// and their hashes.
+func generateBackupCodes() (codes, hashes []string, err error) {
+	for range backupCodeCount {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := backupCodeEncoding.EncodeToString(raw)
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, hashBackupCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashBackupCode hashes a normalized backup code for storage. Backup codes
+// are random, so a fast hash is enough.
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeCode drops the separators users type or paste along with a
+// code.
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+)
+
+// WebhookService is the application API of the webhook module. The HTTP
+// handler depends on it and service.WebhookService implements it.
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, createdBy string, req *CreateEndpointRequest) (*CreatedEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]*Endpoint, error)
+	GetEndpoint(ctx context.Context, id string) (*Endpoint, error)
+	UpdateEndpoint(ctx context.Context, id string, req *UpdateEndpointRequest) (*Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, endpointID string, status DeliveryStatus, params pagination.Params) ([]*Delivery, *pagination.Result, error)
+	GetDelivery(ctx context.Context, id string) (*Delivery, error)
+	Redeliver(ctx context.Context, id string) (*Delivery, error)
+}
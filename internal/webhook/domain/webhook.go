@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventTypes are the events endpoints can subscribe to.
+var EventTypes = []events.EventType{
+	events.BookingRequested,
+	events.BookingConfirmed,
+	events.BookingCancelled,
+	events.BookingUpdated,
+	events.BookingCheckedIn,
+	events.BookingCheckedOut,
+	events.BookingNoShow,
+	events.PaymentProcessed,
+	events.PaymentFailed,
+	events.PaymentRefunded,
+}
+
+// DeliverySortFields are the fields ListDeliveries can sort by.
+var DeliverySortFields = []string{"created_at"}
+
+// Endpoint is an HTTPS URL of an external integrator that is sent the
+// events it subscribed to. Each payload is signed with the endpoint's
+// secret, which is shown once on creation.
+type Endpoint struct {
+	ID          string    `json:"id" db:"id"`
+	URL         string    `json:"url" db:"url"`
+	Description string    `json:"description,omitempty" db:"description"`
+	EventTypes  []string  `json:"event_types" db:"event_types"`
+	Secret      string    `json:"-" db:"secret"`
+	Active      bool      `json:"active" db:"active"`
+	CreatedBy   string    `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,startswith=https://,max=2048"`
+	Description string   `json:"description,omitempty" validate:"max=500"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,required"`
+}
+
+// UpdateEndpointRequest changes the given fields of an endpoint.
+type UpdateEndpointRequest struct {
+	URL         string   `json:"url,omitempty" validate:"omitempty,url,startswith=https://,max=2048"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=500"`
+	EventTypes  []string `json:"event_types,omitempty" validate:"omitempty,min=1,dive,required"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// CreatedEndpoint is returned once, when the endpoint is created.
+type CreatedEndpoint struct {
+	*Endpoint
+	Secret string `json:"secret"`
+}
+
+func (e *Endpoint) Subscribes(eventType string) bool {
+	return slices.Contains(e.EventTypes, eventType)
+}
+
+type DeliveryStatus string
+
+const (
+	// DeliveryStatusPending deliveries are attempted at NextAttemptAt.
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	// DeliveryStatusFailed deliveries ran out of attempts. They are only
+	// retried when redelivered.
+	DeliveryStatusFailed DeliveryStatus = "failed"
+)
+
+// Delivery is one event sent to one endpoint, retried with exponential
+// backoff until the endpoint responds with a 2xx status.
+type Delivery struct {
+	ID             string          `json:"id" db:"id"`
+	EndpointID     string          `json:"endpoint_id" db:"endpoint_id"`
+	EventID        string          `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         DeliveryStatus  `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	// Log of the attempts, returned with a single delivery only.
+	AttemptLog []*DeliveryAttempt `json:"attempt_log,omitempty" db:"-"`
+}
+
+// DeliveryAttempt records one request made for a delivery.
+type DeliveryAttempt struct {
+	ID          string    `json:"id" db:"id"`
+	DeliveryID  string    `json:"delivery_id" db:"delivery_id"`
+	StatusCode  int       `json:"status_code,omitempty" db:"status_code"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	DurationMS  int64     `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at" db:"attempted_at"`
+}
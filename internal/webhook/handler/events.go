@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/webhook/service"
+	"github.com/dmehra2102/booking-system/pkg/events"
+)
+
+// EventHandler queues webhook deliveries of the events consumed from
+// Kafka. Events are forwarded as encoded, so it works on raw messages
+// rather than through an events.Dispatcher.
+type EventHandler struct {
+	service *service.WebhookService
+	logger  *logger.Logger
+}
+
+func NewEventHandler(service *service.WebhookService, logger *logger.Logger) *EventHandler {
+	return &EventHandler{service: service, logger: logger}
+}
+
+// HandleEvent has the signature of a Kafka message handler. The payload of
+// each delivery is the event in its latest version.
+func (h *EventHandler) HandleEvent(ctx context.Context, key, value []byte, headers map[string]string) error {
+	var envelope struct {
+		ID   string           `json:"id"`
+		Type events.EventType `json:"type"`
+	}
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return fmt.Errorf("%w: %v", events.ErrInvalidEvent, err)
+	}
+
+	queued, err := h.service.Enqueue(ctx, string(envelope.Type), envelope.ID, value)
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		h.logger.WithContext(ctx).With("event_id", envelope.ID).With("queued", strconv.Itoa(queued)).Debug("queued webhook deliveries")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/webhook/domain"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service domain.WebhookService
+	logger  *logger.Logger
+}
+
+func NewWebhookHandler(service domain.WebhookService, logger *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{service: service, logger: logger}
+}
+
+// CreateEndpoint returns the new endpoint with its signing secret, which
+// cannot be retrieved again.
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	var req domain.CreateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	endpoint, err := h.service.CreateEndpoint(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.CreatedWithLocation(c, path.Join(c.Request.URL.Path, endpoint.ID), endpoint)
+}
+
+func (h *WebhookHandler) ListEndpoints(c *gin.Context) {
+	endpoints, err := h.service.ListEndpoints(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, endpoints)
+}
+
+func (h *WebhookHandler) GetEndpoint(c *gin.Context) {
+	endpoint, err := h.service.GetEndpoint(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, endpoint)
+}
+
+func (h *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	var req domain.UpdateEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	endpoint, err := h.service.UpdateEndpoint(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, endpoint)
+}
+
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	if err := h.service.DeleteEndpoint(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries lists the deliveries to an endpoint with page or cursor
+// pagination, optionally narrowed by ?status=.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	params, err := pagination.FromQuery(c, domain.DeliverySortFields, pagination.Sort{Field: "created_at", Desc: true})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	deliveries, result, err := h.service.ListDeliveries(c.Request.Context(), c.Param("id"), domain.DeliveryStatus(c.Query("status")), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, deliveries, pagination.Response(params, result))
+}
+
+// GetDelivery returns a delivery with the log of its attempts.
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	delivery, err := h.service.GetDelivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, delivery)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.service.Redeliver(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, delivery)
+}
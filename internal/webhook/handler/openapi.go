@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmehra2102/booking-system/internal/webhook/domain"
+	"github.com/dmehra2102/booking-system/pkg/openapi"
+)
+
+// Routes describes the webhook HTTP API for the OpenAPI document. The
+// routes are served by the booking service; keep them in sync with
+// setupRouter in cmd/booking.
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/v1/admin/webhooks", Summary: "Register a webhook endpoint", Tag: "webhooks", Auth: true, Admin: true,
+			Request: domain.CreateEndpointRequest{}, Response: domain.CreatedEndpoint{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/admin/webhooks", Summary: "List webhook endpoints", Tag: "webhooks", Auth: true, Admin: true,
+			Response: domain.Endpoint{}, List: true},
+		{Method: http.MethodGet, Path: "/api/v1/admin/webhooks/:id", Summary: "Get a webhook endpoint", Tag: "webhooks", Auth: true, Admin: true,
+			Response: domain.Endpoint{}},
+		{Method: http.MethodPut, Path: "/api/v1/admin/webhooks/:id", Summary: "Update a webhook endpoint", Tag: "webhooks", Auth: true, Admin: true,
+			Request: domain.UpdateEndpointRequest{}, Response: domain.Endpoint{}},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/webhooks/:id", Summary: "Delete a webhook endpoint", Tag: "webhooks", Auth: true, Admin: true,
+			Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/api/v1/admin/webhooks/:id/deliveries", Summary: "List the deliveries to a webhook endpoint", Tag: "webhooks", Auth: true, Admin: true,
+			Response: domain.Delivery{}, List: true, Query: []openapi.Param{
+				{Name: "status", Description: "Only deliveries in this status", Type: "string", Enum: []string{
+					string(domain.DeliveryStatusPending), string(domain.DeliveryStatusSucceeded), string(domain.DeliveryStatusFailed),
+				}},
+			}},
+		{Method: http.MethodGet, Path: "/api/v1/admin/webhook-deliveries/:id", Summary: "Get a webhook delivery with its attempts", Tag: "webhooks", Auth: true, Admin: true,
+			Response: domain.Delivery{}},
+		{Method: http.MethodPost, Path: "/api/v1/admin/webhook-deliveries/:id/redeliver", Summary: "Deliver a webhook again", Tag: "webhooks", Auth: true, Admin: true,
+			Response: domain.Delivery{}},
+	}
+}
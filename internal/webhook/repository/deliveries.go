@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/webhook/domain"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+const deliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_status_code, last_error, delivered_at, created_at`
+
+func scanDelivery(row rowScanner) (*domain.Delivery, error) {
+	d := &domain.Delivery{}
+	var payload []byte
+	var nextAttemptAt, deliveredAt sql.NullTime
+
+	err := row.Scan(
+		&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&nextAttemptAt, &d.LastStatusCode, &d.LastError, &deliveredAt, &d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Payload = payload
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]*domain.Delivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*domain.Delivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to scan webhook delivery", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to list webhook deliveries", err)
+	}
+
+	return deliveries, nil
+}
+
+// CreateDeliveries queues deliveries. An event is delivered to an endpoint
+// once, so deliveries of an event that was consumed again are skipped.
+func (r *PostgresWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*domain.Delivery) (err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.create_deliveries")
+	defer tracing.End(span, &err)
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return errors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, tenant_id, endpoint_id, event_id, event_type, payload, status, next_attempt_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+	`
+
+	now := time.Now().UTC()
+	for _, d := range deliveries {
+		d.ID = uuid.New().String()
+		d.CreatedAt = now
+
+		_, err := tx.ExecContext(ctx, query,
+			d.ID, tenancy.ID(ctx), d.EndpointID, d.EventID, d.EventType, []byte(d.Payload),
+			d.Status, d.NextAttemptAt, d.CreatedAt,
+		)
+		if err != nil {
+			return errors.NewInternalError("failed to create webhook delivery", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("failed to commit webhook deliveries", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresWebhookRepository) GetDelivery(ctx context.Context, id string) (_ *domain.Delivery, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.get_delivery")
+	defer tracing.End(span, &err)
+
+	d, err := scanDelivery(r.db.QueryRow(ctx, "webhook.get_delivery", `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1 AND tenant_id = $2`, id, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("webhook delivery")
+		}
+		return nil, errors.NewInternalError("failed to get webhook delivery", err)
+	}
+
+	return d, nil
+}
+
+// ListDeliveries returns a page of the deliveries to an endpoint, narrowed
+// to status unless it is empty.
+func (r *PostgresWebhookRepository) ListDeliveries(ctx context.Context, endpointID string, status domain.DeliveryStatus, params pagination.Params) (_ []*domain.Delivery, _ *pagination.Result, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.list_deliveries")
+	defer tracing.End(span, &err)
+
+	conditions := []string{"endpoint_id = $1", "tenant_id = $2"}
+	args := []any{endpointID, tenancy.ID(ctx)}
+	if status != "" {
+		args = append(args, status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	var total int64
+	countQuery := "SELECT COUNT(*) FROM webhook_deliveries WHERE " + strings.Join(conditions, " AND ")
+	if err := r.db.QueryRow(ctx, "webhook.count_deliveries", countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, errors.NewInternalError("failed to count webhook deliveries", err)
+	}
+
+	if after, afterArgs := params.After("created_at", "timestamptz", "id", len(args)+1); after != "" {
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_deliveries
+		WHERE %s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, deliveryColumns, strings.Join(conditions, " AND "), params.OrderBy("created_at", "id"), len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, "webhook.list_deliveries", query, append(args, params.PageSize+1, params.Offset())...)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to list webhook deliveries", err)
+	}
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &pagination.Result{Total: total}
+	if len(deliveries) > params.PageSize {
+		deliveries = deliveries[:params.PageSize]
+		last := deliveries[len(deliveries)-1]
+		result.NextCursor = params.Next(last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+
+	return deliveries, result, nil
+}
+
+// ListAttempts returns the attempts made for a delivery, oldest first.
+func (r *PostgresWebhookRepository) ListAttempts(ctx context.Context, deliveryID string) (_ []*domain.DeliveryAttempt, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.list_attempts")
+	defer tracing.End(span, &err)
+
+	query := `
+		SELECT a.id, a.delivery_id, a.status_code, a.error, a.duration_ms, a.attempted_at
+		FROM webhook_delivery_attempts a
+		JOIN webhook_deliveries d ON d.id = a.delivery_id
+		WHERE a.delivery_id = $1 AND d.tenant_id = $2
+		ORDER BY a.attempted_at ASC, a.id ASC
+	`
+
+	rows, err := r.db.Query(ctx, "webhook.list_attempts", query, deliveryID, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list webhook delivery attempts", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]*domain.DeliveryAttempt, 0)
+	for rows.Next() {
+		a := &domain.DeliveryAttempt{}
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.StatusCode, &a.Error, &a.DurationMS, &a.AttemptedAt); err != nil {
+			return nil, errors.NewInternalError("failed to scan webhook delivery attempt", err)
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to list webhook delivery attempts", err)
+	}
+
+	return attempts, nil
+}
+
+// ClaimDue returns up to limit pending deliveries due by now and pushes
+// their next attempt lease into the future, so a delivery interrupted by a
+// crash is picked up again once the lease ends.
+func (r *PostgresWebhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) (_ []*domain.Delivery, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.claim_due")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $3
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $1 AND tenant_id = $5 AND next_attempt_at <= $2
+			ORDER BY next_attempt_at ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	rows, err := r.db.Query(ctx, "webhook.claim_due", query, domain.DeliveryStatusPending, now, now.Add(lease), limit, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to claim webhook deliveries", err)
+	}
+	return scanDeliveries(rows)
+}
+
+// RecordAttempt logs an attempt and saves the resulting state of its
+// delivery.
+func (r *PostgresWebhookRepository) RecordAttempt(ctx context.Context, d *domain.Delivery, attempt *domain.DeliveryAttempt) (err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.record_attempt")
+	defer tracing.End(span, &err)
+
+	attempt.ID = uuid.New().String()
+	attempt.DeliveryID = d.ID
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return errors.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhook_delivery_attempts (id, delivery_id, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		attempt.ID, attempt.DeliveryID, attempt.StatusCode, attempt.Error, attempt.DurationMS, attempt.AttemptedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to record webhook delivery attempt", err)
+	}
+
+	query = `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1 AND tenant_id = $8
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, tenancy.ID(ctx),
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to update webhook delivery", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("failed to commit webhook delivery attempt", err)
+	}
+
+	return nil
+}
+
+// Redeliver queues a delivery that is not pending again, with a fresh set
+// of attempts, and returns it.
+func (r *PostgresWebhookRepository) Redeliver(ctx context.Context, id string, now time.Time) (_ *domain.Delivery, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.redeliver")
+	defer tracing.End(span, &err)
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = 0, next_attempt_at = $3
+		WHERE id = $1 AND tenant_id = $4 AND status <> $2
+		RETURNING ` + deliveryColumns
+
+	d, err := scanDelivery(r.db.QueryRow(ctx, "webhook.redeliver", query, id, domain.DeliveryStatusPending, now, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if _, err := r.GetDelivery(ctx, id); err != nil {
+				return nil, err
+			}
+			return nil, errors.NewConflictError("webhook delivery is already pending")
+		}
+		return nil, errors.NewInternalError("failed to redeliver webhook delivery", err)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/webhook/domain"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresWebhookRepository struct {
+	db     *database.PostgresDB
+	tracer trace.Tracer
+}
+
+func NewPostgresWebhookRepository(db *database.PostgresDB, tracer trace.Tracer) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{db: db, tracer: tracer}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+const selectEndpointQuery = `
+	SELECT id, url, description, event_types, secret, active, created_by, created_at, updated_at
+	FROM webhook_endpoints
+`
+
+func scanEndpoint(row rowScanner) (*domain.Endpoint, error) {
+	e := &domain.Endpoint{}
+	err := row.Scan(
+		&e.ID, &e.URL, &e.Description, database.Array(&e.EventTypes), &e.Secret,
+		&e.Active, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt,
+	)
+	return e, err
+}
+
+func (r *PostgresWebhookRepository) CreateEndpoint(ctx context.Context, e *domain.Endpoint) (err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.create_endpoint")
+	defer tracing.End(span, &err)
+
+	e.ID = uuid.New().String()
+	e.CreatedAt = time.Now().UTC()
+	e.UpdatedAt = e.CreatedAt
+
+	query := `
+		INSERT INTO webhook_endpoints (
+			id, tenant_id, url, description, event_types, secret, active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err = r.db.Exec(ctx, "webhook.create_endpoint", query,
+		e.ID, tenancy.ID(ctx), e.URL, e.Description, e.EventTypes, e.Secret,
+		e.Active, e.CreatedBy, e.CreatedAt, e.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewInternalError("failed to create webhook endpoint", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresWebhookRepository) GetEndpoint(ctx context.Context, id string) (_ *domain.Endpoint, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.get_endpoint")
+	defer tracing.End(span, &err)
+
+	e, err := scanEndpoint(r.db.QueryRow(ctx, "webhook.get_endpoint", selectEndpointQuery+` WHERE id = $1 AND tenant_id = $2`, id, tenancy.ID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("webhook endpoint")
+		}
+		return nil, errors.NewInternalError("failed to get webhook endpoint", err)
+	}
+
+	return e, nil
+}
+
+// ListEndpoints returns every endpoint, oldest first.
+func (r *PostgresWebhookRepository) ListEndpoints(ctx context.Context) (_ []*domain.Endpoint, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.list_endpoints")
+	defer tracing.End(span, &err)
+
+	rows, err := r.db.Query(ctx, "webhook.list_endpoints", selectEndpointQuery+` WHERE tenant_id = $1 ORDER BY created_at ASC, id ASC`, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list webhook endpoints", err)
+	}
+	return scanEndpoints(rows)
+}
+
+// ListSubscribed returns the active endpoints subscribed to eventType.
+func (r *PostgresWebhookRepository) ListSubscribed(ctx context.Context, eventType string) (_ []*domain.Endpoint, err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.list_subscribed")
+	defer tracing.End(span, &err)
+
+	rows, err := r.db.Query(ctx, "webhook.list_subscribed", selectEndpointQuery+` WHERE tenant_id = $2 AND active AND $1 = ANY (event_types)`, eventType, tenancy.ID(ctx))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list webhook endpoints", err)
+	}
+	return scanEndpoints(rows)
+}
+
+func scanEndpoints(rows *sql.Rows) ([]*domain.Endpoint, error) {
+	defer rows.Close()
+
+	endpoints := make([]*domain.Endpoint, 0)
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to scan webhook endpoint", err)
+		}
+		endpoints = append(endpoints, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError("failed to list webhook endpoints", err)
+	}
+
+	return endpoints, nil
+}
+
+// UpdateEndpoint saves the URL, description, subscriptions and state of an
+// endpoint.
+func (r *PostgresWebhookRepository) UpdateEndpoint(ctx context.Context, e *domain.Endpoint) (err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.update_endpoint")
+	defer tracing.End(span, &err)
+
+	e.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $2, description = $3, event_types = $4, active = $5, updated_at = $6
+		WHERE id = $1 AND tenant_id = $7
+	`
+
+	result, err := r.db.Exec(ctx, "webhook.update_endpoint", query, e.ID, e.URL, e.Description, e.EventTypes, e.Active, e.UpdatedAt, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to update webhook endpoint", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("webhook endpoint")
+	}
+
+	return nil
+}
+
+// DeleteEndpoint removes an endpoint together with its deliveries.
+func (r *PostgresWebhookRepository) DeleteEndpoint(ctx context.Context, id string) (err error) {
+	ctx, span := r.tracer.Start(ctx, "webhook.repository.delete_endpoint")
+	defer tracing.End(span, &err)
+
+	result, err := r.db.Exec(ctx, "webhook.delete_endpoint", `DELETE FROM webhook_endpoints WHERE id = $1 AND tenant_id = $2`, id, tenancy.ID(ctx))
+	if err != nil {
+		return errors.NewInternalError("failed to delete webhook endpoint", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.NewNotFoundError("webhook endpoint")
+	}
+
+	return nil
+}
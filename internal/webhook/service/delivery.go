@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/scheduler"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/webhook/domain"
+)
+
+const (
+	// deliverBatchSize bounds the deliveries claimed per DeliverDue call.
+	deliverBatchSize = 100
+	// deliverConcurrency bounds the requests in flight at once.
+	deliverConcurrency = 10
+	// maxResponseBody bounds how much of a response is read before the
+	// connection is reused.
+	maxResponseBody = 64 << 10
+)
+
+// Enqueue queues a delivery of an event for every active endpoint
+// subscribed to its type. It returns the number of deliveries queued.
+func (s *WebhookService) Enqueue(ctx context.Context, eventType, eventID string, payload []byte) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.enqueue")
+	defer tracing.End(span, &err)
+
+	endpoints, err := s.repo.ListSubscribed(ctx, eventType)
+	if err != nil || len(endpoints) == 0 {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	deliveries := make([]*domain.Delivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, &domain.Delivery{
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: &now,
+		})
+	}
+
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return 0, err
+	}
+
+	return len(deliveries), nil
+}
+
+// DeliverDue attempts a batch of the pending deliveries that are due and
+// returns the number attempted. Claimed deliveries are leased for longer
+// than a request may take, so another instance does not send them twice.
+func (s *WebhookService) DeliverDue(ctx context.Context) (_ int, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.deliver_due")
+	defer tracing.End(span, &err)
+
+	deliveries, err := s.repo.ClaimDue(ctx, time.Now().UTC(), s.lease, deliverBatchSize)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	endpoints := make(map[string]*domain.Endpoint)
+	for _, d := range deliveries {
+		if _, ok := endpoints[d.EndpointID]; ok {
+			continue
+		}
+		endpoint, err := s.repo.GetEndpoint(ctx, d.EndpointID)
+		if err != nil {
+			return 0, err
+		}
+		endpoints[d.EndpointID] = endpoint
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, deliverConcurrency)
+	for _, d := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := s.attempt(ctx, endpoints[d.EndpointID], d); err != nil {
+				s.logger.WithContext(ctx).With("delivery_id", d.ID).WithError(err).Error("failed to record webhook delivery attempt")
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and records the outcome. A delivery that
+// is not answered with a 2xx status is retried after the policy's backoff,
+// unless it ran out of attempts or its endpoint was deactivated.
+func (s *WebhookService) attempt(ctx context.Context, endpoint *domain.Endpoint, d *domain.Delivery) (err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.attempt")
+	defer tracing.End(span, &err)
+
+	now := time.Now().UTC()
+	attempt := &domain.DeliveryAttempt{AttemptedAt: now}
+
+	succeeded := false
+	if !endpoint.Active {
+		attempt.Error = "endpoint is inactive"
+	} else {
+		attempt.StatusCode, err = s.send(ctx, endpoint, d, now)
+		switch {
+		case err != nil:
+			attempt.Error = err.Error()
+		case attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+			succeeded = true
+		default:
+			attempt.Error = "unexpected status " + strconv.Itoa(attempt.StatusCode)
+		}
+	}
+	attempt.DurationMS = time.Since(now).Milliseconds()
+
+	d.Attempts++
+	d.LastStatusCode = attempt.StatusCode
+	d.LastError = attempt.Error
+	d.NextAttemptAt = nil
+
+	switch {
+	case succeeded:
+		d.Status = domain.DeliveryStatusSucceeded
+		d.DeliveredAt = &now
+	case !endpoint.Active || d.Attempts >= s.policy.MaxAttempts:
+		d.Status = domain.DeliveryStatusFailed
+		s.logger.WithContext(ctx).With("delivery_id", d.ID).With("endpoint_id", endpoint.ID).Warn("webhook delivery failed")
+	default:
+		next := now.Add(s.policy.Backoff(d.Attempts))
+		d.NextAttemptAt = &next
+	}
+
+	return s.repo.RecordAttempt(ctx, d, attempt)
+}
+
+// send posts the signed payload of a delivery to its endpoint and returns
+// the response status.
+func (s *WebhookService) send(ctx context.Context, endpoint *domain.Endpoint, d *domain.Delivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "booking-system-webhooks")
+	req.Header.Set(domain.HeaderDeliveryID, d.ID)
+	req.Header.Set(domain.HeaderEventType, d.EventType)
+	req.Header.Set(domain.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(domain.HeaderSignature, domain.Sign(endpoint.Secret, now, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	return resp.StatusCode, nil
+}
+
+// DeliveryJob returns the scheduled job that sends due webhook deliveries,
+// running every interval. A run keeps going while full batches are due.
+func DeliveryJob(s *WebhookService, logger *logger.Logger, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "webhook_deliveries",
+		Schedule: scheduler.Every(interval),
+		Run: func(ctx context.Context) error {
+			total := 0
+			for {
+				attempted, err := s.DeliverDue(ctx)
+				total += attempted
+				if err != nil || attempted < deliverBatchSize {
+					if total > 0 {
+						logger.WithContext(ctx).With("attempted", strconv.Itoa(total)).Info("attempted webhook deliveries")
+					}
+					return err
+				}
+			}
+		},
+	}
+}
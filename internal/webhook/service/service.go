@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/audit"
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/internal/common/httpclient"
+	"github.com/dmehra2102/booking-system/internal/common/logger"
+	"github.com/dmehra2102/booking-system/internal/common/retry"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"github.com/dmehra2102/booking-system/internal/webhook/domain"
+	"github.com/dmehra2102/booking-system/pkg/events"
+	"github.com/dmehra2102/booking-system/pkg/pagination"
+	"github.com/dmehra2102/booking-system/pkg/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, e *domain.Endpoint) error
+	GetEndpoint(ctx context.Context, id string) (*domain.Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]*domain.Endpoint, error)
+	ListSubscribed(ctx context.Context, eventType string) ([]*domain.Endpoint, error)
+	UpdateEndpoint(ctx context.Context, e *domain.Endpoint) error
+	DeleteEndpoint(ctx context.Context, id string) error
+
+	CreateDeliveries(ctx context.Context, deliveries []*domain.Delivery) error
+	GetDelivery(ctx context.Context, id string) (*domain.Delivery, error)
+	ListDeliveries(ctx context.Context, endpointID string, status domain.DeliveryStatus, params pagination.Params) ([]*domain.Delivery, *pagination.Result, error)
+	ListAttempts(ctx context.Context, deliveryID string) ([]*domain.DeliveryAttempt, error)
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.Delivery, error)
+	RecordAttempt(ctx context.Context, d *domain.Delivery, attempt *domain.DeliveryAttempt) error
+	Redeliver(ctx context.Context, id string, now time.Time) (*domain.Delivery, error)
+}
+
+const secretPrefix = "whsec_"
+
+var _ domain.WebhookService = (*WebhookService)(nil)
+
+// WebhookService manages the endpoints of external integrators and
+// delivers the events they subscribed to. Failed deliveries are retried
+// following policy: the delay after attempt n is policy.Backoff(n), and a
+// delivery fails for good after policy.MaxAttempts attempts.
+type WebhookService struct {
+	repo   WebhookRepository
+	client *httpclient.Client
+	lease  time.Duration
+	policy retry.Policy
+	logger *logger.Logger
+	tracer trace.Tracer
+}
+
+// NewWebhookService returns a WebhookService sending deliveries with
+// client, which should not follow redirects. Deliveries being sent are
+// leased for timeout plus a minute, so timeout must cover the longest an
+// endpoint may take to respond.
+func NewWebhookService(repo WebhookRepository, client *httpclient.Client, timeout time.Duration, policy retry.Policy, logger *logger.Logger, tracer trace.Tracer) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		client: client,
+		lease:  timeout + time.Minute,
+		policy: policy,
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+// CreateEndpoint registers an endpoint and generates its signing secret,
+// which is only returned here.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, createdBy string, req *domain.CreateEndpointRequest) (_ *domain.CreatedEndpoint, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.create_endpoint")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+	if err := validateEventTypes(req.EventTypes); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.NewInternalError("failed to generate webhook secret", err)
+	}
+
+	endpoint := &domain.Endpoint{
+		URL:         req.URL,
+		Description: req.Description,
+		EventTypes:  slices.Compact(slices.Sorted(slices.Values(req.EventTypes))),
+		Secret:      secretPrefix + hex.EncodeToString(raw),
+		Active:      true,
+		CreatedBy:   createdBy,
+	}
+
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "webhook_endpoint.create", "webhook_endpoint", endpoint.ID, nil, endpoint)
+	s.logger.WithContext(ctx).With("endpoint_id", endpoint.ID).Info("webhook endpoint created")
+
+	return &domain.CreatedEndpoint{Endpoint: endpoint, Secret: endpoint.Secret}, nil
+}
+
+func (s *WebhookService) ListEndpoints(ctx context.Context) (_ []*domain.Endpoint, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.list_endpoints")
+	defer tracing.End(span, &err)
+
+	return s.repo.ListEndpoints(ctx)
+}
+
+func (s *WebhookService) GetEndpoint(ctx context.Context, id string) (_ *domain.Endpoint, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.get_endpoint")
+	defer tracing.End(span, &err)
+
+	return s.repo.GetEndpoint(ctx, id)
+}
+
+// UpdateEndpoint changes the given fields of an endpoint. Deactivated
+// endpoints are not sent new events; their pending deliveries fail.
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, id string, req *domain.UpdateEndpointRequest) (_ *domain.Endpoint, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.update_endpoint")
+	defer tracing.End(span, &err)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		return nil, errors.NewValidationError("validation failed", err)
+	}
+
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *endpoint
+
+	if req.URL != "" {
+		endpoint.URL = req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if len(req.EventTypes) > 0 {
+		if err := validateEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+		endpoint.EventTypes = slices.Compact(slices.Sorted(slices.Values(req.EventTypes)))
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "webhook_endpoint.update", "webhook_endpoint", id, &before, endpoint)
+	s.logger.WithContext(ctx).With("endpoint_id", id).Info("webhook endpoint updated")
+
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes an endpoint together with its delivery log.
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.delete_endpoint")
+	defer tracing.End(span, &err)
+
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		return err
+	}
+
+	audit.Log(ctx, "webhook_endpoint.delete", "webhook_endpoint", id, nil, nil)
+	s.logger.WithContext(ctx).With("endpoint_id", id).Info("webhook endpoint deleted")
+
+	return nil
+}
+
+// ListDeliveries returns a page of the deliveries to an endpoint, newest
+// first by default.
+func (s *WebhookService) ListDeliveries(ctx context.Context, endpointID string, status domain.DeliveryStatus, params pagination.Params) (_ []*domain.Delivery, _ *pagination.Result, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.list_deliveries")
+	defer tracing.End(span, &err)
+
+	switch status {
+	case "", domain.DeliveryStatusPending, domain.DeliveryStatusSucceeded, domain.DeliveryStatusFailed:
+	default:
+		return nil, nil, errors.NewValidationError("status must be one of pending, succeeded, failed", nil)
+	}
+
+	if _, err := s.repo.GetEndpoint(ctx, endpointID); err != nil {
+		return nil, nil, err
+	}
+
+	params.Normalize()
+	return s.repo.ListDeliveries(ctx, endpointID, status, params)
+}
+
+// GetDelivery returns a delivery with the log of its attempts.
+func (s *WebhookService) GetDelivery(ctx context.Context, id string) (_ *domain.Delivery, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.get_delivery")
+	defer tracing.End(span, &err)
+
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.AttemptLog, err = s.repo.ListAttempts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Redeliver queues a succeeded or failed delivery again, right away and
+// with a fresh set of attempts.
+func (s *WebhookService) Redeliver(ctx context.Context, id string) (_ *domain.Delivery, err error) {
+	ctx, span := s.tracer.Start(ctx, "webhook.service.redeliver")
+	defer tracing.End(span, &err)
+
+	delivery, err := s.repo.Redeliver(ctx, id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "webhook_delivery.redeliver", "webhook_delivery", id, nil, delivery)
+	s.logger.WithContext(ctx).With("delivery_id", id).Info("webhook delivery queued again")
+
+	return delivery, nil
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(domain.EventTypes, events.EventType(eventType)) {
+			return errors.NewValidationError("unsupported event type "+eventType, nil)
+		}
+	}
+	return nil
+}
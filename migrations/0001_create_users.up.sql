@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            UUID PRIMARY KEY,
+    email         TEXT NOT NULL,
+    name          TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    role          TEXT NOT NULL DEFAULT 'user',
+    active        BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Soft-deleted users release their email address.
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_active_key ON users (email) WHERE active;
+CREATE INDEX IF NOT EXISTS users_created_at_idx ON users (created_at DESC);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS resources (
+    id             UUID PRIMARY KEY,
+    name           TEXT NOT NULL,
+    capacity       INTEGER NOT NULL DEFAULT 1 CHECK (capacity > 0),
+    buffer_minutes INTEGER NOT NULL DEFAULT 0 CHECK (buffer_minutes >= 0),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
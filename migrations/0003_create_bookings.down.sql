@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS booking_comments;
+DROP TABLE IF EXISTS bookings;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS bookings (
+    id             UUID PRIMARY KEY,
+    user_id        UUID NOT NULL,
+    resource_id    UUID NOT NULL,
+    start_time     TIMESTAMPTZ NOT NULL,
+    end_time       TIMESTAMPTZ NOT NULL,
+    status         TEXT NOT NULL,
+    amount         NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    currency       CHAR(3) NOT NULL DEFAULT 'USD',
+    payment_id     TEXT,
+    reservation_id TEXT,
+    notes          TEXT NOT NULL DEFAULT '',
+    metadata       TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS bookings_resource_window_idx ON bookings (resource_id, start_time, end_time);
+CREATE INDEX IF NOT EXISTS bookings_user_id_idx ON bookings (user_id);
+CREATE INDEX IF NOT EXISTS bookings_created_at_idx ON bookings (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS booking_comments (
+    id         UUID PRIMARY KEY,
+    booking_id UUID NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    author_id  UUID NOT NULL,
+    text       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS booking_comments_booking_idx ON booking_comments (booking_id, created_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS reservations (
+    id             UUID PRIMARY KEY,
+    resource_id    UUID NOT NULL,
+    booking_id     UUID NOT NULL,
+    start_time     TIMESTAMPTZ NOT NULL,
+    end_time       TIMESTAMPTZ NOT NULL,
+    status         TEXT NOT NULL,
+    release_reason TEXT NOT NULL DEFAULT '',
+    reserved_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    released_at    TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reservations_active_booking_key ON reservations (booking_id) WHERE status = 'reserved';
+CREATE INDEX IF NOT EXISTS reservations_resource_window_idx ON reservations (resource_id, start_time, end_time) WHERE status = 'reserved';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id             UUID PRIMARY KEY,
+    booking_id     UUID NOT NULL UNIQUE,
+    user_id        UUID NOT NULL,
+    amount         NUMERIC(12, 2) NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    status         TEXT NOT NULL,
+    provider       TEXT NOT NULL,
+    provider_ref   TEXT NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
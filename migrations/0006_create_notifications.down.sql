@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_recipients;
+DROP TABLE IF EXISTS notifications;
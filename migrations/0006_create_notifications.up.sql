@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id         UUID PRIMARY KEY,
+    event_id   TEXT NOT NULL,
+    user_id    UUID NOT NULL,
+    channel    TEXT NOT NULL,
+    template   TEXT NOT NULL,
+    recipient  TEXT NOT NULL,
+    subject    TEXT NOT NULL,
+    body       TEXT NOT NULL,
+    status     TEXT NOT NULL,
+    error      TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at    TIMESTAMPTZ,
+    UNIQUE (event_id, channel)
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS notification_recipients (
+    user_id UUID PRIMARY KEY,
+    email   TEXT NOT NULL,
+    name    TEXT NOT NULL
+);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS resources_active_name_idx;
+
+ALTER TABLE resources
+    DROP COLUMN IF EXISTS active,
+    DROP COLUMN IF EXISTS open_hours,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS price_per_hour,
+    DROP COLUMN IF EXISTS location,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS type;
@@ -0,0 +1,10 @@
+ALTER TABLE resources
+    ADD COLUMN IF NOT EXISTS type           TEXT NOT NULL DEFAULT 'other',
+    ADD COLUMN IF NOT EXISTS description    TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS location       TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS price_per_hour NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS currency       CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN IF NOT EXISTS open_hours     JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS active         BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX IF NOT EXISTS resources_active_name_idx ON resources (name) WHERE active;
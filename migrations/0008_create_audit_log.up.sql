@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id            UUID PRIMARY KEY,
+    service       TEXT NOT NULL,
+    actor_id      TEXT NOT NULL DEFAULT '',
+    actor_role    TEXT NOT NULL DEFAULT '',
+    action        TEXT NOT NULL,
+    resource_type TEXT NOT NULL DEFAULT '',
+    resource_id   TEXT NOT NULL DEFAULT '',
+    changes       JSONB,
+    request_id    TEXT NOT NULL DEFAULT '',
+    trace_id      TEXT NOT NULL DEFAULT '',
+    ip_address    TEXT NOT NULL DEFAULT '',
+    method        TEXT NOT NULL DEFAULT '',
+    path          TEXT NOT NULL DEFAULT '',
+    status_code   INTEGER NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_resource_idx ON audit_log (resource_type, resource_id, created_at DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS bookings_status_start_idx;
+DROP INDEX IF EXISTS bookings_user_start_idx;
+CREATE INDEX IF NOT EXISTS bookings_user_id_idx ON bookings (user_id);
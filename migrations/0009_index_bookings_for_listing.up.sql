@@ -0,0 +1,4 @@
+-- Serve user and status listings ordered or windowed by start_time.
+DROP INDEX IF EXISTS bookings_user_id_idx;
+CREATE INDEX IF NOT EXISTS bookings_user_start_idx ON bookings (user_id, start_time);
+CREATE INDEX IF NOT EXISTS bookings_status_start_idx ON bookings (status, start_time);
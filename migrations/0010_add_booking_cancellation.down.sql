@@ -0,0 +1,4 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancellation_fee,
+    DROP COLUMN IF EXISTS cancellation_reason;
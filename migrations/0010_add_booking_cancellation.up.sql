@@ -0,0 +1,4 @@
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS cancellation_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS cancellation_fee    NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cancelled_at        TIMESTAMPTZ;
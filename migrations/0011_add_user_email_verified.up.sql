@@ -0,0 +1,4 @@
+-- Accounts that predate verification are treated as verified; new rows
+-- start unverified.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS bookings_deleted_at_idx;
+ALTER TABLE bookings DROP COLUMN IF EXISTS deleted_at;
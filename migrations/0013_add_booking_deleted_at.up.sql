@@ -0,0 +1,3 @@
+-- Deleted bookings are kept until the retention job removes them.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS bookings_deleted_at_idx ON bookings (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS reservations_expires_at_idx;
+ALTER TABLE reservations DROP COLUMN IF EXISTS expires_at;
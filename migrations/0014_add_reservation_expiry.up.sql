@@ -0,0 +1,4 @@
+-- Reservations are held until expires_at pending payment; a NULL expiry
+-- holds the slot until the reservation is released.
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS reservations_expires_at_idx ON reservations (expires_at) WHERE status = 'reserved' AND expires_at IS NOT NULL;
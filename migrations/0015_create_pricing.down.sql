@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS promo_codes;
+DROP TABLE IF EXISTS rate_cards;
@@ -0,0 +1,22 @@
+-- A resource without a rate card is charged its price_per_hour.
+CREATE TABLE IF NOT EXISTS rate_cards (
+    resource_id UUID PRIMARY KEY REFERENCES resources (id) ON DELETE CASCADE,
+    currency    CHAR(3) NOT NULL DEFAULT 'USD',
+    hourly_rate NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (hourly_rate >= 0),
+    daily_rate  NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (daily_rate >= 0),
+    multipliers JSONB NOT NULL DEFAULT '[]',
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+    code            TEXT PRIMARY KEY,
+    percent_off     NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 100),
+    amount_off      NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (amount_off >= 0),
+    currency        TEXT NOT NULL DEFAULT '',
+    valid_from      TIMESTAMPTZ,
+    valid_to        TIMESTAMPTZ,
+    max_redemptions INTEGER NOT NULL DEFAULT 0 CHECK (max_redemptions >= 0),
+    redemptions     INTEGER NOT NULL DEFAULT 0,
+    active          BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
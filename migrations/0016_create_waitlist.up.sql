@@ -0,0 +1,20 @@
+-- Users queue for fully booked slots. An offered entry holds its slot
+-- until offer_expires_at.
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id               UUID PRIMARY KEY,
+    user_id          UUID NOT NULL,
+    resource_id      UUID NOT NULL,
+    start_time       TIMESTAMPTZ NOT NULL,
+    end_time         TIMESTAMPTZ NOT NULL,
+    status           TEXT NOT NULL,
+    offer_expires_at TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_time > start_time)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS waitlist_entries_active_idx ON waitlist_entries (user_id, resource_id, start_time, end_time)
+    WHERE status IN ('waiting', 'offered');
+CREATE INDEX IF NOT EXISTS waitlist_entries_resource_window_idx ON waitlist_entries (resource_id, start_time, end_time)
+    WHERE status IN ('waiting', 'offered');
+CREATE INDEX IF NOT EXISTS waitlist_entries_offer_expiry_idx ON waitlist_entries (offer_expires_at) WHERE status = 'offered';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS bookings_series_id_idx;
+ALTER TABLE bookings DROP COLUMN IF EXISTS series_id;
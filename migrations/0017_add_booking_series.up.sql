@@ -0,0 +1,3 @@
+-- Occurrences of a recurring booking share a series_id.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS series_id UUID;
+CREATE INDEX IF NOT EXISTS bookings_series_id_idx ON bookings (series_id, start_time) WHERE series_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS bookings_confirmed_start_idx;
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS checked_out_at,
+    DROP COLUMN IF EXISTS checked_in_at;
@@ -0,0 +1,7 @@
+-- Confirmed bookings are checked in (in_progress) and out (completed), or
+-- marked no_show when check-in never happens.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS checked_in_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS checked_out_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS bookings_confirmed_start_idx ON bookings (start_time) WHERE status = 'confirmed' AND deleted_at IS NULL;
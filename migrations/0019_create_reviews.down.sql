@@ -0,0 +1,6 @@
+ALTER TABLE resources
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating_average;
+
+DROP TABLE IF EXISTS resource_ratings;
+DROP TABLE IF EXISTS reviews;
@@ -0,0 +1,25 @@
+-- One review per completed booking.
+CREATE TABLE IF NOT EXISTS reviews (
+    id          UUID PRIMARY KEY,
+    booking_id  UUID NOT NULL UNIQUE REFERENCES bookings (id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL,
+    resource_id UUID NOT NULL,
+    rating      SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment     TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS reviews_resource_created_idx ON reviews (resource_id, created_at DESC);
+
+-- Running totals of the reviews of each resource, kept with every review.
+CREATE TABLE IF NOT EXISTS resource_ratings (
+    resource_id  UUID PRIMARY KEY,
+    rating_sum   INTEGER NOT NULL DEFAULT 0,
+    rating_count INTEGER NOT NULL DEFAULT 0,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The resource catalog's copy of the rating, fed by review.created.
+ALTER TABLE resources
+    ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_count   INTEGER NOT NULL DEFAULT 0;
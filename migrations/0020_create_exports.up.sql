@@ -0,0 +1,13 @@
+-- Exports written to files in the background. The files live in
+-- EXPORT_DIR; this table tracks who asked for them and how they went.
+CREATE TABLE IF NOT EXISTS exports (
+    id           UUID PRIMARY KEY,
+    user_id      UUID NOT NULL,
+    dataset      TEXT NOT NULL,
+    format       TEXT NOT NULL,
+    status       TEXT NOT NULL,
+    row_count    INTEGER NOT NULL DEFAULT 0,
+    error        TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
@@ -0,0 +1,4 @@
+-- Every update increments the version of the row, so a write based on an
+-- older version can be detected and rejected.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
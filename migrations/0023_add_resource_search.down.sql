@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS resources_search_idx;
+ALTER TABLE resources DROP COLUMN IF EXISTS search_vector;
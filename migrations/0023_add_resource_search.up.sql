@@ -0,0 +1,8 @@
+ALTER TABLE resources
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english'::regconfig, name), 'A') ||
+        setweight(to_tsvector('english'::regconfig, location), 'B') ||
+        setweight(to_tsvector('english'::regconfig, description), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS resources_search_idx ON resources USING GIN (search_vector) WHERE active;
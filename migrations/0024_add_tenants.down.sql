@@ -0,0 +1,25 @@
+DROP INDEX IF EXISTS users_email_active_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_active_key ON users (email) WHERE active;
+ALTER TABLE promo_codes DROP CONSTRAINT IF EXISTS promo_codes_pkey;
+ALTER TABLE promo_codes ADD PRIMARY KEY (code);
+
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE resources DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE bookings DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE booking_comments DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE reservations DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE payments DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE notification_recipients DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE rate_cards DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE promo_codes DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE waitlist_entries DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE reviews DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE resource_ratings DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE exports DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,71 @@
+-- Every business on a deployment is a tenant. Existing data belongs to
+-- the default tenant, whose admins manage the other tenants.
+CREATE TABLE IF NOT EXISTS tenants (
+    id         UUID PRIMARY KEY,
+    name       TEXT NOT NULL,
+    slug       TEXT NOT NULL UNIQUE,
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tenants (id, name, slug)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default')
+ON CONFLICT (id) DO NOTHING;
+
+-- The default only moves existing rows; new rows must name their tenant.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE booking_comments ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE reservations ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE notification_recipients ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE rate_cards ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE promo_codes ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE waitlist_entries ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE resource_ratings ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE exports ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE webhook_endpoints ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants (id);
+
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE resources ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE bookings ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE booking_comments ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE reservations ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE payments ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE notifications ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE notification_recipients ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE audit_log ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE rate_cards ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE promo_codes ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE waitlist_entries ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE reviews ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE resource_ratings ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE exports ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_endpoints ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_deliveries ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- Email addresses and promo codes are unique within a tenant.
+DROP INDEX IF EXISTS users_email_active_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_active_key ON users (tenant_id, email) WHERE active;
+ALTER TABLE promo_codes DROP CONSTRAINT IF EXISTS promo_codes_pkey;
+ALTER TABLE promo_codes ADD PRIMARY KEY (tenant_id, code);
+
+CREATE INDEX IF NOT EXISTS users_tenant_idx ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS resources_tenant_idx ON resources (tenant_id);
+CREATE INDEX IF NOT EXISTS bookings_tenant_idx ON bookings (tenant_id);
+CREATE INDEX IF NOT EXISTS payments_tenant_idx ON payments (tenant_id);
+CREATE INDEX IF NOT EXISTS notifications_tenant_idx ON notifications (tenant_id);
+CREATE INDEX IF NOT EXISTS audit_log_tenant_idx ON audit_log (tenant_id);
+CREATE INDEX IF NOT EXISTS api_keys_tenant_idx ON api_keys (tenant_id);
+CREATE INDEX IF NOT EXISTS waitlist_entries_tenant_idx ON waitlist_entries (tenant_id);
+CREATE INDEX IF NOT EXISTS reviews_tenant_idx ON reviews (tenant_id);
+CREATE INDEX IF NOT EXISTS exports_tenant_idx ON exports (tenant_id);
+CREATE INDEX IF NOT EXISTS webhook_endpoints_tenant_idx ON webhook_endpoints (tenant_id);
@@ -0,0 +1,9 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS reminded_at;
+DROP TABLE IF EXISTS notification_devices;
+ALTER TABLE notification_recipients
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS push_enabled,
+    DROP COLUMN IF EXISTS sms_enabled,
+    DROP COLUMN IF EXISTS email_enabled;
+DROP TABLE IF EXISTS user_notification_preferences;
+DROP TABLE IF EXISTS user_devices;
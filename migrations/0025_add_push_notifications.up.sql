@@ -0,0 +1,45 @@
+-- Devices users registered for push notifications and the channels they
+-- want to be notified over. A token identifies one app installation, so
+-- registering it again moves it to the registering user.
+CREATE TABLE IF NOT EXISTS user_devices (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants (id),
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token        TEXT NOT NULL UNIQUE,
+    platform     TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_devices_user_idx ON user_devices (user_id);
+
+CREATE TABLE IF NOT EXISTS user_notification_preferences (
+    user_id    UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    email      BOOLEAN NOT NULL DEFAULT TRUE,
+    sms        BOOLEAN NOT NULL DEFAULT FALSE,
+    push       BOOLEAN NOT NULL DEFAULT TRUE,
+    phone      TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The notification service's copy of the above, maintained from user
+-- events.
+ALTER TABLE notification_recipients
+    ADD COLUMN IF NOT EXISTS email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS sms_enabled   BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS push_enabled  BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS phone         TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS notification_devices (
+    token      TEXT PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    user_id    UUID NOT NULL,
+    platform   TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS notification_devices_user_idx ON notification_devices (user_id);
+
+-- Confirmed bookings are reminded once before they start.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ;
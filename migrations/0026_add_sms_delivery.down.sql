@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS notifications_provider_message_idx;
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS provider_message_id;
+DROP INDEX IF EXISTS notification_recipients_phone_idx;
+ALTER TABLE notification_recipients
+    DROP COLUMN IF EXISTS sms_opted_out,
+    DROP COLUMN IF EXISTS locale;
+ALTER TABLE user_notification_preferences DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,16 @@
+-- Text messages are written in the user's language and tracked until the
+-- carrier reports them delivered. Numbers that replied STOP are opted out
+-- of text messages until they reply START.
+ALTER TABLE user_notification_preferences ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE notification_recipients
+    ADD COLUMN IF NOT EXISTS locale        TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS sms_opted_out BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS notification_recipients_phone_idx ON notification_recipients (phone) WHERE phone <> '';
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS provider_message_id TEXT,
+    ADD COLUMN IF NOT EXISTS delivered_at        TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS notifications_provider_message_idx ON notifications (provider_message_id) WHERE provider_message_id IS NOT NULL;
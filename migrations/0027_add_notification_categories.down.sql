@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS notification_tenant_defaults;
+ALTER TABLE notification_recipients
+    DROP COLUMN IF EXISTS preferences_set,
+    DROP COLUMN IF EXISTS quiet_hours,
+    DROP COLUMN IF EXISTS categories;
+DROP TABLE IF EXISTS tenant_notification_defaults;
+ALTER TABLE user_notification_preferences
+    DROP COLUMN IF EXISTS quiet_hours,
+    DROP COLUMN IF EXISTS categories;
@@ -0,0 +1,36 @@
+-- Users choose the channels per notification category and quiet hours in
+-- which only email is sent. Admins set the preferences of the users of
+-- their tenant who did not set their own.
+ALTER TABLE user_notification_preferences
+    ADD COLUMN IF NOT EXISTS categories  JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS quiet_hours JSONB;
+
+CREATE TABLE IF NOT EXISTS tenant_notification_defaults (
+    tenant_id   UUID PRIMARY KEY REFERENCES tenants (id),
+    email       BOOLEAN NOT NULL DEFAULT TRUE,
+    sms         BOOLEAN NOT NULL DEFAULT FALSE,
+    push        BOOLEAN NOT NULL DEFAULT TRUE,
+    categories  JSONB NOT NULL DEFAULT '{}',
+    quiet_hours JSONB,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The notification service's copy of the above. Recipients without
+-- preferences of their own are notified by the defaults of their tenant.
+ALTER TABLE notification_recipients
+    ADD COLUMN IF NOT EXISTS categories      JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS quiet_hours     JSONB,
+    ADD COLUMN IF NOT EXISTS preferences_set BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE notification_recipients r SET preferences_set = TRUE
+WHERE EXISTS (SELECT 1 FROM user_notification_preferences p WHERE p.user_id = r.user_id);
+
+CREATE TABLE IF NOT EXISTS notification_tenant_defaults (
+    tenant_id     UUID PRIMARY KEY REFERENCES tenants (id),
+    email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sms_enabled   BOOLEAN NOT NULL DEFAULT FALSE,
+    push_enabled  BOOLEAN NOT NULL DEFAULT TRUE,
+    categories    JSONB NOT NULL DEFAULT '{}',
+    quiet_hours   JSONB,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,19 @@
+-- The calendars users connected to have their confirmed bookings pushed
+-- into, with the OAuth credentials granted for it. Each user connects at
+-- most one calendar.
+CREATE TABLE IF NOT EXISTS calendar_connections (
+    id               UUID PRIMARY KEY,
+    tenant_id        UUID NOT NULL REFERENCES tenants (id),
+    user_id          UUID NOT NULL,
+    provider         TEXT NOT NULL,
+    calendar_id      TEXT NOT NULL,
+    access_token     TEXT NOT NULL,
+    refresh_token    TEXT NOT NULL,
+    token_expires_at TIMESTAMPTZ NOT NULL,
+    enabled          BOOLEAN NOT NULL DEFAULT TRUE,
+    last_error       TEXT NOT NULL DEFAULT '',
+    last_synced_at   TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, user_id)
+);
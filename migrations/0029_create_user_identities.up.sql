@@ -0,0 +1,14 @@
+-- The accounts of external identity providers users log in with. An
+-- account is identified by the subject the provider assigned, which stays
+-- the same when its email address changes.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider   TEXT NOT NULL,
+    subject    TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, provider, subject),
+    UNIQUE (tenant_id, user_id, provider)
+);
@@ -0,0 +1,14 @@
+-- The TOTP enrollment of users. The secret is encrypted by the service,
+-- backup codes are stored as SHA-256 hashes and removed once used.
+-- last_used_step is the time step of the last accepted code, which cannot
+-- be used again.
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id        UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id      UUID NOT NULL REFERENCES tenants (id),
+    secret         TEXT NOT NULL,
+    enabled        BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_codes   TEXT[] NOT NULL DEFAULT '{}',
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    enabled_at     TIMESTAMPTZ
+);
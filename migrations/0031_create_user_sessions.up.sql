@@ -0,0 +1,16 @@
+-- The login sessions of users. A session is started by a login and kept
+-- alive by refreshing its tokens, which carry its ID; ending it removes
+-- the row and revokes the ID. ip_address and user_agent are those of the
+-- last login or refresh.
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants (id),
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    ip_address   TEXT NOT NULL DEFAULT '',
+    user_agent   TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS user_sessions_user_idx ON user_sessions (tenant_id, user_id, expires_at);
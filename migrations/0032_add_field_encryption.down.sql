@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS notification_recipients_phone_hash_idx;
+ALTER TABLE notification_recipients DROP COLUMN IF EXISTS phone_hash;
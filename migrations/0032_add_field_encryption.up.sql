@@ -0,0 +1,7 @@
+-- Phone numbers and the email addresses of notification recipients are
+-- encrypted by the services before they are stored. Encrypted numbers
+-- are looked up by their keyed hash; numbers stored before stay readable
+-- and are encrypted when they are next saved.
+ALTER TABLE notification_recipients ADD COLUMN IF NOT EXISTS phone_hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS notification_recipients_phone_hash_idx ON notification_recipients (phone_hash) WHERE phone_hash <> '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_erased_parts;
+DROP TABLE IF EXISTS user_erasures;
+DROP TABLE IF EXISTS user_data_export_parts;
+DROP TABLE IF EXISTS user_data_exports;
@@ -0,0 +1,42 @@
+-- Copies of the personal data of users, assembled from the parts the
+-- services holding it send. A new export replaces the earlier ones of the
+-- user, and exports are purged once they expire.
+CREATE TABLE IF NOT EXISTS user_data_exports (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants (id),
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS user_data_exports_user_idx ON user_data_exports (tenant_id, user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS user_data_exports_expiry_idx ON user_data_exports (expires_at) WHERE expires_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS user_data_export_parts (
+    export_id   UUID NOT NULL REFERENCES user_data_exports (id) ON DELETE CASCADE,
+    part        TEXT NOT NULL,
+    content     JSONB NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (export_id, part)
+);
+
+-- The erasure of deleted users. Every service holding personal data
+-- reports what it erased; once all did, the erasure is completed and its
+-- certificate written to the audit log.
+CREATE TABLE IF NOT EXISTS user_erasures (
+    user_id      UUID PRIMARY KEY REFERENCES users (id),
+    tenant_id    UUID NOT NULL REFERENCES tenants (id),
+    deleted_at   TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS user_erased_parts (
+    user_id   UUID NOT NULL REFERENCES user_erasures (user_id) ON DELETE CASCADE,
+    part      TEXT NOT NULL,
+    records   INTEGER NOT NULL DEFAULT 0,
+    retained  TEXT[] NOT NULL DEFAULT '{}',
+    erased_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, part)
+);
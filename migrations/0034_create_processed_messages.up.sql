@@ -0,0 +1,12 @@
+-- The events each consumer group handled, so redelivered messages are
+-- skipped. Rows are claims while not completed and expire with their
+-- lease; completed ones are purged once their ttl ran out.
+CREATE TABLE IF NOT EXISTS processed_messages (
+    consumer_group TEXT NOT NULL,
+    event_id       TEXT NOT NULL,
+    completed      BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (consumer_group, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS processed_messages_expiry_idx ON processed_messages (expires_at);
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL schema migrations shared by all
+// services. Files are named <version>_<name>.up.sql / .down.sql and are
+// applied in version order by database.Migrator.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+)
+
+const apiKeyPrefix = "bk_"
+
+// Scopes limit what an API key may do on top of its owner's role. Read
+// covers safe methods; write covers everything else.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// APIKeyPrincipal is the identity an API key authenticates as.
+type APIKeyPrincipal struct {
+	KeyID     string
+	TenantID  string
+	UserID    string
+	UserEmail string
+	UserRole  string
+	Scopes    []string
+}
+
+// Allows reports whether the key's scopes permit a request with method.
+func (p *APIKeyPrincipal) Allows(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return slices.Contains(p.Scopes, ScopeRead) || slices.Contains(p.Scopes, ScopeWrite)
+	}
+	return slices.Contains(p.Scopes, ScopeWrite)
+}
+
+// GenerateAPIKey returns a new random key and the short prefix used to
+// identify it in listings.
+func GenerateAPIKey() (key, prefix string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	key = apiKeyPrefix + hex.EncodeToString(raw)
+	return key, key[:len(apiKeyPrefix)+8], nil
+}
+
+// HashAPIKey returns the stored form of key. Keys carry 256 bits of
+// entropy, so an unsalted SHA-256 is sufficient and allows lookups.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
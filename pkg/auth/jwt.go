@@ -4,22 +4,72 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	TokenTypeAccess            = "access"
+	TokenTypeRefresh           = "refresh"
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypeTwoFactor         = "two_factor"
 )
 
 type Claims struct {
 	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	// TenantID is empty in tokens issued before tenants were introduced,
+	// which act for tenancy.DefaultID.
+	TenantID  string `json:"tenant_id,omitempty"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type,omitempty"`
+	// SessionID is the login session the access and refresh tokens belong
+	// to; revoking it revokes all of them. It is empty in other tokens and
+	// in tokens issued before sessions were tracked.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID, email, role, secret string, expiry time.Duration) (string, error) {
+// GenerateToken issues a short-lived access token for a session.
+func GenerateToken(userID, tenantID, email, role, sessionID, secret string, expiry time.Duration) (string, error) {
+	return generateSession(userID, tenantID, email, role, TokenTypeAccess, sessionID, secret, expiry)
+}
+
+// GenerateRefreshToken issues a long-lived token for a session that can
+// only be exchanged for a new token pair, never used to call the API
+// directly.
+func GenerateRefreshToken(userID, tenantID, email, role, sessionID, secret string, expiry time.Duration) (string, error) {
+	return generateSession(userID, tenantID, email, role, TokenTypeRefresh, sessionID, secret, expiry)
+}
+
+// GenerateEmailVerificationToken issues a token proving that its holder
+// received mail sent to email. It cannot be used to call the API.
+func GenerateEmailVerificationToken(userID, tenantID, email, secret string, expiry time.Duration) (string, error) {
+	return generate(userID, tenantID, email, "", TokenTypeEmailVerification, secret, expiry)
+}
+
+// GenerateTwoFactorToken issues a token proving that its holder passed
+// the first step of a login, to be exchanged for a token pair with a
+// second factor. It cannot be used to call the API.
+func GenerateTwoFactorToken(userID, tenantID, email, role, secret string, expiry time.Duration) (string, error) {
+	return generate(userID, tenantID, email, role, TokenTypeTwoFactor, secret, expiry)
+}
+
+func generate(userID, tenantID, email, role, tokenType, secret string, expiry time.Duration) (string, error) {
+	return generateSession(userID, tenantID, email, role, tokenType, "", secret, expiry)
+}
+
+func generateSession(userID, tenantID, email, role, tokenType, sessionID, secret string, expiry time.Duration) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		TenantID:  tenantID,
+		Email:     email,
+		Role:      role,
+		TokenType: tokenType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -29,13 +79,66 @@ func GenerateToken(userID, email, role, secret string, expiry time.Duration) (st
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return token.SignedString([]byte(secret))
 }
 
+// ValidateToken parses an access token. Refresh tokens are rejected.
 func ValidateToken(tokenString, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, Claims{}, func(t *jwt.Token) (any, error) {
+	claims, err := parse(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != "" && claims.TokenType != TokenTypeAccess {
+		return nil, fmt.Errorf("%s token cannot be used for authentication", claims.TokenType)
+	}
+
+	return claims, nil
+}
+
+func ValidateRefreshToken(tokenString, secret string) (*Claims, error) {
+	claims, err := parse(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, fmt.Errorf("not a refresh token")
+	}
+
+	return claims, nil
+}
+
+func ValidateEmailVerificationToken(tokenString, secret string) (*Claims, error) {
+	claims, err := parse(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeEmailVerification {
+		return nil, fmt.Errorf("not an email verification token")
+	}
+
+	return claims, nil
+}
+
+func ValidateTwoFactorToken(tokenString, secret string) (*Claims, error) {
+	claims, err := parse(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeTwoFactor {
+		return nil, fmt.Errorf("not a two-factor token")
+	}
+
+	return claims, nil
+}
+
+func parse(tokenString, secret string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpectd signing method: %v", t.Header["aig"])
+			return nil, fmt.Errorf("unexpectd signing method: %v", t.Header["alg"])
 		}
 		return []byte(secret), nil
 	})
@@ -50,3 +153,11 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
 
 	return nil, fmt.Errorf("invalid token")
 }
+
+// Tenant returns the tenant the token acts for.
+func (c *Claims) Tenant() string {
+	if c.TenantID == "" {
+		return tenancy.DefaultID
+	}
+	return c.TenantID
+}
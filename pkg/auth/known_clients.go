@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+)
+
+const knownClientKeyPrefix = "auth:known_client:"
+
+// KnownClientStore remembers the IP addresses and devices, told apart by
+// their user agent, users logged in from.
+type KnownClientStore interface {
+	// Remember records a login of userID and reports whether its IP
+	// address or device was not seen within ttl, and whether it is the
+	// first login of the user within ttl.
+	Remember(ctx context.Context, userID, ipAddress, userAgent string, ttl time.Duration) (isNew, isFirst bool, err error)
+}
+
+// RedisKnownClientStore keeps a key for each user, and each of their IP
+// addresses and devices, until it was not seen for ttl.
+type RedisKnownClientStore struct {
+	redis *database.RedisClient
+}
+
+func NewRedisKnownClientStore(redis *database.RedisClient) *RedisKnownClientStore {
+	return &RedisKnownClientStore{redis: redis}
+}
+
+func (s *RedisKnownClientStore) Remember(ctx context.Context, userID, ipAddress, userAgent string, ttl time.Duration) (bool, bool, error) {
+	userKey := knownClientKey(ctx, userID)
+	keys := []string{
+		knownClientKey(ctx, userID, "ip", ipAddress),
+		knownClientKey(ctx, userID, "device", userAgent),
+	}
+
+	seenUser, err := s.redis.Exists(ctx, userKey)
+	if err != nil {
+		return false, false, err
+	}
+	seen, err := s.redis.Exists(ctx, keys...)
+	if err != nil {
+		return false, false, err
+	}
+
+	for _, key := range append(keys, userKey) {
+		if err := s.redis.Set(ctx, key, 1, ttl); err != nil {
+			return false, false, err
+		}
+	}
+
+	return seen < int64(len(keys)), seenUser == 0, nil
+}
+
+// knownClientKey hashes the parts so a Redis dump does not list the
+// addresses users logged in from.
+func knownClientKey(ctx context.Context, userID string, parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(tenancy.ID(ctx) + ":" + userID))
+	for _, part := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(part))
+	}
+	return knownClientKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresKeyPrefix     = "auth:login_failures:"
+	loginLockedKeyPrefix       = "auth:login_locked:"
+	challengeFailuresKeyPrefix = "auth:two_factor_failures:"
+)
+
+// LoginAttemptStore counts the failed logins of accounts, identified by
+// the tenant of ctx and their email address, and locks them out. It also
+// counts the wrong codes entered for each two-factor token.
+type LoginAttemptStore interface {
+	Failures(ctx context.Context, email string) (int64, error)
+	RecordFailure(ctx context.Context, email string, window time.Duration) (int64, error)
+	RecordChallengeFailure(ctx context.Context, tokenID string, expiresAt time.Time) (int64, error)
+	Lock(ctx context.Context, email string, duration time.Duration) error
+	LockedUntil(ctx context.Context, email string) (time.Time, error)
+	Reset(ctx context.Context, email string) error
+}
+
+// RedisLoginAttemptStore keeps the failure count of an account until no
+// login failed for a window, and its lockout until it ends. Keys hash the
+// account so a Redis dump does not list email addresses.
+type RedisLoginAttemptStore struct {
+	redis *database.RedisClient
+}
+
+func NewRedisLoginAttemptStore(redis *database.RedisClient) *RedisLoginAttemptStore {
+	return &RedisLoginAttemptStore{redis: redis}
+}
+
+// Failures returns the failed logins of the account in the current window.
+func (s *RedisLoginAttemptStore) Failures(ctx context.Context, email string) (int64, error) {
+	value, err := s.redis.Get(ctx, loginFailuresKeyPrefix+accountKey(ctx, email))
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// RecordFailure counts a failed login and returns the failures in the
+// window, which restarts with each of them.
+func (s *RedisLoginAttemptStore) RecordFailure(ctx context.Context, email string, window time.Duration) (int64, error) {
+	return s.redis.IncrExpire(ctx, loginFailuresKeyPrefix+accountKey(ctx, email), window)
+}
+
+// RecordChallengeFailure counts a wrong code entered for the two-factor
+// token tokenID and returns the wrong codes so far. The count is kept
+// until the token expires.
+func (s *RedisLoginAttemptStore) RecordChallengeFailure(ctx context.Context, tokenID string, expiresAt time.Time) (int64, error) {
+	return s.redis.IncrExpire(ctx, challengeFailuresKeyPrefix+tokenID, time.Until(expiresAt))
+}
+
+func (s *RedisLoginAttemptStore) Lock(ctx context.Context, email string, duration time.Duration) error {
+	until := time.Now().Add(duration)
+	return s.redis.Set(ctx, loginLockedKeyPrefix+accountKey(ctx, email), until.Unix(), duration)
+}
+
+// LockedUntil returns the end of the lockout of the account, or the zero
+// time when it is not locked.
+func (s *RedisLoginAttemptStore) LockedUntil(ctx context.Context, email string) (time.Time, error) {
+	value, err := s.redis.Get(ctx, loginLockedKeyPrefix+accountKey(ctx, email))
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid lockout %q: %w", value, err)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
+// Reset clears the failures of the account after a successful login.
+func (s *RedisLoginAttemptStore) Reset(ctx context.Context, email string) error {
+	return s.redis.Delete(ctx, loginFailuresKeyPrefix+accountKey(ctx, email))
+}
+
+func accountKey(ctx context.Context, email string) string {
+	sum := sha256.Sum256([]byte(tenancy.ID(ctx) + ":" + strings.ToLower(email)))
+	return hex.EncodeToString(sum[:])
+}
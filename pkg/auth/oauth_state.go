@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/redis/go-redis/v9"
+)
+
+const oauthStateKeyPrefix = "auth:oauth_state:"
+
+// ErrInvalidOAuthState is returned for unknown, expired or already used
+// OAuth states.
+var ErrInvalidOAuthState = errors.New("invalid or expired oauth state")
+
+// OAuthStateStore issues the single-use state of an OAuth authorization,
+// which ties the callback to the login it started and carries the tenant
+// across the redirects. Each state comes with a browser secret that the
+// browser starting the login keeps in a cookie; the state is only accepted
+// back together with it, so a callback URL cannot be replayed in another
+// browser to log it into someone else's account.
+type OAuthStateStore interface {
+	Issue(ctx context.Context, provider string, ttl time.Duration) (state, browserSecret string, err error)
+	Consume(ctx context.Context, state, browserSecret string) (tenantID, provider string, err error)
+}
+
+// RedisOAuthStateStore keeps a hash of each state mapped to its tenant,
+// provider and the hash of its browser secret until the state is consumed
+// or expires.
+type RedisOAuthStateStore struct {
+	redis *database.RedisClient
+}
+
+func NewRedisOAuthStateStore(redis *database.RedisClient) *RedisOAuthStateStore {
+	return &RedisOAuthStateStore{redis: redis}
+}
+
+func (s *RedisOAuthStateStore) Issue(ctx context.Context, provider string, ttl time.Duration) (string, string, error) {
+	state, err := randomHex()
+	if err != nil {
+		return "", "", err
+	}
+	browserSecret, err := randomHex()
+	if err != nil {
+		return "", "", err
+	}
+
+	value := tenancy.ID(ctx) + ":" + provider + ":" + hashHex(browserSecret)
+	if err := s.redis.Set(ctx, stateKey(state), value, ttl); err != nil {
+		return "", "", err
+	}
+
+	return state, browserSecret, nil
+}
+
+// Consume returns the tenant and provider the state was issued for, and
+// invalidates it. A state presented without the browser secret it was
+// issued with is invalid, and used up all the same.
+func (s *RedisOAuthStateStore) Consume(ctx context.Context, state, browserSecret string) (string, string, error) {
+	value, err := s.redis.GetDel(ctx, stateKey(state))
+	if err == redis.Nil {
+		return "", "", ErrInvalidOAuthState
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[2]), []byte(hashHex(browserSecret))) != 1 {
+		return "", "", ErrInvalidOAuthState
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// randomHex returns 32 random bytes, hex encoded.
+func randomHex() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// stateKey hashes the state, as resetKey does tokens.
+func stateKey(state string) string {
+	return oauthStateKeyPrefix + hashHex(state)
+}
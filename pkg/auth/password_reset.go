@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/database"
+	"github.com/dmehra2102/booking-system/internal/common/tenancy"
+	"github.com/redis/go-redis/v9"
+)
+
+const passwordResetKeyPrefix = "auth:password_reset:"
+
+// ErrInvalidResetToken is returned for unknown, expired or already used
+// password reset tokens.
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// PasswordResetStore issues single-use password reset tokens.
+type PasswordResetStore interface {
+	Issue(ctx context.Context, userID string, ttl time.Duration) (string, error)
+	Consume(ctx context.Context, token string) (tenantID, userID string, err error)
+}
+
+// RedisPasswordResetStore keeps a hash of each token mapped to its user
+// and the user's tenant until the token is consumed or expires.
+type RedisPasswordResetStore struct {
+	redis *database.RedisClient
+}
+
+func NewRedisPasswordResetStore(redis *database.RedisClient) *RedisPasswordResetStore {
+	return &RedisPasswordResetStore{redis: redis}
+}
+
+func (s *RedisPasswordResetStore) Issue(ctx context.Context, userID string, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := s.redis.Set(ctx, resetKey(token), tenancy.ID(ctx)+":"+userID, ttl); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Consume returns the user the token was issued for and their tenant, and
+// invalidates it.
+func (s *RedisPasswordResetStore) Consume(ctx context.Context, token string) (string, string, error) {
+	value, err := s.redis.GetDel(ctx, resetKey(token))
+	if err == redis.Nil {
+		return "", "", ErrInvalidResetToken
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	// Tokens issued before tenants were introduced hold the user only
+	tenantID, userID, ok := strings.Cut(value, ":")
+	if !ok {
+		return tenancy.DefaultID, value, nil
+	}
+	return tenantID, userID, nil
+}
+
+// resetKey hashes the token so a Redis dump does not expose usable tokens.
+func resetKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return passwordResetKeyPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeRevocationStore has revoked no tokens and holds one cutoff for every
+// user; its other methods are not used.
+type fakeRevocationStore struct {
+	RevocationStore
+	before time.Time
+}
+
+func (fakeRevocationStore) IsRevoked(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+func (s fakeRevocationStore) RevokedBefore(context.Context, string) (time.Time, error) {
+	return s.before, nil
+}
+
+func TestIsTokenRevokedByUserCutoff(t *testing.T) {
+	cutoff := time.Unix(1_772_000_000, 0)
+
+	tests := []struct {
+		name     string
+		before   time.Time
+		issuedAt time.Time
+		want     bool
+	}{
+		{name: "no cutoff", issuedAt: cutoff, want: false},
+		{name: "issued before the cutoff", before: cutoff, issuedAt: cutoff.Add(-time.Second), want: true},
+		{name: "issued in the second of the cutoff", before: cutoff, issuedAt: cutoff, want: true},
+		{name: "issued after the cutoff", before: cutoff, issuedAt: cutoff.Add(time.Second), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{
+				UserID:           "u-1",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "t-1", IssuedAt: jwt.NewNumericDate(tt.issuedAt)},
+			}
+
+			revoked, err := IsTokenRevoked(context.Background(), fakeRevocationStore{before: tt.before}, claims)
+			if err != nil {
+				t.Fatalf("IsTokenRevoked() error = %v", err)
+			}
+			if revoked != tt.want {
+				t.Errorf("IsTokenRevoked() = %v, want %v", revoked, tt.want)
+			}
+		})
+	}
+}
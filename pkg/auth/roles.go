@@ -0,0 +1,8 @@
+package auth
+
+const (
+	RoleUser = "user"
+	// RoleStaff runs the front desk, checking guests in and out
+	RoleStaff = "staff"
+	RoleAdmin = "admin"
+)
@@ -0,0 +1,208 @@
+package crypto
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// prefix marks encrypted values, so values stored before a column was
+	// encrypted are still read as they are.
+	prefix    = "enc:v1:"
+	separator = ":"
+
+	// A data key encrypts values for dataKeyLifetime or dataKeyUses
+	// values, whichever ends first, keeping calls to the KMS rare and
+	// well below the number of random nonces a GCM key may use.
+	dataKeyLifetime = time.Hour
+	dataKeyUses     = 1 << 20
+
+	// maxCachedKeys bounds the unwrapped data keys kept for decrypting.
+	maxCachedKeys = 1024
+)
+
+// Envelope encrypts the values of sensitive columns with AES-256-GCM
+// under data keys wrapped by a KMS. Each value carries the ID of the
+// master key and its wrapped data key, so values encrypted before a key
+// rotation can still be decrypted:
+//
+//	enc:v1:<master key ID>:<wrapped data key>:<nonce and ciphertext>
+type Envelope struct {
+	kms KMS
+
+	mu      sync.Mutex
+	current *dataKey
+	keys    map[string]cipher.AEAD
+}
+
+type dataKey struct {
+	aead      cipher.AEAD
+	header    string
+	expiresAt time.Time
+	uses      int
+}
+
+func NewEnvelope(kms KMS) *Envelope {
+	return &Envelope{
+		kms:  kms,
+		keys: make(map[string]cipher.AEAD),
+	}
+}
+
+// Encrypt returns plaintext encrypted. The empty string stays empty, so
+// columns can still tell whether a value was set.
+func (e *Envelope) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get data key: %w", err)
+	}
+
+	sealed, err := seal(key.aead, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return key.header + separator + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of a value returned by Encrypt. Values
+// without the prefix of encrypted values are returned unchanged.
+func (e *Envelope) Decrypt(ctx context.Context, value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+
+	i := strings.LastIndex(value, separator)
+	header, encoded := value[:i], value[i+1:]
+
+	aead, err := e.unwrap(ctx, header)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	plaintext, err := open(aead, sealed)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// dataKey returns the data key to encrypt with, generating a new one when
+// the current one is used up.
+func (e *Envelope) dataKey(ctx context.Context) (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil && e.current.uses < dataKeyUses && time.Now().Before(e.current.expiresAt) {
+		e.current.uses++
+		return e.current, nil
+	}
+
+	generated, err := e.kms.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(generated.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	e.current = &dataKey{
+		aead:      aead,
+		header:    prefix + generated.KeyID + separator + base64.RawStdEncoding.EncodeToString(generated.Wrapped),
+		expiresAt: time.Now().Add(dataKeyLifetime),
+		uses:      1,
+	}
+	e.cache(e.current.header, aead)
+
+	return e.current, nil
+}
+
+// unwrap returns the data key of header, asking the KMS to unwrap it when
+// it is not cached.
+func (e *Envelope) unwrap(ctx context.Context, header string) (cipher.AEAD, error) {
+	e.mu.Lock()
+	aead, ok := e.keys[header]
+	e.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(header, prefix), separator)
+	if !ok {
+		return nil, errors.New("malformed encrypted value")
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted value: %w", err)
+	}
+
+	plaintext, err := e.kms.Decrypt(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	aead, err = newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.cache(header, aead)
+	e.mu.Unlock()
+
+	return aead, nil
+}
+
+// cache keeps an unwrapped data key, forgetting the others when there are
+// too many. The caller holds e.mu.
+func (e *Envelope) cache(header string, aead cipher.AEAD) {
+	if len(e.keys) >= maxCachedKeys {
+		clear(e.keys)
+	}
+	e.keys[header] = aead
+}
+
+// BlindIndex hashes the values of encrypted columns with HMAC-SHA256, so
+// rows can be looked up by value without decrypting every one. Its key
+// cannot rotate without rehashing the column, so it is kept apart from
+// the master keys.
+type BlindIndex struct {
+	key []byte
+}
+
+// NewBlindIndex returns a BlindIndex keyed by the SHA-256 of key, so any
+// passphrase can be configured.
+func NewBlindIndex(key string) *BlindIndex {
+	sum := sha256.Sum256([]byte(key))
+	return &BlindIndex{key: sum[:]}
+}
+
+// Hash returns the hex encoded hash of value, and the empty string for the
+// empty string. Values have to be normalized before, as equal values only
+// hash alike when they are byte for byte equal.
+func (b *BlindIndex) Hash(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
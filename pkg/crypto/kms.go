@@ -0,0 +1,124 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KMS holds the master keys that wrap the data keys fields are encrypted
+// with. Master keys never leave it, so rotating one only means wrapping
+// new data keys under another.
+type KMS interface {
+	// GenerateDataKey returns a new 256-bit data key, in plaintext and
+	// wrapped under the current master key.
+	GenerateDataKey(ctx context.Context) (*DataKey, error)
+	// Decrypt unwraps a data key wrapped under the master key keyID.
+	Decrypt(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// DataKey is a data key returned by KMS.GenerateDataKey.
+type DataKey struct {
+	// KeyID is the ID of the master key that wrapped the key
+	KeyID     string
+	Plaintext []byte
+	Wrapped   []byte
+}
+
+// ErrUnknownKey is returned when a value was encrypted under a master key
+// that was removed from the keyring.
+var ErrUnknownKey = errors.New("unknown master key")
+
+// LocalKMS keeps the master keys in process, for deployments without a
+// managed key service. Keys are given as "id:secret", the first one being
+// current; retired keys stay listed until no value uses them.
+type LocalKMS struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewLocalKMS returns a LocalKMS for keys. Each secret is stretched with
+// SHA-256, so any passphrase can be configured.
+func NewLocalKMS(keys []string) (*LocalKMS, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no master keys")
+	}
+
+	kms := &LocalKMS{keys: make(map[string]cipher.AEAD, len(keys))}
+	for _, key := range keys {
+		id, secret, ok := strings.Cut(key, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("master key %q is not id:secret", id)
+		}
+		if strings.Contains(id, separator) {
+			return nil, fmt.Errorf("master key ID %q contains %q", id, separator)
+		}
+		if _, ok := kms.keys[id]; ok {
+			return nil, fmt.Errorf("master key %q is listed twice", id)
+		}
+
+		sum := sha256.Sum256([]byte(secret))
+		aead, err := newAEAD(sum[:])
+		if err != nil {
+			return nil, err
+		}
+		kms.keys[id] = aead
+		if kms.current == "" {
+			kms.current = id
+		}
+	}
+
+	return kms, nil
+}
+
+func (k *LocalKMS) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	plaintext := make([]byte, 32)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, err
+	}
+
+	wrapped, err := seal(k.keys[k.current], plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataKey{KeyID: k.current, Plaintext: plaintext, Wrapped: wrapped}, nil
+}
+
+func (k *LocalKMS) Decrypt(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	return open(aead, wrapped)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce, which it prepends.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed value is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
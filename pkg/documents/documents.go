@@ -0,0 +1,44 @@
+// Package documents renders the documents handed out for a confirmed
+// booking: a PDF receipt and an iCalendar event to add it to a calendar.
+// Both are written by hand, like the XLSX exports, so no rendering
+// library is needed.
+package documents
+
+import (
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+const (
+	ContentTypePDF = "application/pdf"
+	ContentTypeICS = "text/calendar; charset=utf-8; method=PUBLISH"
+)
+
+// Confirmation is what the documents of a confirmed booking show. Names
+// are optional; documents fall back to the IDs when they are empty.
+type Confirmation struct {
+	BookingID    string
+	ResourceID   string
+	ResourceName string
+	UserName     string
+	UserEmail    string
+	StartTime    time.Time
+	EndTime      time.Time
+	Amount       money.Money
+	ConfirmedAt  time.Time
+}
+
+// resource names the booked resource.
+func (c *Confirmation) resource() string {
+	if c.ResourceName != "" {
+		return c.ResourceName
+	}
+	return c.ResourceID
+}
+
+// Filename returns the name of the confirmation document of bookingID with
+// extension, e.g. "booking-<id>.pdf".
+func Filename(bookingID, extension string) string {
+	return "booking-" + bookingID + "." + extension
+}
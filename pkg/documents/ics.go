@@ -0,0 +1,74 @@
+package documents
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// ICS renders the booking as an iCalendar (RFC 5545) event. The UID is
+// derived from the booking ID, so importing the file again updates the
+// event instead of adding a second one.
+func ICS(c *Confirmation) []byte {
+	stamp := c.ConfirmedAt
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	description := "Booking " + c.BookingID
+	if !c.Amount.IsZero() {
+		description += "\nAmount: " + c.Amount.String()
+	}
+
+	var b strings.Builder
+	line := func(name, value string) {
+		writeFolded(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//booking-system//booking confirmation//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("BEGIN", "VEVENT")
+	line("UID", c.BookingID+"@booking-system")
+	line("DTSTAMP", stamp.UTC().Format(icsTimeFormat))
+	line("DTSTART", c.StartTime.UTC().Format(icsTimeFormat))
+	line("DTEND", c.EndTime.UTC().Format(icsTimeFormat))
+	line("SUMMARY", escapeText("Booking: "+c.resource()))
+	line("DESCRIPTION", escapeText(description))
+	line("STATUS", "CONFIRMED")
+	line("TRANSP", "OPAQUE")
+	line("END", "VEVENT")
+	line("END", "VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// escapeText escapes the characters RFC 5545 reserves in TEXT values.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line, folded so that no line is longer than
+// 75 octets, without splitting a UTF-8 sequence.
+func writeFolded(b *strings.Builder, line string) {
+	const limit = 75
+
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with the space
+		width = limit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,122 @@
+package documents
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+)
+
+const (
+	pdfTimeFormat = "2006-01-02 15:04 UTC"
+	// pdfValueLength is about the number of characters that fit next to
+	// the labels on an A4 page; longer values are cut.
+	pdfValueLength = 60
+)
+
+// PDF renders the booking as a one page A4 receipt, labelled in the locale
+// of ctx. It uses the standard Helvetica fonts, which every reader has, so
+// characters outside Windows-1252 are shown as "?".
+func PDF(ctx context.Context, c *Confirmation) []byte {
+	guest := c.UserName
+	if c.UserEmail != "" {
+		if guest != "" {
+			guest += " <" + c.UserEmail + ">"
+		} else {
+			guest = c.UserEmail
+		}
+	}
+
+	rows := [][2]string{
+		{i18n.T(ctx, "Booking"), c.BookingID},
+		{i18n.T(ctx, "Resource"), c.resource()},
+		{i18n.T(ctx, "Guest"), guest},
+		{i18n.T(ctx, "Start"), c.StartTime.UTC().Format(pdfTimeFormat)},
+		{i18n.T(ctx, "End"), c.EndTime.UTC().Format(pdfTimeFormat)},
+		{i18n.T(ctx, "Amount"), c.Amount.String()},
+	}
+	if !c.ConfirmedAt.IsZero() {
+		rows = append(rows, [2]string{i18n.T(ctx, "Confirmed"), c.ConfirmedAt.UTC().Format(pdfTimeFormat)})
+	}
+
+	var content bytes.Buffer
+	pdfText(&content, "F2", 18, 56, 770, i18n.T(ctx, "Booking confirmation"))
+	y := 720
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		pdfText(&content, "F2", 11, 56, y, row[0])
+		pdfText(&content, "F1", 11, 180, y, cut(row[1], pdfValueLength))
+		y -= 22
+	}
+	pdfText(&content, "F1", 9, 56, y-20, i18n.T(ctx, "Please keep this receipt for your records."))
+	pdfText(&content, "F1", 8, 56, 40, fmt.Sprintf("%s %s", i18n.T(ctx, "Issued"), time.Now().UTC().Format(pdfTimeFormat)))
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()),
+	}
+
+	var b bytes.Buffer
+	// The comment of high bytes marks the file as binary for transfers
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.Bytes()
+}
+
+// pdfText writes a line of text at x, y in font and size.
+func pdfText(w *bytes.Buffer, font string, size, x, y int, text string) {
+	fmt.Fprintf(w, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// pdfString encodes s in Windows-1252, as the fonts are, and escapes it
+// for a literal string.
+func pdfString(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		var c byte
+		switch {
+		case r == '€':
+			c = 0x80
+		case r == '…':
+			c = 0x85
+		case r < 0x20, r >= 0x7f && r < 0xa0, r > 0xff:
+			c = '?'
+		default:
+			c = byte(r)
+		}
+		if c == '\\' || c == '(' || c == ')' {
+			out = append(out, '\\')
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// cut shortens s to at most n characters.
+func cut(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
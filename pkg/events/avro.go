@@ -0,0 +1,352 @@
+package events
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// avroNamespace is the namespace of the Avro records of events.
+const avroNamespace = "booking_system.events"
+
+// avroType is the subset of Avro the schemas of events are mapped to.
+// Objects become records with their properties in name order, integers
+// longs and numbers doubles. Values a Schema leaves untyped, such as
+// metadata, are carried as strings holding their JSON, marked with the
+// "encoding" attribute. Every field is nullable, as encoding/json writes
+// nil maps, slices and pointers as null.
+type avroType struct {
+	Type      string
+	Name      string
+	Namespace string
+	Encoding  string
+	Items     *avroType
+	Fields    []avroField
+}
+
+type avroField struct {
+	Name string
+	Type *avroType
+	// Optional fields may be left out of an event; they default to null
+	Optional bool
+}
+
+// avroRecordName returns the name of the record of an event version, such
+// as BookingConfirmed_v2_0. Each version is a record of its own, as new
+// versions change events incompatibly.
+func avroRecordName(eventType EventType, version string) string {
+	var name strings.Builder
+	for _, word := range strings.FieldsFunc(string(eventType), func(r rune) bool { return r == '.' || r == '_' }) {
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	name.WriteString("_v" + strings.ReplaceAll(version, ".", "_"))
+	return name.String()
+}
+
+// newAvroType maps schema to Avro, naming nested records after name.
+func newAvroType(name string, schema *Schema) *avroType {
+	switch schema.Type {
+	case "string":
+		return &avroType{Type: "string"}
+	case "boolean":
+		return &avroType{Type: "boolean"}
+	case "integer":
+		return &avroType{Type: "long"}
+	case "number":
+		return &avroType{Type: "double"}
+	case "array":
+		if schema.Items == nil {
+			break
+		}
+		return &avroType{Type: "array", Items: newAvroType(name+"_item", schema.Items)}
+	case "object":
+		if len(schema.Properties) == 0 {
+			break
+		}
+		record := &avroType{Type: "record", Name: name}
+		for _, prop := range slices.Sorted(maps.Keys(schema.Properties)) {
+			record.Fields = append(record.Fields, avroField{
+				Name:     prop,
+				Type:     newAvroType(name+"_"+prop, schema.Properties[prop]),
+				Optional: !slices.Contains(schema.Required, prop),
+			})
+		}
+		return record
+	}
+	return &avroType{Type: "string", Encoding: "json"}
+}
+
+// MarshalJSON writes t as an Avro schema.
+func (t *avroType) MarshalJSON() ([]byte, error) {
+	switch t.Type {
+	case "record":
+		fields := make([]map[string]any, len(t.Fields))
+		for i, field := range t.Fields {
+			fields[i] = map[string]any{"name": field.Name, "type": []any{"null", field.Type}}
+			if field.Optional {
+				fields[i]["default"] = nil
+			}
+		}
+		record := map[string]any{"type": "record", "name": t.Name, "fields": fields}
+		if t.Namespace != "" {
+			record["namespace"] = t.Namespace
+		}
+		return json.Marshal(record)
+	case "array":
+		return json.Marshal(map[string]any{"type": "array", "items": []any{"null", t.Items}})
+	}
+	if t.Encoding != "" {
+		return json.Marshal(map[string]any{"type": t.Type, "encoding": t.Encoding})
+	}
+	return json.Marshal(t.Type)
+}
+
+// parseAvroType reads an Avro schema written by MarshalJSON. Schemas
+// beyond that subset are refused.
+func parseAvroType(data []byte) (*avroType, error) {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		switch name {
+		case "string", "boolean", "long", "double":
+			return &avroType{Type: name}, nil
+		}
+		return nil, fmt.Errorf("unsupported avro type %q", name)
+	}
+
+	var union []json.RawMessage
+	if err := json.Unmarshal(data, &union); err == nil {
+		if len(union) != 2 || string(union[0]) != `"null"` {
+			return nil, errors.New("unsupported avro union")
+		}
+		return parseAvroType(union[1])
+	}
+
+	var schema struct {
+		Type      string                       `json:"type"`
+		Name      string                       `json:"name"`
+		Namespace string                       `json:"namespace"`
+		Encoding  string                       `json:"encoding"`
+		Items     json.RawMessage              `json:"items"`
+		Fields    []map[string]json.RawMessage `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+
+	t := &avroType{Type: schema.Type, Name: schema.Name, Namespace: schema.Namespace, Encoding: schema.Encoding}
+	switch schema.Type {
+	case "string", "boolean", "long", "double":
+	case "array":
+		items, err := parseAvroType(schema.Items)
+		if err != nil {
+			return nil, err
+		}
+		t.Items = items
+	case "record":
+		for _, field := range schema.Fields {
+			var name string
+			if err := json.Unmarshal(field["name"], &name); err != nil {
+				return nil, fmt.Errorf("invalid avro field: %w", err)
+			}
+			fieldType, err := parseAvroType(field["type"])
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+			// A default of null is what makes a field optional
+			_, optional := field["default"]
+			t.Fields = append(t.Fields, avroField{Name: name, Type: fieldType, Optional: optional})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported avro type %q", schema.Type)
+	}
+	return t, nil
+}
+
+// appendAvro appends the Avro binary encoding of a decoded JSON value,
+// with numbers decoded as json.Number.
+func (t *avroType) appendAvro(buf []byte, value any) ([]byte, error) {
+	if t.Encoding == "json" {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendAvroString(buf, string(encoded)), nil
+	}
+
+	switch t.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, errors.New("must be a string")
+		}
+		return appendAvroString(buf, s), nil
+	case "boolean":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, errors.New("must be a boolean")
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case "long":
+		n, ok := value.(json.Number)
+		if !ok {
+			return nil, errors.New("must be an integer")
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return binary.AppendVarint(buf, i), nil
+	case "double":
+		n, ok := value.(json.Number)
+		if !ok {
+			return nil, errors.New("must be a number")
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), nil
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return nil, errors.New("must be an array")
+		}
+		if len(items) > 0 {
+			buf = binary.AppendVarint(buf, int64(len(items)))
+			for i, item := range items {
+				var err error
+				if buf, err = t.Items.appendNullable(buf, item); err != nil {
+					return nil, fmt.Errorf("[%d]: %w", i, err)
+				}
+			}
+		}
+		return binary.AppendVarint(buf, 0), nil
+	case "record":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, errors.New("must be an object")
+		}
+		for _, field := range t.Fields {
+			var err error
+			if buf, err = field.Type.appendNullable(buf, object[field.Name]); err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Name, err)
+			}
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("unsupported avro type %q", t.Type)
+}
+
+// appendNullable appends value as a ["null", t] union.
+func (t *avroType) appendNullable(buf []byte, value any) ([]byte, error) {
+	if value == nil {
+		return binary.AppendVarint(buf, 0), nil
+	}
+	return t.appendAvro(binary.AppendVarint(buf, 1), value)
+}
+
+func appendAvroString(buf []byte, s string) []byte {
+	buf = binary.AppendVarint(buf, int64(len(s)))
+	return append(buf, s...)
+}
+
+// readAvro decodes a value written with t into its decoded JSON form.
+// Optional record fields that are null are left out, as they were when
+// the event was encoded.
+func (t *avroType) readAvro(r *bytes.Reader) (any, error) {
+	switch t.Type {
+	case "string":
+		n, err := binary.ReadVarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 || n > int64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		s := make([]byte, n)
+		if _, err := io.ReadFull(r, s); err != nil {
+			return nil, err
+		}
+		if t.Encoding == "json" {
+			var value any
+			if err := json.Unmarshal(s, &value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		}
+		return string(s), nil
+	case "boolean":
+		b, err := r.ReadByte()
+		return b == 1, err
+	case "long":
+		return binary.ReadVarint(r)
+	case "double":
+		var bits uint64
+		err := binary.Read(r, binary.LittleEndian, &bits)
+		return math.Float64frombits(bits), err
+	case "array":
+		items := []any{}
+		for {
+			count, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, err
+			}
+			if count == 0 {
+				return items, nil
+			}
+			// Negative counts are followed by the size of the block
+			if count < 0 {
+				count = -count
+				if _, err := binary.ReadVarint(r); err != nil {
+					return nil, err
+				}
+			}
+			for range count {
+				item, _, err := t.Items.readNullable(r)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+		}
+	case "record":
+		object := make(map[string]any, len(t.Fields))
+		for _, field := range t.Fields {
+			value, null, err := field.Type.readNullable(r)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Name, err)
+			}
+			if null && field.Optional {
+				continue
+			}
+			object[field.Name] = value
+		}
+		return object, nil
+	}
+	return nil, fmt.Errorf("unsupported avro type %q", t.Type)
+}
+
+func (t *avroType) readNullable(r *bytes.Reader) (any, bool, error) {
+	branch, err := binary.ReadVarint(r)
+	if err != nil {
+		return nil, false, err
+	}
+	switch branch {
+	case 0:
+		return nil, true, nil
+	case 1:
+		value, err := t.readAvro(r)
+		return value, false, err
+	}
+	return nil, false, fmt.Errorf("invalid union branch %d", branch)
+}
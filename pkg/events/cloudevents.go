@@ -0,0 +1,224 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// Format is how events are encoded in Kafka messages. Consumers decode
+// every format, so producers can switch formats without coordination.
+type Format string
+
+const (
+	// FormatNative encodes the event structs as they are.
+	FormatNative Format = "native"
+	// FormatCloudEventsStructured wraps events in a CloudEvents 1.0 JSON
+	// envelope, so the message value is self-describing.
+	FormatCloudEventsStructured Format = "cloudevents-structured"
+	// FormatCloudEventsBinary carries the CloudEvents attributes in ce_
+	// headers and the event data as the message value.
+	FormatCloudEventsBinary Format = "cloudevents-binary"
+)
+
+// ParseFormat returns the Format named s.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatNative, FormatCloudEventsStructured, FormatCloudEventsBinary:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown event format %q", s)
+}
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType is the content type of structured messages
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// ContentTypeHeader is the message header holding the content type,
+	// as named by the CloudEvents Kafka protocol binding
+	ContentTypeHeader = "content-type"
+
+	jsonContentType = "application/json"
+	// Binary messages carry each attribute in a header with this prefix
+	cloudEventsHeaderPrefix = "ce_"
+)
+
+// nativeEvent is the envelope of BaseEvent with its data kept encoded.
+type nativeEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Source    string          `json:"source"`
+	Timestamp string          `json:"timestamp,omitempty"`
+	Version   string          `json:"version,omitempty"`
+	TraceID   string          `json:"trace_id,omitempty"`
+	TenantID  string          `json:"tenant_id,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// cloudEvent is a structured CloudEvent. The fields of BaseEvent without
+// a CloudEvents counterpart are extension attributes; metadata, not
+// being a primitive, is carried as its JSON encoding.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	EventVersion    string          `json:"eventversion,omitempty"`
+	TraceID         string          `json:"traceid,omitempty"`
+	TenantID        string          `json:"tenantid,omitempty"`
+	Metadata        string          `json:"metadata,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// EncodeMessage encodes an event in format. It returns the message value
+// and the headers to send with it, including its content type.
+func EncodeMessage(format Format, data []byte) ([]byte, map[string]string, error) {
+	if format == "" || format == FormatNative {
+		return data, map[string]string{ContentTypeHeader: jsonContentType}, nil
+	}
+
+	var native nativeEvent
+	if err := json.Unmarshal(data, &native); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	event := toCloudEvent(native)
+
+	switch format {
+	case FormatCloudEventsStructured:
+		value, err := json.Marshal(event)
+		if err != nil {
+			return nil, nil, err
+		}
+		return value, map[string]string{ContentTypeHeader: CloudEventsContentType}, nil
+	case FormatCloudEventsBinary:
+		headers := map[string]string{ContentTypeHeader: event.DataContentType}
+		for name, value := range cloudEventAttributes(event) {
+			headers[cloudEventsHeaderPrefix+name] = value
+		}
+		return event.Data, headers, nil
+	}
+	return nil, nil, fmt.Errorf("unknown event format %q", format)
+}
+
+// DecodeMessage returns the native encoding of the event in a message of
+// any Format, telling them apart by their headers.
+func DecodeMessage(value []byte, headers map[string]string) ([]byte, error) {
+	if _, binary := headers[cloudEventsHeaderPrefix+"specversion"]; binary {
+		attributes := make(map[string]string)
+		for name, value := range headers {
+			if attribute, ok := strings.CutPrefix(name, cloudEventsHeaderPrefix); ok {
+				attributes[attribute] = value
+			}
+		}
+
+		event := fromCloudEventAttributes(attributes)
+		event.DataContentType = headers[ContentTypeHeader]
+		event.Data = value
+		return fromCloudEvent(event)
+	}
+
+	if strings.HasPrefix(headers[ContentTypeHeader], CloudEventsContentType) {
+		var event cloudEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+		}
+		return fromCloudEvent(event)
+	}
+
+	return value, nil
+}
+
+func toCloudEvent(native nativeEvent) cloudEvent {
+	event := cloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              native.ID,
+		Source:          native.Source,
+		Type:            native.Type,
+		Time:            native.Timestamp,
+		DataContentType: jsonContentType,
+		EventVersion:    native.Version,
+		TraceID:         native.TraceID,
+		TenantID:        native.TenantID,
+		Data:            native.Data,
+	}
+	if metadata := string(native.Metadata); metadata != "" && metadata != "{}" && metadata != "null" {
+		event.Metadata = metadata
+	}
+	return event
+}
+
+// fromCloudEvent returns the native encoding of event. Events of other
+// systems carry no eventversion and are taken to be at the latest version
+// of their type.
+func fromCloudEvent(event cloudEvent) ([]byte, error) {
+	if event.SpecVersion != CloudEventsSpecVersion {
+		return nil, fmt.Errorf("%w: unsupported CloudEvents version %q", ErrInvalidEvent, event.SpecVersion)
+	}
+	if event.ID == "" || event.Source == "" || event.Type == "" {
+		return nil, fmt.Errorf("%w: CloudEvent without id, source or type", ErrInvalidEvent)
+	}
+	if contentType := event.DataContentType; contentType != "" && contentType != jsonContentType && !strings.HasSuffix(contentType, "+json") {
+		return nil, fmt.Errorf("%w: unsupported data content type %q", ErrInvalidEvent, contentType)
+	}
+
+	native := nativeEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		Source:    event.Source,
+		Timestamp: event.Time,
+		Version:   event.EventVersion,
+		TraceID:   event.TraceID,
+		TenantID:  event.TenantID,
+		Data:      event.Data,
+	}
+	if native.Version == "" {
+		native.Version = DefaultRegistry.Latest(EventType(event.Type))
+	}
+	if event.Metadata != "" {
+		native.Metadata = json.RawMessage(event.Metadata)
+		if !json.Valid(native.Metadata) {
+			return nil, fmt.Errorf("%w: metadata is not JSON", ErrInvalidEvent)
+		}
+	}
+
+	return json.Marshal(native)
+}
+
+// cloudEventAttributes returns the attributes of event other than its
+// data and data content type, by name.
+func cloudEventAttributes(event cloudEvent) map[string]string {
+	attributes := map[string]string{
+		"specversion":  event.SpecVersion,
+		"id":           event.ID,
+		"source":       event.Source,
+		"type":         event.Type,
+		"time":         event.Time,
+		"eventversion": event.EventVersion,
+		"traceid":      event.TraceID,
+		"tenantid":     event.TenantID,
+		"metadata":     event.Metadata,
+	}
+	maps.DeleteFunc(attributes, func(_, value string) bool { return value == "" })
+	return attributes
+}
+
+// fromCloudEventAttributes is the inverse of cloudEventAttributes.
+// Extensions of other systems are dropped.
+func fromCloudEventAttributes(attributes map[string]string) cloudEvent {
+	return cloudEvent{
+		SpecVersion:  attributes["specversion"],
+		ID:           attributes["id"],
+		Source:       attributes["source"],
+		Type:         attributes["type"],
+		Time:         attributes["time"],
+		EventVersion: attributes["eventversion"],
+		TraceID:      attributes["traceid"],
+		TenantID:     attributes["tenantid"],
+		Metadata:     attributes["metadata"],
+	}
+}
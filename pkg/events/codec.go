@@ -0,0 +1,211 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Codecs events can be encoded with in Kafka messages.
+const (
+	CodecJSON = "json"
+	CodecAvro = "avro"
+)
+
+// AvroContentType is the content type of messages encoded by AvroCodec.
+const AvroContentType = "application/avro"
+
+// ParseCodec checks that s names a codec.
+func ParseCodec(s string) (string, error) {
+	switch s {
+	case CodecJSON, CodecAvro:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown event codec %q", s)
+}
+
+// Codec converts the native JSON encoding of events to the bytes written
+// to a topic and back.
+type Codec interface {
+	Encode(ctx context.Context, topic string, data []byte) ([]byte, error)
+	Decode(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// SchemaRegistry stores the schemas of the events written with a Codec,
+// as a Confluent Schema Registry does.
+type SchemaRegistry interface {
+	Register(ctx context.Context, subject, schemaType, schema string) (int, error)
+	CheckCompatibility(ctx context.Context, subject, schemaType, schema string) (bool, error)
+	Schema(ctx context.Context, id int) (schemaType, schema string, err error)
+}
+
+// ErrIncompatibleSchema is returned when the registry refuses the schema
+// of an event as incompatible with the schemas registered before.
+var ErrIncompatibleSchema = errors.New("event schema is incompatible with its registered versions")
+
+// wireMagic starts the messages of the Confluent wire format, followed by
+// the schema ID as a big endian uint32 and the encoded value. JSON never
+// starts with it, so both encodings can share a topic.
+const wireMagic = 0
+
+// AvroCodec encodes events with Avro in the Confluent wire format, so
+// they can be read by any client of the schema registry. The schema of
+// each event version is registered under the subject "<topic>-<record>"
+// when it is first written; consumers fetch schemas by the ID in each
+// message, so they decode messages written by newer producers too.
+//
+// Decode passes JSON through, so consumers read topics while producers
+// switch codecs.
+type AvroCodec struct {
+	registry SchemaRegistry
+	events   *Registry
+
+	mu      sync.RWMutex
+	written map[string]writerSchema
+	read    map[int]*avroType
+}
+
+// writerSchema is the registered schema events of a subject are written
+// with.
+type writerSchema struct {
+	id   int
+	avro *avroType
+}
+
+// NewAvroCodec returns a codec registering the schemas events has for
+// their event types in registry.
+func NewAvroCodec(registry SchemaRegistry, events *Registry) *AvroCodec {
+	return &AvroCodec{
+		registry: registry,
+		events:   events,
+		written:  make(map[string]writerSchema),
+		read:     make(map[int]*avroType),
+	}
+}
+
+// Encode writes an event with the schema of its version. Events of types
+// without a schema are left as JSON.
+func (c *AvroCodec) Encode(ctx context.Context, topic string, data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var event map[string]any
+	if err := decoder.Decode(&event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	eventType, _ := event["type"].(string)
+	version, _ := event["version"].(string)
+	schema, ok := c.events.Lookup(EventType(eventType), version)
+	if !ok {
+		return data, nil
+	}
+
+	writer, err := c.writerSchema(ctx, topic, EventType(eventType), version, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := []byte{wireMagic}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(writer.id))
+	buf, err = writer.avro.appendAvro(buf, event)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidEvent, eventType, err)
+	}
+	return buf, nil
+}
+
+// writerSchema returns the schema of an event version on topic,
+// registering it when it is first written.
+func (c *AvroCodec) writerSchema(ctx context.Context, topic string, eventType EventType, version string, schema *Schema) (writerSchema, error) {
+	name := avroRecordName(eventType, version)
+	subject := topic + "-" + avroNamespace + "." + name
+
+	c.mu.RLock()
+	writer, ok := c.written[subject]
+	c.mu.RUnlock()
+	if ok {
+		return writer, nil
+	}
+
+	avro := newAvroType(name, schema)
+	avro.Namespace = avroNamespace
+	encoded, err := json.Marshal(avro)
+	if err != nil {
+		return writerSchema{}, err
+	}
+
+	compatible, err := c.registry.CheckCompatibility(ctx, subject, "AVRO", string(encoded))
+	if err != nil {
+		return writerSchema{}, fmt.Errorf("failed to check schema of %s: %w", subject, err)
+	}
+	if !compatible {
+		return writerSchema{}, fmt.Errorf("%w: %s", ErrIncompatibleSchema, subject)
+	}
+
+	id, err := c.registry.Register(ctx, subject, "AVRO", string(encoded))
+	if err != nil {
+		return writerSchema{}, fmt.Errorf("failed to register schema of %s: %w", subject, err)
+	}
+
+	writer = writerSchema{id: id, avro: avro}
+	c.mu.Lock()
+	c.written[subject] = writer
+	c.read[id] = avro
+	c.mu.Unlock()
+
+	return writer, nil
+}
+
+// Decode returns the native JSON encoding of an event written by Encode,
+// fetching its schema from the registry the first time it is seen.
+func (c *AvroCodec) Decode(ctx context.Context, data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != wireMagic {
+		return data, nil
+	}
+	if len(data) < 5 {
+		return nil, fmt.Errorf("%w: truncated avro message", ErrInvalidEvent)
+	}
+
+	id := int(binary.BigEndian.Uint32(data[1:5]))
+	avro, err := c.readerSchema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := avro.readAvro(bytes.NewReader(data[5:]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode avro message with schema %d: %v", ErrInvalidEvent, id, err)
+	}
+	return json.Marshal(value)
+}
+
+func (c *AvroCodec) readerSchema(ctx context.Context, id int) (*avroType, error) {
+	c.mu.RLock()
+	avro, ok := c.read[id]
+	c.mu.RUnlock()
+	if ok {
+		return avro, nil
+	}
+
+	schemaType, schema, err := c.registry.Schema(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+	if schemaType != "AVRO" {
+		return nil, fmt.Errorf("%w: schema %d is %s, not AVRO", ErrInvalidEvent, id, schemaType)
+	}
+	if avro, err = parseAvroType([]byte(schema)); err != nil {
+		return nil, fmt.Errorf("%w: schema %d: %v", ErrInvalidEvent, id, err)
+	}
+
+	c.mu.Lock()
+	c.read[id] = avro
+	c.mu.Unlock()
+
+	return avro, nil
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dmehra2102/booking-system/internal/common/metrics"
+	"github.com/dmehra2102/booking-system/internal/common/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Handler processes a consumed event decoded into its struct type.
+type Handler[E any] func(ctx context.Context, event E) error
+
+// Dispatcher routes encoded events to typed handlers by their type.
+//
+//	dispatcher := events.NewDispatcher(m, tracer)
+//	events.On(dispatcher, events.BookingRequested, h.HandleBookingRequested)
+//
+// Its Handle method has the signature of a Kafka message handler.
+type Dispatcher struct {
+	metrics  *metrics.Metrics
+	tracer   trace.Tracer
+	handlers map[EventType]func(ctx context.Context, value []byte) error
+}
+
+func NewDispatcher(metrics *metrics.Metrics, tracer trace.Tracer) *Dispatcher {
+	return &Dispatcher{
+		metrics:  metrics,
+		tracer:   tracer,
+		handlers: make(map[EventType]func(ctx context.Context, value []byte) error),
+	}
+}
+
+// On registers the handler of eventType, replacing any previous one. E is
+// the event struct eventType is encoded from, e.g. BookingRequestedEvent.
+func On[E any](d *Dispatcher, eventType EventType, handler Handler[E]) {
+	d.handlers[eventType] = func(ctx context.Context, value []byte) error {
+		var event E
+		if err := json.Unmarshal(value, &event); err != nil {
+			return fmt.Errorf("%w: decoding %s: %v", ErrInvalidEvent, eventType, err)
+		}
+		return handler(ctx, event)
+	}
+}
+
+// EventTypes returns the event types with a handler, in a stable order.
+func (d *Dispatcher) EventTypes() []EventType {
+	types := make([]EventType, 0, len(d.handlers))
+	for eventType := range d.handlers {
+		types = append(types, eventType)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Handle decodes an event and runs its handler in a span of its own.
+func (d *Dispatcher) Handle(ctx context.Context, key, value []byte, headers map[string]string) error {
+	var envelope struct {
+		Type EventType `json:"type"`
+	}
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	handler, ok := d.handlers[envelope.Type]
+	if !ok {
+		return fmt.Errorf("%w: no handler for %q", ErrInvalidEvent, envelope.Type)
+	}
+
+	ctx, span := d.tracer.Start(ctx, fmt.Sprintf("events.handle.%s", envelope.Type))
+	defer span.End()
+
+	start := time.Now()
+	err := handler(ctx, value)
+	d.metrics.EventHandlingDuration.WithLabelValues(string(envelope.Type)).Observe(time.Since(start).Seconds())
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+		tracing.RecordError(span, err)
+	}
+	d.metrics.EventsHandled.WithLabelValues(string(envelope.Type), outcome).Inc()
+
+	return err
+}
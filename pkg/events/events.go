@@ -1,8 +1,10 @@
 package events
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/dmehra2102/booking-system/pkg/money"
 	"github.com/google/uuid"
 )
 
@@ -13,15 +15,47 @@ const (
 	UserUpdated EventType = "user.updated"
 	UserDeleted EventType = "user.deleted"
 
+	UserVerificationRequested  EventType = "user.verification_requested"
+	UserPasswordResetRequested EventType = "user.password_reset_requested"
+	UserNewDeviceLogin         EventType = "user.new_device_login"
+
+	UserDeviceRegistered               EventType = "user.device_registered"
+	UserDeviceRemoved                  EventType = "user.device_removed"
+	UserNotificationPreferencesUpdated EventType = "user.notification_preferences_updated"
+	UserNotificationDefaultsUpdated    EventType = "user.notification_defaults_updated"
+
+	UserDataExportRequested EventType = "user.data_export_requested"
+	UserDataExportPart      EventType = "user.data_export_part"
+	UserDataExportReady     EventType = "user.data_export_ready"
+	UserDataErased          EventType = "user.data_erased"
+
+	ResourceCreated EventType = "resource.created"
+	ResourceUpdated EventType = "resource.updated"
+	ResourceDeleted EventType = "resource.deleted"
+
 	BookingRequested EventType = "booking.requested"
 	BookingConfirmed EventType = "booking.confirmed"
 	BookingCancelled EventType = "booking.cancelled"
 	BookingUpdated   EventType = "booking.updated"
 
+	BookingCheckedIn  EventType = "booking.checked_in"
+	BookingCheckedOut EventType = "booking.checked_out"
+	BookingNoShow     EventType = "booking.no_show"
+
+	BookingReminder EventType = "booking.reminder"
+
+	WaitlistOffered EventType = "waitlist.offered"
+
+	ReviewCreated EventType = "review.created"
+
+	ExportCompleted EventType = "export.completed"
+
 	InventoryReserved EventType = "inventory.reserved"
 	InventoryReleased EventType = "inventory.released"
 	InventoryUpdated  EventType = "inventory.updated"
 
+	InventoryReservationFailed EventType = "inventory.reservation_failed"
+
 	PaymentProcessed EventType = "payment.processed"
 	PaymentFailed    EventType = "payment.failed"
 	PaymentRefunded  EventType = "payment.refunded"
@@ -31,23 +65,29 @@ const (
 )
 
 type BaseEvent struct {
-	ID        string         `json:"id"`
-	Type      EventType      `json:"type"`
-	Source    string         `json:"source"`
-	Timestamp time.Time      `json:"timestamp"`
-	Version   string         `json:"version"`
-	TraceID   string         `json:"trace_id,omitempty"`
-	Metadata  map[string]any `json:"metadata,omitempty"`
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Source    string    `json:"source"`
+	Timestamp time.Time `json:"timestamp"`
+	Version   string    `json:"version"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	// TenantID is empty in events produced before tenants were introduced,
+	// which belong to the default tenant.
+	TenantID string         `json:"tenant_id,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
-func NewBaseEvent(eventType EventType, source string, traceID string) BaseEvent {
+// NewBaseEvent returns the envelope of a new event of tenantID at the
+// latest schema version of eventType.
+func NewBaseEvent(eventType EventType, source string, traceID string, tenantID string) BaseEvent {
 	return BaseEvent{
 		ID:        uuid.New().String(),
 		Type:      eventType,
 		Source:    source,
 		Timestamp: time.Now().UTC(),
-		Version:   "1.0",
+		Version:   DefaultRegistry.Latest(eventType),
 		TraceID:   traceID,
+		TenantID:  tenantID,
 		Metadata:  make(map[string]any),
 	}
 }
@@ -58,10 +98,55 @@ type UserCreatedEvent struct {
 }
 
 type UserCreatedData struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+	// Locale is the language the user registered in, which they are
+	// notified in until they choose one
+	Locale    string    `json:"locale,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserVerificationRequestedEvent struct {
+	BaseEvent
+	Data UserVerificationRequestedData `json:"data"`
+}
+
+type UserVerificationRequestedData struct {
+	UserID          string    `json:"user_id"`
+	Email           string    `json:"email"`
+	Name            string    `json:"name"`
+	VerificationURL string    `json:"verification_url"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+type UserPasswordResetRequestedEvent struct {
+	BaseEvent
+	Data UserPasswordResetRequestedData `json:"data"`
+}
+
+type UserPasswordResetRequestedData struct {
 	UserID    string    `json:"user_id"`
 	Email     string    `json:"email"`
 	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	ResetURL  string    `json:"reset_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UserNewDeviceLoginEvent is published when a user logs in from an IP
+// address or device they did not log in from recently.
+type UserNewDeviceLoginEvent struct {
+	BaseEvent
+	Data UserNewDeviceLoginData `json:"data"`
+}
+
+type UserNewDeviceLoginData struct {
+	UserID     string    `json:"user_id"`
+	Email      string    `json:"email"`
+	Name       string    `json:"name"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	LoggedInAt time.Time `json:"logged_in_at"`
 }
 
 type UserUpdatedEvent struct {
@@ -76,6 +161,9 @@ type UserUpdatedData struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UserDeletedEvent is published when a user is deleted. The user is
+// anonymized, and every service holding personal data of theirs erases it
+// and answers with a UserDataErasedEvent.
 type UserDeletedEvent struct {
 	BaseEvent
 	Data UserDeletedData `json:"data"`
@@ -86,20 +174,206 @@ type UserDeletedData struct {
 	DeletedAt time.Time `json:"deleted_at"`
 }
 
+// UserDataExportRequestedEvent is published when a user requests a copy
+// of their personal data. Every service holding some answers with a
+// UserDataExportPartEvent.
+type UserDataExportRequestedEvent struct {
+	BaseEvent
+	Data UserDataExportRequestedData `json:"data"`
+}
+
+type UserDataExportRequestedData struct {
+	ExportID    string    `json:"export_id"`
+	UserID      string    `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// UserDataExportPartEvent carries the personal data a service holds about
+// a user, as a JSON document, for the export it was requested for.
+type UserDataExportPartEvent struct {
+	BaseEvent
+	Data UserDataExportPartData `json:"data"`
+}
+
+type UserDataExportPartData struct {
+	ExportID string          `json:"export_id"`
+	UserID   string          `json:"user_id"`
+	Part     string          `json:"part"`
+	Content  json.RawMessage `json:"content"`
+}
+
+// UserDataExportReadyEvent is published when every part of a data export
+// arrived and it can be downloaded.
+type UserDataExportReadyEvent struct {
+	BaseEvent
+	Data UserDataExportReadyData `json:"data"`
+}
+
+type UserDataExportReadyData struct {
+	ExportID    string    `json:"export_id"`
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	DownloadURL string    `json:"download_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// UserDataErasedEvent is published by a service that erased the personal
+// data of a deleted user. Records counts the records it erased or
+// anonymized; Retained lists what it had to keep, such as payment records
+// kept for accounting.
+type UserDataErasedEvent struct {
+	BaseEvent
+	Data UserDataErasedData `json:"data"`
+}
+
+type UserDataErasedData struct {
+	UserID   string    `json:"user_id"`
+	Part     string    `json:"part"`
+	Records  int       `json:"records"`
+	Retained []string  `json:"retained,omitempty"`
+	ErasedAt time.Time `json:"erased_at"`
+}
+
+// Parts of the personal data of a user, each held by one service.
+const (
+	DataPartProfile       = "profile"
+	DataPartBookings      = "bookings"
+	DataPartPayments      = "payments"
+	DataPartNotifications = "notifications"
+)
+
+// DataParts lists the parts data exports and erasures wait for.
+var DataParts = []string{DataPartProfile, DataPartBookings, DataPartPayments, DataPartNotifications}
+
+// UserDeviceRegisteredEvent is published when a user registers a device
+// for push notifications. A token registered again, also by another user,
+// replaces the earlier registration.
+type UserDeviceRegisteredEvent struct {
+	BaseEvent
+	Data UserDeviceRegisteredData `json:"data"`
+}
+
+type UserDeviceRegisteredData struct {
+	UserID       string    `json:"user_id"`
+	DeviceID     string    `json:"device_id"`
+	Token        string    `json:"token"`
+	Platform     string    `json:"platform"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+type UserDeviceRemovedEvent struct {
+	BaseEvent
+	Data UserDeviceRemovedData `json:"data"`
+}
+
+type UserDeviceRemovedData struct {
+	UserID    string    `json:"user_id"`
+	DeviceID  string    `json:"device_id"`
+	Token     string    `json:"token"`
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// UserNotificationPreferencesUpdatedEvent carries all notification
+// preferences of a user, not only the changed ones.
+type UserNotificationPreferencesUpdatedEvent struct {
+	BaseEvent
+	Data UserNotificationPreferencesData `json:"data"`
+}
+
+// UserNotificationPreferencesData holds the default channels of a user,
+// the channels per notification category that override them, and the
+// quiet hours in which only email is sent.
+type UserNotificationPreferencesData struct {
+	UserID     string                          `json:"user_id"`
+	Email      bool                            `json:"email"`
+	SMS        bool                            `json:"sms"`
+	Push       bool                            `json:"push"`
+	Categories map[string]NotificationChannels `json:"categories,omitempty"`
+	QuietHours *QuietHours                     `json:"quiet_hours,omitempty"`
+	Phone      string                          `json:"phone,omitempty"`
+	Locale     string                          `json:"locale,omitempty"`
+	UpdatedAt  time.Time                       `json:"updated_at"`
+}
+
+// UserNotificationDefaultsUpdatedEvent carries the notification
+// preferences of the users of a tenant who did not set their own.
+type UserNotificationDefaultsUpdatedEvent struct {
+	BaseEvent
+	Data UserNotificationDefaultsData `json:"data"`
+}
+
+type UserNotificationDefaultsData struct {
+	Email      bool                            `json:"email"`
+	SMS        bool                            `json:"sms"`
+	Push       bool                            `json:"push"`
+	Categories map[string]NotificationChannels `json:"categories,omitempty"`
+	QuietHours *QuietHours                     `json:"quiet_hours,omitempty"`
+	UpdatedAt  time.Time                       `json:"updated_at"`
+}
+
+type NotificationChannels struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+	Push  bool `json:"push"`
+}
+
+// QuietHours are a daily window from Start to End, as HH:MM in TimeZone.
+// The window spans midnight when End is before Start.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"time_zone"`
+}
+
+type ResourceCreatedEvent struct {
+	BaseEvent
+	Data ResourceData `json:"data"`
+}
+
+type ResourceUpdatedEvent struct {
+	BaseEvent
+	Data ResourceData `json:"data"`
+}
+
+// ResourceData is a full snapshot of a resource so consumers can replace
+// their cached copy without calling back into the resource service.
+type ResourceData struct {
+	ResourceID    string    `json:"resource_id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Description   string    `json:"description,omitempty"`
+	Location      string    `json:"location,omitempty"`
+	Capacity      int       `json:"capacity"`
+	BufferMinutes int       `json:"buffer_minutes"`
+	PricePerHour  float64   `json:"price_per_hour"`
+	Currency      string    `json:"currency"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ResourceDeletedEvent struct {
+	BaseEvent
+	Data ResourceDeletedData `json:"data"`
+}
+
+type ResourceDeletedData struct {
+	ResourceID string    `json:"resource_id"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
 type BookingRequestedEvent struct {
 	BaseEvent
 	Data BookingRequestedData `json:"data"`
 }
 
 type BookingRequestedData struct {
-	BookingID  string    `json:"booking_id"`
-	UserID     string    `json:"user_id"`
-	ResourceID string    `json:"resource_id"`
-	StartTime  time.Time `json:"start_time"`
-	EndTime    time.Time `json:"end_time"`
-	Amount     float64   `json:"amount"`
-	Currency   string    `json:"currency"`
-	Status     string    `json:"status"`
+	BookingID  string      `json:"booking_id"`
+	UserID     string      `json:"user_id"`
+	ResourceID string      `json:"resource_id"`
+	StartTime  time.Time   `json:"start_time"`
+	EndTime    time.Time   `json:"end_time"`
+	Amount     money.Money `json:"amount"`
+	Status     string      `json:"status"`
 }
 
 type BookingConfirmedEvent struct {
@@ -107,16 +381,44 @@ type BookingConfirmedEvent struct {
 	Data BookingConfirmedData `json:"data"`
 }
 
+// BookingConfirmedData names the resource in ResourceName when the
+// producer knows it, for the confirmation documents mailed to the user.
 type BookingConfirmedData struct {
-	BookingID   string    `json:"booking_id"`
-	UserID      string    `json:"user_id"`
-	ResourceID  string    `json:"resource_id"`
-	StartTime   time.Time `json:"start_time"`
-	EndTime     time.Time `json:"end_time"`
-	Amount      float64   `json:"amount"`
-	Currency    string    `json:"currency"`
-	PaymentID   string    `json:"payment_id"`
-	ConfirmedAt time.Time `json:"confirmed_at"`
+	BookingID    string      `json:"booking_id"`
+	UserID       string      `json:"user_id"`
+	ResourceID   string      `json:"resource_id"`
+	ResourceName string      `json:"resource_name,omitempty"`
+	StartTime    time.Time   `json:"start_time"`
+	EndTime      time.Time   `json:"end_time"`
+	Amount       money.Money `json:"amount"`
+	PaymentID    string      `json:"payment_id"`
+	ConfirmedAt  time.Time   `json:"confirmed_at"`
+}
+
+type BookingUpdatedEvent struct {
+	BaseEvent
+	Data BookingUpdatedData `json:"data"`
+}
+
+// BookingUpdatedData carries the booking after the update and, in
+// Previous, the values it replaced. The payment service settles the
+// difference between Previous.Amount and Amount.
+type BookingUpdatedData struct {
+	BookingID  string              `json:"booking_id"`
+	UserID     string              `json:"user_id"`
+	ResourceID string              `json:"resource_id"`
+	StartTime  time.Time           `json:"start_time"`
+	EndTime    time.Time           `json:"end_time"`
+	Amount     money.Money         `json:"amount"`
+	Status     string              `json:"status"`
+	Previous   BookingPreviousData `json:"previous"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+type BookingPreviousData struct {
+	StartTime time.Time   `json:"start_time"`
+	EndTime   time.Time   `json:"end_time"`
+	Amount    money.Money `json:"amount"`
 }
 
 type BookingCancelledEvent struct {
@@ -125,11 +427,128 @@ type BookingCancelledEvent struct {
 }
 
 type BookingCancelledData struct {
+	BookingID       string      `json:"booking_id"`
+	UserID          string      `json:"user_id"`
+	ResourceID      string      `json:"resource_id"`
+	Reason          string      `json:"reason"`
+	CancellationFee money.Money `json:"cancellation_fee"`
+	RefundAmount    money.Money `json:"refund_amount"`
+	CancelledAt     time.Time   `json:"cancelled_at"`
+}
+
+type BookingCheckedInEvent struct {
+	BaseEvent
+	Data BookingCheckedInData `json:"data"`
+}
+
+type BookingCheckedInData struct {
 	BookingID   string    `json:"booking_id"`
 	UserID      string    `json:"user_id"`
 	ResourceID  string    `json:"resource_id"`
-	Reason      string    `json:"reason"`
-	CancelledAt time.Time `json:"cancelled_at"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	CheckedInAt time.Time `json:"checked_in_at"`
+}
+
+// BookingCheckedOutEvent completes a booking. The scheduled window is
+// included so consumers can tell early departures and overstays.
+type BookingCheckedOutEvent struct {
+	BaseEvent
+	Data BookingCheckedOutData `json:"data"`
+}
+
+type BookingCheckedOutData struct {
+	BookingID    string      `json:"booking_id"`
+	UserID       string      `json:"user_id"`
+	ResourceID   string      `json:"resource_id"`
+	StartTime    time.Time   `json:"start_time"`
+	EndTime      time.Time   `json:"end_time"`
+	Amount       money.Money `json:"amount"`
+	CheckedInAt  time.Time   `json:"checked_in_at"`
+	CheckedOutAt time.Time   `json:"checked_out_at"`
+}
+
+// BookingNoShowEvent reports a confirmed booking that was never checked
+// in.
+type BookingNoShowEvent struct {
+	BaseEvent
+	Data BookingNoShowData `json:"data"`
+}
+
+type BookingNoShowData struct {
+	BookingID  string      `json:"booking_id"`
+	UserID     string      `json:"user_id"`
+	ResourceID string      `json:"resource_id"`
+	StartTime  time.Time   `json:"start_time"`
+	EndTime    time.Time   `json:"end_time"`
+	Amount     money.Money `json:"amount"`
+	MarkedAt   time.Time   `json:"marked_at"`
+}
+
+// BookingReminderEvent is published once for a confirmed booking shortly
+// before it starts, for reminding its user.
+type BookingReminderEvent struct {
+	BaseEvent
+	Data BookingReminderData `json:"data"`
+}
+
+type BookingReminderData struct {
+	BookingID  string    `json:"booking_id"`
+	UserID     string    `json:"user_id"`
+	ResourceID string    `json:"resource_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+}
+
+// WaitlistOfferedEvent tells a waitlisted user that their slot freed up
+// and is held for them until ExpiresAt.
+type WaitlistOfferedEvent struct {
+	BaseEvent
+	Data WaitlistOfferedData `json:"data"`
+}
+
+type WaitlistOfferedData struct {
+	EntryID    string    `json:"entry_id"`
+	UserID     string    `json:"user_id"`
+	ResourceID string    `json:"resource_id"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ReviewCreatedEvent carries a new review together with the rating of the
+// resource including it, which the resource catalog keeps a copy of.
+type ReviewCreatedEvent struct {
+	BaseEvent
+	Data ReviewCreatedData `json:"data"`
+}
+
+type ReviewCreatedData struct {
+	ReviewID      string    `json:"review_id"`
+	BookingID     string    `json:"booking_id"`
+	UserID        string    `json:"user_id"`
+	ResourceID    string    `json:"resource_id"`
+	Rating        int       `json:"rating"`
+	AverageRating float64   `json:"average_rating"`
+	RatingCount   int       `json:"rating_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ExportCompletedEvent is published when a background export was written,
+// for mailing the download link to the admin who requested it.
+type ExportCompletedEvent struct {
+	BaseEvent
+	Data ExportCompletedData `json:"data"`
+}
+
+type ExportCompletedData struct {
+	ExportID    string    `json:"export_id"`
+	UserID      string    `json:"user_id"`
+	Dataset     string    `json:"dataset"`
+	Format      string    `json:"format"`
+	Rows        int       `json:"rows"`
+	DownloadURL string    `json:"download_url"`
+	CompletedAt time.Time `json:"completed_at"`
 }
 
 type InventoryReservedEvent struct {
@@ -159,6 +578,26 @@ type InventoryReleasedData struct {
 	Reason        string    `json:"reason"`
 }
 
+// Reasons a reservation is released for.
+const (
+	ReleaseReasonBookingCancelled = "booking_cancelled"
+	// ReleaseReasonHoldExpired releases a slot whose booking was not paid
+	// before the hold expired.
+	ReleaseReasonHoldExpired = "hold_expired"
+)
+
+type InventoryReservationFailedEvent struct {
+	BaseEvent
+	Data InventoryReservationFailedData `json:"data"`
+}
+
+type InventoryReservationFailedData struct {
+	ResourceID string    `json:"resource_id"`
+	BookingID  string    `json:"booking_id"`
+	Reason     string    `json:"reason"`
+	FailedAt   time.Time `json:"failed_at"`
+}
+
 // Payment Events
 type PaymentProcessedEvent struct {
 	BaseEvent
@@ -166,14 +605,13 @@ type PaymentProcessedEvent struct {
 }
 
 type PaymentProcessedData struct {
-	PaymentID   string    `json:"payment_id"`
-	BookingID   string    `json:"booking_id"`
-	UserID      string    `json:"user_id"`
-	Amount      float64   `json:"amount"`
-	Currency    string    `json:"currency"`
-	Method      string    `json:"method"`
-	Status      string    `json:"status"`
-	ProcessedAt time.Time `json:"processed_at"`
+	PaymentID   string      `json:"payment_id"`
+	BookingID   string      `json:"booking_id"`
+	UserID      string      `json:"user_id"`
+	Amount      money.Money `json:"amount"`
+	Method      string      `json:"method"`
+	Status      string      `json:"status"`
+	ProcessedAt time.Time   `json:"processed_at"`
 }
 
 type PaymentFailedEvent struct {
@@ -182,13 +620,25 @@ type PaymentFailedEvent struct {
 }
 
 type PaymentFailedData struct {
-	PaymentID string    `json:"payment_id"`
-	BookingID string    `json:"booking_id"`
-	UserID    string    `json:"user_id"`
-	Amount    float64   `json:"amount"`
-	Currency  string    `json:"currency"`
-	Reason    string    `json:"reason"`
-	FailedAt  time.Time `json:"failed_at"`
+	PaymentID string      `json:"payment_id"`
+	BookingID string      `json:"booking_id"`
+	UserID    string      `json:"user_id"`
+	Amount    money.Money `json:"amount"`
+	Reason    string      `json:"reason"`
+	FailedAt  time.Time   `json:"failed_at"`
+}
+
+type PaymentRefundedEvent struct {
+	BaseEvent
+	Data PaymentRefundedData `json:"data"`
+}
+
+type PaymentRefundedData struct {
+	PaymentID  string      `json:"payment_id"`
+	BookingID  string      `json:"booking_id"`
+	UserID     string      `json:"user_id"`
+	Amount     money.Money `json:"amount"`
+	RefundedAt time.Time   `json:"refunded_at"`
 }
 
 // Notification Events
@@ -207,3 +657,17 @@ type NotificationSentData struct {
 	SentAt         time.Time      `json:"sent_at"`
 	Metadata       map[string]any `json:"metadata,omitempty"`
 }
+
+type NotificationFailedEvent struct {
+	BaseEvent
+	Data NotificationFailedData `json:"data"`
+}
+
+type NotificationFailedData struct {
+	NotificationID string    `json:"notification_id"`
+	UserID         string    `json:"user_id"`
+	Type           string    `json:"type"`
+	Channel        string    `json:"channel"`
+	Reason         string    `json:"reason"`
+	FailedAt       time.Time `json:"failed_at"`
+}
@@ -0,0 +1,384 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/money"
+)
+
+// InitialVersion is the schema version every event type starts at.
+const InitialVersion = "1.0"
+
+// ErrInvalidEvent is wrapped by the errors of events that do not match
+// their schema or have an unknown version. Such events can never be
+// processed and are dead-lettered by consumers.
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Upcaster converts the decoded JSON of an event from one version to the
+// next. It must set the "version" field of the result.
+type Upcaster func(event map[string]any) (map[string]any, error)
+
+type upcaster struct {
+	to string
+	fn Upcaster
+}
+
+// Registry holds the schemas of every version of every event type and the
+// upcasters between them. Producers validate events against the latest
+// version; consumers accept any registered version and upcast it to the
+// latest, so handlers only ever see the current shape of an event.
+//
+// To change an event incompatibly, register the new version's schema and
+// an upcaster from the previous version, then update the event struct.
+type Registry struct {
+	mu        sync.RWMutex
+	schemas   map[EventType]map[string]*Schema
+	latest    map[EventType]string
+	upcasters map[EventType]map[string]upcaster
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas:   make(map[EventType]map[string]*Schema),
+		latest:    make(map[EventType]string),
+		upcasters: make(map[EventType]map[string]upcaster),
+	}
+}
+
+// DefaultRegistry holds the schemas of the events in this package.
+var DefaultRegistry = NewRegistry()
+
+// Register adds the schema of a version of an event type. The highest
+// registered version becomes the one events are produced at.
+func (r *Registry) Register(eventType EventType, version string, schema *Schema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.schemas[eventType] == nil {
+		r.schemas[eventType] = make(map[string]*Schema)
+	}
+	r.schemas[eventType][version] = schema
+
+	if latest, ok := r.latest[eventType]; !ok || compareVersions(version, latest) > 0 {
+		r.latest[eventType] = version
+	}
+}
+
+// RegisterUpcaster adds the conversion of an event type from one version to
+// the next.
+func (r *Registry) RegisterUpcaster(eventType EventType, from, to string, fn Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = make(map[string]upcaster)
+	}
+	r.upcasters[eventType][from] = upcaster{to: to, fn: fn}
+}
+
+// Latest returns the version events of eventType are produced at.
+func (r *Registry) Latest(eventType EventType) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if version, ok := r.latest[eventType]; ok {
+		return version
+	}
+	return InitialVersion
+}
+
+// EventTypes returns the registered event types, sorted.
+func (r *Registry) EventTypes() []EventType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.schemas))
+}
+
+// Lookup returns the schema of a version of an event type.
+func (r *Registry) Lookup(eventType EventType, version string) (*Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[eventType][version]
+	return schema, ok
+}
+
+// Validate checks an encoded event against the schema of its version.
+// Payloads of unregistered types are not checked.
+func (r *Registry) Validate(data []byte) error {
+	event, eventType, version, err := decode(data)
+	if err != nil {
+		return err
+	}
+
+	schema, known, err := r.schema(eventType, version)
+	if err != nil || !known {
+		return err
+	}
+	return check(schema, eventType, version, event)
+}
+
+// Upcast validates an encoded event and converts it to the latest version
+// of its type. Payloads of unregistered types are returned unchanged.
+func (r *Registry) Upcast(data []byte) ([]byte, error) {
+	event, eventType, version, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, known, err := r.schema(eventType, version)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return data, nil
+	}
+	if err := check(schema, eventType, version, event); err != nil {
+		return nil, err
+	}
+
+	latest := r.Latest(eventType)
+	if version == latest {
+		return data, nil
+	}
+
+	for version != latest {
+		r.mu.RLock()
+		next, ok := r.upcasters[eventType][version]
+		r.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no upcaster from version %s", ErrInvalidEvent, eventType, version)
+		}
+
+		if event, err = next.fn(event); err != nil {
+			return nil, fmt.Errorf("%w: upcasting %s from version %s: %v", ErrInvalidEvent, eventType, version, err)
+		}
+		version = next.to
+	}
+
+	schema, _, err = r.schema(eventType, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(schema, eventType, version, event); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(event)
+}
+
+// schema reports whether eventType is registered at all, and fails when it
+// is but version is not.
+func (r *Registry) schema(eventType EventType, version string) (*Schema, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.schemas[eventType]
+	if !ok {
+		return nil, false, nil
+	}
+
+	schema, ok := versions[version]
+	if !ok {
+		return nil, true, fmt.Errorf("%w: unknown version %q of %s", ErrInvalidEvent, version, eventType)
+	}
+	return schema, true, nil
+}
+
+func decode(data []byte) (map[string]any, EventType, string, error) {
+	var event map[string]any
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, "", "", fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	eventType, _ := event["type"].(string)
+	version, _ := event["version"].(string)
+	return event, EventType(eventType), version, nil
+}
+
+func check(schema *Schema, eventType EventType, version string, event map[string]any) error {
+	if err := schema.Validate(event); err != nil {
+		return fmt.Errorf("%w: %s version %s: %v", ErrInvalidEvent, eventType, version, err)
+	}
+	return nil
+}
+
+// compareVersions orders "major.minor" versions numerically.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// register adds the schema of the initial version of an event.
+func register(eventType EventType, event any) {
+	DefaultRegistry.Register(eventType, InitialVersion, eventSchema(eventType, event))
+}
+
+// eventSchema derives the schema of an event, pinning its type and
+// requiring a UUID id.
+func eventSchema(eventType EventType, event any) *Schema {
+	schema := SchemaOf(event)
+	schema.Properties["type"].Enum = []string{string(eventType)}
+	schema.Properties["id"].Format = "uuid"
+	return schema
+}
+
+// MoneyVersion is the version from which events carry amounts as
+// money.Money in minor units. Version 1.0 carried them as decimal numbers
+// next to a single data.currency.
+const MoneyVersion = "2.0"
+
+// registerMoney adds the schema of an event with money amounts in the data
+// fields named amounts, along with the 1.0 schema they replaced and the
+// upcaster from it.
+func registerMoney(eventType EventType, event any, amounts ...string) {
+	schema := eventSchema(eventType, event)
+	DefaultRegistry.Register(eventType, MoneyVersion, schema)
+	DefaultRegistry.Register(eventType, InitialVersion, decimalAmounts(schema, amounts))
+	DefaultRegistry.RegisterUpcaster(eventType, InitialVersion, MoneyVersion, upcastAmounts(amounts))
+}
+
+// decimalAmounts returns a copy of schema with the amounts as numbers and
+// a required data.currency.
+func decimalAmounts(schema *Schema, amounts []string) *Schema {
+	data := *schema.Properties["data"]
+	data.Properties = maps.Clone(data.Properties)
+	for _, name := range amounts {
+		data.Properties[name] = &Schema{Type: "number"}
+	}
+	data.Properties["currency"] = &Schema{Type: "string"}
+	data.Required = append(slices.Clone(data.Required), "currency")
+
+	v1 := *schema
+	v1.Properties = maps.Clone(schema.Properties)
+	v1.Properties["data"] = &data
+	return &v1
+}
+
+// upcastAmounts converts the decimal amounts of a 1.0 event to minor units
+// of its data.currency.
+func upcastAmounts(amounts []string) Upcaster {
+	return func(event map[string]any) (map[string]any, error) {
+		data, _ := event["data"].(map[string]any)
+		currency, err := money.ParseCurrency(fmt.Sprint(data["currency"]))
+		if err != nil {
+			return nil, err
+		}
+
+		// Decoded JSON numbers are float64, also for the minor units
+		for _, name := range amounts {
+			amount, _ := data[name].(float64)
+			data[name] = map[string]any{
+				"amount":   float64(money.FromFloat(amount, currency).Amount),
+				"currency": string(currency),
+			}
+		}
+		delete(data, "currency")
+
+		event["version"] = MoneyVersion
+		return event, nil
+	}
+}
+
+func init() {
+	register(UserCreated, UserCreatedEvent{})
+	register(UserUpdated, UserUpdatedEvent{})
+	register(UserDeleted, UserDeletedEvent{})
+	register(UserVerificationRequested, UserVerificationRequestedEvent{})
+	register(UserPasswordResetRequested, UserPasswordResetRequestedEvent{})
+	register(UserNewDeviceLogin, UserNewDeviceLoginEvent{})
+	register(UserDeviceRegistered, UserDeviceRegisteredEvent{})
+	register(UserDeviceRemoved, UserDeviceRemovedEvent{})
+	register(UserNotificationPreferencesUpdated, UserNotificationPreferencesUpdatedEvent{})
+	register(UserNotificationDefaultsUpdated, UserNotificationDefaultsUpdatedEvent{})
+	register(UserDataExportRequested, UserDataExportRequestedEvent{})
+	register(UserDataExportPart, UserDataExportPartEvent{})
+	register(UserDataExportReady, UserDataExportReadyEvent{})
+	register(UserDataErased, UserDataErasedEvent{})
+
+	register(ResourceCreated, ResourceCreatedEvent{})
+	register(ResourceUpdated, ResourceUpdatedEvent{})
+	register(ResourceDeleted, ResourceDeletedEvent{})
+
+	registerMoney(BookingRequested, BookingRequestedEvent{}, "amount")
+	registerMoney(BookingConfirmed, BookingConfirmedEvent{}, "amount")
+	registerMoney(BookingCancelled, BookingCancelledEvent{}, "cancellation_fee", "refund_amount")
+	registerBookingUpdated()
+	register(BookingCheckedIn, BookingCheckedInEvent{})
+	register(BookingCheckedOut, BookingCheckedOutEvent{})
+	register(BookingNoShow, BookingNoShowEvent{})
+	register(BookingReminder, BookingReminderEvent{})
+	register(WaitlistOffered, WaitlistOfferedEvent{})
+	register(ReviewCreated, ReviewCreatedEvent{})
+	register(ExportCompleted, ExportCompletedEvent{})
+
+	register(InventoryReserved, InventoryReservedEvent{})
+	register(InventoryReleased, InventoryReleasedEvent{})
+	register(InventoryReservationFailed, InventoryReservationFailedEvent{})
+
+	registerMoney(PaymentProcessed, PaymentProcessedEvent{}, "amount")
+	registerMoney(PaymentFailed, PaymentFailedEvent{}, "amount")
+	registerMoney(PaymentRefunded, PaymentRefundedEvent{}, "amount")
+
+	register(NotificationSent, NotificationSentEvent{})
+	register(NotificationFailed, NotificationFailedEvent{})
+}
+
+// bookingUpdatedV1 is the shape of booking.updated before it carried the
+// amount and the previous values.
+type bookingUpdatedV1 struct {
+	BaseEvent
+	Data struct {
+		BookingID  string    `json:"booking_id"`
+		UserID     string    `json:"user_id"`
+		ResourceID string    `json:"resource_id"`
+		StartTime  time.Time `json:"start_time"`
+		EndTime    time.Time `json:"end_time"`
+		Status     string    `json:"status"`
+		UpdatedAt  time.Time `json:"updated_at"`
+	} `json:"data"`
+}
+
+// registerBookingUpdated adds booking.updated at MoneyVersion, when it
+// gained the amount and the previous values, with an upcaster from 1.0.
+// Older events did not change the price, so they are upcast with equal
+// zero amounts and the current window as the previous one.
+func registerBookingUpdated() {
+	DefaultRegistry.Register(BookingUpdated, MoneyVersion, eventSchema(BookingUpdated, BookingUpdatedEvent{}))
+	DefaultRegistry.Register(BookingUpdated, InitialVersion, eventSchema(BookingUpdated, bookingUpdatedV1{}))
+	DefaultRegistry.RegisterUpcaster(BookingUpdated, InitialVersion, MoneyVersion, func(event map[string]any) (map[string]any, error) {
+		data, _ := event["data"].(map[string]any)
+		zero := map[string]any{"amount": float64(0), "currency": ""}
+
+		data["amount"] = zero
+		data["previous"] = map[string]any{
+			"start_time": data["start_time"],
+			"end_time":   data["end_time"],
+			"amount":     zero,
+		}
+
+		event["version"] = MoneyVersion
+		return event, nil
+	})
+}
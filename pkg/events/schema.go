@@ -0,0 +1,219 @@
+package events
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schema is the subset of JSON Schema used to describe event payloads.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf derives the schema of an event struct from its json tags.
+// Fields without omitempty are required, and time.Time fields must be RFC
+// 3339 timestamps. Formats and enums the tags cannot express are added to
+// the returned schema by hand.
+func SchemaOf(event any) *Schema {
+	return schemaOf(reflect.TypeOf(event))
+}
+
+func schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return objectSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// objectSchema flattens embedded structs as encoding/json does.
+func objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := objectSchema(field.Type)
+			for prop, propSchema := range embedded.Properties {
+				schema.Properties[prop] = propSchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaOf(field.Type)
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// Property returns the schema of a nested property such as "data.user_id",
+// or nil if there is none.
+func (s *Schema) Property(path string) *Schema {
+	schema := s
+	for _, name := range strings.Split(path, ".") {
+		if schema == nil {
+			return nil
+		}
+		schema = schema.Properties[name]
+	}
+	return schema
+}
+
+// Validate checks a decoded JSON value against the schema. Undeclared
+// properties are allowed so that producers can add optional fields before
+// consumers know about them.
+func (s *Schema) Validate(value any) error {
+	return s.validate("", value)
+}
+
+func (s *Schema) validate(path string, value any) error {
+	at := func(format string, args ...any) error {
+		if path == "" {
+			return fmt.Errorf(format, args...)
+		}
+		return fmt.Errorf(path+": "+format, args...)
+	}
+
+	// encoding/json writes nil maps and slices as null
+	if value == nil {
+		if s.Type == "" || s.Type == "object" || s.Type == "array" {
+			return nil
+		}
+		return at("must be a %s, got null", s.Type)
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return at("must be an object")
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return at("missing required property %q", name)
+			}
+		}
+		for name, prop := range s.Properties {
+			propValue, ok := object[name]
+			if !ok {
+				continue
+			}
+			if err := prop.validate(join(path, name), propValue); err != nil {
+				return err
+			}
+		}
+
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return at("must be an array")
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return at("must be a string")
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return at("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		return validateFormat(s.Format, str, at)
+
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return at("must be an integer")
+		}
+
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return at("must be a number")
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return at("must be a boolean")
+		}
+	}
+
+	return nil
+}
+
+func validateFormat(format, value string, at func(string, ...any) error) error {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return at("must be an RFC 3339 timestamp")
+		}
+	case "uuid":
+		if _, err := uuid.Parse(value); err != nil {
+			return at("must be a UUID")
+		}
+	}
+	return nil
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,62 @@
+// Package export writes tabular exports as CSV or XLSX. Rows are written
+// one at a time so an export never has to be held in memory.
+package export
+
+import (
+	"context"
+	"io"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat reads the format query parameter. It defaults to CSV.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", errors.NewValidationError("format must be csv or xlsx", nil)
+	}
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename returns the name of an export of dataset in this format.
+func (f Format) Filename(dataset string) string {
+	return dataset + "." + string(f)
+}
+
+// Writer writes the rows of an export. Flush hands the rows written so far
+// to the underlying writer; Close finishes the file and must be called
+// once all rows were written.
+type Writer interface {
+	Write(row []string) error
+	Flush() error
+	Close() error
+}
+
+// NewWriter returns a Writer producing format f on w.
+func NewWriter(w io.Writer, f Format) Writer {
+	if f == FormatXLSX {
+		return newXLSXWriter(w)
+	}
+	return newCSVWriter(w)
+}
+
+// Source writes the rows of an export, header first, and returns the
+// number of data rows written.
+type Source func(ctx context.Context, w Writer) (int, error)
@@ -0,0 +1,6 @@
+// Package grpc holds the protobuf messages and gRPC stubs generated from
+// the definitions in proto/. Do not edit the generated files by hand;
+// regenerate them with go generate ./pkg/grpc.
+package grpc
+
+//go:generate protoc --proto_path=../../proto --go_out=../.. --go_opt=module=github.com/dmehra2102/booking-system --go-grpc_out=../.. --go-grpc_opt=module=github.com/dmehra2102/booking-system user/v1/user.proto resource/v1/resource.proto booking/v1/booking.proto inventory/v1/inventory.proto
@@ -0,0 +1,157 @@
+// Package i18n translates the messages the services show to people.
+// Messages are written in English and keyed by their English text, so a
+// message without a translation is shown as written.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the language messages are written in.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Default bundles the translations shipped with the services.
+var Default = MustLoad(locales, "locales")
+
+// Bundle holds the translations of messages by locale.
+type Bundle struct {
+	// tags are the supported locales, DefaultLocale first
+	tags     []language.Tag
+	matcher  language.Matcher
+	messages map[string]map[string]string
+}
+
+// Load reads the translations in dir of fsys, one <locale>.json file per
+// locale mapping English messages to their translation.
+func Load(fsys fs.FS, dir string) (*Bundle, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read translations: %w", err)
+	}
+
+	b := &Bundle{
+		tags:     []language.Tag{language.Make(DefaultLocale)},
+		messages: make(map[string]map[string]string),
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		tag, err := language.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("translation file %s is not named after a locale: %w", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read translation file %s: %w", entry.Name(), err)
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse translation file %s: %w", entry.Name(), err)
+		}
+
+		b.tags = append(b.tags, tag)
+		b.messages[strings.ToLower(tag.String())] = messages
+	}
+	b.matcher = language.NewMatcher(b.tags)
+
+	return b, nil
+}
+
+// MustLoad is Load for translations embedded in the binary, which are
+// known to load.
+func MustLoad(fsys fs.FS, dir string) *Bundle {
+	b, err := Load(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Locales returns the supported locales, DefaultLocale first.
+func (b *Bundle) Locales() []string {
+	locales := make([]string, len(b.tags))
+	for i, tag := range b.tags {
+		locales[i] = tag.String()
+	}
+	return locales
+}
+
+// Match returns the supported locale that suits an Accept-Language header
+// best, or DefaultLocale when none does.
+func (b *Bundle) Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return b.tags[index].String()
+}
+
+// Translate returns message in locale, or in the base language of locale
+// when there is no translation for its region. Messages without a
+// translation are returned as they are.
+func (b *Bundle) Translate(locale, message string) string {
+	for _, candidate := range Fallbacks(locale) {
+		if translated, ok := b.messages[candidate][message]; ok {
+			return translated
+		}
+	}
+	return message
+}
+
+// Fallbacks returns the locales to try for locale, most specific first,
+// in lower case and ending with DefaultLocale: "pt-BR" gives "pt-br",
+// "pt" and "en".
+func Fallbacks(locale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	var candidates []string
+	for locale != "" && locale != DefaultLocale {
+		candidates = append(candidates, locale)
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return append(candidates, DefaultLocale)
+}
+
+type contextKey struct{}
+
+// WithLocale returns a context for a request in locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// Locale returns the locale of the request of ctx, or DefaultLocale.
+func Locale(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T translates message into the locale of ctx with the Default bundle.
+func T(ctx context.Context, message string) string {
+	return Default.Translate(Locale(ctx), message)
+}
@@ -0,0 +1,85 @@
+package money
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RateProvider supplies exchange rates for showing amounts in other
+// currencies. Amounts are always charged in their own currency; converted
+// amounts are informational.
+type RateProvider interface {
+	// Rate returns how many units of to one unit of from buys.
+	Rate(ctx context.Context, from, to Currency) (float64, error)
+}
+
+// Convert expresses m in currency to at the provider's rate.
+func Convert(ctx context.Context, rates RateProvider, m Money, to Currency) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	rate, err := rates.Rate(ctx, m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+	return FromFloat(m.Float()*rate, to), nil
+}
+
+// StaticRates are fixed rates against a base currency, for deployments
+// that configure them rather than query a rates service.
+type StaticRates struct {
+	base  Currency
+	rates map[Currency]float64
+}
+
+// NewStaticRates returns rates where one unit of base buys rates[c] of c.
+// Rates between two other currencies go through base.
+func NewStaticRates(base Currency, rates map[Currency]float64) *StaticRates {
+	all := map[Currency]float64{base: 1}
+	for currency, rate := range rates {
+		all[currency] = rate
+	}
+	return &StaticRates{base: base, rates: all}
+}
+
+func (r *StaticRates) Rate(ctx context.Context, from, to Currency) (float64, error) {
+	fromRate, ok := r.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := r.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+// ParseRates parses a comma separated list of "currency:rate" pairs, e.g.
+// "EUR:0.92,GBP:0.79".
+func ParseRates(s string) (map[Currency]float64, error) {
+	rates := make(map[Currency]float64)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		code, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate %q, want currency:rate", part)
+		}
+		currency, err := ParseCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q", part)
+		}
+		rates[currency] = rate
+	}
+	return rates, nil
+}
@@ -0,0 +1,176 @@
+// Package money represents amounts as integer minor units of an ISO 4217
+// currency, so that prices, fees and refunds add up to the cent.
+//
+// Amounts are stored as NUMERIC columns and read back with Parse, which
+// is exact; FromFloat is only meant for decimal inputs such as configured
+// rates.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are
+// combined.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount in the minor units of its currency, e.g. 1250 USD is
+// $12.50 and 1250 JPY is ¥1250.
+type Money struct {
+	Amount   int64    `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// New returns amount minor units of currency.
+func New(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Zero returns nothing in currency.
+func Zero(currency Currency) Money {
+	return Money{Currency: currency}
+}
+
+// FromFloat converts a decimal amount such as 12.5 to minor units, rounding
+// half away from zero.
+func FromFloat(amount float64, currency Currency) Money {
+	return Money{
+		Amount:   int64(math.Round(amount * math.Pow10(currency.MinorUnits()))),
+		Currency: currency,
+	}
+}
+
+// Parse reads a decimal amount such as "12.50" exactly. More fractional
+// digits than the currency has are rejected.
+func Parse(amount string, currency Currency) (Money, error) {
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	digits := currency.MinorUnits()
+
+	// Trailing zeros beyond the currency's precision are harmless
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > digits {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimals for %s", amount, digits, currency)
+	}
+	fraction += strings.Repeat("0", digits-len(fraction))
+
+	if whole == "" {
+		whole = "0"
+	}
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || strings.ContainsAny(whole, "+-") {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	if negative {
+		units = -units
+	}
+	return Money{Amount: units, Currency: currency}, nil
+}
+
+// Float returns the amount in major units. Use it for display and metrics
+// only.
+func (m Money) Float() float64 {
+	return float64(m.Amount) / math.Pow10(m.Currency.MinorUnits())
+}
+
+// Decimal formats the amount in major units with the currency's number of
+// decimals, e.g. "12.50".
+func (m Money) Decimal() string {
+	digits := m.Currency.MinorUnits()
+
+	units := m.Amount
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+
+	s := strconv.FormatInt(units, 10)
+	if digits == 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+// String formats the amount with its currency, e.g. "12.50 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add returns m + other.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp compares m to other, returning -1, 0 or 1.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Mul scales m by factor, rounding to the nearest minor unit.
+func (m Money) Mul(factor float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * factor)), Currency: m.Currency}
+}
+
+// Percent returns percent of m, rounded to the nearest minor unit.
+func (m Money) Percent(percent float64) Money {
+	return m.Mul(percent / 100)
+}
+
+// Min returns the smaller of m and other.
+func (m Money) Min(other Money) (Money, error) {
+	cmp, err := m.Cmp(other)
+	if err != nil {
+		return Money{}, err
+	}
+	if cmp > 0 {
+		return other, nil
+	}
+	return m, nil
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+// Package openapi builds OpenAPI 3 documents from route definitions and
+// the request and response types they use. Schemas are derived from json
+// and validate struct tags, so the document describes exactly what the
+// validator enforces.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+// Route describes one HTTP endpoint. Path uses gin syntax (":id"). Request
+// and Response are zero values of the body types, e.g. domain.User{}; a nil
+// Response documents an empty body. List wraps Response in a paginated
+// envelope.
+type Route struct {
+	Method          string
+	Path            string
+	Summary         string
+	Tag             string
+	Auth            bool
+	Admin           bool
+	Request         any
+	RequestOptional bool
+	Response        any
+	List            bool
+	Status          int
+	Query           []Param
+}
+
+// Param is a query string parameter.
+type Param struct {
+	Name        string
+	Description string
+	Type        string
+	Format      string
+	Enum        []string
+}
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps lower case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Build returns the document for routes. Authenticated routes accept
+// either a bearer JWT or an API key.
+func Build(title, version string, routes []Route) *Document {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: s.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				"apiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			},
+		},
+	}
+
+	errorSchema := s.errorEnvelope()
+
+	for _, route := range routes {
+		path, pathParams := convertPath(route.Path)
+
+		op := &Operation{
+			Summary:     route.Summary,
+			OperationID: operationID(route.Method, route.Path),
+			Parameters:  pathParams,
+			Responses:   make(map[string]Response),
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+		}
+
+		for _, q := range route.Query {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        q.Name,
+				In:          "query",
+				Description: q.Description,
+				Schema:      q.schema(),
+			})
+		}
+		if route.List {
+			op.Parameters = append(op.Parameters, paginationParams()...)
+		}
+
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: !route.RequestOptional,
+				Content:  jsonContent(s.ref(reflect.TypeOf(route.Request))),
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		if route.Response != nil {
+			success.Content = jsonContent(s.envelope(reflect.TypeOf(route.Response), route.List))
+		}
+		op.Responses[strconv.Itoa(status)] = success
+
+		if route.Request != nil || len(route.Query) > 0 || route.List {
+			op.Responses["400"] = Response{Description: "Validation failed", Content: jsonContent(errorSchema)}
+		}
+		if route.Auth {
+			op.Security = []map[string][]string{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+			op.Responses["401"] = Response{Description: "Unauthorized", Content: jsonContent(errorSchema)}
+		}
+		if route.Admin {
+			op.Responses["403"] = Response{Description: "Forbidden", Content: jsonContent(errorSchema)}
+		}
+		op.Responses["default"] = Response{Description: "Error", Content: jsonContent(errorSchema)}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	return doc
+}
+
+// convertPath turns "/users/:id" into "/users/{id}" and returns the path
+// parameters it declares. Parameters named id or ending in _id are UUIDs.
+func convertPath(path string) (string, []Parameter) {
+	var params []Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema.Format = "uuid"
+		}
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a stable identifier such as "post_users_id_role".
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimPrefix(segment, ":")
+		if segment == "" || segment == "api" || segment == "v1" {
+			continue
+		}
+		parts = append(parts, strings.ReplaceAll(segment, "-", "_"))
+	}
+	return strings.Join(parts, "_")
+}
+
+func (p Param) schema() *Schema {
+	t := p.Type
+	if t == "" {
+		t = "string"
+	}
+	return &Schema{Type: t, Format: p.Format, Enum: p.Enum}
+}
+
+func paginationParams() []Parameter {
+	return []Parameter{
+		{Name: "page", In: "query", Schema: &Schema{Type: "integer", Minimum: float64Ptr(1)}},
+		{Name: "page_size", In: "query", Schema: &Schema{Type: "integer", Minimum: float64Ptr(1), Maximum: float64Ptr(100)}},
+		{Name: "cursor", In: "query", Description: "Opaque cursor from a previous page's next_cursor", Schema: &Schema{Type: "string"}},
+		{Name: "sort", In: "query", Description: "Sort field, prefixed with - for descending order", Schema: &Schema{Type: "string"}},
+	}
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
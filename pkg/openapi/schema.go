@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmehra2102/booking-system/pkg/response"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// passwordMinLength mirrors the "password" validator in pkg/validation.
+const passwordMinLength = 8
+
+// slugPattern mirrors the "slug" validator in pkg/validation.
+const slugPattern = `^[a-z0-9]+(-[a-z0-9]+)*$`
+
+// schemas registers named struct types as components and refers to them
+// by $ref.
+type schemas struct {
+	components map[string]*Schema
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: make(map[string]*Schema)}
+}
+
+// ref returns a schema for t, registering struct types as components.
+func (s *schemas) ref(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t == timeType || t.Name() == "" {
+		return s.inline(t)
+	}
+
+	name := t.Name()
+	if _, ok := s.components[name]; !ok {
+		// Reserve the name first so self-referencing types terminate
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemas) inline(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.ref(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.ref(t.Elem())}
+	case reflect.Struct:
+		return s.object(t)
+	case reflect.Pointer:
+		return s.ref(t)
+	default:
+		// interface{} and anything else accepts any JSON value
+		return &Schema{}
+	}
+}
+
+// object builds the schema of a struct from its exported, json tagged
+// fields. Embedded structs are flattened as encoding/json does.
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := s.object(indirect(field.Type))
+			for prop, propSchema := range embedded.Properties {
+				schema.Properties[prop] = propSchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := s.ref(field.Type)
+		if field.Type.Kind() == reflect.Pointer {
+			prop = withNullable(prop)
+		}
+
+		required := applyValidation(prop, indirect(field.Type), field.Tag.Get("validate"))
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = prop
+	}
+
+	return schema
+}
+
+// applyValidation copies validate tag rules onto schema and reports
+// whether the field is required. Rules after "dive" apply to elements.
+func applyValidation(schema *Schema, t reflect.Type, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if schema.Items != nil && t.Kind() == reflect.Slice {
+				applyValidation(schema.Items, indirect(t.Elem()), strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "email":
+			schema.Format = "email"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "url":
+			schema.Format = "uri"
+		case "password":
+			schema.MinLength = intPtr(passwordMinLength)
+		case "currency":
+			schema.Description = "ISO 4217 currency code"
+			schema.MinLength, schema.MaxLength = intPtr(3), intPtr(3)
+		case "slug":
+			schema.Pattern = slugPattern
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "min", "gte":
+			setBound(schema, t, param, true)
+		case "max", "lte":
+			setBound(schema, t, param, false)
+		case "len":
+			setBound(schema, t, param, true)
+			setBound(schema, t, param, false)
+		case "gtfield":
+			schema.Description = "Must be after " + snakeCase(param)
+		}
+	}
+
+	return required
+}
+
+// setBound applies a min or max rule, which bounds the length of strings
+// and slices and the value of numbers.
+func setBound(schema *Schema, t reflect.Type, param string, lower bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			schema.MinLength = intPtr(int(n))
+		} else {
+			schema.MaxLength = intPtr(int(n))
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			schema.MinItems = intPtr(int(n))
+		} else {
+			schema.MaxItems = intPtr(int(n))
+		}
+	default:
+		if lower {
+			schema.Minimum = &n
+		} else {
+			schema.Maximum = &n
+		}
+	}
+}
+
+// snakeCase converts a Go field name such as StartTime, which is how
+// gtfield refers to fields, into its json name.
+func snakeCase(field string) string {
+	var b strings.Builder
+	for i, r := range field {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
+
+// envelope wraps the schema of t in the response.Response or, for lists,
+// the response.PaginatedResponse body.
+func (s *schemas) envelope(t reflect.Type, list bool) *Schema {
+	data := s.ref(t)
+	if list {
+		data = &Schema{Type: "array", Items: data}
+	}
+
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success":    {Type: "boolean"},
+			"data":       data,
+			"request_id": {Type: "string"},
+		},
+		Required: []string{"success", "data"},
+	}
+	if list {
+		schema.Properties["pagination"] = s.ref(reflect.TypeOf(response.Pagination{}))
+	}
+	return schema
+}
+
+// errorEnvelope is the body written by response.Error.
+func (s *schemas) errorEnvelope() *Schema {
+	name := "ErrorResponse"
+	if _, ok := s.components[name]; !ok {
+		s.components[name] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"success":    {Type: "boolean"},
+				"error":      s.ref(reflect.TypeOf(response.ErrorInfo{})),
+				"request_id": {Type: "string"},
+			},
+			Required: []string{"success", "error"},
+		}
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// withNullable marks a schema nullable. Siblings of $ref are ignored in
+// OpenAPI 3.0, so references are wrapped in allOf.
+func withNullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{Nullable: true, AllOf: []*Schema{schema}}
+	}
+	schema.Nullable = true
+	return schema
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func intPtr(v int) *int {
+	return &v
+}
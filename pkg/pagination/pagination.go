@@ -0,0 +1,184 @@
+// Package pagination implements the page/page_size and keyset cursor
+// pagination shared by list endpoints.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// String returns the sort in its query form, e.g. "-created_at".
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Cursor is a keyset position: the sort value and ID of the last item of
+// the previous page. It carries the sort it was issued for so a cursor
+// cannot be replayed against a different ordering.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Params are the pagination and sort options of a list request. Page is
+// ignored when Cursor is set.
+type Params struct {
+	Page     int
+	PageSize int
+	Cursor   *Cursor
+	Sort     Sort
+}
+
+// Result describes the page returned for a Params. NextCursor is empty on
+// the last page.
+type Result struct {
+	Total      int64
+	NextCursor string
+}
+
+// Normalize clamps Page and PageSize to valid values.
+func (p *Params) Normalize() {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 || p.PageSize > MaxPageSize {
+		p.PageSize = DefaultPageSize
+	}
+}
+
+func (p Params) Offset() int {
+	if p.Cursor != nil {
+		return 0
+	}
+	return (p.Page - 1) * p.PageSize
+}
+
+// OrderBy returns the ORDER BY clause for column, using idColumn as a
+// tiebreaker so keyset positions are unique.
+func (p Params) OrderBy(column, idColumn string) string {
+	dir := "ASC"
+	if p.Sort.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("ORDER BY %s %s, %s %s", column, dir, idColumn, dir)
+}
+
+// After returns the condition that resumes after the cursor, with
+// placeholders starting at argIndex, and its arguments. cast is the SQL
+// type of column. It returns an empty condition when no cursor is set.
+func (p Params) After(column, cast, idColumn string, argIndex int) (string, []any) {
+	if p.Cursor == nil {
+		return "", nil
+	}
+
+	op := ">"
+	if p.Sort.Desc {
+		op = "<"
+	}
+
+	condition := fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d::uuid)", column, idColumn, op, argIndex, cast, argIndex+1)
+	return condition, []any{p.Cursor.Value, p.Cursor.ID}
+}
+
+// Next returns the cursor for the page following one whose last item has
+// the given sort value and ID.
+func (p Params) Next(value, id string) string {
+	return EncodeCursor(Cursor{Sort: p.Sort.String(), Value: value, ID: id})
+}
+
+func EncodeCursor(cursor Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.NewValidationError("invalid cursor", err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == "" {
+		return nil, errors.NewValidationError("invalid cursor", err)
+	}
+
+	return &cursor, nil
+}
+
+// FromQuery reads page, page_size, cursor and sort from the query string.
+// sort is one of allowed, optionally prefixed with "-" for descending
+// order; fallback is used when it is absent.
+func FromQuery(c *gin.Context, allowed []string, fallback Sort) (Params, error) {
+	params := Params{Page: 1, PageSize: DefaultPageSize, Sort: fallback}
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			params.Page = parsed
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= MaxPageSize {
+			params.PageSize = parsed
+		}
+	}
+
+	if s := c.Query("sort"); s != "" {
+		sort := Sort{Field: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+		if !slices.Contains(allowed, sort.Field) {
+			return Params{}, errors.NewValidationError(
+				fmt.Sprintf("sort must be one of %s, optionally prefixed with -", strings.Join(allowed, ", ")), nil)
+		}
+		params.Sort = sort
+	}
+
+	if cur := c.Query("cursor"); cur != "" {
+		cursor, err := DecodeCursor(cur)
+		if err != nil {
+			return Params{}, err
+		}
+		if cursor.Sort != params.Sort.String() {
+			return Params{}, errors.NewValidationError("cursor was issued for a different sort", nil)
+		}
+		params.Cursor = cursor
+	}
+
+	return params, nil
+}
+
+// Response builds the pagination block of a list response. Page numbers
+// are omitted for cursor requests.
+func Response(params Params, result *Result) *response.Pagination {
+	pagination := &response.Pagination{
+		PageSize:   params.PageSize,
+		Total:      result.Total,
+		TotalPages: int((result.Total + int64(params.PageSize) - 1) / int64(params.PageSize)),
+		NextCursor: result.NextCursor,
+	}
+	if params.Cursor == nil {
+		pagination.Page = params.Page
+	}
+	return pagination
+}
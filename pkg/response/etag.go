@@ -0,0 +1,73 @@
+package response
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// ETag returns the entity tag of a resource at version.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Digest returns an entity tag for resources without a version, made from
+// the values that change with their representation, such as updated_at.
+func Digest(parts ...any) string {
+	h := fnv.New64a()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%v\x00", part)
+	}
+	return `"` + strconv.FormatUint(h.Sum64(), 36) + `"`
+}
+
+// SuccessWithETag responds like Success and tags the response with the
+// version of the resource, for clients to send back in If-Match or
+// If-None-Match.
+func SuccessWithETag(c *gin.Context, version int64, data any) {
+	if NotModified(c, ETag(version)) {
+		return
+	}
+	Success(c, data)
+}
+
+// NotModified tags the response with etag and, when the request is a GET
+// or HEAD whose If-None-Match names it, answers 304 Not Modified and
+// returns true. Tags are compared weakly, so W/ prefixes are ignored.
+func NotModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			c.Status(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatch returns the version the If-Match header of the request names, or
+// 0 when the header is missing or "*". Only single tags made by ETag are
+// accepted.
+func IfMatch(c *gin.Context) (int64, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+	if err != nil || version <= 0 || value != ETag(version) {
+		return 0, errors.NewValidationError("If-Match must be an entity tag returned in ETag", nil)
+	}
+	return version, nil
+}
@@ -1,9 +1,12 @@
 package response
 
 import (
+	stderrors "errors"
 	"net/http"
 
 	"github.com/dmehra2102/booking-system/internal/common/errors"
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+	"github.com/dmehra2102/booking-system/pkg/validation"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,54 +21,98 @@ type ErrorInfo struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	// Fields lists the invalid fields of a request that failed validation
+	Fields []validation.FieldError `json:"fields,omitempty"`
 }
 
 func Success(c *gin.Context, data any) {
-	requestID, _ := c.Get("request_id")
+	requestID := c.GetString("request_id")
 	c.JSON(http.StatusOK, Response{
 		Success:   true,
 		Data:      data,
-		RequestID: requestID.(string),
+		RequestID: requestID,
 	})
 }
 
 func Created(c *gin.Context, data any) {
-	requestID, _ := c.Get("request_id")
+	requestID := c.GetString("request_id")
 	c.JSON(http.StatusCreated, Response{
 		Success:   true,
 		Data:      data,
-		RequestID: requestID.(string),
+		RequestID: requestID,
 	})
 }
 
-func Error(c *gin.Context, statusCode int, err error) {
-	requestID, _ := c.Get("requedt_id")
-
-	var errorInfo *ErrorInfo
-	if appErr := errors.GetAppError(err); appErr != nil {
-		errorInfo = &ErrorInfo{
-			Type:    string(appErr.Type),
-			Message: appErr.Message,
-			Details: appErr.Details,
-		}
-
-		statusCode = appErr.Code
-	} else {
-		errorInfo = &ErrorInfo{
-			Type:    string(errors.ErrorTypeInternal),
-			Message: err.Error(),
-		}
+// CreatedWithLocation responds like Created and points the Location header
+// at the newly created resource. Resources without a GET route of their
+// own are answered with Created.
+func CreatedWithLocation(c *gin.Context, location string, data any) {
+	c.Header("Location", location)
+	Created(c, data)
+}
+
+// Accepted responds to a request that is carried out in the background,
+// pointing the Location header at where its progress can be followed.
+func Accepted(c *gin.Context, location string, data any) {
+	c.Header("Location", location)
+	c.JSON(http.StatusAccepted, Response{
+		Success:   true,
+		Data:      data,
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// hideInternalErrorsKey marks requests whose server errors are answered
+// without their message, see HideInternalErrors.
+const hideInternalErrorsKey = "response.hide_internal_errors"
+
+// HideInternalErrors makes Error answer server errors of the request with
+// a generic message, so that their causes are only logged.
+func HideInternalErrors(c *gin.Context) {
+	c.Set(hideInternalErrorsKey, true)
+}
+
+// Error answers with err and the status errors.HTTPStatus maps it to, and
+// records it on the context for the Errors middleware to log. The message
+// of an AppError is translated into the locale of the request. Validation
+// errors caused by invalid fields list them.
+func Error(c *gin.Context, err error) {
+	_ = c.Error(err)
+
+	ctx := c.Request.Context()
+	appErr := errors.GetAppError(err)
+	statusCode := errors.HTTPStatus(appErr)
+
+	errorInfo := &ErrorInfo{
+		Type:    string(appErr.Type),
+		Message: i18n.T(ctx, appErr.Message),
+		Details: appErr.Details,
+	}
+	if appErr.Type == errors.ErrorTypeValidation {
+		errorInfo.Fields = validation.FieldErrors(ctx, appErr.Err)
+	}
+	if statusCode >= http.StatusInternalServerError && c.GetBool(hideInternalErrorsKey) {
+		errorInfo.Message = i18n.T(ctx, "internal server error")
+		errorInfo.Details = ""
 	}
 
 	c.JSON(statusCode, Response{
 		Success:   false,
 		Error:     errorInfo,
-		RequestID: requestID.(string),
+		RequestID: c.GetString("request_id"),
 	})
 }
 
-func ValidationError(c *gin.Context, details string) {
-	Error(c, http.StatusBadRequest, errors.NewValidationError("validation failed", nil))
+// ValidationError answers a request that could not be bound or has invalid
+// fields, listing the fields err is about. Bodies cut off by
+// http.MaxBytesReader are answered with 413.
+func ValidationError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if stderrors.As(err, &tooLarge) {
+		Error(c, errors.NewPayloadTooLargeError("request body is too large"))
+		return
+	}
+	Error(c, errors.NewValidationError("validation failed", err))
 }
 
 type PaginatedResponse struct {
@@ -76,18 +123,19 @@ type PaginatedResponse struct {
 }
 
 type Pagination struct {
-	Page       int   `json:"page"`
-	PageSize   int   `json:"page_size"`
-	Total      int64 `json:"total"`
-	TotalPages int   `json:"total_pages"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func Paginated(c *gin.Context, data any, pagination *Pagination) {
-	requestID, _ := c.Get("request_id")
+	requestID := c.GetString("request_id")
 	c.JSON(http.StatusOK, PaginatedResponse{
 		Success:    true,
 		Data:       data,
 		Pagination: pagination,
-		RequestID:  requestID.(string),
+		RequestID:  requestID,
 	})
 }
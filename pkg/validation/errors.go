@@ -0,0 +1,244 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/dmehra2102/booking-system/pkg/i18n"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError tells which field of a request is invalid and which rule it
+// broke. Field is the JSON path of the field, such as "items[0].quantity",
+// and is empty when the request body as a whole is malformed.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// InvalidFieldError reports a field that failed a check made outside the
+// validate tags, such as parsing a query parameter.
+type InvalidFieldError struct {
+	Field string
+	Rule  string
+	Param string
+}
+
+// Invalid returns the error of a field that broke rule, e.g.
+// Invalid("from", "rfc3339", "").
+func Invalid(field, rule, param string) error {
+	return &InvalidFieldError{Field: field, Rule: rule, Param: param}
+}
+
+func (e *InvalidFieldError) Error() string {
+	return render(context.Background(), e.Field, e.Rule, e.Param, reflect.String)
+}
+
+// FieldErrors converts the errors of binding and validating a request into
+// field errors with messages in the locale of ctx. It returns nil when err
+// is not about the fields of the request.
+func FieldErrors(ctx context.Context, err error) []FieldError {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if stderrors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, e := range validationErrors {
+			field := fieldPath(e.Namespace())
+			fields = append(fields, FieldError{
+				Field:   field,
+				Rule:    e.Tag(),
+				Message: render(ctx, field, e.Tag(), ruleParam(e), e.Kind()),
+			})
+		}
+		return fields
+	}
+
+	var invalidField *InvalidFieldError
+	if stderrors.As(err, &invalidField) {
+		return []FieldError{{
+			Field:   invalidField.Field,
+			Rule:    invalidField.Rule,
+			Message: render(ctx, invalidField.Field, invalidField.Rule, invalidField.Param, reflect.String),
+		}}
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if stderrors.As(err, &typeError) {
+		return []FieldError{{
+			Field:   typeError.Field,
+			Rule:    "type",
+			Message: render(ctx, typeError.Field, "type", "", jsonKind(typeError.Type)),
+		}}
+	}
+
+	var syntaxError *json.SyntaxError
+	if stderrors.As(err, &syntaxError) || stderrors.Is(err, io.EOF) || stderrors.Is(err, io.ErrUnexpectedEOF) {
+		return []FieldError{{
+			Rule:    "json",
+			Message: render(ctx, "", "json", "", reflect.Invalid),
+		}}
+	}
+
+	return nil
+}
+
+// render writes the message of a broken rule in the locale of ctx. The
+// English messages are the translation keys, with {field} and {param}
+// filled in after translating.
+func render(ctx context.Context, field, rule, param string, kind reflect.Kind) string {
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(i18n.T(ctx, message(rule, kind)))
+}
+
+// message returns the English message of a broken rule. Size rules read
+// differently for text, collections and numbers.
+func message(rule string, kind reflect.Kind) string {
+	switch rule {
+	case "required", "required_with", "required_without":
+		return "{field} is required"
+	case "excluded_with":
+		return "{field} cannot be combined with {param}"
+	case "email":
+		return "{field} must be a valid email"
+	case "min":
+		switch kind {
+		case reflect.String:
+			return "{field} must be at least {param} characters"
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return "{field} must have at least {param} items"
+		}
+		return "{field} must be at least {param}"
+	case "max":
+		switch kind {
+		case reflect.String:
+			return "{field} must be at most {param} characters"
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return "{field} must have at most {param} items"
+		}
+		return "{field} must be at most {param}"
+	case "len":
+		return "{field} must be exactly {param} characters"
+	case "gt", "gtfield":
+		return "{field} must be greater than {param}"
+	case "gte":
+		return "{field} must be at least {param}"
+	case "nefield":
+		return "{field} must differ from {param}"
+	case "oneof":
+		return "{field} must be one of {param}"
+	case "uuid":
+		return "{field} must be a UUID"
+	case "url":
+		return "{field} must be a URL"
+	case "startswith":
+		return "{field} must start with {param}"
+	case "alphanum":
+		return "{field} must contain only letters and digits"
+	case "datetime":
+		return "{field} must be a time in the format {param}"
+	case "timezone":
+		return "{field} must be an IANA time zone"
+	case "e164":
+		return "{field} must be a phone number in E.164 format"
+	case "bcp47_language_tag":
+		return "{field} must be a BCP 47 language tag"
+	case "password":
+		return "{field} must be at least 8 characters long"
+	case "currency":
+		return "{field} must be an ISO 4217 currency code"
+	case "slug":
+		return "{field} must be lowercase letters, digits and hyphens"
+	case "rfc3339":
+		return "{field} must be an RFC 3339 timestamp"
+	case "type":
+		switch kind {
+		case reflect.String:
+			return "{field} must be a string"
+		case reflect.Bool:
+			return "{field} must be a boolean"
+		case reflect.Int:
+			return "{field} must be an integer"
+		case reflect.Float64:
+			return "{field} must be a number"
+		case reflect.Slice:
+			return "{field} must be an array"
+		case reflect.Map:
+			return "{field} must be an object"
+		}
+		return "{field} has the wrong type"
+	case "json":
+		return "the request body must be valid JSON"
+	default:
+		return "{field} is invalid"
+	}
+}
+
+// fieldPath drops the name of the validated struct from a namespace, which
+// is made of JSON names by the tag name function.
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// ruleParam returns the parameter of a rule as shown in messages. Field
+// rules name a Go field, which is shown in the snake case of its JSON name.
+func ruleParam(e validator.FieldError) string {
+	switch e.Tag() {
+	case "gtfield", "nefield", "excluded_with", "required_with", "required_without":
+		return snakeCase(e.Param())
+	case "oneof":
+		return strings.Join(strings.Fields(e.Param()), ", ")
+	}
+	return e.Param()
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	previous := rune(0)
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+		previous = r
+	}
+	return b.String()
+}
+
+// jsonKind reduces a Go type to the kind of JSON value it is decoded
+// from: String, Bool, Int, Float64, Slice or Map.
+func jsonKind(t reflect.Type) reflect.Kind {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return reflect.Invalid
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.Int
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.Array, reflect.Slice:
+		return reflect.Slice
+	case reflect.Map, reflect.Struct:
+		return reflect.Map
+	}
+	return t.Kind()
+}
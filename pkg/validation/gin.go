@@ -0,0 +1,34 @@
+package validation
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// GinValidator makes gin's ShouldBind* methods enforce the validate tags
+// used by ValidateStruct and the OpenAPI documents, instead of gin's own
+// binding tags. Install it with binding.Validator = validation.GinValidator{}.
+type GinValidator struct{}
+
+var _ binding.StructValidator = GinValidator{}
+
+func (GinValidator) ValidateStruct(obj any) error {
+	if obj == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return ValidateStruct(obj)
+}
+
+func (GinValidator) Engine() any {
+	return validate
+}
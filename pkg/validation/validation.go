@@ -2,18 +2,26 @@ package validation
 
 import (
 	"reflect"
+	"regexp"
 	"strings"
 
+	"github.com/dmehra2102/booking-system/pkg/money"
 	"github.com/go-playground/validator/v10"
 )
 
 var validate *validator.Validate
 
+// slugPattern matches lowercase words of letters and digits joined by
+// single hyphens, such as "acme-rentals".
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
 func init() {
 	validate = validator.New()
 
 	// Register custom validators
 	validate.RegisterValidation("password", validatePassword)
+	validate.RegisterValidation("currency", validateCurrency)
+	validate.RegisterValidation("slug", validateSlug)
 
 	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
 		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
@@ -33,28 +41,12 @@ func validatePassword(f1 validator.FieldLevel) bool {
 	return len(password) >= 8
 }
 
-func GetValidationErrors(err error) map[string]string {
-	errors := make(map[string]string)
-
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, e := range validationErrors {
-			field := e.Field()
-			switch e.Tag() {
-			case "required":
-				errors[field] = field + " is required"
-			case "email":
-				errors[field] = field + " must be a valid email"
-			case "min":
-				errors[field] = field + " must be at least " + e.Param() + " characters"
-			case "max":
-				errors[field] = field + " must be at most " + e.Param() + " characters"
-			case "password":
-				errors[field] = field + " must be at least 8 characters long"
-			default:
-				errors[field] = field + " is invalid"
-			}
-		}
-	}
+// validateCurrency accepts ISO 4217 codes in any letter case.
+func validateCurrency(fl validator.FieldLevel) bool {
+	_, err := money.ParseCurrency(fl.Field().String())
+	return err == nil
+}
 
-	return errors
+func validateSlug(fl validator.FieldLevel) bool {
+	return slugPattern.MatchString(fl.Field().String())
 }